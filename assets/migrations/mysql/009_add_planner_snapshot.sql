@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE planner_snapshot (
+    replica_id VARCHAR(255) PRIMARY KEY,
+    snapshot LONGBLOB NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE planner_snapshot;
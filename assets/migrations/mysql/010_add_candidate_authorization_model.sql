@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE candidate_authorization_model (
+    store CHAR(26) PRIMARY KEY,
+    authorization_model_id CHAR(26) NOT NULL,
+    sample_percentage INTEGER NOT NULL,
+    check_evaluated BIGINT NOT NULL DEFAULT 0,
+    check_diverged BIGINT NOT NULL DEFAULT 0,
+    check_errored BIGINT NOT NULL DEFAULT 0,
+    list_objects_evaluated BIGINT NOT NULL DEFAULT 0,
+    list_objects_diverged BIGINT NOT NULL DEFAULT 0,
+    list_objects_errored BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE candidate_authorization_model;
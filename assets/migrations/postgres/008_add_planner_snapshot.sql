@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE planner_snapshot (
+	replica_id TEXT PRIMARY KEY,
+	snapshot BYTEA NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL
+);
+
+-- +goose Down
+DROP TABLE planner_snapshot;
@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE active_authorization_model (
+    store CHAR(26) PRIMARY KEY,
+    authorization_model_id CHAR(26) NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE active_authorization_model;
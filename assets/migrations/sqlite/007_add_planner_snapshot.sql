@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE planner_snapshot (
+    replica_id TEXT PRIMARY KEY,
+    snapshot BLOB NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE planner_snapshot;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE candidate_authorization_model (
+    store CHAR(26) PRIMARY KEY,
+    authorization_model_id CHAR(26) NOT NULL,
+    sample_percentage INTEGER NOT NULL,
+    check_evaluated INTEGER NOT NULL DEFAULT 0,
+    check_diverged INTEGER NOT NULL DEFAULT 0,
+    check_errored INTEGER NOT NULL DEFAULT 0,
+    list_objects_evaluated INTEGER NOT NULL DEFAULT 0,
+    list_objects_diverged INTEGER NOT NULL DEFAULT 0,
+    list_objects_errored INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE candidate_authorization_model;
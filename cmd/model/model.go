@@ -0,0 +1,163 @@
+// Package model contains the commands that work on authorization model files.
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	parser "github.com/openfga/language/pkg/go/transformer"
+
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+const (
+	outputFlag = "output"
+	failOnFlag = "fail-on"
+)
+
+// NewModelCommand returns the command that groups the authorization model subcommands.
+func NewModelCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "model",
+		Short: "Work with authorization model files",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newDiffCommand())
+
+	return cmd
+}
+
+func newDiffCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff <from-model-file> <to-model-file>",
+		Short: "Show the changes between two authorization models and classify them",
+		Long: `Show the semantic changes between two authorization models: added or removed types and relations, changed rewrites,
+narrowed type restrictions and changed conditions. Each change is classified as additive, behavior_changing or breaking
+(existing tuples would become invalid). The models are read from DSL files, or from JSON files if their extension is .json.`,
+		Args: cobra.ExactArgs(2),
+		RunE: runDiff,
+	}
+
+	flags := cmd.Flags()
+	flags.String(outputFlag, "text", "the output format, 'text' or 'json'")
+	flags.String(failOnFlag, "", "exit with an error if a change is at least as severe as this classification: 'additive', 'behavior_changing' or 'breaking'")
+
+	return cmd
+}
+
+type diffOutput struct {
+	Classification string         `json:"classification"`
+	Changes        []changeOutput `json:"changes"`
+}
+
+type changeOutput struct {
+	Kind           string `json:"kind"`
+	Classification string `json:"classification"`
+	ObjectType     string `json:"object_type,omitempty"`
+	Relation       string `json:"relation,omitempty"`
+	Condition      string `json:"condition,omitempty"`
+	Detail         string `json:"detail,omitempty"`
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	output, err := cmd.Flags().GetString(outputFlag)
+	if err != nil {
+		return err
+	}
+	if output != "text" && output != "json" {
+		return fmt.Errorf("invalid output format '%s'", output)
+	}
+
+	failOn, err := cmd.Flags().GetString(failOnFlag)
+	if err != nil {
+		return err
+	}
+	var failOnClassification typesystem.ModelChangeClassification
+	if failOn != "" {
+		if failOnClassification, err = typesystem.ParseModelChangeClassification(failOn); err != nil {
+			return err
+		}
+	}
+
+	from, err := readModelFile(args[0])
+	if err != nil {
+		return err
+	}
+	to, err := readModelFile(args[1])
+	if err != nil {
+		return err
+	}
+
+	diff := typesystem.Diff(from, to)
+
+	out := cmd.OutOrStdout()
+	switch output {
+	case "json":
+		res := diffOutput{Classification: diff.Classification().String(), Changes: make([]changeOutput, 0, len(diff.Changes))}
+		for _, change := range diff.Changes {
+			res.Changes = append(res.Changes, changeOutput{
+				Kind:           string(change.Kind),
+				Classification: change.Classification.String(),
+				ObjectType:     change.ObjectType,
+				Relation:       change.Relation,
+				Condition:      change.Condition,
+				Detail:         change.Detail,
+			})
+		}
+		marshalled, err := json.MarshalIndent(res, "", "    ")
+		if err != nil {
+			return fmt.Errorf("error marshalling the diff: %w", err)
+		}
+		fmt.Fprintln(out, string(marshalled))
+	default:
+		if len(diff.Changes) == 0 {
+			fmt.Fprintln(out, "no changes")
+		}
+		for _, change := range diff.Changes {
+			fmt.Fprintln(out, change.String())
+		}
+	}
+
+	if failOn != "" && len(diff.Changes) > 0 && diff.Classification() >= failOnClassification {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("the models have %s changes", diff.Classification())
+	}
+
+	return nil
+}
+
+// readModelFile reads and validates the authorization model of a DSL file, or of a JSON file if its extension
+// is .json.
+func readModelFile(path string) (*typesystem.TypeSystem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the model file: %w", err)
+	}
+
+	var model *openfgav1.AuthorizationModel
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		model = &openfgav1.AuthorizationModel{}
+		err = protojson.Unmarshal(data, model)
+	} else {
+		model, err = parser.TransformDSLToProto(string(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the model file '%s': %w", path, err)
+	}
+
+	typesys, err := typesystem.NewAndValidate(context.Background(), model)
+	if err != nil {
+		return nil, fmt.Errorf("invalid model in '%s': %w", path, err)
+	}
+
+	return typesys, nil
+}
@@ -0,0 +1,97 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	parser "github.com/openfga/language/pkg/go/transformer"
+)
+
+func TestDiffCommand(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile := func(t *testing.T, name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	fromPath := writeFile(t, "from.fga", `model
+  schema 1.1
+type user
+type document
+  relations
+    define viewer: [user, user:*]
+`)
+
+	toModel, err := parser.TransformDSLToProto(`model
+  schema 1.1
+type user
+type document
+  relations
+    define owner: [user]
+    define viewer: [user] or owner
+`)
+	require.NoError(t, err)
+	toJSON, err := protojson.Marshal(toModel)
+	require.NoError(t, err)
+	toPath := writeFile(t, "to.json", string(toJSON))
+
+	runDiffCommand := func(args ...string) (string, error) {
+		cmd := NewModelCommand()
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(append([]string{"diff"}, args...))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	t.Run("text_output", func(t *testing.T) {
+		out, err := runDiffCommand(fromPath, toPath)
+		require.NoError(t, err)
+		require.Equal(t, `additive: relation_added document#owner
+behavior_changing: relation_rewrite_changed document#viewer
+breaking: type_restriction_removed document#viewer (user:*)
+`, out)
+	})
+
+	t.Run("json_output", func(t *testing.T) {
+		out, err := runDiffCommand(fromPath, toPath, "--output", "json")
+		require.NoError(t, err)
+
+		var res diffOutput
+		require.NoError(t, json.Unmarshal([]byte(out), &res))
+		require.Equal(t, "breaking", res.Classification)
+		require.Len(t, res.Changes, 3)
+	})
+
+	t.Run("no_changes", func(t *testing.T) {
+		out, err := runDiffCommand(fromPath, fromPath, "--fail-on", "additive")
+		require.NoError(t, err)
+		require.Equal(t, "no changes\n", out)
+	})
+
+	t.Run("fail_on", func(t *testing.T) {
+		_, err := runDiffCommand(fromPath, toPath, "--fail-on", "breaking")
+		require.ErrorContains(t, err, "the models have breaking changes")
+
+		_, err = runDiffCommand(toPath, fromPath, "--fail-on", "unknown")
+		require.ErrorContains(t, err, "unknown model change classification")
+	})
+
+	t.Run("invalid_files", func(t *testing.T) {
+		_, err := runDiffCommand(fromPath, filepath.Join(dir, "missing.fga"))
+		require.ErrorContains(t, err, "failed to read the model file")
+
+		invalidPath := writeFile(t, "invalid.fga", "model\n  schema 1.1\ntype document\n  relations\n    define viewer: [user]\n")
+		_, err = runDiffCommand(fromPath, invalidPath)
+		require.ErrorContains(t, err, "invalid model")
+	})
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/openfga/openfga/cmd"
 	"github.com/openfga/openfga/cmd/migrate"
+	"github.com/openfga/openfga/cmd/model"
 	"github.com/openfga/openfga/cmd/run"
 	"github.com/openfga/openfga/cmd/validatemodels"
 )
@@ -19,6 +20,9 @@ func main() {
 	migrateCmd := migrate.NewMigrateCommand()
 	rootCmd.AddCommand(migrateCmd)
 
+	modelCmd := model.NewModelCommand()
+	rootCmd.AddCommand(modelCmd)
+
 	validateModelsCmd := validatemodels.NewValidateCommand()
 	rootCmd.AddCommand(validateModelsCmd)
 
@@ -191,6 +191,12 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("maxAuthorizationModelSizeInBytes", flags.Lookup("max-authorization-model-size-in-bytes"))
 		util.MustBindEnv("maxAuthorizationModelSizeInBytes", "OPENFGA_MAX_AUTHORIZATION_MODEL_SIZE_IN_BYTES", "OPENFGA_MAXAUTHORIZATIONMODELSIZEINBYTES")
 
+		util.MustBindPFlag("authorizationModelOrphanedTuplesCheck.enabled", flags.Lookup("authorization-model-orphaned-tuples-check-enabled"))
+		util.MustBindEnv("authorizationModelOrphanedTuplesCheck.enabled", "OPENFGA_AUTHORIZATION_MODEL_ORPHANED_TUPLES_CHECK_ENABLED")
+
+		util.MustBindPFlag("authorizationModelOrphanedTuplesCheck.threshold", flags.Lookup("authorization-model-orphaned-tuples-check-threshold"))
+		util.MustBindEnv("authorizationModelOrphanedTuplesCheck.threshold", "OPENFGA_AUTHORIZATION_MODEL_ORPHANED_TUPLES_CHECK_THRESHOLD")
+
 		util.MustBindPFlag("maxConcurrentReadsForListObjects", flags.Lookup("max-concurrent-reads-for-list-objects"))
 		util.MustBindEnv("maxConcurrentReadsForListObjects", "OPENFGA_MAX_CONCURRENT_READS_FOR_LIST_OBJECTS", "OPENFGA_MAXCONCURRENTREADSFORLISTOBJECTS")
 
@@ -218,15 +224,39 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("listObjectsMaxResults", flags.Lookup("listObjects-max-results"))
 		util.MustBindEnv("listObjectsMaxResults", "OPENFGA_LIST_OBJECTS_MAX_RESULTS", "OPENFGA_LISTOBJECTSMAXRESULTS")
 
+		util.MustBindPFlag("listObjectsMaxObjectsInMemory", flags.Lookup("listObjects-max-objects-in-memory"))
+		util.MustBindEnv("listObjectsMaxObjectsInMemory", "OPENFGA_LIST_OBJECTS_MAX_OBJECTS_IN_MEMORY", "OPENFGA_LISTOBJECTSMAXOBJECTSINMEMORY")
+
+		util.MustBindPFlag("listObjectsMaxCandidateObjectIDs", flags.Lookup("listObjects-max-candidate-object-ids"))
+		util.MustBindEnv("listObjectsMaxCandidateObjectIDs", "OPENFGA_LIST_OBJECTS_MAX_CANDIDATE_OBJECT_IDS", "OPENFGA_LISTOBJECTSMAXCANDIDATEOBJECTIDS")
+
 		util.MustBindPFlag("listUsersDeadline", flags.Lookup("listUsers-deadline"))
 		util.MustBindEnv("listUsersDeadline", "OPENFGA_LIST_USERS_DEADLINE", "OPENFGA_LISTUSERSDEADLINE")
 
 		util.MustBindPFlag("listUsersMaxResults", flags.Lookup("listUsers-max-results"))
 		util.MustBindEnv("listUsersMaxResults", "OPENFGA_LIST_USERS_MAX_RESULTS", "OPENFGA_LISTUSERSMAXRESULTS")
 
+		util.MustBindPFlag("listUsersMaxUsersInMemory", flags.Lookup("listUsers-max-users-in-memory"))
+		util.MustBindEnv("listUsersMaxUsersInMemory", "OPENFGA_LIST_USERS_MAX_USERS_IN_MEMORY", "OPENFGA_LISTUSERSMAXUSERSINMEMORY")
+
 		util.MustBindPFlag("checkCache.limit", flags.Lookup("check-cache-limit"))
 		util.MustBindEnv("checkCache.limit", "OPENFGA_CHECK_CACHE_LIMIT")
 
+		util.MustBindPFlag("checkCache.valkey.enabled", flags.Lookup("check-cache-valkey-enabled"))
+		util.MustBindEnv("checkCache.valkey.enabled", "OPENFGA_CHECK_CACHE_VALKEY_ENABLED")
+
+		util.MustBindPFlag("checkCache.valkey.uri", flags.Lookup("check-cache-valkey-uri"))
+		util.MustBindEnv("checkCache.valkey.uri", "OPENFGA_CHECK_CACHE_VALKEY_URI")
+
+		util.MustBindPFlag("checkCache.valkey.keyPrefix", flags.Lookup("check-cache-valkey-key-prefix"))
+		util.MustBindEnv("checkCache.valkey.keyPrefix", "OPENFGA_CHECK_CACHE_VALKEY_KEY_PREFIX")
+
+		util.MustBindPFlag("checkCache.valkey.localTierEnabled", flags.Lookup("check-cache-valkey-local-tier-enabled"))
+		util.MustBindEnv("checkCache.valkey.localTierEnabled", "OPENFGA_CHECK_CACHE_VALKEY_LOCAL_TIER_ENABLED")
+
+		util.MustBindPFlag("checkCache.valkey.localTierTTL", flags.Lookup("check-cache-valkey-local-tier-ttl"))
+		util.MustBindEnv("checkCache.valkey.localTierTTL", "OPENFGA_CHECK_CACHE_VALKEY_LOCAL_TIER_TTL")
+
 		// The below configuration is deprecated in favour of OPENFGA_CHECK_CACHE_LIMIT
 		util.MustBindPFlag("cache.limit", flags.Lookup("check-query-cache-limit"))
 		util.MustBindEnv("cache.limit", "OPENFGA_CHECK_QUERY_CACHE_LIMIT")
@@ -261,6 +291,12 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("listObjectsIteratorCache.ttl", flags.Lookup("list-objects-iterator-cache-ttl"))
 		util.MustBindEnv("listObjectsIteratorCache.ttl", "OPENFGA_LIST_OBJECTS_ITERATOR_CACHE_TTL")
 
+		util.MustBindPFlag("listObjectsQueryCache.enabled", flags.Lookup("list-objects-query-cache-enabled"))
+		util.MustBindEnv("listObjectsQueryCache.enabled", "OPENFGA_LIST_OBJECTS_QUERY_CACHE_ENABLED")
+
+		util.MustBindPFlag("listObjectsQueryCache.ttl", flags.Lookup("list-objects-query-cache-ttl"))
+		util.MustBindEnv("listObjectsQueryCache.ttl", "OPENFGA_LIST_OBJECTS_QUERY_CACHE_TTL")
+
 		util.MustBindPFlag("sharedIterator.enabled", flags.Lookup("shared-iterator-enabled"))
 		util.MustBindEnv("sharedIterator.enabled", "OPENFGA_SHARED_ITERATOR_ENABLED")
 
@@ -338,5 +374,153 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindEnv("planner.evictionThreshold", "OPENFGA_PLANNER_EVICTION_THRESHOLD")
 		util.MustBindPFlag("planner.cleanupInterval", flags.Lookup("planner-cleanup-interval"))
 		util.MustBindEnv("planner.cleanupInterval", "OPENFGA_PLANNER_CLEANUP_INTERVAL")
+		util.MustBindPFlag("planner.snapshot.backend", flags.Lookup("planner-snapshot-backend"))
+		util.MustBindEnv("planner.snapshot.backend", "OPENFGA_PLANNER_SNAPSHOT_BACKEND")
+		util.MustBindPFlag("planner.snapshot.path", flags.Lookup("planner-snapshot-path"))
+		util.MustBindEnv("planner.snapshot.path", "OPENFGA_PLANNER_SNAPSHOT_PATH")
+		util.MustBindPFlag("planner.snapshot.uri", flags.Lookup("planner-snapshot-uri"))
+		util.MustBindEnv("planner.snapshot.uri", "OPENFGA_PLANNER_SNAPSHOT_URI")
+		util.MustBindPFlag("planner.snapshot.keyPrefix", flags.Lookup("planner-snapshot-key-prefix"))
+		util.MustBindEnv("planner.snapshot.keyPrefix", "OPENFGA_PLANNER_SNAPSHOT_KEY_PREFIX")
+		util.MustBindPFlag("planner.snapshot.interval", flags.Lookup("planner-snapshot-interval"))
+		util.MustBindEnv("planner.snapshot.interval", "OPENFGA_PLANNER_SNAPSHOT_INTERVAL")
+		util.MustBindPFlag("planner.snapshot.replicaId", flags.Lookup("planner-snapshot-replica-id"))
+		util.MustBindEnv("planner.snapshot.replicaId", "OPENFGA_PLANNER_SNAPSHOT_REPLICA_ID")
+		util.MustBindPFlag("planner.snapshot.mergeReplicas", flags.Lookup("planner-snapshot-merge-replicas"))
+		util.MustBindEnv("planner.snapshot.mergeReplicas", "OPENFGA_PLANNER_SNAPSHOT_MERGE_REPLICAS")
+		util.MustBindPFlag("planner.snapshot.maxAge", flags.Lookup("planner-snapshot-max-age"))
+		util.MustBindEnv("planner.snapshot.maxAge", "OPENFGA_PLANNER_SNAPSHOT_MAX_AGE")
+		util.MustBindPFlag("planner.listStrategies.enabled", flags.Lookup("planner-list-strategies-enabled"))
+		util.MustBindEnv("planner.listStrategies.enabled", "OPENFGA_PLANNER_LIST_STRATEGIES_ENABLED")
+		util.MustBindPFlag("planner.listStrategies.requiredValidations", flags.Lookup("planner-list-strategies-required-validations"))
+		util.MustBindEnv("planner.listStrategies.requiredValidations", "OPENFGA_PLANNER_LIST_STRATEGIES_REQUIRED_VALIDATIONS")
+		util.MustBindPFlag("planner.listStrategies.shadowTimeout", flags.Lookup("planner-list-strategies-shadow-timeout"))
+		util.MustBindEnv("planner.listStrategies.shadowTimeout", "OPENFGA_PLANNER_LIST_STRATEGIES_SHADOW_TIMEOUT")
+
+		util.MustBindPFlag("peerDispatch.enabled", flags.Lookup("peer-dispatch-enabled"))
+		util.MustBindEnv("peerDispatch.enabled", "OPENFGA_PEER_DISPATCH_ENABLED")
+
+		util.MustBindPFlag("peerDispatch.addr", flags.Lookup("peer-dispatch-addr"))
+		util.MustBindEnv("peerDispatch.addr", "OPENFGA_PEER_DISPATCH_ADDR")
+
+		util.MustBindPFlag("peerDispatch.presharedKeys", flags.Lookup("peer-dispatch-preshared-keys"))
+		util.MustBindEnv("peerDispatch.presharedKeys", "OPENFGA_PEER_DISPATCH_PRESHARED_KEYS")
+
+		util.MustBindPFlag("peerDispatch.advertiseAddr", flags.Lookup("peer-dispatch-advertise-addr"))
+		util.MustBindEnv("peerDispatch.advertiseAddr", "OPENFGA_PEER_DISPATCH_ADVERTISE_ADDR")
+
+		util.MustBindPFlag("peerDispatch.peers", flags.Lookup("peer-dispatch-peers"))
+		util.MustBindEnv("peerDispatch.peers", "OPENFGA_PEER_DISPATCH_PEERS")
+
+		util.MustBindPFlag("peerDispatch.dnsName", flags.Lookup("peer-dispatch-dns-name"))
+		util.MustBindEnv("peerDispatch.dnsName", "OPENFGA_PEER_DISPATCH_DNS_NAME")
+
+		util.MustBindPFlag("peerDispatch.dnsRefreshInterval", flags.Lookup("peer-dispatch-dns-refresh-interval"))
+		util.MustBindEnv("peerDispatch.dnsRefreshInterval", "OPENFGA_PEER_DISPATCH_DNS_REFRESH_INTERVAL")
+
+		util.MustBindPFlag("peerDispatch.timeout", flags.Lookup("peer-dispatch-timeout"))
+		util.MustBindEnv("peerDispatch.timeout", "OPENFGA_PEER_DISPATCH_TIMEOUT")
+
+		util.MustBindPFlag("peerDispatch.virtualNodes", flags.Lookup("peer-dispatch-virtual-nodes"))
+		util.MustBindEnv("peerDispatch.virtualNodes", "OPENFGA_PEER_DISPATCH_VIRTUAL_NODES")
+
+		util.MustBindPFlag("checkPermissionIndex.enabled", flags.Lookup("check-permission-index-enabled"))
+		util.MustBindEnv("checkPermissionIndex.enabled", "OPENFGA_CHECK_PERMISSION_INDEX_ENABLED")
+
+		util.MustBindPFlag("checkPermissionIndex.refreshInterval", flags.Lookup("check-permission-index-refresh-interval"))
+		util.MustBindEnv("checkPermissionIndex.refreshInterval", "OPENFGA_CHECK_PERMISSION_INDEX_REFRESH_INTERVAL")
+
+		util.MustBindPFlag("checkPermissionIndex.maxStaleness", flags.Lookup("check-permission-index-max-staleness"))
+		util.MustBindEnv("checkPermissionIndex.maxStaleness", "OPENFGA_CHECK_PERMISSION_INDEX_MAX_STALENESS")
+
+		util.MustBindPFlag("checkPermissionIndex.valkeyURI", flags.Lookup("check-permission-index-valkey-uri"))
+		util.MustBindEnv("checkPermissionIndex.valkeyURI", "OPENFGA_CHECK_PERMISSION_INDEX_VALKEY_URI")
+
+		util.MustBindPFlag("checkPermissionIndex.valkeyKeyPrefix", flags.Lookup("check-permission-index-valkey-key-prefix"))
+		util.MustBindEnv("checkPermissionIndex.valkeyKeyPrefix", "OPENFGA_CHECK_PERMISSION_INDEX_VALKEY_KEY_PREFIX")
+
+		util.MustBindPFlag("checkSingleflight.enabled", flags.Lookup("check-singleflight-enabled"))
+		util.MustBindEnv("checkSingleflight.enabled", "OPENFGA_CHECK_SINGLEFLIGHT_ENABLED")
+
+		util.MustBindPFlag("edgeSync.enabled", flags.Lookup("edge-sync-enabled"))
+		util.MustBindEnv("edgeSync.enabled", "OPENFGA_EDGE_SYNC_ENABLED")
+
+		util.MustBindPFlag("edgeSync.pollInterval", flags.Lookup("edge-sync-poll-interval"))
+		util.MustBindEnv("edgeSync.pollInterval", "OPENFGA_EDGE_SYNC_POLL_INTERVAL")
+
+		util.MustBindPFlag("edgeSync.cursorTTL", flags.Lookup("edge-sync-cursor-ttl"))
+		util.MustBindEnv("edgeSync.cursorTTL", "OPENFGA_EDGE_SYNC_CURSOR_TTL")
+
+		util.MustBindPFlag("checkCostAdmission.enabled", flags.Lookup("check-cost-admission-enabled"))
+		util.MustBindEnv("checkCostAdmission.enabled", "OPENFGA_CHECK_COST_ADMISSION_ENABLED")
+
+		util.MustBindPFlag("checkCostAdmission.action", flags.Lookup("check-cost-admission-action"))
+		util.MustBindEnv("checkCostAdmission.action", "OPENFGA_CHECK_COST_ADMISSION_ACTION")
+
+		util.MustBindPFlag("checkCostAdmission.maxReads", flags.Lookup("check-cost-admission-max-reads"))
+		util.MustBindEnv("checkCostAdmission.maxReads", "OPENFGA_CHECK_COST_ADMISSION_MAX_READS")
+
+		util.MustBindPFlag("checkCostAdmission.maxDispatches", flags.Lookup("check-cost-admission-max-dispatches"))
+		util.MustBindEnv("checkCostAdmission.maxDispatches", "OPENFGA_CHECK_COST_ADMISSION_MAX_DISPATCHES")
+
+		util.MustBindPFlag("checkCostAdmission.storeBudgets", flags.Lookup("check-cost-admission-store-budgets"))
+		util.MustBindEnv("checkCostAdmission.storeBudgets", "OPENFGA_CHECK_COST_ADMISSION_STORE_BUDGETS")
+
+		util.MustBindPFlag("checkCostAdmission.deprioritizedFrequency", flags.Lookup("check-cost-admission-deprioritized-frequency"))
+		util.MustBindEnv("checkCostAdmission.deprioritizedFrequency", "OPENFGA_CHECK_COST_ADMISSION_DEPRIORITIZED_FREQUENCY")
+
+		util.MustBindPFlag("checkCostAdmission.statisticsTTL", flags.Lookup("check-cost-admission-statistics-ttl"))
+		util.MustBindEnv("checkCostAdmission.statisticsTTL", "OPENFGA_CHECK_COST_ADMISSION_STATISTICS_TTL")
+
+		util.MustBindPFlag("datastoreAdaptiveConcurrency.enabled", flags.Lookup("datastore-adaptive-concurrency-enabled"))
+		util.MustBindEnv("datastoreAdaptiveConcurrency.enabled", "OPENFGA_DATASTORE_ADAPTIVE_CONCURRENCY_ENABLED")
+
+		util.MustBindPFlag("datastoreAdaptiveConcurrency.initialLimit", flags.Lookup("datastore-adaptive-concurrency-initial-limit"))
+		util.MustBindEnv("datastoreAdaptiveConcurrency.initialLimit", "OPENFGA_DATASTORE_ADAPTIVE_CONCURRENCY_INITIAL_LIMIT")
+
+		util.MustBindPFlag("datastoreAdaptiveConcurrency.minLimit", flags.Lookup("datastore-adaptive-concurrency-min-limit"))
+		util.MustBindEnv("datastoreAdaptiveConcurrency.minLimit", "OPENFGA_DATASTORE_ADAPTIVE_CONCURRENCY_MIN_LIMIT")
+
+		util.MustBindPFlag("datastoreAdaptiveConcurrency.latencyThreshold", flags.Lookup("datastore-adaptive-concurrency-latency-threshold"))
+		util.MustBindEnv("datastoreAdaptiveConcurrency.latencyThreshold", "OPENFGA_DATASTORE_ADAPTIVE_CONCURRENCY_LATENCY_THRESHOLD")
+
+		util.MustBindPFlag("datastoreAdaptiveConcurrency.backoffRatio", flags.Lookup("datastore-adaptive-concurrency-backoff-ratio"))
+		util.MustBindEnv("datastoreAdaptiveConcurrency.backoffRatio", "OPENFGA_DATASTORE_ADAPTIVE_CONCURRENCY_BACKOFF_RATIO")
+
+		util.MustBindPFlag("fairDispatchThrottling.enabled", flags.Lookup("fair-dispatch-throttling-enabled"))
+		util.MustBindEnv("fairDispatchThrottling.enabled", "OPENFGA_FAIR_DISPATCH_THROTTLING_ENABLED")
+
+		util.MustBindPFlag("fairDispatchThrottling.perClient", flags.Lookup("fair-dispatch-throttling-per-client"))
+		util.MustBindEnv("fairDispatchThrottling.perClient", "OPENFGA_FAIR_DISPATCH_THROTTLING_PER_CLIENT")
+
+		util.MustBindPFlag("fairDispatchThrottling.storeWeights", flags.Lookup("fair-dispatch-throttling-store-weights"))
+		util.MustBindEnv("fairDispatchThrottling.storeWeights", "OPENFGA_FAIR_DISPATCH_THROTTLING_STORE_WEIGHTS")
+
+		util.MustBindPFlag("fairDispatchThrottling.checkFrequency", flags.Lookup("fair-dispatch-throttling-check-frequency"))
+		util.MustBindEnv("fairDispatchThrottling.checkFrequency", "OPENFGA_FAIR_DISPATCH_THROTTLING_CHECK_FREQUENCY")
+
+		util.MustBindPFlag("loadShedding.enabled", flags.Lookup("load-shedding-enabled"))
+		util.MustBindEnv("loadShedding.enabled", "OPENFGA_LOAD_SHEDDING_ENABLED")
+
+		util.MustBindPFlag("loadShedding.maxInflight", flags.Lookup("load-shedding-max-inflight"))
+		util.MustBindEnv("loadShedding.maxInflight", "OPENFGA_LOAD_SHEDDING_MAX_INFLIGHT")
+
+		util.MustBindPFlag("loadShedding.latencyTarget", flags.Lookup("load-shedding-latency-target"))
+		util.MustBindEnv("loadShedding.latencyTarget", "OPENFGA_LOAD_SHEDDING_LATENCY_TARGET")
+
+		util.MustBindPFlag("loadShedding.maxThrottledDispatches", flags.Lookup("load-shedding-max-throttled-dispatches"))
+		util.MustBindEnv("loadShedding.maxThrottledDispatches", "OPENFGA_LOAD_SHEDDING_MAX_THROTTLED_DISPATCHES")
+
+		util.MustBindPFlag("loadShedding.lowPriorityThreshold", flags.Lookup("load-shedding-low-priority-threshold"))
+		util.MustBindEnv("loadShedding.lowPriorityThreshold", "OPENFGA_LOAD_SHEDDING_LOW_PRIORITY_THRESHOLD")
+
+		util.MustBindPFlag("loadShedding.retryAfter", flags.Lookup("load-shedding-retry-after"))
+		util.MustBindEnv("loadShedding.retryAfter", "OPENFGA_LOAD_SHEDDING_RETRY_AFTER")
+
+		util.MustBindPFlag("loadShedding.methodPriorities", flags.Lookup("load-shedding-method-priorities"))
+		util.MustBindEnv("loadShedding.methodPriorities", "OPENFGA_LOAD_SHEDDING_METHOD_PRIORITIES")
+
+		util.MustBindPFlag("loadShedding.clientPriorities", flags.Lookup("load-shedding-client-priorities"))
+		util.MustBindEnv("loadShedding.clientPriorities", "OPENFGA_LOAD_SHEDDING_CLIENT_PRIORITIES")
 	}
 }
@@ -47,12 +47,24 @@ import (
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 
 	"github.com/openfga/openfga/assets"
+	"github.com/openfga/openfga/internal/activemodel"
+	"github.com/openfga/openfga/internal/assertionrun"
 	"github.com/openfga/openfga/internal/authn"
 	"github.com/openfga/openfga/internal/authn/oidc"
 	"github.com/openfga/openfga/internal/authn/presharedkey"
 	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/internal/candidatemodel"
+	"github.com/openfga/openfga/internal/edgesync"
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/internal/listcount"
+	"github.com/openfga/openfga/internal/listrelations"
 	authnmw "github.com/openfga/openfga/internal/middleware/authn"
+	"github.com/openfga/openfga/internal/modeldiff"
+	"github.com/openfga/openfga/internal/paginatedlist"
+	"github.com/openfga/openfga/internal/peer"
 	"github.com/openfga/openfga/internal/planner"
+	"github.com/openfga/openfga/internal/streamedbatchcheck"
+	"github.com/openfga/openfga/internal/streamedlistusers"
 	"github.com/openfga/openfga/pkg/encoder"
 	"github.com/openfga/openfga/pkg/gateway"
 	"github.com/openfga/openfga/pkg/logger"
@@ -216,6 +228,10 @@ func NewRunCommand() *cobra.Command {
 
 	flags.Int("max-authorization-model-size-in-bytes", defaultConfig.MaxAuthorizationModelSizeInBytes, "the maximum size in bytes allowed for persisting an Authorization Model.")
 
+	flags.Bool("authorization-model-orphaned-tuples-check-enabled", defaultConfig.AuthorizationModelOrphanedTuplesCheck.Enabled, "enable the rejection of the authorization models that would invalidate more than authorization-model-orphaned-tuples-check-threshold existing tuples of the store, e.g. by removing a relation or narrowing its type restrictions. Every tuple of the store is read when a model is written.")
+
+	flags.Uint32("authorization-model-orphaned-tuples-check-threshold", defaultConfig.AuthorizationModelOrphanedTuplesCheck.Threshold, "if authorization-model-orphaned-tuples-check-enabled, the maximum number of existing tuples that a new authorization model may invalidate.")
+
 	flags.Uint32("max-concurrent-reads-for-list-users", defaultConfig.MaxConcurrentReadsForListUsers, "the maximum allowed number of concurrent datastore reads in a single ListUsers query. A high number will consume more connections from the datastore pool and will attempt to prioritize performance for the request at the expense of other queries performance.")
 
 	flags.Uint32("max-concurrent-reads-for-list-objects", defaultConfig.MaxConcurrentReadsForListObjects, "the maximum allowed number of concurrent datastore reads in a single ListObjects or StreamedListObjects query. A high number will consume more connections from the datastore pool and will attempt to prioritize performance for the request at the expense of other queries performance.")
@@ -234,12 +250,28 @@ func NewRunCommand() *cobra.Command {
 
 	flags.Uint32("listObjects-max-results", defaultConfig.ListObjectsMaxResults, "the maximum results to return in non-streaming ListObjects API responses. If 0, all results can be returned")
 
+	flags.Uint32("listObjects-max-objects-in-memory", defaultConfig.ListObjectsMaxObjectsInMemory, "the maximum number of objects the exact ListObjects count keeps in memory to count each object once. The objects beyond it are spilled to temporary files. If 0, every object is kept in memory")
+
+	flags.Uint32("listObjects-max-candidate-object-ids", defaultConfig.ListObjectsMaxCandidateObjectIDs, "the maximum number of candidate object IDs a paginated ListObjects request can restrict its results to. If 0, there is no maximum")
+
 	flags.Duration("listUsers-deadline", defaultConfig.ListUsersDeadline, "the timeout deadline for serving ListUsers requests. If 0, there is no deadline")
 
 	flags.Uint32("listUsers-max-results", defaultConfig.ListUsersMaxResults, "the maximum results to return in ListUsers API responses. If 0, all results can be returned")
 
+	flags.Uint32("listUsers-max-users-in-memory", defaultConfig.ListUsersMaxUsersInMemory, "the maximum number of users the streaming ListUsers API keeps in memory to deduplicate the users it sends, and the exact ListUsers count to count each user once. The users beyond it are spilled to temporary files. If 0, every user is kept in memory")
+
 	flags.Uint32("check-cache-limit", defaultConfig.CheckCache.Limit, "if check-query-cache-enabled or check-iterator-cache-enabled, this is the size limit of the cache")
 
+	flags.Bool("check-cache-valkey-enabled", defaultConfig.CheckCache.Valkey.Enabled, "store the check cache (queries and iterators) and the cache controller invalidation timestamps in Valkey, so that they are shared across replicas.")
+
+	flags.String("check-cache-valkey-uri", defaultConfig.CheckCache.Valkey.URI, "the connection uri of the Valkey instance of the check cache, e.g. redis://localhost:6379/0.")
+
+	flags.String("check-cache-valkey-key-prefix", defaultConfig.CheckCache.Valkey.KeyPrefix, "the prefix of every key of the check cache stored in Valkey.")
+
+	flags.Bool("check-cache-valkey-local-tier-enabled", defaultConfig.CheckCache.Valkey.LocalTierEnabled, "keep a local cache, of check-cache-limit items, in front of the Valkey check cache.")
+
+	flags.Duration("check-cache-valkey-local-tier-ttl", defaultConfig.CheckCache.Valkey.LocalTierTTL, "the maximum time a value of the Valkey check cache is kept in the local cache. It bounds how long a replica may use a value that another replica changed.")
+
 	flags.Bool("shared-iterator-enabled", defaultConfig.SharedIterator.Enabled, "enabling sharing of datastore iterators with different consumers. Each iterator is the result of a database query, for example usersets related to a specific object, or objects related to a specific user, up to a certain number of tuples per iterator.")
 
 	flags.Uint32("shared-iterator-limit", defaultConfig.SharedIterator.Limit, "if shared-iterator-enabled is enabled, this is the limit of the number of iterators that can be shared.")
@@ -256,6 +288,10 @@ func NewRunCommand() *cobra.Command {
 
 	flags.Duration("list-objects-iterator-cache-ttl", defaultConfig.ListObjectsIteratorCache.TTL, "if caching of datastore iterators of ListObjects requests is enabled, this is the TTL of each value")
 
+	flags.Bool("list-objects-query-cache-enabled", defaultConfig.ListObjectsQueryCache.Enabled, "enable caching of ListObjects results. The key is the store, the model, the object type, the relation, the user and the context of the request, and requests with contextual tuples are not cached. The cached results are invalidated by the cache controller when a tuple that the relation depends on is written or deleted, so cache-controller-enabled should be enabled too. The cache is stored in the check cache, of size check-cache-limit. If the request's consistency is HIGHER_CONSISTENCY, this cache is not used.")
+
+	flags.Duration("list-objects-query-cache-ttl", defaultConfig.ListObjectsQueryCache.TTL, "if list-objects-query-cache-enabled, this is the TTL of each value")
+
 	flags.Bool("check-query-cache-enabled", defaultConfig.CheckQueryCache.Enabled, "enable caching of Check requests. For example, if you have a relation define viewer: owner or editor, and the query is Check(user:anne, viewer, doc:1), we'll evaluate the owner relation and the editor relation and cache both results: (user:anne, viewer, doc:1) -> allowed=true and (user:anne, owner, doc:1) -> allowed=true. The cache is stored in-memory; the cached values are overwritten on every change in the result, and cleared after the configured TTL. This flag improves latency, but turns Check and ListObjects into eventually consistent APIs. If the request's consistency is HIGHER_CONSISTENCY, this cache is not used.")
 
 	flags.Uint32("check-query-cache-limit", defaultConfig.CheckCache.Limit, "DEPRECATED: Use check-cache-limit instead. If caching of Check and ListObjects calls is enabled, this is the size limit of the cache")
@@ -314,6 +350,112 @@ func NewRunCommand() *cobra.Command {
 	flags.Duration("planner-eviction-threshold", defaultConfig.Planner.EvictionThreshold, "how long a planner key can be unused before being evicted")
 	flags.Duration("planner-cleanup-interval", defaultConfig.Planner.CleanupInterval, "how often the planner checks for stale keys")
 
+	flags.String("planner-snapshot-backend", defaultConfig.Planner.Snapshot.Backend, "where the planner persists what it learns, so that it survives restarts: 'none', 'file', 'valkey' or 'datastore', the datastore of the server.")
+
+	flags.String("planner-snapshot-path", defaultConfig.Planner.Snapshot.Path, "the directory of the planner snapshots when 'planner-snapshot-backend' is 'file'. Replicas share their snapshots by sharing the directory.")
+
+	flags.String("planner-snapshot-uri", defaultConfig.Planner.Snapshot.URI, "the connection uri of the Valkey instance of the planner snapshots when 'planner-snapshot-backend' is 'valkey', e.g. redis://localhost:6379/0.")
+
+	flags.String("planner-snapshot-key-prefix", defaultConfig.Planner.Snapshot.KeyPrefix, "the prefix of the keys of the planner snapshots stored in Valkey.")
+
+	flags.Duration("planner-snapshot-interval", defaultConfig.Planner.Snapshot.Interval, "how often the planner saves its snapshot, and merges the ones of the other replicas if 'planner-snapshot-merge-replicas' is enabled.")
+
+	flags.String("planner-snapshot-replica-id", defaultConfig.Planner.Snapshot.ReplicaID, "identifies the planner snapshot of this replica. It must be stable across restarts and unique across replicas. Defaults to the hostname.")
+
+	flags.Bool("planner-snapshot-merge-replicas", defaultConfig.Planner.Snapshot.MergeReplicas, "merge what the other replicas sharing the planner snapshots learned into the planner of this replica.")
+
+	flags.Duration("planner-snapshot-max-age", defaultConfig.Planner.Snapshot.MaxAge, "how old a planner snapshot may be to be restored or merged.")
+
+	flags.Bool("planner-list-strategies-enabled", defaultConfig.Planner.ListStrategies.Enabled, "let the planner select the strategy that resolves ListObjects and ListUsers requests. The strategy selected by the other flags and the feature flags is always trusted, the others are validated in shadow before being trusted.")
+
+	flags.Uint32("planner-list-strategies-required-validations", defaultConfig.Planner.ListStrategies.RequiredValidations, "the number of times the results of a strategy must match the ones of the strategy selected by the other flags, in shadow, before it is trusted. A single mismatch rejects it.")
+
+	flags.Duration("planner-list-strategies-shadow-timeout", defaultConfig.Planner.ListStrategies.ShadowTimeout, "the maximum amount of time to wait for the validation of a strategy selected by the planner.")
+
+	flags.Bool("peer-dispatch-enabled", defaultConfig.PeerDispatch.Enabled, "enable sharding of Check sub-problems across a cluster of OpenFGA nodes by consistent hashing. Each node resolves and caches the sub-problems it owns.")
+
+	flags.String("peer-dispatch-addr", defaultConfig.PeerDispatch.Addr, "the host:port address of the dedicated gRPC listener serving the sub-problems dispatched by peers. It should only be reachable by the nodes of the cluster.")
+
+	flags.StringSlice("peer-dispatch-preshared-keys", defaultConfig.PeerDispatch.PresharedKeys, "one or more keys authenticating the peers on the peer dispatch listener. The first one is sent to the peers, the others are also accepted to allow rotating the key.")
+
+	flags.String("peer-dispatch-advertise-addr", defaultConfig.PeerDispatch.AdvertiseAddr, "the address (host:port) of the peer dispatch listener under which this node is reachable by its peers. It must match the address listed in 'peer-dispatch-peers' or resolved from 'peer-dispatch-dns-name'.")
+
+	flags.StringSlice("peer-dispatch-peers", defaultConfig.PeerDispatch.Peers, "a static list of the peer dispatch addresses (host:port) of the nodes of the cluster. Mutually exclusive with 'peer-dispatch-dns-name'.")
+
+	flags.String("peer-dispatch-dns-name", defaultConfig.PeerDispatch.DNSName, "a DNS name and peer dispatch port (host:port) resolving to all the nodes of the cluster, e.g. a Kubernetes headless service. Mutually exclusive with 'peer-dispatch-peers'.")
+
+	flags.Duration("peer-dispatch-dns-refresh-interval", defaultConfig.PeerDispatch.DNSRefreshInterval, "how often 'peer-dispatch-dns-name' is resolved again to discover peers.")
+
+	flags.Duration("peer-dispatch-timeout", defaultConfig.PeerDispatch.Timeout, "the maximum amount of time to wait for a peer to resolve a sub-problem before resolving it locally.")
+
+	flags.Int("peer-dispatch-virtual-nodes", defaultConfig.PeerDispatch.VirtualNodes, "the number of times each node is placed on the consistent hash ring.")
+
+	flags.Bool("check-permission-index-enabled", defaultConfig.CheckPermissionIndex.Enabled, "enable a precomputed index of nested userset membership, maintained from the changelog, that Check consults for recursive relations such as 'define member: [user, group#member]'.")
+
+	flags.Duration("check-permission-index-refresh-interval", defaultConfig.CheckPermissionIndex.RefreshInterval, "how often the permission index is brought up to date with the changelog of each store.")
+
+	flags.Duration("check-permission-index-max-staleness", defaultConfig.CheckPermissionIndex.MaxStaleness, "how old the permission index of a store may be for Check to use it. Checks on staler stores are resolved through the graph.")
+
+	flags.String("check-permission-index-valkey-uri", defaultConfig.CheckPermissionIndex.ValkeyURI, "the connection uri of the Valkey instance the permission index is stored in and shared by the replicas, e.g. redis://localhost:6379/0. Required when the permission index is enabled.")
+
+	flags.String("check-permission-index-valkey-key-prefix", defaultConfig.CheckPermissionIndex.ValkeyKeyPrefix, "the prefix of the keys of the permission index stored in Valkey.")
+
+	flags.Bool("check-singleflight-enabled", defaultConfig.CheckSingleflight.Enabled, "enable coalescing of identical concurrent Check sub-problems (top-level and nested), so that they share a single resolution. Sub-problems with HIGHER_CONSISTENCY are never coalesced.")
+
+	flags.Bool("edge-sync-enabled", defaultConfig.EdgeSync.Enabled, "enable the edge sync streaming service, through which edge sidecars subscribe to materialized Check results and receive deltas as they change.")
+
+	flags.Duration("edge-sync-poll-interval", defaultConfig.EdgeSync.PollInterval, "how often the changelog of each subscribed store is read to stream deltas to edge sync subscribers.")
+
+	flags.Duration("edge-sync-cursor-ttl", defaultConfig.EdgeSync.CursorTTL, "how long an edge sync subscriber can resume from a cursor with deltas only. Subscribers resuming from an older cursor are sent a new snapshot.")
+
+	flags.Bool("check-cost-admission-enabled", defaultConfig.CheckCostAdmission.Enabled, "enable the admission of Check requests by their cost, estimated from the weighted graph of the model and the sampled cardinality of the relations it traverses. The estimate is returned in the 'Openfga-Check-Estimated-Reads' and 'Openfga-Check-Estimated-Dispatches' headers.")
+
+	flags.String("check-cost-admission-action", defaultConfig.CheckCostAdmission.Action, "what happens to the checks estimated above the budget of their store: 'reject' fails them with a resource exhausted error, 'deprioritize' lets them through at the rate of 'check-cost-admission-deprioritized-frequency'.")
+
+	flags.Uint32("check-cost-admission-max-reads", defaultConfig.CheckCostAdmission.MaxReads, "the maximum estimated number of datastore reads of a check, for the stores without a budget in 'check-cost-admission-store-budgets'. 0 is unlimited.")
+
+	flags.Uint32("check-cost-admission-max-dispatches", defaultConfig.CheckCostAdmission.MaxDispatches, "the maximum estimated number of dispatches of a check, for the stores without a budget in 'check-cost-admission-store-budgets'. 0 is unlimited.")
+
+	flags.StringSlice("check-cost-admission-store-budgets", defaultConfig.CheckCostAdmission.StoreBudgets, "the budgets of specific stores, each as '<store_id>:<max_reads>:<max_dispatches>'. 0 is unlimited.")
+
+	flags.Duration("check-cost-admission-deprioritized-frequency", defaultConfig.CheckCostAdmission.DeprioritizedFrequency, "how often a deprioritized check is let through.")
+
+	flags.Duration("check-cost-admission-statistics-ttl", defaultConfig.CheckCostAdmission.StatisticsTTL, "how long the sampled cardinality of a relation is used to estimate the cost of checks before it is sampled again.")
+
+	flags.Bool("datastore-adaptive-concurrency-enabled", defaultConfig.DatastoreAdaptiveConcurrency.Enabled, "enable adapting the number of concurrent datastore reads of each Check, ListObjects and ListUsers request to the latency and errors of the datastore, instead of bounding it with a static limit. The 'max-concurrent-reads-for-*' flags are the maximum limits of each method. Reads slowed down by the datastore throttling never raise the limits.")
+
+	flags.Uint32("datastore-adaptive-concurrency-initial-limit", defaultConfig.DatastoreAdaptiveConcurrency.InitialLimit, "the number of concurrent datastore reads per request that the adaptive limits start at.")
+
+	flags.Uint32("datastore-adaptive-concurrency-min-limit", defaultConfig.DatastoreAdaptiveConcurrency.MinLimit, "the lowest number of concurrent datastore reads per request that the adaptive limits can go down to.")
+
+	flags.Duration("datastore-adaptive-concurrency-latency-threshold", defaultConfig.DatastoreAdaptiveConcurrency.LatencyThreshold, "the latency of the datastore reads above which the adaptive limits are lowered.")
+
+	flags.Float64("datastore-adaptive-concurrency-backoff-ratio", defaultConfig.DatastoreAdaptiveConcurrency.BackoffRatio, "the ratio, between 0 and 1 exclusive, by which the adaptive limits are lowered when the datastore is slow or overloaded.")
+
+	flags.Bool("fair-dispatch-throttling-enabled", defaultConfig.FairDispatchThrottling.Enabled, "enable scheduling the throttled dispatches of Check, ListObjects and ListUsers fairly across stores, with weighted fair queuing, so that the dispatches of a noisy store are throttled first. It applies to the dispatch throttling of the methods that have it enabled.")
+
+	flags.Bool("fair-dispatch-throttling-per-client", defaultConfig.FairDispatchThrottling.PerClient, "schedule the throttled dispatches fairly across the clients of each store too, by the client ID of the authenticated requests.")
+
+	flags.StringSlice("fair-dispatch-throttling-store-weights", defaultConfig.FairDispatchThrottling.StoreWeights, "the weights of specific stores, each as '<store_id>:<weight>'. A store gets a share of the throttled dispatches proportional to its weight. The other stores weigh 1.")
+
+	flags.Duration("fair-dispatch-throttling-check-frequency", defaultConfig.FairDispatchThrottling.CheckFrequency, "the frequency at which the fair throttler of Check releases a throttled dispatch. It is shared by all the Check requests of the server, unlike the throttler of each request used without fair scheduling, so it should be much smaller than the check dispatch throttling frequency.")
+
+	flags.Bool("load-shedding-enabled", defaultConfig.LoadShedding.Enabled, "enable rejecting requests with RESOURCE_EXHAUSTED and a retry-after hint when the server is under pressure, starting with the low priority ones so that the high priority ones aren't affected. Check and BatchCheck are high priority, ListObjects, ListUsers, Read, ReadChanges and Expand are low priority, and the other methods are normal priority. Clients can lower the priority of their requests with the 'Openfga-Priority' header.")
+
+	flags.Int("load-shedding-max-inflight", defaultConfig.LoadShedding.MaxInflight, "the number of requests in flight at which the server is at capacity. 0 ignores the requests in flight.")
+
+	flags.Duration("load-shedding-latency-target", defaultConfig.LoadShedding.LatencyTarget, "the p99 latency of the high priority requests at which the server is at capacity. 0 ignores the latency.")
+
+	flags.Int("load-shedding-max-throttled-dispatches", defaultConfig.LoadShedding.MaxThrottledDispatches, "the number of dispatches waiting in the shared dispatch throttlers at which the server is at capacity. 0 ignores the throttled dispatches.")
+
+	flags.Float64("load-shedding-low-priority-threshold", defaultConfig.LoadShedding.LowPriorityThreshold, "the pressure, greater than 0 and at most 1, above which the low priority requests are shed. The normal priority requests are shed at capacity, and the high priority requests are never shed.")
+
+	flags.Duration("load-shedding-retry-after", defaultConfig.LoadShedding.RetryAfter, "the delay after which the shed requests are told to retry.")
+
+	flags.StringSlice("load-shedding-method-priorities", defaultConfig.LoadShedding.MethodPriorities, "the priorities of specific methods, each as '<method>:<low|normal|high>', e.g. 'Read:normal'.")
+
+	flags.StringSlice("load-shedding-client-priorities", defaultConfig.LoadShedding.ClientPriorities, "the priorities of the requests of specific clients, each as '<client_id>:<low|normal|high>', whatever their method.")
+
 	// NOTE: if you add a new flag here, update the function below, too
 
 	cmd.PreRun = bindRunFlagsFunc(flags)
@@ -503,6 +645,179 @@ func (s *ServerContext) authenticatorConfig(config *serverconfig.Config) (authn.
 	return authenticator, nil
 }
 
+// peerDispatchConfig returns the dispatcher used to shard Check sub-problems across the peers of the cluster,
+// or nil if peer dispatch is disabled. The returned function must be called to release the dispatcher.
+func (s *ServerContext) peerDispatchConfig(ctx context.Context, config *serverconfig.Config) (graph.PeerDispatcher, func(), error) {
+	if !config.PeerDispatch.Enabled {
+		return nil, func() {}, nil
+	}
+
+	var provider peer.Provider
+	if config.PeerDispatch.DNSName != "" {
+		dnsProvider, err := peer.NewDNSProvider(ctx, config.PeerDispatch.DNSName,
+			peer.WithDNSRefreshInterval(config.PeerDispatch.DNSRefreshInterval),
+			peer.WithDNSLogger(s.Logger),
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize peer discovery: %w", err)
+		}
+		provider = dnsProvider
+	} else {
+		provider = peer.NewStaticProvider(config.PeerDispatch.Peers...)
+	}
+
+	transportCredentials := insecure.NewCredentials()
+	if config.GRPC.TLS.Enabled {
+		creds, err := credentials.NewClientTLSFromFile(config.GRPC.TLS.CertPath, "")
+		if err != nil {
+			provider.Close()
+			return nil, nil, fmt.Errorf("failed to load gRPC credentials for peer dispatch: %w", err)
+		}
+		transportCredentials = creds
+	}
+
+	dialOpts := []grpc.DialOption{grpc.WithTransportCredentials(transportCredentials)}
+	if config.Trace.Enabled {
+		dialOpts = append(dialOpts, grpc.WithStatsHandler(otelgrpc.NewClientHandler()))
+	}
+
+	client := peer.NewClient(config.PeerDispatch.AdvertiseAddr, provider,
+		peer.WithVirtualNodes(config.PeerDispatch.VirtualNodes),
+		peer.WithDispatchTimeout(config.PeerDispatch.Timeout),
+		peer.WithDialOptions(dialOpts...),
+		peer.WithPresharedKey(config.PeerDispatch.PresharedKeys[0]),
+	)
+
+	s.Logger.Info("🕸️ peer dispatch is enabled", zap.String("advertise_addr", config.PeerDispatch.AdvertiseAddr))
+
+	return client, client.Close, nil
+}
+
+// peerDispatchServer starts the dedicated gRPC listener serving the sub-problems dispatched by the peers of the
+// cluster. It is kept apart from the public listener, and only accepts the peers authenticated with one of the
+// peer dispatch preshared keys.
+func (s *ServerContext) peerDispatchServer(config *serverconfig.Config, svr *server.Server, creds credentials.TransportCredentials) (*grpc.Server, error) {
+	authenticator, err := presharedkey.NewPresharedKeyAuthenticator(config.PeerDispatch.PresharedKeys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize peer dispatch authenticator: %w", err)
+	}
+
+	serverOpts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(serverconfig.DefaultMaxRPCMessageSizeInBytes),
+		grpc.ChainUnaryInterceptor(
+			grpc_recovery.UnaryServerInterceptor( // panic middleware must be 1st in chain
+				grpc_recovery.WithRecoveryHandlerContext(
+					recovery.PanicRecoveryHandler(s.Logger),
+				),
+			),
+			grpc_ctxtags.UnaryServerInterceptor(),
+			requestid.NewUnaryInterceptor(),
+			storeid.NewUnaryInterceptor(),
+			grpcauth.UnaryServerInterceptor(authnmw.AuthFunc(authenticator)),
+		),
+	}
+	if config.Trace.Enabled {
+		serverOpts = append(serverOpts, grpc.StatsHandler(otelgrpc.NewServerHandler()))
+	}
+	if creds != nil {
+		serverOpts = append(serverOpts, grpc.Creds(creds))
+	}
+
+	// nosemgrep: grpc-server-insecure-connection
+	peerServer := grpc.NewServer(serverOpts...)
+	peer.RegisterDispatchServer(peerServer, svr)
+
+	lis, err := net.Listen("tcp", config.PeerDispatch.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for peer dispatch: %w", err)
+	}
+
+	go func() {
+		s.Logger.Info(fmt.Sprintf("🕸️ starting peer dispatch gRPC server on '%s'...", lis.Addr().String()))
+		if err := peerServer.Serve(lis); err != nil {
+			if !errors.Is(err, grpc.ErrServerStopped) {
+				s.Logger.Fatal("failed to start peer dispatch gRPC server", zap.Error(err))
+			}
+		}
+		s.Logger.Info("peer dispatch gRPC server shut down.")
+	}()
+
+	return peerServer, nil
+}
+
+// plannerConfig returns the planner of the server, which persists what it learns if a snapshot backend is
+// configured.
+func (s *ServerContext) plannerConfig(config *serverconfig.Config, datastore storage.OpenFGADatastore) (*planner.Planner, error) {
+	plannerConfig := &planner.Config{
+		EvictionThreshold: config.Planner.EvictionThreshold,
+		CleanupInterval:   config.Planner.CleanupInterval,
+	}
+
+	snapshotConfig := config.Planner.Snapshot
+	var err error
+	switch snapshotConfig.Backend {
+	case serverconfig.PlannerSnapshotBackendFile:
+		plannerConfig.Store, err = planner.NewFileSnapshotStore(snapshotConfig.Path)
+	case serverconfig.PlannerSnapshotBackendValkey:
+		// let the snapshots of replicas that are gone expire once they are stale
+		plannerConfig.Store, err = planner.NewValkeySnapshotStore(snapshotConfig.URI, snapshotConfig.KeyPrefix, snapshotConfig.MaxAge)
+	case serverconfig.PlannerSnapshotBackendDatastore:
+		plannerConfig.Store = planner.NewDatastoreSnapshotStore(datastore, snapshotConfig.MaxAge)
+	default:
+		return planner.New(plannerConfig), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize the planner snapshot store: %w", err)
+	}
+
+	replicaID := snapshotConfig.ReplicaID
+	if replicaID == "" {
+		if replicaID, err = os.Hostname(); err != nil {
+			_ = plannerConfig.Store.Close()
+			return nil, fmt.Errorf("failed to determine the planner snapshot replica id: %w", err)
+		}
+	}
+
+	plannerConfig.SnapshotInterval = snapshotConfig.Interval
+	plannerConfig.ReplicaID = replicaID
+	plannerConfig.MergeReplicas = snapshotConfig.MergeReplicas
+	plannerConfig.SnapshotMaxAge = snapshotConfig.MaxAge
+	plannerConfig.Logger = s.Logger
+
+	s.Logger.Info("🧠 planner snapshots are enabled",
+		zap.String("backend", snapshotConfig.Backend),
+		zap.String("replica_id", replicaID),
+		zap.Bool("merge_replicas", snapshotConfig.MergeReplicas),
+	)
+
+	return planner.New(plannerConfig), nil
+}
+
+// loadSheddingConfig returns the load shedding interceptor of the server, from the load shedding config.
+func (s *ServerContext) loadSheddingConfig(config *serverconfig.Config, svr *server.Server) *middleware.LoadSheddingInterceptor {
+	// the priorities were validated with the rest of the config
+	methodPriorities, _ := serverconfig.ParseLoadSheddingPriorities(config.LoadShedding.MethodPriorities)
+	clientPriorities, _ := serverconfig.ParseLoadSheddingPriorities(config.LoadShedding.ClientPriorities)
+
+	toPriorities := func(names map[string]string) map[string]middleware.Priority {
+		priorities := make(map[string]middleware.Priority, len(names))
+		for name, priority := range names {
+			priorities[name], _ = middleware.ParsePriority(priority)
+		}
+		return priorities
+	}
+
+	return middleware.NewLoadSheddingInterceptor(
+		middleware.WithMethodPriorities(toPriorities(methodPriorities)),
+		middleware.WithClientPriorities(toPriorities(clientPriorities)),
+		middleware.WithMaxInflight(config.LoadShedding.MaxInflight),
+		middleware.WithLatencyTarget(config.LoadShedding.LatencyTarget),
+		middleware.WithThrottledDispatches(svr.ThrottledDispatches, config.LoadShedding.MaxThrottledDispatches),
+		middleware.WithLowPriorityThreshold(config.LoadShedding.LowPriorityThreshold),
+		middleware.WithRetryAfter(config.LoadShedding.RetryAfter),
+	)
+}
+
 // Run returns an error if the server was unable to start successfully.
 // If it started and terminated successfully, it returns a nil error.
 func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) error {
@@ -606,6 +921,8 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 		),
 	)
 
+	// grpcCreds are also used by the peer dispatch listener
+	var grpcCreds credentials.TransportCredentials
 	if config.GRPC.TLS.Enabled {
 		if config.GRPC.TLS.CertPath == "" || config.GRPC.TLS.KeyPath == "" {
 			return errors.New("'grpc.tls.cert' and 'grpc.tls.key' configs must be set")
@@ -614,17 +931,22 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 		if err != nil {
 			return err
 		}
-		creds := credentials.NewTLS(&tls.Config{
+		grpcCreds = credentials.NewTLS(&tls.Config{
 			GetCertificate: grpcGetCertificate,
 		})
 
-		serverOpts = append(serverOpts, grpc.Creds(creds))
+		serverOpts = append(serverOpts, grpc.Creds(grpcCreds))
 
 		s.Logger.Info("gRPC TLS is enabled, serving connections using the provided certificate")
 	} else {
 		s.Logger.Warn("gRPC TLS is disabled, serving connections using insecure plaintext")
 	}
 
+	checkPlanner, err := s.plannerConfig(config, datastore)
+	if err != nil {
+		return err
+	}
+
 	var profilerServer *http.Server
 	if config.Profiler.Enabled {
 		mux := http.NewServeMux()
@@ -652,6 +974,9 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 	if config.Metrics.Enabled {
 		mux := http.NewServeMux()
 		mux.Handle("/metrics", promhttp.Handler())
+		// inspect (GET) and reset (DELETE) what the planner learned, optionally for a single ?key=, as an
+		// authenticated client of the server
+		mux.Handle("/debug/planner", authnmw.HTTPHandler(authenticator, checkPlanner.Handler()))
 
 		metricsServer = &http.Server{Addr: config.Metrics.Addr, Handler: mux}
 
@@ -666,6 +991,16 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 		}()
 	}
 
+	peerDispatcher, peerDispatcherCloser, err := s.peerDispatchConfig(ctx, config)
+	if err != nil {
+		checkPlanner.Stop()
+		return err
+	}
+
+	// the store budgets and weights were validated with the rest of the config
+	checkCostStoreBudgets, _ := serverconfig.ParseCheckCostStoreBudgets(config.CheckCostAdmission.StoreBudgets)
+	fairDispatchThrottlingStoreWeights, _ := serverconfig.ParseFairDispatchThrottlingStoreWeights(config.FairDispatchThrottling.StoreWeights)
+
 	svr := server.MustNewServerWithOpts(
 		server.WithDatastore(datastore),
 		server.WithContinuationTokenSerializer(continuationTokenSerializer),
@@ -677,14 +1012,21 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 		server.WithChangelogHorizonOffset(config.ChangelogHorizonOffset),
 		server.WithListObjectsDeadline(config.ListObjectsDeadline),
 		server.WithListObjectsMaxResults(config.ListObjectsMaxResults),
+		server.WithListObjectsMaxObjectsInMemory(config.ListObjectsMaxObjectsInMemory),
+		server.WithListObjectsMaxCandidateObjectIDs(config.ListObjectsMaxCandidateObjectIDs),
 		server.WithListUsersDeadline(config.ListUsersDeadline),
 		server.WithListUsersMaxResults(config.ListUsersMaxResults),
+		server.WithListUsersMaxUsersInMemory(config.ListUsersMaxUsersInMemory),
 		server.WithMaxConcurrentReadsForListObjects(config.MaxConcurrentReadsForListObjects),
 		server.WithMaxConcurrentReadsForCheck(config.MaxConcurrentReadsForCheck),
 		server.WithMaxConcurrentReadsForListUsers(config.MaxConcurrentReadsForListUsers),
 		server.WithCacheControllerEnabled(config.CacheController.Enabled),
 		server.WithCacheControllerTTL(config.CacheController.TTL),
 		server.WithCheckCacheLimit(config.CheckCache.Limit),
+		server.WithCheckCacheValkeyEnabled(config.CheckCache.Valkey.Enabled),
+		server.WithCheckCacheValkeyURI(config.CheckCache.Valkey.URI),
+		server.WithCheckCacheValkeyKeyPrefix(config.CheckCache.Valkey.KeyPrefix),
+		server.WithCheckCacheValkeyLocalTier(config.CheckCache.Valkey.LocalTierEnabled, config.CheckCache.Valkey.LocalTierTTL),
 		server.WithCheckIteratorCacheEnabled(config.CheckIteratorCache.Enabled),
 		server.WithCheckIteratorCacheMaxResults(config.CheckIteratorCache.MaxResults),
 		server.WithCheckIteratorCacheTTL(config.CheckIteratorCache.TTL),
@@ -693,6 +1035,8 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 		server.WithRequestDurationByQueryHistogramBuckets(convertStringArrayToUintArray(config.RequestDurationDatastoreQueryCountBuckets)),
 		server.WithRequestDurationByDispatchCountHistogramBuckets(convertStringArrayToUintArray(config.RequestDurationDispatchCountBuckets)),
 		server.WithMaxAuthorizationModelSizeInBytes(config.MaxAuthorizationModelSizeInBytes),
+		server.WithAuthorizationModelOrphanedTuplesCheckEnabled(config.AuthorizationModelOrphanedTuplesCheck.Enabled),
+		server.WithAuthorizationModelOrphanedTuplesCheckThreshold(config.AuthorizationModelOrphanedTuplesCheck.Threshold),
 		server.WithContextPropagationToDatastore(config.ContextPropagationToDatastore),
 		server.WithDispatchThrottlingCheckResolverEnabled(config.CheckDispatchThrottling.Enabled),
 		server.WithDispatchThrottlingCheckResolverFrequency(config.CheckDispatchThrottling.Frequency),
@@ -712,19 +1056,48 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 		server.WithListObjectsIteratorCacheEnabled(config.ListObjectsIteratorCache.Enabled),
 		server.WithListObjectsIteratorCacheMaxResults(config.ListObjectsIteratorCache.MaxResults),
 		server.WithListObjectsIteratorCacheTTL(config.ListObjectsIteratorCache.TTL),
+		server.WithListObjectsQueryCacheEnabled(config.ListObjectsQueryCache.Enabled),
+		server.WithListObjectsQueryCacheTTL(config.ListObjectsQueryCache.TTL),
 		server.WithMaxChecksPerBatchCheck(config.MaxChecksPerBatchCheck),
 		server.WithMaxConcurrentChecksPerBatchCheck(config.MaxConcurrentChecksPerBatchCheck),
 		server.WithSharedIteratorEnabled(config.SharedIterator.Enabled),
 		server.WithSharedIteratorLimit(config.SharedIterator.Limit),
-		server.WithPlanner(planner.New(&planner.Config{
-			EvictionThreshold: config.Planner.EvictionThreshold,
-			CleanupInterval:   config.Planner.CleanupInterval,
-		})),
+		server.WithPlanner(checkPlanner),
+		server.WithListStrategiesPlanner(config.Planner.ListStrategies.Enabled, config.Planner.ListStrategies.RequiredValidations, config.Planner.ListStrategies.ShadowTimeout),
 		// The shared iterator watchdog timeout is set to config.RequestTimeout + 2 seconds
 		// to provide a small buffer for operations that might slightly exceed the request timeout.
 		server.WithSharedIteratorTTL(config.RequestTimeout+2*time.Second),
 		server.WithExperimentals(experimentals...),
 		server.WithAccessControlParams(config.AccessControl.Enabled, config.AccessControl.StoreID, config.AccessControl.ModelID, config.Authn.Method),
+		server.WithPeerDispatcher(peerDispatcher),
+		server.WithCheckPermissionIndexEnabled(config.CheckPermissionIndex.Enabled),
+		server.WithCheckPermissionIndexRefreshInterval(config.CheckPermissionIndex.RefreshInterval),
+		server.WithCheckPermissionIndexMaxStaleness(config.CheckPermissionIndex.MaxStaleness),
+		server.WithCheckPermissionIndexValkeyURI(config.CheckPermissionIndex.ValkeyURI),
+		server.WithCheckPermissionIndexValkeyKeyPrefix(config.CheckPermissionIndex.ValkeyKeyPrefix),
+		server.WithCheckSingleflightEnabled(config.CheckSingleflight.Enabled),
+		server.WithEdgeSyncEnabled(config.EdgeSync.Enabled),
+		server.WithEdgeSyncPollInterval(config.EdgeSync.PollInterval),
+		server.WithEdgeSyncCursorTTL(config.EdgeSync.CursorTTL),
+		server.WithCheckCostAdmissionEnabled(config.CheckCostAdmission.Enabled),
+		server.WithCheckCostAdmissionAction(config.CheckCostAdmission.Action),
+		server.WithCheckCostBudget(config.CheckCostAdmission.MaxReads, config.CheckCostAdmission.MaxDispatches),
+		server.WithCheckCostAdmissionDeprioritizedFrequency(config.CheckCostAdmission.DeprioritizedFrequency),
+		server.WithCheckCostStatisticsTTL(config.CheckCostAdmission.StatisticsTTL),
+		server.WithCheckCostStoreBudgets(checkCostStoreBudgets),
+		server.WithDatastoreAdaptiveConcurrency(
+			config.DatastoreAdaptiveConcurrency.Enabled,
+			config.DatastoreAdaptiveConcurrency.InitialLimit,
+			config.DatastoreAdaptiveConcurrency.MinLimit,
+			config.DatastoreAdaptiveConcurrency.LatencyThreshold,
+			config.DatastoreAdaptiveConcurrency.BackoffRatio,
+		),
+		server.WithFairDispatchThrottling(
+			config.FairDispatchThrottling.Enabled,
+			config.FairDispatchThrottling.PerClient,
+			fairDispatchThrottlingStoreWeights,
+		),
+		server.WithFairCheckDispatchThrottlingFrequency(config.FairDispatchThrottling.CheckFrequency),
 		server.WithContext(ctx),
 	)
 
@@ -737,9 +1110,30 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 		zap.Any("config", config),
 	)
 
+	if config.LoadShedding.Enabled {
+		// after the authentication, to classify the requests by client
+		loadShedding := s.loadSheddingConfig(config, svr)
+		serverOpts = append(serverOpts,
+			grpc.ChainUnaryInterceptor(loadShedding.NewUnaryLoadSheddingInterceptor()),
+			grpc.ChainStreamInterceptor(loadShedding.NewStreamLoadSheddingInterceptor()),
+		)
+	}
+
 	// nosemgrep: grpc-server-insecure-connection
 	grpcServer := grpc.NewServer(serverOpts...)
 	openfgav1.RegisterOpenFGAServiceServer(grpcServer, svr)
+	listcount.RegisterListCountServer(grpcServer, svr)
+	modeldiff.RegisterModelDiffServer(grpcServer, svr)
+	activemodel.RegisterActiveModelServer(grpcServer, svr)
+	assertionrun.RegisterAssertionRunServer(grpcServer, svr)
+	candidatemodel.RegisterCandidateModelServer(grpcServer, svr)
+	listrelations.RegisterRelationsServer(grpcServer, svr)
+	paginatedlist.RegisterPaginatedListServer(grpcServer, svr)
+	streamedbatchcheck.RegisterStreamedBatchCheckServer(grpcServer, svr)
+	streamedlistusers.RegisterStreamedListUsersServer(grpcServer, svr)
+	if config.EdgeSync.Enabled {
+		edgesync.RegisterSyncServer(grpcServer, svr)
+	}
 	healthServer := &health.Checker{TargetService: svr, TargetServiceName: openfgav1.OpenFGAService_ServiceDesc.ServiceName}
 	healthv1pb.RegisterHealthServer(grpcServer, healthServer)
 	reflection.Register(grpcServer)
@@ -759,6 +1153,14 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 		s.Logger.Info("gRPC server shut down.")
 	}()
 
+	var peerServer *grpc.Server
+	if config.PeerDispatch.Enabled {
+		peerServer, err = s.peerDispatchServer(config, svr, grpcCreds)
+		if err != nil {
+			return err
+		}
+	}
+
 	var httpServer *http.Server
 	if config.HTTP.Enabled {
 		runtime.DefaultContextTimeout = serverconfig.DefaultContextTimeout(config)
@@ -808,6 +1210,19 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 		if err := openfgav1.RegisterOpenFGAServiceHandler(ctx, mux, conn); err != nil {
 			return err
 		}
+		for _, registerHandler := range []func(*runtime.ServeMux, grpc.ClientConnInterface) error{
+			listrelations.RegisterRelationsHandler,
+			paginatedlist.RegisterPaginatedListHandler,
+			listcount.RegisterListCountHandler,
+			modeldiff.RegisterModelDiffHandler,
+			activemodel.RegisterActiveModelHandler,
+			candidatemodel.RegisterCandidateModelHandler,
+			assertionrun.RegisterAssertionRunHandler,
+		} {
+			if err := registerHandler(mux, conn); err != nil {
+				return err
+			}
+		}
 		handler := http.Handler(mux)
 
 		if config.Trace.Enabled {
@@ -969,8 +1384,14 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 
 	grpcServer.GracefulStop()
 
+	if peerServer != nil {
+		peerServer.GracefulStop()
+	}
+
 	svr.Close()
 
+	peerDispatcherCloser()
+
 	authenticator.Close()
 
 	if err := tracerProviderCloser(); err != nil {
@@ -1560,6 +1560,118 @@ func TestHTTPHeaders(t *testing.T) {
 	}
 }
 
+func TestHTTPGatewayJSONServices(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	cfg := testutils.MustDefaultConfigWithRandomPorts()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go func() {
+		if err := runServer(ctx, cfg); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	testutils.EnsureServiceHealthy(t, cfg.GRPC.Addr, cfg.HTTP.Addr, nil)
+
+	conn := testutils.CreateGrpcConnection(t, cfg.GRPC.Addr)
+	client := openfgav1.NewOpenFGAServiceClient(conn)
+
+	createStoreResp, err := client.CreateStore(context.Background(), &openfgav1.CreateStoreRequest{
+		Name: "openfga-demo",
+	})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	writeModelResp, err := client.WriteAuthorizationModel(context.Background(), &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:       storeID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: parser.MustTransformDSLToProto(`
+			model
+				schema 1.1
+			type user
+
+			type document
+				relations
+					define viewer: [user]`).GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+	authorizationModelID := writeModelResp.GetAuthorizationModelId()
+
+	_, err = client.Write(context.Background(), &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			{Object: "document:1", Relation: "viewer", User: "user:anne"},
+		}},
+	})
+	require.NoError(t, err)
+
+	httpClient := retryablehttp.NewClient()
+	t.Cleanup(httpClient.HTTPClient.CloseIdleConnections)
+
+	var testCases = map[string]struct {
+		httpVerb         string
+		httpPath         string
+		httpJSONBody     string
+		expectedStatus   int
+		expectedResponse string
+	}{
+		`list-relations`: {
+			httpVerb:         "POST",
+			httpPath:         fmt.Sprintf("http://%s/stores/%s/list-relations", cfg.HTTP.Addr, storeID),
+			httpJSONBody:     `{"object": "document:1", "user": "user:anne"}`,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"relations": {"viewer": true}}`,
+		},
+		`count-objects`: {
+			httpVerb:         "POST",
+			httpPath:         fmt.Sprintf("http://%s/stores/%s/count-objects", cfg.HTTP.Addr, storeID),
+			httpJSONBody:     `{"request": {"type": "document", "user": "user:anne", "relation": "viewer"}}`,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"count": 1, "complete": true}`,
+		},
+		`paginated-list-objects`: {
+			httpVerb:         "POST",
+			httpPath:         fmt.Sprintf("http://%s/stores/%s/paginated-list-objects", cfg.HTTP.Addr, storeID),
+			httpJSONBody:     `{"request": {"type": "document", "user": "user:anne", "relation": "viewer"}}`,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"objects": ["document:1"]}`,
+		},
+		`active-authorization-model`: {
+			httpVerb:         "GET",
+			httpPath:         fmt.Sprintf("http://%s/stores/%s/active-authorization-model", cfg.HTTP.Addr, storeID),
+			expectedStatus:   http.StatusOK,
+			expectedResponse: fmt.Sprintf(`{"authorization_model_id": %q, "pinned": false}`, authorizationModelID),
+		},
+		`invalid_store_id`: {
+			httpVerb:       "POST",
+			httpPath:       fmt.Sprintf("http://%s/stores/%s/list-relations", cfg.HTTP.Addr, "invalid"),
+			httpJSONBody:   `{"object": "document:1", "user": "user:anne"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req, err := retryablehttp.NewRequest(test.httpVerb, test.httpPath, strings.NewReader(test.httpJSONBody))
+			require.NoError(t, err, "Failed to construct request")
+
+			httpResponse, err := httpClient.Do(req)
+			require.NoError(t, err)
+			defer httpResponse.Body.Close()
+
+			body, err := io.ReadAll(httpResponse.Body)
+			require.NoError(t, err)
+			require.Equal(t, test.expectedStatus, httpResponse.StatusCode, string(body))
+			if test.expectedResponse != "" {
+				require.JSONEq(t, test.expectedResponse, string(body))
+			}
+		})
+	}
+}
+
 func TestServerContext_datastoreConfig(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -0,0 +1,108 @@
+// Package activemodel defines the gRPC service that pins the active authorization model of a store, i.e. the model
+// used by the requests that do not specify one. A new model can then be written and tested before it is promoted
+// explicitly, and rolled back by pinning the previous model again.
+package activemodel
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+
+	"github.com/openfga/openfga/internal/jsongrpc"
+)
+
+const (
+	// ServiceName is the fully qualified name of the active model gRPC service.
+	ServiceName = "openfga.activemodel.v1.ActiveModelService"
+
+	setActiveAuthorizationModelMethod = "/" + ServiceName + "/SetActiveAuthorizationModel"
+	getActiveAuthorizationModelMethod = "/" + ServiceName + "/GetActiveAuthorizationModel"
+
+	// codecName is the gRPC content-subtype of the active model service, whose messages are exchanged as JSON.
+	codecName = "openfga-activemodel-json"
+)
+
+func init() {
+	jsongrpc.RegisterCodec(codecName)
+}
+
+// SetActiveAuthorizationModelRequest pins the AuthorizationModelID model as the active model of the store. An empty
+// AuthorizationModelID unpins the active model, so that the latest model is the active one again.
+// RequirePassingAssertions only pins the model if it has assertions stored for it, and they all pass against it.
+// The server that serves the request uses the new active model right away, the other servers within seconds.
+type SetActiveAuthorizationModelRequest struct {
+	jsongrpc.StoreRequest
+	AuthorizationModelID     string `json:"authorization_model_id,omitempty"`
+	RequirePassingAssertions bool   `json:"require_passing_assertions,omitempty"`
+}
+
+// SetActiveAuthorizationModelResponse holds the model that was pinned before the request, if any, so that the
+// change can be rolled back.
+type SetActiveAuthorizationModelResponse struct {
+	PreviousAuthorizationModelID string `json:"previous_authorization_model_id,omitempty"`
+}
+
+// GetActiveAuthorizationModelRequest asks for the active model of the store.
+type GetActiveAuthorizationModelRequest struct {
+	jsongrpc.StoreRequest
+}
+
+// GetActiveAuthorizationModelResponse holds the model used by the requests that do not specify one. Pinned is false
+// when no model is pinned, in which case the latest model is used.
+type GetActiveAuthorizationModelResponse struct {
+	AuthorizationModelID string `json:"authorization_model_id"`
+	Pinned               bool   `json:"pinned"`
+}
+
+// ActiveModelServer is implemented by the node that serves SetActiveAuthorizationModel and
+// GetActiveAuthorizationModel.
+type ActiveModelServer interface {
+	SetActiveAuthorizationModel(ctx context.Context, req *SetActiveAuthorizationModelRequest) (*SetActiveAuthorizationModelResponse, error)
+	GetActiveAuthorizationModel(ctx context.Context, req *GetActiveAuthorizationModelRequest) (*GetActiveAuthorizationModelResponse, error)
+}
+
+// RegisterActiveModelServer registers the active model service on the provided gRPC server.
+func RegisterActiveModelServer(s grpc.ServiceRegistrar, srv ActiveModelServer) {
+	s.RegisterService(&ServiceDesc, srv)
+}
+
+// ServiceDesc is the grpc.ServiceDesc of the active model service.
+var ServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*ActiveModelServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SetActiveAuthorizationModel",
+			Handler:    jsongrpc.UnaryHandler(setActiveAuthorizationModelMethod, ActiveModelServer.SetActiveAuthorizationModel),
+		},
+		{
+			MethodName: "GetActiveAuthorizationModel",
+			Handler:    jsongrpc.UnaryHandler(getActiveAuthorizationModelMethod, ActiveModelServer.GetActiveAuthorizationModel),
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "internal/activemodel/service.go",
+}
+
+// SetActiveAuthorizationModel calls SetActiveAuthorizationModel on the provided connection.
+func SetActiveAuthorizationModel(ctx context.Context, conn grpc.ClientConnInterface, req *SetActiveAuthorizationModelRequest, opts ...grpc.CallOption) (*SetActiveAuthorizationModelResponse, error) {
+	return jsongrpc.Invoke[SetActiveAuthorizationModelResponse](ctx, conn, codecName, setActiveAuthorizationModelMethod, req, opts...)
+}
+
+// GetActiveAuthorizationModel calls GetActiveAuthorizationModel on the provided connection.
+func GetActiveAuthorizationModel(ctx context.Context, conn grpc.ClientConnInterface, req *GetActiveAuthorizationModelRequest, opts ...grpc.CallOption) (*GetActiveAuthorizationModelResponse, error) {
+	return jsongrpc.Invoke[GetActiveAuthorizationModelResponse](ctx, conn, codecName, getActiveAuthorizationModelMethod, req, opts...)
+}
+
+// RegisterActiveModelHandler exposes SetActiveAuthorizationModel and GetActiveAuthorizationModel on the HTTP gateway
+// mux, as PUT and GET /stores/{store_id}/active-authorization-model.
+func RegisterActiveModelHandler(mux *runtime.ServeMux, conn grpc.ClientConnInterface) error {
+	if err := jsongrpc.HandleHTTP[SetActiveAuthorizationModelRequest, SetActiveAuthorizationModelResponse](mux, http.MethodPut, "/stores/{store_id}/active-authorization-model", conn, codecName, setActiveAuthorizationModelMethod,
+		func(req *SetActiveAuthorizationModelRequest, storeID string) { req.StoreID = storeID }); err != nil {
+		return err
+	}
+	return jsongrpc.HandleHTTP[GetActiveAuthorizationModelRequest, GetActiveAuthorizationModelResponse](mux, http.MethodGet, "/stores/{store_id}/active-authorization-model", conn, codecName, getActiveAuthorizationModelMethod,
+		func(req *GetActiveAuthorizationModelRequest, storeID string) { req.StoreID = storeID })
+}
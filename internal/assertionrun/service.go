@@ -0,0 +1,93 @@
+// Package assertionrun defines the gRPC service that runs the assertions stored for an authorization model, through
+// the Check API, against the same or another model of the store. It turns the assertions written with
+// WriteAssertions into a regression test suite for the changes of the model.
+package assertionrun
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+
+	"github.com/openfga/openfga/internal/jsongrpc"
+)
+
+const (
+	// ServiceName is the fully qualified name of the assertion run gRPC service.
+	ServiceName = "openfga.assertionrun.v1.AssertionRunService"
+
+	runAssertionsMethod = "/" + ServiceName + "/RunAssertions"
+
+	// codecName is the gRPC content-subtype of the assertion run service, whose messages are exchanged as JSON.
+	codecName = "openfga-assertionrun-json"
+)
+
+func init() {
+	jsongrpc.RegisterCodec(codecName)
+}
+
+// RunAssertionsRequest runs the assertions stored for the AssertionsAuthorizationModelID model against the
+// AuthorizationModelID model. An empty AuthorizationModelID runs them against the active model of the store, and an
+// empty AssertionsAuthorizationModelID runs the assertions stored for the model they are run against.
+type RunAssertionsRequest struct {
+	jsongrpc.StoreRequest
+	AuthorizationModelID           string `json:"authorization_model_id,omitempty"`
+	AssertionsAuthorizationModelID string `json:"assertions_authorization_model_id,omitempty"`
+}
+
+// AssertionResult is the outcome of an assertion. Assertion is the JSON representation of the stored assertion.
+// Error is set when the assertion could not be checked, e.g. because it is not valid for the model, in which case
+// the assertion fails.
+type AssertionResult struct {
+	Assertion   json.RawMessage `json:"assertion"`
+	Expectation bool            `json:"expectation"`
+	Allowed     bool            `json:"allowed"`
+	Passed      bool            `json:"passed"`
+	Error       string          `json:"error,omitempty"`
+}
+
+// RunAssertionsResponse holds the outcome of each assertion, in the order they are stored.
+type RunAssertionsResponse struct {
+	AuthorizationModelID           string            `json:"authorization_model_id"`
+	AssertionsAuthorizationModelID string            `json:"assertions_authorization_model_id"`
+	Passed                         int               `json:"passed"`
+	Failed                         int               `json:"failed"`
+	Results                        []AssertionResult `json:"results"`
+}
+
+// AssertionRunServer is implemented by the node that serves RunAssertions.
+type AssertionRunServer interface {
+	RunAssertions(ctx context.Context, req *RunAssertionsRequest) (*RunAssertionsResponse, error)
+}
+
+// RegisterAssertionRunServer registers the assertion run service on the provided gRPC server.
+func RegisterAssertionRunServer(s grpc.ServiceRegistrar, srv AssertionRunServer) {
+	s.RegisterService(&ServiceDesc, srv)
+}
+
+// ServiceDesc is the grpc.ServiceDesc of the assertion run service.
+var ServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*AssertionRunServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RunAssertions",
+			Handler:    jsongrpc.UnaryHandler(runAssertionsMethod, AssertionRunServer.RunAssertions),
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "internal/assertionrun/service.go",
+}
+
+// RunAssertions calls RunAssertions on the provided connection.
+func RunAssertions(ctx context.Context, conn grpc.ClientConnInterface, req *RunAssertionsRequest, opts ...grpc.CallOption) (*RunAssertionsResponse, error) {
+	return jsongrpc.Invoke[RunAssertionsResponse](ctx, conn, codecName, runAssertionsMethod, req, opts...)
+}
+
+// RegisterAssertionRunHandler exposes RunAssertions on the HTTP gateway mux, as POST /stores/{store_id}/assertions/run.
+func RegisterAssertionRunHandler(mux *runtime.ServeMux, conn grpc.ClientConnInterface) error {
+	return jsongrpc.HandleHTTP[RunAssertionsRequest, RunAssertionsResponse](mux, http.MethodPost, "/stores/{store_id}/assertions/run", conn, codecName, runAssertionsMethod,
+		func(req *RunAssertionsRequest, storeID string) { req.StoreID = storeID })
+}
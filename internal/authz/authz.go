@@ -155,7 +155,7 @@ func (a *Authorizer) getRelation(apiMethod apimethod.APIMethod) (string, error)
 		return CanCallWrite, nil
 	case apimethod.ListObjects, apimethod.StreamedListObjects:
 		return CanCallListObjects, nil
-	case apimethod.Check, apimethod.BatchCheck:
+	case apimethod.Check, apimethod.BatchCheck, apimethod.StreamedBatchCheck:
 		return CanCallCheck, nil
 	case apimethod.ListUsers:
 		return CanCallListUsers, nil
@@ -13,9 +13,12 @@ var (
 	// Date is the date when the app was built.
 	Date = "unknown"
 
-	// MinimumSupportedDatastoreSchemaRevision refers to the minimum schema version that is required to run
+	// MinimumSupportedMySQLSchemaRevision, MinimumSupportedPostgresSchemaRevision and
+	// MinimumSupportedSQLiteSchemaRevision refer to the minimum schema version of each engine that is required to run
 	// this specific build of OpenFGA. Refer to the `assets/migrations` artifacts for more information.
-	MinimumSupportedDatastoreSchemaRevision int64 = 4
+	MinimumSupportedMySQLSchemaRevision    int64 = 10
+	MinimumSupportedPostgresSchemaRevision int64 = 9
+	MinimumSupportedSQLiteSchemaRevision   int64 = 8
 
 	ProjectName = "openfga"
 )
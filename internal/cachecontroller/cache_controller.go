@@ -93,10 +93,20 @@ func WithLogger(logger logger.Logger) InMemoryCacheControllerOpt {
 	}
 }
 
+// WithListObjectsCacheTTL sets the TTL of the cached ListObjects results, which enables their invalidation. See
+// storage.ListObjectsCacheEntry.
+func WithListObjectsCacheTTL(ttl time.Duration) InMemoryCacheControllerOpt {
+	return func(inm *InMemoryCacheController) {
+		inm.listObjectsCacheTTL = ttl
+	}
+}
+
 // InMemoryCacheController will invalidate cache iterator (InMemoryCache) and sub problem cache (CachedCheckResolver) entries
 // that are more recent than the last write for the specified store.
 // Note that the invalidation is done asynchronously, and only after a Check request is received.
 // It will be eventually consistent.
+// When the cache is shared by several replicas (see valkey.Cache), so are the changelog timestamps and the
+// invalidation entries it writes, and every replica invalidates its entries together.
 type InMemoryCacheController struct {
 	ds    storage.OpenFGADatastore
 	cache storage.InMemoryCache[any]
@@ -107,6 +117,7 @@ type InMemoryCacheController struct {
 	minInvalidationInterval time.Duration
 	queryCacheTTL           time.Duration
 	iteratorCacheTTL        time.Duration
+	listObjectsCacheTTL     time.Duration
 	inflightInvalidations   sync.Map
 	logger                  logger.Logger
 
@@ -278,6 +289,9 @@ func (c *InMemoryCacheController) findChangesAndInvalidateIfNecessary(parentCtx
 			telemetry.TraceError(span, msg.err)
 			// do not allow any cache read until next refresh
 			c.invalidateIteratorCache(storeID)
+			if c.listObjectsCacheTTL > 0 {
+				c.invalidateListObjectsCache(storeID)
+			}
 			return
 		}
 		changes = msg.changes
@@ -342,6 +356,10 @@ func (c *InMemoryCacheController) findChangesAndInvalidateIfNecessary(parentCtx
 		}
 	}
 
+	if c.listObjectsCacheTTL > 0 {
+		c.invalidateListObjectsCacheByChanges(storeID, changes)
+	}
+
 	if invalidationType != "none" {
 		cacheInvalidationCounter.Inc()
 	}
@@ -371,3 +389,42 @@ func (c *InMemoryCacheController) invalidateIteratorCacheByObjectRelation(storeI
 func (c *InMemoryCacheController) invalidateIteratorCacheByUserAndObjectType(storeID, user, objectType string, ts time.Time) {
 	c.cache.Set(storage.GetInvalidIteratorByUserObjectTypeCacheKeys(storeID, []string{user}, objectType)[0], &storage.InvalidEntityCacheEntry{LastModified: ts}, c.iteratorCacheTTL)
 }
+
+// invalidateListObjectsCacheByChanges invalidates the cached ListObjects results that depend on the tuples changed
+// within the TTL of the ListObjects cache, since the results cached before the older changes have expired. Only the
+// results of the relations that are evaluated with the tuples of the changed relations are invalidated, see
+// typesystem.GetTupleRelations.
+func (c *InMemoryCacheController) invalidateListObjectsCacheByChanges(storeID string, changes []*openfgav1.TupleChange) {
+	lastListObjectsInvalidation := time.Now().Add(-c.listObjectsCacheTTL)
+
+	// changes is ordered from most recent to oldest, see findChangesAndInvalidateIfNecessary.
+	idx := len(changes) - 1
+	for ; idx >= 0; idx-- {
+		if changes[idx].GetTimestamp().AsTime().After(lastListObjectsInvalidation) {
+			break
+		}
+	}
+
+	// when every change of a full page happened within the TTL, the changes of the next pages may have too.
+	if idx == len(changes)-1 && len(changes) >= storage.DefaultPageSize {
+		c.invalidateListObjectsCache(storeID)
+		return
+	}
+
+	lastModified := time.Now()
+	invalidated := make(map[string]struct{}, idx+1)
+	for ; idx >= 0; idx-- {
+		t := changes[idx].GetTupleKey()
+		key := storage.GetInvalidListObjectsByObjectTypeRelationCacheKey(storeID, tuple.GetType(t.GetObject()), t.GetRelation())
+		if _, ok := invalidated[key]; ok {
+			continue
+		}
+		invalidated[key] = struct{}{}
+		c.cache.Set(key, &storage.InvalidEntityCacheEntry{LastModified: lastModified}, c.listObjectsCacheTTL)
+	}
+}
+
+// invalidateListObjectsCache invalidates every cached ListObjects result of the store.
+func (c *InMemoryCacheController) invalidateListObjectsCache(storeID string) {
+	c.cache.Set(storage.GetInvalidListObjectsCacheKey(storeID), &storage.InvalidEntityCacheEntry{LastModified: time.Now()}, c.listObjectsCacheTTL)
+}
@@ -563,3 +563,58 @@ func TestInMemoryCacheController_findChangesAndInvalidateIfNecessary(t *testing.
 		})
 	}
 }
+
+func TestInMemoryCacheController_invalidateListObjectsCacheByChanges(t *testing.T) {
+	newTupleChange := func(object, relation string, age time.Duration) *openfgav1.TupleChange {
+		return &openfgav1.TupleChange{
+			Operation: openfgav1.TupleOperation_TUPLE_OPERATION_WRITE,
+			Timestamp: timestamppb.New(time.Now().Add(-age)),
+			TupleKey: &openfgav1.TupleKey{
+				Object:   object,
+				Relation: relation,
+				User:     "user:anne",
+			},
+		}
+	}
+
+	newCacheController := func() (*InMemoryCacheController, storage.InMemoryCache[any]) {
+		cache, err := storage.NewInMemoryLRUCache[any]()
+		require.NoError(t, err)
+		t.Cleanup(cache.Stop)
+		return &InMemoryCacheController{
+			cache:               cache,
+			listObjectsCacheTTL: 10 * time.Second,
+			logger:              logger.NewNoopLogger(),
+		}, cache
+	}
+
+	t.Run("invalidates_the_relations_changed_within_the_ttl", func(t *testing.T) {
+		cacheController, cache := newCacheController()
+
+		// ordered from most recent to oldest
+		cacheController.invalidateListObjectsCacheByChanges("1", []*openfgav1.TupleChange{
+			newTupleChange("document:1", "viewer", time.Second),
+			newTupleChange("folder:1", "viewer", 2*time.Second),
+			newTupleChange("document:2", "viewer", 3*time.Second),
+			newTupleChange("group:1", "member", 20*time.Second),
+		})
+
+		require.NotNil(t, cache.Get(storage.GetInvalidListObjectsByObjectTypeRelationCacheKey("1", "document", "viewer")))
+		require.NotNil(t, cache.Get(storage.GetInvalidListObjectsByObjectTypeRelationCacheKey("1", "folder", "viewer")))
+		require.Nil(t, cache.Get(storage.GetInvalidListObjectsByObjectTypeRelationCacheKey("1", "group", "member")))
+		require.Nil(t, cache.Get(storage.GetInvalidListObjectsByObjectTypeRelationCacheKey("2", "document", "viewer")))
+		require.Nil(t, cache.Get(storage.GetInvalidListObjectsCacheKey("1")))
+	})
+
+	t.Run("invalidates_the_store_when_a_full_page_is_within_the_ttl", func(t *testing.T) {
+		cacheController, cache := newCacheController()
+
+		changes := make([]*openfgav1.TupleChange, 0, storage.DefaultPageSize)
+		for i := 0; i < storage.DefaultPageSize; i++ {
+			changes = append(changes, newTupleChange("document:1", "viewer", time.Second))
+		}
+		cacheController.invalidateListObjectsCacheByChanges("1", changes)
+
+		require.NotNil(t, cache.Get(storage.GetInvalidListObjectsCacheKey("1")))
+	})
+}
@@ -0,0 +1,396 @@
+package candidatemodel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+const (
+	// MethodCheck is the method of the divergences of the Check requests.
+	MethodCheck = "check"
+	// MethodListObjects is the method of the divergences of the ListObjects requests.
+	MethodListObjects = "list_objects"
+)
+
+// Counts holds the number of requests evaluated against the candidate model, and how many of them diverged from
+// the active model or errored.
+type Counts struct {
+	Evaluated uint64 `json:"evaluated"`
+	Diverged  uint64 `json:"diverged"`
+	Errored   uint64 `json:"errored"`
+}
+
+// Divergence is a request whose outcome with the candidate model differs from its outcome with the active model.
+// Request is the JSON representation of the API request, which can be replayed against either model.
+type Divergence struct {
+	Time                       time.Time       `json:"time"`
+	Method                     string          `json:"method"`
+	Request                    json.RawMessage `json:"request"`
+	ActiveAuthorizationModelID string          `json:"active_authorization_model_id"`
+	ActiveResult               string          `json:"active_result"`
+	CandidateResult            string          `json:"candidate_result"`
+	// Delta holds the objects only returned with the active model prefixed with "-", and the objects only
+	// returned with the candidate model prefixed with "+".
+	Delta []string `json:"delta,omitempty"`
+}
+
+// Report summarizes the evaluation of the candidate model of a store since it was set. The counts are the ones of
+// all the servers that share the datastore. Divergences holds the most recent divergences recorded by the server that
+// returns the report, up to the maximum the Recorder keeps, from the oldest to the newest.
+type Report struct {
+	AuthorizationModelID string       `json:"authorization_model_id"`
+	SamplePercentage     int          `json:"sample_percentage"`
+	Since                time.Time    `json:"since"`
+	Check                Counts       `json:"check"`
+	ListObjects          Counts       `json:"list_objects"`
+	Divergences          []Divergence `json:"divergences"`
+}
+
+const (
+	// DefaultMaxDivergences is the number of most recent divergences kept for each store.
+	DefaultMaxDivergences = 100
+	// DefaultFlushInterval is how often the counts recorded by the server are added to the ones in the datastore.
+	DefaultFlushInterval = 10 * time.Second
+
+	// candidateCacheTTL bounds how long the candidate model of a store that was set, or removed, through another
+	// server is still sampled from the cache.
+	candidateCacheTTL = 10 * time.Second
+	flushTimeout      = 5 * time.Second
+)
+
+// candidate is the candidate model of a store, or the absence of one if modelID is empty.
+type candidate struct {
+	modelID          string
+	samplePercentage int
+}
+
+var _ storage.CacheItem = (*candidate)(nil)
+
+func (*candidate) CacheEntityType() string {
+	return "candidate_authorization_model"
+}
+
+// localReport holds what the server recorded for the candidate model of a store: the counts not added to the ones in
+// the datastore yet, and the most recent divergences.
+type localReport struct {
+	modelID     string
+	check       storage.CandidateModelCounts
+	listObjects storage.CandidateModelCounts
+	divergences []Divergence
+}
+
+// Recorder records how the decisions of the candidate model of each store compare with the ones of the active model
+// on the sampled requests of the store. The candidate models and the counts of their reports are persisted in the
+// datastore, so that they are shared by the servers, and the counts are added to the ones in the datastore every
+// flush interval. The candidate models are cached for a few seconds.
+type Recorder struct {
+	backend        storage.CandidateModelBackend
+	maxDivergences int
+	flushInterval  time.Duration
+	logger         logger.Logger
+
+	lookupGroup singleflight.Group
+	candidates  storage.InMemoryCache[*candidate]
+
+	mu      sync.Mutex
+	reports map[string]*localReport // GUARDED_BY(mu)
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+type RecorderOption func(*Recorder)
+
+// WithMaxDivergences sets the number of most recent divergences kept for each store.
+func WithMaxDivergences(maxDivergences int) RecorderOption {
+	return func(r *Recorder) {
+		r.maxDivergences = maxDivergences
+	}
+}
+
+// WithFlushInterval sets how often the counts recorded by the server are added to the ones in the datastore.
+func WithFlushInterval(interval time.Duration) RecorderOption {
+	return func(r *Recorder) {
+		r.flushInterval = interval
+	}
+}
+
+func WithLogger(l logger.Logger) RecorderOption {
+	return func(r *Recorder) {
+		r.logger = l
+	}
+}
+
+// NewRecorder returns a Recorder that persists the candidate models in backend. Close must be called to add the last
+// recorded counts to the ones in the datastore.
+func NewRecorder(backend storage.CandidateModelBackend, opts ...RecorderOption) (*Recorder, error) {
+	r := &Recorder{
+		backend:        backend,
+		maxDivergences: DefaultMaxDivergences,
+		flushInterval:  DefaultFlushInterval,
+		logger:         logger.NewNoopLogger(),
+		reports:        make(map[string]*localReport),
+		done:           make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	candidates, err := storage.NewInMemoryLRUCache[*candidate]()
+	if err != nil {
+		return nil, err
+	}
+	r.candidates = candidates
+
+	r.wg.Add(1)
+	go r.flushLoop()
+	return r, nil
+}
+
+// Set sets the candidate model of the store, and the percentage, between 0 and 100, of its requests that are also
+// evaluated against it. The report of the previous candidate, if any, is discarded.
+func (r *Recorder) Set(ctx context.Context, storeID, modelID string, samplePercentage int) error {
+	samplePercentage = min(max(samplePercentage, 0), 100)
+	if err := r.backend.WriteCandidateAuthorizationModel(ctx, storeID, modelID, samplePercentage); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.reports, storeID)
+	r.candidates.Set(storeID, &candidate{modelID: modelID, samplePercentage: samplePercentage}, candidateCacheTTL)
+	return nil
+}
+
+// Clear removes the candidate model of the store and its report.
+func (r *Recorder) Clear(ctx context.Context, storeID string) error {
+	return r.Set(ctx, storeID, "", 0)
+}
+
+// Sample returns the candidate model of the store if the request is sampled to be evaluated against it.
+func (r *Recorder) Sample(ctx context.Context, storeID string) (string, bool) {
+	c, err := r.candidate(ctx, storeID)
+	if err != nil {
+		r.logger.WarnWithContext(ctx, "failed to read the candidate authorization model",
+			zap.String("store_id", storeID),
+			zap.Error(err),
+		)
+		return "", false
+	}
+
+	if c.modelID == "" || c.samplePercentage <= 0 || rand.Intn(100) >= c.samplePercentage {
+		return "", false
+	}
+	return c.modelID, true
+}
+
+// candidate returns the candidate model of the store, from the cache or else from the datastore.
+func (r *Recorder) candidate(ctx context.Context, storeID string) (*candidate, error) {
+	if c := r.candidates.Get(storeID); c != nil {
+		return c, nil
+	}
+
+	v, err, _ := r.lookupGroup.Do(storeID, func() (interface{}, error) {
+		stored, err := r.backend.ReadCandidateAuthorizationModel(ctx, storeID)
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			return &candidate{}, nil
+		case err != nil:
+			return nil, err
+		}
+		return &candidate{modelID: stored.ModelID, samplePercentage: stored.SamplePercentage}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c := v.(*candidate)
+	r.candidates.Set(storeID, c, candidateCacheTTL)
+	return c, nil
+}
+
+// Report returns the report of the candidate model of the store, or storage.ErrNotFound if it has none. The counts
+// recorded by the server are added to the ones in the datastore first.
+func (r *Recorder) Report(ctx context.Context, storeID string) (*Report, error) {
+	r.flush(ctx, storeID)
+
+	stored, err := r.backend.ReadCandidateAuthorizationModel(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		AuthorizationModelID: stored.ModelID,
+		SamplePercentage:     stored.SamplePercentage,
+		Since:                stored.Since,
+		Check:                Counts(stored.Check),
+		ListObjects:          Counts(stored.ListObjects),
+		Divergences:          []Divergence{},
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if local, ok := r.reports[storeID]; ok && local.modelID == stored.ModelID {
+		report.Divergences = append(report.Divergences, local.divergences...)
+	}
+	return report, nil
+}
+
+// Record records the outcome of a request evaluated against the candidate model. divergence is nil when the
+// outcome matches the one of the active model. Outcomes of a previous candidate model are ignored.
+func (r *Recorder) Record(storeID, candidateModelID, method string, divergence *Divergence, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if c := r.candidates.Get(storeID); c != nil && c.modelID != candidateModelID {
+		return
+	}
+
+	report, ok := r.reports[storeID]
+	if !ok || report.modelID != candidateModelID {
+		report = &localReport{modelID: candidateModelID}
+		r.reports[storeID] = report
+	}
+
+	counts := &report.check
+	if method == MethodListObjects {
+		counts = &report.listObjects
+	}
+
+	counts.Evaluated++
+	switch {
+	case err != nil:
+		counts.Errored++
+	case divergence != nil:
+		counts.Diverged++
+		if r.maxDivergences <= 0 {
+			return
+		}
+		if len(report.divergences) >= r.maxDivergences {
+			report.divergences = report.divergences[1:]
+		}
+		report.divergences = append(report.divergences, *divergence)
+	}
+}
+
+func (r *Recorder) flushLoop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+			r.flush(ctx, "")
+			cancel()
+		}
+	}
+}
+
+// flush adds the counts recorded by the server for the store, or for every store if storeID is empty, to the ones in
+// the datastore. The counts that fail to be added are kept for the next flush.
+func (r *Recorder) flush(ctx context.Context, storeID string) {
+	type pending struct {
+		storeID string
+		localReport
+	}
+
+	r.mu.Lock()
+	var flushed []pending
+	for id, report := range r.reports {
+		if storeID != "" && id != storeID {
+			continue
+		}
+		if report.check == (storage.CandidateModelCounts{}) && report.listObjects == (storage.CandidateModelCounts{}) {
+			if len(report.divergences) == 0 {
+				delete(r.reports, id)
+			}
+			continue
+		}
+		flushed = append(flushed, pending{storeID: id, localReport: localReport{
+			modelID:     report.modelID,
+			check:       report.check,
+			listObjects: report.listObjects,
+		}})
+		report.check, report.listObjects = storage.CandidateModelCounts{}, storage.CandidateModelCounts{}
+	}
+	r.mu.Unlock()
+
+	for _, p := range flushed {
+		err := r.backend.AddCandidateAuthorizationModelCounts(ctx, p.storeID, p.modelID, p.check, p.listObjects)
+		if err == nil {
+			continue
+		}
+
+		r.logger.Warn("failed to add the counts of the candidate authorization model",
+			zap.String("store_id", p.storeID),
+			zap.String("candidate_model_id", p.modelID),
+			zap.Error(err),
+		)
+		r.mu.Lock()
+		if report, ok := r.reports[p.storeID]; ok && report.modelID == p.modelID {
+			report.check = addCounts(report.check, p.check)
+			report.listObjects = addCounts(report.listObjects, p.listObjects)
+		}
+		r.mu.Unlock()
+	}
+}
+
+func addCounts(a, b storage.CandidateModelCounts) storage.CandidateModelCounts {
+	return storage.CandidateModelCounts{
+		Evaluated: a.Evaluated + b.Evaluated,
+		Diverged:  a.Diverged + b.Diverged,
+		Errored:   a.Errored + b.Errored,
+	}
+}
+
+// Close adds the last recorded counts to the ones in the datastore, and releases the cache. The datastore must be
+// closed after.
+func (r *Recorder) Close() {
+	close(r.done)
+	r.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+	r.flush(ctx, "")
+	r.candidates.Stop()
+}
+
+// ObjectsDelta returns the objects only in active prefixed with "-", followed by the objects only in candidate
+// prefixed with "+". It is empty when both hold the same objects.
+func ObjectsDelta(active, candidate []string) []string {
+	activeSet := make(map[string]struct{}, len(active))
+	for _, object := range active {
+		activeSet[object] = struct{}{}
+	}
+	candidateSet := make(map[string]struct{}, len(candidate))
+	for _, object := range candidate {
+		candidateSet[object] = struct{}{}
+	}
+
+	delta := make([]string, 0)
+	for _, object := range active {
+		if _, ok := candidateSet[object]; !ok {
+			delta = append(delta, "-"+object)
+		}
+	}
+	for _, object := range candidate {
+		if _, ok := activeSet[object]; !ok {
+			delta = append(delta, "+"+object)
+		}
+	}
+	return delta
+}
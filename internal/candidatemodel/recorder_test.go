@@ -0,0 +1,144 @@
+package candidatemodel
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+)
+
+func newTestRecorder(t *testing.T, ds storage.CandidateModelBackend, opts ...RecorderOption) *Recorder {
+	t.Helper()
+
+	r, err := NewRecorder(ds, opts...)
+	require.NoError(t, err)
+	t.Cleanup(r.Close)
+	return r
+}
+
+func TestRecorder(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+
+	t.Run("sample", func(t *testing.T) {
+		ds := memory.New()
+		t.Cleanup(ds.Close)
+		r := newTestRecorder(t, ds)
+
+		_, ok := r.Sample(ctx, "store")
+		require.False(t, ok)
+
+		require.NoError(t, r.Set(ctx, "store", "model", 100))
+		modelID, ok := r.Sample(ctx, "store")
+		require.True(t, ok)
+		require.Equal(t, "model", modelID)
+
+		require.NoError(t, r.Set(ctx, "store", "model", 0))
+		_, ok = r.Sample(ctx, "store")
+		require.False(t, ok)
+
+		require.NoError(t, r.Set(ctx, "store", "model", 200))
+		report, err := r.Report(ctx, "store")
+		require.NoError(t, err)
+		require.Equal(t, 100, report.SamplePercentage)
+
+		require.NoError(t, r.Clear(ctx, "store"))
+		_, ok = r.Sample(ctx, "store")
+		require.False(t, ok)
+		_, err = r.Report(ctx, "store")
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("record", func(t *testing.T) {
+		ds := memory.New()
+		t.Cleanup(ds.Close)
+		r := newTestRecorder(t, ds, WithMaxDivergences(2))
+		require.NoError(t, r.Set(ctx, "store", "model", 100))
+
+		r.Record("store", "model", MethodCheck, nil, nil)
+		r.Record("store", "model", MethodCheck, &Divergence{ActiveResult: "1"}, nil)
+		r.Record("store", "model", MethodListObjects, &Divergence{ActiveResult: "2"}, nil)
+		r.Record("store", "model", MethodListObjects, &Divergence{ActiveResult: "3"}, nil)
+		r.Record("store", "model", MethodListObjects, nil, errors.New("error"))
+
+		// the outcomes of another candidate or store are ignored
+		r.Record("store", "other", MethodCheck, &Divergence{ActiveResult: "4"}, nil)
+		r.Record("other", "model", MethodCheck, &Divergence{ActiveResult: "5"}, nil)
+
+		report, err := r.Report(ctx, "store")
+		require.NoError(t, err)
+		require.Equal(t, Counts{Evaluated: 2, Diverged: 1}, report.Check)
+		require.Equal(t, Counts{Evaluated: 3, Diverged: 2, Errored: 1}, report.ListObjects)
+		require.Equal(t, []Divergence{{ActiveResult: "2"}, {ActiveResult: "3"}}, report.Divergences)
+
+		// the report is a copy, and the counts are only added once
+		report.Divergences[0].ActiveResult = "changed"
+		report, err = r.Report(ctx, "store")
+		require.NoError(t, err)
+		require.Equal(t, "2", report.Divergences[0].ActiveResult)
+		require.Equal(t, Counts{Evaluated: 2, Diverged: 1}, report.Check)
+
+		// setting the candidate again resets its report
+		require.NoError(t, r.Set(ctx, "store", "model", 100))
+		report, err = r.Report(ctx, "store")
+		require.NoError(t, err)
+		require.Equal(t, Counts{}, report.Check)
+		require.Empty(t, report.Divergences)
+	})
+
+	t.Run("servers_share_the_candidate_and_its_counts", func(t *testing.T) {
+		ds := memory.New()
+		t.Cleanup(ds.Close)
+		first := newTestRecorder(t, ds)
+		second := newTestRecorder(t, ds, WithFlushInterval(10*time.Millisecond))
+
+		require.NoError(t, first.Set(ctx, "store", "model", 100))
+		modelID, ok := second.Sample(ctx, "store")
+		require.True(t, ok)
+		require.Equal(t, "model", modelID)
+
+		first.Record("store", "model", MethodCheck, nil, nil)
+		second.Record("store", "model", MethodCheck, &Divergence{ActiveResult: "1"}, nil)
+
+		// the counts of the second server are flushed in the background
+		require.Eventually(t, func() bool {
+			report, err := first.Report(ctx, "store")
+			require.NoError(t, err)
+			return report.Check == Counts{Evaluated: 2, Diverged: 1}
+		}, time.Second, 10*time.Millisecond)
+
+		report, err := second.Report(ctx, "store")
+		require.NoError(t, err)
+		require.Equal(t, []Divergence{{ActiveResult: "1"}}, report.Divergences)
+	})
+
+	t.Run("close_flushes_the_counts", func(t *testing.T) {
+		ds := memory.New()
+		t.Cleanup(ds.Close)
+		r, err := NewRecorder(ds)
+		require.NoError(t, err)
+
+		require.NoError(t, r.Set(ctx, "store", "model", 100))
+		r.Record("store", "model", MethodListObjects, nil, nil)
+		r.Close()
+
+		stored, err := ds.ReadCandidateAuthorizationModel(ctx, "store")
+		require.NoError(t, err)
+		require.Equal(t, storage.CandidateModelCounts{Evaluated: 1}, stored.ListObjects)
+	})
+}
+
+func TestObjectsDelta(t *testing.T) {
+	require.Empty(t, ObjectsDelta(nil, nil))
+	require.Empty(t, ObjectsDelta([]string{"a", "b"}, []string{"b", "a"}))
+	require.Equal(t, []string{"-a", "+c", "+d"}, ObjectsDelta([]string{"a", "b"}, []string{"b", "c", "d"}))
+}
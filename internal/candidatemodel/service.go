@@ -0,0 +1,104 @@
+// Package candidatemodel evaluates a candidate authorization model of a store against its live traffic before the
+// model is activated. A sampled fraction of the Check and ListObjects requests of the store are also evaluated
+// against the candidate model in the background, and the requests whose outcome it would change are recorded in a
+// report. The package also defines the gRPC service that sets the candidate model and returns its report.
+package candidatemodel
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+
+	"github.com/openfga/openfga/internal/jsongrpc"
+)
+
+const (
+	// ServiceName is the fully qualified name of the candidate model gRPC service.
+	ServiceName = "openfga.candidatemodel.v1.CandidateModelService"
+
+	setCandidateAuthorizationModelMethod       = "/" + ServiceName + "/SetCandidateAuthorizationModel"
+	getCandidateAuthorizationModelReportMethod = "/" + ServiceName + "/GetCandidateAuthorizationModelReport"
+
+	// codecName is the gRPC content-subtype of the candidate model service, whose messages are exchanged as JSON.
+	codecName = "openfga-candidatemodel-json"
+)
+
+func init() {
+	jsongrpc.RegisterCodec(codecName)
+}
+
+// SetCandidateAuthorizationModelRequest sets the AuthorizationModelID model as the candidate model of the store,
+// against which SamplePercentage percent of its requests are also evaluated. An empty AuthorizationModelID removes
+// the candidate model of the store.
+type SetCandidateAuthorizationModelRequest struct {
+	jsongrpc.StoreRequest
+	AuthorizationModelID string `json:"authorization_model_id,omitempty"`
+	SamplePercentage     uint32 `json:"sample_percentage,omitempty"`
+}
+
+type SetCandidateAuthorizationModelResponse struct{}
+
+// GetCandidateAuthorizationModelReportRequest asks for the report of the candidate model of the store.
+type GetCandidateAuthorizationModelReportRequest struct {
+	jsongrpc.StoreRequest
+}
+
+// GetCandidateAuthorizationModelReportResponse holds the report of the candidate model of the store. The counts are
+// the ones of all the servers, the divergences the ones recorded by the server that served the request.
+type GetCandidateAuthorizationModelReportResponse struct {
+	Report
+}
+
+// CandidateModelServer is implemented by the node that serves SetCandidateAuthorizationModel and
+// GetCandidateAuthorizationModelReport.
+type CandidateModelServer interface {
+	SetCandidateAuthorizationModel(ctx context.Context, req *SetCandidateAuthorizationModelRequest) (*SetCandidateAuthorizationModelResponse, error)
+	GetCandidateAuthorizationModelReport(ctx context.Context, req *GetCandidateAuthorizationModelReportRequest) (*GetCandidateAuthorizationModelReportResponse, error)
+}
+
+// RegisterCandidateModelServer registers the candidate model service on the provided gRPC server.
+func RegisterCandidateModelServer(s grpc.ServiceRegistrar, srv CandidateModelServer) {
+	s.RegisterService(&ServiceDesc, srv)
+}
+
+// ServiceDesc is the grpc.ServiceDesc of the candidate model service.
+var ServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*CandidateModelServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SetCandidateAuthorizationModel",
+			Handler:    jsongrpc.UnaryHandler(setCandidateAuthorizationModelMethod, CandidateModelServer.SetCandidateAuthorizationModel),
+		},
+		{
+			MethodName: "GetCandidateAuthorizationModelReport",
+			Handler:    jsongrpc.UnaryHandler(getCandidateAuthorizationModelReportMethod, CandidateModelServer.GetCandidateAuthorizationModelReport),
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "internal/candidatemodel/service.go",
+}
+
+// SetCandidateAuthorizationModel calls SetCandidateAuthorizationModel on the provided connection.
+func SetCandidateAuthorizationModel(ctx context.Context, conn grpc.ClientConnInterface, req *SetCandidateAuthorizationModelRequest, opts ...grpc.CallOption) (*SetCandidateAuthorizationModelResponse, error) {
+	return jsongrpc.Invoke[SetCandidateAuthorizationModelResponse](ctx, conn, codecName, setCandidateAuthorizationModelMethod, req, opts...)
+}
+
+// GetCandidateAuthorizationModelReport calls GetCandidateAuthorizationModelReport on the provided connection.
+func GetCandidateAuthorizationModelReport(ctx context.Context, conn grpc.ClientConnInterface, req *GetCandidateAuthorizationModelReportRequest, opts ...grpc.CallOption) (*GetCandidateAuthorizationModelReportResponse, error) {
+	return jsongrpc.Invoke[GetCandidateAuthorizationModelReportResponse](ctx, conn, codecName, getCandidateAuthorizationModelReportMethod, req, opts...)
+}
+
+// RegisterCandidateModelHandler exposes SetCandidateAuthorizationModel and GetCandidateAuthorizationModelReport on the
+// HTTP gateway mux, as PUT /stores/{store_id}/candidate-authorization-model and
+// GET /stores/{store_id}/candidate-authorization-model/report.
+func RegisterCandidateModelHandler(mux *runtime.ServeMux, conn grpc.ClientConnInterface) error {
+	if err := jsongrpc.HandleHTTP[SetCandidateAuthorizationModelRequest, SetCandidateAuthorizationModelResponse](mux, http.MethodPut, "/stores/{store_id}/candidate-authorization-model", conn, codecName, setCandidateAuthorizationModelMethod,
+		func(req *SetCandidateAuthorizationModelRequest, storeID string) { req.StoreID = storeID }); err != nil {
+		return err
+	}
+	return jsongrpc.HandleHTTP[GetCandidateAuthorizationModelReportRequest, GetCandidateAuthorizationModelReportResponse](mux, http.MethodGet, "/stores/{store_id}/candidate-authorization-model/report", conn, codecName, getCandidateAuthorizationModelReportMethod,
+		func(req *GetCandidateAuthorizationModelReportRequest, storeID string) { req.StoreID = storeID })
+}
@@ -0,0 +1,151 @@
+package checkcost
+
+import (
+	"context"
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/internal/throttler"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// Action is what happens to a check whose estimated cost exceeds the budget of its store.
+type Action string
+
+const (
+	// ActionReject rejects the check with ErrBudgetExceeded.
+	ActionReject Action = "reject"
+	// ActionDeprioritize makes the check wait for its turn in a throttler before it is resolved.
+	ActionDeprioritize Action = "deprioritize"
+)
+
+// Decision is the outcome of the admission of a check.
+type Decision string
+
+const (
+	DecisionAdmitted      Decision = "admitted"
+	DecisionDeprioritized Decision = "deprioritized"
+	DecisionRejected      Decision = "rejected"
+	// DecisionUnestimated is the decision for the checks whose cost can't be estimated, which are admitted.
+	DecisionUnestimated Decision = "unestimated"
+)
+
+var ErrBudgetExceeded = errors.New("the estimated cost of the check exceeds the budget of the store")
+
+var (
+	admissionCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "check_cost_admission_count",
+		Help:      "The total number of checks admitted by their estimated cost, labeled by decision (admitted, deprioritized, rejected or unestimated).",
+	}, []string{"decision"})
+
+	estimatedCostHistogram = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:                       build.ProjectName,
+		Name:                            "check_estimated_cost",
+		Help:                            "The estimated cost of checks before they are resolved, labeled by kind (reads or dispatches).",
+		Buckets:                         []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000, 5000, 10000},
+		NativeHistogramBucketFactor:     1.1,
+		NativeHistogramMaxBucketNumber:  100,
+		NativeHistogramMinResetDuration: 0,
+	}, []string{"kind"})
+)
+
+// Budget limits the estimated cost of a check. Zero limits are unlimited.
+type Budget struct {
+	MaxReads      uint32
+	MaxDispatches uint32
+}
+
+// Admission admits the checks whose estimated cost is within the budget of their store, and rejects or
+// deprioritizes the others.
+type Admission struct {
+	estimator     *Estimator
+	action        Action
+	defaultBudget Budget
+	storeBudgets  map[string]Budget
+	throttler     throttler.Throttler
+}
+
+type AdmissionOption func(*Admission)
+
+// WithAction sets what happens to the checks that exceed their budget. It defaults to ActionDeprioritize.
+func WithAction(action Action) AdmissionOption {
+	return func(a *Admission) {
+		a.action = action
+	}
+}
+
+// WithDefaultBudget sets the budget of the stores without a budget of their own.
+func WithDefaultBudget(budget Budget) AdmissionOption {
+	return func(a *Admission) {
+		a.defaultBudget = budget
+	}
+}
+
+// WithStoreBudgets sets the budgets of specific stores, by store ID.
+func WithStoreBudgets(budgets map[string]Budget) AdmissionOption {
+	return func(a *Admission) {
+		a.storeBudgets = budgets
+	}
+}
+
+// WithThrottler sets the throttler that deprioritized checks wait in.
+func WithThrottler(t throttler.Throttler) AdmissionOption {
+	return func(a *Admission) {
+		a.throttler = t
+	}
+}
+
+func NewAdmission(estimator *Estimator, opts ...AdmissionOption) *Admission {
+	a := &Admission{
+		estimator: estimator,
+		action:    ActionDeprioritize,
+		throttler: throttler.NewNoopThrottler(),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Admit estimates the cost of checking tk in the store and decides whether the check can be resolved. It
+// returns ErrBudgetExceeded if the check is rejected, and waits for the turn of the check if it is
+// deprioritized.
+func (a *Admission) Admit(ctx context.Context, typesys *typesystem.TypeSystem, storeID string, tk *openfgav1.TupleKey) (Cost, Decision, error) {
+	cost, ok := a.estimator.Estimate(typesys, storeID, tk)
+	if !ok {
+		admissionCounter.WithLabelValues(string(DecisionUnestimated)).Inc()
+		return cost, DecisionUnestimated, nil
+	}
+	estimatedCostHistogram.WithLabelValues("reads").Observe(cost.Reads)
+	estimatedCostHistogram.WithLabelValues("dispatches").Observe(cost.Dispatches)
+
+	budget, ok := a.storeBudgets[storeID]
+	if !ok {
+		budget = a.defaultBudget
+	}
+
+	if !cost.Exceeds(budget) {
+		admissionCounter.WithLabelValues(string(DecisionAdmitted)).Inc()
+		return cost, DecisionAdmitted, nil
+	}
+
+	if a.action == ActionReject {
+		admissionCounter.WithLabelValues(string(DecisionRejected)).Inc()
+		return cost, DecisionRejected, ErrBudgetExceeded
+	}
+
+	admissionCounter.WithLabelValues(string(DecisionDeprioritized)).Inc()
+	a.throttler.Throttle(ctx)
+	return cost, DecisionDeprioritized, ctx.Err()
+}
+
+// Close releases the resources of the admission.
+func (a *Admission) Close() {
+	a.throttler.Close()
+}
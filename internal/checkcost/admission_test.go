@@ -0,0 +1,97 @@
+package checkcost
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/openfga/openfga/internal/throttler"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestAdmission(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	typesys, err := typesystem.New(testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type group
+			relations
+				define member: [user, group#member]
+		type document
+			relations
+				define viewer: [user, group#member]`))
+	require.NoError(t, err)
+
+	estimator := NewEstimator(staticStatistics(10))
+	cheap := tuple.NewTupleKey("group:1", "member", "user:anne")
+	expensive := tuple.NewTupleKey("document:1", "viewer", "user:anne")
+	budget := Budget{MaxDispatches: 100}
+
+	t.Run("admitted_within_budget", func(t *testing.T) {
+		a := NewAdmission(estimator, WithAction(ActionReject), WithDefaultBudget(budget))
+		defer a.Close()
+
+		cost, decision, err := a.Admit(context.Background(), typesys, "store", cheap)
+		require.NoError(t, err)
+		require.Equal(t, DecisionAdmitted, decision)
+		require.False(t, cost.Exceeds(budget))
+	})
+
+	t.Run("rejected_above_budget", func(t *testing.T) {
+		a := NewAdmission(estimator, WithAction(ActionReject), WithDefaultBudget(budget))
+		defer a.Close()
+
+		cost, decision, err := a.Admit(context.Background(), typesys, "store", expensive)
+		require.ErrorIs(t, err, ErrBudgetExceeded)
+		require.Equal(t, DecisionRejected, decision)
+		require.True(t, cost.Exceeds(budget))
+	})
+
+	t.Run("store_budget_overrides_default_budget", func(t *testing.T) {
+		a := NewAdmission(estimator,
+			WithAction(ActionReject),
+			WithDefaultBudget(budget),
+			WithStoreBudgets(map[string]Budget{"large": {}}),
+		)
+		defer a.Close()
+
+		_, decision, err := a.Admit(context.Background(), typesys, "large", expensive)
+		require.NoError(t, err)
+		require.Equal(t, DecisionAdmitted, decision)
+	})
+
+	t.Run("deprioritized_above_budget", func(t *testing.T) {
+		a := NewAdmission(estimator,
+			WithDefaultBudget(budget),
+			WithThrottler(throttler.NewConstantRateThrottler(time.Millisecond, "test")),
+		)
+		defer a.Close()
+
+		_, decision, err := a.Admit(context.Background(), typesys, "store", expensive)
+		require.NoError(t, err)
+		require.Equal(t, DecisionDeprioritized, decision)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, _, err = NewAdmission(estimator, WithDefaultBudget(budget)).Admit(ctx, typesys, "store", expensive)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("unestimated_without_relation", func(t *testing.T) {
+		a := NewAdmission(estimator, WithAction(ActionReject), WithDefaultBudget(Budget{MaxReads: 1}))
+		defer a.Close()
+
+		_, decision, err := a.Admit(context.Background(), typesys, "store", tuple.NewTupleKey("document:1", "unknown", "user:anne"))
+		require.NoError(t, err)
+		require.Equal(t, DecisionUnestimated, decision)
+	})
+}
@@ -0,0 +1,180 @@
+// Package checkcost estimates the cost of a check before it is resolved, and admits checks according to the
+// budget of their store.
+package checkcost
+
+import (
+	"github.com/openfga/language/pkg/go/graph"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// DefaultRecursionDepth is the number of times a recursive relation is assumed to recurse.
+const DefaultRecursionDepth = 5
+
+// Cost is the estimated cost of resolving a check.
+type Cost struct {
+	// Dispatches is the number of sub-problems that the check dispatches.
+	Dispatches float64
+	// Reads is the number of datastore reads of the check.
+	Reads float64
+}
+
+func (c Cost) add(o Cost) Cost {
+	return Cost{Dispatches: c.Dispatches + o.Dispatches, Reads: c.Reads + o.Reads}
+}
+
+func (c Cost) scale(f float64) Cost {
+	return Cost{Dispatches: c.Dispatches * f, Reads: c.Reads * f}
+}
+
+// Exceeds returns whether c exceeds the reads or the dispatches of budget.
+func (c Cost) Exceeds(budget Budget) bool {
+	return (budget.MaxReads > 0 && c.Reads > float64(budget.MaxReads)) ||
+		(budget.MaxDispatches > 0 && c.Dispatches > float64(budget.MaxDispatches))
+}
+
+// Statistics describes the cardinality of the relations of the objects of a store.
+type Statistics interface {
+	// Fanout returns the average number of tuples of relation per object of objectType in the store.
+	Fanout(storeID, objectType, relation string) float64
+}
+
+// Estimator estimates the cost of checks from the weighted graph of their model and the cardinality of the
+// relations they traverse.
+type Estimator struct {
+	statistics     Statistics
+	recursionDepth float64
+}
+
+type EstimatorOption func(*Estimator)
+
+// WithRecursionDepth sets the number of times a recursive relation is assumed to recurse.
+func WithRecursionDepth(depth uint32) EstimatorOption {
+	return func(e *Estimator) {
+		e.recursionDepth = float64(depth)
+	}
+}
+
+func NewEstimator(statistics Statistics, opts ...EstimatorOption) *Estimator {
+	e := &Estimator{
+		statistics:     statistics,
+		recursionDepth: DefaultRecursionDepth,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Estimate returns the estimated cost of checking tk in the store, or false if the model of typesys has no
+// weighted graph to estimate it from.
+//
+// Each edge of the weighted graph that leads to the type of the user costs one read, and as many dispatches as
+// the fanout of the relation it reads when it leads to another relation, e.g. a userset or a tuple to userset.
+// All the branches of unions, intersections and exclusions are counted, as if none of them short-circuits.
+func (e *Estimator) Estimate(typesys *typesystem.TypeSystem, storeID string, tk *openfgav1.TupleKey) (Cost, bool) {
+	wg := typesys.GetWeightedGraph()
+	if wg == nil {
+		return Cost{}, false
+	}
+
+	node, ok := wg.GetNodeByID(tuple.ToObjectRelationString(tuple.GetType(tk.GetObject()), tk.GetRelation()))
+	if !ok {
+		return Cost{}, false
+	}
+
+	userObj, userRel := tuple.SplitObjectRelation(tk.GetUser())
+	est := &estimation{
+		Estimator: e,
+		wg:        wg,
+		storeID:   storeID,
+		userType:  tuple.GetType(userObj),
+		// the weights of the graph are keyed by the terminal types, the edges that lead to a userset aren't
+		// told apart and are all counted
+		anyPath:  userRel != "",
+		memo:     map[string]Cost{},
+		visiting: map[string]bool{},
+		cycles:   map[string]bool{},
+	}
+	return est.node(node), true
+}
+
+type estimation struct {
+	*Estimator
+	wg       *graph.WeightedAuthorizationModelGraph
+	storeID  string
+	userType string
+	anyPath  bool
+
+	memo     map[string]Cost
+	visiting map[string]bool
+	cycles   map[string]bool
+}
+
+func (e *estimation) node(node *graph.WeightedAuthorizationModelNode) Cost {
+	id := node.GetUniqueLabel()
+	if cost, ok := e.memo[id]; ok {
+		return cost
+	}
+	if e.visiting[id] {
+		// the cost of the recursion is accounted for once the node that starts it is estimated
+		e.cycles[id] = true
+		return Cost{}
+	}
+	e.visiting[id] = true
+
+	edges, _ := e.wg.GetEdgesFromNode(node)
+
+	var cost Cost
+	readsUser := false
+	for _, edge := range edges {
+		if _, ok := edge.GetWeight(e.userType); !ok && !e.anyPath {
+			continue
+		}
+
+		if edge.GetEdgeType() == graph.DirectEdge && edge.GetTo().GetNodeType() != graph.SpecificTypeAndRelation {
+			// the user, or a wildcard of its type, is looked up once for all the directly related types
+			readsUser = true
+			continue
+		}
+		cost = cost.add(e.edge(edge))
+	}
+	if readsUser {
+		cost.Reads++
+	}
+
+	delete(e.visiting, id)
+	if e.cycles[id] {
+		cost = cost.scale(e.recursionDepth)
+	}
+	e.memo[id] = cost
+	return cost
+}
+
+func (e *estimation) edge(edge *graph.WeightedAuthorizationModelEdge) Cost {
+	to := edge.GetTo()
+
+	switch edge.GetEdgeType() {
+	case graph.DirectEdge:
+		// the usersets of the relation are read, and each of them is dispatched
+		objectType, relation := tuple.SplitObjectRelation(edge.GetRelationDefinition())
+		fanout := e.statistics.Fanout(e.storeID, objectType, relation)
+		return Cost{Reads: 1}.add(Cost{Dispatches: 1}.add(e.node(to)).scale(fanout))
+	case graph.TTUEdge:
+		// the tupleset is read, and the computed relation of each of its objects is dispatched
+		objectType, relation := tuple.SplitObjectRelation(edge.GetTuplesetRelation())
+		fanout := e.statistics.Fanout(e.storeID, objectType, relation)
+		return Cost{Reads: 1}.add(Cost{Dispatches: 1}.add(e.node(to)).scale(fanout))
+	case graph.RewriteEdge, graph.ComputedEdge:
+		if to.GetNodeType() == graph.SpecificTypeAndRelation {
+			// computed usersets are dispatched
+			return Cost{Dispatches: 1}.add(e.node(to))
+		}
+		return e.node(to)
+	default:
+		return e.node(to)
+	}
+}
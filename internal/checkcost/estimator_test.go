@@ -0,0 +1,90 @@
+package checkcost
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// staticStatistics has the same fanout for every relation.
+type staticStatistics float64
+
+func (s staticStatistics) Fanout(string, string, string) float64 { return float64(s) }
+
+func TestEstimate(t *testing.T) {
+	typesys, err := typesystem.New(testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type group
+			relations
+				define member: [user, group#member]
+		type folder
+			relations
+				define viewer: [user]
+		type document
+			relations
+				define parent: [folder]
+				define owner: [user]
+				define editor: [user] or owner
+				define viewer: [user, group#member] or editor or viewer from parent`))
+	require.NoError(t, err)
+
+	e := NewEstimator(staticStatistics(2), WithRecursionDepth(3))
+
+	t.Run("direct", func(t *testing.T) {
+		cost, ok := e.Estimate(typesys, "store", tuple.NewTupleKey("document:1", "owner", "user:anne"))
+		require.True(t, ok)
+		require.Equal(t, Cost{Reads: 1}, cost)
+	})
+
+	t.Run("computed_userset", func(t *testing.T) {
+		// [user] reads once, owner is dispatched and reads once
+		cost, ok := e.Estimate(typesys, "store", tuple.NewTupleKey("document:1", "editor", "user:anne"))
+		require.True(t, ok)
+		require.Equal(t, Cost{Reads: 2, Dispatches: 1}, cost)
+	})
+
+	t.Run("recursion", func(t *testing.T) {
+		// [user] and group#member read once each, and the 2 groups read are dispatched, 3 times over
+		cost, ok := e.Estimate(typesys, "store", tuple.NewTupleKey("group:1", "member", "user:anne"))
+		require.True(t, ok)
+		require.Equal(t, Cost{Reads: 6, Dispatches: 6}, cost)
+	})
+
+	t.Run("all_branches", func(t *testing.T) {
+		// [user]: 1 read
+		// group#member: 1 read, and 2 dispatches of group#member: 13 reads and 14 dispatches
+		// editor: 1 dispatch of editor: 2 reads and 2 dispatches
+		// viewer from parent: 1 read, and 2 dispatches of folder#viewer: 3 reads and 2 dispatches
+		cost, ok := e.Estimate(typesys, "store", tuple.NewTupleKey("document:1", "viewer", "user:anne"))
+		require.True(t, ok)
+		require.Equal(t, Cost{Reads: 19, Dispatches: 18}, cost)
+	})
+
+	t.Run("cost_grows_with_fanout", func(t *testing.T) {
+		tk := tuple.NewTupleKey("document:1", "viewer", "user:anne")
+		low, _ := NewEstimator(staticStatistics(2)).Estimate(typesys, "store", tk)
+		high, _ := NewEstimator(staticStatistics(20)).Estimate(typesys, "store", tk)
+		require.Greater(t, high.Reads, low.Reads)
+		require.Greater(t, high.Dispatches, low.Dispatches)
+	})
+
+	t.Run("unknown_relation", func(t *testing.T) {
+		_, ok := e.Estimate(typesys, "store", tuple.NewTupleKey("document:1", "unknown", "user:anne"))
+		require.False(t, ok)
+	})
+}
+
+func TestCostExceeds(t *testing.T) {
+	cost := Cost{Reads: 10, Dispatches: 5}
+
+	require.False(t, cost.Exceeds(Budget{}))
+	require.False(t, cost.Exceeds(Budget{MaxReads: 10, MaxDispatches: 5}))
+	require.True(t, cost.Exceeds(Budget{MaxReads: 9}))
+	require.True(t, cost.Exceeds(Budget{MaxDispatches: 4}))
+}
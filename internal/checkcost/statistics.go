@@ -0,0 +1,277 @@
+package checkcost
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"go.uber.org/zap"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+const (
+	// DefaultFanout is the fanout of a relation that wasn't sampled yet.
+	DefaultFanout = 10
+	// DefaultSampleSize is the number of tuples read to sample the fanout of a relation.
+	DefaultSampleSize = 1000
+	// DefaultStatisticsTTL is how long a sampled fanout is used before it is sampled again.
+	DefaultStatisticsTTL = 10 * time.Minute
+	// DefaultStatisticsMaxEntries is the number of relations whose fanout is kept.
+	DefaultStatisticsMaxEntries = 10000
+
+	maxConcurrentSamples = 4
+	sampleTimeout        = 5 * time.Second
+	// sampleChunks is the number of pages a sample is read in, each from its own offset of the relation.
+	sampleChunks = 4
+)
+
+// SampledStatistics samples the fanout of the relations of a store from the datastore. A relation is sampled
+// in the background the first time its fanout is needed, and again once the sample is older than the TTL;
+// meanwhile its fanout is the default one, or the one of the previous sample. The samples are kept in an
+// LRU cache, and dropped once they are not sampled again shortly after the TTL.
+type SampledStatistics struct {
+	datastore     storage.RelationshipTupleReader
+	defaultFanout float64
+	sampleSize    int32
+	ttl           time.Duration
+	maxEntries    int64
+	logger        logger.Logger
+
+	// guards the creation of the samples in the cache
+	mu      sync.Mutex
+	samples storage.InMemoryCache[*sample]
+	// limits the number of relations sampled at the same time
+	sampling chan struct{}
+	wg       sync.WaitGroup
+}
+
+var _ Statistics = (*SampledStatistics)(nil)
+
+type sampleKey struct {
+	storeID    string
+	objectType string
+	relation   string
+}
+
+func (k sampleKey) String() string {
+	return k.storeID + "/" + k.objectType + "#" + k.relation
+}
+
+type sample struct {
+	mu        sync.Mutex
+	fanout    float64
+	sampledAt time.Time
+	inFlight  bool
+}
+
+var _ storage.CacheItem = (*sample)(nil)
+
+func (*sample) CacheEntityType() string {
+	return "check_cost_fanout"
+}
+
+type SampledStatisticsOption func(*SampledStatistics)
+
+// WithDefaultFanout sets the fanout of the relations that weren't sampled yet.
+func WithDefaultFanout(fanout float64) SampledStatisticsOption {
+	return func(s *SampledStatistics) {
+		s.defaultFanout = fanout
+	}
+}
+
+// WithSampleSize sets the number of tuples read to sample the fanout of a relation.
+func WithSampleSize(size int32) SampledStatisticsOption {
+	return func(s *SampledStatistics) {
+		s.sampleSize = size
+	}
+}
+
+// WithStatisticsTTL sets how long a sampled fanout is used before it is sampled again.
+func WithStatisticsTTL(ttl time.Duration) SampledStatisticsOption {
+	return func(s *SampledStatistics) {
+		s.ttl = ttl
+	}
+}
+
+// WithStatisticsMaxEntries sets the number of relations whose fanout is kept.
+func WithStatisticsMaxEntries(maxEntries int64) SampledStatisticsOption {
+	return func(s *SampledStatistics) {
+		s.maxEntries = maxEntries
+	}
+}
+
+func WithStatisticsLogger(l logger.Logger) SampledStatisticsOption {
+	return func(s *SampledStatistics) {
+		s.logger = l
+	}
+}
+
+func NewSampledStatistics(ds storage.RelationshipTupleReader, opts ...SampledStatisticsOption) (*SampledStatistics, error) {
+	s := &SampledStatistics{
+		datastore:     ds,
+		defaultFanout: DefaultFanout,
+		sampleSize:    DefaultSampleSize,
+		ttl:           DefaultStatisticsTTL,
+		maxEntries:    DefaultStatisticsMaxEntries,
+		logger:        logger.NewNoopLogger(),
+		sampling:      make(chan struct{}, maxConcurrentSamples),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	samples, err := storage.NewInMemoryLRUCache([]storage.InMemoryLRUCacheOpt[*sample]{
+		storage.WithMaxCacheSize[*sample](s.maxEntries),
+	}...)
+	if err != nil {
+		return nil, err
+	}
+	s.samples = samples
+	return s, nil
+}
+
+// entryTTL is how long a sample is kept in the cache: past the TTL, it is still used while it is sampled again.
+func (s *SampledStatistics) entryTTL() time.Duration {
+	return s.ttl + sampleTimeout
+}
+
+// Fanout returns the sampled fanout of relation for the objects of objectType in the store, and samples it
+// again in the background if it is missing or expired.
+func (s *SampledStatistics) Fanout(storeID, objectType, relation string) float64 {
+	key := sampleKey{storeID: storeID, objectType: objectType, relation: relation}
+	s.mu.Lock()
+	smp := s.samples.Get(key.String())
+	if smp == nil {
+		smp = &sample{fanout: s.defaultFanout}
+		s.samples.Set(key.String(), smp, s.entryTTL())
+	}
+	s.mu.Unlock()
+
+	smp.mu.Lock()
+	defer smp.mu.Unlock()
+	if !smp.inFlight && time.Since(smp.sampledAt) > s.ttl {
+		select {
+		case s.sampling <- struct{}{}:
+			smp.inFlight = true
+			s.wg.Add(1)
+			go s.sample(key, smp)
+		default:
+			// too many relations are being sampled, it is sampled on a next call
+		}
+	}
+	return smp.fanout
+}
+
+func (s *SampledStatistics) sample(key sampleKey, smp *sample) {
+	defer s.wg.Done()
+	defer func() { <-s.sampling }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), sampleTimeout)
+	defer cancel()
+
+	tuples, err := s.read(ctx, key)
+
+	// keep the sample for another TTL, even if it was evicted while it was read
+	s.mu.Lock()
+	s.samples.Set(key.String(), smp, s.entryTTL())
+	s.mu.Unlock()
+
+	smp.mu.Lock()
+	defer smp.mu.Unlock()
+	smp.inFlight = false
+	smp.sampledAt = time.Now()
+	if err != nil {
+		s.logger.Warn("failed to sample the fanout of a relation",
+			zap.String("store_id", key.storeID),
+			zap.String("object_type", key.objectType),
+			zap.String("relation", key.relation),
+			zap.Error(err),
+		)
+		return
+	}
+
+	objects := make(map[string]struct{}, len(tuples))
+	for _, t := range tuples {
+		objects[t.GetKey().GetObject()] = struct{}{}
+	}
+	if len(objects) == 0 {
+		smp.fanout = 0
+		return
+	}
+	smp.fanout = float64(len(tuples)) / float64(len(objects))
+}
+
+// read reads the tuples of the sample of the relation. When the relation has more tuples than a page, and the
+// continuation tokens of the datastore are ULIDs, i.e. the tuples are read in the order they were written, the
+// sample is spread over the relation: each page after the first starts at a random point between the first tuple
+// and now, so that the fanout isn't the one of the oldest objects only. Otherwise, the first tuples are read.
+func (s *SampledStatistics) read(ctx context.Context, key sampleKey) ([]*openfgav1.Tuple, error) {
+	filter := storage.ReadFilter{
+		Object:   tuple.BuildObject(key.objectType, ""),
+		Relation: key.relation,
+	}
+	pageSize := max(s.sampleSize/sampleChunks, 1)
+
+	tuples, token, err := s.datastore.ReadPage(ctx, key.storeID, filter, storage.ReadPageOptions{
+		Pagination: storage.NewPaginationOptions(pageSize, ""),
+	})
+	if err != nil || token == "" {
+		return tuples, err
+	}
+
+	first, err := ulid.Parse(token)
+	if err != nil {
+		rest, _, err := s.datastore.ReadPage(ctx, key.storeID, filter, storage.ReadPageOptions{
+			Pagination: storage.NewPaginationOptions(s.sampleSize-int32(len(tuples)), token),
+		})
+		return append(tuples, rest...), err
+	}
+
+	seen := make(map[string]struct{}, s.sampleSize)
+	for _, t := range tuples {
+		seen[tuple.TupleKeyToString(t.GetKey())] = struct{}{}
+	}
+	since, until := first.Time(), ulid.Timestamp(time.Now())
+	for i := 1; i < sampleChunks && since < until; i++ {
+		//nolint:gosec // the offset doesn't need a secure source of randomness.
+		from, err := ulid.New(since+uint64(rand.Int63n(int64(until-since))), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		page, _, err := s.datastore.ReadPage(ctx, key.storeID, filter, storage.ReadPageOptions{
+			Pagination: storage.NewPaginationOptions(pageSize, from.String()),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range page {
+			tk := tuple.TupleKeyToString(t.GetKey())
+			if _, ok := seen[tk]; ok {
+				// the pages overlap
+				continue
+			}
+			seen[tk] = struct{}{}
+			tuples = append(tuples, t)
+		}
+	}
+	return tuples, nil
+}
+
+// Wait waits for the samples in flight.
+func (s *SampledStatistics) Wait() {
+	s.wg.Wait()
+}
+
+// Close waits for the samples in flight, e.g. before the datastore is closed, and releases the cache.
+func (s *SampledStatistics) Close() {
+	s.wg.Wait()
+	s.samples.Stop()
+}
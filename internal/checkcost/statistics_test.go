@@ -0,0 +1,107 @@
+package checkcost
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"go.uber.org/mock/gomock"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/mocks"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestSampledStatistics(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+	require.NoError(t, ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("group:1", "member", "user:1"),
+		tuple.NewTupleKey("group:1", "member", "user:2"),
+		tuple.NewTupleKey("group:1", "member", "user:3"),
+		tuple.NewTupleKey("group:2", "member", "user:1"),
+		tuple.NewTupleKey("group:2", "owner", "user:1"),
+	}))
+
+	stats, err := NewSampledStatistics(ds, WithDefaultFanout(7), WithStatisticsTTL(time.Hour))
+	require.NoError(t, err)
+	t.Cleanup(stats.Close)
+
+	// the first call returns the default fanout and samples the relation in the background
+	require.InDelta(t, 7, stats.Fanout(storeID, "group", "member"), 0)
+	stats.Wait()
+	require.InDelta(t, 2, stats.Fanout(storeID, "group", "member"), 0)
+
+	require.InDelta(t, 7, stats.Fanout(storeID, "group", "admin"), 0)
+	stats.Wait()
+	require.InDelta(t, 0, stats.Fanout(storeID, "group", "admin"), 0)
+
+	t.Run("sampled_again_once_expired", func(t *testing.T) {
+		stats, err := NewSampledStatistics(ds, WithStatisticsTTL(time.Nanosecond))
+		require.NoError(t, err)
+		t.Cleanup(stats.Close)
+
+		stats.Fanout(storeID, "group", "member")
+		stats.Wait()
+		require.NoError(t, ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+			tuple.NewTupleKey("group:2", "member", "user:2"),
+			tuple.NewTupleKey("group:2", "member", "user:3"),
+		}))
+
+		require.InDelta(t, 2, stats.Fanout(storeID, "group", "member"), 0)
+		stats.Wait()
+		require.InDelta(t, 3, stats.Fanout(storeID, "group", "member"), 0)
+	})
+}
+
+func TestSampledStatisticsSpreadsTheSample(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctrl := gomock.NewController(t)
+	ds := mocks.NewMockRelationshipTupleReader(ctrl)
+
+	first := ulid.MustNew(ulid.Timestamp(time.Now().Add(-time.Hour)), nil)
+	ds.EXPECT().ReadPage(gomock.Any(), "store", storage.ReadFilter{Object: "group:", Relation: "member"}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ storage.ReadFilter, options storage.ReadPageOptions) ([]*openfgav1.Tuple, string, error) {
+			require.Equal(t, 2, options.Pagination.PageSize)
+			if options.Pagination.From == "" {
+				return []*openfgav1.Tuple{
+					{Key: tuple.NewTupleKey("group:1", "member", "user:1")},
+					{Key: tuple.NewTupleKey("group:1", "member", "user:2")},
+				}, first.String(), nil
+			}
+
+			from, err := ulid.Parse(options.Pagination.From)
+			require.NoError(t, err)
+			require.GreaterOrEqual(t, from.Time(), first.Time())
+			require.LessOrEqual(t, from.Time(), ulid.Timestamp(time.Now()))
+			return []*openfgav1.Tuple{
+				// overlaps the first page
+				{Key: tuple.NewTupleKey("group:1", "member", "user:1")},
+				{Key: tuple.NewTupleKey("group:"+from.String(), "member", "user:1")},
+			}, "", nil
+		}).Times(sampleChunks)
+
+	stats, err := NewSampledStatistics(ds, WithSampleSize(2*sampleChunks))
+	require.NoError(t, err)
+	t.Cleanup(stats.Close)
+
+	stats.Fanout("store", "group", "member")
+	stats.Wait()
+	// group:1 with 2 tuples, and one object with 1 tuple per other page
+	require.InDelta(t, float64(2+sampleChunks-1)/float64(sampleChunks), stats.Fanout("store", "group", "member"), 0.001)
+}
@@ -0,0 +1,106 @@
+package containers
+
+import (
+	"math"
+	"math/bits"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// hyperLogLogPrecision is the number of bits of the hashes that select a register. Its 2^14 registers estimate
+// cardinalities with a standard error of about 0.8%.
+const hyperLogLogPrecision = 14
+
+// HyperLogLog estimates the number of distinct strings added to it in constant memory, whatever their number.
+//
+// A HyperLogLog is not thread safe.
+type HyperLogLog struct {
+	registers []uint8
+}
+
+func NewHyperLogLog() *HyperLogLog {
+	return &HyperLogLog{
+		registers: make([]uint8, 1<<hyperLogLogPrecision),
+	}
+}
+
+// Add adds v to the estimate.
+func (h *HyperLogLog) Add(v string) {
+	x := xxhash.Sum64String(v)
+	i := x >> (64 - hyperLogLogPrecision)
+	// the guard bit bounds the number of leading zeros of the bits that don't select the register
+	w := x<<hyperLogLogPrecision | 1<<(hyperLogLogPrecision-1)
+	if rank := uint8(bits.LeadingZeros64(w)) + 1; rank > h.registers[i] {
+		h.registers[i] = rank
+	}
+}
+
+// Count returns the estimated number of distinct strings added.
+func (h *HyperLogLog) Count() uint64 {
+	m := float64(len(h.registers))
+
+	var sum float64
+	var zeros int
+	for _, r := range h.registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	estimate := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		// linear counting is more accurate for the small cardinalities
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+// DistinctCounter counts the distinct strings added to it.
+type DistinctCounter interface {
+	Add(v string) error
+	Count() uint64
+	// Approximate returns whether Count is an estimate.
+	Approximate() bool
+	// Close releases the resources of the counter.
+	Close() error
+}
+
+// NewDistinctCounter returns a counter that estimates the count with a HyperLogLog if approximate, and otherwise
+// counts exactly with a SpillSet that keeps up to maxInMemory strings in memory.
+func NewDistinctCounter(approximate bool, maxInMemory int) DistinctCounter {
+	if approximate {
+		return &approximateCounter{h: NewHyperLogLog()}
+	}
+	return &exactCounter{s: NewSpillSet(maxInMemory, "")}
+}
+
+type approximateCounter struct {
+	h *HyperLogLog
+}
+
+func (c *approximateCounter) Add(v string) error {
+	c.h.Add(v)
+	return nil
+}
+
+func (c *approximateCounter) Count() uint64 { return c.h.Count() }
+
+func (c *approximateCounter) Approximate() bool { return true }
+
+func (c *approximateCounter) Close() error { return nil }
+
+type exactCounter struct {
+	s *SpillSet
+}
+
+func (c *exactCounter) Add(v string) error {
+	_, err := c.s.Add(v)
+	return err
+}
+
+func (c *exactCounter) Count() uint64 { return uint64(c.s.Len()) }
+
+func (c *exactCounter) Approximate() bool { return false }
+
+func (c *exactCounter) Close() error { return c.s.Close() }
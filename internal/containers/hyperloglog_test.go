@@ -0,0 +1,39 @@
+package containers
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	for _, n := range []int{0, 10, 1000, 200000} {
+		t.Run(fmt.Sprintf("%d_distinct", n), func(t *testing.T) {
+			h := NewHyperLogLog()
+			for i := 0; i < n; i++ {
+				// every string twice
+				h.Add(fmt.Sprintf("document:%d", i))
+				h.Add(fmt.Sprintf("document:%d", i))
+			}
+			require.InEpsilon(t, float64(n)+1, float64(h.Count())+1, 0.02)
+		})
+	}
+}
+
+func TestDistinctCounter(t *testing.T) {
+	for _, approximate := range []bool{false, true} {
+		t.Run(fmt.Sprintf("approximate_%t", approximate), func(t *testing.T) {
+			c := NewDistinctCounter(approximate, 10)
+			t.Cleanup(func() {
+				require.NoError(t, c.Close())
+			})
+
+			for i := 0; i < 100; i++ {
+				require.NoError(t, c.Add(fmt.Sprintf("user:%d", i%50)))
+			}
+			require.Equal(t, approximate, c.Approximate())
+			require.Equal(t, uint64(50), c.Count())
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package containers
+
+import (
+	"container/heap"
+	"slices"
+)
+
+// Smallest keeps the n smallest distinct strings added to it, e.g. to build a page of ordered results while the
+// results are found in any order, without holding more than the page in memory. A Smallest is not thread safe.
+type Smallest struct {
+	n      int
+	values maxStringHeap
+	kept   map[string]struct{}
+}
+
+// NewSmallest returns a Smallest that keeps up to n strings.
+func NewSmallest(n int) *Smallest {
+	return &Smallest{
+		n:      n,
+		values: make(maxStringHeap, 0, n),
+		kept:   make(map[string]struct{}, n),
+	}
+}
+
+// Add adds v, unless it is already kept or n smaller strings are. The greatest kept string is evicted if v is
+// smaller.
+func (s *Smallest) Add(v string) {
+	if _, ok := s.kept[v]; ok || s.n <= 0 {
+		return
+	}
+
+	if s.values.Len() == s.n {
+		if v >= s.values[0] {
+			return
+		}
+		delete(s.kept, heap.Pop(&s.values).(string))
+	}
+
+	heap.Push(&s.values, v)
+	s.kept[v] = struct{}{}
+}
+
+// Len returns the number of kept strings.
+func (s *Smallest) Len() int {
+	return s.values.Len()
+}
+
+// Sorted returns the kept strings in ascending order.
+func (s *Smallest) Sorted() []string {
+	values := slices.Clone([]string(s.values))
+	slices.Sort(values)
+	return values
+}
+
+// maxStringHeap is a heap of strings whose root is the greatest one.
+type maxStringHeap []string
+
+func (h maxStringHeap) Len() int { return len(h) }
+
+func (h maxStringHeap) Less(i, j int) bool { return h[i] > h[j] }
+
+func (h maxStringHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *maxStringHeap) Push(x any) {
+	*h = append(*h, x.(string))
+}
+
+func (h *maxStringHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
@@ -0,0 +1,216 @@
+package containers
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"os"
+	"slices"
+)
+
+// spillSetMaxRuns is the number of files a SpillSet spills to before merging them into one, which bounds the
+// number of files a lookup searches.
+const spillSetMaxRuns = 8
+
+type spillKey [16]byte
+
+// SpillSet is a set of strings that keeps up to a number of them in memory and spills the others to sorted files
+// on disk, so that its memory is bounded whatever the number of strings it holds. It holds 128 bit hashes of the
+// strings rather than the strings, which bounds the size of its entries too.
+//
+// A SpillSet is not thread safe. Close removes its files.
+type SpillSet struct {
+	maxInMemory int
+	dir         string
+	mem         map[spillKey]struct{}
+	runs        []*spillRun
+	len         int
+}
+
+// spillRun is a file of sorted keys.
+type spillRun struct {
+	f *os.File
+	n int64
+}
+
+// NewSpillSet returns a SpillSet that keeps up to maxInMemory strings in memory and spills the others to files in
+// dir, or in the default directory for temporary files if dir is empty. A maxInMemory of 0 keeps every string in
+// memory.
+func NewSpillSet(maxInMemory int, dir string) *SpillSet {
+	return &SpillSet{
+		maxInMemory: maxInMemory,
+		dir:         dir,
+		mem:         map[spillKey]struct{}{},
+	}
+}
+
+// Add adds v to the set, and returns whether v was not in the set yet.
+func (s *SpillSet) Add(v string) (bool, error) {
+	sum := sha256.Sum256([]byte(v))
+	key := spillKey(sum[:len(spillKey{})])
+
+	if _, ok := s.mem[key]; ok {
+		return false, nil
+	}
+	for _, r := range s.runs {
+		found, err := r.contains(key)
+		if err != nil {
+			return false, err
+		}
+		if found {
+			return false, nil
+		}
+	}
+
+	s.mem[key] = struct{}{}
+	s.len++
+
+	if s.maxInMemory > 0 && len(s.mem) >= s.maxInMemory {
+		if err := s.spill(); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// Len returns the number of strings in the set.
+func (s *SpillSet) Len() int {
+	return s.len
+}
+
+// Close removes the files of the set.
+func (s *SpillSet) Close() error {
+	var errs error
+	for _, r := range s.runs {
+		errs = errors.Join(errs, r.remove())
+	}
+	s.runs = nil
+	s.mem = map[spillKey]struct{}{}
+	s.len = 0
+	return errs
+}
+
+// spill writes the keys held in memory to a new run, and merges the runs once there are too many of them.
+func (s *SpillSet) spill() error {
+	keys := make([]spillKey, 0, len(s.mem))
+	for key := range s.mem {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b spillKey) int {
+		return bytes.Compare(a[:], b[:])
+	})
+
+	r, err := s.writeRun(slices.Values(keys))
+	if err != nil {
+		return err
+	}
+	s.runs = append(s.runs, r)
+	clear(s.mem)
+
+	if len(s.runs) > spillSetMaxRuns {
+		return s.merge()
+	}
+	return nil
+}
+
+// merge merges the runs into a single one. The runs hold distinct keys, since a key is only added once.
+func (s *SpillSet) merge() error {
+	readers := make([]*bufio.Reader, len(s.runs))
+	heads := make([]*spillKey, len(s.runs))
+	var readErr error
+
+	next := func(i int) {
+		var key spillKey
+		if _, err := io.ReadFull(readers[i], key[:]); err != nil {
+			if !errors.Is(err, io.EOF) {
+				readErr = err
+			}
+			heads[i] = nil
+			return
+		}
+		heads[i] = &key
+	}
+
+	for i, r := range s.runs {
+		if _, err := r.f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		readers[i] = bufio.NewReader(r.f)
+		next(i)
+	}
+
+	merged, err := s.writeRun(func(yield func(spillKey) bool) {
+		for readErr == nil {
+			smallest := -1
+			for i, head := range heads {
+				if head != nil && (smallest < 0 || bytes.Compare(head[:], heads[smallest][:]) < 0) {
+					smallest = i
+				}
+			}
+			if smallest < 0 || !yield(*heads[smallest]) {
+				return
+			}
+			next(smallest)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if readErr != nil {
+		return errors.Join(readErr, merged.remove())
+	}
+
+	var errs error
+	for _, r := range s.runs {
+		errs = errors.Join(errs, r.remove())
+	}
+	s.runs = []*spillRun{merged}
+	return errs
+}
+
+func (s *SpillSet) writeRun(keys func(yield func(spillKey) bool)) (*spillRun, error) {
+	f, err := os.CreateTemp(s.dir, "openfga-spillset-*")
+	if err != nil {
+		return nil, err
+	}
+	r := &spillRun{f: f}
+
+	w := bufio.NewWriter(f)
+	for key := range keys {
+		if _, err := w.Write(key[:]); err != nil {
+			return nil, errors.Join(err, r.remove())
+		}
+		r.n++
+	}
+	if err := w.Flush(); err != nil {
+		return nil, errors.Join(err, r.remove())
+	}
+	return r, nil
+}
+
+// contains searches the key in the sorted keys of the run.
+func (r *spillRun) contains(key spillKey) (bool, error) {
+	var buf spillKey
+	lo, hi := int64(0), r.n
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		if _, err := r.f.ReadAt(buf[:], mid*int64(len(buf))); err != nil {
+			return false, err
+		}
+		switch c := bytes.Compare(buf[:], key[:]); {
+		case c == 0:
+			return true, nil
+		case c < 0:
+			lo = mid + 1
+		default:
+			hi = mid
+		}
+	}
+	return false, nil
+}
+
+func (r *spillRun) remove() error {
+	return errors.Join(r.f.Close(), os.Remove(r.f.Name()))
+}
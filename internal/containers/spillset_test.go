@@ -0,0 +1,46 @@
+package containers
+
+import (
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpillSet(t *testing.T) {
+	dir := t.TempDir()
+	s := NewSpillSet(10, dir)
+
+	for i := range 1000 {
+		added, err := s.Add("user:" + strconv.Itoa(i))
+		require.NoError(t, err)
+		require.True(t, added)
+	}
+	require.Equal(t, 1000, s.Len())
+
+	// the spilled runs were merged
+	require.LessOrEqual(t, len(s.runs), spillSetMaxRuns)
+	require.LessOrEqual(t, len(s.mem), 10)
+
+	for i := range 1000 {
+		added, err := s.Add("user:" + strconv.Itoa(i))
+		require.NoError(t, err)
+		require.False(t, added)
+	}
+	require.Equal(t, 1000, s.Len())
+
+	require.NoError(t, s.Close())
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestSmallest(t *testing.T) {
+	s := NewSmallest(3)
+	for _, v := range []string{"e", "b", "d", "b", "a", "f", "c"} {
+		s.Add(v)
+	}
+	require.Equal(t, 3, s.Len())
+	require.Equal(t, []string{"a", "b", "c"}, s.Sorted())
+}
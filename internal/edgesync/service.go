@@ -0,0 +1,174 @@
+// Package edgesync defines the gRPC streaming service through which edge sidecars subscribe to
+// materialized Check results of a store, and a reference subscriber that serves Check from memory.
+//
+// A subscriber registers interest in a set of object types, relations and users. The server first
+// streams a snapshot of every (object, relation, user) that is allowed, and then streams deltas as the
+// results change, derived from the store changelog. Every complete snapshot and delta carries a cursor;
+// a subscriber that reconnects with its last cursor resumes from it and only receives what changed.
+package edgesync
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"github.com/openfga/openfga/internal/jsongrpc"
+)
+
+const (
+	// ServiceName is the fully qualified name of the edge sync gRPC service.
+	ServiceName = "openfga.edge.v1.SyncService"
+
+	subscribeMethod = "/" + ServiceName + "/Subscribe"
+
+	// codecName is the gRPC content-subtype of the sync service, whose messages are exchanged as JSON.
+	codecName = "openfga-edgesync-json"
+)
+
+func init() {
+	jsongrpc.RegisterCodec(codecName)
+}
+
+// SubscribeRequest registers interest in the Check results of every object of ObjectTypes, for every
+// relation of Relations and every user of Users.
+type SubscribeRequest struct {
+	jsongrpc.StoreRequest
+
+	// AuthorizationModelID pins the model the results are computed with. If empty, the latest model
+	// is used and a new snapshot is streamed whenever a newer model is written.
+	AuthorizationModelID string `json:"authorization_model_id,omitempty"`
+
+	ObjectTypes []string `json:"object_types"`
+	Relations   []string `json:"relations"`
+	Users       []string `json:"users"`
+
+	// Cursor is the cursor of the last message applied by the subscriber, if any.
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// MessageType is the type of a SyncMessage.
+type MessageType string
+
+const (
+	// MessageTypeSnapshot is a chunk of a snapshot. The first chunk of a snapshot has Reset set, the
+	// last one has Cursor set.
+	MessageTypeSnapshot MessageType = "snapshot"
+
+	// MessageTypeDelta holds the results that changed since the previous cursor. It may hold no
+	// updates at all, in which case it only advances the cursor.
+	MessageTypeDelta MessageType = "delta"
+)
+
+// CheckResult is the materialized result of Check(Object, Relation, User).
+type CheckResult struct {
+	Object   string `json:"object"`
+	Relation string `json:"relation"`
+	User     string `json:"user"`
+	Allowed  bool   `json:"allowed"`
+}
+
+// SyncMessage is a message streamed to a subscriber.
+type SyncMessage struct {
+	Type MessageType `json:"type"`
+
+	// Reset tells the subscriber to discard every result it holds before applying Updates.
+	Reset bool `json:"reset,omitempty"`
+
+	// Updates are results to apply. Results that are not allowed are removed.
+	Updates []CheckResult `json:"updates,omitempty"`
+
+	// Cursor is set when the subscriber holds a consistent view of the results once Updates are
+	// applied. It is opaque to the subscriber.
+	Cursor string `json:"cursor,omitempty"`
+
+	// ModelID is the authorization model the results were computed with.
+	ModelID string `json:"authorization_model_id,omitempty"`
+
+	// Incomplete holds the queries, as "objectType#relation", whose results may be missing objects, because
+	// computing them reached the deadline or the maximum number of results of ListObjects. It is set along with
+	// Cursor, and replaces the previous one.
+	Incomplete []string `json:"incomplete,omitempty"`
+}
+
+// SyncServer is implemented by the node that serves subscriptions.
+type SyncServer interface {
+	Subscribe(req *SubscribeRequest, stream SubscribeServer) error
+}
+
+// SubscribeServer is the server side of a subscription stream.
+type SubscribeServer interface {
+	Send(*SyncMessage) error
+	grpc.ServerStream
+}
+
+// SubscribeClient is the client side of a subscription stream.
+type SubscribeClient interface {
+	Recv() (*SyncMessage, error)
+	grpc.ClientStream
+}
+
+// RegisterSyncServer registers the edge sync service on the provided gRPC server.
+func RegisterSyncServer(s grpc.ServiceRegistrar, srv SyncServer) {
+	s.RegisterService(&ServiceDesc, srv)
+}
+
+// ServiceDesc is the grpc.ServiceDesc of the edge sync service.
+var ServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*SyncServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       subscribeHandler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "internal/edgesync/service.go",
+}
+
+type subscribeServer struct {
+	grpc.ServerStream
+}
+
+func (s *subscribeServer) Send(m *SyncMessage) error {
+	return s.ServerStream.SendMsg(m)
+}
+
+func subscribeHandler(srv any, stream grpc.ServerStream) error {
+	in := new(SubscribeRequest)
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	return srv.(SyncServer).Subscribe(in, &subscribeServer{stream})
+}
+
+type subscribeClient struct {
+	grpc.ClientStream
+}
+
+func (c *subscribeClient) Recv() (*SyncMessage, error) {
+	m := new(SyncMessage)
+	if err := c.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Subscribe opens a subscription stream on the provided connection.
+func Subscribe(ctx context.Context, conn grpc.ClientConnInterface, req *SubscribeRequest, opts ...grpc.CallOption) (SubscribeClient, error) {
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(codecName)}, opts...)
+	stream, err := conn.NewStream(ctx, &ServiceDesc.Streams[0], subscribeMethod, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := stream.SendMsg(req); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	return &subscribeClient{stream}, nil
+}
@@ -0,0 +1,132 @@
+package edgesync
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// Subscriber is a reference subscriber that keeps the results of a subscription in memory and serves
+// Check from them. Run may be called again after it returns, e.g. after a disconnection, in which case
+// the subscription resumes from the last cursor applied.
+type Subscriber struct {
+	conn grpc.ClientConnInterface
+	req  SubscribeRequest
+
+	mu      sync.RWMutex
+	allowed map[CheckResult]struct{}
+	pending map[CheckResult]struct{} // a snapshot being received
+	cursor  string
+	modelID string
+	// incomplete holds the queries, as "objectType#relation", whose results may be missing objects
+	incomplete map[string]struct{}
+}
+
+// NewSubscriber returns a Subscriber for the provided subscription. The cursor of req is ignored, as a
+// new Subscriber holds no results to resume from.
+func NewSubscriber(conn grpc.ClientConnInterface, req SubscribeRequest) *Subscriber {
+	return &Subscriber{
+		conn: conn,
+		req:  req,
+	}
+}
+
+// Run subscribes and applies every message received until ctx is done or the stream fails.
+func (s *Subscriber) Run(ctx context.Context) error {
+	s.mu.Lock()
+	// a snapshot interrupted by a disconnection is never completed
+	s.pending = nil
+	req := s.req
+	req.Cursor = s.cursor
+	s.mu.Unlock()
+
+	stream, err := Subscribe(ctx, s.conn, &req)
+	if err != nil {
+		return err
+	}
+
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		s.apply(msg)
+	}
+}
+
+func (s *Subscriber) apply(msg *SyncMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if msg.Reset || s.allowed == nil {
+		// keep serving the previous results until the new snapshot is complete
+		if s.pending == nil || msg.Reset {
+			s.pending = make(map[CheckResult]struct{})
+		}
+	}
+
+	target := s.allowed
+	if s.pending != nil {
+		target = s.pending
+	}
+	for _, update := range msg.Updates {
+		key := CheckResult{Object: update.Object, Relation: update.Relation, User: update.User, Allowed: true}
+		if update.Allowed {
+			target[key] = struct{}{}
+		} else {
+			delete(target, key)
+		}
+	}
+
+	if msg.Cursor != "" {
+		if s.pending != nil {
+			s.allowed = s.pending
+			s.pending = nil
+		}
+		s.cursor = msg.Cursor
+		s.modelID = msg.ModelID
+		s.incomplete = make(map[string]struct{}, len(msg.Incomplete))
+		for _, query := range msg.Incomplete {
+			s.incomplete[query] = struct{}{}
+		}
+	}
+}
+
+// Cursor returns the cursor of the last consistent view applied, or an empty string if there is none.
+func (s *Subscriber) Cursor() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cursor
+}
+
+// ModelID returns the authorization model the results held were computed with.
+func (s *Subscriber) ModelID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.modelID
+}
+
+// Check returns whether user has relation with object. ok is false until a first snapshot is complete, and for the
+// object type and relation whose results may be incomplete. Results outside the subscription are reported as not
+// allowed.
+func (s *Subscriber) Check(object, relation, user string) (allowed bool, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.allowed == nil {
+		return false, false
+	}
+	if _, incomplete := s.incomplete[tuple.ToObjectRelationString(tuple.GetType(object), relation)]; incomplete {
+		return false, false
+	}
+	_, allowed = s.allowed[CheckResult{Object: object, Relation: relation, User: user, Allowed: true}]
+	return allowed, true
+}
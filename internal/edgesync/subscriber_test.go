@@ -0,0 +1,58 @@
+package edgesync
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriberApply(t *testing.T) {
+	anne := CheckResult{Object: "document:1", Relation: "viewer", User: "user:anne", Allowed: true}
+	bob := CheckResult{Object: "document:1", Relation: "viewer", User: "user:bob", Allowed: true}
+
+	s := NewSubscriber(nil, SubscribeRequest{})
+
+	// a snapshot is not served until its last chunk is applied
+	s.apply(&SyncMessage{Type: MessageTypeSnapshot, Reset: true, Updates: []CheckResult{anne}})
+	_, ok := s.Check(anne.Object, anne.Relation, anne.User)
+	require.False(t, ok)
+
+	s.apply(&SyncMessage{Type: MessageTypeSnapshot, Updates: []CheckResult{bob}, Cursor: "c1", ModelID: "m1"})
+	allowed, ok := s.Check(anne.Object, anne.Relation, anne.User)
+	require.True(t, ok)
+	require.True(t, allowed)
+	require.Equal(t, "c1", s.Cursor())
+	require.Equal(t, "m1", s.ModelID())
+
+	revoked := bob
+	revoked.Allowed = false
+	s.apply(&SyncMessage{Type: MessageTypeDelta, Updates: []CheckResult{revoked}, Cursor: "c2", ModelID: "m1"})
+	allowed, ok = s.Check(bob.Object, bob.Relation, bob.User)
+	require.True(t, ok)
+	require.False(t, allowed)
+	require.Equal(t, "c2", s.Cursor())
+
+	// the previous results are served while a new snapshot is being received
+	s.apply(&SyncMessage{Type: MessageTypeSnapshot, Reset: true, Updates: []CheckResult{bob}})
+	allowed, _ = s.Check(anne.Object, anne.Relation, anne.User)
+	require.True(t, allowed)
+
+	s.apply(&SyncMessage{Type: MessageTypeSnapshot, Cursor: "c3", ModelID: "m2"})
+	allowed, _ = s.Check(anne.Object, anne.Relation, anne.User)
+	require.False(t, allowed)
+	allowed, _ = s.Check(bob.Object, bob.Relation, bob.User)
+	require.True(t, allowed)
+	require.Equal(t, "m2", s.ModelID())
+
+	// the results of an incomplete query are not served
+	s.apply(&SyncMessage{Type: MessageTypeDelta, Cursor: "c4", ModelID: "m2", Incomplete: []string{"document#viewer"}})
+	_, ok = s.Check(bob.Object, bob.Relation, bob.User)
+	require.False(t, ok)
+	_, ok = s.Check("folder:1", "viewer", bob.User)
+	require.True(t, ok)
+
+	s.apply(&SyncMessage{Type: MessageTypeDelta, Cursor: "c5", ModelID: "m2"})
+	allowed, ok = s.Check(bob.Object, bob.Relation, bob.User)
+	require.True(t, ok)
+	require.True(t, allowed)
+}
@@ -8,8 +8,12 @@ type CheckResolverOrderedBuilder struct {
 	shadowResolverOptions                  []ShadowResolverOpt
 	cachedCheckResolverEnabled             bool
 	cachedCheckResolverOptions             []CachedCheckResolverOpt
+	singleflightCheckResolverEnabled       bool
+	singleflightCheckResolverOptions       []SingleflightCheckResolverOpt
 	dispatchThrottlingCheckResolverEnabled bool
 	dispatchThrottlingCheckResolverOptions []DispatchThrottlingCheckResolverOpt
+	remoteCheckResolverEnabled             bool
+	remoteCheckResolverOptions             []RemoteCheckResolverOpt
 }
 
 type CheckResolverOrderedBuilderOpt func(checkResolver *CheckResolverOrderedBuilder)
@@ -47,6 +51,14 @@ func WithCachedCheckResolverOpts(enabled bool, opts ...CachedCheckResolverOpt) C
 	}
 }
 
+// WithSingleflightCheckResolverOpts sets the opts to be used to build SingleflightCheckResolver.
+func WithSingleflightCheckResolverOpts(enabled bool, opts ...SingleflightCheckResolverOpt) CheckResolverOrderedBuilderOpt {
+	return func(r *CheckResolverOrderedBuilder) {
+		r.singleflightCheckResolverEnabled = enabled
+		r.singleflightCheckResolverOptions = opts
+	}
+}
+
 // WithDispatchThrottlingCheckResolverOpts sets the opts to be used to build DispatchThrottlingCheckResolver.
 func WithDispatchThrottlingCheckResolverOpts(enabled bool, opts ...DispatchThrottlingCheckResolverOpt) CheckResolverOrderedBuilderOpt {
 	return func(r *CheckResolverOrderedBuilder) {
@@ -55,6 +67,14 @@ func WithDispatchThrottlingCheckResolverOpts(enabled bool, opts ...DispatchThrot
 	}
 }
 
+// WithRemoteCheckResolverOpts sets the opts to be used to build RemoteCheckResolver.
+func WithRemoteCheckResolverOpts(enabled bool, opts ...RemoteCheckResolverOpt) CheckResolverOrderedBuilderOpt {
+	return func(r *CheckResolverOrderedBuilder) {
+		r.remoteCheckResolverEnabled = enabled
+		r.remoteCheckResolverOptions = opts
+	}
+}
+
 func NewOrderedCheckResolvers(opts ...CheckResolverOrderedBuilderOpt) *CheckResolverOrderedBuilder {
 	checkResolverBuilder := &CheckResolverOrderedBuilder{}
 	for _, opt := range opts {
@@ -73,6 +93,12 @@ func NewOrderedCheckResolvers(opts ...CheckResolverOrderedBuilderOpt) *CheckReso
 func (c *CheckResolverOrderedBuilder) Build() (CheckResolver, CheckResolverCloser, error) {
 	c.resolvers = []CheckResolver{}
 
+	// the remote resolver goes first so that sub-problems are routed to their owner before
+	// they are looked up in (and saved to) the local cache.
+	if c.remoteCheckResolverEnabled {
+		c.resolvers = append(c.resolvers, NewRemoteCheckResolver(c.remoteCheckResolverOptions...))
+	}
+
 	if c.cachedCheckResolverEnabled {
 		cachedCheckResolver, err := NewCachedCheckResolver(c.cachedCheckResolverOptions...)
 		if err != nil {
@@ -82,6 +108,11 @@ func (c *CheckResolverOrderedBuilder) Build() (CheckResolver, CheckResolverClose
 		c.resolvers = append(c.resolvers, cachedCheckResolver)
 	}
 
+	// sub-problems are coalesced once they missed the cache, and before they are throttled
+	if c.singleflightCheckResolverEnabled {
+		c.resolvers = append(c.resolvers, NewSingleflightCheckResolver(c.singleflightCheckResolverOptions...))
+	}
+
 	if c.dispatchThrottlingCheckResolverEnabled {
 		c.resolvers = append(c.resolvers, NewDispatchThrottlingCheckResolver(c.dispatchThrottlingCheckResolverOptions...))
 	}
@@ -11,6 +11,7 @@ func TestNewOrderedCheckResolverBuilder(t *testing.T) {
 	type Test struct {
 		name                                   string
 		CachedCheckResolverEnabled             bool
+		SingleflightCheckResolverEnabled       bool
 		DispatchThrottlingCheckResolverEnabled bool
 		ShadowResolverEnabled                  bool
 		expectedResolverOrder                  []CheckResolver
@@ -31,11 +32,17 @@ func TestNewOrderedCheckResolverBuilder(t *testing.T) {
 			DispatchThrottlingCheckResolverEnabled: true,
 			expectedResolverOrder:                  []CheckResolver{&DispatchThrottlingCheckResolver{}, &LocalChecker{}},
 		},
+		{
+			name:                             "when_singleflight_alone_is_enabled",
+			SingleflightCheckResolverEnabled: true,
+			expectedResolverOrder:            []CheckResolver{&SingleflightCheckResolver{}, &LocalChecker{}},
+		},
 		{
 			name:                                   "when_all_are_enabled",
 			CachedCheckResolverEnabled:             true,
+			SingleflightCheckResolverEnabled:       true,
 			DispatchThrottlingCheckResolverEnabled: true,
-			expectedResolverOrder:                  []CheckResolver{&CachedCheckResolver{}, &DispatchThrottlingCheckResolver{}, &LocalChecker{}},
+			expectedResolverOrder:                  []CheckResolver{&CachedCheckResolver{}, &SingleflightCheckResolver{}, &DispatchThrottlingCheckResolver{}, &LocalChecker{}},
 		},
 		{
 			name:                                   "when_all_are_enabled_with_shadow",
@@ -50,6 +57,7 @@ func TestNewOrderedCheckResolverBuilder(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			builder := NewOrderedCheckResolvers([]CheckResolverOrderedBuilderOpt{
 				WithCachedCheckResolverOpts(test.CachedCheckResolverEnabled),
+				WithSingleflightCheckResolverOpts(test.SingleflightCheckResolverEnabled),
 				WithDispatchThrottlingCheckResolverOpts(test.DispatchThrottlingCheckResolverEnabled),
 				WithShadowResolverEnabled(test.ShadowResolverEnabled),
 			}...)
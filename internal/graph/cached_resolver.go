@@ -2,6 +2,7 @@ package graph
 
 import (
 	"context"
+	"encoding/json"
 	"strconv"
 	"time"
 
@@ -57,6 +58,21 @@ func (c *CheckResponseCacheEntry) CacheEntityType() string {
 	return "check_response"
 }
 
+func init() {
+	// allows check responses to be stored in caches shared between replicas
+	storage.RegisterCacheItem(func() storage.CacheItem { return &CheckResponseCacheEntry{} })
+}
+
+func (c *CheckResponseCacheEntry) MarshalBinary() ([]byte, error) {
+	type entry CheckResponseCacheEntry
+	return json.Marshal((*entry)(c))
+}
+
+func (c *CheckResponseCacheEntry) UnmarshalBinary(data []byte) error {
+	type entry CheckResponseCacheEntry
+	return json.Unmarshal(data, (*entry)(c))
+}
+
 // CachedCheckResolver attempts to resolve check sub-problems via prior computations before
 // delegating the request to some underlying CheckResolver.
 type CachedCheckResolver struct {
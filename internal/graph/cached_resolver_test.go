@@ -13,6 +13,7 @@ import (
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 
+	"github.com/openfga/openfga/pkg/storage"
 	"github.com/openfga/openfga/pkg/tuple"
 )
 
@@ -626,6 +627,28 @@ func TestCachedCheckResolver_FieldsInResponse(t *testing.T) {
 	require.True(t, resp.GetResolutionMetadata().CycleDetected)
 }
 
+func TestCheckResponseCacheEntryCodec(t *testing.T) {
+	entry := &CheckResponseCacheEntry{
+		LastModified: time.Now().UTC().Truncate(time.Microsecond),
+		CheckResponse: &ResolveCheckResponse{
+			Allowed: true,
+			ResolutionMetadata: ResolveCheckResponseMetadata{
+				DatastoreQueryCount: 3,
+				DatastoreItemCount:  7,
+				Duration:            time.Millisecond,
+			},
+		},
+	}
+
+	codec := storage.NewCacheItemCodec()
+	data, err := codec.Encode(entry)
+	require.NoError(t, err)
+
+	decoded, err := codec.Decode(data)
+	require.NoError(t, err)
+	require.Equal(t, entry, decoded)
+}
+
 func TestBuildCacheKey(t *testing.T) {
 	req, err := NewResolveCheckRequest(ResolveCheckRequestParams{
 		StoreID: "abc123",
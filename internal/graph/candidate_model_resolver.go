@@ -0,0 +1,55 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// CandidateModelCheckResolver resolves the checks against a candidate authorization model instead of the model of
+// the request. It is meant to be the shadow of a ShadowResolver, to find the checks whose outcome the candidate
+// model would change before it is activated.
+type CandidateModelCheckResolver struct {
+	delegate  CheckResolver
+	candidate *typesystem.TypeSystem
+}
+
+var _ CheckResolver = (*CandidateModelCheckResolver)(nil)
+
+// NewCandidateModelCheckResolver returns a CheckResolver that resolves the checks with the delegate, e.g. the head
+// of the chain of resolvers of the main model, against the candidate model. The delegate is not owned, so it is not
+// closed with the returned resolver.
+func NewCandidateModelCheckResolver(delegate CheckResolver, candidate *typesystem.TypeSystem) *CandidateModelCheckResolver {
+	return &CandidateModelCheckResolver{
+		delegate:  delegate,
+		candidate: candidate,
+	}
+}
+
+func (c *CandidateModelCheckResolver) ResolveCheck(ctx context.Context, req *ResolveCheckRequest) (*ResolveCheckResponse, error) {
+	// a new request is built so that the cache keys and the dispatch counters are the ones of the candidate model
+	candidateReq, err := NewResolveCheckRequest(ResolveCheckRequestParams{
+		StoreID:                   req.GetStoreID(),
+		TupleKey:                  req.GetTupleKey(),
+		ContextualTuples:          req.GetContextualTuples(),
+		Context:                   req.GetContext(),
+		Consistency:               req.GetConsistency(),
+		LastCacheInvalidationTime: req.GetLastCacheInvalidationTime(),
+		AuthorizationModelID:      c.candidate.GetAuthorizationModelID(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.delegate.ResolveCheck(typesystem.ContextWithTypesystem(ctx, c.candidate), candidateReq)
+}
+
+func (c *CandidateModelCheckResolver) Close() {}
+
+func (c *CandidateModelCheckResolver) SetDelegate(CheckResolver) {
+	// the delegate is the chain of the main model, which is not changed by the shadow
+}
+
+func (c *CandidateModelCheckResolver) GetDelegate() CheckResolver {
+	return c.delegate
+}
@@ -56,6 +56,7 @@ type LocalChecker struct {
 	logger               logger.Logger
 	optimizationsEnabled bool
 	maxResolutionDepth   uint32
+	permissionIndex      PermissionIndex
 }
 
 type LocalCheckerOption func(d *LocalChecker)
@@ -450,7 +451,19 @@ func (c *LocalChecker) ResolveCheck(
 		}, nil
 	}
 
-	resp, err := c.CheckRewrite(ctx, req, rel.GetRewrite())(ctx)
+	resolve := func(ctx context.Context, req *ResolveCheckRequest) (*ResolveCheckResponse, error) {
+		if resp, ok := c.checkPermissionIndex(ctx, req); ok {
+			return resp, nil
+		}
+		return c.CheckRewrite(ctx, req, rel.GetRewrite())(ctx)
+	}
+
+	var resp *ResolveCheckResponse
+	if memo, ok := CheckMemoFromContext(ctx); ok {
+		resp, err = memo.resolve(ctx, req, resolve)
+	} else {
+		resp, err = resolve(ctx, req)
+	}
 	if err != nil {
 		telemetry.TraceError(span, err)
 		return nil, err
@@ -0,0 +1,165 @@
+package graph
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultCheckMemoMaxEntries is the default maximum number of outcomes a CheckMemo holds.
+const DefaultCheckMemoMaxEntries = 10000
+
+type checkMemoCtxKey struct{}
+
+// CheckMemo memoizes the outcome of the check sub-problems resolved by LocalChecker, as identified by
+// BuildCacheKey, so that related checks (e.g. every relation of an object for the same user, or the items
+// of a BatchCheck) resolve the sub-problems they have in common only once. Sub-problems that are reached
+// concurrently share a single in-flight resolution. A CheckMemo is meant to live as long as the request
+// that created it, so unlike the check query cache it is never invalidated. It holds at most MaxEntries
+// outcomes, and evicts the least recently used ones beyond that, so that long-lived requests (e.g. a
+// StreamedBatchCheck with no limit on the number of checks) do not grow it without bound.
+type CheckMemo struct {
+	inflight *InflightChecks
+
+	mu         sync.Mutex
+	results    map[string]*list.Element // of *checkMemoEntry
+	recency    *list.List               // most recently used first
+	maxEntries int
+
+	hits            atomic.Uint64
+	dispatchesSaved atomic.Uint64
+}
+
+type checkMemoEntry struct {
+	key  string
+	resp *ResolveCheckResponse
+	// dispatches is the number of dispatches the resolution of the sub-problem took.
+	dispatches uint32
+}
+
+// CheckMemoOption configures a CheckMemo.
+type CheckMemoOption func(*CheckMemo)
+
+// WithCheckMemoMaxEntries sets the maximum number of outcomes the CheckMemo holds. It defaults to
+// DefaultCheckMemoMaxEntries.
+func WithCheckMemoMaxEntries(maxEntries int) CheckMemoOption {
+	return func(m *CheckMemo) {
+		m.maxEntries = maxEntries
+	}
+}
+
+// NewCheckMemo returns an empty CheckMemo.
+func NewCheckMemo(opts ...CheckMemoOption) *CheckMemo {
+	m := &CheckMemo{
+		inflight:   NewInflightChecks(),
+		results:    make(map[string]*list.Element),
+		recency:    list.New(),
+		maxEntries: DefaultCheckMemoMaxEntries,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// ContextWithCheckMemo returns a context whose checks resolved by LocalChecker share memo.
+func ContextWithCheckMemo(ctx context.Context, memo *CheckMemo) context.Context {
+	return context.WithValue(ctx, checkMemoCtxKey{}, memo)
+}
+
+// CheckMemoFromContext returns the CheckMemo of ctx, if any.
+func CheckMemoFromContext(ctx context.Context) (*CheckMemo, bool) {
+	memo, ok := ctx.Value(checkMemoCtxKey{}).(*CheckMemo)
+	return memo, ok && memo != nil
+}
+
+// Hits returns the number of sub-problems whose resolution was shared, either memoized or in flight.
+func (m *CheckMemo) Hits() uint64 {
+	return m.hits.Load()
+}
+
+// DispatchesSaved returns the number of dispatches the shared resolutions would have taken had they not
+// been shared.
+func (m *CheckMemo) DispatchesSaved() uint64 {
+	return m.dispatchesSaved.Load()
+}
+
+// resolve returns the memoized outcome of req, or resolves it with resolve, sharing the resolution with the
+// identical sub-problems that are reached in the meantime.
+func (m *CheckMemo) resolve(
+	ctx context.Context,
+	req *ResolveCheckRequest,
+	resolve func(ctx context.Context, req *ResolveCheckRequest) (*ResolveCheckResponse, error),
+) (*ResolveCheckResponse, error) {
+	key := BuildCacheKey(*req)
+	if entry, ok := m.load(key); ok {
+		m.hit(entry)
+		return entry.resp.clone(), nil
+	}
+
+	resp, outcome, err := m.inflight.resolve(ctx, key, req, func(ctx context.Context, req *ResolveCheckRequest) (*ResolveCheckResponse, error) {
+		// count the dispatches of this resolution on their own, to know how many are saved when it is shared
+		dispatchCounter := req.GetRequestMetadata().DispatchCounter
+		req = req.clone()
+		req.GetRequestMetadata().DispatchCounter = new(atomic.Uint32)
+
+		resp, err := resolve(ctx, req)
+		dispatches := req.GetRequestMetadata().DispatchCounter.Load()
+		dispatchCounter.Add(dispatches)
+
+		if err == nil {
+			m.store(key, resp, dispatches)
+		}
+		return resp, err
+	})
+
+	if err == nil && outcome == flightShared {
+		if entry, ok := m.load(key); ok {
+			m.hit(entry)
+		}
+	}
+	return resp, err
+}
+
+func (m *CheckMemo) hit(entry *checkMemoEntry) {
+	m.hits.Add(1)
+	m.dispatchesSaved.Add(uint64(entry.dispatches))
+}
+
+func (m *CheckMemo) load(key string) (*checkMemoEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elem, ok := m.results[key]
+	if !ok {
+		return nil, false
+	}
+	m.recency.MoveToFront(elem)
+	return elem.Value.(*checkMemoEntry), true
+}
+
+// store memoizes resp as the outcome of key. Outcomes that depend on the path to the sub-problem
+// (i.e. cycles) are not memoized.
+func (m *CheckMemo) store(key string, resp *ResolveCheckResponse, dispatches uint32) {
+	if resp.GetCycleDetected() || m.maxEntries <= 0 {
+		return
+	}
+	entry := &checkMemoEntry{key: key, resp: resp.clone(), dispatches: dispatches}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if elem, ok := m.results[key]; ok {
+		elem.Value = entry
+		m.recency.MoveToFront(elem)
+		return
+	}
+	m.results[key] = m.recency.PushFront(entry)
+
+	for m.recency.Len() > m.maxEntries {
+		oldest := m.recency.Back()
+		m.recency.Remove(oldest)
+		delete(m.results, oldest.Value.(*checkMemoEntry).key)
+	}
+}
@@ -0,0 +1,92 @@
+package graph
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+)
+
+func TestCheckMemo(t *testing.T) {
+	t.Run("context", func(t *testing.T) {
+		_, ok := CheckMemoFromContext(context.Background())
+		require.False(t, ok)
+
+		memo := NewCheckMemo()
+		fromCtx, ok := CheckMemoFromContext(ContextWithCheckMemo(context.Background(), memo))
+		require.True(t, ok)
+		require.Same(t, memo, fromCtx)
+	})
+
+	t.Run("resolves_each_sub_problem_once", func(t *testing.T) {
+		memo := NewCheckMemo()
+		var calls atomic.Int32
+		resolve := func(ctx context.Context, req *ResolveCheckRequest) (*ResolveCheckResponse, error) {
+			calls.Add(1)
+			req.GetRequestMetadata().DispatchCounter.Add(3)
+			return &ResolveCheckResponse{Allowed: true}, nil
+		}
+
+		req := newSingleflightTestRequest(t, "document:1", openfgav1.ConsistencyPreference_UNSPECIFIED)
+		resp, err := memo.resolve(context.Background(), req, resolve)
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+		require.Equal(t, uint32(3), req.GetRequestMetadata().DispatchCounter.Load())
+
+		// the memoized response is not modified by the callers
+		resp.Allowed = false
+
+		req = newSingleflightTestRequest(t, "document:1", openfgav1.ConsistencyPreference_UNSPECIFIED)
+		resp, err = memo.resolve(context.Background(), req, resolve)
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+		require.Zero(t, req.GetRequestMetadata().DispatchCounter.Load())
+
+		require.Equal(t, int32(1), calls.Load())
+		require.Equal(t, uint64(1), memo.Hits())
+		require.Equal(t, uint64(3), memo.DispatchesSaved())
+	})
+
+	t.Run("cycles_are_not_memoized", func(t *testing.T) {
+		memo := NewCheckMemo()
+		var calls atomic.Int32
+		resolve := func(ctx context.Context, req *ResolveCheckRequest) (*ResolveCheckResponse, error) {
+			calls.Add(1)
+			return &ResolveCheckResponse{ResolutionMetadata: ResolveCheckResponseMetadata{CycleDetected: true}}, nil
+		}
+
+		for i := 0; i < 2; i++ {
+			resp, err := memo.resolve(context.Background(), newSingleflightTestRequest(t, "document:1", openfgav1.ConsistencyPreference_UNSPECIFIED), resolve)
+			require.NoError(t, err)
+			require.True(t, resp.GetCycleDetected())
+		}
+		require.Equal(t, int32(2), calls.Load())
+		require.Zero(t, memo.Hits())
+	})
+	t.Run("least_recently_used_outcomes_are_evicted", func(t *testing.T) {
+		memo := NewCheckMemo(WithCheckMemoMaxEntries(2))
+		calls := make(map[string]int)
+		resolve := func(ctx context.Context, req *ResolveCheckRequest) (*ResolveCheckResponse, error) {
+			calls[req.GetTupleKey().GetObject()]++
+			return &ResolveCheckResponse{Allowed: true}, nil
+		}
+		check := func(object string) {
+			_, err := memo.resolve(context.Background(), newSingleflightTestRequest(t, object, openfgav1.ConsistencyPreference_UNSPECIFIED), resolve)
+			require.NoError(t, err)
+		}
+
+		check("document:1")
+		check("document:2")
+		check("document:1") // document:2 is now the least recently used
+		check("document:3")
+		require.Len(t, memo.results, 2)
+
+		check("document:1")
+		check("document:2")
+		require.Equal(t, map[string]int{"document:1": 1, "document:2": 2, "document:3": 1}, calls)
+		require.Equal(t, uint64(2), memo.Hits())
+	})
+}
@@ -28,6 +28,8 @@ type DispatchThrottlingCheckResolver struct {
 	delegate  CheckResolver
 	config    *DispatchThrottlingCheckResolverConfig
 	throttler throttler.Throttler
+	// sharedThrottler is whether the throttler is shared with other resolvers, and isn't closed with this one
+	sharedThrottler bool
 }
 
 var _ CheckResolver = (*DispatchThrottlingCheckResolver)(nil)
@@ -50,6 +52,15 @@ func WithThrottler(throttler throttler.Throttler) DispatchThrottlingCheckResolve
 	}
 }
 
+// WithSharedThrottler sets a throttler to be used for DispatchThrottlingCheckResolver that is shared across
+// resolvers, e.g. to schedule the dispatches of all the requests fairly. It isn't closed when the resolver is.
+func WithSharedThrottler(throttler throttler.Throttler) DispatchThrottlingCheckResolverOpt {
+	return func(r *DispatchThrottlingCheckResolver) {
+		r.throttler = throttler
+		r.sharedThrottler = true
+	}
+}
+
 // WithConstantRateThrottler sets the constant rate throttler to be used for DispatchThrottlingCheckResolver.
 func WithConstantRateThrottler(frequency time.Duration, metricLabel string) DispatchThrottlingCheckResolverOpt {
 	return func(r *DispatchThrottlingCheckResolver) {
@@ -82,6 +93,9 @@ func (r *DispatchThrottlingCheckResolver) GetDelegate() CheckResolver {
 }
 
 func (r *DispatchThrottlingCheckResolver) Close() {
+	if r.sharedThrottler {
+		return
+	}
 	r.throttler.Close()
 }
 
@@ -197,4 +197,33 @@ func TestDispatchThrottlingCheckResolver(t *testing.T) {
 
 		require.True(t, req.GetRequestMetadata().DispatchThrottled.Load())
 	})
+	t.Run("shared_throttler_is_not_closed_with_the_resolver", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockThrottler := mocks.NewMockThrottler(ctrl)
+		mockThrottler.EXPECT().Close().Times(0)
+
+		dut := NewDispatchThrottlingCheckResolver(
+			WithDispatchThrottlingCheckResolverConfig(DispatchThrottlingCheckResolverConfig{
+				DefaultThreshold: 200,
+				MaxThreshold:     200,
+			}),
+			WithSharedThrottler(mockThrottler),
+		)
+
+		mockCheckResolver := NewMockCheckResolver(ctrl)
+		dut.SetDelegate(mockCheckResolver)
+
+		mockCheckResolver.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Times(1)
+		mockThrottler.EXPECT().Throttle(gomock.Any()).Times(1)
+
+		req := &ResolveCheckRequest{RequestMetadata: NewCheckRequestMetadata()}
+		req.GetRequestMetadata().DispatchCounter.Store(201)
+
+		_, err := dut.ResolveCheck(context.Background(), req)
+		require.NoError(t, err)
+
+		dut.Close()
+	})
 }
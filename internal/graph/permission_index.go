@@ -0,0 +1,98 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/internal/permissionindex"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+var permissionIndexCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: build.ProjectName,
+	Name:      "check_permission_index_count",
+	Help:      "The total number of eligible recursive relations resolved by the permission index (hit) or by graph resolution (fallback).",
+}, []string{"outcome"})
+
+// PermissionIndex answers membership of recursive usersets from a precomputed index.
+// See [permissionindex.Index].
+type PermissionIndex interface {
+	// Lookup reports whether user is a direct or nested member of object#relation. ok is false if
+	// the index cannot answer, in which case the relation must be resolved through the graph.
+	Lookup(ctx context.Context, storeID, object, relation, user string, includeWildcard bool) (allowed bool, ok bool)
+}
+
+// WithPermissionIndex makes the LocalChecker consult the provided index for eligible recursive relations.
+// See [permissionIndexEligible].
+func WithPermissionIndex(index PermissionIndex) LocalCheckerOption {
+	return func(d *LocalChecker) {
+		d.permissionIndex = index
+	}
+}
+
+// permissionIndexEligible returns whether the relation can be answered by a PermissionIndex for users of
+// userType, and whether typed wildcards of userType are assignable to it. See [permissionindex.Eligible].
+func permissionIndexEligible(typesys *typesystem.TypeSystem, objectType, relation, userType string) (bool, bool) {
+	if !permissionindex.Eligible(typesys, objectType, relation) {
+		return false, false
+	}
+
+	directlyRelatedTypes, err := typesys.GetDirectlyRelatedUserTypes(objectType, relation)
+	if err != nil {
+		return false, false
+	}
+
+	var userAssignable, wildcardAssignable bool
+	for _, ref := range directlyRelatedTypes {
+		switch {
+		case ref.GetRelation() != "" || ref.GetType() != userType:
+			continue
+		case ref.GetWildcard() != nil:
+			wildcardAssignable = true
+		default:
+			userAssignable = true
+		}
+	}
+
+	return userAssignable || wildcardAssignable, wildcardAssignable
+}
+
+// checkPermissionIndex resolves req from the permission index if the relation is eligible and the index
+// is fresh. The second value is false if req must be resolved through the graph.
+func (c *LocalChecker) checkPermissionIndex(ctx context.Context, req *ResolveCheckRequest) (*ResolveCheckResponse, bool) {
+	if c.permissionIndex == nil ||
+		len(req.GetContextualTuples()) > 0 ||
+		req.GetConsistency() == openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY {
+		return nil, false
+	}
+
+	tk := req.GetTupleKey()
+	user := tk.GetUser()
+	if tuple.IsObjectRelation(user) || tuple.IsWildcard(user) {
+		return nil, false
+	}
+
+	typesys, _ := typesystem.TypesystemFromContext(ctx)
+	eligible, includeWildcard := permissionIndexEligible(typesys, tuple.GetType(tk.GetObject()), tk.GetRelation(), tuple.GetType(user))
+	if !eligible {
+		return nil, false
+	}
+
+	allowed, ok := c.permissionIndex.Lookup(ctx, req.GetStoreID(), tk.GetObject(), tk.GetRelation(), user, includeWildcard)
+	if !ok {
+		permissionIndexCounter.WithLabelValues("fallback").Inc()
+		return nil, false
+	}
+	permissionIndexCounter.WithLabelValues("hit").Inc()
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("permission_index", true))
+
+	return &ResolveCheckResponse{Allowed: allowed}, true
+}
@@ -0,0 +1,165 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+type fakePermissionIndex struct {
+	allowed bool
+	ok      bool
+	lookups int
+}
+
+func (f *fakePermissionIndex) Lookup(_ context.Context, _, _, _, _ string, _ bool) (bool, bool) {
+	f.lookups++
+	return f.allowed, f.ok
+}
+
+func TestPermissionIndexEligible(t *testing.T) {
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+		type employee
+		type group
+			relations
+				define member: [user, group#member]
+				define public_member: [user, user:*, group#public_member]
+				define wildcard_member: [user:*, group#wildcard_member]
+				define flat_member: [user]
+				define conditional_member: [user with cond, group#conditional_member]
+				define mixed_member: [user, group#member, group#mixed_member]
+				define computed_member: [user, group#computed_member] or flat_member
+
+		condition cond(x: int) {
+			x < 100
+		}`)
+	ts, err := typesystem.New(model)
+	require.NoError(t, err)
+
+	tests := []struct {
+		relation         string
+		userType         string
+		expectedEligible bool
+		expectedWildcard bool
+	}{
+		{relation: "member", userType: "user", expectedEligible: true},
+		{relation: "member", userType: "employee", expectedEligible: false},
+		{relation: "public_member", userType: "user", expectedEligible: true, expectedWildcard: true},
+		{relation: "wildcard_member", userType: "user", expectedEligible: true, expectedWildcard: true},
+		{relation: "flat_member", userType: "user", expectedEligible: false},
+		{relation: "conditional_member", userType: "user", expectedEligible: false},
+		{relation: "mixed_member", userType: "user", expectedEligible: false},
+		{relation: "computed_member", userType: "user", expectedEligible: false},
+		{relation: "undefined", userType: "user", expectedEligible: false},
+	}
+	for _, test := range tests {
+		t.Run(test.relation+"_"+test.userType, func(t *testing.T) {
+			eligible, includeWildcard := permissionIndexEligible(ts, "group", test.relation, test.userType)
+			require.Equal(t, test.expectedEligible, eligible)
+			if eligible {
+				require.Equal(t, test.expectedWildcard, includeWildcard)
+			}
+		})
+	}
+}
+
+func TestCheckWithPermissionIndex(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+	err := ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("group:eng", "member", "group:backend#member"),
+		tuple.NewTupleKey("group:backend", "member", "user:anne"),
+	})
+	require.NoError(t, err)
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+		type group
+			relations
+				define member: [user, group#member]`)
+	ts, err := typesystem.New(model)
+	require.NoError(t, err)
+
+	newRequest := func(user string, consistency openfgav1.ConsistencyPreference, contextualTuples ...*openfgav1.TupleKey) *ResolveCheckRequest {
+		return &ResolveCheckRequest{
+			StoreID:          storeID,
+			TupleKey:         tuple.NewTupleKey("group:eng", "member", user),
+			ContextualTuples: contextualTuples,
+			Consistency:      consistency,
+			RequestMetadata:  NewCheckRequestMetadata(),
+		}
+	}
+
+	t.Run("answered_from_the_index", func(t *testing.T) {
+		// the index disagrees with the datastore on purpose, to prove it is the one answering
+		index := &fakePermissionIndex{allowed: true, ok: true}
+		checker := NewLocalChecker(WithPermissionIndex(index))
+		t.Cleanup(checker.Close)
+
+		ctx := setRequestContext(context.Background(), ts, ds, nil)
+		resp, err := checker.ResolveCheck(ctx, newRequest("user:bob", openfgav1.ConsistencyPreference_UNSPECIFIED))
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+		require.Equal(t, 1, index.lookups)
+	})
+
+	t.Run("stale_index_falls_back_to_graph_resolution", func(t *testing.T) {
+		index := &fakePermissionIndex{allowed: false, ok: false}
+		checker := NewLocalChecker(WithPermissionIndex(index))
+		t.Cleanup(checker.Close)
+
+		ctx := setRequestContext(context.Background(), ts, ds, nil)
+		resp, err := checker.ResolveCheck(ctx, newRequest("user:anne", openfgav1.ConsistencyPreference_UNSPECIFIED))
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+		require.Positive(t, index.lookups)
+	})
+
+	t.Run("higher_consistency_bypasses_the_index", func(t *testing.T) {
+		index := &fakePermissionIndex{allowed: true, ok: true}
+		checker := NewLocalChecker(WithPermissionIndex(index))
+		t.Cleanup(checker.Close)
+
+		ctx := setRequestContext(context.Background(), ts, ds, nil)
+		resp, err := checker.ResolveCheck(ctx, newRequest("user:bob", openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY))
+		require.NoError(t, err)
+		require.False(t, resp.GetAllowed())
+		require.Zero(t, index.lookups)
+	})
+
+	t.Run("contextual_tuples_bypass_the_index", func(t *testing.T) {
+		index := &fakePermissionIndex{allowed: false, ok: true}
+		checker := NewLocalChecker(WithPermissionIndex(index))
+		t.Cleanup(checker.Close)
+
+		contextualTuple := tuple.NewTupleKey("group:eng", "member", "user:bob")
+		ctx := setRequestContext(context.Background(), ts, ds, []*openfgav1.TupleKey{contextualTuple})
+		resp, err := checker.ResolveCheck(ctx, newRequest("user:bob", openfgav1.ConsistencyPreference_UNSPECIFIED, contextualTuple))
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+		require.Zero(t, index.lookups)
+	})
+}
@@ -0,0 +1,168 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"golang.org/x/exp/maps"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/internal/jsongrpc"
+	"github.com/openfga/openfga/internal/peer"
+	"github.com/openfga/openfga/pkg/logger"
+)
+
+const (
+	remoteDispatchOutcomeLocal    = "local"
+	remoteDispatchOutcomeRemote   = "remote"
+	remoteDispatchOutcomeFallback = "fallback"
+)
+
+var remoteDispatchCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: build.ProjectName,
+	Name:      "check_remote_dispatch_count",
+	Help:      "The total number of Check sub-problems routed by the remote check resolver, labeled by whether they were resolved locally, by a peer, or locally after the peer failed.",
+}, []string{"outcome"})
+
+// PeerDispatcher routes Check sub-problems to the node of the cluster that owns them.
+type PeerDispatcher interface {
+	// Owner returns the address of the peer owning the key, and whether that peer is the local node.
+	Owner(key string) (string, bool)
+
+	// DispatchCheck resolves the sub-problem on the peer at addr.
+	DispatchCheck(ctx context.Context, addr string, req *peer.CheckRequest) (*peer.CheckResponse, error)
+}
+
+// RemoteCheckResolver shards Check sub-problems across the peers of a cluster. Every sub-problem is
+// owned by exactly one peer, chosen by consistent hashing of its cache key, so that each peer only
+// caches the sub-problems of its own key range. Sub-problems owned by the local node, and sub-problems
+// whose peer cannot be reached, are delegated to the rest of the local chain.
+type RemoteCheckResolver struct {
+	delegate   CheckResolver
+	dispatcher PeerDispatcher
+	logger     logger.Logger
+}
+
+var _ CheckResolver = (*RemoteCheckResolver)(nil)
+
+// RemoteCheckResolverOpt defines an option that can be used to change the behavior of RemoteCheckResolver
+// instance.
+type RemoteCheckResolverOpt func(*RemoteCheckResolver)
+
+// WithPeerDispatcher sets the dispatcher used to route sub-problems to peers.
+func WithPeerDispatcher(dispatcher PeerDispatcher) RemoteCheckResolverOpt {
+	return func(r *RemoteCheckResolver) {
+		r.dispatcher = dispatcher
+	}
+}
+
+// WithRemoteCheckResolverLogger sets the logger for the remote check resolver.
+func WithRemoteCheckResolverLogger(logger logger.Logger) RemoteCheckResolverOpt {
+	return func(r *RemoteCheckResolver) {
+		r.logger = logger
+	}
+}
+
+// NewRemoteCheckResolver constructs a RemoteCheckResolver. Without a PeerDispatcher every sub-problem
+// is resolved locally.
+func NewRemoteCheckResolver(opts ...RemoteCheckResolverOpt) *RemoteCheckResolver {
+	r := &RemoteCheckResolver{
+		logger: logger.NewNoopLogger(),
+	}
+	r.delegate = r
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// SetDelegate sets this RemoteCheckResolver's dispatch delegate.
+func (r *RemoteCheckResolver) SetDelegate(delegate CheckResolver) {
+	r.delegate = delegate
+}
+
+// GetDelegate returns this RemoteCheckResolver's dispatch delegate.
+func (r *RemoteCheckResolver) GetDelegate() CheckResolver {
+	return r.delegate
+}
+
+// Close is a noop. The PeerDispatcher is shared across requests and is owned by the caller.
+func (r *RemoteCheckResolver) Close() {}
+
+func (r *RemoteCheckResolver) ResolveCheck(ctx context.Context, req *ResolveCheckRequest) (*ResolveCheckResponse, error) {
+	if r.dispatcher == nil {
+		return r.delegate.ResolveCheck(ctx, req)
+	}
+
+	addr, isLocal := r.dispatcher.Owner(BuildCacheKey(*req))
+	if isLocal {
+		remoteDispatchCounter.WithLabelValues(remoteDispatchOutcomeLocal).Inc()
+		return r.delegate.ResolveCheck(ctx, req)
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.String("peer", addr))
+
+	resp, err := r.dispatcher.DispatchCheck(ctx, addr, newPeerCheckRequest(req))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		// Resolution is deterministic, so resolving locally yields the same outcome (or the same error)
+		// the peer would have produced. Only the cache locality is lost.
+		remoteDispatchCounter.WithLabelValues(remoteDispatchOutcomeFallback).Inc()
+		r.logger.WarnWithContext(ctx, "failed to dispatch check to peer, resolving locally",
+			zap.String("peer", addr),
+			zap.String("store_id", req.GetStoreID()),
+			zap.Error(err))
+		return r.delegate.ResolveCheck(ctx, req)
+	}
+
+	remoteDispatchCounter.WithLabelValues(remoteDispatchOutcomeRemote).Inc()
+
+	if resp.ResolutionDepthExceeded {
+		return nil, ErrResolutionDepthExceeded
+	}
+
+	if metadata := req.GetRequestMetadata(); metadata != nil {
+		metadata.DispatchCounter.Add(resp.DispatchCount)
+		if resp.DispatchThrottled {
+			metadata.DispatchThrottled.Store(true)
+		}
+	}
+
+	return &ResolveCheckResponse{
+		Allowed: resp.Allowed,
+		ResolutionMetadata: ResolveCheckResponseMetadata{
+			DatastoreQueryCount: resp.DatastoreQueryCount,
+			DatastoreItemCount:  resp.DatastoreItemCount,
+			CycleDetected:       resp.CycleDetected,
+		},
+	}, nil
+}
+
+func newPeerCheckRequest(req *ResolveCheckRequest) *peer.CheckRequest {
+	var depth uint32
+	if metadata := req.GetRequestMetadata(); metadata != nil {
+		depth = metadata.Depth
+	}
+
+	return &peer.CheckRequest{
+		StoreRequest:              jsongrpc.StoreRequest{StoreID: req.GetStoreID()},
+		AuthorizationModelID:      req.GetAuthorizationModelID(),
+		TupleKey:                  req.GetTupleKey(),
+		ContextualTuples:          req.GetContextualTuples(),
+		Context:                   req.GetContext(),
+		Consistency:               req.GetConsistency(),
+		LastCacheInvalidationTime: req.GetLastCacheInvalidationTime(),
+		Depth:                     depth,
+		VisitedPaths:              maps.Keys(req.GetVisitedPaths()),
+	}
+}
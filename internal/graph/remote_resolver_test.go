@@ -0,0 +1,123 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/openfga/openfga/internal/peer"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+type fakePeerDispatcher struct {
+	owner   string
+	isLocal bool
+	resp    *peer.CheckResponse
+	err     error
+	got     *peer.CheckRequest
+}
+
+func (f *fakePeerDispatcher) Owner(string) (string, bool) {
+	return f.owner, f.isLocal
+}
+
+func (f *fakePeerDispatcher) DispatchCheck(_ context.Context, _ string, req *peer.CheckRequest) (*peer.CheckResponse, error) {
+	f.got = req
+	return f.resp, f.err
+}
+
+func TestRemoteCheckResolver(t *testing.T) {
+	newRequest := func(t *testing.T) *ResolveCheckRequest {
+		req, err := NewResolveCheckRequest(ResolveCheckRequestParams{
+			StoreID:              "store",
+			AuthorizationModelID: "model",
+			TupleKey:             tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		})
+		require.NoError(t, err)
+		req.GetRequestMetadata().Depth = 2
+		req.VisitedPaths["document:1#viewer@user:anne"] = struct{}{}
+		return req
+	}
+
+	t.Run("local_owner_is_delegated", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockDelegate := NewMockCheckResolver(ctrl)
+		mockDelegate.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Return(&ResolveCheckResponse{Allowed: true}, nil)
+
+		dispatcher := &fakePeerDispatcher{owner: "self", isLocal: true}
+		r := NewRemoteCheckResolver(WithPeerDispatcher(dispatcher))
+		r.SetDelegate(mockDelegate)
+
+		resp, err := r.ResolveCheck(context.Background(), newRequest(t))
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+		require.Nil(t, dispatcher.got)
+	})
+
+	t.Run("remote_owner_resolves_the_sub_problem", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockDelegate := NewMockCheckResolver(ctrl)
+
+		dispatcher := &fakePeerDispatcher{owner: "peer", resp: &peer.CheckResponse{
+			Allowed:           true,
+			CycleDetected:     true,
+			DispatchCount:     5,
+			DispatchThrottled: true,
+		}}
+		r := NewRemoteCheckResolver(WithPeerDispatcher(dispatcher))
+		r.SetDelegate(mockDelegate)
+
+		req := newRequest(t)
+		resp, err := r.ResolveCheck(context.Background(), req)
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+		require.True(t, resp.GetCycleDetected())
+		require.Equal(t, uint32(5), req.GetRequestMetadata().DispatchCounter.Load())
+		require.True(t, req.GetRequestMetadata().DispatchThrottled.Load())
+
+		require.Equal(t, "store", dispatcher.got.StoreID)
+		require.Equal(t, "model", dispatcher.got.AuthorizationModelID)
+		require.Equal(t, uint32(2), dispatcher.got.Depth)
+		require.Equal(t, []string{"document:1#viewer@user:anne"}, dispatcher.got.VisitedPaths)
+	})
+
+	t.Run("resolution_depth_exceeded_on_peer", func(t *testing.T) {
+		dispatcher := &fakePeerDispatcher{owner: "peer", resp: &peer.CheckResponse{ResolutionDepthExceeded: true}}
+		r := NewRemoteCheckResolver(WithPeerDispatcher(dispatcher))
+
+		_, err := r.ResolveCheck(context.Background(), newRequest(t))
+		require.ErrorIs(t, err, ErrResolutionDepthExceeded)
+	})
+
+	t.Run("unreachable_peer_falls_back_to_local", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockDelegate := NewMockCheckResolver(ctrl)
+		mockDelegate.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Return(&ResolveCheckResponse{Allowed: true}, nil)
+
+		dispatcher := &fakePeerDispatcher{owner: "peer", err: errors.New("connection refused")}
+		r := NewRemoteCheckResolver(WithPeerDispatcher(dispatcher))
+		r.SetDelegate(mockDelegate)
+
+		resp, err := r.ResolveCheck(context.Background(), newRequest(t))
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+	})
+
+	t.Run("cancelled_context_is_not_retried_locally", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockDelegate := NewMockCheckResolver(ctrl)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		dispatcher := &fakePeerDispatcher{owner: "peer", err: context.Canceled}
+		r := NewRemoteCheckResolver(WithPeerDispatcher(dispatcher))
+		r.SetDelegate(mockDelegate)
+
+		_, err := r.ResolveCheck(ctx, newRequest(t))
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
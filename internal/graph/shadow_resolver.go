@@ -14,6 +14,10 @@ const Hundred = 100
 
 type ShadowResolverOpt func(*ShadowResolver)
 
+// ShadowResolverResultHandler is called with the outcomes of the main and the shadow resolvers once the shadow
+// resolver of a request completes. shadowErr is set when the shadow resolver errored.
+type ShadowResolverResultHandler func(ctx context.Context, req *ResolveCheckRequest, main, shadow *ResolveCheckResponse, shadowErr error)
+
 func ShadowResolverWithName(name string) ShadowResolverOpt {
 	return func(shadowResolver *ShadowResolver) {
 		shadowResolver.name = name
@@ -32,12 +36,21 @@ func ShadowResolverWithLogger(logger logger.Logger) ShadowResolverOpt {
 	}
 }
 
+// ShadowResolverWithResultHandler sets a handler that records the outcomes of the main and the shadow resolvers,
+// in addition to the logs.
+func ShadowResolverWithResultHandler(handler ShadowResolverResultHandler) ShadowResolverOpt {
+	return func(shadowResolver *ShadowResolver) {
+		shadowResolver.resultHandler = handler
+	}
+}
+
 type ShadowResolver struct {
 	name          string
 	main          CheckResolver
 	shadow        CheckResolver
 	shadowTimeout time.Duration
 	logger        logger.Logger
+	resultHandler ShadowResolverResultHandler
 	// only used for testing signals
 	wg *sync.WaitGroup
 }
@@ -78,6 +91,9 @@ func (s ShadowResolver) ResolveCheck(ctx context.Context, req *ResolveCheckReque
 		shadowStart := time.Now()
 		shadowRes, err := s.shadow.ResolveCheck(ctx, reqClone)
 		shadowDuration := time.Since(shadowStart)
+		if s.resultHandler != nil {
+			s.resultHandler(ctx, reqClone, resClone, shadowRes, err)
+		}
 		if err != nil {
 			s.logger.WarnWithContext(ctx, "shadow check errored",
 				zap.String("resolver", s.name),
@@ -68,6 +68,36 @@ func TestShadowResolver_ResolveCheck(t *testing.T) {
 		require.NoError(t, err)
 		require.False(t, res.Allowed)
 	})
+	t.Run("should_call_result_handler", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		main := NewMockCheckResolver(ctrl)
+		main.EXPECT().Close().MaxTimes(1)
+		shadow := NewMockCheckResolver(ctrl)
+		shadow.EXPECT().Close().MaxTimes(1)
+		var (
+			handledMain, handledShadow *ResolveCheckResponse
+			handledErr                 error
+		)
+		logger := mocks.NewMockLogger(ctrl)
+		logger.EXPECT().InfoWithContext(gomock.Any(), "shadow check difference", gomock.Any())
+		checker := NewShadowChecker(main, shadow, ShadowResolverWithLogger(logger), ShadowResolverWithResultHandler(
+			func(_ context.Context, _ *ResolveCheckRequest, main, shadow *ResolveCheckResponse, shadowErr error) {
+				handledMain, handledShadow, handledErr = main, shadow, shadowErr
+			}))
+		defer checker.Close()
+		main.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Return(&ResolveCheckResponse{
+			Allowed: false,
+		}, nil)
+		shadow.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).Return(&ResolveCheckResponse{Allowed: true}, nil)
+		res, err := checker.ResolveCheck(context.Background(), &ResolveCheckRequest{})
+		checker.wg.Wait()
+		require.NoError(t, err)
+		require.False(t, res.Allowed)
+		require.False(t, handledMain.GetAllowed())
+		require.True(t, handledShadow.GetAllowed())
+		require.NoError(t, handledErr)
+	})
 	t.Run("should_recover_from_panic", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
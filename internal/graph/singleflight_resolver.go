@@ -0,0 +1,337 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+var singleflightCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: build.ProjectName,
+	Name:      "check_singleflight_count",
+	Help:      "The total number of ResolveCheck calls seen by the singleflight check resolver, labeled by whether they started a resolution (leader), shared an in-flight one (shared) or were not eligible for sharing (bypassed).",
+}, []string{"outcome"})
+
+type checkFlightCtxKey struct{}
+
+// flightOutcome is how a caller of InflightChecks.resolve obtained its response.
+type flightOutcome string
+
+const (
+	// flightLeader is a caller that started a new flight.
+	flightLeader flightOutcome = "leader"
+	// flightShared is a caller that shared an in-flight resolution.
+	flightShared flightOutcome = "shared"
+	// flightBypassed is a caller that resolved on its own, as sharing the in-flight resolution could never complete.
+	flightBypassed flightOutcome = "bypassed"
+)
+
+// inflightChecksShards is the number of shards of the flights of an InflightChecks, each with its own lock.
+const inflightChecksShards = 64
+
+// checkFlight is an in-flight resolution of a check sub-problem.
+type checkFlight struct {
+	done    chan struct{}
+	resp    *ResolveCheckResponse
+	err     error
+	cancel  context.CancelFunc
+	waiters int // guarded by the lock of the shard of the flight
+
+	// waitingOn counts, per flight, the callers of that flight that are part of this flight's resolution.
+	mu        sync.Mutex
+	waitingOn map[*checkFlight]int
+}
+
+// waitOn records that a caller that is part of the resolution of f joined other.
+func (f *checkFlight) waitOn(other *checkFlight) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.waitingOn[other]++
+}
+
+// stopWaitingOn removes a caller recorded by waitOn.
+func (f *checkFlight) stopWaitingOn(other *checkFlight) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.waitingOn[other]--
+	if f.waitingOn[other] == 0 {
+		delete(f.waitingOn, other)
+	}
+}
+
+func (f *checkFlight) waitedOn() []*checkFlight {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	flights := make([]*checkFlight, 0, len(f.waitingOn))
+	for other := range f.waitingOn {
+		flights = append(flights, other)
+	}
+	return flights
+}
+
+// reaches reports whether target is f, or a flight f is (transitively) waiting on. It holds the lock of a single
+// flight at a time.
+func (f *checkFlight) reaches(target *checkFlight) bool {
+	visited := map[*checkFlight]struct{}{}
+	stack := []*checkFlight{f}
+	for len(stack) > 0 {
+		next := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if next == target {
+			return true
+		}
+		if _, ok := visited[next]; ok {
+			continue
+		}
+		visited[next] = struct{}{}
+		stack = append(stack, next.waitedOn()...)
+	}
+	return false
+}
+
+type inflightChecksShard struct {
+	mu      sync.Mutex
+	flights map[string]*checkFlight
+}
+
+// InflightChecks holds the check sub-problems being resolved, so that identical concurrent sub-problems
+// share a single resolution. An InflightChecks is meant to be shared by the SingleflightCheckResolver of
+// every request.
+type InflightChecks struct {
+	shards [inflightChecksShards]inflightChecksShard
+}
+
+// NewInflightChecks returns an empty InflightChecks.
+func NewInflightChecks() *InflightChecks {
+	i := &InflightChecks{}
+	for n := range i.shards {
+		i.shards[n].flights = map[string]*checkFlight{}
+	}
+	return i
+}
+
+func (i *InflightChecks) shard(key string) *inflightChecksShard {
+	return &i.shards[xxhash.Sum64String(key)%inflightChecksShards]
+}
+
+// currentCheckFlight returns the flight whose resolution ctx is part of, if any.
+func currentCheckFlight(ctx context.Context) *checkFlight {
+	current, _ := ctx.Value(checkFlightCtxKey{}).(*checkFlight)
+	return current
+}
+
+// newCheckFlightContext returns the context a flight is resolved with. It holds the values of the context of the
+// caller that started the flight, such as its store ID and RPC info, but is not cancelled with it, as the resolution
+// outlives that caller if others are waiting for it.
+func newCheckFlightContext(ctx context.Context, f *checkFlight) context.Context {
+	return context.WithValue(context.WithoutCancel(ctx), checkFlightCtxKey{}, f)
+}
+
+// resolve resolves req with resolve, sharing the resolution of the identical sub-problem key if it is in flight.
+// Each caller returns as soon as its own context is done; the shared resolution is cancelled once no caller is
+// waiting for it.
+func (i *InflightChecks) resolve(
+	ctx context.Context,
+	key string,
+	req *ResolveCheckRequest,
+	resolve func(ctx context.Context, req *ResolveCheckRequest) (*ResolveCheckResponse, error),
+) (*ResolveCheckResponse, flightOutcome, error) {
+	current := currentCheckFlight(ctx)
+
+	// the request may be modified by resolve (e.g. its visited paths) while the caller still holds it
+	flightReq := req.clone()
+	f, leader := i.join(ctx, key, current, func(ctx context.Context) (*ResolveCheckResponse, error) {
+		return resolve(ctx, flightReq)
+	})
+	if f == nil {
+		resp, err := resolve(ctx, req)
+		return resp, flightBypassed, err
+	}
+	defer i.leave(key, f, current)
+
+	outcome := flightShared
+	if leader {
+		outcome = flightLeader
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, outcome, ctx.Err()
+	case <-f.done:
+	}
+
+	// cycles and the resolution depth depend on the path to the sub-problem, which differs between callers
+	if !leader && (f.resp.GetCycleDetected() || errors.Is(f.err, ErrResolutionDepthExceeded)) {
+		resp, err := resolve(ctx, req)
+		return resp, flightBypassed, err
+	}
+
+	if f.err != nil {
+		return nil, outcome, f.err
+	}
+	return f.resp.clone(), outcome, nil
+}
+
+// join returns the flight of key, starting it with resolve if there is none, on behalf of a caller that is part
+// of the resolution of current (which may be nil). It returns nil if the caller must resolve on its own, because
+// the flight of key waits on current: sharing it would never complete.
+func (i *InflightChecks) join(
+	ctx context.Context,
+	key string,
+	current *checkFlight,
+	resolve func(ctx context.Context) (*ResolveCheckResponse, error),
+) (f *checkFlight, leader bool) {
+	shard := i.shard(key)
+	shard.mu.Lock()
+	f, ok := shard.flights[key]
+	if !ok {
+		leader = true
+		f = &checkFlight{
+			done:      make(chan struct{}),
+			waitingOn: map[*checkFlight]int{},
+		}
+		shard.flights[key] = f
+
+		var flightCtx context.Context
+		flightCtx, f.cancel = context.WithCancel(newCheckFlightContext(ctx, f))
+
+		go func() {
+			resp, err := resolve(flightCtx)
+
+			shard.mu.Lock()
+			if shard.flights[key] == f {
+				delete(shard.flights, key)
+			}
+			f.resp, f.err = resp, err
+			shard.mu.Unlock()
+
+			close(f.done)
+			f.cancel()
+		}()
+	}
+	f.waiters++
+	shard.mu.Unlock()
+
+	if current == nil {
+		return f, leader
+	}
+
+	// the wait is recorded before looking for a cycle, so that of two flights joining each other concurrently
+	// at least the last one to record its wait sees the cycle
+	current.waitOn(f)
+	if f.reaches(current) {
+		i.leave(key, f, current)
+		return nil, false
+	}
+	return f, leader
+}
+
+// leave removes a caller that joined f. The resolution is cancelled if it has no callers left.
+func (i *InflightChecks) leave(key string, f, current *checkFlight) {
+	if current != nil {
+		current.stopWaitingOn(f)
+	}
+
+	shard := i.shard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	f.waiters--
+	if f.waiters == 0 {
+		if shard.flights[key] == f {
+			delete(shard.flights, key)
+		}
+		f.cancel()
+	}
+}
+
+// SingleflightCheckResolver coalesces identical concurrent check sub-problems, as identified by BuildCacheKey,
+// so that they share a single resolution by the delegate. Each caller still returns as soon as its own context is
+// done; the shared resolution is cancelled once no caller is waiting for it.
+//
+// Requests with HIGHER_CONSISTENCY are never coalesced, as an in-flight resolution may have read the datastore
+// before the request was made.
+type SingleflightCheckResolver struct {
+	delegate CheckResolver
+	inflight *InflightChecks
+}
+
+var _ CheckResolver = (*SingleflightCheckResolver)(nil)
+
+// SingleflightCheckResolverOpt defines an option that can be used to change the behavior of SingleflightCheckResolver
+// instance.
+type SingleflightCheckResolverOpt func(*SingleflightCheckResolver)
+
+// WithInflightChecks sets the in-flight sub-problems to coalesce with. Without it, only the sub-problems of the
+// requests resolved by the same SingleflightCheckResolver are coalesced.
+func WithInflightChecks(inflight *InflightChecks) SingleflightCheckResolverOpt {
+	return func(r *SingleflightCheckResolver) {
+		r.inflight = inflight
+	}
+}
+
+func NewSingleflightCheckResolver(opts ...SingleflightCheckResolverOpt) *SingleflightCheckResolver {
+	r := &SingleflightCheckResolver{}
+	r.delegate = r
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.inflight == nil {
+		r.inflight = NewInflightChecks()
+	}
+	return r
+}
+
+func (r *SingleflightCheckResolver) SetDelegate(delegate CheckResolver) {
+	r.delegate = delegate
+}
+
+func (r *SingleflightCheckResolver) GetDelegate() CheckResolver {
+	return r.delegate
+}
+
+func (r *SingleflightCheckResolver) Close() {}
+
+func (r *SingleflightCheckResolver) ResolveCheck(ctx context.Context, req *ResolveCheckRequest) (*ResolveCheckResponse, error) {
+	if !r.eligible(req, currentCheckFlight(ctx)) {
+		singleflightCounter.WithLabelValues(string(flightBypassed)).Inc()
+		return r.delegate.ResolveCheck(ctx, req)
+	}
+
+	resp, outcome, err := r.inflight.resolve(ctx, BuildCacheKey(*req), req, r.delegate.ResolveCheck)
+	singleflightCounter.WithLabelValues(string(outcome)).Inc()
+	if outcome == flightShared {
+		trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("singleflight_shared", true))
+	}
+	return resp, err
+}
+
+// eligible returns whether req may share the resolution of an identical sub-problem.
+func (r *SingleflightCheckResolver) eligible(req *ResolveCheckRequest, current *checkFlight) bool {
+	if req.GetConsistency() == openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY {
+		return false
+	}
+
+	// a nested sub-problem outside of any flight was dispatched by another node, which may be waiting on
+	// a flight of this node: sharing a flight could then never complete
+	if current == nil && req.GetRequestMetadata().Depth > 0 {
+		return false
+	}
+
+	// a sub-problem that is one of its own ancestors is a cycle, which is for the delegate to detect
+	_, cycle := req.GetVisitedPaths()[tuple.TupleKeyToString(req.GetTupleKey())]
+	return !cycle
+}
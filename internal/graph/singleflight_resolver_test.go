@@ -0,0 +1,313 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/middleware/storeid"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/telemetry"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// funcCheckResolver is a CheckResolver that resolves with the provided function.
+type funcCheckResolver struct {
+	resolve func(ctx context.Context, req *ResolveCheckRequest) (*ResolveCheckResponse, error)
+}
+
+func (f *funcCheckResolver) ResolveCheck(ctx context.Context, req *ResolveCheckRequest) (*ResolveCheckResponse, error) {
+	return f.resolve(ctx, req)
+}
+
+func (f *funcCheckResolver) Close()                    {}
+func (f *funcCheckResolver) SetDelegate(CheckResolver) {}
+func (f *funcCheckResolver) GetDelegate() CheckResolver {
+	return nil
+}
+
+func newSingleflightTestRequest(t *testing.T, object string, consistency openfgav1.ConsistencyPreference) *ResolveCheckRequest {
+	req, err := NewResolveCheckRequest(ResolveCheckRequestParams{
+		StoreID:              "store",
+		AuthorizationModelID: "model",
+		TupleKey:             tuple.NewTupleKey(object, "viewer", "user:anne"),
+		Consistency:          consistency,
+	})
+	require.NoError(t, err)
+	return req
+}
+
+// flightWaiters returns the number of callers of the flight of key, or -1 if it is not in flight.
+func (i *InflightChecks) flightWaiters(key string) int {
+	shard := i.shard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	if f, ok := shard.flights[key]; ok {
+		return f.waiters
+	}
+	return -1
+}
+
+func TestSingleflightCheckResolver(t *testing.T) {
+	key := BuildCacheKey(*newSingleflightTestRequest(t, "document:1", openfgav1.ConsistencyPreference_UNSPECIFIED))
+
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	t.Run("coalesces_identical_requests", func(t *testing.T) {
+		var calls atomic.Int32
+		release := make(chan struct{})
+		r := NewSingleflightCheckResolver()
+		r.SetDelegate(&funcCheckResolver{resolve: func(ctx context.Context, req *ResolveCheckRequest) (*ResolveCheckResponse, error) {
+			calls.Add(1)
+			<-release
+			return &ResolveCheckResponse{Allowed: true}, nil
+		}})
+
+		const numCallers = 10
+		var wg sync.WaitGroup
+		responses := make([]*ResolveCheckResponse, numCallers)
+		for i := 0; i < numCallers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				resp, err := r.ResolveCheck(context.Background(), newSingleflightTestRequest(t, "document:1", openfgav1.ConsistencyPreference_UNSPECIFIED))
+				require.NoError(t, err)
+				responses[i] = resp
+			}(i)
+		}
+
+		require.Eventually(t, func() bool {
+			return r.inflight.flightWaiters(key) == numCallers
+		}, time.Second, time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		require.Equal(t, int32(1), calls.Load())
+		for _, resp := range responses {
+			require.True(t, resp.GetAllowed())
+		}
+		// every caller gets its own copy of the response
+		require.NotSame(t, responses[0], responses[1])
+	})
+
+	t.Run("higher_consistency_is_not_coalesced", func(t *testing.T) {
+		var calls atomic.Int32
+		var started sync.WaitGroup
+		started.Add(2)
+		r := NewSingleflightCheckResolver()
+		r.SetDelegate(&funcCheckResolver{resolve: func(ctx context.Context, req *ResolveCheckRequest) (*ResolveCheckResponse, error) {
+			calls.Add(1)
+			started.Done()
+			started.Wait()
+			return &ResolveCheckResponse{Allowed: true}, nil
+		}})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := r.ResolveCheck(context.Background(), newSingleflightTestRequest(t, "document:1", openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY))
+				require.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+		require.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("cancelled_caller_does_not_cancel_the_others", func(t *testing.T) {
+		release := make(chan struct{})
+		var delegateCtxErr atomic.Value
+		r := NewSingleflightCheckResolver()
+		r.SetDelegate(&funcCheckResolver{resolve: func(ctx context.Context, req *ResolveCheckRequest) (*ResolveCheckResponse, error) {
+			<-release
+			if ctx.Err() != nil {
+				delegateCtxErr.Store(ctx.Err())
+			}
+			return &ResolveCheckResponse{Allowed: true}, nil
+		}})
+
+		leaderCtx, cancelLeader := context.WithCancel(context.Background())
+		leaderErr := make(chan error, 1)
+		go func() {
+			_, err := r.ResolveCheck(leaderCtx, newSingleflightTestRequest(t, "document:1", openfgav1.ConsistencyPreference_UNSPECIFIED))
+			leaderErr <- err
+		}()
+
+		followerResp := make(chan *ResolveCheckResponse, 1)
+		require.Eventually(t, func() bool {
+			return r.inflight.flightWaiters(key) == 1
+		}, time.Second, time.Millisecond)
+		go func() {
+			resp, err := r.ResolveCheck(context.Background(), newSingleflightTestRequest(t, "document:1", openfgav1.ConsistencyPreference_UNSPECIFIED))
+			require.NoError(t, err)
+			followerResp <- resp
+		}()
+
+		require.Eventually(t, func() bool {
+			return r.inflight.flightWaiters(key) == 2
+		}, time.Second, time.Millisecond)
+
+		cancelLeader()
+		require.ErrorIs(t, <-leaderErr, context.Canceled)
+
+		close(release)
+		require.True(t, (<-followerResp).GetAllowed())
+		require.Nil(t, delegateCtxErr.Load())
+	})
+
+	t.Run("resolution_context_carries_the_values_of_the_caller_without_its_deadline", func(t *testing.T) {
+		typesys := &typesystem.TypeSystem{}
+		ds := memory.New()
+		t.Cleanup(ds.Close)
+		memo := NewCheckMemo()
+
+		r := NewSingleflightCheckResolver()
+		r.SetDelegate(&funcCheckResolver{resolve: func(ctx context.Context, req *ResolveCheckRequest) (*ResolveCheckResponse, error) {
+			resolveTypesys, _ := typesystem.TypesystemFromContext(ctx)
+			resolveDS, _ := storage.RelationshipTupleReaderFromContext(ctx)
+			resolveMemo, _ := CheckMemoFromContext(ctx)
+			resolveStoreID, _ := storeid.StoreIDFromContext(ctx)
+			_, hasDeadline := ctx.Deadline()
+			return &ResolveCheckResponse{Allowed: resolveTypesys == typesys && resolveDS == ds && resolveMemo == memo &&
+				resolveStoreID == "store" && telemetry.RPCInfoFromContext(ctx).Method == "Check" && !hasDeadline}, nil
+		}})
+
+		ctx, cancel := context.WithTimeout(testutils.ContextWithStoreID(t, "store"), time.Minute)
+		t.Cleanup(cancel)
+		ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{Service: "openfga.v1.OpenFGAService", Method: "Check"})
+		ctx = typesystem.ContextWithTypesystem(ctx, typesys)
+		ctx = storage.ContextWithRelationshipTupleReader(ctx, ds)
+		ctx = ContextWithCheckMemo(ctx, memo)
+
+		resp, err := r.ResolveCheck(ctx, newSingleflightTestRequest(t, "document:1", openfgav1.ConsistencyPreference_UNSPECIFIED))
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+	})
+
+	t.Run("resolution_is_cancelled_without_callers", func(t *testing.T) {
+		delegateCtxDone := make(chan struct{})
+		r := NewSingleflightCheckResolver()
+		r.SetDelegate(&funcCheckResolver{resolve: func(ctx context.Context, req *ResolveCheckRequest) (*ResolveCheckResponse, error) {
+			<-ctx.Done()
+			close(delegateCtxDone)
+			return nil, ctx.Err()
+		}})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+		}()
+		_, err := r.ResolveCheck(ctx, newSingleflightTestRequest(t, "document:1", openfgav1.ConsistencyPreference_UNSPECIFIED))
+		require.ErrorIs(t, err, context.Canceled)
+
+		select {
+		case <-delegateCtxDone:
+		case <-time.After(time.Second):
+			require.FailNow(t, "the resolution was not cancelled")
+		}
+	})
+
+	t.Run("followers_resolve_cycles_on_their_own", func(t *testing.T) {
+		var calls atomic.Int32
+		release := make(chan struct{})
+		r := NewSingleflightCheckResolver()
+		r.SetDelegate(&funcCheckResolver{resolve: func(ctx context.Context, req *ResolveCheckRequest) (*ResolveCheckResponse, error) {
+			if calls.Add(1) == 1 {
+				<-release
+				return &ResolveCheckResponse{ResolutionMetadata: ResolveCheckResponseMetadata{CycleDetected: true}}, nil
+			}
+			return &ResolveCheckResponse{Allowed: true}, nil
+		}})
+
+		leaderResp := make(chan *ResolveCheckResponse, 1)
+		go func() {
+			resp, err := r.ResolveCheck(context.Background(), newSingleflightTestRequest(t, "document:1", openfgav1.ConsistencyPreference_UNSPECIFIED))
+			require.NoError(t, err)
+			leaderResp <- resp
+		}()
+		require.Eventually(t, func() bool {
+			return calls.Load() == 1
+		}, time.Second, time.Millisecond)
+
+		followerResp := make(chan *ResolveCheckResponse, 1)
+		go func() {
+			resp, err := r.ResolveCheck(context.Background(), newSingleflightTestRequest(t, "document:1", openfgav1.ConsistencyPreference_UNSPECIFIED))
+			require.NoError(t, err)
+			followerResp <- resp
+		}()
+		require.Eventually(t, func() bool {
+			return r.inflight.flightWaiters(key) == 2
+		}, time.Second, time.Millisecond)
+		close(release)
+
+		require.True(t, (<-leaderResp).GetCycleDetected())
+		require.True(t, (<-followerResp).GetAllowed())
+		require.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("flights_waiting_on_each_other_do_not_deadlock", func(t *testing.T) {
+		// document:1 depends on document:2 and vice versa, and both are resolved concurrently
+		inflight := NewInflightChecks()
+		var started sync.WaitGroup
+		started.Add(2)
+
+		r := NewSingleflightCheckResolver(WithInflightChecks(inflight))
+		r.SetDelegate(&funcCheckResolver{resolve: func(ctx context.Context, req *ResolveCheckRequest) (*ResolveCheckResponse, error) {
+			if req.GetRequestMetadata().Depth > 0 {
+				return &ResolveCheckResponse{Allowed: true}, nil
+			}
+			started.Done()
+			started.Wait()
+
+			other := "document:2"
+			if req.GetTupleKey().GetObject() == other {
+				other = "document:1"
+			}
+			child := req.clone()
+			child.TupleKey = tuple.NewTupleKey(other, "viewer", "user:anne")
+			child.GetRequestMetadata().Depth++
+			return r.ResolveCheck(ctx, child)
+		}})
+
+		var wg sync.WaitGroup
+		for _, object := range []string{"document:1", "document:2"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				resp, err := r.ResolveCheck(ctx, newSingleflightTestRequest(t, object, openfgav1.ConsistencyPreference_UNSPECIFIED))
+				require.NoError(t, err)
+				require.True(t, resp.GetAllowed())
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("nested_requests_from_other_nodes_are_not_coalesced", func(t *testing.T) {
+		r := NewSingleflightCheckResolver()
+		req := newSingleflightTestRequest(t, "document:1", openfgav1.ConsistencyPreference_UNSPECIFIED)
+		req.GetRequestMetadata().Depth = 2
+		require.False(t, r.eligible(req, nil))
+		require.True(t, r.eligible(req, &checkFlight{}))
+
+		req.GetRequestMetadata().Depth = 0
+		require.True(t, r.eligible(req, nil))
+		req.VisitedPaths = map[string]struct{}{tuple.TupleKeyToString(req.GetTupleKey()): {}}
+		require.False(t, r.eligible(req, nil))
+	})
+}
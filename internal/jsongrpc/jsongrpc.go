@@ -0,0 +1,140 @@
+// Package jsongrpc holds what the gRPC services whose messages are not generated from protobuf definitions
+// share. Their messages are exchanged as JSON, under a gRPC content-subtype of their own, and their
+// user-facing methods are exposed on the HTTP gateway next to the ones of the OpenFGA service.
+package jsongrpc
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/status"
+)
+
+type codec struct {
+	name string
+}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (c codec) Name() string {
+	return c.name
+}
+
+// RegisterCodec registers the gRPC codec that exchanges messages as JSON under the content-subtype name.
+// It must be called from an init function of the package of the service.
+func RegisterCodec(name string) {
+	encoding.RegisterCodec(codec{name: name})
+}
+
+// StoreRequest is embedded in the requests that target a store.
+type StoreRequest struct {
+	StoreID string `json:"store_id"`
+}
+
+// GetStoreId allows the store ID to be picked up by the store ID interceptor like any other request.
+//
+//nolint:revive,stylecheck // matches the generated protobuf getter name used by the interceptors.
+func (r *StoreRequest) GetStoreId() string {
+	if r == nil {
+		return ""
+	}
+	return r.StoreID
+}
+
+// UnaryHandler returns the grpc.MethodHandler of the unary method fullMethod, which call serves.
+// call is typically a method expression of the server interface of the service, e.g. Server.Method.
+func UnaryHandler[Srv, Req, Resp any](fullMethod string, call func(Srv, context.Context, *Req) (*Resp, error)) grpc.MethodHandler {
+	return func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+		in := new(Req)
+		if err := dec(in); err != nil {
+			return nil, err
+		}
+
+		if interceptor == nil {
+			return call(srv.(Srv), ctx, in)
+		}
+
+		info := &grpc.UnaryServerInfo{
+			Server:     srv,
+			FullMethod: fullMethod,
+		}
+		handler := func(ctx context.Context, req any) (any, error) {
+			return call(srv.(Srv), ctx, req.(*Req))
+		}
+		return interceptor(ctx, in, info, handler)
+	}
+}
+
+// Invoke calls the unary method fullMethod on the provided connection, with the codec of the content-subtype codecName.
+func Invoke[Resp any](ctx context.Context, conn grpc.ClientConnInterface, codecName, fullMethod string, req any, opts ...grpc.CallOption) (*Resp, error) {
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(codecName)}, opts...)
+	out := new(Resp)
+	if err := conn.Invoke(ctx, fullMethod, req, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// HandleHTTP exposes the unary method fullMethod on the HTTP gateway mux, for the HTTP method and path pattern,
+// e.g. "/stores/{store_id}/list-relations". The body of the HTTP request, if any, is decoded as the JSON of a new
+// Req, on which setStoreID sets the store ID of the path. The method is then invoked on conn, like the methods
+// of the OpenFGA service are, so that the request goes through the same interceptors as a gRPC one.
+func HandleHTTP[Req, Resp any](
+	mux *runtime.ServeMux,
+	httpMethod, pattern string,
+	conn grpc.ClientConnInterface,
+	codecName, fullMethod string,
+	setStoreID func(req *Req, storeID string),
+) error {
+	return mux.HandlePath(httpMethod, pattern, func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		_, outbound := runtime.MarshalerForRequest(mux, r)
+		ctx, err := runtime.AnnotateContext(ctx, mux, r, fullMethod, runtime.WithHTTPPathPattern(pattern))
+		if err != nil {
+			runtime.HTTPError(ctx, mux, outbound, w, r, err)
+			return
+		}
+
+		req := new(Req)
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			runtime.HTTPError(ctx, mux, outbound, w, r, status.Errorf(codes.InvalidArgument, "%v", err))
+			return
+		}
+		if len(body) > 0 {
+			if err := json.Unmarshal(body, req); err != nil {
+				runtime.HTTPError(ctx, mux, outbound, w, r, status.Errorf(codes.InvalidArgument, "%v", err))
+				return
+			}
+		}
+		setStoreID(req, pathParams["store_id"])
+
+		resp, err := Invoke[Resp](ctx, conn, codecName, fullMethod, req)
+		if err != nil {
+			runtime.HTTPError(ctx, mux, outbound, w, r, err)
+			return
+		}
+
+		data, err := json.Marshal(resp)
+		if err != nil {
+			runtime.HTTPError(ctx, mux, outbound, w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	})
+}
@@ -0,0 +1,170 @@
+package jsongrpc
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	testCodecName  = "openfga-jsongrpc-test-json"
+	testMethodName = "/openfga.jsongrpc.v1.TestService/Echo"
+)
+
+func init() {
+	RegisterCodec(testCodecName)
+}
+
+type echoRequest struct {
+	StoreRequest
+	Message string `json:"message"`
+}
+
+type echoResponse struct {
+	StoreID string `json:"store_id"`
+	Message string `json:"message"`
+}
+
+type echoServer interface {
+	Echo(ctx context.Context, req *echoRequest) (*echoResponse, error)
+}
+
+type testEchoServer struct{}
+
+func (testEchoServer) Echo(_ context.Context, req *echoRequest) (*echoResponse, error) {
+	if req.Message == "" {
+		return nil, status.Error(codes.InvalidArgument, "empty message")
+	}
+	return &echoResponse{StoreID: req.GetStoreId(), Message: req.Message}, nil
+}
+
+var testServiceDesc = grpc.ServiceDesc{
+	ServiceName: "openfga.jsongrpc.v1.TestService",
+	HandlerType: (*echoServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Echo",
+			Handler:    UnaryHandler(testMethodName, echoServer.Echo),
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "internal/jsongrpc/jsongrpc_test.go",
+}
+
+func newTestConn(t *testing.T, opts ...grpc.ServerOption) *grpc.ClientConn {
+	lis := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer(opts...)
+	grpcServer.RegisterService(&testServiceDesc, testEchoServer{})
+	go func() {
+		_ = grpcServer.Serve(lis)
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+		grpcServer.Stop()
+	})
+	return conn
+}
+
+func TestCodec(t *testing.T) {
+	codec := encoding.GetCodec(testCodecName)
+	require.NotNil(t, codec)
+	require.Equal(t, testCodecName, codec.Name())
+
+	data, err := codec.Marshal(&echoRequest{StoreRequest: StoreRequest{StoreID: "store"}, Message: "hi"})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"store_id":"store","message":"hi"}`, string(data))
+
+	var got echoRequest
+	require.NoError(t, codec.Unmarshal(data, &got))
+	require.Equal(t, "store", got.GetStoreId())
+	require.Equal(t, "hi", got.Message)
+
+	var nilRequest *StoreRequest
+	require.Empty(t, nilRequest.GetStoreId())
+}
+
+func TestUnaryHandler(t *testing.T) {
+	var intercepted string
+	conn := newTestConn(t, grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		intercepted = info.FullMethod + ":" + req.(interface{ GetStoreId() string }).GetStoreId()
+		return handler(ctx, req)
+	}))
+
+	resp, err := Invoke[echoResponse](context.Background(), conn, testCodecName, testMethodName, &echoRequest{
+		StoreRequest: StoreRequest{StoreID: "store"},
+		Message:      "hi",
+	})
+	require.NoError(t, err)
+	require.Equal(t, &echoResponse{StoreID: "store", Message: "hi"}, resp)
+	require.Equal(t, testMethodName+":store", intercepted)
+
+	_, err = Invoke[echoResponse](context.Background(), conn, testCodecName, testMethodName, &echoRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestHandleHTTP(t *testing.T) {
+	conn := newTestConn(t)
+
+	mux := runtime.NewServeMux()
+	require.NoError(t, HandleHTTP[echoRequest, echoResponse](mux, http.MethodPost, "/stores/{store_id}/echo", conn, testCodecName, testMethodName,
+		func(req *echoRequest, storeID string) { req.StoreID = storeID }))
+
+	t.Run("store_id_is_taken_from_the_path", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/stores/store/echo", strings.NewReader(`{"store_id":"other","message":"hi"}`))
+		mux.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		var resp echoResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		require.Equal(t, echoResponse{StoreID: "store", Message: "hi"}, resp)
+	})
+
+	t.Run("invalid_body", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/stores/store/echo", strings.NewReader(`{`))
+		mux.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("errors_are_mapped_to_http_status", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/stores/store/echo", nil)
+		mux.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Contains(t, rec.Body.String(), "empty message")
+	})
+
+	t.Run("other_methods_are_not_handled", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/stores/store/echo", nil)
+		mux.ServeHTTP(rec, req)
+
+		require.NotEqual(t, http.StatusOK, rec.Code)
+	})
+}
@@ -0,0 +1,203 @@
+// Package listcount defines the gRPC service that counts the objects of a ListObjects request, or the users of a
+// ListUsers request, without returning them, for the clients that only need the number of results.
+package listcount
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/jsongrpc"
+)
+
+const (
+	// ServiceName is the fully qualified name of the list count gRPC service.
+	ServiceName = "openfga.listcount.v1.ListCountService"
+
+	countObjectsMethod = "/" + ServiceName + "/CountObjects"
+	countUsersMethod   = "/" + ServiceName + "/CountUsers"
+
+	// codecName is the gRPC content-subtype of the list count service, whose messages are exchanged as JSON.
+	codecName = "openfga-listcount-json"
+)
+
+func init() {
+	jsongrpc.RegisterCodec(codecName)
+}
+
+// CountObjectsRequest asks for the number of objects of Request, estimated if Approximate.
+type CountObjectsRequest struct {
+	Request     *openfgav1.ListObjectsRequest
+	Approximate bool
+}
+
+// CountUsersRequest asks for the number of users of Request, estimated if Approximate.
+type CountUsersRequest struct {
+	Request     *openfgav1.ListUsersRequest
+	Approximate bool
+}
+
+// CountResponse holds the number of results of a request. Approximate is true when Count is an estimate, and
+// Complete is false when the deadline stopped the count before it found every result, in which case Count only
+// counts the results found until then.
+type CountResponse struct {
+	Count       uint64 `json:"count"`
+	Approximate bool   `json:"approximate,omitempty"`
+	Complete    bool   `json:"complete"`
+}
+
+// GetStoreId allows the store ID to be picked up by the store ID interceptor like any other request.
+//
+//nolint:revive,stylecheck // matches the generated protobuf getter name used by the interceptors.
+func (r *CountObjectsRequest) GetStoreId() string {
+	if r == nil {
+		return ""
+	}
+	return r.Request.GetStoreId()
+}
+
+// GetStoreId allows the store ID to be picked up by the store ID interceptor like any other request.
+//
+//nolint:revive,stylecheck // matches the generated protobuf getter name used by the interceptors.
+func (r *CountUsersRequest) GetStoreId() string {
+	if r == nil {
+		return ""
+	}
+	return r.Request.GetStoreId()
+}
+
+// wireCountRequest is the JSON representation of CountObjectsRequest and CountUsersRequest. The counted request
+// is encoded with protojson because structpb values cannot be round-tripped through encoding/json.
+type wireCountRequest struct {
+	Request     json.RawMessage `json:"request,omitempty"`
+	Approximate bool            `json:"approximate,omitempty"`
+}
+
+func (r *CountObjectsRequest) MarshalJSON() ([]byte, error) {
+	w := wireCountRequest{Approximate: r.Approximate}
+
+	if r.Request != nil {
+		var err error
+		if w.Request, err = protojson.Marshal(r.Request); err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+
+	return json.Marshal(w)
+}
+
+func (r *CountObjectsRequest) UnmarshalJSON(data []byte) error {
+	var w wireCountRequest
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+
+	*r = CountObjectsRequest{Approximate: w.Approximate}
+
+	if len(w.Request) > 0 {
+		r.Request = &openfgav1.ListObjectsRequest{}
+		if err := protojson.Unmarshal(w.Request, r.Request); err != nil {
+			return fmt.Errorf("failed to unmarshal request: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *CountUsersRequest) MarshalJSON() ([]byte, error) {
+	w := wireCountRequest{Approximate: r.Approximate}
+
+	if r.Request != nil {
+		var err error
+		if w.Request, err = protojson.Marshal(r.Request); err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+
+	return json.Marshal(w)
+}
+
+func (r *CountUsersRequest) UnmarshalJSON(data []byte) error {
+	var w wireCountRequest
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+
+	*r = CountUsersRequest{Approximate: w.Approximate}
+
+	if len(w.Request) > 0 {
+		r.Request = &openfgav1.ListUsersRequest{}
+		if err := protojson.Unmarshal(w.Request, r.Request); err != nil {
+			return fmt.Errorf("failed to unmarshal request: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ListCountServer is implemented by the node that serves the list count service.
+type ListCountServer interface {
+	CountObjects(ctx context.Context, req *CountObjectsRequest) (*CountResponse, error)
+	CountUsers(ctx context.Context, req *CountUsersRequest) (*CountResponse, error)
+}
+
+// RegisterListCountServer registers the list count service on the provided gRPC server.
+func RegisterListCountServer(s grpc.ServiceRegistrar, srv ListCountServer) {
+	s.RegisterService(&ServiceDesc, srv)
+}
+
+// ServiceDesc is the grpc.ServiceDesc of the list count service.
+var ServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*ListCountServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CountObjects",
+			Handler:    jsongrpc.UnaryHandler(countObjectsMethod, ListCountServer.CountObjects),
+		},
+		{
+			MethodName: "CountUsers",
+			Handler:    jsongrpc.UnaryHandler(countUsersMethod, ListCountServer.CountUsers),
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "internal/listcount/service.go",
+}
+
+// CountObjects calls CountObjects on the provided connection.
+func CountObjects(ctx context.Context, conn grpc.ClientConnInterface, req *CountObjectsRequest, opts ...grpc.CallOption) (*CountResponse, error) {
+	return jsongrpc.Invoke[CountResponse](ctx, conn, codecName, countObjectsMethod, req, opts...)
+}
+
+// CountUsers calls CountUsers on the provided connection.
+func CountUsers(ctx context.Context, conn grpc.ClientConnInterface, req *CountUsersRequest, opts ...grpc.CallOption) (*CountResponse, error) {
+	return jsongrpc.Invoke[CountResponse](ctx, conn, codecName, countUsersMethod, req, opts...)
+}
+
+// RegisterListCountHandler exposes CountObjects and CountUsers on the HTTP gateway mux, as
+// POST /stores/{store_id}/count-objects and POST /stores/{store_id}/count-users.
+func RegisterListCountHandler(mux *runtime.ServeMux, conn grpc.ClientConnInterface) error {
+	if err := jsongrpc.HandleHTTP[CountObjectsRequest, CountResponse](mux, http.MethodPost, "/stores/{store_id}/count-objects", conn, codecName, countObjectsMethod,
+		func(req *CountObjectsRequest, storeID string) {
+			if req.Request == nil {
+				req.Request = &openfgav1.ListObjectsRequest{}
+			}
+			req.Request.StoreId = storeID
+		}); err != nil {
+		return err
+	}
+	return jsongrpc.HandleHTTP[CountUsersRequest, CountResponse](mux, http.MethodPost, "/stores/{store_id}/count-users", conn, codecName, countUsersMethod,
+		func(req *CountUsersRequest, storeID string) {
+			if req.Request == nil {
+				req.Request = &openfgav1.ListUsersRequest{}
+			}
+			req.Request.StoreId = storeID
+		})
+}
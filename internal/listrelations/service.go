@@ -0,0 +1,159 @@
+// Package listrelations defines the gRPC service that evaluates several relations of an object for
+// a single user in one request, e.g. to render which actions a user may take on a resource.
+package listrelations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/jsongrpc"
+)
+
+const (
+	// ServiceName is the fully qualified name of the list relations gRPC service.
+	ServiceName = "openfga.relations.v1.RelationsService"
+
+	listRelationsMethod = "/" + ServiceName + "/ListRelations"
+
+	// codecName is the gRPC content-subtype of the list relations service, whose messages are exchanged as JSON.
+	codecName = "openfga-relations-json"
+)
+
+func init() {
+	jsongrpc.RegisterCodec(codecName)
+}
+
+// ListRelationsRequest asks which of Relations the User has on the Object. If Relations is empty,
+// every relation of the object type is evaluated.
+type ListRelationsRequest struct {
+	jsongrpc.StoreRequest
+	AuthorizationModelID string
+	Object               string
+	User                 string
+	Relations            []string
+	ContextualTuples     []*openfgav1.TupleKey
+	Context              *structpb.Struct
+	Consistency          openfgav1.ConsistencyPreference
+}
+
+// ListRelationsResponse holds, for every evaluated relation, whether the user has it on the object.
+type ListRelationsResponse struct {
+	Relations map[string]bool `json:"relations"`
+}
+
+// wireListRelationsRequest is the JSON representation of ListRelationsRequest. Protobuf fields are
+// encoded with protojson because structpb values cannot be round-tripped through encoding/json.
+type wireListRelationsRequest struct {
+	StoreID              string          `json:"store_id"`
+	AuthorizationModelID string          `json:"authorization_model_id,omitempty"`
+	Object               string          `json:"object"`
+	User                 string          `json:"user"`
+	Relations            []string        `json:"relations,omitempty"`
+	ContextualTuples     json.RawMessage `json:"contextual_tuples,omitempty"`
+	Context              json.RawMessage `json:"context,omitempty"`
+	Consistency          int32           `json:"consistency,omitempty"`
+}
+
+func (r *ListRelationsRequest) MarshalJSON() ([]byte, error) {
+	w := wireListRelationsRequest{
+		StoreID:              r.StoreID,
+		AuthorizationModelID: r.AuthorizationModelID,
+		Object:               r.Object,
+		User:                 r.User,
+		Relations:            r.Relations,
+		Consistency:          int32(r.Consistency),
+	}
+
+	var err error
+	if len(r.ContextualTuples) > 0 {
+		w.ContextualTuples, err = protojson.Marshal(&openfgav1.ContextualTupleKeys{TupleKeys: r.ContextualTuples})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal contextual tuples: %w", err)
+		}
+	}
+
+	if r.Context != nil {
+		if w.Context, err = protojson.Marshal(r.Context); err != nil {
+			return nil, fmt.Errorf("failed to marshal context: %w", err)
+		}
+	}
+
+	return json.Marshal(w)
+}
+
+func (r *ListRelationsRequest) UnmarshalJSON(data []byte) error {
+	var w wireListRelationsRequest
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+
+	*r = ListRelationsRequest{
+		StoreRequest:         jsongrpc.StoreRequest{StoreID: w.StoreID},
+		AuthorizationModelID: w.AuthorizationModelID,
+		Object:               w.Object,
+		User:                 w.User,
+		Relations:            w.Relations,
+		Consistency:          openfgav1.ConsistencyPreference(w.Consistency),
+	}
+
+	if len(w.ContextualTuples) > 0 {
+		var contextualTuples openfgav1.ContextualTupleKeys
+		if err := protojson.Unmarshal(w.ContextualTuples, &contextualTuples); err != nil {
+			return fmt.Errorf("failed to unmarshal contextual tuples: %w", err)
+		}
+		r.ContextualTuples = contextualTuples.GetTupleKeys()
+	}
+
+	if len(w.Context) > 0 {
+		r.Context = &structpb.Struct{}
+		if err := protojson.Unmarshal(w.Context, r.Context); err != nil {
+			return fmt.Errorf("failed to unmarshal context: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// RelationsServer is implemented by the node that serves ListRelations.
+type RelationsServer interface {
+	ListRelations(ctx context.Context, req *ListRelationsRequest) (*ListRelationsResponse, error)
+}
+
+// RegisterRelationsServer registers the list relations service on the provided gRPC server.
+func RegisterRelationsServer(s grpc.ServiceRegistrar, srv RelationsServer) {
+	s.RegisterService(&ServiceDesc, srv)
+}
+
+// ServiceDesc is the grpc.ServiceDesc of the list relations service.
+var ServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*RelationsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListRelations",
+			Handler:    jsongrpc.UnaryHandler(listRelationsMethod, RelationsServer.ListRelations),
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "internal/listrelations/service.go",
+}
+
+// ListRelations calls ListRelations on the provided connection.
+func ListRelations(ctx context.Context, conn grpc.ClientConnInterface, req *ListRelationsRequest, opts ...grpc.CallOption) (*ListRelationsResponse, error) {
+	return jsongrpc.Invoke[ListRelationsResponse](ctx, conn, codecName, listRelationsMethod, req, opts...)
+}
+
+// RegisterRelationsHandler exposes ListRelations on the HTTP gateway mux, as POST /stores/{store_id}/list-relations.
+func RegisterRelationsHandler(mux *runtime.ServeMux, conn grpc.ClientConnInterface) error {
+	return jsongrpc.HandleHTTP[ListRelationsRequest, ListRelationsResponse](mux, http.MethodPost, "/stores/{store_id}/list-relations", conn, codecName, listRelationsMethod,
+		func(req *ListRelationsRequest, storeID string) { req.StoreID = storeID })
+}
@@ -2,8 +2,11 @@ package authn
 
 import (
 	"context"
+	"net/http"
+	"strings"
 
 	grpcauth "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
+	"google.golang.org/grpc/metadata"
 
 	"github.com/openfga/openfga/internal/authn"
 	"github.com/openfga/openfga/pkg/authclaims"
@@ -19,3 +22,22 @@ func AuthFunc(authenticator authn.Authenticator) grpcauth.AuthFunc {
 		return authclaims.ContextWithAuthClaims(ctx, claims), nil
 	}
 }
+
+// HTTPHandler returns a handler that serves next only to the requests that authenticator authenticates, e.g.
+// with their "Authorization: Bearer" header, and that responds 401 to the others. It is meant for the
+// handlers that are served outside of the gRPC server and the HTTP gateway.
+func HTTPHandler(authenticator authn.Authenticator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		md := metadata.MD{}
+		for name, values := range r.Header {
+			md.Append(strings.ToLower(name), values...)
+		}
+
+		ctx, err := AuthFunc(authenticator)(metadata.NewIncomingContext(r.Context(), md))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,44 @@
+package authn
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/internal/authn/presharedkey"
+	"github.com/openfga/openfga/pkg/authclaims"
+)
+
+func TestHTTPHandler(t *testing.T) {
+	authenticator, err := presharedkey.NewPresharedKeyAuthenticator([]string{"key"})
+	require.NoError(t, err)
+
+	handler := HTTPHandler(authenticator, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := authclaims.AuthClaimsFromContext(r.Context())
+		require.True(t, ok)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := map[string]struct {
+		authorization string
+		expectedCode  int
+	}{
+		"valid_key":   {authorization: "Bearer key", expectedCode: http.StatusNoContent},
+		"invalid_key": {authorization: "Bearer other", expectedCode: http.StatusUnauthorized},
+		"missing_key": {expectedCode: http.StatusUnauthorized},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, "/debug/planner", nil)
+			if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			require.Equal(t, test.expectedCode, rec.Code)
+		})
+	}
+}
@@ -12,6 +12,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 	storage "github.com/openfga/openfga/pkg/storage"
@@ -347,6 +348,21 @@ func (mr *MockAuthorizationModelReadBackendMockRecorder) FindLatestAuthorization
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLatestAuthorizationModel", reflect.TypeOf((*MockAuthorizationModelReadBackend)(nil).FindLatestAuthorizationModel), ctx, store)
 }
 
+// ReadActiveAuthorizationModelID mocks base method.
+func (m *MockAuthorizationModelReadBackend) ReadActiveAuthorizationModelID(ctx context.Context, store string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadActiveAuthorizationModelID", ctx, store)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadActiveAuthorizationModelID indicates an expected call of ReadActiveAuthorizationModelID.
+func (mr *MockAuthorizationModelReadBackendMockRecorder) ReadActiveAuthorizationModelID(ctx, store any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadActiveAuthorizationModelID", reflect.TypeOf((*MockAuthorizationModelReadBackend)(nil).ReadActiveAuthorizationModelID), ctx, store)
+}
+
 // ReadAuthorizationModel mocks base method.
 func (m *MockAuthorizationModelReadBackend) ReadAuthorizationModel(ctx context.Context, store, id string) (*openfgav1.AuthorizationModel, error) {
 	m.ctrl.T.Helper()
@@ -416,6 +432,20 @@ func (mr *MockTypeDefinitionWriteBackendMockRecorder) MaxTypesPerAuthorizationMo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxTypesPerAuthorizationModel", reflect.TypeOf((*MockTypeDefinitionWriteBackend)(nil).MaxTypesPerAuthorizationModel))
 }
 
+// WriteActiveAuthorizationModelID mocks base method.
+func (m *MockTypeDefinitionWriteBackend) WriteActiveAuthorizationModelID(ctx context.Context, store, modelID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteActiveAuthorizationModelID", ctx, store, modelID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteActiveAuthorizationModelID indicates an expected call of WriteActiveAuthorizationModelID.
+func (mr *MockTypeDefinitionWriteBackendMockRecorder) WriteActiveAuthorizationModelID(ctx, store, modelID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteActiveAuthorizationModelID", reflect.TypeOf((*MockTypeDefinitionWriteBackend)(nil).WriteActiveAuthorizationModelID), ctx, store, modelID)
+}
+
 // WriteAuthorizationModel mocks base method.
 func (m *MockTypeDefinitionWriteBackend) WriteAuthorizationModel(ctx context.Context, store string, model *openfgav1.AuthorizationModel) error {
 	m.ctrl.T.Helper()
@@ -483,6 +513,21 @@ func (mr *MockAuthorizationModelBackendMockRecorder) MaxTypesPerAuthorizationMod
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxTypesPerAuthorizationModel", reflect.TypeOf((*MockAuthorizationModelBackend)(nil).MaxTypesPerAuthorizationModel))
 }
 
+// ReadActiveAuthorizationModelID mocks base method.
+func (m *MockAuthorizationModelBackend) ReadActiveAuthorizationModelID(ctx context.Context, store string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadActiveAuthorizationModelID", ctx, store)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadActiveAuthorizationModelID indicates an expected call of ReadActiveAuthorizationModelID.
+func (mr *MockAuthorizationModelBackendMockRecorder) ReadActiveAuthorizationModelID(ctx, store any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadActiveAuthorizationModelID", reflect.TypeOf((*MockAuthorizationModelBackend)(nil).ReadActiveAuthorizationModelID), ctx, store)
+}
+
 // ReadAuthorizationModel mocks base method.
 func (m *MockAuthorizationModelBackend) ReadAuthorizationModel(ctx context.Context, store, id string) (*openfgav1.AuthorizationModel, error) {
 	m.ctrl.T.Helper()
@@ -514,6 +559,20 @@ func (mr *MockAuthorizationModelBackendMockRecorder) ReadAuthorizationModels(ctx
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAuthorizationModels", reflect.TypeOf((*MockAuthorizationModelBackend)(nil).ReadAuthorizationModels), ctx, store, options)
 }
 
+// WriteActiveAuthorizationModelID mocks base method.
+func (m *MockAuthorizationModelBackend) WriteActiveAuthorizationModelID(ctx context.Context, store, modelID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteActiveAuthorizationModelID", ctx, store, modelID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteActiveAuthorizationModelID indicates an expected call of WriteActiveAuthorizationModelID.
+func (mr *MockAuthorizationModelBackendMockRecorder) WriteActiveAuthorizationModelID(ctx, store, modelID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteActiveAuthorizationModelID", reflect.TypeOf((*MockAuthorizationModelBackend)(nil).WriteActiveAuthorizationModelID), ctx, store, modelID)
+}
+
 // WriteAuthorizationModel mocks base method.
 func (m *MockAuthorizationModelBackend) WriteAuthorizationModel(ctx context.Context, store string, model *openfgav1.AuthorizationModel) error {
 	m.ctrl.T.Helper()
@@ -665,6 +724,140 @@ func (mr *MockAssertionsBackendMockRecorder) WriteAssertions(ctx, store, modelID
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteAssertions", reflect.TypeOf((*MockAssertionsBackend)(nil).WriteAssertions), ctx, store, modelID, assertions)
 }
 
+// MockPlannerSnapshotBackend is a mock of PlannerSnapshotBackend interface.
+type MockPlannerSnapshotBackend struct {
+	ctrl     *gomock.Controller
+	recorder *MockPlannerSnapshotBackendMockRecorder
+	isgomock struct{}
+}
+
+// MockPlannerSnapshotBackendMockRecorder is the mock recorder for MockPlannerSnapshotBackend.
+type MockPlannerSnapshotBackendMockRecorder struct {
+	mock *MockPlannerSnapshotBackend
+}
+
+// NewMockPlannerSnapshotBackend creates a new mock instance.
+func NewMockPlannerSnapshotBackend(ctrl *gomock.Controller) *MockPlannerSnapshotBackend {
+	mock := &MockPlannerSnapshotBackend{ctrl: ctrl}
+	mock.recorder = &MockPlannerSnapshotBackendMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPlannerSnapshotBackend) EXPECT() *MockPlannerSnapshotBackendMockRecorder {
+	return m.recorder
+}
+
+// DeletePlannerSnapshots mocks base method.
+func (m *MockPlannerSnapshotBackend) DeletePlannerSnapshots(ctx context.Context, olderThan time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePlannerSnapshots", ctx, olderThan)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePlannerSnapshots indicates an expected call of DeletePlannerSnapshots.
+func (mr *MockPlannerSnapshotBackendMockRecorder) DeletePlannerSnapshots(ctx, olderThan any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePlannerSnapshots", reflect.TypeOf((*MockPlannerSnapshotBackend)(nil).DeletePlannerSnapshots), ctx, olderThan)
+}
+
+// ReadPlannerSnapshots mocks base method.
+func (m *MockPlannerSnapshotBackend) ReadPlannerSnapshots(ctx context.Context) (map[string][]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadPlannerSnapshots", ctx)
+	ret0, _ := ret[0].(map[string][]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadPlannerSnapshots indicates an expected call of ReadPlannerSnapshots.
+func (mr *MockPlannerSnapshotBackendMockRecorder) ReadPlannerSnapshots(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadPlannerSnapshots", reflect.TypeOf((*MockPlannerSnapshotBackend)(nil).ReadPlannerSnapshots), ctx)
+}
+
+// WritePlannerSnapshot mocks base method.
+func (m *MockPlannerSnapshotBackend) WritePlannerSnapshot(ctx context.Context, replicaID string, snapshot []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WritePlannerSnapshot", ctx, replicaID, snapshot)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WritePlannerSnapshot indicates an expected call of WritePlannerSnapshot.
+func (mr *MockPlannerSnapshotBackendMockRecorder) WritePlannerSnapshot(ctx, replicaID, snapshot any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WritePlannerSnapshot", reflect.TypeOf((*MockPlannerSnapshotBackend)(nil).WritePlannerSnapshot), ctx, replicaID, snapshot)
+}
+
+// MockCandidateModelBackend is a mock of CandidateModelBackend interface.
+type MockCandidateModelBackend struct {
+	ctrl     *gomock.Controller
+	recorder *MockCandidateModelBackendMockRecorder
+	isgomock struct{}
+}
+
+// MockCandidateModelBackendMockRecorder is the mock recorder for MockCandidateModelBackend.
+type MockCandidateModelBackendMockRecorder struct {
+	mock *MockCandidateModelBackend
+}
+
+// NewMockCandidateModelBackend creates a new mock instance.
+func NewMockCandidateModelBackend(ctrl *gomock.Controller) *MockCandidateModelBackend {
+	mock := &MockCandidateModelBackend{ctrl: ctrl}
+	mock.recorder = &MockCandidateModelBackendMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCandidateModelBackend) EXPECT() *MockCandidateModelBackendMockRecorder {
+	return m.recorder
+}
+
+// AddCandidateAuthorizationModelCounts mocks base method.
+func (m *MockCandidateModelBackend) AddCandidateAuthorizationModelCounts(ctx context.Context, store, modelID string, check, listObjects storage.CandidateModelCounts) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCandidateAuthorizationModelCounts", ctx, store, modelID, check, listObjects)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddCandidateAuthorizationModelCounts indicates an expected call of AddCandidateAuthorizationModelCounts.
+func (mr *MockCandidateModelBackendMockRecorder) AddCandidateAuthorizationModelCounts(ctx, store, modelID, check, listObjects any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCandidateAuthorizationModelCounts", reflect.TypeOf((*MockCandidateModelBackend)(nil).AddCandidateAuthorizationModelCounts), ctx, store, modelID, check, listObjects)
+}
+
+// ReadCandidateAuthorizationModel mocks base method.
+func (m *MockCandidateModelBackend) ReadCandidateAuthorizationModel(ctx context.Context, store string) (*storage.CandidateAuthorizationModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadCandidateAuthorizationModel", ctx, store)
+	ret0, _ := ret[0].(*storage.CandidateAuthorizationModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadCandidateAuthorizationModel indicates an expected call of ReadCandidateAuthorizationModel.
+func (mr *MockCandidateModelBackendMockRecorder) ReadCandidateAuthorizationModel(ctx, store any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadCandidateAuthorizationModel", reflect.TypeOf((*MockCandidateModelBackend)(nil).ReadCandidateAuthorizationModel), ctx, store)
+}
+
+// WriteCandidateAuthorizationModel mocks base method.
+func (m *MockCandidateModelBackend) WriteCandidateAuthorizationModel(ctx context.Context, store, modelID string, samplePercentage int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteCandidateAuthorizationModel", ctx, store, modelID, samplePercentage)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteCandidateAuthorizationModel indicates an expected call of WriteCandidateAuthorizationModel.
+func (mr *MockCandidateModelBackendMockRecorder) WriteCandidateAuthorizationModel(ctx, store, modelID, samplePercentage any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteCandidateAuthorizationModel", reflect.TypeOf((*MockCandidateModelBackend)(nil).WriteCandidateAuthorizationModel), ctx, store, modelID, samplePercentage)
+}
+
 // MockChangelogBackend is a mock of ChangelogBackend interface.
 type MockChangelogBackend struct {
 	ctrl     *gomock.Controller
@@ -729,6 +922,20 @@ func (m *MockOpenFGADatastore) EXPECT() *MockOpenFGADatastoreMockRecorder {
 	return m.recorder
 }
 
+// AddCandidateAuthorizationModelCounts mocks base method.
+func (m *MockOpenFGADatastore) AddCandidateAuthorizationModelCounts(ctx context.Context, store, modelID string, check, listObjects storage.CandidateModelCounts) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCandidateAuthorizationModelCounts", ctx, store, modelID, check, listObjects)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddCandidateAuthorizationModelCounts indicates an expected call of AddCandidateAuthorizationModelCounts.
+func (mr *MockOpenFGADatastoreMockRecorder) AddCandidateAuthorizationModelCounts(ctx, store, modelID, check, listObjects any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCandidateAuthorizationModelCounts", reflect.TypeOf((*MockOpenFGADatastore)(nil).AddCandidateAuthorizationModelCounts), ctx, store, modelID, check, listObjects)
+}
+
 // Close mocks base method.
 func (m *MockOpenFGADatastore) Close() {
 	m.ctrl.T.Helper()
@@ -756,6 +963,20 @@ func (mr *MockOpenFGADatastoreMockRecorder) CreateStore(ctx, store any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStore", reflect.TypeOf((*MockOpenFGADatastore)(nil).CreateStore), ctx, store)
 }
 
+// DeletePlannerSnapshots mocks base method.
+func (m *MockOpenFGADatastore) DeletePlannerSnapshots(ctx context.Context, olderThan time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePlannerSnapshots", ctx, olderThan)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePlannerSnapshots indicates an expected call of DeletePlannerSnapshots.
+func (mr *MockOpenFGADatastoreMockRecorder) DeletePlannerSnapshots(ctx, olderThan any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePlannerSnapshots", reflect.TypeOf((*MockOpenFGADatastore)(nil).DeletePlannerSnapshots), ctx, olderThan)
+}
+
 // DeleteStore mocks base method.
 func (m *MockOpenFGADatastore) DeleteStore(ctx context.Context, id string) error {
 	m.ctrl.T.Helper()
@@ -874,6 +1095,21 @@ func (mr *MockOpenFGADatastoreMockRecorder) Read(ctx, store, filter, options any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockOpenFGADatastore)(nil).Read), ctx, store, filter, options)
 }
 
+// ReadActiveAuthorizationModelID mocks base method.
+func (m *MockOpenFGADatastore) ReadActiveAuthorizationModelID(ctx context.Context, store string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadActiveAuthorizationModelID", ctx, store)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadActiveAuthorizationModelID indicates an expected call of ReadActiveAuthorizationModelID.
+func (mr *MockOpenFGADatastoreMockRecorder) ReadActiveAuthorizationModelID(ctx, store any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadActiveAuthorizationModelID", reflect.TypeOf((*MockOpenFGADatastore)(nil).ReadActiveAuthorizationModelID), ctx, store)
+}
+
 // ReadAssertions mocks base method.
 func (m *MockOpenFGADatastore) ReadAssertions(ctx context.Context, store, modelID string) ([]*openfgav1.Assertion, error) {
 	m.ctrl.T.Helper()
@@ -920,6 +1156,21 @@ func (mr *MockOpenFGADatastoreMockRecorder) ReadAuthorizationModels(ctx, store,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAuthorizationModels", reflect.TypeOf((*MockOpenFGADatastore)(nil).ReadAuthorizationModels), ctx, store, options)
 }
 
+// ReadCandidateAuthorizationModel mocks base method.
+func (m *MockOpenFGADatastore) ReadCandidateAuthorizationModel(ctx context.Context, store string) (*storage.CandidateAuthorizationModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadCandidateAuthorizationModel", ctx, store)
+	ret0, _ := ret[0].(*storage.CandidateAuthorizationModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadCandidateAuthorizationModel indicates an expected call of ReadCandidateAuthorizationModel.
+func (mr *MockOpenFGADatastoreMockRecorder) ReadCandidateAuthorizationModel(ctx, store any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadCandidateAuthorizationModel", reflect.TypeOf((*MockOpenFGADatastore)(nil).ReadCandidateAuthorizationModel), ctx, store)
+}
+
 // ReadChanges mocks base method.
 func (m *MockOpenFGADatastore) ReadChanges(ctx context.Context, store string, filter storage.ReadChangesFilter, options storage.ReadChangesOptions) ([]*openfgav1.TupleChange, string, error) {
 	m.ctrl.T.Helper()
@@ -952,6 +1203,21 @@ func (mr *MockOpenFGADatastoreMockRecorder) ReadPage(ctx, store, filter, options
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadPage", reflect.TypeOf((*MockOpenFGADatastore)(nil).ReadPage), ctx, store, filter, options)
 }
 
+// ReadPlannerSnapshots mocks base method.
+func (m *MockOpenFGADatastore) ReadPlannerSnapshots(ctx context.Context) (map[string][]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadPlannerSnapshots", ctx)
+	ret0, _ := ret[0].(map[string][]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadPlannerSnapshots indicates an expected call of ReadPlannerSnapshots.
+func (mr *MockOpenFGADatastoreMockRecorder) ReadPlannerSnapshots(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadPlannerSnapshots", reflect.TypeOf((*MockOpenFGADatastore)(nil).ReadPlannerSnapshots), ctx)
+}
+
 // ReadStartingWithUser mocks base method.
 func (m *MockOpenFGADatastore) ReadStartingWithUser(ctx context.Context, store string, filter storage.ReadStartingWithUserFilter, options storage.ReadStartingWithUserOptions) (storage.TupleIterator, error) {
 	m.ctrl.T.Helper()
@@ -1016,6 +1282,20 @@ func (mr *MockOpenFGADatastoreMockRecorder) Write(ctx, store, d, w any, opts ...
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockOpenFGADatastore)(nil).Write), varargs...)
 }
 
+// WriteActiveAuthorizationModelID mocks base method.
+func (m *MockOpenFGADatastore) WriteActiveAuthorizationModelID(ctx context.Context, store, modelID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteActiveAuthorizationModelID", ctx, store, modelID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteActiveAuthorizationModelID indicates an expected call of WriteActiveAuthorizationModelID.
+func (mr *MockOpenFGADatastoreMockRecorder) WriteActiveAuthorizationModelID(ctx, store, modelID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteActiveAuthorizationModelID", reflect.TypeOf((*MockOpenFGADatastore)(nil).WriteActiveAuthorizationModelID), ctx, store, modelID)
+}
+
 // WriteAssertions mocks base method.
 func (m *MockOpenFGADatastore) WriteAssertions(ctx context.Context, store, modelID string, assertions []*openfgav1.Assertion) error {
 	m.ctrl.T.Helper()
@@ -1043,3 +1323,31 @@ func (mr *MockOpenFGADatastoreMockRecorder) WriteAuthorizationModel(ctx, store,
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteAuthorizationModel", reflect.TypeOf((*MockOpenFGADatastore)(nil).WriteAuthorizationModel), ctx, store, model)
 }
+
+// WriteCandidateAuthorizationModel mocks base method.
+func (m *MockOpenFGADatastore) WriteCandidateAuthorizationModel(ctx context.Context, store, modelID string, samplePercentage int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteCandidateAuthorizationModel", ctx, store, modelID, samplePercentage)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteCandidateAuthorizationModel indicates an expected call of WriteCandidateAuthorizationModel.
+func (mr *MockOpenFGADatastoreMockRecorder) WriteCandidateAuthorizationModel(ctx, store, modelID, samplePercentage any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteCandidateAuthorizationModel", reflect.TypeOf((*MockOpenFGADatastore)(nil).WriteCandidateAuthorizationModel), ctx, store, modelID, samplePercentage)
+}
+
+// WritePlannerSnapshot mocks base method.
+func (m *MockOpenFGADatastore) WritePlannerSnapshot(ctx context.Context, replicaID string, snapshot []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WritePlannerSnapshot", ctx, replicaID, snapshot)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WritePlannerSnapshot indicates an expected call of WritePlannerSnapshot.
+func (mr *MockOpenFGADatastoreMockRecorder) WritePlannerSnapshot(ctx, replicaID, snapshot any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WritePlannerSnapshot", reflect.TypeOf((*MockOpenFGADatastore)(nil).WritePlannerSnapshot), ctx, replicaID, snapshot)
+}
@@ -0,0 +1,193 @@
+// Package modeldiff defines the gRPC service that compares two authorization models of a store, and classifies
+// each change as additive, behavior-changing or breaking before a new model is promoted. It also reports the
+// existing tuples that a new model would invalidate before it is written.
+package modeldiff
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/jsongrpc"
+)
+
+const (
+	// ServiceName is the fully qualified name of the model diff gRPC service.
+	ServiceName = "openfga.modeldiff.v1.ModelDiffService"
+
+	diffAuthorizationModelsMethod       = "/" + ServiceName + "/DiffAuthorizationModels"
+	dryRunWriteAuthorizationModelMethod = "/" + ServiceName + "/DryRunWriteAuthorizationModel"
+
+	// codecName is the gRPC content-subtype of the model diff service, whose messages are exchanged as JSON.
+	codecName = "openfga-modeldiff-json"
+)
+
+func init() {
+	jsongrpc.RegisterCodec(codecName)
+}
+
+// DiffAuthorizationModelsRequest asks for the changes that turn the FromAuthorizationModelID model into the
+// ToAuthorizationModelID model of the store. If ToAuthorizationModelID is empty, the latest model is used.
+type DiffAuthorizationModelsRequest struct {
+	jsongrpc.StoreRequest
+	FromAuthorizationModelID string `json:"from_authorization_model_id"`
+	ToAuthorizationModelID   string `json:"to_authorization_model_id,omitempty"`
+}
+
+// ModelChange is a change between two authorization models, see typesystem.ModelChange.
+type ModelChange struct {
+	Kind           string `json:"kind"`
+	Classification string `json:"classification"`
+	ObjectType     string `json:"object_type,omitempty"`
+	Relation       string `json:"relation,omitempty"`
+	Condition      string `json:"condition,omitempty"`
+	Detail         string `json:"detail,omitempty"`
+}
+
+// DiffAuthorizationModelsResponse holds the changes between the models, and the most severe classification of the
+// changes in Classification.
+type DiffAuthorizationModelsResponse struct {
+	FromAuthorizationModelID string        `json:"from_authorization_model_id"`
+	ToAuthorizationModelID   string        `json:"to_authorization_model_id"`
+	Classification           string        `json:"classification"`
+	Changes                  []ModelChange `json:"changes"`
+}
+
+// DryRunWriteAuthorizationModelRequest asks for the existing tuples of the store that the model of Request would
+// invalidate, without writing it. At most MaxOrphanedTuples of them are returned, the others are only counted.
+type DryRunWriteAuthorizationModelRequest struct {
+	Request           *openfgav1.WriteAuthorizationModelRequest
+	MaxOrphanedTuples uint32
+}
+
+// OrphanedTuple is an existing tuple that the model would invalidate, and the reason why.
+type OrphanedTuple struct {
+	Object    string `json:"object"`
+	Relation  string `json:"relation"`
+	User      string `json:"user"`
+	Condition string `json:"condition,omitempty"`
+	Reason    string `json:"reason"`
+}
+
+// DryRunWriteAuthorizationModelResponse holds the number of tuples of the store that were scanned, i.e. the tuples
+// of the types, relations and conditions that the model breaks, and of the tuples that the model would invalidate.
+// Complete is false when the scan timed out, in which case the counts only cover the tuples scanned until then.
+// Rejected is true when WriteAuthorizationModel would reject the model because it invalidates more tuples than the
+// server allows.
+type DryRunWriteAuthorizationModelResponse struct {
+	ScannedTupleCount  uint64          `json:"scanned_tuple_count"`
+	OrphanedTupleCount uint64          `json:"orphaned_tuple_count"`
+	OrphanedTuples     []OrphanedTuple `json:"orphaned_tuples"`
+	Complete           bool            `json:"complete"`
+	Rejected           bool            `json:"rejected"`
+}
+
+// GetStoreId allows the store ID to be picked up by the store ID interceptor like any other request.
+//
+//nolint:revive,stylecheck // matches the generated protobuf getter name used by the interceptors.
+func (r *DryRunWriteAuthorizationModelRequest) GetStoreId() string {
+	if r == nil {
+		return ""
+	}
+	return r.Request.GetStoreId()
+}
+
+// wireDryRunWriteAuthorizationModelRequest is the JSON representation of DryRunWriteAuthorizationModelRequest.
+// The model is encoded with protojson because its protobuf messages cannot be round-tripped through encoding/json.
+type wireDryRunWriteAuthorizationModelRequest struct {
+	Request           json.RawMessage `json:"request,omitempty"`
+	MaxOrphanedTuples uint32          `json:"max_orphaned_tuples,omitempty"`
+}
+
+func (r *DryRunWriteAuthorizationModelRequest) MarshalJSON() ([]byte, error) {
+	w := wireDryRunWriteAuthorizationModelRequest{MaxOrphanedTuples: r.MaxOrphanedTuples}
+
+	if r.Request != nil {
+		var err error
+		if w.Request, err = protojson.Marshal(r.Request); err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+
+	return json.Marshal(w)
+}
+
+func (r *DryRunWriteAuthorizationModelRequest) UnmarshalJSON(data []byte) error {
+	var w wireDryRunWriteAuthorizationModelRequest
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+
+	*r = DryRunWriteAuthorizationModelRequest{MaxOrphanedTuples: w.MaxOrphanedTuples}
+
+	if len(w.Request) > 0 {
+		r.Request = &openfgav1.WriteAuthorizationModelRequest{}
+		if err := protojson.Unmarshal(w.Request, r.Request); err != nil {
+			return fmt.Errorf("failed to unmarshal request: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ModelDiffServer is implemented by the node that serves DiffAuthorizationModels and DryRunWriteAuthorizationModel.
+type ModelDiffServer interface {
+	DiffAuthorizationModels(ctx context.Context, req *DiffAuthorizationModelsRequest) (*DiffAuthorizationModelsResponse, error)
+	DryRunWriteAuthorizationModel(ctx context.Context, req *DryRunWriteAuthorizationModelRequest) (*DryRunWriteAuthorizationModelResponse, error)
+}
+
+// RegisterModelDiffServer registers the model diff service on the provided gRPC server.
+func RegisterModelDiffServer(s grpc.ServiceRegistrar, srv ModelDiffServer) {
+	s.RegisterService(&ServiceDesc, srv)
+}
+
+// ServiceDesc is the grpc.ServiceDesc of the model diff service.
+var ServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*ModelDiffServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "DiffAuthorizationModels",
+			Handler:    jsongrpc.UnaryHandler(diffAuthorizationModelsMethod, ModelDiffServer.DiffAuthorizationModels),
+		},
+		{
+			MethodName: "DryRunWriteAuthorizationModel",
+			Handler:    jsongrpc.UnaryHandler(dryRunWriteAuthorizationModelMethod, ModelDiffServer.DryRunWriteAuthorizationModel),
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "internal/modeldiff/service.go",
+}
+
+// DiffAuthorizationModels calls DiffAuthorizationModels on the provided connection.
+func DiffAuthorizationModels(ctx context.Context, conn grpc.ClientConnInterface, req *DiffAuthorizationModelsRequest, opts ...grpc.CallOption) (*DiffAuthorizationModelsResponse, error) {
+	return jsongrpc.Invoke[DiffAuthorizationModelsResponse](ctx, conn, codecName, diffAuthorizationModelsMethod, req, opts...)
+}
+
+// DryRunWriteAuthorizationModel calls DryRunWriteAuthorizationModel on the provided connection.
+func DryRunWriteAuthorizationModel(ctx context.Context, conn grpc.ClientConnInterface, req *DryRunWriteAuthorizationModelRequest, opts ...grpc.CallOption) (*DryRunWriteAuthorizationModelResponse, error) {
+	return jsongrpc.Invoke[DryRunWriteAuthorizationModelResponse](ctx, conn, codecName, dryRunWriteAuthorizationModelMethod, req, opts...)
+}
+
+// RegisterModelDiffHandler exposes DiffAuthorizationModels and DryRunWriteAuthorizationModel on the HTTP gateway
+// mux, as POST /stores/{store_id}/authorization-models/diff and POST /stores/{store_id}/authorization-models/dry-run.
+func RegisterModelDiffHandler(mux *runtime.ServeMux, conn grpc.ClientConnInterface) error {
+	if err := jsongrpc.HandleHTTP[DiffAuthorizationModelsRequest, DiffAuthorizationModelsResponse](mux, http.MethodPost, "/stores/{store_id}/authorization-models/diff", conn, codecName, diffAuthorizationModelsMethod,
+		func(req *DiffAuthorizationModelsRequest, storeID string) { req.StoreID = storeID }); err != nil {
+		return err
+	}
+	return jsongrpc.HandleHTTP[DryRunWriteAuthorizationModelRequest, DryRunWriteAuthorizationModelResponse](mux, http.MethodPost, "/stores/{store_id}/authorization-models/dry-run", conn, codecName, dryRunWriteAuthorizationModelMethod,
+		func(req *DryRunWriteAuthorizationModelRequest, storeID string) {
+			if req.Request == nil {
+				req.Request = &openfgav1.WriteAuthorizationModelRequest{}
+			}
+			req.Request.StoreId = storeID
+		})
+}
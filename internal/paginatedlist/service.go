@@ -0,0 +1,285 @@
+// Package paginatedlist defines the gRPC service that lists the objects of a ListObjects request, or the users of
+// a ListUsers request, page by page with continuation tokens, for the clients that need more results than a single
+// ListObjects or ListUsers response holds.
+package paginatedlist
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/jsongrpc"
+)
+
+const (
+	// ServiceName is the fully qualified name of the paginated list gRPC service.
+	ServiceName = "openfga.paginatedlist.v1.PaginatedListService"
+
+	paginatedListObjectsMethod = "/" + ServiceName + "/PaginatedListObjects"
+	paginatedListUsersMethod   = "/" + ServiceName + "/PaginatedListUsers"
+
+	// codecName is the gRPC content-subtype of the paginated list service, whose messages are exchanged as JSON.
+	codecName = "openfga-paginatedlist-json"
+)
+
+func init() {
+	jsongrpc.RegisterCodec(codecName)
+}
+
+// PaginatedListObjectsRequest asks for the page of at most PageSize objects of Request that follows
+// ContinuationToken, or for the first page if ContinuationToken is empty. A PageSize of 0 asks for pages of the
+// maximum size, which is the ListObjects max results of the server.
+//
+// When ObjectIDs is not nil, only the objects whose ID is one of ObjectIDs are listed, and when ObjectIDPrefix is
+// not empty, only the objects whose ID starts with it. The IDs do not include the object type.
+type PaginatedListObjectsRequest struct {
+	Request           *openfgav1.ListObjectsRequest
+	PageSize          uint32
+	ContinuationToken string
+	ObjectIDs         []string
+	ObjectIDPrefix    string
+}
+
+// PaginatedListObjectsResponse holds a page of objects, in ascending order. ContinuationToken is empty on the
+// last page.
+type PaginatedListObjectsResponse struct {
+	Objects           []string `json:"objects"`
+	ContinuationToken string   `json:"continuation_token,omitempty"`
+}
+
+// GetStoreId allows the store ID to be picked up by the store ID interceptor like any other request.
+//
+//nolint:revive,stylecheck // matches the generated protobuf getter name used by the interceptors.
+func (r *PaginatedListObjectsRequest) GetStoreId() string {
+	if r == nil {
+		return ""
+	}
+	return r.Request.GetStoreId()
+}
+
+// wirePaginatedRequest is the JSON representation of PaginatedListObjectsRequest and PaginatedListUsersRequest.
+// The paginated request is encoded with protojson because structpb values cannot be round-tripped through
+// encoding/json.
+type wirePaginatedRequest struct {
+	Request           json.RawMessage `json:"request,omitempty"`
+	PageSize          uint32          `json:"page_size,omitempty"`
+	ContinuationToken string          `json:"continuation_token,omitempty"`
+
+	// ObjectIDs is not omitted when empty, since an empty list of object IDs lists no object while a nil one
+	// lists every object.
+	ObjectIDs      []string `json:"object_ids"`
+	ObjectIDPrefix string   `json:"object_id_prefix,omitempty"`
+}
+
+func (r *PaginatedListObjectsRequest) MarshalJSON() ([]byte, error) {
+	w := wirePaginatedRequest{
+		PageSize:          r.PageSize,
+		ContinuationToken: r.ContinuationToken,
+		ObjectIDs:         r.ObjectIDs,
+		ObjectIDPrefix:    r.ObjectIDPrefix,
+	}
+
+	if r.Request != nil {
+		var err error
+		if w.Request, err = protojson.Marshal(r.Request); err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+
+	return json.Marshal(w)
+}
+
+func (r *PaginatedListObjectsRequest) UnmarshalJSON(data []byte) error {
+	var w wirePaginatedRequest
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+
+	*r = PaginatedListObjectsRequest{
+		PageSize:          w.PageSize,
+		ContinuationToken: w.ContinuationToken,
+		ObjectIDs:         w.ObjectIDs,
+		ObjectIDPrefix:    w.ObjectIDPrefix,
+	}
+
+	if len(w.Request) > 0 {
+		r.Request = &openfgav1.ListObjectsRequest{}
+		if err := protojson.Unmarshal(w.Request, r.Request); err != nil {
+			return fmt.Errorf("failed to unmarshal request: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// PaginatedListUsersRequest asks for the page of at most PageSize users of Request that follows
+// ContinuationToken, or for the first page if ContinuationToken is empty. A PageSize of 0 asks for pages of the
+// maximum size, which is the ListUsers max results of the server.
+type PaginatedListUsersRequest struct {
+	Request           *openfgav1.ListUsersRequest
+	PageSize          uint32
+	ContinuationToken string
+}
+
+// PaginatedListUsersResponse holds a page of users, ordered by their string representation (see
+// tuple.UserProtoToString). ContinuationToken is empty on the last page.
+type PaginatedListUsersResponse struct {
+	Users             []*openfgav1.User
+	ContinuationToken string
+}
+
+// GetStoreId allows the store ID to be picked up by the store ID interceptor like any other request.
+//
+//nolint:revive,stylecheck // matches the generated protobuf getter name used by the interceptors.
+func (r *PaginatedListUsersRequest) GetStoreId() string {
+	if r == nil {
+		return ""
+	}
+	return r.Request.GetStoreId()
+}
+
+func (r *PaginatedListUsersRequest) MarshalJSON() ([]byte, error) {
+	w := wirePaginatedRequest{
+		PageSize:          r.PageSize,
+		ContinuationToken: r.ContinuationToken,
+	}
+
+	if r.Request != nil {
+		var err error
+		if w.Request, err = protojson.Marshal(r.Request); err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+
+	return json.Marshal(w)
+}
+
+func (r *PaginatedListUsersRequest) UnmarshalJSON(data []byte) error {
+	var w wirePaginatedRequest
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+
+	*r = PaginatedListUsersRequest{
+		PageSize:          w.PageSize,
+		ContinuationToken: w.ContinuationToken,
+	}
+
+	if len(w.Request) > 0 {
+		r.Request = &openfgav1.ListUsersRequest{}
+		if err := protojson.Unmarshal(w.Request, r.Request); err != nil {
+			return fmt.Errorf("failed to unmarshal request: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// wirePaginatedListUsersResponse is the JSON representation of PaginatedListUsersResponse. The users are encoded
+// with protojson, as the users of a ListUsersResponse, because their oneof cannot be round-tripped through
+// encoding/json.
+type wirePaginatedListUsersResponse struct {
+	Users             json.RawMessage `json:"users,omitempty"`
+	ContinuationToken string          `json:"continuation_token,omitempty"`
+}
+
+func (r *PaginatedListUsersResponse) MarshalJSON() ([]byte, error) {
+	w := wirePaginatedListUsersResponse{
+		ContinuationToken: r.ContinuationToken,
+	}
+
+	var err error
+	if w.Users, err = protojson.Marshal(&openfgav1.ListUsersResponse{Users: r.Users}); err != nil {
+		return nil, fmt.Errorf("failed to marshal users: %w", err)
+	}
+
+	return json.Marshal(w)
+}
+
+func (r *PaginatedListUsersResponse) UnmarshalJSON(data []byte) error {
+	var w wirePaginatedListUsersResponse
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+
+	*r = PaginatedListUsersResponse{
+		ContinuationToken: w.ContinuationToken,
+	}
+
+	if len(w.Users) > 0 {
+		var users openfgav1.ListUsersResponse
+		if err := protojson.Unmarshal(w.Users, &users); err != nil {
+			return fmt.Errorf("failed to unmarshal users: %w", err)
+		}
+		r.Users = users.GetUsers()
+	}
+
+	return nil
+}
+
+// PaginatedListServer is implemented by the node that serves the paginated list service.
+type PaginatedListServer interface {
+	PaginatedListObjects(ctx context.Context, req *PaginatedListObjectsRequest) (*PaginatedListObjectsResponse, error)
+	PaginatedListUsers(ctx context.Context, req *PaginatedListUsersRequest) (*PaginatedListUsersResponse, error)
+}
+
+// RegisterPaginatedListServer registers the paginated list service on the provided gRPC server.
+func RegisterPaginatedListServer(s grpc.ServiceRegistrar, srv PaginatedListServer) {
+	s.RegisterService(&ServiceDesc, srv)
+}
+
+// ServiceDesc is the grpc.ServiceDesc of the paginated list service.
+var ServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*PaginatedListServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "PaginatedListObjects",
+			Handler:    jsongrpc.UnaryHandler(paginatedListObjectsMethod, PaginatedListServer.PaginatedListObjects),
+		},
+		{
+			MethodName: "PaginatedListUsers",
+			Handler:    jsongrpc.UnaryHandler(paginatedListUsersMethod, PaginatedListServer.PaginatedListUsers),
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "internal/paginatedlist/service.go",
+}
+
+// PaginatedListObjects calls PaginatedListObjects on the provided connection.
+func PaginatedListObjects(ctx context.Context, conn grpc.ClientConnInterface, req *PaginatedListObjectsRequest, opts ...grpc.CallOption) (*PaginatedListObjectsResponse, error) {
+	return jsongrpc.Invoke[PaginatedListObjectsResponse](ctx, conn, codecName, paginatedListObjectsMethod, req, opts...)
+}
+
+// PaginatedListUsers calls PaginatedListUsers on the provided connection.
+func PaginatedListUsers(ctx context.Context, conn grpc.ClientConnInterface, req *PaginatedListUsersRequest, opts ...grpc.CallOption) (*PaginatedListUsersResponse, error) {
+	return jsongrpc.Invoke[PaginatedListUsersResponse](ctx, conn, codecName, paginatedListUsersMethod, req, opts...)
+}
+
+// RegisterPaginatedListHandler exposes PaginatedListObjects and PaginatedListUsers on the HTTP gateway mux, as
+// POST /stores/{store_id}/paginated-list-objects and POST /stores/{store_id}/paginated-list-users.
+func RegisterPaginatedListHandler(mux *runtime.ServeMux, conn grpc.ClientConnInterface) error {
+	if err := jsongrpc.HandleHTTP[PaginatedListObjectsRequest, PaginatedListObjectsResponse](mux, http.MethodPost, "/stores/{store_id}/paginated-list-objects", conn, codecName, paginatedListObjectsMethod,
+		func(req *PaginatedListObjectsRequest, storeID string) {
+			if req.Request == nil {
+				req.Request = &openfgav1.ListObjectsRequest{}
+			}
+			req.Request.StoreId = storeID
+		}); err != nil {
+		return err
+	}
+	return jsongrpc.HandleHTTP[PaginatedListUsersRequest, PaginatedListUsersResponse](mux, http.MethodPost, "/stores/{store_id}/paginated-list-users", conn, codecName, paginatedListUsersMethod,
+		func(req *PaginatedListUsersRequest, storeID string) {
+			if req.Request == nil {
+				req.Request = &openfgav1.ListUsersRequest{}
+			}
+			req.Request.StoreId = storeID
+		})
+}
@@ -0,0 +1,175 @@
+package peer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+const defaultDispatchTimeout = 1 * time.Second
+
+// Client routes keys to the peer that owns them and dispatches sub-problems to that peer.
+// Connections to peers are created lazily and shared by all requests.
+type Client struct {
+	self         string
+	provider     Provider
+	virtualNodes int
+	timeout      time.Duration
+	dialOptions  []grpc.DialOption
+	presharedKey string
+
+	// ring is rebuilt when the membership changes, so that routing a key does not compare memberships
+	ring atomic.Pointer[Ring]
+
+	mu    sync.RWMutex
+	conns map[string]*grpc.ClientConn
+}
+
+// ClientOpt defines an option that can be used to change the behavior of Client.
+type ClientOpt func(*Client)
+
+// WithVirtualNodes sets how many times each peer is placed on the hash ring.
+func WithVirtualNodes(n int) ClientOpt {
+	return func(c *Client) {
+		c.virtualNodes = n
+	}
+}
+
+// WithDispatchTimeout sets the maximum amount of time to wait for a peer to resolve a sub-problem.
+func WithDispatchTimeout(timeout time.Duration) ClientOpt {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithDialOptions sets the options used to dial peers. By default, peers are dialed without TLS.
+func WithDialOptions(opts ...grpc.DialOption) ClientOpt {
+	return func(c *Client) {
+		c.dialOptions = opts
+	}
+}
+
+// WithPresharedKey sets the key that authenticates this node to the peer dispatch listener of its peers.
+func WithPresharedKey(key string) ClientOpt {
+	return func(c *Client) {
+		c.presharedKey = key
+	}
+}
+
+// NewClient constructs a Client. self is the address under which this node is known to its peers;
+// it is always part of the ring even if the provider does not return it.
+func NewClient(self string, provider Provider, opts ...ClientOpt) *Client {
+	c := &Client{
+		self:         self,
+		provider:     provider,
+		virtualNodes: defaultVirtualNodes,
+		timeout:      defaultDispatchTimeout,
+		dialOptions:  []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())},
+		conns:        make(map[string]*grpc.ClientConn),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.ring.Store(NewRing(c.members(provider.Peers()), c.virtualNodes))
+	provider.OnChange(c.rebuild)
+
+	return c
+}
+
+// Owner returns the address of the peer owning the provided key, and whether that peer is this node.
+func (c *Client) Owner(key string) (string, bool) {
+	owner := c.ring.Load().Owner(key)
+	return owner, owner == "" || owner == c.self
+}
+
+// DispatchCheck sends the sub-problem to the peer at addr.
+func (c *Client) DispatchCheck(ctx context.Context, addr string, req *CheckRequest) (*CheckResponse, error) {
+	conn, err := c.conn(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	if c.presharedKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+c.presharedKey)
+	}
+
+	resp := new(CheckResponse)
+	err = conn.Invoke(ctx, dispatchCheckMethod, req, resp, grpc.CallContentSubtype(codecName))
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// Close closes all the connections to peers and the membership provider.
+func (c *Client) Close() {
+	c.provider.Close()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for addr, conn := range c.conns {
+		_ = conn.Close()
+		delete(c.conns, addr)
+	}
+}
+
+func (c *Client) members(peers []string) []string {
+	if slices.Contains(peers, c.self) {
+		return peers
+	}
+	return append(slices.Clone(peers), c.self)
+}
+
+// rebuild replaces the ring with one for the new membership, and closes the connections to the peers that left.
+func (c *Client) rebuild(peers []string) {
+	ring := NewRing(c.members(peers), c.virtualNodes)
+	c.ring.Store(ring)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for addr, conn := range c.conns {
+		if _, found := slices.BinarySearch(ring.Members(), addr); !found {
+			_ = conn.Close()
+			delete(c.conns, addr)
+		}
+	}
+}
+
+func (c *Client) conn(addr string) (*grpc.ClientConn, error) {
+	c.mu.RLock()
+	conn, ok := c.conns[addr]
+	c.mu.RUnlock()
+	if ok {
+		return conn, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if conn, ok := c.conns[addr]; ok {
+		return conn, nil
+	}
+
+	conn, err := grpc.NewClient(addr, c.dialOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to peer '%s': %w", addr, err)
+	}
+	c.conns[addr] = conn
+
+	return conn, nil
+}
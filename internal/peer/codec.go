@@ -0,0 +1,114 @@
+package peer
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/jsongrpc"
+)
+
+// codecName is the gRPC content-subtype of the dispatch service, whose messages are exchanged as JSON.
+const codecName = "openfga-peer-json"
+
+func init() {
+	jsongrpc.RegisterCodec(codecName)
+}
+
+// wireCheckRequest is the JSON representation of CheckRequest. Protobuf fields are encoded with
+// protojson because structpb values cannot be round-tripped through encoding/json.
+type wireCheckRequest struct {
+	StoreID                   string          `json:"store_id"`
+	AuthorizationModelID      string          `json:"authorization_model_id"`
+	TupleKey                  json.RawMessage `json:"tuple_key,omitempty"`
+	ContextualTuples          json.RawMessage `json:"contextual_tuples,omitempty"`
+	Context                   json.RawMessage `json:"context,omitempty"`
+	Consistency               int32           `json:"consistency,omitempty"`
+	LastCacheInvalidationTime int64           `json:"last_cache_invalidation_time,omitempty"`
+	Depth                     uint32          `json:"depth,omitempty"`
+	VisitedPaths              []string        `json:"visited_paths,omitempty"`
+}
+
+func (r *CheckRequest) MarshalJSON() ([]byte, error) {
+	w := wireCheckRequest{
+		StoreID:              r.StoreID,
+		AuthorizationModelID: r.AuthorizationModelID,
+		Consistency:          int32(r.Consistency),
+		Depth:                r.Depth,
+		VisitedPaths:         r.VisitedPaths,
+	}
+
+	if !r.LastCacheInvalidationTime.IsZero() {
+		w.LastCacheInvalidationTime = r.LastCacheInvalidationTime.UnixNano()
+	}
+
+	var err error
+	if r.TupleKey != nil {
+		if w.TupleKey, err = protojson.Marshal(r.TupleKey); err != nil {
+			return nil, fmt.Errorf("failed to marshal tuple key: %w", err)
+		}
+	}
+
+	if len(r.ContextualTuples) > 0 {
+		w.ContextualTuples, err = protojson.Marshal(&openfgav1.ContextualTupleKeys{TupleKeys: r.ContextualTuples})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal contextual tuples: %w", err)
+		}
+	}
+
+	if r.Context != nil {
+		if w.Context, err = protojson.Marshal(r.Context); err != nil {
+			return nil, fmt.Errorf("failed to marshal context: %w", err)
+		}
+	}
+
+	return json.Marshal(w)
+}
+
+func (r *CheckRequest) UnmarshalJSON(data []byte) error {
+	var w wireCheckRequest
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+
+	*r = CheckRequest{
+		StoreRequest:         jsongrpc.StoreRequest{StoreID: w.StoreID},
+		AuthorizationModelID: w.AuthorizationModelID,
+		Consistency:          openfgav1.ConsistencyPreference(w.Consistency),
+		Depth:                w.Depth,
+		VisitedPaths:         w.VisitedPaths,
+	}
+
+	if w.LastCacheInvalidationTime != 0 {
+		r.LastCacheInvalidationTime = time.Unix(0, w.LastCacheInvalidationTime)
+	}
+
+	if len(w.TupleKey) > 0 {
+		r.TupleKey = &openfgav1.TupleKey{}
+		if err := protojson.Unmarshal(w.TupleKey, r.TupleKey); err != nil {
+			return fmt.Errorf("failed to unmarshal tuple key: %w", err)
+		}
+	}
+
+	if len(w.ContextualTuples) > 0 {
+		var contextualTuples openfgav1.ContextualTupleKeys
+		if err := protojson.Unmarshal(w.ContextualTuples, &contextualTuples); err != nil {
+			return fmt.Errorf("failed to unmarshal contextual tuples: %w", err)
+		}
+		r.ContextualTuples = contextualTuples.GetTupleKeys()
+	}
+
+	if len(w.Context) > 0 {
+		r.Context = &structpb.Struct{}
+		if err := protojson.Unmarshal(w.Context, r.Context); err != nil {
+			return fmt.Errorf("failed to unmarshal context: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package peer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/openfga/openfga/pkg/logger"
+)
+
+const defaultDNSRefreshInterval = 10 * time.Second
+
+// Provider returns the current set of peers of the cluster, as addresses that can be dialed over gRPC.
+// The returned slice must not be modified by the caller.
+type Provider interface {
+	Peers() []string
+	// OnChange registers a function called with the new peers every time they change.
+	OnChange(fn func(peers []string))
+	Close()
+}
+
+// StaticProvider is a Provider with a fixed set of peers.
+type StaticProvider struct {
+	peers []string
+}
+
+var _ Provider = (*StaticProvider)(nil)
+
+// NewStaticProvider returns a Provider that always returns the provided peers.
+func NewStaticProvider(peers ...string) *StaticProvider {
+	return &StaticProvider{peers: slices.Clone(peers)}
+}
+
+func (s *StaticProvider) Peers() []string {
+	return s.peers
+}
+
+// OnChange is a no-op, the peers of a StaticProvider never change.
+func (s *StaticProvider) OnChange(func(peers []string)) {}
+
+func (s *StaticProvider) Close() {}
+
+// DNSProvider is a Provider that periodically resolves a DNS name (e.g. a Kubernetes headless service)
+// and returns one peer per resolved address.
+type DNSProvider struct {
+	host     string
+	port     string
+	interval time.Duration
+	resolver *net.Resolver
+	logger   logger.Logger
+
+	mu        sync.RWMutex
+	peers     []string
+	listeners []func(peers []string)
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+var _ Provider = (*DNSProvider)(nil)
+
+// DNSProviderOpt defines an option that can be used to change the behavior of DNSProvider.
+type DNSProviderOpt func(*DNSProvider)
+
+// WithDNSRefreshInterval sets how often the DNS name is resolved again.
+func WithDNSRefreshInterval(interval time.Duration) DNSProviderOpt {
+	return func(d *DNSProvider) {
+		d.interval = interval
+	}
+}
+
+// WithDNSResolver sets the resolver used to look up the DNS name.
+func WithDNSResolver(resolver *net.Resolver) DNSProviderOpt {
+	return func(d *DNSProvider) {
+		d.resolver = resolver
+	}
+}
+
+// WithDNSLogger sets the logger used to report resolution failures.
+func WithDNSLogger(logger logger.Logger) DNSProviderOpt {
+	return func(d *DNSProvider) {
+		d.logger = logger
+	}
+}
+
+// NewDNSProvider resolves hostPort (e.g. "openfga-headless.default.svc.cluster.local:8081") once and then
+// keeps refreshing it in the background until Close is called. The first resolution must succeed.
+func NewDNSProvider(ctx context.Context, hostPort string, opts ...DNSProviderOpt) (*DNSProvider, error) {
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return nil, fmt.Errorf("invalid peer DNS name '%s': %w", hostPort, err)
+	}
+
+	d := &DNSProvider{
+		host:     host,
+		port:     port,
+		interval: defaultDNSRefreshInterval,
+		resolver: net.DefaultResolver,
+		logger:   logger.NewNoopLogger(),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	if err := d.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	d.wg.Add(1)
+	go d.run()
+
+	return d, nil
+}
+
+func (d *DNSProvider) Peers() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.peers
+}
+
+func (d *DNSProvider) OnChange(fn func(peers []string)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.listeners = append(d.listeners, fn)
+}
+
+// Close stops the background refresh.
+func (d *DNSProvider) Close() {
+	close(d.done)
+	d.wg.Wait()
+}
+
+func (d *DNSProvider) run() {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), d.interval)
+			if err := d.refresh(ctx); err != nil {
+				// keep the last known membership, a transient DNS failure should not collapse the ring
+				d.logger.Warn("failed to refresh peer membership", zap.String("host", d.host), zap.Error(err))
+			}
+			cancel()
+		}
+	}
+}
+
+func (d *DNSProvider) refresh(ctx context.Context) error {
+	addrs, err := d.resolver.LookupHost(ctx, d.host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve peers from '%s': %w", d.host, err)
+	}
+
+	peers := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		peers = append(peers, net.JoinHostPort(addr, d.port))
+	}
+	slices.Sort(peers)
+
+	d.mu.Lock()
+	if slices.Equal(d.peers, peers) {
+		d.mu.Unlock()
+		return nil
+	}
+	d.peers = peers
+	listeners := slices.Clone(d.listeners)
+	d.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(peers)
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+// Package peer contains the building blocks used to shard Check sub-problems across a cluster of
+// OpenFGA nodes: peer membership, a consistent hash ring and the gRPC transport used to dispatch
+// sub-problems to the node that owns them.
+package peer
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+const defaultVirtualNodes = 100
+
+// Ring is an immutable consistent hash ring. Every member is placed on the ring several times
+// (virtual nodes) so that keys are spread evenly and adding or removing one member only moves
+// roughly 1/N of the key space.
+type Ring struct {
+	members []string
+	hashes  []uint64
+	owners  []int
+}
+
+// NewRing builds a Ring with the provided members, each placed virtualNodes times on the ring.
+// Duplicate members are ignored. If virtualNodes is not positive a default is used.
+func NewRing(members []string, virtualNodes int) *Ring {
+	if virtualNodes <= 0 {
+		virtualNodes = defaultVirtualNodes
+	}
+
+	unique := slices.Clone(members)
+	slices.Sort(unique)
+	unique = slices.Compact(unique)
+
+	r := &Ring{
+		members: unique,
+		hashes:  make([]uint64, 0, len(unique)*virtualNodes),
+		owners:  make([]int, 0, len(unique)*virtualNodes),
+	}
+
+	type point struct {
+		hash  uint64
+		owner int
+	}
+	points := make([]point, 0, len(unique)*virtualNodes)
+	for i, member := range unique {
+		for v := 0; v < virtualNodes; v++ {
+			points = append(points, point{hash: xxhash.Sum64String(member + "#" + strconv.Itoa(v)), owner: i})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].hash < points[j].hash })
+
+	for _, p := range points {
+		r.hashes = append(r.hashes, p.hash)
+		r.owners = append(r.owners, p.owner)
+	}
+
+	return r
+}
+
+// Members returns the sorted, de-duplicated members of the ring.
+func (r *Ring) Members() []string {
+	return r.members
+}
+
+// Owner returns the member that owns the provided key. It returns an empty string if the ring has no members.
+func (r *Ring) Owner(key string) string {
+	if len(r.hashes) == 0 {
+		return ""
+	}
+
+	h := xxhash.Sum64String(key)
+	idx := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+	if idx == len(r.hashes) {
+		idx = 0
+	}
+
+	return r.members[r.owners[idx]]
+}
@@ -0,0 +1,152 @@
+package peer
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/jsongrpc"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestRing(t *testing.T) {
+	t.Run("empty_ring_has_no_owner", func(t *testing.T) {
+		require.Empty(t, NewRing(nil, 0).Owner("key"))
+	})
+
+	t.Run("members_are_deduplicated", func(t *testing.T) {
+		ring := NewRing([]string{"b:1", "a:1", "b:1"}, 10)
+		require.Equal(t, []string{"a:1", "b:1"}, ring.Members())
+	})
+
+	t.Run("owner_does_not_depend_on_member_order", func(t *testing.T) {
+		r1 := NewRing([]string{"a:1", "b:1", "c:1"}, 50)
+		r2 := NewRing([]string{"c:1", "a:1", "b:1"}, 50)
+		for i := 0; i < 1000; i++ {
+			key := strconv.Itoa(i)
+			require.Equal(t, r1.Owner(key), r2.Owner(key))
+		}
+	})
+
+	t.Run("keys_are_spread_across_members", func(t *testing.T) {
+		ring := NewRing([]string{"a:1", "b:1", "c:1"}, 100)
+		owned := map[string]int{}
+		for i := 0; i < 3000; i++ {
+			owned[ring.Owner(strconv.Itoa(i))]++
+		}
+		require.Len(t, owned, 3)
+		for member, count := range owned {
+			require.Greater(t, count, 500, "member %s owns too few keys", member)
+		}
+	})
+
+	t.Run("adding_a_member_only_moves_its_keys", func(t *testing.T) {
+		before := NewRing([]string{"a:1", "b:1", "c:1"}, 100)
+		after := NewRing([]string{"a:1", "b:1", "c:1", "d:1"}, 100)
+		for i := 0; i < 3000; i++ {
+			key := strconv.Itoa(i)
+			if owner := after.Owner(key); owner != "d:1" {
+				require.Equal(t, before.Owner(key), owner)
+			}
+		}
+	})
+}
+
+func TestClientOwner(t *testing.T) {
+	c := NewClient("a:1", NewStaticProvider("b:1", "c:1"))
+	t.Cleanup(c.Close)
+
+	members := map[string]struct{}{}
+	for i := 0; i < 1000; i++ {
+		owner, isLocal := c.Owner(strconv.Itoa(i))
+		require.Equal(t, owner == "a:1", isLocal)
+		members[owner] = struct{}{}
+	}
+	require.Len(t, members, 3)
+}
+
+// changingProvider is a Provider whose peers are changed by the test.
+type changingProvider struct {
+	peers    []string
+	listener func(peers []string)
+}
+
+func (p *changingProvider) Peers() []string                  { return p.peers }
+func (p *changingProvider) OnChange(fn func(peers []string)) { p.listener = fn }
+func (p *changingProvider) Close()                           {}
+
+func (p *changingProvider) set(peers ...string) {
+	p.peers = peers
+	p.listener(peers)
+}
+
+func TestClientOwnerAfterMembershipChange(t *testing.T) {
+	provider := &changingProvider{peers: []string{"b:1"}}
+	c := NewClient("a:1", provider)
+	t.Cleanup(c.Close)
+
+	owners := func() map[string]struct{} {
+		members := map[string]struct{}{}
+		for i := 0; i < 1000; i++ {
+			owner, _ := c.Owner(strconv.Itoa(i))
+			members[owner] = struct{}{}
+		}
+		return members
+	}
+	require.Equal(t, map[string]struct{}{"a:1": {}, "b:1": {}}, owners())
+
+	_, err := c.conn("b:1")
+	require.NoError(t, err)
+
+	provider.set("c:1")
+	require.Equal(t, map[string]struct{}{"a:1": {}, "c:1": {}}, owners())
+
+	// the connection to the peer that left is closed
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	require.NotContains(t, c.conns, "b:1")
+}
+
+func TestCheckRequestJSONRoundTrip(t *testing.T) {
+	ctx, err := structpb.NewStruct(map[string]any{"x": 1, "ip": "127.0.0.1"})
+	require.NoError(t, err)
+
+	req := &CheckRequest{
+		StoreRequest:         jsongrpc.StoreRequest{StoreID: "store"},
+		AuthorizationModelID: "model",
+		TupleKey:             tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		ContextualTuples: []*openfgav1.TupleKey{
+			tuple.NewTupleKeyWithCondition("document:1", "viewer", "user:bob", "cond", ctx),
+		},
+		Context:                   ctx,
+		Consistency:               openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY,
+		LastCacheInvalidationTime: time.Unix(0, 1700000000123456789),
+		Depth:                     3,
+		VisitedPaths:              []string{"document:1#viewer@user:anne"},
+	}
+
+	codec := encoding.GetCodec(codecName)
+	require.NotNil(t, codec)
+	data, err := codec.Marshal(req)
+	require.NoError(t, err)
+
+	var got CheckRequest
+	require.NoError(t, codec.Unmarshal(data, &got))
+
+	require.Equal(t, req.StoreID, got.StoreID)
+	require.Equal(t, req.AuthorizationModelID, got.AuthorizationModelID)
+	require.Equal(t, req.TupleKey.String(), got.TupleKey.String())
+	require.Len(t, got.ContextualTuples, 1)
+	require.Equal(t, req.ContextualTuples[0].String(), got.ContextualTuples[0].String())
+	require.Equal(t, req.Context.AsMap(), got.Context.AsMap())
+	require.Equal(t, req.Consistency, got.Consistency)
+	require.True(t, req.LastCacheInvalidationTime.Equal(got.LastCacheInvalidationTime))
+	require.Equal(t, req.Depth, got.Depth)
+	require.Equal(t, req.VisitedPaths, got.VisitedPaths)
+}
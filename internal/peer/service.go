@@ -0,0 +1,72 @@
+package peer
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/jsongrpc"
+)
+
+const (
+	// ServiceName is the fully qualified name of the peer dispatch gRPC service.
+	ServiceName = "openfga.peer.v1.DispatchService"
+
+	dispatchCheckMethod = "/" + ServiceName + "/DispatchCheck"
+)
+
+// CheckRequest is a Check sub-problem dispatched from one node to the peer that owns it.
+// It carries everything needed to resume resolution on the peer as if it was dispatched locally.
+type CheckRequest struct {
+	jsongrpc.StoreRequest
+	AuthorizationModelID      string
+	TupleKey                  *openfgav1.TupleKey
+	ContextualTuples          []*openfgav1.TupleKey
+	Context                   *structpb.Struct
+	Consistency               openfgav1.ConsistencyPreference
+	LastCacheInvalidationTime time.Time
+	Depth                     uint32
+	VisitedPaths              []string
+}
+
+// CheckResponse is the outcome of a dispatched Check sub-problem.
+type CheckResponse struct {
+	Allowed             bool   `json:"allowed,omitempty"`
+	CycleDetected       bool   `json:"cycle_detected,omitempty"`
+	DatastoreQueryCount uint32 `json:"datastore_query_count,omitempty"`
+	DatastoreItemCount  uint64 `json:"datastore_item_count,omitempty"`
+	DispatchCount       uint32 `json:"dispatch_count,omitempty"`
+	DispatchThrottled   bool   `json:"dispatch_throttled,omitempty"`
+
+	// ResolutionDepthExceeded is set instead of returning an error so that the dispatching node
+	// can surface the same error it would have returned had it resolved the sub-problem itself.
+	ResolutionDepthExceeded bool `json:"resolution_depth_exceeded,omitempty"`
+}
+
+// DispatchServer is implemented by the node that resolves sub-problems dispatched by its peers.
+type DispatchServer interface {
+	DispatchCheck(ctx context.Context, req *CheckRequest) (*CheckResponse, error)
+}
+
+// RegisterDispatchServer registers the peer dispatch service on the provided gRPC server.
+func RegisterDispatchServer(s grpc.ServiceRegistrar, srv DispatchServer) {
+	s.RegisterService(&ServiceDesc, srv)
+}
+
+// ServiceDesc is the grpc.ServiceDesc of the peer dispatch service.
+var ServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*DispatchServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "DispatchCheck",
+			Handler:    jsongrpc.UnaryHandler(dispatchCheckMethod, DispatchServer.DispatchCheck),
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "internal/peer/service.go",
+}
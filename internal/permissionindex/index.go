@@ -0,0 +1,489 @@
+// Package permissionindex maintains a precomputed index of transitive membership for
+// recursive usersets (e.g. `define member: [user, group#member]`), in the spirit of
+// Google Zanzibar's Leopard indexing system.
+//
+// For every Eligible `type#relation` of the model of a store the index keeps two kinds of sets,
+// maintained incrementally from the store changelog (ReadChanges) in a Store shared by the replicas:
+//
+//   - the objects each user is a direct member of (user -> objects), and
+//   - the objects each object is nested into (object -> parent objects).
+//
+// A membership check walks the nesting sets up from the objects the user is a direct member of,
+// one level per read, instead of a breadth first traversal of the datastore.
+package permissionindex
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/telemetry"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+const (
+	defaultRefreshInterval = 5 * time.Second
+	defaultMaxStaleness    = 10 * time.Second
+	defaultIdleTimeout     = time.Hour
+	defaultPageSize        = 100
+	defaultMaxDepth        = 25
+	defaultSyncTimeout     = time.Minute
+)
+
+var (
+	tracer = otel.Tracer("internal/permissionindex")
+
+	lookupCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "permission_index_lookup_count",
+		Help:      "The total number of permission index lookups labeled by result (allowed, denied, stale, unindexed or error).",
+	}, []string{"result"})
+
+	changesAppliedCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "permission_index_changes_applied_count",
+		Help:      "The total number of changelog entries applied to the permission index.",
+	})
+
+	syncErrorCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "permission_index_sync_error_count",
+		Help:      "The total number of permission index syncs that failed.",
+	})
+
+	indexedStoresGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: build.ProjectName,
+		Name:      "permission_index_stores",
+		Help:      "The number of stores currently tracked by the permission index of this replica.",
+	})
+)
+
+// IndexOpt defines an option that can be used to change the behavior of Index.
+type IndexOpt func(*Index)
+
+// WithRefreshInterval sets how often the index of every tracked store is brought up to date with the changelog.
+func WithRefreshInterval(interval time.Duration) IndexOpt {
+	return func(idx *Index) {
+		idx.refreshInterval = interval
+	}
+}
+
+// WithMaxStaleness sets how old the sync point of a store may be for the index to answer lookups.
+// Lookups on a staler store report ok=false so that the caller falls back to graph resolution.
+func WithMaxStaleness(staleness time.Duration) IndexOpt {
+	return func(idx *Index) {
+		idx.maxStaleness = staleness
+	}
+}
+
+// WithIdleTimeout sets how long a store is tracked after its last lookup.
+func WithIdleTimeout(timeout time.Duration) IndexOpt {
+	return func(idx *Index) {
+		idx.idleTimeout = timeout
+	}
+}
+
+// WithHorizonOffset sets the changelog horizon offset used when reading changes. See storage.ReadChangesFilter.
+func WithHorizonOffset(offset time.Duration) IndexOpt {
+	return func(idx *Index) {
+		idx.horizonOffset = offset
+	}
+}
+
+// WithPageSize sets the page size used when reading changes.
+func WithPageSize(pageSize int) IndexOpt {
+	return func(idx *Index) {
+		idx.pageSize = pageSize
+	}
+}
+
+// WithMaxDepth sets how many levels of nesting a lookup walks before giving up and reporting ok=false.
+func WithMaxDepth(depth int) IndexOpt {
+	return func(idx *Index) {
+		idx.maxDepth = depth
+	}
+}
+
+// WithLogger sets the logger for Index.
+func WithLogger(logger logger.Logger) IndexOpt {
+	return func(idx *Index) {
+		idx.logger = logger
+	}
+}
+
+// Index is the precomputed permission index. A store is tracked from the first lookup made against it
+// and its index is kept up to date by a background worker until it has not been looked up for the idle
+// timeout. Only one replica sharing the Store syncs a store at a time, the others read its sync point.
+// You must call Close on it after you are done using it.
+type Index struct {
+	ds                storage.ChangelogBackend
+	store             Store
+	resolveTypesystem typesystem.TypesystemResolverFunc
+	logger            logger.Logger
+	refreshInterval   time.Duration
+	maxStaleness      time.Duration
+	idleTimeout       time.Duration
+	horizonOffset     time.Duration
+	pageSize          int
+	maxDepth          int
+
+	stores   sync.Map // storeID -> *trackedStore
+	inflight sync.Map // storeID -> struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	mu     sync.Mutex // guards closed and wg.Add
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// New returns a new Index that reads the changelog of the provided datastore into store. The indexed relations
+// of a store are the Eligible relations of the model resolveTypesystem returns for it without a model ID.
+func New(ds storage.ChangelogBackend, store Store, resolveTypesystem typesystem.TypesystemResolverFunc, opts ...IndexOpt) *Index {
+	ctx, cancel := context.WithCancel(context.Background())
+	idx := &Index{
+		ds:                ds,
+		store:             store,
+		resolveTypesystem: resolveTypesystem,
+		logger:            logger.NewNoopLogger(),
+		refreshInterval:   defaultRefreshInterval,
+		maxStaleness:      defaultMaxStaleness,
+		idleTimeout:       defaultIdleTimeout,
+		pageSize:          defaultPageSize,
+		maxDepth:          defaultMaxDepth,
+		ctx:               ctx,
+		cancel:            cancel,
+	}
+
+	for _, opt := range opts {
+		opt(idx)
+	}
+
+	idx.wg.Add(1)
+	go idx.run()
+
+	return idx
+}
+
+// Close stops the background worker and waits for in-flight syncs to finish. It does not close the Store.
+func (idx *Index) Close() {
+	idx.mu.Lock()
+	idx.closed = true
+	idx.mu.Unlock()
+
+	idx.cancel()
+	idx.wg.Wait()
+}
+
+// Lookup reports whether user (e.g. "user:anne") is a member of object#relation (e.g. "group:eng#member"),
+// directly or through any number of nested object#relation usersets of the same type and relation.
+// When includeWildcard is true, a typed wildcard of the user's type (e.g. "user:*") also grants membership.
+//
+// ok is false when the store's index is not fresh enough to answer, when the relation is not indexed or
+// holds conditional tuples, or when the index can't be read; the caller must then resolve the relation
+// through the graph. The first lookup on a store starts indexing it.
+func (idx *Index) Lookup(ctx context.Context, storeID, object, relation, user string, includeWildcard bool) (allowed bool, ok bool) {
+	t := idx.getOrCreateStore(storeID)
+	t.lastLookup.Store(time.Now().UnixNano())
+
+	key := tuple.GetType(object) + "#" + relation
+	syncedAt, indexed := t.indexed(key)
+	switch {
+	case syncedAt.IsZero() || time.Since(syncedAt) > idx.maxStaleness:
+		lookupCounter.WithLabelValues("stale").Inc()
+		return false, false
+	case !indexed:
+		lookupCounter.WithLabelValues("unindexed").Inc()
+		return false, false
+	}
+
+	allowed, ok, err := idx.isMember(ctx, storeID, key, object, user, includeWildcard)
+	switch {
+	case err != nil:
+		lookupCounter.WithLabelValues("error").Inc()
+		idx.logger.WarnWithContext(ctx, "permission index lookup failed",
+			zap.String("store_id", storeID),
+			zap.Error(err))
+		return false, false
+	case !ok:
+		lookupCounter.WithLabelValues("unindexed").Inc()
+	case allowed:
+		lookupCounter.WithLabelValues("allowed").Inc()
+	default:
+		lookupCounter.WithLabelValues("denied").Inc()
+	}
+	return allowed, ok
+}
+
+// isMember walks the nesting sets of relation up from the objects user is a direct member of, one level per
+// read of the Store, until it reaches object.
+func (idx *Index) isMember(ctx context.Context, storeID, relation, object, user string, includeWildcard bool) (bool, bool, error) {
+	users := []string{user}
+	if includeWildcard {
+		users = append(users, tuple.TypedPublicWildcard(tuple.GetType(user)))
+	}
+
+	frontier, err := idx.store.Read(ctx, storeID, relation, MemberEntry, users)
+	if err != nil {
+		return false, false, err
+	}
+
+	visited := make(map[string]struct{})
+	for depth := 0; len(frontier) > 0; depth++ {
+		if depth >= idx.maxDepth {
+			return false, false, nil
+		}
+
+		next := make([]string, 0, len(frontier))
+		for _, current := range frontier {
+			if current == object {
+				return true, true, nil
+			}
+			if _, ok := visited[current]; ok {
+				continue
+			}
+			visited[current] = struct{}{}
+			next = append(next, current)
+		}
+		if len(next) == 0 {
+			break
+		}
+
+		frontier, err = idx.store.Read(ctx, storeID, relation, ParentEntry, next)
+		if err != nil {
+			return false, false, err
+		}
+	}
+
+	return false, true, nil
+}
+
+func (idx *Index) getOrCreateStore(storeID string) *trackedStore {
+	if t, ok := idx.stores.Load(storeID); ok {
+		return t.(*trackedStore)
+	}
+
+	t, loaded := idx.stores.LoadOrStore(storeID, newTrackedStore())
+	if !loaded {
+		indexedStoresGauge.Inc()
+		idx.syncIfNeeded(storeID)
+	}
+	return t.(*trackedStore)
+}
+
+// run periodically syncs every tracked store and stops tracking the stores that have not been looked up recently.
+func (idx *Index) run() {
+	defer idx.wg.Done()
+
+	ticker := time.NewTicker(idx.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-idx.ctx.Done():
+			return
+		case <-ticker.C:
+			idx.stores.Range(func(key, value any) bool {
+				storeID := key.(string)
+				lastLookup := time.Unix(0, value.(*trackedStore).lastLookup.Load())
+				if time.Since(lastLookup) > idx.idleTimeout {
+					idx.stores.Delete(storeID)
+					indexedStoresGauge.Dec()
+					return true
+				}
+				idx.syncIfNeeded(storeID)
+				return true
+			})
+		}
+	}
+}
+
+// syncIfNeeded spawns a goroutine that applies the pending changes of the store, unless one is already running.
+func (idx *Index) syncIfNeeded(storeID string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.closed {
+		return
+	}
+
+	if _, inflight := idx.inflight.LoadOrStore(storeID, struct{}{}); inflight {
+		return
+	}
+
+	idx.wg.Add(1)
+	go func() {
+		defer idx.wg.Done()
+		defer idx.inflight.Delete(storeID)
+
+		ctx, cancel := context.WithTimeout(idx.ctx, defaultSyncTimeout)
+		defer cancel()
+
+		if err := idx.sync(ctx, storeID); err != nil && !errors.Is(err, context.Canceled) {
+			syncErrorCounter.Inc()
+			idx.logger.Warn("permission index sync failed",
+				zap.String("store_id", storeID),
+				zap.Error(err))
+		}
+	}()
+}
+
+// sync applies the changelog of the store from its sync point until the end, if no other replica is doing so,
+// and refreshes the sync point this replica answers lookups with. The index of the store is rebuilt from the
+// start of the changelog whenever its Eligible relations change.
+func (idx *Index) sync(ctx context.Context, storeID string) error {
+	ctx, span := tracer.Start(ctx, "permissionindex.sync", trace.WithAttributes(
+		attribute.String("store_id", storeID),
+	))
+	defer span.End()
+
+	value, ok := idx.stores.Load(storeID)
+	if !ok {
+		return nil
+	}
+	t := value.(*trackedStore)
+
+	unlock, locked, err := idx.store.Lock(ctx, storeID, defaultSyncTimeout)
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return err
+	}
+	if !locked {
+		// another replica is syncing the store
+		state, err := idx.store.ReadState(ctx, storeID)
+		if err != nil {
+			telemetry.TraceError(span, err)
+			return err
+		}
+		return idx.refresh(ctx, storeID, t, state)
+	}
+	defer unlock()
+
+	state, err := idx.store.ReadState(ctx, storeID)
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return err
+	}
+
+	var relations []string
+	typesys, err := idx.resolveTypesystem(ctx, storeID, "")
+	switch {
+	case err == nil:
+		relations = EligibleRelations(typesys)
+	case !errors.Is(err, typesystem.ErrModelNotFound):
+		telemetry.TraceError(span, err)
+		return err
+	}
+	if !slices.Equal(relations, state.Relations) {
+		if err := idx.store.Reset(ctx, storeID); err != nil {
+			telemetry.TraceError(span, err)
+			return err
+		}
+		state = &State{Relations: relations}
+	}
+	indexed := make(map[string]struct{}, len(relations))
+	for _, relation := range relations {
+		indexed[relation] = struct{}{}
+	}
+
+	start := time.Now()
+	applied := 0
+	for {
+		tupleChanges, token, err := idx.ds.ReadChanges(ctx, storeID,
+			storage.ReadChangesFilter{HorizonOffset: idx.horizonOffset},
+			storage.ReadChangesOptions{Pagination: storage.NewPaginationOptions(int32(idx.pageSize), state.Token)},
+		)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			telemetry.TraceError(span, err)
+			return err
+		}
+
+		if len(tupleChanges) > 0 {
+			if token != "" {
+				state.Token = token
+			}
+			state.SyncedAt = tupleChanges[len(tupleChanges)-1].GetTimestamp().AsTime()
+			if err := idx.store.Apply(ctx, storeID, changesOf(tupleChanges, indexed), state); err != nil {
+				telemetry.TraceError(span, err)
+				return err
+			}
+			applied += len(tupleChanges)
+		}
+
+		if errors.Is(err, storage.ErrNotFound) || len(tupleChanges) < idx.pageSize {
+			break
+		}
+	}
+
+	// every change older than the horizon at the start of the sync has been read, so the index also reflects
+	// the (absence of) changes up to it, which keeps a store that is not written to from going stale
+	if horizon := start.Add(-idx.horizonOffset); horizon.After(state.SyncedAt) {
+		state.SyncedAt = horizon
+		if err := idx.store.Apply(ctx, storeID, nil, state); err != nil {
+			telemetry.TraceError(span, err)
+			return err
+		}
+	}
+
+	changesAppliedCounter.Add(float64(applied))
+	span.SetAttributes(attribute.Int("changes_applied", applied))
+	return idx.refresh(ctx, storeID, t, state)
+}
+
+// refresh updates the sync point this replica answers the lookups of the store with.
+func (idx *Index) refresh(ctx context.Context, storeID string, t *trackedStore, state *State) error {
+	conditioned, err := idx.store.Conditioned(ctx, storeID, state.Relations)
+	if err != nil {
+		return err
+	}
+
+	relations := make(map[string]struct{}, len(state.Relations))
+	for _, relation := range state.Relations {
+		relations[relation] = struct{}{}
+	}
+	for _, relation := range conditioned {
+		delete(relations, relation)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.syncedAt = state.SyncedAt
+	t.relations = relations
+	return nil
+}
+
+// trackedStore holds the sync point of a store, as last read or written by this replica.
+type trackedStore struct {
+	mu        sync.RWMutex
+	syncedAt  time.Time
+	relations map[string]struct{} // the indexed type#relation without conditioned tuples
+
+	lastLookup atomic.Int64
+}
+
+func newTrackedStore() *trackedStore {
+	t := &trackedStore{}
+	t.lastLookup.Store(time.Now().UnixNano())
+	return t
+}
+
+// indexed returns the sync point of the store and whether relation can be answered from its index.
+func (t *trackedStore) indexed(relation string) (time.Time, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	_, ok := t.relations[relation]
+	return t.syncedAt, ok
+}
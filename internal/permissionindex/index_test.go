@@ -0,0 +1,327 @@
+package permissionindex
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+const testModel = `
+	model
+		schema 1.1
+
+	type user
+
+	type group
+		relations
+			define member: [user, user:*, group#member]
+			define owner: [user, group#owner]
+			define admin: [user]`
+
+func newTestIndex(t *testing.T, ds storage.OpenFGADatastore, store Store, opts ...IndexOpt) *Index {
+	t.Helper()
+
+	resolver, stop, err := typesystem.MemoizedTypesystemResolverFunc(ds)
+	require.NoError(t, err)
+	t.Cleanup(stop)
+
+	idx := New(ds, store, resolver, opts...)
+	t.Cleanup(idx.Close)
+	return idx
+}
+
+func writeTestModel(t *testing.T, ds storage.OpenFGADatastore, storeID, model string) {
+	t.Helper()
+	require.NoError(t, ds.WriteAuthorizationModel(context.Background(), storeID, testutils.MustTransformDSLToProtoWithID(model)))
+}
+
+func TestIndexLookup(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+	writeTestModel(t, ds, storeID, testModel)
+	err := ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("group:eng", "member", "group:backend#member"),
+		tuple.NewTupleKey("group:backend", "member", "group:storage#member"),
+		tuple.NewTupleKey("group:storage", "member", "user:anne"),
+		tuple.NewTupleKey("group:public", "member", "user:*"),
+		// a cycle between groups must not prevent lookups from terminating
+		tuple.NewTupleKey("group:a", "member", "group:b#member"),
+		tuple.NewTupleKey("group:b", "member", "group:a#member"),
+		// usersets of other relations are not part of the recursion
+		tuple.NewTupleKey("group:eng", "member", "group:ops#owner"),
+		tuple.NewTupleKey("group:ops", "owner", "user:bob"),
+	})
+	require.NoError(t, err)
+
+	store := NewMemoryStore()
+	idx := newTestIndex(t, ds, store, WithRefreshInterval(10*time.Millisecond), WithMaxStaleness(time.Minute), WithPageSize(2))
+
+	// the first lookup starts indexing the store
+	_, ok := idx.Lookup(context.Background(), storeID, "group:eng", "member", "user:anne", false)
+	require.False(t, ok)
+
+	require.Eventually(t, func() bool {
+		_, ok := idx.Lookup(context.Background(), storeID, "group:eng", "member", "user:anne", false)
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+
+	state, err := store.ReadState(context.Background(), storeID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"group#member", "group#owner"}, state.Relations)
+
+	tests := []struct {
+		name            string
+		object          string
+		relation        string
+		user            string
+		includeWildcard bool
+		expected        bool
+		expectedOK      bool
+	}{
+		{name: "direct_member", object: "group:storage", relation: "member", user: "user:anne", expected: true, expectedOK: true},
+		{name: "nested_member", object: "group:eng", relation: "member", user: "user:anne", expected: true, expectedOK: true},
+		{name: "not_a_member", object: "group:storage", relation: "member", user: "user:bob", expectedOK: true},
+		{name: "member_of_a_child_only", object: "group:backend", relation: "member", user: "user:carl", expectedOK: true},
+		{name: "other_relation_usersets_are_ignored", object: "group:eng", relation: "member", user: "user:bob", expectedOK: true},
+		{name: "wildcard_excluded", object: "group:public", relation: "member", user: "user:carl", expectedOK: true},
+		{name: "wildcard_included", object: "group:public", relation: "member", user: "user:carl", includeWildcard: true, expected: true, expectedOK: true},
+		{name: "cycle", object: "group:a", relation: "member", user: "user:anne", expectedOK: true},
+		{name: "ineligible_relation", object: "group:ops", relation: "admin", user: "user:bob"},
+		{name: "unknown_relation", object: "group:eng", relation: "viewer", user: "user:anne"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			allowed, ok := idx.Lookup(context.Background(), storeID, test.object, test.relation, test.user, test.includeWildcard)
+			require.Equal(t, test.expectedOK, ok)
+			require.Equal(t, test.expected, allowed)
+		})
+	}
+
+	t.Run("changes_are_applied_incrementally", func(t *testing.T) {
+		err := ds.Write(context.Background(), storeID,
+			[]*openfgav1.TupleKeyWithoutCondition{
+				tuple.TupleKeyToTupleKeyWithoutCondition(tuple.NewTupleKey("group:backend", "member", "group:storage#member")),
+			},
+			[]*openfgav1.TupleKey{
+				tuple.NewTupleKey("group:a", "member", "user:anne"),
+			},
+		)
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			allowed, ok := idx.Lookup(context.Background(), storeID, "group:eng", "member", "user:anne", false)
+			return ok && !allowed
+		}, 5*time.Second, 10*time.Millisecond)
+
+		allowed, ok := idx.Lookup(context.Background(), storeID, "group:b", "member", "user:anne", false)
+		require.True(t, ok)
+		require.True(t, allowed)
+	})
+
+	t.Run("conditioned_tuples_are_not_answered", func(t *testing.T) {
+		err := ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+			tuple.NewTupleKeyWithCondition("group:eng", "member", "user:dan", "in_office", nil),
+		})
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			_, ok := idx.Lookup(context.Background(), storeID, "group:eng", "member", "user:anne", false)
+			return !ok
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("model_changes_rebuild_the_index", func(t *testing.T) {
+		writeTestModel(t, ds, storeID, `
+			model
+				schema 1.1
+
+			type user
+
+			type group
+				relations
+					define member: [user]
+					define owner: [user, group#owner]`)
+
+		require.Eventually(t, func() bool {
+			allowed, ok := idx.Lookup(context.Background(), storeID, "group:ops", "owner", "user:bob", false)
+			return ok && allowed
+		}, 5*time.Second, 10*time.Millisecond)
+
+		_, ok := idx.Lookup(context.Background(), storeID, "group:b", "member", "user:anne", false)
+		require.False(t, ok)
+	})
+}
+
+func TestIndexSyncPoint(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+	writeTestModel(t, ds, storeID, testModel)
+	err := ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("group:eng", "member", "user:anne"),
+	})
+	require.NoError(t, err)
+
+	t.Run("changes_within_the_horizon_offset_are_not_applied", func(t *testing.T) {
+		store := NewMemoryStore()
+		idx := newTestIndex(t, ds, store, WithRefreshInterval(10*time.Millisecond), WithMaxStaleness(time.Minute), WithHorizonOffset(time.Hour))
+
+		_, ok := idx.Lookup(context.Background(), storeID, "group:eng", "member", "user:anne", false)
+		require.False(t, ok)
+
+		require.Eventually(t, func() bool {
+			state, err := store.ReadState(context.Background(), storeID)
+			require.NoError(t, err)
+			return !state.SyncedAt.IsZero()
+		}, 5*time.Second, 10*time.Millisecond)
+
+		// the index only reflects the changelog up to the horizon, which is older than the maximum staleness
+		state, err := store.ReadState(context.Background(), storeID)
+		require.NoError(t, err)
+		require.Empty(t, state.Token)
+		require.WithinDuration(t, time.Now().Add(-time.Hour), state.SyncedAt, time.Minute)
+
+		_, ok = idx.Lookup(context.Background(), storeID, "group:eng", "member", "user:anne", false)
+		require.False(t, ok)
+	})
+
+	t.Run("stale_when_not_refreshed", func(t *testing.T) {
+		// the store is synced once on the first lookup and never refreshed afterwards
+		idx := newTestIndex(t, ds, NewMemoryStore(), WithRefreshInterval(time.Hour), WithMaxStaleness(50*time.Millisecond))
+
+		require.Eventually(t, func() bool {
+			_, ok := idx.Lookup(context.Background(), storeID, "group:eng", "member", "user:anne", false)
+			return ok
+		}, 5*time.Second, 5*time.Millisecond)
+
+		require.Eventually(t, func() bool {
+			_, ok := idx.Lookup(context.Background(), storeID, "group:eng", "member", "user:anne", false)
+			return !ok
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("replicas_share_the_store", func(t *testing.T) {
+		store := NewMemoryStore()
+		leader := newTestIndex(t, ds, store, WithRefreshInterval(10*time.Millisecond), WithMaxStaleness(time.Minute))
+		require.Eventually(t, func() bool {
+			allowed, ok := leader.Lookup(context.Background(), storeID, "group:eng", "member", "user:anne", false)
+			return ok && allowed
+		}, 5*time.Second, 10*time.Millisecond)
+
+		// the other replica can't sync the store while the lock is held, and answers from the shared index
+		unlock, ok, err := store.Lock(context.Background(), storeID, time.Minute)
+		require.NoError(t, err)
+		require.True(t, ok)
+		t.Cleanup(unlock)
+
+		follower := newTestIndex(t, ds, store, WithRefreshInterval(10*time.Millisecond), WithMaxStaleness(time.Minute))
+		require.Eventually(t, func() bool {
+			allowed, ok := follower.Lookup(context.Background(), storeID, "group:eng", "member", "user:anne", false)
+			return ok && allowed
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestValkeyStore(t *testing.T) {
+	uri := os.Getenv("OPENFGA_VALKEY_URI")
+	if uri == "" {
+		uri = "redis://localhost:6380"
+	}
+
+	opt, err := redis.ParseURL(uri)
+	require.NoError(t, err)
+	client := redis.NewClient(opt)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Valkey not ready at %s: %v", uri, err)
+	}
+
+	store, err := NewValkeyStore(uri, "openfga-test:"+ulid.Make().String()+":")
+	require.NoError(t, err)
+	t.Cleanup(store.Close)
+
+	storeID := ulid.Make().String()
+
+	state, err := store.ReadState(ctx, storeID)
+	require.NoError(t, err)
+	require.Equal(t, &State{}, state)
+
+	unlock, ok, err := store.Lock(ctx, storeID, time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+	_, ok, err = store.Lock(ctx, storeID, time.Minute)
+	require.NoError(t, err)
+	require.False(t, ok)
+	unlock()
+	unlock, ok, err = store.Lock(ctx, storeID, time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+	unlock()
+
+	syncedAt := time.Now().UTC().Truncate(time.Millisecond)
+	err = store.Apply(ctx, storeID, []Change{
+		{Relation: "group#member", Kind: MemberEntry, Key: "user:anne", Value: "group:eng"},
+		{Relation: "group#member", Kind: MemberEntry, Key: "user:anne", Value: "group:ops"},
+		{Relation: "group#member", Kind: ParentEntry, Key: "group:eng", Value: "group:all"},
+		{Relation: "group#member", Kind: ConditionedEntry, Value: "group:x#member@user:bob"},
+	}, &State{Token: "token", SyncedAt: syncedAt, Relations: []string{"group#member", "team#member"}})
+	require.NoError(t, err)
+
+	state, err = store.ReadState(ctx, storeID)
+	require.NoError(t, err)
+	require.Equal(t, "token", state.Token)
+	require.True(t, syncedAt.Equal(state.SyncedAt))
+	require.Equal(t, []string{"group#member", "team#member"}, state.Relations)
+
+	members, err := store.Read(ctx, storeID, "group#member", MemberEntry, []string{"user:anne", "user:*"})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"group:eng", "group:ops"}, members)
+
+	conditioned, err := store.Conditioned(ctx, storeID, state.Relations)
+	require.NoError(t, err)
+	require.Equal(t, []string{"group#member"}, conditioned)
+
+	err = store.Apply(ctx, storeID, []Change{
+		{Relation: "group#member", Kind: ConditionedEntry, Value: "group:x#member@user:bob", Delete: true},
+	}, state)
+	require.NoError(t, err)
+	conditioned, err = store.Conditioned(ctx, storeID, state.Relations)
+	require.NoError(t, err)
+	require.Empty(t, conditioned)
+
+	require.NoError(t, store.Reset(ctx, storeID))
+	state, err = store.ReadState(ctx, storeID)
+	require.NoError(t, err)
+	require.Equal(t, &State{}, state)
+	parents, err := store.Read(ctx, storeID, "group#member", ParentEntry, []string{"group:eng"})
+	require.NoError(t, err)
+	require.Empty(t, parents)
+}
@@ -0,0 +1,94 @@
+package permissionindex
+
+import (
+	"slices"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// Eligible returns whether objectType#relation can be indexed. A relation is eligible when it is defined only
+// by direct assignment of users (and/or typed wildcards) and of usersets of itself, without conditions,
+// e.g. `define member: [user, user:*, group#member]`.
+func Eligible(typesys *typesystem.TypeSystem, objectType, relation string) bool {
+	rel, err := typesys.GetRelation(objectType, relation)
+	if err != nil {
+		return false
+	}
+	if _, ok := rel.GetRewrite().GetUserset().(*openfgav1.Userset_This); !ok {
+		return false
+	}
+
+	directlyRelatedTypes, err := typesys.GetDirectlyRelatedUserTypes(objectType, relation)
+	if err != nil {
+		return false
+	}
+
+	var assignable, recursive bool
+	for _, ref := range directlyRelatedTypes {
+		if ref.GetCondition() != "" {
+			return false
+		}
+		if ref.GetRelation() == "" {
+			assignable = true
+			continue
+		}
+		if ref.GetType() != objectType || ref.GetRelation() != relation {
+			return false
+		}
+		recursive = true
+	}
+
+	return recursive && assignable
+}
+
+// EligibleRelations returns the sorted type#relation of the model that are Eligible.
+func EligibleRelations(typesys *typesystem.TypeSystem) []string {
+	var relations []string
+	for objectType, typeRelations := range typesys.GetAllRelations() {
+		for relation := range typeRelations {
+			if Eligible(typesys, objectType, relation) {
+				relations = append(relations, objectType+"#"+relation)
+			}
+		}
+	}
+	slices.Sort(relations)
+	return relations
+}
+
+// changesOf returns the changes to the index of the tuple changes of the indexed relations.
+func changesOf(tupleChanges []*openfgav1.TupleChange, relations map[string]struct{}) []Change {
+	var changes []Change
+	for _, tupleChange := range tupleChanges {
+		tk := tupleChange.GetTupleKey()
+		objectType := tuple.GetType(tk.GetObject())
+		relation := objectType + "#" + tk.GetRelation()
+		if _, ok := relations[relation]; !ok {
+			continue
+		}
+
+		isDelete := tupleChange.GetOperation() == openfgav1.TupleOperation_TUPLE_OPERATION_DELETE
+		if isDelete || tk.GetCondition().GetName() != "" {
+			changes = append(changes, Change{
+				Relation: relation,
+				Kind:     ConditionedEntry,
+				Value:    tuple.TupleKeyToString(tk),
+				Delete:   isDelete,
+			})
+		}
+
+		object, user := tk.GetObject(), tk.GetUser()
+		userObject, userRelation := tuple.SplitObjectRelation(user)
+		switch {
+		case userRelation == "":
+			changes = append(changes, Change{Relation: relation, Kind: MemberEntry, Key: user, Value: object, Delete: isDelete})
+		case userRelation == tk.GetRelation() && tuple.GetType(userObject) == objectType:
+			changes = append(changes, Change{Relation: relation, Kind: ParentEntry, Key: userObject, Value: object, Delete: isDelete})
+		default:
+			// usersets of other type#relation are not part of the recursion
+		}
+	}
+	return changes
+}
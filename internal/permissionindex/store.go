@@ -0,0 +1,179 @@
+package permissionindex
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// EntryKind is the kind of the sets the index is made of.
+type EntryKind string
+
+const (
+	// MemberEntry sets hold the objects a user (or typed wildcard) is a direct member of.
+	MemberEntry EntryKind = "m"
+	// ParentEntry sets hold the objects an object is nested into, i.e. `parent#relation@object#relation`.
+	ParentEntry EntryKind = "p"
+	// ConditionedEntry sets hold the tuples that were written with a condition. The index cannot evaluate
+	// conditions, so while a relation has any it cannot be answered from the index.
+	ConditionedEntry EntryKind = "c"
+)
+
+// Change adds Value to, or removes it from, the set of the given kind stored under Key for a type#relation.
+type Change struct {
+	Relation string
+	Kind     EntryKind
+	Key      string
+	Value    string
+	Delete   bool
+}
+
+// State is the sync point of the index of a store.
+type State struct {
+	// Token is the changelog continuation token of the next change to apply.
+	Token string
+	// SyncedAt is the timestamp of the last applied change. The index reflects every change up to it.
+	SyncedAt time.Time
+	// Relations are the type#relation that are indexed, sorted.
+	Relations []string
+}
+
+// Store persists the permission index of every store, so that it survives restarts and is shared by the
+// replicas. Apply must write the changes and the state atomically.
+type Store interface {
+	// Lock acquires the exclusive right to sync the index of storeID for at most ttl. ok is false if
+	// another replica holds it.
+	Lock(ctx context.Context, storeID string, ttl time.Duration) (unlock func(), ok bool, err error)
+	// ReadState returns the sync point of the index of storeID, or the zero State if it was never synced.
+	ReadState(ctx context.Context, storeID string) (*State, error)
+	// Apply applies changes to the index of storeID and records state as its sync point.
+	Apply(ctx context.Context, storeID string, changes []Change, state *State) error
+	// Reset deletes the index of storeID.
+	Reset(ctx context.Context, storeID string) error
+	// Read returns the union of the sets of the given kind stored under keys for relation.
+	Read(ctx context.Context, storeID, relation string, kind EntryKind, keys []string) ([]string, error)
+	// Conditioned returns the relations among relations that hold conditioned tuples.
+	Conditioned(ctx context.Context, storeID string, relations []string) ([]string, error)
+	// Close releases the resources of the store.
+	Close()
+}
+
+// entryKey returns the key of a set of the index of a store.
+func entryKey(relation string, kind EntryKind, key string) string {
+	return relation + ":" + string(kind) + ":" + key
+}
+
+// MemoryStore is a Store that keeps the index in the memory of the process. It is neither persisted nor
+// shared by replicas, and is meant for tests and single node deployments.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	states map[string]State
+	sets   map[string]map[string]map[string]struct{} // storeID -> entry key -> values
+	locks  map[string]struct{}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		states: make(map[string]State),
+		sets:   make(map[string]map[string]map[string]struct{}),
+		locks:  make(map[string]struct{}),
+	}
+}
+
+func (m *MemoryStore) Lock(_ context.Context, storeID string, _ time.Duration) (func(), bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, locked := m.locks[storeID]; locked {
+		return nil, false, nil
+	}
+	m.locks[storeID] = struct{}{}
+
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.locks, storeID)
+	}, true, nil
+}
+
+func (m *MemoryStore) ReadState(_ context.Context, storeID string) (*State, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	state := m.states[storeID]
+	return &state, nil
+}
+
+func (m *MemoryStore) Apply(_ context.Context, storeID string, changes []Change, state *State) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sets, ok := m.sets[storeID]
+	if !ok {
+		sets = make(map[string]map[string]struct{})
+		m.sets[storeID] = sets
+	}
+
+	for _, change := range changes {
+		key := entryKey(change.Relation, change.Kind, change.Key)
+		if change.Delete {
+			delete(sets[key], change.Value)
+			if len(sets[key]) == 0 {
+				delete(sets, key)
+			}
+			continue
+		}
+		if _, ok := sets[key]; !ok {
+			sets[key] = make(map[string]struct{})
+		}
+		sets[key][change.Value] = struct{}{}
+	}
+
+	m.states[storeID] = *state
+	return nil
+}
+
+func (m *MemoryStore) Reset(_ context.Context, storeID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.states, storeID)
+	delete(m.sets, storeID)
+	return nil
+}
+
+func (m *MemoryStore) Read(_ context.Context, storeID, relation string, kind EntryKind, keys []string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	seen := make(map[string]struct{})
+	var values []string
+	for _, key := range keys {
+		for value := range m.sets[storeID][entryKey(relation, kind, key)] {
+			if _, ok := seen[value]; ok {
+				continue
+			}
+			seen[value] = struct{}{}
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
+func (m *MemoryStore) Conditioned(_ context.Context, storeID string, relations []string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var conditioned []string
+	for _, relation := range relations {
+		if len(m.sets[storeID][entryKey(relation, ConditionedEntry, "")]) > 0 {
+			conditioned = append(conditioned, relation)
+		}
+	}
+	return conditioned, nil
+}
+
+func (m *MemoryStore) Close() {}
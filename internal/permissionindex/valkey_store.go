@@ -0,0 +1,175 @@
+package permissionindex
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultValkeyKeyPrefix is the default prefix of the keys of the ValkeyStore.
+const DefaultValkeyKeyPrefix = "openfga:permissionindex:"
+
+const (
+	stateTokenField     = "token"
+	stateSyncedAtField  = "synced_at"
+	stateRelationsField = "relations"
+)
+
+// unlockScript deletes the lock only if it is still held by the caller.
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// ValkeyStore stores the permission index in a Valkey (or Redis) database shared by the replicas. Every
+// set of the index is a Valkey set, and the sync point of each store is a hash. The keys of a store share
+// a hash tag, so that they live in the same slot of a cluster and can be updated in a transaction.
+type ValkeyStore struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+var _ Store = (*ValkeyStore)(nil)
+
+// NewValkeyStore returns a ValkeyStore connected to the Valkey instance at uri, e.g. redis://localhost:6379/0.
+func NewValkeyStore(uri, keyPrefix string) (*ValkeyStore, error) {
+	opts, err := redis.ParseURL(uri)
+	if err != nil {
+		return nil, fmt.Errorf("parse permission index valkey uri: %w", err)
+	}
+	return &ValkeyStore{
+		client:    redis.NewClient(opts),
+		keyPrefix: keyPrefix,
+	}, nil
+}
+
+func (v *ValkeyStore) storeKey(storeID, suffix string) string {
+	return v.keyPrefix + "{" + storeID + "}:" + suffix
+}
+
+func (v *ValkeyStore) entryKey(storeID, relation string, kind EntryKind, key string) string {
+	return v.storeKey(storeID, "e:"+entryKey(relation, kind, key))
+}
+
+func (v *ValkeyStore) Lock(ctx context.Context, storeID string, ttl time.Duration) (func(), bool, error) {
+	key := v.storeKey(storeID, "lock")
+	owner := ulid.Make().String()
+
+	ok, err := v.client.SetNX(ctx, key, owner, ttl).Result()
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	return func() {
+		// the lock expires on its own if it can't be released
+		_ = unlockScript.Run(context.Background(), v.client, []string{key}, owner).Err()
+	}, true, nil
+}
+
+func (v *ValkeyStore) ReadState(ctx context.Context, storeID string) (*State, error) {
+	fields, err := v.client.HGetAll(ctx, v.storeKey(storeID, "state")).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	state := &State{Token: fields[stateTokenField]}
+	if syncedAt := fields[stateSyncedAtField]; syncedAt != "" {
+		state.SyncedAt, err = time.Parse(time.RFC3339Nano, syncedAt)
+		if err != nil {
+			return nil, fmt.Errorf("decode permission index state of store '%s': %w", storeID, err)
+		}
+	}
+	if relations := fields[stateRelationsField]; relations != "" {
+		state.Relations = strings.Split(relations, ",")
+	}
+	return state, nil
+}
+
+func (v *ValkeyStore) Apply(ctx context.Context, storeID string, changes []Change, state *State) error {
+	_, err := v.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, change := range changes {
+			key := v.entryKey(storeID, change.Relation, change.Kind, change.Key)
+			if change.Delete {
+				pipe.SRem(ctx, key, change.Value)
+			} else {
+				pipe.SAdd(ctx, key, change.Value)
+			}
+		}
+
+		syncedAt := ""
+		if !state.SyncedAt.IsZero() {
+			syncedAt = state.SyncedAt.UTC().Format(time.RFC3339Nano)
+		}
+		pipe.HSet(ctx, v.storeKey(storeID, "state"),
+			stateTokenField, state.Token,
+			stateSyncedAtField, syncedAt,
+			stateRelationsField, strings.Join(state.Relations, ","),
+		)
+		return nil
+	})
+	return err
+}
+
+func (v *ValkeyStore) Reset(ctx context.Context, storeID string) error {
+	keys := []string{v.storeKey(storeID, "state")}
+	iter := v.client.Scan(ctx, 0, v.storeKey(storeID, "e:*"), 1000).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	for start := 0; start < len(keys); start += 1000 {
+		if err := v.client.Del(ctx, keys[start:min(start+1000, len(keys))]...).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *ValkeyStore) Read(ctx context.Context, storeID, relation string, kind EntryKind, keys []string) ([]string, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	setKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		setKeys = append(setKeys, v.entryKey(storeID, relation, kind, key))
+	}
+	return v.client.SUnion(ctx, setKeys...).Result()
+}
+
+func (v *ValkeyStore) Conditioned(ctx context.Context, storeID string, relations []string) ([]string, error) {
+	if len(relations) == 0 {
+		return nil, nil
+	}
+
+	cmds := make([]*redis.IntCmd, 0, len(relations))
+	_, err := v.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, relation := range relations {
+			cmds = append(cmds, pipe.Exists(ctx, v.entryKey(storeID, relation, ConditionedEntry, "")))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var conditioned []string
+	for i, cmd := range cmds {
+		if cmd.Val() > 0 {
+			conditioned = append(conditioned, relations[i])
+		}
+	}
+	return conditioned, nil
+}
+
+func (v *ValkeyStore) Close() {
+	_ = v.client.Close()
+}
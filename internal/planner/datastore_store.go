@@ -0,0 +1,67 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+// DatastoreSnapshotStore stores the snapshot of each replica in the datastore of the server, which the
+// replicas already share.
+type DatastoreSnapshotStore struct {
+	backend storage.PlannerSnapshotBackend
+	maxAge  time.Duration
+}
+
+var _ SnapshotStore = (*DatastoreSnapshotStore)(nil)
+
+// NewDatastoreSnapshotStore returns a DatastoreSnapshotStore that stores snapshots in backend. Snapshots that
+// were not written for maxAge, if positive, are deleted, so that the snapshots of replicas that are gone are
+// eventually dropped.
+func NewDatastoreSnapshotStore(backend storage.PlannerSnapshotBackend, maxAge time.Duration) *DatastoreSnapshotStore {
+	return &DatastoreSnapshotStore{backend: backend, maxAge: maxAge}
+}
+
+func (s *DatastoreSnapshotStore) Save(ctx context.Context, snapshot *Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	if err := s.backend.WritePlannerSnapshot(ctx, snapshot.ReplicaID, data); err != nil {
+		return err
+	}
+
+	if s.maxAge > 0 {
+		return s.backend.DeletePlannerSnapshots(ctx, s.maxAge)
+	}
+	return nil
+}
+
+// Load reads the snapshots of every replica. Snapshots that can't be decoded are skipped.
+func (s *DatastoreSnapshotStore) Load(ctx context.Context) (map[string]*Snapshot, error) {
+	values, err := s.backend.ReadPlannerSnapshots(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	snapshots := map[string]*Snapshot{}
+	for replicaID, data := range values {
+		var snapshot Snapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			errs = append(errs, fmt.Errorf("decode planner snapshot '%s': %w", replicaID, err))
+			continue
+		}
+		snapshots[snapshot.ReplicaID] = &snapshot
+	}
+	return snapshots, errors.Join(errs...)
+}
+
+// Close does not close the datastore, which the server owns.
+func (s *DatastoreSnapshotStore) Close() error {
+	return nil
+}
@@ -0,0 +1,89 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const snapshotFileExtension = ".json"
+
+// FileSnapshotStore stores the snapshot of each replica in its own JSON file of a directory. Replicas
+// share their snapshots by sharing the directory, e.g. through a shared volume.
+type FileSnapshotStore struct {
+	dir string
+}
+
+var _ SnapshotStore = (*FileSnapshotStore)(nil)
+
+// NewFileSnapshotStore returns a FileSnapshotStore that stores snapshots in dir, creating it if needed.
+func NewFileSnapshotStore(dir string) (*FileSnapshotStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create planner snapshot directory: %w", err)
+	}
+	return &FileSnapshotStore{dir: dir}, nil
+}
+
+// Save writes snapshot to a temporary file, then renames it so that readers never see a partial snapshot.
+func (s *FileSnapshotStore) Save(_ context.Context, snapshot *Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".snapshot-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(s.dir, url.PathEscape(snapshot.ReplicaID)+snapshotFileExtension))
+}
+
+// Load reads the snapshot files of the directory. Files that can't be read or decoded are skipped.
+func (s *FileSnapshotStore) Load(_ context.Context) (map[string]*Snapshot, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	snapshots := map[string]*Snapshot{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != snapshotFileExtension {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(s.dir, name))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		var snapshot Snapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			errs = append(errs, fmt.Errorf("decode planner snapshot '%s': %w", name, err))
+			continue
+		}
+		snapshots[snapshot.ReplicaID] = &snapshot
+	}
+	return snapshots, errors.Join(errs...)
+}
+
+func (s *FileSnapshotStore) Close() error {
+	return nil
+}
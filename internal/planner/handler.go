@@ -0,0 +1,29 @@
+package planner
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Handler returns an HTTP handler to inspect and reset what the planner learned:
+//   - GET returns the learned distribution of every plan of every key, as returned by Stats.
+//   - DELETE forgets what was learned, as Reset does.
+//
+// Both act on a single key if the "key" query parameter is set.
+func (p *Planner) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Query().Get("key")
+
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(p.Stats(key))
+		case http.MethodDelete:
+			p.Reset(key)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("Allow", "GET, DELETE")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}
+	})
+}
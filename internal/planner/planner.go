@@ -1,11 +1,28 @@
 package planner
 
 import (
+	"context"
 	"math/rand"
 	"sync"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/logger"
 )
 
+// snapshotOperationTimeout bounds how long a single operation on the SnapshotStore may take.
+const snapshotOperationTimeout = 5 * time.Second
+
+var snapshotErrorCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: build.ProjectName,
+	Name:      "planner_snapshot_error_count",
+	Help:      "The total number of failed planner snapshot operations labeled by operation (save or load).",
+}, []string{"operation"})
+
 // Planner is the top-level entry point for creating and managing plans for different keys.
 // It is safe for concurrent use and includes a background routine to evict old keys.
 type Planner struct {
@@ -16,6 +33,12 @@ type Planner struct {
 
 	wg          sync.WaitGroup
 	stopCleanup chan struct{}
+
+	store          SnapshotStore
+	replicaID      string
+	mergeReplicas  bool
+	snapshotMaxAge time.Duration
+	logger         logger.Logger
 }
 
 var _ Manager = (*Planner)(nil)
@@ -24,6 +47,18 @@ var _ Manager = (*Planner)(nil)
 type Config struct {
 	EvictionThreshold time.Duration // How long a key can be unused before being evicted. (e.g., 30 * time.Minute)
 	CleanupInterval   time.Duration // How often the planner checks for stale keys. (e.g., 5 * time.Minute)
+
+	// Store persists what the planner learns, so that it survives restarts (warm start). If nil, nothing is persisted.
+	Store SnapshotStore
+	// SnapshotInterval is how often the planner saves its snapshot, and merges the ones of other replicas if MergeReplicas is set.
+	SnapshotInterval time.Duration
+	// ReplicaID identifies the snapshot of this replica in the Store. It must be stable across restarts and unique across replicas.
+	ReplicaID string
+	// MergeReplicas merges the observations of the other replicas that share the Store into the planner statistics.
+	MergeReplicas bool
+	// SnapshotMaxAge is how old a snapshot may be to be restored or merged. If zero, snapshots never become stale.
+	SnapshotMaxAge time.Duration
+	Logger         logger.Logger
 }
 
 // New creates a new Planner with the specified configuration and starts its cleanup routine.
@@ -32,16 +67,31 @@ func New(config *Config) *Planner {
 		evictionThreshold: config.EvictionThreshold,
 		stopCleanup:       make(chan struct{}),
 		wg:                sync.WaitGroup{},
+		store:             config.Store,
+		replicaID:         config.ReplicaID,
+		mergeReplicas:     config.MergeReplicas,
+		snapshotMaxAge:    config.SnapshotMaxAge,
+		logger:            config.Logger,
 	}
 	p.rngPool.New = func() interface{} {
 		// Each new RNG is seeded to ensure different sequences.
 		return rand.New(rand.NewSource(time.Now().UnixNano()))
 	}
+	if p.logger == nil {
+		p.logger = logger.NewNoopLogger()
+	}
 
 	if config.EvictionThreshold > 0 && config.CleanupInterval > 0 {
 		p.startCleanupRoutine(config.CleanupInterval)
 	}
 
+	if p.store != nil {
+		p.warmStart()
+		if config.SnapshotInterval > 0 {
+			p.startSnapshotRoutine(config.SnapshotInterval)
+		}
+	}
+
 	return p
 }
 
@@ -106,8 +156,114 @@ func (p *Planner) evictStaleKeys() {
 	})
 }
 
-// Stop gracefully terminates the background cleanup goroutine.
+// warmStart restores the latest snapshot of this replica, and merges the ones of the other replicas if
+// MergeReplicas is set. Without a snapshot of its own and without merging, a new replica merges the latest
+// snapshot of another replica, so that it doesn't start cold either. Since merged observations are never
+// part of the snapshot of this replica, they are never counted twice.
+func (p *Planner) warmStart() {
+	snapshots := p.loadSnapshots()
+
+	own, ok := snapshots[p.replicaID]
+	delete(snapshots, p.replicaID)
+	if ok {
+		p.Restore(own)
+	}
+
+	if p.mergeReplicas {
+		p.merge(snapshots)
+		return
+	}
+
+	if !ok {
+		var latest *Snapshot
+		for _, snapshot := range snapshots {
+			if latest == nil || snapshot.TakenAt.After(latest.TakenAt) {
+				latest = snapshot
+			}
+		}
+		if latest != nil {
+			p.Merge([]*Snapshot{latest})
+		}
+	}
+}
+
+// startSnapshotRoutine runs a background goroutine that periodically saves the snapshot of this replica and
+// merges the ones of the other replicas.
+func (p *Planner) startSnapshotRoutine(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		for {
+			select {
+			case <-ticker.C:
+				p.saveSnapshot()
+				if p.mergeReplicas {
+					snapshots := p.loadSnapshots()
+					delete(snapshots, p.replicaID)
+					p.merge(snapshots)
+				}
+			case <-p.stopCleanup:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+func (p *Planner) merge(snapshots map[string]*Snapshot) {
+	others := make([]*Snapshot, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		others = append(others, snapshot)
+	}
+	p.Merge(others)
+}
+
+func (p *Planner) saveSnapshot() {
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotOperationTimeout)
+	defer cancel()
+
+	if err := p.store.Save(ctx, p.Snapshot()); err != nil {
+		snapshotErrorCounter.WithLabelValues("save").Inc()
+		p.logger.Warn("failed to save the planner snapshot", zap.Error(err))
+	}
+}
+
+// loadSnapshots returns the snapshots of the store that are not stale. Failures are logged, and whatever
+// could be loaded is returned.
+func (p *Planner) loadSnapshots() map[string]*Snapshot {
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotOperationTimeout)
+	defer cancel()
+
+	snapshots, err := p.store.Load(ctx)
+	if err != nil {
+		snapshotErrorCounter.WithLabelValues("load").Inc()
+		p.logger.Warn("failed to load the planner snapshots", zap.Error(err))
+	}
+	if snapshots == nil {
+		snapshots = map[string]*Snapshot{}
+	}
+
+	if p.snapshotMaxAge > 0 {
+		for replicaID, snapshot := range snapshots {
+			if time.Since(snapshot.TakenAt) > p.snapshotMaxAge {
+				delete(snapshots, replicaID)
+			}
+		}
+	}
+	return snapshots
+}
+
+// Stop gracefully terminates the background goroutines. If the planner has a Store, its final snapshot is
+// saved and the Store is closed.
 func (p *Planner) Stop() {
 	close(p.stopCleanup)
 	p.wg.Wait()
+
+	if p.store != nil {
+		p.saveSnapshot()
+		if err := p.store.Close(); err != nil {
+			p.logger.Warn("failed to close the planner snapshot store", zap.Error(err))
+		}
+	}
 }
@@ -125,3 +125,23 @@ func TestPlanner_EvictStaleKeys(t *testing.T) {
 	_, exists := p.keys.Load("fresh_key")
 	require.True(t, exists, "fresh key should not have been evicted")
 }
+
+func TestTrust(t *testing.T) {
+	trust := NewTrust(2)
+
+	require.False(t, trust.Trusted("key", "plan"))
+	trust.Record("key", "plan", true)
+	require.False(t, trust.Trusted("key", "plan"))
+	trust.Record("key", "plan", true)
+	require.True(t, trust.Trusted("key", "plan"))
+	require.False(t, trust.Trusted("other", "plan"))
+
+	trust.Record("key", "plan", false)
+	require.False(t, trust.Trusted("key", "plan"))
+	require.True(t, trust.Rejected("key", "plan"))
+
+	trust.Reset("key")
+	require.False(t, trust.Rejected("key", "plan"))
+
+	require.True(t, NewTrust(0).Trusted("key", "plan"))
+}
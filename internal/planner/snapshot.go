@@ -0,0 +1,144 @@
+package planner
+
+import (
+	"context"
+	"time"
+)
+
+// Snapshot holds the observations a replica made for every plan of every key, so that they can be restored
+// after a restart (warm start) or merged into the statistics of other replicas.
+type Snapshot struct {
+	ReplicaID string    `json:"replica_id"`
+	TakenAt   time.Time `json:"taken_at"`
+	// Keys maps each key to the evidence of each of its plans, by plan name.
+	Keys map[string]map[string]PlanEvidence `json:"keys"`
+}
+
+// PlanEvidence holds the local observations of a plan, along with the prior they apply to.
+type PlanEvidence struct {
+	Prior    Params   `json:"prior"`
+	Evidence Evidence `json:"evidence"`
+}
+
+// PlanStats describes the learned distribution of a plan.
+type PlanStats struct {
+	Prior     Params `json:"prior"`
+	Posterior Params `json:"posterior"`
+	// Local holds the observations of this replica, and Remote the ones merged from other replicas.
+	Local  Evidence `json:"local"`
+	Remote Evidence `json:"remote"`
+}
+
+// SnapshotStore persists the snapshots of the replicas of a planner.
+type SnapshotStore interface {
+	// Save stores snapshot as the latest snapshot of its replica.
+	Save(ctx context.Context, snapshot *Snapshot) error
+	// Load returns the latest snapshot of every replica, keyed by replica ID.
+	Load(ctx context.Context) (map[string]*Snapshot, error)
+	// Close releases the resources of the store.
+	Close() error
+}
+
+// Snapshot returns the local observations of every plan of every key.
+func (p *Planner) Snapshot() *Snapshot {
+	snapshot := &Snapshot{
+		ReplicaID: p.replicaID,
+		TakenAt:   time.Now().UTC(),
+		Keys:      map[string]map[string]PlanEvidence{},
+	}
+
+	p.rangeStats(func(key, plan string, ts *ThompsonStats) {
+		evidence := ts.LocalEvidence()
+		if evidence.Count == 0 {
+			return
+		}
+		if snapshot.Keys[key] == nil {
+			snapshot.Keys[key] = map[string]PlanEvidence{}
+		}
+		snapshot.Keys[key][plan] = PlanEvidence{Prior: ts.Prior(), Evidence: evidence}
+	})
+	return snapshot
+}
+
+// Restore replaces the local observations of the plans of snapshot by the ones it holds (warm start).
+func (p *Planner) Restore(snapshot *Snapshot) {
+	for key, plans := range snapshot.Keys {
+		for plan, planEvidence := range plans {
+			p.statsFor(key, plan, planEvidence.Prior).SetLocalEvidence(planEvidence.Evidence)
+		}
+	}
+}
+
+// Merge replaces the observations merged from other replicas by the ones of snapshots, the latest snapshot
+// of each of the other replicas. Plans that none of the snapshots holds lose their remote observations.
+func (p *Planner) Merge(snapshots []*Snapshot) {
+	remote := map[string]map[string]Evidence{}
+	for _, snapshot := range snapshots {
+		for key, plans := range snapshot.Keys {
+			if remote[key] == nil {
+				remote[key] = map[string]Evidence{}
+			}
+			for plan, planEvidence := range plans {
+				// make sure that the plan exists, even if it was never used by this replica
+				p.statsFor(key, plan, planEvidence.Prior)
+				remote[key][plan] = remote[key][plan].add(planEvidence.Evidence)
+			}
+		}
+	}
+
+	p.rangeStats(func(key, plan string, ts *ThompsonStats) {
+		ts.SetRemoteEvidence(remote[key][plan])
+	})
+}
+
+// Stats returns the learned distribution of every plan of every key, or only of key if it is not empty.
+func (p *Planner) Stats(key string) map[string]map[string]PlanStats {
+	stats := map[string]map[string]PlanStats{}
+	p.rangeStats(func(k, plan string, ts *ThompsonStats) {
+		if key != "" && k != key {
+			return
+		}
+		if stats[k] == nil {
+			stats[k] = map[string]PlanStats{}
+		}
+		stats[k][plan] = PlanStats{
+			Prior:     ts.Prior(),
+			Posterior: ts.Posterior(),
+			Local:     ts.LocalEvidence(),
+			Remote:    ts.RemoteEvidence(),
+		}
+	})
+	return stats
+}
+
+// Reset forgets what was learned for key, or for every key if key is empty. The observations of other
+// replicas are merged again on the next synchronization, unless they are reset too.
+func (p *Planner) Reset(key string) {
+	if key != "" {
+		p.keys.Delete(key)
+		return
+	}
+	p.keys.Clear()
+}
+
+func (p *Planner) rangeStats(f func(key, plan string, ts *ThompsonStats)) {
+	p.keys.Range(func(key, value any) bool {
+		value.(*keyPlan).stats.Range(func(plan, ts any) bool {
+			f(key.(string), plan.(string), ts.(*ThompsonStats))
+			return true
+		})
+		return true
+	})
+}
+
+// statsFor returns the stats of plan for key, creating them from prior if they don't exist.
+func (p *Planner) statsFor(key, plan string, prior Params) *ThompsonStats {
+	kp := p.GetPlanSelector(key).(*keyPlan)
+	return kp.getOrCreateStats(&PlanConfig{
+		Name:         plan,
+		InitialGuess: time.Duration(prior.Mu * float64(time.Millisecond)),
+		Lambda:       prior.Lambda,
+		Alpha:        prior.Alpha,
+		Beta:         prior.Beta,
+	})
+}
@@ -0,0 +1,203 @@
+package planner
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/storage/memory"
+)
+
+var (
+	fastPlan = &PlanConfig{Name: "fast", InitialGuess: 5 * time.Millisecond, Lambda: 1, Alpha: 1, Beta: 1}
+	slowPlan = &PlanConfig{Name: "slow", InitialGuess: 10 * time.Millisecond, Lambda: 1, Alpha: 1, Beta: 1}
+)
+
+func TestPlanner_SnapshotRestoreAndMerge(t *testing.T) {
+	p := New(&Config{ReplicaID: "a"})
+	t.Cleanup(p.Stop)
+
+	kp := p.GetPlanSelector("key")
+	kp.UpdateStats(fastPlan, 2*time.Millisecond)
+	kp.UpdateStats(fastPlan, 4*time.Millisecond)
+	kp.UpdateStats(slowPlan, 20*time.Millisecond)
+	p.GetPlanSelector("unused").Select(map[string]*PlanConfig{"fast": fastPlan})
+
+	snapshot := p.Snapshot()
+	require.Equal(t, "a", snapshot.ReplicaID)
+	require.Len(t, snapshot.Keys, 1, "keys without observations are not part of the snapshot")
+	require.InDelta(t, float64(2), snapshot.Keys["key"]["fast"].Evidence.Count, 0)
+
+	t.Run("restore", func(t *testing.T) {
+		restored := New(&Config{})
+		t.Cleanup(restored.Stop)
+		restored.Restore(snapshot)
+
+		expected, actual := p.Stats("key")["key"], restored.Stats("key")["key"]
+		require.Len(t, actual, len(expected))
+		for plan, stats := range expected {
+			require.Equal(t, stats.Local, actual[plan].Local)
+			require.InDelta(t, stats.Posterior.Mu, actual[plan].Posterior.Mu, 1e-9)
+			require.InDelta(t, stats.Posterior.Beta, actual[plan].Posterior.Beta, 1e-9)
+		}
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		other := New(&Config{ReplicaID: "b"})
+		t.Cleanup(other.Stop)
+		other.GetPlanSelector("key").UpdateStats(fastPlan, 3*time.Millisecond)
+
+		other.Merge([]*Snapshot{snapshot})
+		other.Merge([]*Snapshot{snapshot})
+
+		stats := other.Stats("key")["key"]
+		require.InDelta(t, float64(1), stats["fast"].Local.Count, 0)
+		require.InDelta(t, float64(2), stats["fast"].Remote.Count, 0)
+		require.InDelta(t, float64(1), stats["slow"].Remote.Count, 0)
+		require.InDelta(t, stats["fast"].Prior.Lambda+3, stats["fast"].Posterior.Lambda, 1e-9)
+
+		// the snapshot of a replica only holds what it observed itself
+		require.InDelta(t, float64(1), other.Snapshot().Keys["key"]["fast"].Evidence.Count, 0)
+
+		other.Merge(nil)
+		require.InDelta(t, float64(0), other.Stats("key")["key"]["fast"].Remote.Count, 0)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		p.Reset("key")
+		require.Empty(t, p.Stats("key"))
+		require.NotEmpty(t, p.Stats("unused"))
+
+		p.Reset("")
+		require.Empty(t, p.Stats(""))
+	})
+}
+
+func TestPlanner_WarmStart(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileSnapshotStore(dir)
+	require.NoError(t, err)
+
+	p := New(&Config{Store: store, ReplicaID: "a", SnapshotInterval: time.Hour})
+	p.GetPlanSelector("key").UpdateStats(fastPlan, 2*time.Millisecond)
+	p.Stop() // saves the final snapshot
+
+	_, err = os.Stat(filepath.Join(dir, "a.json"))
+	require.NoError(t, err)
+
+	t.Run("same_replica", func(t *testing.T) {
+		restarted := New(&Config{Store: store, ReplicaID: "a"})
+		t.Cleanup(restarted.Stop)
+
+		require.InDelta(t, float64(1), restarted.Stats("key")["key"]["fast"].Local.Count, 0)
+	})
+
+	t.Run("new_replica", func(t *testing.T) {
+		replica := New(&Config{Store: store, ReplicaID: "b"})
+		t.Cleanup(replica.Stop)
+
+		// the latest snapshot of another replica is merged, so that it is not part of the snapshot of this replica
+		stats := replica.Stats("key")["key"]["fast"]
+		require.InDelta(t, float64(0), stats.Local.Count, 0)
+		require.InDelta(t, float64(1), stats.Remote.Count, 0)
+	})
+
+	t.Run("new_replica_merging", func(t *testing.T) {
+		replica := New(&Config{Store: store, ReplicaID: "c", MergeReplicas: true})
+		t.Cleanup(replica.Stop)
+
+		stats := replica.Stats("key")["key"]["fast"]
+		require.InDelta(t, float64(0), stats.Local.Count, 0)
+		require.InDelta(t, float64(1), stats.Remote.Count, 0)
+	})
+
+	t.Run("stale_snapshots", func(t *testing.T) {
+		replica := New(&Config{Store: store, ReplicaID: "d", SnapshotMaxAge: time.Nanosecond})
+		t.Cleanup(replica.Stop)
+
+		require.Empty(t, replica.Stats("key"))
+	})
+}
+
+func TestFileSnapshotStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileSnapshotStore(dir)
+	require.NoError(t, err)
+
+	snapshot := &Snapshot{
+		ReplicaID: "replica/1",
+		TakenAt:   time.Now().UTC().Truncate(time.Second),
+		Keys: map[string]map[string]PlanEvidence{
+			"key": {"fast": {Prior: Params{Mu: 5, Lambda: 1, Alpha: 1, Beta: 1}, Evidence: Evidence{Count: 1, Sum: 2, SumSquares: 4}}},
+		},
+	}
+	require.NoError(t, store.Save(t.Context(), snapshot))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "corrupted.json"), []byte("{"), 0o600))
+
+	snapshots, err := store.Load(t.Context())
+	require.Error(t, err)
+	require.Equal(t, map[string]*Snapshot{"replica/1": snapshot}, snapshots)
+}
+
+func TestDatastoreSnapshotStore(t *testing.T) {
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+	store := NewDatastoreSnapshotStore(ds, time.Hour)
+
+	snapshot := &Snapshot{
+		ReplicaID: "replica/1",
+		TakenAt:   time.Now().UTC().Truncate(time.Second),
+		Keys: map[string]map[string]PlanEvidence{
+			"key": {"fast": {Prior: Params{Mu: 5, Lambda: 1, Alpha: 1, Beta: 1}, Evidence: Evidence{Count: 1, Sum: 2, SumSquares: 4}}},
+		},
+	}
+	require.NoError(t, store.Save(t.Context(), snapshot))
+	require.NoError(t, ds.WritePlannerSnapshot(t.Context(), "corrupted", []byte("{")))
+
+	snapshots, err := store.Load(t.Context())
+	require.Error(t, err)
+	require.Equal(t, map[string]*Snapshot{"replica/1": snapshot}, snapshots)
+
+	// saving deletes the snapshots that were not written for the max age, e.g. the corrupted one
+	store = NewDatastoreSnapshotStore(ds, 50*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, store.Save(t.Context(), snapshot))
+
+	snapshots, err = store.Load(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, map[string]*Snapshot{"replica/1": snapshot}, snapshots)
+}
+
+func TestPlanner_Handler(t *testing.T) {
+	p := New(&Config{})
+	t.Cleanup(p.Stop)
+	p.GetPlanSelector("a").UpdateStats(fastPlan, 2*time.Millisecond)
+	p.GetPlanSelector("b").UpdateStats(fastPlan, 2*time.Millisecond)
+
+	handler := p.Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/planner?key=a", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var stats map[string]map[string]PlanStats
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &stats))
+	require.Len(t, stats, 1)
+	require.InDelta(t, float64(1), stats["a"]["fast"].Local.Count, 0)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/debug/planner?key=a", nil))
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Empty(t, p.Stats("a"))
+	require.NotEmpty(t, p.Stats("b"))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/planner", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
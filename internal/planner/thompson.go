@@ -14,6 +14,8 @@ import (
 // which models our belief about the performance (execution time) of a strategy.
 type ThompsonStats struct {
 	params unsafe.Pointer // *samplingParams - atomic access
+	// prior holds the parameters the distribution started from, before any observation.
+	prior samplingParams
 }
 
 type samplingParams struct {
@@ -21,6 +23,50 @@ type samplingParams struct {
 	lambda float64
 	alpha  float64
 	beta   float64
+
+	// local holds the observations made by this process, and remote the ones merged from other replicas.
+	local  Evidence
+	remote Evidence
+}
+
+// Params holds the parameters of a Normal-gamma distribution. Mu is expressed in milliseconds.
+type Params struct {
+	Mu     float64 `json:"mu"`
+	Lambda float64 `json:"lambda"`
+	Alpha  float64 `json:"alpha"`
+	Beta   float64 `json:"beta"`
+}
+
+// Evidence holds the sufficient statistics of a set of observed execution times, in milliseconds. Since
+// the Normal-gamma distribution is a conjugate prior, the posterior only depends on the prior and on the
+// evidence, whatever the order in which the observations were made. This is what allows evidence to be
+// persisted and merged across replicas.
+type Evidence struct {
+	Count      float64 `json:"count"`
+	Sum        float64 `json:"sum"`
+	SumSquares float64 `json:"sum_squares"`
+}
+
+func (e Evidence) add(o Evidence) Evidence {
+	return Evidence{Count: e.Count + o.Count, Sum: e.Sum + o.Sum, SumSquares: e.SumSquares + o.SumSquares}
+}
+
+// posterior returns the parameters of the distribution after observing evidence, starting from prior.
+func posterior(prior samplingParams, evidence Evidence) (mu, lambda, alpha, beta float64) {
+	n := evidence.Count
+	if n <= 0 {
+		return prior.mu, prior.lambda, prior.alpha, prior.beta
+	}
+
+	mean := evidence.Sum / n
+	// sum of the squared deviations from the mean of the observations
+	deviations := math.Max(evidence.SumSquares-evidence.Sum*mean, 0)
+
+	lambda = prior.lambda + n
+	mu = (prior.lambda*prior.mu + evidence.Sum) / lambda
+	alpha = prior.alpha + n/2
+	beta = prior.beta + deviations/2 + (prior.lambda*n*(mean-prior.mu)*(mean-prior.mu))/(2*lambda)
+	return mu, lambda, alpha, beta
 }
 
 // Sample draws a random execution time from the learned distribution.
@@ -100,6 +146,8 @@ func (ts *ThompsonStats) Update(duration time.Duration) {
 			lambda: newLambda,
 			alpha:  newAlpha,
 			beta:   newBeta,
+			local:  currentParams.local.add(Evidence{Count: 1, Sum: x, SumSquares: x * x}),
+			remote: currentParams.remote,
 		}
 
 		// 3. Try to atomically swap the old pointer with the new one.
@@ -111,21 +159,69 @@ func (ts *ThompsonStats) Update(duration time.Duration) {
 	}
 }
 
+// Prior returns the parameters the distribution started from.
+func (ts *ThompsonStats) Prior() Params {
+	return Params{Mu: ts.prior.mu, Lambda: ts.prior.lambda, Alpha: ts.prior.alpha, Beta: ts.prior.beta}
+}
+
+// Posterior returns the current parameters of the distribution.
+func (ts *ThompsonStats) Posterior() Params {
+	params := (*samplingParams)(atomic.LoadPointer(&ts.params))
+	return Params{Mu: params.mu, Lambda: params.lambda, Alpha: params.alpha, Beta: params.beta}
+}
+
+// LocalEvidence returns the observations made by this process, including the ones restored with SetLocalEvidence.
+func (ts *ThompsonStats) LocalEvidence() Evidence {
+	return (*samplingParams)(atomic.LoadPointer(&ts.params)).local
+}
+
+// RemoteEvidence returns the observations merged from other replicas with SetRemoteEvidence.
+func (ts *ThompsonStats) RemoteEvidence() Evidence {
+	return (*samplingParams)(atomic.LoadPointer(&ts.params)).remote
+}
+
+// SetLocalEvidence replaces the observations made by this process, e.g. to restore them from a snapshot.
+func (ts *ThompsonStats) SetLocalEvidence(evidence Evidence) {
+	ts.setEvidence(func(params *samplingParams) { params.local = evidence })
+}
+
+// SetRemoteEvidence replaces the observations merged from other replicas. Since the evidence of other
+// replicas is replaced rather than accumulated, merging their latest snapshots never counts the same
+// observations twice.
+func (ts *ThompsonStats) SetRemoteEvidence(evidence Evidence) {
+	ts.setEvidence(func(params *samplingParams) { params.remote = evidence })
+}
+
+func (ts *ThompsonStats) setEvidence(set func(params *samplingParams)) {
+	for {
+		oldPtr := atomic.LoadPointer(&ts.params)
+		newParams := *(*samplingParams)(oldPtr)
+		set(&newParams)
+		newParams.mu, newParams.lambda, newParams.alpha, newParams.beta = posterior(ts.prior, newParams.local.add(newParams.remote))
+
+		if atomic.CompareAndSwapPointer(&ts.params, oldPtr, unsafe.Pointer(&newParams)) {
+			return
+		}
+	}
+}
+
 // NewThompsonStats creates a new stats object with a diffuse prior,
 // representing our initial uncertainty about a strategy's performance.
 func NewThompsonStats(initialGuess time.Duration, lambda, alpha, beta float64) *ThompsonStats {
 	initialMs := float64(initialGuess.Nanoseconds()) / 1e6
 
-	ts := &ThompsonStats{}
+	ts := &ThompsonStats{
+		prior: samplingParams{
+			mu:     initialMs,
+			lambda: lambda,
+			alpha:  alpha,
+			beta:   beta,
+		},
+	}
 
 	// Create the initial immutable parameter snapshot.
-	params := &samplingParams{
-		mu:     initialMs,
-		lambda: lambda,
-		alpha:  alpha,
-		beta:   beta,
-	}
-	atomic.StorePointer(&ts.params, unsafe.Pointer(params))
+	params := ts.prior
+	atomic.StorePointer(&ts.params, unsafe.Pointer(&params))
 
 	return ts
 }
@@ -78,3 +78,44 @@ func BenchmarkThompsonStats_SampleParallel(b *testing.B) {
 		}
 	})
 }
+
+func TestThompsonStats_Evidence(t *testing.T) {
+	durations := []time.Duration{3 * time.Millisecond, 12 * time.Millisecond, 7 * time.Millisecond, 40 * time.Millisecond}
+
+	updated := NewThompsonStats(10*time.Millisecond, 2, 1, 1)
+	for _, d := range durations {
+		updated.Update(d)
+	}
+
+	// the posterior only depends on the evidence, so restoring it gives the same distribution
+	restored := NewThompsonStats(10*time.Millisecond, 2, 1, 1)
+	restored.SetLocalEvidence(updated.LocalEvidence())
+	requireParamsInDelta(t, updated.Posterior(), restored.Posterior())
+
+	// and so does splitting it between local and remote observations
+	split := NewThompsonStats(10*time.Millisecond, 2, 1, 1)
+	for _, d := range durations[:2] {
+		split.Update(d)
+	}
+	other := NewThompsonStats(10*time.Millisecond, 2, 1, 1)
+	for _, d := range durations[2:] {
+		other.Update(d)
+	}
+	split.SetRemoteEvidence(other.LocalEvidence())
+	requireParamsInDelta(t, updated.Posterior(), split.Posterior())
+
+	// replacing the remote observations doesn't count them twice
+	split.SetRemoteEvidence(other.LocalEvidence())
+	requireParamsInDelta(t, updated.Posterior(), split.Posterior())
+
+	split.SetRemoteEvidence(Evidence{})
+	require.InDelta(t, float64(2), split.Posterior().Lambda-split.Prior().Lambda, 1e-9)
+}
+
+func requireParamsInDelta(t *testing.T, expected, actual Params) {
+	t.Helper()
+	require.InDelta(t, expected.Mu, actual.Mu, 1e-9)
+	require.InDelta(t, expected.Lambda, actual.Lambda, 1e-9)
+	require.InDelta(t, expected.Alpha, actual.Alpha, 1e-9)
+	require.InDelta(t, expected.Beta, actual.Beta, 1e-9)
+}
@@ -0,0 +1,78 @@
+package planner
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Trust tracks, for every key, which plans returned the same results as a trusted baseline plan. It is meant
+// for plans that are not known to be equivalent to the baseline in every case: such a plan is validated in
+// shadow until it is trusted, after a number of consecutive matching validations, or rejected as soon as a
+// validation doesn't match. Rejected plans stay rejected until Reset.
+type Trust struct {
+	required uint32
+	plans    sync.Map // map[trustKey]*planTrust
+}
+
+type trustKey struct {
+	key  string
+	plan string
+}
+
+type planTrust struct {
+	matches  atomic.Uint32
+	rejected atomic.Bool
+}
+
+// NewTrust returns a Trust that trusts a plan for a key after required consecutive matching validations.
+func NewTrust(required uint32) *Trust {
+	return &Trust{required: required}
+}
+
+func (t *Trust) load(key, plan string) (*planTrust, bool) {
+	value, ok := t.plans.Load(trustKey{key: key, plan: plan})
+	if !ok {
+		return nil, false
+	}
+	return value.(*planTrust), true
+}
+
+// Trusted returns whether plan may be used for key without being validated.
+func (t *Trust) Trusted(key, plan string) bool {
+	pt, ok := t.load(key, plan)
+	if !ok {
+		return t.required == 0
+	}
+	return !pt.rejected.Load() && pt.matches.Load() >= t.required
+}
+
+// Rejected returns whether a validation of plan for key didn't match the baseline.
+func (t *Trust) Rejected(key, plan string) bool {
+	pt, ok := t.load(key, plan)
+	return ok && pt.rejected.Load()
+}
+
+// Record records the outcome of a validation of plan for key.
+func (t *Trust) Record(key, plan string, matched bool) {
+	value, _ := t.plans.LoadOrStore(trustKey{key: key, plan: plan}, &planTrust{})
+	pt := value.(*planTrust)
+	if !matched {
+		pt.rejected.Store(true)
+		return
+	}
+	pt.matches.Add(1)
+}
+
+// Reset forgets the validations of every plan for key, or for every key if key is empty.
+func (t *Trust) Reset(key string) {
+	if key == "" {
+		t.plans.Clear()
+		return
+	}
+	t.plans.Range(func(k, _ any) bool {
+		if k.(trustKey).key == key {
+			t.plans.Delete(k)
+		}
+		return true
+	})
+}
@@ -0,0 +1,90 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultValkeySnapshotKeyPrefix is the default prefix of the keys of the ValkeySnapshotStore.
+const DefaultValkeySnapshotKeyPrefix = "openfga:planner:"
+
+// ValkeySnapshotStore stores the snapshot of each replica under its own key of a Valkey (or Redis)
+// database shared by the replicas.
+type ValkeySnapshotStore struct {
+	client    *redis.Client
+	keyPrefix string
+	ttl       time.Duration
+}
+
+var _ SnapshotStore = (*ValkeySnapshotStore)(nil)
+
+// NewValkeySnapshotStore returns a ValkeySnapshotStore connected to the Valkey instance at uri, e.g.
+// redis://localhost:6379/0. Snapshots expire after ttl, if positive, so that the snapshots of replicas
+// that are gone are eventually dropped.
+func NewValkeySnapshotStore(uri, keyPrefix string, ttl time.Duration) (*ValkeySnapshotStore, error) {
+	opts, err := redis.ParseURL(uri)
+	if err != nil {
+		return nil, fmt.Errorf("parse planner snapshot valkey uri: %w", err)
+	}
+	return &ValkeySnapshotStore{
+		client:    redis.NewClient(opts),
+		keyPrefix: keyPrefix,
+		ttl:       ttl,
+	}, nil
+}
+
+func (s *ValkeySnapshotStore) Save(ctx context.Context, snapshot *Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.keyPrefix+snapshot.ReplicaID, data, s.ttl).Err()
+}
+
+// Load reads the snapshots of every replica. Snapshots that can't be decoded are skipped.
+func (s *ValkeySnapshotStore) Load(ctx context.Context) (map[string]*Snapshot, error) {
+	var keys []string
+	iter := s.client.Scan(ctx, 0, s.keyPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	snapshots := map[string]*Snapshot{}
+	if len(keys) == 0 {
+		return snapshots, nil
+	}
+
+	values, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			// the key expired in the meantime
+			continue
+		}
+
+		var snapshot Snapshot
+		if err := json.Unmarshal([]byte(data), &snapshot); err != nil {
+			errs = append(errs, fmt.Errorf("decode planner snapshot '%s': %w", keys[i], err))
+			continue
+		}
+		snapshots[snapshot.ReplicaID] = &snapshot
+	}
+	return snapshots, errors.Join(errs...)
+}
+
+func (s *ValkeySnapshotStore) Close() error {
+	return s.client.Close()
+}
@@ -11,6 +11,7 @@ import (
 	serverconfig "github.com/openfga/openfga/pkg/server/config"
 	"github.com/openfga/openfga/pkg/storage"
 	"github.com/openfga/openfga/pkg/storage/storagewrappers/sharediterator"
+	"github.com/openfga/openfga/pkg/storage/valkey"
 )
 
 // SharedDatastoreResourcesOpt defines an option that can be used to change the behavior of SharedDatastoreResources
@@ -71,16 +72,14 @@ func NewSharedDatastoreResources(
 
 	if settings.ShouldCreateNewCache() {
 		var err error
-		s.CheckCache, err = storage.NewInMemoryLRUCache([]storage.InMemoryLRUCacheOpt[any]{
-			storage.WithMaxCacheSize[any](int64(settings.CheckCacheLimit)),
-		}...)
+		s.CheckCache, err = newCheckCache(settings, "")
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	if settings.ShouldCreateCacheController() {
-		s.CacheController = cachecontroller.NewCacheController(ds, s.CheckCache, settings.CacheControllerTTL, settings.CheckQueryCacheTTL, settings.CheckIteratorCacheTTL, cachecontroller.WithLogger(s.Logger))
+		s.CacheController = cachecontroller.NewCacheController(ds, s.CheckCache, settings.CacheControllerTTL, settings.CheckQueryCacheTTL, settings.CheckIteratorCacheTTL, cacheControllerOpts(settings, s.Logger)...)
 	}
 
 	// The default behavior is to use the same cache instance for both the
@@ -92,16 +91,14 @@ func NewSharedDatastoreResources(
 
 	if settings.ShouldCreateShadowNewCache() {
 		var err error
-		s.ShadowCheckCache, err = storage.NewInMemoryLRUCache([]storage.InMemoryLRUCacheOpt[any]{
-			storage.WithMaxCacheSize[any](int64(settings.CheckCacheLimit)),
-		}...)
+		s.ShadowCheckCache, err = newCheckCache(settings, "shadow:")
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	if settings.ShouldCreateShadowCacheController() {
-		s.ShadowCacheController = cachecontroller.NewCacheController(ds, s.ShadowCheckCache, settings.CacheControllerTTL, settings.CheckQueryCacheTTL, settings.CheckIteratorCacheTTL, cachecontroller.WithLogger(s.Logger))
+		s.ShadowCacheController = cachecontroller.NewCacheController(ds, s.ShadowCheckCache, settings.CacheControllerTTL, settings.CheckQueryCacheTTL, settings.CheckIteratorCacheTTL, cacheControllerOpts(settings, s.Logger)...)
 	}
 
 	for _, opt := range opts {
@@ -111,6 +108,35 @@ func NewSharedDatastoreResources(
 	return s, nil
 }
 
+// cacheControllerOpts returns the options of the cache controllers of the settings.
+func cacheControllerOpts(settings serverconfig.CacheSettings, l logger.Logger) []cachecontroller.InMemoryCacheControllerOpt {
+	opts := []cachecontroller.InMemoryCacheControllerOpt{cachecontroller.WithLogger(l)}
+	if settings.ShouldCacheListObjectsQueries() {
+		opts = append(opts, cachecontroller.WithListObjectsCacheTTL(settings.ListObjectsQueryCacheTTL))
+	}
+	return opts
+}
+
+// newCheckCache returns the cache of Check sub-problems and iterators. If it is stored in Valkey, its keys
+// are prefixed with keyPrefix in addition to the configured prefix, so that several caches can share it.
+func newCheckCache(settings serverconfig.CacheSettings, keyPrefix string) (storage.InMemoryCache[any], error) {
+	if !settings.CheckCacheValkeyEnabled {
+		return storage.NewInMemoryLRUCache([]storage.InMemoryLRUCacheOpt[any]{
+			storage.WithMaxCacheSize[any](int64(settings.CheckCacheLimit)),
+		}...)
+	}
+
+	opts := []valkey.CacheOpt[any]{
+		valkey.WithCacheKeyPrefix[any](settings.CheckCacheValkeyKeyPrefix + keyPrefix),
+		// the cache controller invalidation timestamps must be the same on every replica
+		valkey.WithCacheLocalTierBypass[any](storage.IsCacheInvalidationKey),
+	}
+	if settings.CheckCacheValkeyLocalTierEnabled {
+		opts = append(opts, valkey.WithCacheLocalTier[any](int64(settings.CheckCacheLimit), settings.CheckCacheValkeyLocalTierTTL))
+	}
+	return valkey.NewCache(settings.CheckCacheValkeyURI, storage.NewCacheItemCodec(), opts...)
+}
+
 func (s *SharedDatastoreResources) Close() {
 	// wait for any goroutines still in flight before
 	// closing the cache instance to avoid data races
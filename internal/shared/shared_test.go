@@ -11,6 +11,7 @@ import (
 	"github.com/openfga/openfga/internal/cachecontroller"
 	mockstorage "github.com/openfga/openfga/internal/mocks"
 	"github.com/openfga/openfga/pkg/server/config"
+	"github.com/openfga/openfga/pkg/storage/valkey"
 )
 
 func TestSharedDatastoreResources(t *testing.T) {
@@ -97,4 +98,36 @@ func TestSharedDatastoreResources(t *testing.T) {
 		require.True(t, ok)
 		require.NotEqual(t, s.CacheController, s.ShadowCacheController)
 	})
+
+	t.Run("with_valkey_cache", func(t *testing.T) {
+		settings := config.CacheSettings{
+			CheckCacheLimit:           1,
+			CheckIteratorCacheEnabled: true,
+			CheckCacheValkeyEnabled:   true,
+			CheckCacheValkeyURI:       "redis://localhost:6379/0",
+		}
+
+		// the connection to Valkey is lazy, so the caches are created without one
+		s, err := NewSharedDatastoreResources(sharedCtx, sharedSf, mockDatastore, settings)
+		require.NoError(t, err)
+		t.Cleanup(s.Close)
+
+		_, ok := s.CheckCache.(*valkey.Cache[any])
+		require.True(t, ok)
+		_, ok = s.ShadowCheckCache.(*valkey.Cache[any])
+		require.True(t, ok)
+		require.NotEqual(t, s.CheckCache, s.ShadowCheckCache)
+	})
+
+	t.Run("with_invalid_valkey_uri", func(t *testing.T) {
+		settings := config.CacheSettings{
+			CheckCacheLimit:           1,
+			CheckIteratorCacheEnabled: true,
+			CheckCacheValkeyEnabled:   true,
+			CheckCacheValkeyURI:       "localhost:6379",
+		}
+
+		_, err := NewSharedDatastoreResources(sharedCtx, sharedSf, mockDatastore, settings)
+		require.Error(t, err)
+	})
 }
@@ -0,0 +1,102 @@
+// Package streamedbatchcheck defines the server-streaming variant of BatchCheck. It takes a regular
+// BatchCheckRequest, without the per-request limit on the number of checks, and streams a
+// BatchCheckResponse holding the result of each check as soon as it is resolved.
+package streamedbatchcheck
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+)
+
+const (
+	// ServiceName is the fully qualified name of the streamed batch check gRPC service.
+	ServiceName = "openfga.batchcheck.v1.StreamedBatchCheckService"
+
+	streamedBatchCheckMethod = "/" + ServiceName + "/StreamedBatchCheck"
+)
+
+// StreamedBatchCheckServer is implemented by the node that serves StreamedBatchCheck.
+type StreamedBatchCheckServer interface {
+	StreamedBatchCheck(req *openfgav1.BatchCheckRequest, stream StreamServer) error
+}
+
+// StreamServer is the server side of a StreamedBatchCheck stream.
+type StreamServer interface {
+	Send(*openfgav1.BatchCheckResponse) error
+	grpc.ServerStream
+}
+
+// StreamClient is the client side of a StreamedBatchCheck stream.
+type StreamClient interface {
+	Recv() (*openfgav1.BatchCheckResponse, error)
+	grpc.ClientStream
+}
+
+// RegisterStreamedBatchCheckServer registers the streamed batch check service on the provided gRPC server.
+func RegisterStreamedBatchCheckServer(s grpc.ServiceRegistrar, srv StreamedBatchCheckServer) {
+	s.RegisterService(&ServiceDesc, srv)
+}
+
+// ServiceDesc is the grpc.ServiceDesc of the streamed batch check service.
+var ServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*StreamedBatchCheckServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamedBatchCheck",
+			Handler:       streamedBatchCheckHandler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "internal/streamedbatchcheck/service.go",
+}
+
+type streamServer struct {
+	grpc.ServerStream
+}
+
+func (s *streamServer) Send(m *openfgav1.BatchCheckResponse) error {
+	return s.ServerStream.SendMsg(m)
+}
+
+func streamedBatchCheckHandler(srv any, stream grpc.ServerStream) error {
+	in := new(openfgav1.BatchCheckRequest)
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	return srv.(StreamedBatchCheckServer).StreamedBatchCheck(in, &streamServer{stream})
+}
+
+type streamClient struct {
+	grpc.ClientStream
+}
+
+func (c *streamClient) Recv() (*openfgav1.BatchCheckResponse, error) {
+	m := new(openfgav1.BatchCheckResponse)
+	if err := c.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// StreamedBatchCheck opens a StreamedBatchCheck stream on the provided connection. The stream ends with
+// io.EOF once the result of every check was received.
+func StreamedBatchCheck(ctx context.Context, conn grpc.ClientConnInterface, req *openfgav1.BatchCheckRequest, opts ...grpc.CallOption) (StreamClient, error) {
+	stream, err := conn.NewStream(ctx, &ServiceDesc.Streams[0], streamedBatchCheckMethod, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := stream.SendMsg(req); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	return &streamClient{stream}, nil
+}
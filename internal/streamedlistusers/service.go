@@ -0,0 +1,102 @@
+// Package streamedlistusers defines the server-streaming variant of ListUsers. It takes a regular
+// ListUsersRequest and streams each user as soon as it is found, without the limit on the number
+// of results of ListUsers.
+package streamedlistusers
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+)
+
+const (
+	// ServiceName is the fully qualified name of the streamed list users gRPC service.
+	ServiceName = "openfga.listusers.v1.StreamedListUsersService"
+
+	streamedListUsersMethod = "/" + ServiceName + "/StreamedListUsers"
+)
+
+// StreamedListUsersServer is implemented by the node that serves StreamedListUsers.
+type StreamedListUsersServer interface {
+	StreamedListUsers(req *openfgav1.ListUsersRequest, stream StreamServer) error
+}
+
+// StreamServer is the server side of a StreamedListUsers stream.
+type StreamServer interface {
+	Send(*openfgav1.User) error
+	grpc.ServerStream
+}
+
+// StreamClient is the client side of a StreamedListUsers stream.
+type StreamClient interface {
+	Recv() (*openfgav1.User, error)
+	grpc.ClientStream
+}
+
+// RegisterStreamedListUsersServer registers the streamed list users service on the provided gRPC server.
+func RegisterStreamedListUsersServer(s grpc.ServiceRegistrar, srv StreamedListUsersServer) {
+	s.RegisterService(&ServiceDesc, srv)
+}
+
+// ServiceDesc is the grpc.ServiceDesc of the streamed list users service.
+var ServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*StreamedListUsersServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamedListUsers",
+			Handler:       streamedListUsersHandler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "internal/streamedlistusers/service.go",
+}
+
+type streamServer struct {
+	grpc.ServerStream
+}
+
+func (s *streamServer) Send(m *openfgav1.User) error {
+	return s.ServerStream.SendMsg(m)
+}
+
+func streamedListUsersHandler(srv any, stream grpc.ServerStream) error {
+	in := new(openfgav1.ListUsersRequest)
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	return srv.(StreamedListUsersServer).StreamedListUsers(in, &streamServer{stream})
+}
+
+type streamClient struct {
+	grpc.ClientStream
+}
+
+func (c *streamClient) Recv() (*openfgav1.User, error) {
+	m := new(openfgav1.User)
+	if err := c.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// StreamedListUsers opens a StreamedListUsers stream on the provided connection. The stream ends with
+// io.EOF once every user was received.
+func StreamedListUsers(ctx context.Context, conn grpc.ClientConnInterface, req *openfgav1.ListUsersRequest, opts ...grpc.CallOption) (StreamClient, error) {
+	stream, err := conn.NewStream(ctx, &ServiceDesc.Streams[0], streamedListUsersMethod, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := stream.SendMsg(req); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	return &streamClient{stream}, nil
+}
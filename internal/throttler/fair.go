@@ -0,0 +1,295 @@
+package throttler
+
+import (
+	"container/heap"
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/authclaims"
+	"github.com/openfga/openfga/pkg/middleware/storeid"
+	"github.com/openfga/openfga/pkg/telemetry"
+)
+
+var (
+	throttlingQueueDepthGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: build.ProjectName,
+		Name:      "throttling_queue_depth",
+		Help:      "The number of dispatches waiting in a fair throttler, labeled by throttler name.",
+	}, []string{"throttler_name"})
+
+	throttlingStoreQueueDepthGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: build.ProjectName,
+		Name:      "throttling_store_queue_depth",
+		Help:      "The number of dispatches waiting in a fair throttler, labeled by throttler name and store ID. Only the stores with a configured weight have their own store ID label, the others are labeled \"other\".",
+	}, []string{"throttler_name", "store_id"})
+)
+
+// otherStoresLabel is the store ID label of the queue depth of the stores without a configured weight.
+const otherStoresLabel = "other"
+
+// FairThrottler releases the throttled dispatches at a constant rate, like the constant rate throttler, but
+// schedules them with weighted fair queuing across the stores they belong to: every store gets a share of the
+// releases proportional to its weight, whatever the number of dispatches it throttles, so that the dispatches of
+// a noisy store wait behind the ones of the other stores instead of slowing them down.
+//
+// The store of a dispatch is the one of the request it belongs to (see storeid.StoreIDFromContext). The
+// dispatches of requests without a store share a single flow.
+type FairThrottler struct {
+	name          string
+	ticker        *time.Ticker
+	weights       map[string]float64
+	defaultWeight float64
+	perClient     bool
+	done          chan struct{}
+	wg            sync.WaitGroup
+
+	mu          sync.Mutex
+	waiting     waiterHeap
+	flows       map[flowKey]*flow
+	virtualTime float64
+	seq         uint64
+}
+
+var _ Throttler = (*FairThrottler)(nil)
+
+type flowKey struct {
+	storeID  string
+	clientID string
+}
+
+type flow struct {
+	// finish is the virtual time at which the last dispatch of the flow is released
+	finish float64
+	depth  int
+}
+
+type waiter struct {
+	flow    flowKey
+	finish  float64
+	seq     uint64
+	release chan struct{}
+	index   int
+}
+
+type FairThrottlerOption func(*FairThrottler)
+
+// WithStoreWeights sets the weights of specific stores, by store ID. The stores without a weight weigh 1: a store
+// that weighs 2 gets twice as many releases as one that weighs 1 when both are throttled. The queue depth of the
+// stores with a weight is reported on its own.
+func WithStoreWeights(weights map[string]float64) FairThrottlerOption {
+	return func(t *FairThrottler) {
+		t.weights = weights
+	}
+}
+
+// WithPerClientFairness schedules the dispatches fairly across the clients of each store too, by the client ID of
+// the authenticated request (see authclaims.AuthClaimsFromContext). Each client gets the weight of its store.
+func WithPerClientFairness(enabled bool) FairThrottlerOption {
+	return func(t *FairThrottler) {
+		t.perClient = enabled
+	}
+}
+
+// NewFairThrottler constructs a FairThrottler that releases one throttled dispatch every frequency.
+func NewFairThrottler(frequency time.Duration, metricLabel string, opts ...FairThrottlerOption) *FairThrottler {
+	t := &FairThrottler{
+		name:          metricLabel,
+		ticker:        time.NewTicker(frequency),
+		defaultWeight: 1,
+		done:          make(chan struct{}),
+		flows:         map[flowKey]*flow{},
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	t.wg.Add(1)
+	go t.runTicker()
+	return t
+}
+
+func (t *FairThrottler) runTicker() {
+	defer t.wg.Done()
+	for {
+		select {
+		case <-t.done:
+			return
+		case <-t.ticker.C:
+			t.releaseNext()
+		}
+	}
+}
+
+// releaseNext releases the waiting dispatch with the earliest virtual finish time, if any.
+func (t *FairThrottler) releaseNext() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.waiting.Len() == 0 {
+		return
+	}
+	w := heap.Pop(&t.waiting).(*waiter)
+	t.virtualTime = math.Max(t.virtualTime, w.finish)
+	t.dequeued(w)
+	close(w.release)
+}
+
+// Throttle blocks until the dispatch is released by the fair scheduling, or the context is done.
+func (t *FairThrottler) Throttle(ctx context.Context) {
+	start := time.Now()
+
+	w, depth := t.enqueue(t.flowOf(ctx))
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("throttling_queue_depth", depth))
+
+	select {
+	case <-ctx.Done():
+		t.cancel(w)
+	case <-w.release:
+	}
+
+	rpcInfo := telemetry.RPCInfoFromContext(ctx)
+	throttlingDelayMsHistogram.WithLabelValues(
+		rpcInfo.Service,
+		rpcInfo.Method,
+		t.name,
+	).Observe(float64(time.Since(start).Milliseconds()))
+}
+
+// Waiting returns the number of dispatches waiting to be released.
+func (t *FairThrottler) Waiting() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.waiting.Len()
+}
+
+func (t *FairThrottler) Close() {
+	close(t.done)
+	t.wg.Wait()
+	t.ticker.Stop()
+
+	// release the dispatches that are still waiting
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for t.waiting.Len() > 0 {
+		w := heap.Pop(&t.waiting).(*waiter)
+		t.dequeued(w)
+		close(w.release)
+	}
+}
+
+func (t *FairThrottler) flowOf(ctx context.Context) flowKey {
+	var key flowKey
+	key.storeID, _ = storeid.StoreIDFromContext(ctx)
+	if t.perClient {
+		if claims, ok := authclaims.AuthClaimsFromContext(ctx); ok {
+			key.clientID = claims.ClientID
+		}
+	}
+	return key
+}
+
+// enqueue adds a waiting dispatch to the flow, finishing 1/weight after the last dispatch of the flow or after the
+// current virtual time, whichever is later. It returns the waiter and the depth of the flow.
+func (t *FairThrottler) enqueue(key flowKey) (*waiter, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	f, ok := t.flows[key]
+	if !ok {
+		f = &flow{}
+		t.flows[key] = f
+	}
+
+	weight, ok := t.weights[key.storeID]
+	if !ok || weight <= 0 {
+		weight = t.defaultWeight
+	}
+	f.finish = math.Max(f.finish, t.virtualTime) + 1/weight
+	f.depth++
+
+	t.seq++
+	w := &waiter{
+		flow:    key,
+		finish:  f.finish,
+		seq:     t.seq,
+		release: make(chan struct{}),
+	}
+	heap.Push(&t.waiting, w)
+	throttlingQueueDepthGauge.WithLabelValues(t.name).Inc()
+	throttlingStoreQueueDepthGauge.WithLabelValues(t.name, t.storeLabel(key.storeID)).Inc()
+	return w, f.depth
+}
+
+// cancel removes a dispatch whose context is done, unless it was released meanwhile.
+func (t *FairThrottler) cancel(w *waiter) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if w.index < 0 {
+		return
+	}
+	heap.Remove(&t.waiting, w.index)
+	t.dequeued(w)
+}
+
+// dequeued accounts for a dispatch that left the queue. The flows without waiting dispatches are forgotten once
+// the virtual time caught up with them, since they would start from the virtual time anyway.
+func (t *FairThrottler) dequeued(w *waiter) {
+	throttlingQueueDepthGauge.WithLabelValues(t.name).Dec()
+	throttlingStoreQueueDepthGauge.WithLabelValues(t.name, t.storeLabel(w.flow.storeID)).Dec()
+	f := t.flows[w.flow]
+	f.depth--
+	for key, f := range t.flows {
+		if f.depth == 0 && f.finish <= t.virtualTime {
+			delete(t.flows, key)
+		}
+	}
+}
+
+// storeLabel returns the store ID label of the queue depth of the store, which is bounded by the configured weights.
+func (t *FairThrottler) storeLabel(storeID string) string {
+	if _, ok := t.weights[storeID]; ok {
+		return storeID
+	}
+	return otherStoresLabel
+}
+
+// waiterHeap orders the waiting dispatches by virtual finish time, then by arrival.
+type waiterHeap []*waiter
+
+func (h waiterHeap) Len() int { return len(h) }
+
+func (h waiterHeap) Less(i, j int) bool {
+	if h[i].finish != h[j].finish {
+		return h[i].finish < h[j].finish
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h waiterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *waiterHeap) Push(x any) {
+	w := x.(*waiter)
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *waiterHeap) Pop() any {
+	old := *h
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.index = -1
+	*h = old[:n-1]
+	return w
+}
@@ -4,6 +4,7 @@ package throttler
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -30,6 +31,14 @@ type Throttler interface {
 	Throttle(context.Context)
 }
 
+// Waiting returns the number of dispatches waiting in the throttler, or 0 if it can't tell.
+func Waiting(t Throttler) int {
+	if w, ok := t.(interface{ Waiting() int }); ok {
+		return w.Waiting()
+	}
+	return 0
+}
+
 type noopThrottler struct{}
 
 var _ Throttler = (*noopThrottler)(nil)
@@ -49,6 +58,7 @@ type constantRateThrottler struct {
 	ticker          *time.Ticker
 	throttlingQueue chan struct{}
 	done            chan struct{}
+	waiting         atomic.Int64
 }
 
 // NewConstantRateThrottler constructs a constantRateThrottler which can be used to control the rate of recursive resource consumption.
@@ -88,6 +98,11 @@ func (r *constantRateThrottler) runTicker() {
 	}
 }
 
+// Waiting returns the number of dispatches waiting in the throttler.
+func (r *constantRateThrottler) Waiting() int {
+	return int(r.waiting.Load())
+}
+
 func (r *constantRateThrottler) Close() {
 	r.done <- struct{}{}
 	r.ticker.Stop()
@@ -100,10 +115,12 @@ func (r *constantRateThrottler) Close() {
 // which is produced by periodically sending a value on the channel based on the configured ticker frequency.
 func (r *constantRateThrottler) Throttle(ctx context.Context) {
 	start := time.Now()
+	r.waiting.Add(1)
 	select {
 	case <-ctx.Done():
 	case <-r.throttlingQueue:
 	}
+	r.waiting.Add(-1)
 	end := time.Now()
 	timeWaiting := end.Sub(start).Milliseconds()
 
@@ -6,8 +6,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
+
+	"github.com/openfga/openfga/pkg/testutils"
 )
 
 func TestConstantRateThrottler(t *testing.T) {
@@ -33,8 +36,114 @@ func TestConstantRateThrottler(t *testing.T) {
 
 		time.Sleep(100 * time.Millisecond) // Wait for the goroutine to attempt to throttle
 		require.Equal(t, 0, counter)
+		require.Equal(t, 1, Waiting(testThrottler))
 		testThrottler.throttlingQueue <- struct{}{}
 		wg.Wait()
 		require.Equal(t, 1, counter)
 	})
 }
+
+func TestFairThrottler(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	t.Run("releases_the_stores_in_proportion_to_their_weight", func(t *testing.T) {
+		testThrottler := NewFairThrottler(1*time.Hour, "weighted", WithStoreWeights(map[string]float64{"heavy": 2}))
+		t.Cleanup(testThrottler.Close)
+
+		var (
+			mu       sync.Mutex
+			released []string
+			wg       sync.WaitGroup
+		)
+		throttle := func(storeID string, depth int) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				testThrottler.Throttle(testutils.ContextWithStoreID(t, storeID))
+				mu.Lock()
+				released = append(released, storeID)
+				mu.Unlock()
+			}()
+			require.Eventually(t, func() bool {
+				return testThrottler.queueDepth(storeID) == depth
+			}, time.Second, time.Millisecond)
+		}
+
+		// the noisy store throttles first, the other stores are released ahead of its backlog
+		throttle("noisy", 1)
+		throttle("noisy", 2)
+		throttle("noisy", 3)
+		throttle("quiet", 1)
+		throttle("heavy", 1)
+		throttle("heavy", 2)
+
+		// only the stores with a weight have their own queue depth
+		require.InDelta(t, 2, testutil.ToFloat64(throttlingStoreQueueDepthGauge.WithLabelValues("weighted", "heavy")), 0)
+		require.InDelta(t, 4, testutil.ToFloat64(throttlingStoreQueueDepthGauge.WithLabelValues("weighted", otherStoresLabel)), 0)
+
+		for i := 0; i < 6; i++ {
+			testThrottler.releaseNext()
+			require.Eventually(t, func() bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return len(released) == i+1
+			}, time.Second, time.Millisecond)
+		}
+		wg.Wait()
+
+		require.Equal(t, []string{"heavy", "noisy", "quiet", "heavy", "noisy", "noisy"}, released)
+		require.Zero(t, testThrottler.queueDepth("noisy"))
+		require.Zero(t, testutil.ToFloat64(throttlingStoreQueueDepthGauge.WithLabelValues("weighted", otherStoresLabel)))
+	})
+
+	t.Run("throttle_returns_when_the_context_is_done", func(t *testing.T) {
+		testThrottler := NewFairThrottler(1*time.Hour, "test")
+		t.Cleanup(testThrottler.Close)
+
+		ctx, cancel := context.WithCancel(testutils.ContextWithStoreID(t, "store"))
+		done := make(chan struct{})
+		go func() {
+			testThrottler.Throttle(ctx)
+			close(done)
+		}()
+		require.Eventually(t, func() bool {
+			return testThrottler.queueDepth("store") == 1
+		}, time.Second, time.Millisecond)
+
+		cancel()
+		<-done
+		require.Zero(t, testThrottler.queueDepth("store"))
+	})
+
+	t.Run("close_releases_the_waiting_dispatches", func(t *testing.T) {
+		testThrottler := NewFairThrottler(1*time.Hour, "test")
+
+		done := make(chan struct{})
+		go func() {
+			testThrottler.Throttle(context.Background())
+			close(done)
+		}()
+		require.Eventually(t, func() bool {
+			return testThrottler.queueDepth("") == 1
+		}, time.Second, time.Millisecond)
+		require.Equal(t, 1, Waiting(testThrottler))
+
+		testThrottler.Close()
+		<-done
+	})
+}
+
+// queueDepth returns the number of dispatches of the store waiting to be released.
+func (t *FairThrottler) queueDepth(storeID string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	depth := 0
+	for key, f := range t.flows {
+		if key.storeID == storeID {
+			depth += f.depth
+		}
+	}
+	return depth
+}
@@ -18,6 +18,7 @@ const (
 	StreamedListObjects     APIMethod = "StreamedListObjects"
 	Check                   APIMethod = "Check"
 	BatchCheck              APIMethod = "BatchCheck"
+	StreamedBatchCheck      APIMethod = "StreamedBatchCheck"
 	ListUsers               APIMethod = "ListUsers"
 	WriteAssertions         APIMethod = "WriteAssertions"
 	ReadAssertions          APIMethod = "ReadAssertions"
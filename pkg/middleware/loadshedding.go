@@ -0,0 +1,385 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/authclaims"
+)
+
+// Priority is the lane of a request in the LoadSheddingInterceptor. The requests of the lower lanes are shed
+// first.
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityNormal
+	PriorityHigh
+)
+
+const (
+	// PriorityHeader is the header with which a client lowers the priority of its request, e.g. for bulk jobs.
+	// It can't raise it above the priority of the method and the client.
+	PriorityHeader = "Openfga-Priority"
+
+	// RetryAfterHeader is the header with the number of seconds after which a shed request can be retried.
+	RetryAfterHeader = "Retry-After"
+
+	// DefaultLoadSheddingLowPriorityThreshold is the pressure above which the low priority requests are shed.
+	DefaultLoadSheddingLowPriorityThreshold = 0.8
+	// DefaultLoadSheddingRetryAfter is the delay after which shed requests are told to retry.
+	DefaultLoadSheddingRetryAfter = time.Second
+
+	// latencyWindowSize is the number of the latest high priority requests whose latency is tracked.
+	latencyWindowSize = 1000
+	// latencyWindowDuration is how long the latency of a high priority request is tracked, so that the p99 latency
+	// reflects the recent requests when there are few of them.
+	latencyWindowDuration = 10 * time.Second
+	// latencyRefreshInterval is how often the p99 latency is computed from the window.
+	latencyRefreshInterval = 100 * time.Millisecond
+)
+
+var (
+	loadSheddingRejectedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "load_shedding_rejected_count",
+		Help:      "The total number of requests rejected by load shedding, labeled by grpc_method and priority.",
+	}, []string{"grpc_method", "priority"})
+
+	loadSheddingPressureGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: build.ProjectName,
+		Name:      "load_shedding_pressure",
+		Help:      "The pressure on the server as last seen by load shedding, where 1 is the configured capacity.",
+	})
+)
+
+// DefaultMethodPriorities are the lanes of the API methods: the latency critical checks are high priority, and the
+// bulk reads and listings are low priority. The other methods are normal priority.
+var DefaultMethodPriorities = map[string]Priority{
+	"Check":              PriorityHigh,
+	"BatchCheck":         PriorityHigh,
+	"StreamedBatchCheck": PriorityHigh,
+	"ListRelations":      PriorityHigh,
+	// the peers dispatch the sub-problems of high priority checks
+	"DispatchCheck": PriorityHigh,
+
+	"ListObjects":          PriorityLow,
+	"StreamedListObjects":  PriorityLow,
+	"PaginatedListObjects": PriorityLow,
+	"CountObjects":         PriorityLow,
+	"ListUsers":            PriorityLow,
+	"StreamedListUsers":    PriorityLow,
+	"PaginatedListUsers":   PriorityLow,
+	"CountUsers":           PriorityLow,
+	"Read":                 PriorityLow,
+	"ReadChanges":          PriorityLow,
+	"Expand":               PriorityLow,
+	"Subscribe":            PriorityLow,
+}
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	default:
+		return strconv.Itoa(int(p))
+	}
+}
+
+// ParsePriority parses a priority named 'low', 'normal' or 'high'.
+func ParsePriority(name string) (Priority, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "low":
+		return PriorityLow, nil
+	case "normal":
+		return PriorityNormal, nil
+	case "high":
+		return PriorityHigh, nil
+	default:
+		return 0, fmt.Errorf("invalid priority '%s', it must be 'low', 'normal' or 'high'", name)
+	}
+}
+
+// LoadSheddingInterceptor rejects requests with RESOURCE_EXHAUSTED when the server is under pressure, starting with
+// the low priority ones, so that the high priority requests aren't affected.
+//
+// The pressure is the highest ratio of the number of requests in flight, the p99 latency of the high priority
+// requests and the number of throttled dispatches to their configured capacity. The low priority requests are shed
+// once it reaches the low priority threshold, and the normal priority requests once it reaches 1. The high priority
+// requests are never shed.
+//
+// A request gets the priority of its method (see DefaultMethodPriorities), or of its client if it has one, and can
+// lower it with the PriorityHeader. The interceptor must come after the authentication to know the client.
+type LoadSheddingInterceptor struct {
+	methodPriorities     map[string]Priority
+	clientPriorities     map[string]Priority
+	maxInflight          int64
+	latencyTarget        time.Duration
+	throttledDispatches  func() int
+	maxThrottled         int
+	lowPriorityThreshold float64
+	retryAfter           time.Duration
+
+	inflight atomic.Int64
+
+	mu        sync.Mutex
+	latencies []latencySample
+	next      int
+	p99       time.Duration
+	computed  time.Time
+}
+
+type latencySample struct {
+	latency  time.Duration
+	observed time.Time
+}
+
+type LoadSheddingOption func(*LoadSheddingInterceptor)
+
+// WithMethodPriorities overrides the priority of specific methods, by method name, e.g. 'ListObjects'.
+func WithMethodPriorities(priorities map[string]Priority) LoadSheddingOption {
+	return func(l *LoadSheddingInterceptor) {
+		for method, priority := range priorities {
+			l.methodPriorities[method] = priority
+		}
+	}
+}
+
+// WithClientPriorities sets the priority of the requests of specific clients, by the client ID of their
+// authentication, whatever their method.
+func WithClientPriorities(priorities map[string]Priority) LoadSheddingOption {
+	return func(l *LoadSheddingInterceptor) {
+		l.clientPriorities = priorities
+	}
+}
+
+// WithMaxInflight sets the number of requests in flight at which the server is at capacity. Zero ignores the
+// requests in flight.
+func WithMaxInflight(maxInflight int) LoadSheddingOption {
+	return func(l *LoadSheddingInterceptor) {
+		l.maxInflight = int64(maxInflight)
+	}
+}
+
+// WithLatencyTarget sets the p99 latency of the high priority requests at which the server is at capacity. Zero
+// ignores the latency.
+func WithLatencyTarget(target time.Duration) LoadSheddingOption {
+	return func(l *LoadSheddingInterceptor) {
+		l.latencyTarget = target
+	}
+}
+
+// WithThrottledDispatches sets the function that returns the number of dispatches waiting in the dispatch
+// throttlers, and the number at which the server is at capacity. A zero maximum ignores the throttled dispatches.
+func WithThrottledDispatches(throttledDispatches func() int, maxThrottled int) LoadSheddingOption {
+	return func(l *LoadSheddingInterceptor) {
+		l.throttledDispatches = throttledDispatches
+		l.maxThrottled = maxThrottled
+	}
+}
+
+// WithLowPriorityThreshold sets the pressure, between 0 and 1, above which the low priority requests are shed.
+func WithLowPriorityThreshold(threshold float64) LoadSheddingOption {
+	return func(l *LoadSheddingInterceptor) {
+		l.lowPriorityThreshold = threshold
+	}
+}
+
+// WithRetryAfter sets the delay after which the shed requests are told to retry.
+func WithRetryAfter(retryAfter time.Duration) LoadSheddingOption {
+	return func(l *LoadSheddingInterceptor) {
+		l.retryAfter = retryAfter
+	}
+}
+
+// NewLoadSheddingInterceptor returns a new LoadSheddingInterceptor.
+func NewLoadSheddingInterceptor(opts ...LoadSheddingOption) *LoadSheddingInterceptor {
+	l := &LoadSheddingInterceptor{
+		methodPriorities:     make(map[string]Priority, len(DefaultMethodPriorities)),
+		lowPriorityThreshold: DefaultLoadSheddingLowPriorityThreshold,
+		retryAfter:           DefaultLoadSheddingRetryAfter,
+		latencies:            make([]latencySample, 0, latencyWindowSize),
+	}
+	for method, priority := range DefaultMethodPriorities {
+		l.methodPriorities[method] = priority
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// NewUnaryLoadSheddingInterceptor returns an interceptor that sheds the unary requests.
+func (l *LoadSheddingInterceptor) NewUnaryLoadSheddingInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		method := methodName(info.FullMethod)
+		priority := l.Classify(ctx, method)
+		if err := l.admit(ctx, method, priority); err != nil {
+			_ = grpc.SetHeader(ctx, l.retryAfterHeader())
+			return nil, err
+		}
+
+		l.inflight.Add(1)
+		defer l.inflight.Add(-1)
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		if priority == PriorityHigh {
+			l.observeLatency(time.Since(start))
+		}
+		return resp, err
+	}
+}
+
+// NewStreamLoadSheddingInterceptor returns an interceptor that sheds the streaming requests. The latency of the
+// streams isn't tracked, since it depends on how long the clients keep them open.
+func (l *LoadSheddingInterceptor) NewStreamLoadSheddingInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := stream.Context()
+		method := methodName(info.FullMethod)
+		if err := l.admit(ctx, method, l.Classify(ctx, method)); err != nil {
+			_ = stream.SetHeader(l.retryAfterHeader())
+			return err
+		}
+
+		l.inflight.Add(1)
+		defer l.inflight.Add(-1)
+		return handler(srv, stream)
+	}
+}
+
+// Classify returns the priority lane of a request to the method.
+func (l *LoadSheddingInterceptor) Classify(ctx context.Context, method string) Priority {
+	priority, ok := l.methodPriorities[method]
+	if !ok {
+		priority = PriorityNormal
+	}
+
+	if claims, ok := authclaims.AuthClaimsFromContext(ctx); ok && claims.ClientID != "" {
+		if clientPriority, ok := l.clientPriorities[claims.ClientID]; ok {
+			priority = clientPriority
+		}
+	}
+
+	if values := metadata.ValueFromIncomingContext(ctx, PriorityHeader); len(values) > 0 {
+		if requested, err := ParsePriority(values[0]); err == nil && requested < priority {
+			priority = requested
+		}
+	}
+	return priority
+}
+
+// Pressure returns the pressure on the server, where 1 is the configured capacity.
+func (l *LoadSheddingInterceptor) Pressure() float64 {
+	var pressure float64
+	if l.maxInflight > 0 {
+		pressure = math.Max(pressure, float64(l.inflight.Load())/float64(l.maxInflight))
+	}
+	if l.latencyTarget > 0 {
+		pressure = math.Max(pressure, float64(l.latencyP99())/float64(l.latencyTarget))
+	}
+	if l.maxThrottled > 0 && l.throttledDispatches != nil {
+		pressure = math.Max(pressure, float64(l.throttledDispatches())/float64(l.maxThrottled))
+	}
+	return pressure
+}
+
+func (l *LoadSheddingInterceptor) admit(ctx context.Context, method string, priority Priority) error {
+	grpc_ctxtags.Extract(ctx).Set("request.priority", priority.String())
+	if priority == PriorityHigh {
+		return nil
+	}
+
+	pressure := l.Pressure()
+	loadSheddingPressureGauge.Set(pressure)
+
+	threshold := 1.0
+	if priority == PriorityLow {
+		threshold = l.lowPriorityThreshold
+	}
+	if pressure < threshold {
+		return nil
+	}
+
+	loadSheddingRejectedCounter.WithLabelValues(method, priority.String()).Inc()
+	st, err := status.New(codes.ResourceExhausted,
+		fmt.Sprintf("the server is overloaded, %s priority requests are rejected, retry after %s", priority, l.retryAfter),
+	).WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(l.retryAfter)})
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, "the server is overloaded")
+	}
+	return st.Err()
+}
+
+func (l *LoadSheddingInterceptor) retryAfterHeader() metadata.MD {
+	seconds := int(math.Ceil(l.retryAfter.Seconds()))
+	return metadata.Pairs(RetryAfterHeader, strconv.Itoa(seconds))
+}
+
+// observeLatency adds the latency of a high priority request to the window.
+func (l *LoadSheddingInterceptor) observeLatency(latency time.Duration) {
+	sample := latencySample{latency: latency, observed: time.Now()}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.latencies) < latencyWindowSize {
+		l.latencies = append(l.latencies, sample)
+		return
+	}
+	l.latencies[l.next] = sample
+	l.next = (l.next + 1) % latencyWindowSize
+}
+
+// latencyP99 returns the p99 latency of the window, computed again at most every latencyRefreshInterval. The
+// latencies observed more than latencyWindowDuration ago are ignored, and the p99 latency is zero without any.
+func (l *LoadSheddingInterceptor) latencyP99() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if len(l.latencies) == 0 || now.Sub(l.computed) < latencyRefreshInterval {
+		return l.p99
+	}
+	l.computed = now
+
+	recent := make([]time.Duration, 0, len(l.latencies))
+	for _, sample := range l.latencies {
+		if now.Sub(sample.observed) < latencyWindowDuration {
+			recent = append(recent, sample.latency)
+		}
+	}
+	if len(recent) == 0 {
+		l.p99 = 0
+		return l.p99
+	}
+
+	slices.Sort(recent)
+	l.p99 = recent[int(math.Ceil(0.99*float64(len(recent))))-1]
+	return l.p99
+}
+
+// methodName returns the name of the method of a full gRPC method, e.g. 'Check' for '/openfga.v1.OpenFGAService/Check'.
+func methodName(fullMethod string) string {
+	return fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+}
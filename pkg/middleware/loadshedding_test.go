@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/pkg/authclaims"
+)
+
+func TestLoadSheddingClassify(t *testing.T) {
+	l := NewLoadSheddingInterceptor(
+		WithMethodPriorities(map[string]Priority{"Read": PriorityNormal}),
+		WithClientPriorities(map[string]Priority{"batch": PriorityLow}),
+	)
+
+	ctx := context.Background()
+	require.Equal(t, PriorityHigh, l.Classify(ctx, "Check"))
+	require.Equal(t, PriorityLow, l.Classify(ctx, "ListObjects"))
+	require.Equal(t, PriorityNormal, l.Classify(ctx, "Read"))
+	require.Equal(t, PriorityNormal, l.Classify(ctx, "Write"))
+
+	t.Run("client", func(t *testing.T) {
+		ctx := authclaims.ContextWithAuthClaims(context.Background(), &authclaims.AuthClaims{ClientID: "batch"})
+		require.Equal(t, PriorityLow, l.Classify(ctx, "Check"))
+	})
+
+	t.Run("header_lowers_the_priority", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(PriorityHeader, "low"))
+		require.Equal(t, PriorityLow, l.Classify(ctx, "Check"))
+	})
+
+	t.Run("header_does_not_raise_the_priority", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(PriorityHeader, "high"))
+		require.Equal(t, PriorityLow, l.Classify(ctx, "ListObjects"))
+	})
+}
+
+func TestNewUnaryLoadSheddingInterceptor(t *testing.T) {
+	handler := func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	}
+	call := func(l *LoadSheddingInterceptor, method string) error {
+		_, err := l.NewUnaryLoadSheddingInterceptor()(context.Background(), nil,
+			&grpc.UnaryServerInfo{FullMethod: "/openfga.v1.OpenFGAService/" + method}, handler)
+		return err
+	}
+
+	t.Run("sheds_low_priority_requests_first", func(t *testing.T) {
+		l := NewLoadSheddingInterceptor(WithMaxInflight(10), WithRetryAfter(2*time.Second))
+
+		l.inflight.Store(7)
+		require.NoError(t, call(l, "ListObjects"))
+
+		l.inflight.Store(9)
+		err := call(l, "ListObjects")
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, codes.ResourceExhausted, st.Code())
+		require.Len(t, st.Details(), 1)
+		retryInfo, ok := st.Details()[0].(*errdetails.RetryInfo)
+		require.True(t, ok)
+		require.Equal(t, 2*time.Second, retryInfo.GetRetryDelay().AsDuration())
+
+		require.NoError(t, call(l, "Write"))
+		require.NoError(t, call(l, "Check"))
+
+		l.inflight.Store(10)
+		require.Equal(t, codes.ResourceExhausted, status.Code(call(l, "Write")))
+		require.NoError(t, call(l, "Check"))
+	})
+
+	t.Run("throttled_dispatches", func(t *testing.T) {
+		throttled := 0
+		l := NewLoadSheddingInterceptor(WithThrottledDispatches(func() int { return throttled }, 100))
+
+		require.NoError(t, call(l, "ListUsers"))
+
+		throttled = 100
+		require.Equal(t, codes.ResourceExhausted, status.Code(call(l, "ListUsers")))
+		require.NoError(t, call(l, "Check"))
+	})
+
+	t.Run("latency_of_high_priority_requests", func(t *testing.T) {
+		l := NewLoadSheddingInterceptor(WithLatencyTarget(10 * time.Millisecond))
+
+		require.NoError(t, call(l, "ReadChanges"))
+
+		for i := 0; i < 100; i++ {
+			l.observeLatency(20 * time.Millisecond)
+		}
+		require.InDelta(t, 2, l.Pressure(), 0.01)
+		require.Equal(t, codes.ResourceExhausted, status.Code(call(l, "ReadChanges")))
+
+		t.Run("expire_after_the_window_duration", func(t *testing.T) {
+			l.mu.Lock()
+			for i := range l.latencies {
+				l.latencies[i].observed = l.latencies[i].observed.Add(-latencyWindowDuration)
+			}
+			l.computed = time.Time{}
+			l.mu.Unlock()
+
+			require.Zero(t, l.Pressure())
+			require.NoError(t, call(l, "ReadChanges"))
+		})
+	})
+}
+
+func TestNewStreamLoadSheddingInterceptor(t *testing.T) {
+	l := NewLoadSheddingInterceptor(WithMaxInflight(1))
+	l.inflight.Store(1)
+
+	interceptor := l.NewStreamLoadSheddingInterceptor()
+	handler := func(srv any, stream grpc.ServerStream) error {
+		return nil
+	}
+
+	stream := mockServerGRPCStream{ctx: context.Background()}
+	err := interceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: "/openfga.v1.OpenFGAService/StreamedListObjects"}, handler)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	err = interceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: "/openfga.v1.OpenFGAService/StreamedBatchCheck"}, handler)
+	require.NoError(t, err)
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/internal/activemodel"
+	"github.com/openfga/openfga/internal/utils/apimethod"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/telemetry"
+)
+
+var _ activemodel.ActiveModelServer = (*Server)(nil)
+
+// SetActiveAuthorizationModel pins an authorization model as the active model of a store, so that the requests
+// that do not specify a model ID use it instead of the latest model. An empty model ID unpins the active model.
+// When passing assertions are required, the model is only pinned if all of its assertions pass, see RunAssertions.
+// It is authorized like a WriteAuthorizationModel.
+func (s *Server) SetActiveAuthorizationModel(ctx context.Context, req *activemodel.SetActiveAuthorizationModelRequest) (*activemodel.SetActiveAuthorizationModelResponse, error) {
+	ctx, span := tracer.Start(ctx, "SetActiveAuthorizationModel", trace.WithAttributes(
+		attribute.String("store_id", req.StoreID),
+		attribute.String("authorization_model_id", req.AuthorizationModelID),
+	))
+	defer span.End()
+
+	if req.StoreID == "" {
+		return nil, status.Error(codes.InvalidArgument, "store_id is required")
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: activemodel.ServiceName,
+		Method:  "SetActiveAuthorizationModel",
+	})
+
+	if err := s.checkAuthz(ctx, req.StoreID, apimethod.WriteAuthorizationModel); err != nil {
+		return nil, err
+	}
+
+	if req.AuthorizationModelID != "" {
+		// only the models that exist and are valid can serve the requests
+		if _, err := s.resolveTypesystem(ctx, req.StoreID, req.AuthorizationModelID); err != nil {
+			return nil, err
+		}
+
+		if req.RequirePassingAssertions {
+			res, err := s.runAssertions(ctx, req.StoreID, req.AuthorizationModelID, "")
+			if err != nil {
+				return nil, err
+			}
+			if len(res.Results) == 0 {
+				return nil, status.Errorf(codes.FailedPrecondition,
+					"the authorization model '%s' has no assertions", req.AuthorizationModelID)
+			}
+			if res.Failed > 0 {
+				return nil, status.Errorf(codes.FailedPrecondition,
+					"%d of %d assertions of the authorization model '%s' failed", res.Failed, len(res.Results), req.AuthorizationModelID)
+			}
+		}
+	}
+
+	previousModelID, err := s.datastore.ReadActiveAuthorizationModelID(ctx, req.StoreID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		telemetry.TraceError(span, err)
+		return nil, serverErrors.HandleError("", err)
+	}
+
+	if err := s.datastore.WriteActiveAuthorizationModelID(ctx, req.StoreID, req.AuthorizationModelID); err != nil {
+		telemetry.TraceError(span, err)
+		return nil, serverErrors.HandleError("", err)
+	}
+	// the other servers serve the previous active model until their cached ID expires
+	s.memoizedTypesystem.InvalidateActiveModelID(req.StoreID)
+
+	return &activemodel.SetActiveAuthorizationModelResponse{
+		PreviousAuthorizationModelID: previousModelID,
+	}, nil
+}
+
+// GetActiveAuthorizationModel returns the ID of the model used by the requests of a store that do not specify a
+// model ID, and whether it is pinned. It is authorized like a ReadAuthorizationModel.
+func (s *Server) GetActiveAuthorizationModel(ctx context.Context, req *activemodel.GetActiveAuthorizationModelRequest) (*activemodel.GetActiveAuthorizationModelResponse, error) {
+	ctx, span := tracer.Start(ctx, "GetActiveAuthorizationModel", trace.WithAttributes(
+		attribute.String("store_id", req.StoreID),
+	))
+	defer span.End()
+
+	if req.StoreID == "" {
+		return nil, status.Error(codes.InvalidArgument, "store_id is required")
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: activemodel.ServiceName,
+		Method:  "GetActiveAuthorizationModel",
+	})
+
+	if err := s.checkAuthz(ctx, req.StoreID, apimethod.ReadAuthorizationModel); err != nil {
+		return nil, err
+	}
+
+	pinned := true
+	if _, err := s.datastore.ReadActiveAuthorizationModelID(ctx, req.StoreID); err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			telemetry.TraceError(span, err)
+			return nil, serverErrors.HandleError("", err)
+		}
+		pinned = false
+	}
+
+	typesys, err := s.resolveTypesystem(ctx, req.StoreID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &activemodel.GetActiveAuthorizationModelResponse{
+		AuthorizationModelID: typesys.GetAuthorizationModelID(),
+		Pinned:               pinned,
+	}, nil
+}
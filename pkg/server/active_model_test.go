@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/activemodel"
+	"github.com/openfga/openfga/internal/jsongrpc"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestActiveAuthorizationModel(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(WithDatastore(ds))
+	t.Cleanup(s.Close)
+
+	conn := newJSONGRPCTestConn(t, s, &activemodel.ServiceDesc)
+
+	ctx := context.Background()
+	createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "openfga-test"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	writeModel := func(t *testing.T, dsl string) string {
+		model := testutils.MustTransformDSLToProtoWithID(dsl)
+		resp, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+			StoreId:         storeID,
+			SchemaVersion:   model.GetSchemaVersion(),
+			TypeDefinitions: model.GetTypeDefinitions(),
+		})
+		require.NoError(t, err)
+		return resp.GetAuthorizationModelId()
+	}
+
+	// the viewer tuple grants access in the first model only
+	firstModelID := writeModel(t, `
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define viewer: [user]`)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		}},
+	})
+	require.NoError(t, err)
+
+	secondModelID := writeModel(t, `
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define editor: [user]
+				define viewer: editor`)
+
+	check := func(t *testing.T) bool {
+		resp, err := s.Check(ctx, &openfgav1.CheckRequest{
+			StoreId:  storeID,
+			TupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:anne"),
+		})
+		require.NoError(t, err)
+		return resp.GetAllowed()
+	}
+
+	getActive := func(t *testing.T) *activemodel.GetActiveAuthorizationModelResponse {
+		resp, err := activemodel.GetActiveAuthorizationModel(ctx, conn, &activemodel.GetActiveAuthorizationModelRequest{StoreRequest: jsongrpc.StoreRequest{StoreID: storeID}})
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("latest_model_is_active_by_default", func(t *testing.T) {
+		require.Equal(t, &activemodel.GetActiveAuthorizationModelResponse{AuthorizationModelID: secondModelID}, getActive(t))
+		require.False(t, check(t))
+	})
+
+	t.Run("pin_and_rollback", func(t *testing.T) {
+		resp, err := activemodel.SetActiveAuthorizationModel(ctx, conn, &activemodel.SetActiveAuthorizationModelRequest{
+			StoreRequest:         jsongrpc.StoreRequest{StoreID: storeID},
+			AuthorizationModelID: firstModelID,
+		})
+		require.NoError(t, err)
+		require.Empty(t, resp.PreviousAuthorizationModelID)
+
+		require.Equal(t, &activemodel.GetActiveAuthorizationModelResponse{AuthorizationModelID: firstModelID, Pinned: true}, getActive(t))
+		require.True(t, check(t))
+
+		// writing a new model does not change the active model
+		thirdModelID := writeModel(t, `
+			model
+				schema 1.1
+			type user
+			type document
+				relations
+					define owner: [user]
+					define viewer: owner`)
+		require.Equal(t, firstModelID, getActive(t).AuthorizationModelID)
+		require.True(t, check(t))
+
+		resp, err = activemodel.SetActiveAuthorizationModel(ctx, conn, &activemodel.SetActiveAuthorizationModelRequest{
+			StoreRequest:         jsongrpc.StoreRequest{StoreID: storeID},
+			AuthorizationModelID: thirdModelID,
+		})
+		require.NoError(t, err)
+		require.Equal(t, firstModelID, resp.PreviousAuthorizationModelID)
+		require.False(t, check(t))
+
+		// roll back to the previous model
+		_, err = activemodel.SetActiveAuthorizationModel(ctx, conn, &activemodel.SetActiveAuthorizationModelRequest{
+			StoreRequest:         jsongrpc.StoreRequest{StoreID: storeID},
+			AuthorizationModelID: resp.PreviousAuthorizationModelID,
+		})
+		require.NoError(t, err)
+		require.True(t, check(t))
+
+		// unpin to use the latest model again
+		resp, err = activemodel.SetActiveAuthorizationModel(ctx, conn, &activemodel.SetActiveAuthorizationModelRequest{StoreRequest: jsongrpc.StoreRequest{StoreID: storeID}})
+		require.NoError(t, err)
+		require.Equal(t, firstModelID, resp.PreviousAuthorizationModelID)
+		require.Equal(t, &activemodel.GetActiveAuthorizationModelResponse{AuthorizationModelID: thirdModelID}, getActive(t))
+		require.False(t, check(t))
+	})
+
+	t.Run("invalid_requests", func(t *testing.T) {
+		_, err := activemodel.SetActiveAuthorizationModel(ctx, conn, &activemodel.SetActiveAuthorizationModelRequest{})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = activemodel.GetActiveAuthorizationModel(ctx, conn, &activemodel.GetActiveAuthorizationModelRequest{})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = activemodel.SetActiveAuthorizationModel(ctx, conn, &activemodel.SetActiveAuthorizationModelRequest{
+			StoreRequest:         jsongrpc.StoreRequest{StoreID: storeID},
+			AuthorizationModelID: "01JBVMPYB8Q4G2NCC8Z2RMMA5A",
+		})
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_authorization_model_not_found), status.Code(err))
+	})
+}
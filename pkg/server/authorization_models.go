@@ -68,10 +68,15 @@ func (s *Server) WriteAuthorizationModel(ctx context.Context, req *openfgav1.Wri
 		return nil, err
 	}
 
-	c := commands.NewWriteAuthorizationModelCommand(s.datastore,
+	opts := []commands.WriteAuthModelOption{
 		commands.WithWriteAuthModelLogger(s.logger),
 		commands.WithWriteAuthModelMaxSizeInBytes(s.maxAuthorizationModelSizeInBytes),
-	)
+	}
+	if s.authorizationModelOrphanedTuplesCheckEnabled {
+		opts = append(opts, commands.WithWriteAuthModelOrphanedTuplesThreshold(s.datastore, uint64(s.authorizationModelOrphanedTuplesCheckThreshold)))
+	}
+
+	c := commands.NewWriteAuthorizationModelCommand(s.datastore, opts...)
 	res, err := c.Execute(ctx, req)
 	if err != nil {
 		return nil, err
@@ -14,14 +14,18 @@ import (
 
 	"github.com/openfga/openfga/internal/condition"
 	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/internal/streamedbatchcheck"
 	"github.com/openfga/openfga/internal/utils/apimethod"
 	"github.com/openfga/openfga/pkg/middleware/validator"
 	"github.com/openfga/openfga/pkg/server/commands"
 	"github.com/openfga/openfga/pkg/server/config"
 	serverErrors "github.com/openfga/openfga/pkg/server/errors"
 	"github.com/openfga/openfga/pkg/telemetry"
+	"github.com/openfga/openfga/pkg/typesystem"
 )
 
+var _ streamedbatchcheck.StreamedBatchCheckServer = (*Server)(nil)
+
 func (s *Server) BatchCheck(ctx context.Context, req *openfgav1.BatchCheckRequest) (*openfgav1.BatchCheckResponse, error) {
 	ctx, span := tracer.Start(ctx, apimethod.BatchCheck.String(), trace.WithAttributes(
 		attribute.KeyValue{Key: "store_id", Value: attribute.StringValue(req.GetStoreId())},
@@ -53,14 +57,112 @@ func (s *Server) BatchCheck(ctx context.Context, req *openfgav1.BatchCheckReques
 	}
 	req.AuthorizationModelId = typesys.GetAuthorizationModelID() // the resolved model id
 
-	builder := s.getCheckResolverBuilder(req.GetStoreId())
-	checkResolver, checkResolverCloser, err := builder.Build()
+	checkResolver, checkResolverCloser, err := s.getCheckResolverBuilder(storeID).Build()
 	if err != nil {
 		return nil, err
 	}
 	defer checkResolverCloser()
 
-	cmd := commands.NewBatchCheckCommand(
+	cmd := s.newBatchCheckCommand(storeID, checkResolver, typesys)
+
+	result, metadata, err := cmd.Execute(ctx, &commands.BatchCheckCommandParams{
+		AuthorizationModelID: req.GetAuthorizationModelId(),
+		Checks:               req.GetChecks(),
+		Consistency:          req.GetConsistency(),
+		StoreID:              storeID,
+	})
+
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, batchCheckCommandErrorToServerError(err)
+	}
+
+	methodName := "batchcheck"
+	s.observeBatchCheckMetadata(ctx, span, methodName, metadata)
+
+	var batchResult = map[string]*openfgav1.BatchCheckSingleResult{}
+	for correlationID, outcome := range result {
+		batchResult[string(correlationID)] = transformCheckResultToProto(outcome)
+		s.emitCheckDurationMetric(outcome.CheckResponse.GetResolutionMetadata(), methodName)
+	}
+
+	return &openfgav1.BatchCheckResponse{Result: batchResult}, nil
+}
+
+// StreamedBatchCheck resolves the checks of a BatchCheckRequest like BatchCheck, but sends the result of
+// each check as soon as it is resolved, in a BatchCheckResponse of its own. The number of checks is not
+// limited: at most MaxConcurrentChecksPerBatchCheck are resolved concurrently, and a client that is slow to
+// receive the results holds back the resolution of the checks that are left.
+func (s *Server) StreamedBatchCheck(req *openfgav1.BatchCheckRequest, srv streamedbatchcheck.StreamServer) error {
+	ctx, span := tracer.Start(srv.Context(), apimethod.StreamedBatchCheck.String(), trace.WithAttributes(
+		attribute.KeyValue{Key: "store_id", Value: attribute.StringValue(req.GetStoreId())},
+		attribute.KeyValue{Key: "batch_size", Value: attribute.IntValue(len(req.GetChecks()))},
+		attribute.KeyValue{Key: "consistency", Value: attribute.StringValue(req.GetConsistency().String())},
+	))
+	defer span.End()
+
+	if !validator.RequestIsValidatedFromContext(ctx) {
+		if err := req.Validate(); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: streamedbatchcheck.ServiceName,
+		Method:  apimethod.StreamedBatchCheck.String(),
+	})
+
+	storeID := req.GetStoreId()
+	err := s.checkAuthz(ctx, storeID, apimethod.StreamedBatchCheck)
+	if err != nil {
+		return err
+	}
+
+	typesys, err := s.resolveTypesystem(ctx, storeID, req.GetAuthorizationModelId())
+	if err != nil {
+		return err
+	}
+
+	checkResolver, checkResolverCloser, err := s.getCheckResolverBuilder(storeID).Build()
+	if err != nil {
+		return err
+	}
+	defer checkResolverCloser()
+
+	cmd := s.newBatchCheckCommand(storeID, checkResolver, typesys)
+
+	methodName := "streamedbatchcheck"
+	metadata, err := cmd.ExecuteStreamed(ctx, &commands.BatchCheckCommandParams{
+		AuthorizationModelID: typesys.GetAuthorizationModelID(),
+		Checks:               req.GetChecks(),
+		Consistency:          req.GetConsistency(),
+		StoreID:              storeID,
+	}, func(correlationIDs []commands.CorrelationID, outcome *commands.BatchCheckOutcome) error {
+		// the client is gone, so is the stream
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		s.emitCheckDurationMetric(outcome.CheckResponse.GetResolutionMetadata(), methodName)
+
+		result := transformCheckResultToProto(outcome)
+		batchResult := make(map[string]*openfgav1.BatchCheckSingleResult, len(correlationIDs))
+		for _, id := range correlationIDs {
+			batchResult[string(id)] = result
+		}
+		return srv.Send(&openfgav1.BatchCheckResponse{Result: batchResult})
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return batchCheckCommandErrorToServerError(err)
+	}
+
+	s.observeBatchCheckMetadata(ctx, span, methodName, metadata)
+	return nil
+}
+
+func (s *Server) newBatchCheckCommand(storeID string, checkResolver graph.CheckResolver, typesys *typesystem.TypeSystem) *commands.BatchCheckQuery {
+	return commands.NewBatchCheckCommand(
 		s.datastore,
 		checkResolver,
 		typesys,
@@ -74,26 +176,19 @@ func (s *Server) BatchCheck(ctx context.Context, req *openfgav1.BatchCheckReques
 			s.checkDatastoreThrottleDuration,
 		),
 	)
+}
 
-	result, metadata, err := cmd.Execute(ctx, &commands.BatchCheckCommandParams{
-		AuthorizationModelID: req.GetAuthorizationModelId(),
-		Checks:               req.GetChecks(),
-		Consistency:          req.GetConsistency(),
-		StoreID:              storeID,
-	})
-
-	if err != nil {
-		telemetry.TraceError(span, err)
-		var batchValidationError *commands.BatchCheckValidationError
-		if errors.As(err, &batchValidationError) {
-			return nil, serverErrors.ValidationError(err)
-		}
-
-		return nil, err
+func batchCheckCommandErrorToServerError(err error) error {
+	var batchValidationError *commands.BatchCheckValidationError
+	if errors.As(err, &batchValidationError) {
+		return serverErrors.ValidationError(err)
 	}
 
-	methodName := "batchcheck"
+	return err
+}
 
+// observeBatchCheckMetadata reports the metadata of a resolved batch in the metrics, the span and the request tags.
+func (s *Server) observeBatchCheckMetadata(ctx context.Context, span trace.Span, methodName string, metadata *commands.BatchCheckMetadata) {
 	dispatchCount := float64(metadata.DispatchCount)
 	grpc_ctxtags.Extract(ctx).Set(dispatchCountHistogramName, dispatchCount)
 	span.SetAttributes(attribute.Float64(dispatchCountHistogramName, dispatchCount))
@@ -128,20 +223,17 @@ func (s *Server) BatchCheck(ctx context.Context, req *openfgav1.BatchCheckReques
 		methodName,
 	).Observe(datastoreItemCount)
 
+	dispatchesSaved := "dispatches_saved"
+	span.SetAttributes(attribute.Int64(dispatchesSaved, int64(metadata.DispatchesSaved)))
+	grpc_ctxtags.Extract(ctx).Set(dispatchesSaved, metadata.DispatchesSaved)
+	dispatchesSavedCounter.WithLabelValues(s.serviceName, methodName).Add(float64(metadata.DispatchesSaved))
+
 	duplicateChecks := "duplicate_checks"
 	span.SetAttributes(attribute.Int(duplicateChecks, metadata.DuplicateCheckCount))
 	grpc_ctxtags.Extract(ctx).Set(duplicateChecks, metadata.DuplicateCheckCount)
 
-	var batchResult = map[string]*openfgav1.BatchCheckSingleResult{}
-	for correlationID, outcome := range result {
-		batchResult[string(correlationID)] = transformCheckResultToProto(outcome)
-		s.emitCheckDurationMetric(outcome.CheckResponse.GetResolutionMetadata(), methodName)
-	}
-
 	grpc_ctxtags.Extract(ctx).Set(datastoreQueryCountHistogramName, metadata.DatastoreQueryCount)
 	grpc_ctxtags.Extract(ctx).Set(datastoreItemCountHistogramName, metadata.DatastoreItemCount)
-
-	return &openfgav1.BatchCheckResponse{Result: batchResult}, nil
 }
 
 // transformCheckResultToProto transforms the internal BatchCheckOutcome into the external-facing
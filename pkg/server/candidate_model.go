@@ -0,0 +1,238 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/candidatemodel"
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/internal/utils/apimethod"
+	"github.com/openfga/openfga/pkg/server/commands"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/telemetry"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+var _ candidatemodel.CandidateModelServer = (*Server)(nil)
+
+// SetCandidateAuthorizationModel sets the candidate model of a store. A sampled fraction of the Check and
+// ListObjects requests of the store are also evaluated against it in the background, and the requests whose outcome
+// it would change are recorded in its report. An empty model ID removes the candidate model. It is authorized like a
+// WriteAuthorizationModel.
+func (s *Server) SetCandidateAuthorizationModel(ctx context.Context, req *candidatemodel.SetCandidateAuthorizationModelRequest) (*candidatemodel.SetCandidateAuthorizationModelResponse, error) {
+	ctx, span := tracer.Start(ctx, "SetCandidateAuthorizationModel", trace.WithAttributes(
+		attribute.String("store_id", req.StoreID),
+		attribute.String("authorization_model_id", req.AuthorizationModelID),
+	))
+	defer span.End()
+
+	if req.StoreID == "" {
+		return nil, status.Error(codes.InvalidArgument, "store_id is required")
+	}
+	if req.SamplePercentage > 100 {
+		return nil, status.Error(codes.InvalidArgument, "sample_percentage must be between 0 and 100")
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: candidatemodel.ServiceName,
+		Method:  "SetCandidateAuthorizationModel",
+	})
+
+	if err := s.checkAuthz(ctx, req.StoreID, apimethod.WriteAuthorizationModel); err != nil {
+		return nil, err
+	}
+
+	if req.AuthorizationModelID == "" {
+		if err := s.candidateModels.Clear(ctx, req.StoreID); err != nil {
+			return nil, serverErrors.HandleError("", err)
+		}
+		return &candidatemodel.SetCandidateAuthorizationModelResponse{}, nil
+	}
+
+	if _, err := s.resolveTypesystem(ctx, req.StoreID, req.AuthorizationModelID); err != nil {
+		return nil, err
+	}
+
+	samplePercentage := int(req.SamplePercentage)
+	if samplePercentage == 0 {
+		samplePercentage = s.candidateModelSamplePercentage
+	}
+	if err := s.candidateModels.Set(ctx, req.StoreID, req.AuthorizationModelID, samplePercentage); err != nil {
+		return nil, serverErrors.HandleError("", err)
+	}
+
+	return &candidatemodel.SetCandidateAuthorizationModelResponse{}, nil
+}
+
+// GetCandidateAuthorizationModelReport returns the report of the candidate model of a store. The counts are the ones
+// of all the servers, the divergences the ones recorded by the server that serves the request. It is authorized like a ReadAuthorizationModel.
+func (s *Server) GetCandidateAuthorizationModelReport(ctx context.Context, req *candidatemodel.GetCandidateAuthorizationModelReportRequest) (*candidatemodel.GetCandidateAuthorizationModelReportResponse, error) {
+	ctx, span := tracer.Start(ctx, "GetCandidateAuthorizationModelReport", trace.WithAttributes(
+		attribute.String("store_id", req.StoreID),
+	))
+	defer span.End()
+
+	if req.StoreID == "" {
+		return nil, status.Error(codes.InvalidArgument, "store_id is required")
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: candidatemodel.ServiceName,
+		Method:  "GetCandidateAuthorizationModelReport",
+	})
+
+	if err := s.checkAuthz(ctx, req.StoreID, apimethod.ReadAuthorizationModel); err != nil {
+		return nil, err
+	}
+
+	report, err := s.candidateModels.Report(ctx, req.StoreID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "the store has no candidate authorization model")
+		}
+		return nil, serverErrors.HandleError("", err)
+	}
+
+	return &candidatemodel.GetCandidateAuthorizationModelReportResponse{Report: *report}, nil
+}
+
+// sampleCandidateModel returns the candidate model of the store if the request, resolved with typesys, is sampled to
+// also be evaluated against it.
+func (s *Server) sampleCandidateModel(ctx context.Context, storeID string, typesys *typesystem.TypeSystem) (*typesystem.TypeSystem, bool) {
+	modelID, ok := s.candidateModels.Sample(ctx, storeID)
+	if !ok || modelID == typesys.GetAuthorizationModelID() {
+		return nil, false
+	}
+
+	candidate, err := s.typesystemResolver(ctx, storeID, modelID)
+	if err != nil {
+		s.logger.WarnWithContext(ctx, "failed to resolve the candidate authorization model",
+			zap.String("store_id", storeID),
+			zap.String("candidate_model_id", modelID),
+			zap.Error(err),
+		)
+		return nil, false
+	}
+
+	return candidate, true
+}
+
+// withCandidateModelCheckResolver returns a CheckResolver that resolves the checks with checkResolver, and also
+// against the candidate model of the store if the request is sampled.
+func (s *Server) withCandidateModelCheckResolver(ctx context.Context, storeID string, typesys *typesystem.TypeSystem, checkResolver graph.CheckResolver) graph.CheckResolver {
+	candidate, ok := s.sampleCandidateModel(ctx, storeID, typesys)
+	if !ok {
+		return checkResolver
+	}
+	candidateModelID := candidate.GetAuthorizationModelID()
+
+	return graph.NewShadowChecker(
+		checkResolver,
+		graph.NewCandidateModelCheckResolver(checkResolver, candidate),
+		graph.ShadowResolverWithName("candidate_model_check"),
+		graph.ShadowResolverWithLogger(s.logger),
+		graph.ShadowResolverWithTimeout(s.shadowCheckResolverTimeout),
+		graph.ShadowResolverWithResultHandler(func(_ context.Context, req *graph.ResolveCheckRequest, main, shadow *graph.ResolveCheckResponse, shadowErr error) {
+			if shadowErr != nil || main.GetAllowed() == shadow.GetAllowed() {
+				s.candidateModels.Record(storeID, candidateModelID, candidatemodel.MethodCheck, nil, shadowErr)
+				return
+			}
+
+			tk := req.GetTupleKey()
+			request, err := protojson.Marshal(&openfgav1.CheckRequest{
+				StoreId:              req.GetStoreID(),
+				TupleKey:             &openfgav1.CheckRequestTupleKey{User: tk.GetUser(), Relation: tk.GetRelation(), Object: tk.GetObject()},
+				ContextualTuples:     &openfgav1.ContextualTupleKeys{TupleKeys: req.GetContextualTuples()},
+				AuthorizationModelId: req.GetAuthorizationModelID(),
+				Context:              req.GetContext(),
+				Consistency:          req.GetConsistency(),
+			})
+			if err != nil {
+				s.candidateModels.Record(storeID, candidateModelID, candidatemodel.MethodCheck, nil, err)
+				return
+			}
+
+			s.candidateModels.Record(storeID, candidateModelID, candidatemodel.MethodCheck, &candidatemodel.Divergence{
+				Time:                       time.Now().UTC(),
+				Method:                     candidatemodel.MethodCheck,
+				Request:                    request,
+				ActiveAuthorizationModelID: req.GetAuthorizationModelID(),
+				ActiveResult:               checkResult(main.GetAllowed()),
+				CandidateResult:            checkResult(shadow.GetAllowed()),
+			}, nil)
+		}),
+	)
+}
+
+// withCandidateModelListObjectsQuery returns a ListObjectsResolver that returns the results of q, and also evaluates
+// the query against the candidate model of the store if the request is sampled.
+func (s *Server) withCandidateModelListObjectsQuery(ctx context.Context, storeID string, typesys *typesystem.TypeSystem, q commands.ListObjectsResolver) (commands.ListObjectsResolver, error) {
+	candidate, ok := s.sampleCandidateModel(ctx, storeID, typesys)
+	if !ok {
+		return q, nil
+	}
+	candidateModelID := candidate.GetAuthorizationModelID()
+
+	return commands.NewCandidateModelListObjectsQuery(q, candidate, commands.NewShadowListObjectsQueryConfig(
+		commands.WithShadowListObjectsQueryTimeout(s.shadowListObjectsQueryTimeout),
+		commands.WithShadowListObjectsQueryLogger(s.logger),
+		commands.WithShadowListObjectsQueryMaxDeltaItems(s.shadowListObjectsQueryMaxDeltaItems),
+		commands.WithShadowListObjectsQueryResultHandler(func(_ context.Context, req *openfgav1.ListObjectsRequest, main, shadow *commands.ListObjectsResponse, shadowErr error) {
+			if shadowErr != nil {
+				s.candidateModels.Record(storeID, candidateModelID, candidatemodel.MethodListObjects, nil, shadowErr)
+				return
+			}
+
+			// the results truncated to the maximum number of results cannot be compared
+			if s.listObjectsMaxResults > 0 &&
+				(len(main.Objects) >= int(s.listObjectsMaxResults) || len(shadow.Objects) >= int(s.listObjectsMaxResults)) {
+				return
+			}
+
+			delta := candidatemodel.ObjectsDelta(main.Objects, shadow.Objects)
+			if len(delta) == 0 {
+				s.candidateModels.Record(storeID, candidateModelID, candidatemodel.MethodListObjects, nil, nil)
+				return
+			}
+
+			request, err := protojson.Marshal(req)
+			if err != nil {
+				s.candidateModels.Record(storeID, candidateModelID, candidatemodel.MethodListObjects, nil, err)
+				return
+			}
+
+			s.candidateModels.Record(storeID, candidateModelID, candidatemodel.MethodListObjects, &candidatemodel.Divergence{
+				Time:                       time.Now().UTC(),
+				Method:                     candidatemodel.MethodListObjects,
+				Request:                    request,
+				ActiveAuthorizationModelID: req.GetAuthorizationModelId(),
+				ActiveResult:               listObjectsResult(main.Objects),
+				CandidateResult:            listObjectsResult(shadow.Objects),
+				Delta:                      delta[:min(len(delta), max(s.shadowListObjectsQueryMaxDeltaItems, 0))],
+			}, nil)
+		}),
+	))
+}
+
+func checkResult(allowed bool) string {
+	if allowed {
+		return "allowed"
+	}
+	return "denied"
+}
+
+func listObjectsResult(objects []string) string {
+	return strconv.Itoa(len(objects)) + " objects"
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/candidatemodel"
+	"github.com/openfga/openfga/internal/jsongrpc"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestCandidateAuthorizationModel(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(WithDatastore(ds))
+	t.Cleanup(s.Close)
+
+	conn := newJSONGRPCTestConn(t, s, &candidatemodel.ServiceDesc)
+
+	ctx := context.Background()
+	createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "openfga-test"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	writeModel := func(t *testing.T, dsl string) string {
+		model := testutils.MustTransformDSLToProtoWithID(dsl)
+		resp, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+			StoreId:         storeID,
+			SchemaVersion:   model.GetSchemaVersion(),
+			TypeDefinitions: model.GetTypeDefinitions(),
+		})
+		require.NoError(t, err)
+		return resp.GetAuthorizationModelId()
+	}
+
+	// the viewer tuple grants access in the active model only
+	activeModelID := writeModel(t, `
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define viewer: [user]`)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		}},
+	})
+	require.NoError(t, err)
+
+	candidateModelID := writeModel(t, `
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define editor: [user]
+				define viewer: editor`)
+
+	t.Run("no_candidate", func(t *testing.T) {
+		_, err := candidatemodel.GetCandidateAuthorizationModelReport(ctx, conn, &candidatemodel.GetCandidateAuthorizationModelReportRequest{StoreRequest: jsongrpc.StoreRequest{StoreID: storeID}})
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("divergences_are_reported", func(t *testing.T) {
+		_, err := candidatemodel.SetCandidateAuthorizationModel(ctx, conn, &candidatemodel.SetCandidateAuthorizationModelRequest{
+			StoreRequest:         jsongrpc.StoreRequest{StoreID: storeID},
+			AuthorizationModelID: candidateModelID,
+			SamplePercentage:     100,
+		})
+		require.NoError(t, err)
+
+		checkResp, err := s.Check(ctx, &openfgav1.CheckRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: activeModelID,
+			TupleKey:             tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:anne"),
+		})
+		require.NoError(t, err)
+		require.True(t, checkResp.GetAllowed())
+
+		listObjectsResp, err := s.ListObjects(ctx, &openfgav1.ListObjectsRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: activeModelID,
+			Type:                 "document",
+			Relation:             "viewer",
+			User:                 "user:anne",
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"document:1"}, listObjectsResp.GetObjects())
+
+		var report *candidatemodel.GetCandidateAuthorizationModelReportResponse
+		require.Eventually(t, func() bool {
+			report, err = candidatemodel.GetCandidateAuthorizationModelReport(ctx, conn, &candidatemodel.GetCandidateAuthorizationModelReportRequest{StoreRequest: jsongrpc.StoreRequest{StoreID: storeID}})
+			require.NoError(t, err)
+			return report.Check.Evaluated == 1 && report.ListObjects.Evaluated == 1
+		}, 5*time.Second, 10*time.Millisecond)
+
+		require.Equal(t, candidateModelID, report.AuthorizationModelID)
+		require.Equal(t, 100, report.SamplePercentage)
+		require.Equal(t, candidatemodel.Counts{Evaluated: 1, Diverged: 1}, report.Check)
+		require.Equal(t, candidatemodel.Counts{Evaluated: 1, Diverged: 1}, report.ListObjects)
+		require.Len(t, report.Divergences, 2)
+
+		divergences := make(map[string]candidatemodel.Divergence, len(report.Divergences))
+		for _, divergence := range report.Divergences {
+			require.Equal(t, activeModelID, divergence.ActiveAuthorizationModelID)
+			divergences[divergence.Method] = divergence
+		}
+
+		check := divergences[candidatemodel.MethodCheck]
+		require.Equal(t, "allowed", check.ActiveResult)
+		require.Equal(t, "denied", check.CandidateResult)
+		var checkReq openfgav1.CheckRequest
+		require.NoError(t, protojson.Unmarshal(check.Request, &checkReq))
+		require.Equal(t, "document:1", checkReq.GetTupleKey().GetObject())
+		require.Equal(t, "user:anne", checkReq.GetTupleKey().GetUser())
+
+		listObjects := divergences[candidatemodel.MethodListObjects]
+		require.Equal(t, "1 objects", listObjects.ActiveResult)
+		require.Equal(t, "0 objects", listObjects.CandidateResult)
+		require.Equal(t, []string{"-document:1"}, listObjects.Delta)
+		var listObjectsReq openfgav1.ListObjectsRequest
+		require.NoError(t, protojson.Unmarshal(listObjects.Request, &listObjectsReq))
+		require.Equal(t, "user:anne", listObjectsReq.GetUser())
+	})
+
+	t.Run("clear_candidate", func(t *testing.T) {
+		_, err := candidatemodel.SetCandidateAuthorizationModel(ctx, conn, &candidatemodel.SetCandidateAuthorizationModelRequest{StoreRequest: jsongrpc.StoreRequest{StoreID: storeID}})
+		require.NoError(t, err)
+
+		_, err = candidatemodel.GetCandidateAuthorizationModelReport(ctx, conn, &candidatemodel.GetCandidateAuthorizationModelReportRequest{StoreRequest: jsongrpc.StoreRequest{StoreID: storeID}})
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("invalid_requests", func(t *testing.T) {
+		_, err := candidatemodel.SetCandidateAuthorizationModel(ctx, conn, &candidatemodel.SetCandidateAuthorizationModelRequest{})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = candidatemodel.SetCandidateAuthorizationModel(ctx, conn, &candidatemodel.SetCandidateAuthorizationModelRequest{
+			StoreRequest:         jsongrpc.StoreRequest{StoreID: storeID},
+			AuthorizationModelID: candidateModelID,
+			SamplePercentage:     101,
+		})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = candidatemodel.GetCandidateAuthorizationModelReport(ctx, conn, &candidatemodel.GetCandidateAuthorizationModelReportRequest{})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = candidatemodel.SetCandidateAuthorizationModel(ctx, conn, &candidatemodel.SetCandidateAuthorizationModelRequest{
+			StoreRequest:         jsongrpc.StoreRequest{StoreID: storeID},
+			AuthorizationModelID: "01JBVMPYB8Q4G2NCC8Z2RMMA5A",
+		})
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_authorization_model_not_found), status.Code(err))
+	})
+}
@@ -15,6 +15,7 @@ import (
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 
+	"github.com/openfga/openfga/internal/checkcost"
 	"github.com/openfga/openfga/internal/graph"
 	"github.com/openfga/openfga/internal/utils"
 	"github.com/openfga/openfga/internal/utils/apimethod"
@@ -23,6 +24,8 @@ import (
 	serverconfig "github.com/openfga/openfga/pkg/server/config"
 	serverErrors "github.com/openfga/openfga/pkg/server/errors"
 	"github.com/openfga/openfga/pkg/telemetry"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
 )
 
 func (s *Server) Check(ctx context.Context, req *openfgav1.CheckRequest) (*openfgav1.CheckResponse, error) {
@@ -71,12 +74,19 @@ func (s *Server) Check(ctx context.Context, req *openfgav1.CheckRequest) (*openf
 	}
 	req.AuthorizationModelId = typesys.GetAuthorizationModelID() // the resolved model id
 
+	err = s.admitCheck(ctx, typesys, storeID, tk)
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+
 	checkQuery := commands.NewCheckCommand(
 		s.datastore,
-		checkResolver,
+		s.withCandidateModelCheckResolver(ctx, storeID, typesys, checkResolver),
 		typesys,
 		commands.WithCheckCommandLogger(s.logger),
 		commands.WithCheckCommandMaxConcurrentReads(s.maxConcurrentReadsForCheck),
+		commands.WithCheckCommandConcurrencyLimiter(s.checkConcurrencyLimiter),
 		commands.WithCheckCommandCache(s.sharedDatastoreResources, s.cacheSettings),
 		commands.WithCheckDatastoreThrottler(
 			s.featureFlagClient.Boolean(serverconfig.ExperimentalDatastoreThrottling, storeID),
@@ -190,6 +200,48 @@ func (s *Server) Check(ctx context.Context, req *openfgav1.CheckRequest) (*openf
 	return res, nil
 }
 
+// admitCheck estimates the cost of the check and admits it against the budget of the store, if checks are
+// admitted by their cost. The estimate is set in the response headers, the request tags and the span.
+func (s *Server) admitCheck(ctx context.Context, typesys *typesystem.TypeSystem, storeID string, tk *openfgav1.CheckRequestTupleKey) error {
+	if s.checkCostAdmission == nil {
+		return nil
+	}
+
+	cost, decision, err := s.checkCostAdmission.Admit(ctx, typesys, storeID, tuple.ConvertCheckRequestTupleKeyToTupleKey(tk))
+
+	grpc_ctxtags.Extract(ctx).Set("request.cost_admission", string(decision))
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("cost_admission", string(decision)))
+	if decision != checkcost.DecisionUnestimated {
+		reads := strconv.FormatFloat(cost.Reads, 'f', 0, 64)
+		dispatches := strconv.FormatFloat(cost.Dispatches, 'f', 0, 64)
+		s.transport.SetHeader(ctx, CheckEstimatedReadsHeader, reads)
+		s.transport.SetHeader(ctx, CheckEstimatedDispatchesHeader, dispatches)
+
+		grpc_ctxtags.Extract(ctx).Set("request.estimated_reads", cost.Reads)
+		grpc_ctxtags.Extract(ctx).Set("request.estimated_dispatches", cost.Dispatches)
+		trace.SpanFromContext(ctx).SetAttributes(
+			attribute.Float64("estimated_reads", cost.Reads),
+			attribute.Float64("estimated_dispatches", cost.Dispatches),
+		)
+	}
+
+	if errors.Is(err, checkcost.ErrBudgetExceeded) {
+		return serverErrors.ErrCheckCostBudgetExceeded
+	}
+	if err != nil {
+		return commands.CheckCommandErrorToServerError(err)
+	}
+	return nil
+}
+
+// getPermissionIndex returns the permission index as a graph.PermissionIndex, or nil if it is disabled.
+func (s *Server) getPermissionIndex() graph.PermissionIndex {
+	if s.permissionIndex == nil {
+		return nil
+	}
+	return s.permissionIndex
+}
+
 func (s *Server) getCheckResolverBuilder(storeID string) *graph.CheckResolverOrderedBuilder {
 	checkCacheOptions, checkDispatchThrottlingOptions := s.getCheckResolverOptions()
 
@@ -201,6 +253,7 @@ func (s *Server) getCheckResolverBuilder(storeID string) *graph.CheckResolverOrd
 			graph.WithPlanner(s.planner),
 			graph.WithUpstreamTimeout(s.requestTimeout),
 			graph.WithLocalCheckerLogger(s.logger),
+			graph.WithPermissionIndex(s.getPermissionIndex()),
 		}...),
 		graph.WithLocalShadowCheckerOpts([]graph.LocalCheckerOption{
 			graph.WithResolveNodeBreadthLimit(s.resolveNodeBreadthLimit),
@@ -214,6 +267,11 @@ func (s *Server) getCheckResolverBuilder(storeID string) *graph.CheckResolverOrd
 			graph.ShadowResolverWithTimeout(s.shadowCheckResolverTimeout),
 		}...),
 		graph.WithCachedCheckResolverOpts(s.cacheSettings.ShouldCacheCheckQueries(), checkCacheOptions...),
+		graph.WithSingleflightCheckResolverOpts(s.inflightChecks != nil, graph.WithInflightChecks(s.inflightChecks)),
 		graph.WithDispatchThrottlingCheckResolverOpts(s.checkDispatchThrottlingEnabled, checkDispatchThrottlingOptions...),
+		graph.WithRemoteCheckResolverOpts(s.peerDispatcher != nil, []graph.RemoteCheckResolverOpt{
+			graph.WithPeerDispatcher(s.peerDispatcher),
+			graph.WithRemoteCheckResolverLogger(s.logger),
+		}...),
 	}...)
 }
@@ -1,15 +1,24 @@
 package server
 
 import (
+	"context"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 
 	"github.com/openfga/openfga/pkg/featureflags"
+	serverconfig "github.com/openfga/openfga/pkg/server/config"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
 )
 
 func TestCheck_Validation(t *testing.T) {
@@ -84,3 +93,198 @@ func TestCheck_Validation(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckWithPermissionIndex(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		WithCheckPermissionIndexEnabled(true),
+		WithCheckPermissionIndexRefreshInterval(10*time.Millisecond),
+	)
+	t.Cleanup(s.Close)
+
+	createStoreResp, err := s.CreateStore(context.Background(), &openfgav1.CreateStoreRequest{Name: "openfga-test"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type group
+			relations
+				define member: [user, group#member]`)
+
+	_, err = s.WriteAuthorizationModel(context.Background(), &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	_, err = s.Write(context.Background(), &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKey("group:eng", "member", "group:backend#member"),
+			tuple.NewTupleKey("group:backend", "member", "user:anne"),
+		}},
+	})
+	require.NoError(t, err)
+
+	check := func(user string) bool {
+		resp, err := s.Check(context.Background(), &openfgav1.CheckRequest{
+			StoreId:  storeID,
+			TupleKey: tuple.NewCheckRequestTupleKey("group:eng", "member", user),
+		})
+		require.NoError(t, err)
+		return resp.GetAllowed()
+	}
+
+	// results are the same whether they come from the graph (before the store is indexed) or the index
+	for i := 0; i < 10; i++ {
+		require.True(t, check("user:anne"))
+		require.False(t, check("user:bob"))
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	_, err = s.Write(context.Background(), &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Deletes: &openfgav1.WriteRequestDeletes{TupleKeys: []*openfgav1.TupleKeyWithoutCondition{
+			tuple.TupleKeyToTupleKeyWithoutCondition(tuple.NewTupleKey("group:backend", "member", "user:anne")),
+		}},
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return !check("user:anne")
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestCheckWithSingleflight(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		WithCheckSingleflightEnabled(true),
+	)
+	t.Cleanup(s.Close)
+
+	createStoreResp, err := s.CreateStore(context.Background(), &openfgav1.CreateStoreRequest{Name: "openfga-test"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type group
+			relations
+				define member: [user, group#member]`)
+
+	_, err = s.WriteAuthorizationModel(context.Background(), &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	// group:a and group:b are members of each other, so that concurrent checks share cyclic sub-problems
+	_, err = s.Write(context.Background(), &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKey("group:a", "member", "group:b#member"),
+			tuple.NewTupleKey("group:b", "member", "group:a#member"),
+			tuple.NewTupleKey("group:b", "member", "user:anne"),
+		}},
+	})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		for _, object := range []string{"group:a", "group:b"} {
+			for user, expected := range map[string]bool{"user:anne": true, "user:bob": false} {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					resp, err := s.Check(context.Background(), &openfgav1.CheckRequest{
+						StoreId:  storeID,
+						TupleKey: tuple.NewCheckRequestTupleKey(object, "member", user),
+					})
+					require.NoError(t, err)
+					require.Equal(t, expected, resp.GetAllowed())
+				}()
+			}
+		}
+	}
+	wg.Wait()
+}
+
+func TestCheckWithCostAdmission(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		WithCheckCostAdmissionEnabled(true),
+		WithCheckCostAdmissionAction(serverconfig.CheckCostAdmissionActionReject),
+		WithCheckCostBudget(0, 5),
+	)
+	t.Cleanup(s.Close)
+
+	createStoreResp, err := s.CreateStore(context.Background(), &openfgav1.CreateStoreRequest{Name: "openfga-test"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type group
+			relations
+				define owner: [user]
+				define member: [user, group#member]`)
+
+	_, err = s.WriteAuthorizationModel(context.Background(), &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	// a direct relation is read once
+	resp, err := s.Check(context.Background(), &openfgav1.CheckRequest{
+		StoreId:  storeID,
+		TupleKey: tuple.NewCheckRequestTupleKey("group:a", "owner", "user:anne"),
+	})
+	require.NoError(t, err)
+	require.False(t, resp.GetAllowed())
+
+	// a recursive relation dispatches more than the budget allows
+	_, err = s.Check(context.Background(), &openfgav1.CheckRequest{
+		StoreId:  storeID,
+		TupleKey: tuple.NewCheckRequestTupleKey("group:a", "member", "user:anne"),
+	})
+	require.ErrorIs(t, err, serverErrors.ErrCheckCostBudgetExceeded)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
@@ -16,9 +16,11 @@ import (
 	"github.com/openfga/openfga/internal/concurrency"
 	"github.com/openfga/openfga/internal/graph"
 	"github.com/openfga/openfga/internal/shared"
+	"github.com/openfga/openfga/internal/utils/apimethod"
 	"github.com/openfga/openfga/pkg/logger"
 	"github.com/openfga/openfga/pkg/server/config"
 	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/storagewrappers/sharediterator"
 	"github.com/openfga/openfga/pkg/typesystem"
 )
 
@@ -55,6 +57,10 @@ type BatchCheckMetadata struct {
 	DatastoreItemCount     uint64
 	DatastoreThrottleCount uint32
 	DuplicateCheckCount    int
+	// MemoHits is the number of sub-problems whose resolution was shared between checks.
+	MemoHits uint64
+	// DispatchesSaved is the number of dispatches that sharing sub-problems between checks saved.
+	DispatchesSaved uint64
 }
 
 type BatchCheckValidationError struct {
@@ -135,24 +141,52 @@ func (bq *BatchCheckQuery) Execute(ctx context.Context, params *BatchCheckComman
 		}
 	}
 
+	results := map[CorrelationID]*BatchCheckOutcome{}
+	metadata, err := bq.execute(ctx, params, func(correlationIDs []CorrelationID, outcome *BatchCheckOutcome) error {
+		// map all associated CorrelationIDs to this outcome
+		for _, id := range correlationIDs {
+			results[id] = outcome
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return results, metadata, nil
+}
+
+// BatchCheckResultHandler receives the outcome of a check of a batch, along with the correlation IDs of
+// every item of the batch that is identical to the check. Handlers are never called concurrently.
+type BatchCheckResultHandler func(correlationIDs []CorrelationID, outcome *BatchCheckOutcome) error
+
+// ExecuteStreamed resolves the checks of the batch like Execute, but hands the outcome of each check to
+// handler as soon as it is resolved, and does not limit the number of checks. At most maxConcurrentChecks
+// are resolved concurrently, and a slow handler holds back the checks that are resolved in the meantime.
+// If handler fails, the checks that are left are cancelled and its error is returned.
+func (bq *BatchCheckQuery) ExecuteStreamed(ctx context.Context, params *BatchCheckCommandParams, handler BatchCheckResultHandler) (*BatchCheckMetadata, error) {
+	return bq.execute(ctx, params, handler)
+}
+
+func (bq *BatchCheckQuery) execute(ctx context.Context, params *BatchCheckCommandParams, handler BatchCheckResultHandler) (*BatchCheckMetadata, error) {
 	if len(params.Checks) == 0 {
-		return nil, nil, &BatchCheckValidationError{
+		return nil, &BatchCheckValidationError{
 			Message: "batch check requires at least one check to evaluate, no checks were received",
 		}
 	}
 
 	if err := validateCorrelationIDs(params.Checks); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	// Before processing the batch, deduplicate the checks based on their unique cache key
-	// After all routines have finished, we will map each individual check response to all associated CorrelationIDs
+	// Once a check is resolved, its response is handed over with all associated CorrelationIDs
 	cacheKeyMap := make(map[CacheKey]*checkAndCorrelationIDs)
 	for _, check := range params.Checks {
 		key, err := generateCacheKeyFromCheck(check, params.StoreID, bq.typesys.GetAuthorizationModelID())
 		if err != nil {
 			bq.logger.Error("batch check cache key computation failed with error", zap.Error(err))
-			return nil, nil, err
+			return nil, err
 		}
 
 		if item, ok := cacheKeyMap[key]; ok {
@@ -165,7 +199,23 @@ func (bq *BatchCheckQuery) Execute(ctx context.Context, params *BatchCheckComman
 		}
 	}
 
-	var resultMap = new(sync.Map)
+	// the checks of the batch share the resolution of the sub-problems they have in common...
+	memo := graph.NewCheckMemo()
+	ctx = graph.ContextWithCheckMemo(ctx, memo)
+
+	// ...and the datastore reads they have in common, unless these are already shared server-wide
+	datastore := bq.datastore
+	if !bq.cacheSettings.SharedIteratorEnabled {
+		datastore = sharediterator.NewSharedIteratorDatastore(
+			datastore,
+			sharediterator.NewSharedIteratorDatastoreStorage(),
+			sharediterator.WithSharedIteratorDatastoreLogger(bq.logger),
+			sharediterator.WithMethod(string(apimethod.BatchCheck)),
+		)
+	}
+
+	var handlerMu sync.Mutex
+	var handlerFailed atomic.Bool
 	var totalQueryCount atomic.Uint32
 	var totalDispatchCount atomic.Uint32
 	var dispatchThrottleCount atomic.Uint32
@@ -173,20 +223,43 @@ func (bq *BatchCheckQuery) Execute(ctx context.Context, params *BatchCheckComman
 	var datastoreThrottleCount atomic.Uint32
 
 	pool := concurrency.NewPool(ctx, int(bq.maxConcurrentChecks))
-	for key, item := range cacheKeyMap {
+	for _, item := range cacheKeyMap {
+		// once the handler failed, the checks that are left are not resolved
+		if handlerFailed.Load() {
+			break
+		}
+
 		check := item.Check
 		pool.Go(func(ctx context.Context) error {
+			handle := func(outcome *BatchCheckOutcome) error {
+				handlerMu.Lock()
+				defer handlerMu.Unlock()
+
+				// the handler is not called again after it failed, its error is the one returned
+				if handlerFailed.Load() {
+					return nil
+				}
+				if err := handler(item.CorrelationIDs, outcome); err != nil {
+					handlerFailed.Store(true)
+					return err
+				}
+				return nil
+			}
+
+			if handlerFailed.Load() {
+				return nil
+			}
+
 			select {
 			case <-ctx.Done():
-				resultMap.Store(key, &BatchCheckOutcome{
+				return handle(&BatchCheckOutcome{
 					Err: ctx.Err(),
 				})
-				return nil
 			default:
 			}
 
 			checkQuery := NewCheckCommand(
-				bq.datastore,
+				datastore,
 				bq.checkResolver,
 				bq.typesys,
 				WithCheckCommandLogger(bq.logger),
@@ -208,11 +281,6 @@ func (bq *BatchCheckQuery) Execute(ctx context.Context, params *BatchCheckComman
 
 			response, metadata, err := checkQuery.Execute(ctx, checkParams)
 
-			resultMap.Store(key, &BatchCheckOutcome{
-				CheckResponse: response,
-				Err:           err,
-			})
-
 			if metadata != nil {
 				if metadata.DispatchThrottled.Load() {
 					dispatchThrottleCount.Add(1)
@@ -227,32 +295,26 @@ func (bq *BatchCheckQuery) Execute(ctx context.Context, params *BatchCheckComman
 			totalQueryCount.Add(response.GetResolutionMetadata().DatastoreQueryCount)
 			totalItemCount.Add(response.GetResolutionMetadata().DatastoreItemCount)
 
-			return nil
+			return handle(&BatchCheckOutcome{
+				CheckResponse: response,
+				Err:           err,
+			})
 		})
 	}
 
-	_ = pool.Wait()
-
-	results := map[CorrelationID]*BatchCheckOutcome{}
-
-	// Each cacheKey can have > 1 associated CorrelationID
-	for cacheKey, checkItem := range cacheKeyMap {
-		res, _ := resultMap.Load(cacheKey)
-		outcome := res.(*BatchCheckOutcome)
-
-		for _, id := range checkItem.CorrelationIDs {
-			// map all associated CorrelationIDs to this outcome
-			results[id] = outcome
-		}
+	if err := pool.Wait(); err != nil {
+		return nil, err
 	}
 
-	return results, &BatchCheckMetadata{
+	return &BatchCheckMetadata{
 		DispatchThrottleCount:  dispatchThrottleCount.Load(),
 		DatastoreQueryCount:    totalQueryCount.Load(),
 		DatastoreItemCount:     totalItemCount.Load(),
 		DatastoreThrottleCount: datastoreThrottleCount.Load(),
 		DispatchCount:          totalDispatchCount.Load(),
 		DuplicateCheckCount:    len(params.Checks) - len(cacheKeyMap),
+		MemoHits:               memo.Hits(),
+		DispatchesSaved:        memo.DispatchesSaved(),
 	}, nil
 }
 
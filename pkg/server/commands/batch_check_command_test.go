@@ -2,7 +2,9 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"testing"
 
 	"github.com/oklog/ulid/v2"
@@ -17,6 +19,7 @@ import (
 	"github.com/openfga/openfga/pkg/server/config"
 	"github.com/openfga/openfga/pkg/storage/memory"
 	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
 	"github.com/openfga/openfga/pkg/typesystem"
 )
 
@@ -422,6 +425,165 @@ func TestBatchCheckCommand(t *testing.T) {
 	})
 }
 
+func TestBatchCheckCommandSharesSubProblems(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type team
+			relations
+				define member: [user]
+		type group
+			relations
+				define member: [user, team#member]
+		type folder
+			relations
+				define viewer: [group#member]
+		type doc
+			relations
+				define parent: [folder]
+				define viewer: viewer from parent
+	`)
+	ts, err := typesystem.NewAndValidate(context.Background(), model)
+	require.NoError(t, err)
+
+	const numDocs = 20
+	tuples := []*openfgav1.TupleKey{
+		tuple.NewTupleKey("group:eng", "member", "user:anne"),
+		tuple.NewTupleKey("folder:1", "viewer", "group:eng#member"),
+	}
+	checks := make([]*openfgav1.BatchCheckItem, numDocs)
+	for i := 0; i < numDocs; i++ {
+		object := fmt.Sprintf("doc:%d", i)
+		tuples = append(tuples, tuple.NewTupleKey(object, "parent", "folder:1"))
+		checks[i] = &openfgav1.BatchCheckItem{
+			TupleKey:      tuple.NewCheckRequestTupleKey(object, "viewer", "user:anne"),
+			CorrelationId: strconv.Itoa(i),
+		}
+	}
+	require.NoError(t, ds.Write(context.Background(), storeID, nil, tuples))
+
+	checkResolver, checkResolverCloser, err := graph.NewOrderedCheckResolvers().Build()
+	require.NoError(t, err)
+	t.Cleanup(checkResolverCloser)
+
+	cmd := NewBatchCheckCommand(ds, checkResolver, ts)
+	results, meta, err := cmd.Execute(context.Background(), &BatchCheckCommandParams{
+		AuthorizationModelID: ts.GetAuthorizationModelID(),
+		Checks:               checks,
+		StoreID:              storeID,
+	})
+	require.NoError(t, err)
+	require.Len(t, results, numDocs)
+	for _, outcome := range results {
+		require.NoError(t, outcome.Err)
+		require.True(t, outcome.CheckResponse.GetAllowed())
+	}
+
+	// folder:1#viewer, and the dispatch to group:eng#member it takes, are resolved once for the whole batch
+	require.Equal(t, uint64(numDocs-1), meta.MemoHits)
+	require.Equal(t, uint64(numDocs-1), meta.DispatchesSaved)
+	require.Equal(t, uint32(numDocs+1), meta.DispatchCount)
+}
+
+func TestBatchCheckCommandExecuteStreamed(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type doc
+			relations
+				define viewer: [user]
+	`)
+	ts, err := typesystem.NewAndValidate(context.Background(), model)
+	require.NoError(t, err)
+	require.NoError(t, ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("doc:0", "viewer", "user:anne"),
+	}))
+
+	checkResolver, checkResolverCloser, err := graph.NewOrderedCheckResolvers().Build()
+	require.NoError(t, err)
+	t.Cleanup(checkResolverCloser)
+
+	// more checks than allowed per batch, two of which are identical
+	const numChecks = 10
+	checks := make([]*openfgav1.BatchCheckItem, 0, numChecks+1)
+	for i := 0; i < numChecks; i++ {
+		checks = append(checks, &openfgav1.BatchCheckItem{
+			TupleKey:      tuple.NewCheckRequestTupleKey(fmt.Sprintf("doc:%d", i), "viewer", "user:anne"),
+			CorrelationId: strconv.Itoa(i),
+		})
+	}
+	checks = append(checks, &openfgav1.BatchCheckItem{
+		TupleKey:      tuple.NewCheckRequestTupleKey("doc:0", "viewer", "user:anne"),
+		CorrelationId: "duplicate",
+	})
+
+	t.Run("streams_every_result", func(t *testing.T) {
+		cmd := NewBatchCheckCommand(ds, checkResolver, ts,
+			WithBatchCheckMaxChecksPerBatch(2),
+			WithBatchCheckMaxConcurrentChecks(2),
+		)
+
+		results := map[CorrelationID]bool{}
+		handled := 0
+		_, err := cmd.ExecuteStreamed(context.Background(), &BatchCheckCommandParams{
+			AuthorizationModelID: ts.GetAuthorizationModelID(),
+			Checks:               checks,
+			StoreID:              storeID,
+		}, func(correlationIDs []CorrelationID, outcome *BatchCheckOutcome) error {
+			require.NoError(t, outcome.Err)
+			handled++
+			for _, id := range correlationIDs {
+				results[id] = outcome.CheckResponse.GetAllowed()
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, numChecks, handled)
+		require.Len(t, results, numChecks+1)
+		require.True(t, results["0"])
+		require.True(t, results["duplicate"])
+		require.False(t, results["1"])
+	})
+
+	t.Run("handler_error_stops_the_batch", func(t *testing.T) {
+		for _, maxConcurrentChecks := range []uint32{1, 4} {
+			cmd := NewBatchCheckCommand(ds, checkResolver, ts, WithBatchCheckMaxConcurrentChecks(maxConcurrentChecks))
+
+			handlerErr := errors.New("handler failed")
+			handled := 0
+			_, err := cmd.ExecuteStreamed(context.Background(), &BatchCheckCommandParams{
+				AuthorizationModelID: ts.GetAuthorizationModelID(),
+				Checks:               checks,
+				StoreID:              storeID,
+			}, func(correlationIDs []CorrelationID, outcome *BatchCheckOutcome) error {
+				handled++
+				return handlerErr
+			})
+			require.ErrorIs(t, err, handlerErr)
+			// the handler is not called again once it failed
+			require.Equal(t, 1, handled)
+		}
+	})
+}
+
 func BenchmarkBatchCheckCommand(b *testing.B) {
 	ds := memory.New()
 	model := testutils.MustTransformDSLToProtoWithID(`
@@ -35,6 +35,7 @@ type CheckQuery struct {
 	sharedCheckResources       *shared.SharedDatastoreResources
 	cacheSettings              config.CacheSettings
 	maxConcurrentReads         uint32
+	concurrencyLimiter         *storagewrappers.AdaptiveConcurrencyLimiter
 	shouldCacheIterators       bool
 	datastoreThrottlingEnabled bool
 	datastoreThrottleThreshold int
@@ -57,6 +58,14 @@ func WithCheckCommandMaxConcurrentReads(m uint32) CheckQueryOption {
 	}
 }
 
+// WithCheckCommandConcurrencyLimiter bounds the concurrent datastore reads of the check to the limit of an adaptive
+// limiter instead of WithCheckCommandMaxConcurrentReads. See storagewrappers.AdaptiveConcurrencyLimiter.
+func WithCheckCommandConcurrencyLimiter(l *storagewrappers.AdaptiveConcurrencyLimiter) CheckQueryOption {
+	return func(c *CheckQuery) {
+		c.concurrencyLimiter = l
+	}
+}
+
 func WithCheckCommandLogger(l logger.Logger) CheckQueryOption {
 	return func(c *CheckQuery) {
 		c.logger = l
@@ -127,12 +136,33 @@ func (c *CheckQuery) Execute(ctx context.Context, params *CheckCommandParams) (*
 		return nil, nil, err
 	}
 
+	return c.resolve(ctx, resolveCheckRequest)
+}
+
+// ExecuteDispatched resolves a Check sub-problem that was dispatched to this node by a peer, continuing
+// from the depth and visited paths it was dispatched with. The sub-problem is validated against the model
+// like a Check request, since it was received over the network.
+func (c *CheckQuery) ExecuteDispatched(ctx context.Context, resolveCheckRequest *graph.ResolveCheckRequest) (*graph.ResolveCheckResponse, *graph.ResolveCheckRequestMetadata, error) {
+	tk := resolveCheckRequest.GetTupleKey()
+	err := validateCheckRequest(c.typesys,
+		tuple.NewCheckRequestTupleKey(tk.GetObject(), tk.GetRelation(), tk.GetUser()),
+		&openfgav1.ContextualTupleKeys{TupleKeys: resolveCheckRequest.GetContextualTuples()},
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return c.resolve(ctx, resolveCheckRequest)
+}
+
+func (c *CheckQuery) resolve(ctx context.Context, resolveCheckRequest *graph.ResolveCheckRequest) (*graph.ResolveCheckResponse, *graph.ResolveCheckRequestMetadata, error) {
 	datastoreWithTupleCache := storagewrappers.NewRequestStorageWrapperWithCache(
 		c.datastore,
-		params.ContextualTuples.GetTupleKeys(),
+		resolveCheckRequest.GetContextualTuples(),
 		&storagewrappers.Operation{
 			Method:            apimethod.Check,
 			Concurrency:       c.maxConcurrentReads,
+			Limiter:           c.concurrencyLimiter,
 			ThrottlingEnabled: c.datastoreThrottlingEnabled,
 			ThrottleThreshold: c.datastoreThrottleThreshold,
 			ThrottleDuration:  c.datastoreThrottleDuration,
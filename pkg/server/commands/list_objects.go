@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"slices"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -63,6 +64,7 @@ type ListObjectsQuery struct {
 	resolveNodeLimit        uint32
 	resolveNodeBreadthLimit uint32
 	maxConcurrentReads      uint32
+	concurrencyLimiter      *storagewrappers.AdaptiveConcurrencyLimiter
 
 	dispatchThrottlerConfig threshold.Config
 
@@ -78,6 +80,11 @@ type ListObjectsQuery struct {
 	useShadowCache       bool // Indicates that the shadow cache should be used instead of the main cache
 
 	pipelineEnabled bool // Indicates whether to run with the pipeline optimized code
+
+	resumeAfter string // Excludes the objects ordered before or equal to it from the streamed results
+
+	candidateObjectIDs storage.SortedSet // Restricts the results to these object IDs when not nil
+	objectIDPrefix     string            // Restricts the results to the object IDs starting with it
 }
 
 type ListObjectsResolver interface {
@@ -166,6 +173,14 @@ func WithMaxConcurrentReads(limit uint32) ListObjectsQueryOption {
 	}
 }
 
+// WithListObjectsConcurrencyLimiter bounds the concurrent datastore reads of the request to the limit of an adaptive
+// limiter instead of WithMaxConcurrentReads. See storagewrappers.AdaptiveConcurrencyLimiter.
+func WithListObjectsConcurrencyLimiter(l *storagewrappers.AdaptiveConcurrencyLimiter) ListObjectsQueryOption {
+	return func(d *ListObjectsQuery) {
+		d.concurrencyLimiter = l
+	}
+}
+
 func WithListObjectsCache(sharedDatastoreResources *shared.SharedDatastoreResources, cacheSettings serverconfig.CacheSettings) ListObjectsQueryOption {
 	return func(d *ListObjectsQuery) {
 		d.cacheSettings = cacheSettings
@@ -198,12 +213,52 @@ func WithListObjectsUseShadowCache(useShadowCache bool) ListObjectsQueryOption {
 	}
 }
 
+// WithListObjectsOptimizationsEnabled enables the weighted graph optimizations of reverse expansion. They are
+// also enabled by the ExperimentalListObjectsOptimizations feature flag.
+func WithListObjectsOptimizationsEnabled(value bool) ListObjectsQueryOption {
+	return func(d *ListObjectsQuery) {
+		d.optimizationsEnabled = value
+	}
+}
+
 func WithListObjectsPipelineEnabled(value bool) ListObjectsQueryOption {
 	return func(d *ListObjectsQuery) {
 		d.pipelineEnabled = value
 	}
 }
 
+// WithListObjectsResumeAfter makes ExecuteStreamed stream only the objects ordered after the provided object,
+// which paginated callers set to the last object of the previous page. The objects before it are still
+// traversed when the objects of the type may be users of other objects, since the ones after it may be reached
+// through them, otherwise their tuples are skipped when read.
+func WithListObjectsResumeAfter(object string) ListObjectsQueryOption {
+	return func(d *ListObjectsQuery) {
+		d.resumeAfter = object
+	}
+}
+
+// WithListObjectsCandidateObjectIDs restricts the results to the objects whose ID is one of the provided IDs, for
+// callers that already have a list of candidates and want to know which of them the user is related to. A nil
+// slice does not restrict the results, while an empty one restricts them to none. The IDs do not include the
+// object type.
+func WithListObjectsCandidateObjectIDs(objectIDs []string) ListObjectsQueryOption {
+	return func(d *ListObjectsQuery) {
+		if objectIDs == nil {
+			d.candidateObjectIDs = nil
+			return
+		}
+
+		d.candidateObjectIDs = storage.NewSortedSet(objectIDs...)
+	}
+}
+
+// WithListObjectsObjectIDPrefix restricts the results to the objects whose ID starts with the provided prefix.
+func WithListObjectsObjectIDPrefix(prefix string) ListObjectsQueryOption {
+	return func(d *ListObjectsQuery) {
+		d.objectIDPrefix = prefix
+	}
+}
+
 func NewListObjectsQuery(
 	ds storage.RelationshipTupleReader,
 	checkResolver graph.CheckResolver,
@@ -253,6 +308,20 @@ func NewListObjectsQuery(
 	return query, nil
 }
 
+func (q *ListObjectsQuery) hasObjectIDFilter() bool {
+	return q.candidateObjectIDs != nil || q.objectIDPrefix != ""
+}
+
+// pipelineObjectIDFilter returns the pipeline options that restrict the objects of the target type to the
+// candidate object IDs and to the object ID prefix.
+func (q *ListObjectsQuery) pipelineObjectIDFilter(targetObjectType string) []pipeline.Option {
+	if !q.hasObjectIDFilter() {
+		return nil
+	}
+
+	return []pipeline.Option{pipeline.WithObjectIDFilter(targetObjectType, q.candidateObjectIDs, q.objectIDPrefix)}
+}
+
 type ListObjectsResult struct {
 	ObjectID string
 	Err      error
@@ -333,6 +402,7 @@ func (q *ListObjectsQuery) evaluate(
 			&storagewrappers.Operation{
 				Method:            apimethod.ListObjects,
 				Concurrency:       q.maxConcurrentReads,
+				Limiter:           q.concurrencyLimiter,
 				ThrottlingEnabled: q.datastoreThrottlingEnabled,
 				ThrottleThreshold: q.datastoreThrottleThreshold,
 				ThrottleDuration:  q.datastoreThrottleDuration,
@@ -344,16 +414,21 @@ func (q *ListObjectsQuery) evaluate(
 			},
 		)
 
-		reverseExpandQuery := reverseexpand.NewReverseExpandQuery(
-			ds,
-			typesys,
+		reverseExpandOpts := []reverseexpand.ReverseExpandQueryOption{
 			reverseexpand.WithResolveNodeLimit(q.resolveNodeLimit),
 			reverseexpand.WithDispatchThrottlerConfig(q.dispatchThrottlerConfig),
 			reverseexpand.WithResolveNodeBreadthLimit(q.resolveNodeBreadthLimit),
 			reverseexpand.WithLogger(q.logger),
 			reverseexpand.WithCheckResolver(q.checkResolver),
 			reverseexpand.WithListObjectOptimizationsEnabled(q.optimizationsEnabled),
-		)
+			reverseexpand.WithResumeAfter(q.resumeAfter),
+		}
+		if q.hasObjectIDFilter() {
+			reverseExpandOpts = append(reverseExpandOpts,
+				reverseexpand.WithObjectIDFilter(targetObjectType, q.candidateObjectIDs, q.objectIDPrefix))
+		}
+
+		reverseExpandQuery := reverseexpand.NewReverseExpandQuery(ds, typesys, reverseExpandOpts...)
 
 		reverseExpandDoneWithError := make(chan struct{}, 1)
 		cancelCtx, cancel := context.WithCancel(ctx)
@@ -420,6 +495,7 @@ func (q *ListObjectsQuery) evaluate(
 					resp, checkRequestMetadata, err := NewCheckCommand(q.datastore, q.checkResolver, typesys,
 						WithCheckCommandLogger(q.logger),
 						WithCheckCommandMaxConcurrentReads(q.maxConcurrentReads),
+						WithCheckCommandConcurrencyLimiter(q.concurrencyLimiter),
 						WithCheckDatastoreThrottler(
 							q.datastoreThrottlingEnabled,
 							q.datastoreThrottleThreshold,
@@ -483,6 +559,53 @@ func trySendObject(ctx context.Context, object string, objectsFound *atomic.Uint
 func (q *ListObjectsQuery) Execute(
 	ctx context.Context,
 	req *openfgav1.ListObjectsRequest,
+) (*ListObjectsResponse, error) {
+	typesys, ok := typesystem.TypesystemFromContext(ctx)
+	if !ok || !q.shouldCacheResults(req) {
+		return q.execute(ctx, req)
+	}
+
+	cacheKey, err := listObjectsCacheKey(req)
+	if err != nil {
+		return q.execute(ctx, req)
+	}
+
+	if q.sharedDatastoreResources.CacheController != nil {
+		// Kick off background job to check if cache records are stale, invalidating where needed
+		q.sharedDatastoreResources.CacheController.InvalidateIfNeeded(ctx, req.GetStoreId())
+	}
+
+	maxResults := q.listObjectsMaxResults
+	if objects, ok := q.getCachedResults(ctx, typesys, req, cacheKey); ok {
+		if maxResults > 0 && uint32(len(objects)) > maxResults {
+			objects = objects[:maxResults]
+		}
+		return &ListObjectsResponse{Objects: slices.Clone(objects)}, nil
+	}
+
+	// the results cached before a change are invalidated by the changes of the tuples read after it
+	lastModified := time.Now()
+	res, err := q.execute(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// only the complete results are cached, i.e. the results that were not cut by the max results or the deadline
+	truncated := maxResults > 0 && uint32(len(res.Objects)) >= maxResults
+	timedOut := q.listObjectsDeadline != 0 && time.Since(lastModified) >= q.listObjectsDeadline
+	if !truncated && !timedOut {
+		q.sharedDatastoreResources.CheckCache.Set(cacheKey, &storage.ListObjectsCacheEntry{
+			Objects:      slices.Clone(res.Objects),
+			LastModified: lastModified,
+		}, q.cacheSettings.ListObjectsQueryCacheTTL)
+	}
+
+	return res, nil
+}
+
+func (q *ListObjectsQuery) execute(
+	ctx context.Context,
+	req *openfgav1.ListObjectsRequest,
 ) (*ListObjectsResponse, error) {
 	maxResults := q.listObjectsMaxResults
 
@@ -528,6 +651,10 @@ func (q *ListObjectsQuery) Execute(
 		return nil, serverErrors.ValidationError(fmt.Errorf("invalid 'user' value: %s", err))
 	}
 
+	if q.candidateObjectIDs != nil && q.candidateObjectIDs.Size() == 0 {
+		return &ListObjectsResponse{Objects: []string{}}, nil
+	}
+
 	if req.GetConsistency() != openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY {
 		if q.cacheSettings.ShouldCacheListObjectsIterators() {
 			// Kick off background job to check if cache records are stale, invalidating where needed
@@ -547,6 +674,7 @@ func (q *ListObjectsQuery) Execute(
 			&storagewrappers.Operation{
 				Method:            apimethod.ListObjects,
 				Concurrency:       q.maxConcurrentReads,
+				Limiter:           q.concurrencyLimiter,
 				ThrottlingEnabled: q.datastoreThrottlingEnabled,
 				ThrottleThreshold: q.datastoreThrottleThreshold,
 				ThrottleDuration:  q.datastoreThrottleDuration,
@@ -567,7 +695,7 @@ func (q *ListObjectsQuery) Execute(
 			Preference: req.GetConsistency(),
 		}
 
-		pl := pipeline.New(backend)
+		pl := pipeline.New(backend, q.pipelineObjectIDFilter(targetObjectType)...)
 
 		var source pipeline.Source
 		var target pipeline.Target
@@ -708,6 +836,10 @@ func (q *ListObjectsQuery) ExecuteStreamed(ctx context.Context, req *openfgav1.S
 		return nil, serverErrors.ValidationError(fmt.Errorf("invalid 'user' value: %s", err))
 	}
 
+	if q.candidateObjectIDs != nil && q.candidateObjectIDs.Size() == 0 {
+		return &resolutionMetadata, nil
+	}
+
 	wgraph := typesys.GetWeightedGraph()
 
 	if wgraph != nil && q.pipelineEnabled {
@@ -717,6 +849,7 @@ func (q *ListObjectsQuery) ExecuteStreamed(ctx context.Context, req *openfgav1.S
 			&storagewrappers.Operation{
 				Method:            apimethod.ListObjects,
 				Concurrency:       q.maxConcurrentReads,
+				Limiter:           q.concurrencyLimiter,
 				ThrottlingEnabled: q.datastoreThrottlingEnabled,
 				ThrottleThreshold: q.datastoreThrottleThreshold,
 				ThrottleDuration:  q.datastoreThrottleDuration,
@@ -737,7 +870,7 @@ func (q *ListObjectsQuery) ExecuteStreamed(ctx context.Context, req *openfgav1.S
 			Preference: req.GetConsistency(),
 		}
 
-		pl := pipeline.New(backend)
+		pl := pipeline.New(backend, append(q.pipelineObjectIDFilter(targetObjectType), pipeline.WithResumeAfter(q.resumeAfter))...)
 
 		var source pipeline.Source
 		var target pipeline.Target
@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"context"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+var (
+	listObjectsCacheTotalCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "list_objects_cache_total_count",
+		Help:      "The total number of ListObjects requests that tried the ListObjects cache.",
+	})
+
+	listObjectsCacheHitCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "list_objects_cache_hit_count",
+		Help:      "The total number of cache hits for ListObjects.",
+	})
+
+	listObjectsCacheInvalidHit = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "list_objects_cache_invalid_hit_count",
+		Help:      "The total number of cache hits for ListObjects that were discarded because they were invalidated.",
+	})
+)
+
+// shouldCacheResults returns true if the results of the request can be cached. Requests with contextual tuples or
+// with an object ID filter are not cached, since their results are not shared with the other requests.
+func (q *ListObjectsQuery) shouldCacheResults(req *openfgav1.ListObjectsRequest) bool {
+	return q.cacheSettings.ShouldCacheListObjectsQueries() &&
+		q.sharedDatastoreResources != nil &&
+		q.sharedDatastoreResources.CheckCache != nil &&
+		!q.useShadowCache &&
+		req.GetConsistency() != openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY &&
+		len(req.GetContextualTuples().GetTupleKeys()) == 0 &&
+		!q.hasObjectIDFilter()
+}
+
+func listObjectsCacheKey(req *openfgav1.ListObjectsRequest) (string, error) {
+	var b strings.Builder
+	err := storage.WriteListObjectsCacheKey(&b, &storage.ListObjectsCacheKeyParams{
+		StoreID:              req.GetStoreId(),
+		AuthorizationModelID: req.GetAuthorizationModelId(),
+		ObjectType:           req.GetType(),
+		Relation:             req.GetRelation(),
+		User:                 req.GetUser(),
+		Context:              req.GetContext(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// getCachedResults returns the cached objects of the request, if they were not invalidated since they were cached.
+// The cache controller invalidates them when a tuple of one of the relations the requested relation is evaluated
+// with has changed, see typesystem.GetTupleRelations.
+func (q *ListObjectsQuery) getCachedResults(ctx context.Context, typesys *typesystem.TypeSystem, req *openfgav1.ListObjectsRequest, cacheKey string) ([]string, bool) {
+	span := trace.SpanFromContext(ctx)
+	cache := q.sharedDatastoreResources.CheckCache
+
+	listObjectsCacheTotalCounter.Inc()
+
+	cachedResp := cache.Get(cacheKey)
+	if cachedResp == nil {
+		return nil, false
+	}
+	entry, ok := cachedResp.(*storage.ListObjectsCacheEntry)
+	if !ok {
+		return nil, false
+	}
+
+	isValid := func() bool {
+		if invalid, ok := cache.Get(storage.GetInvalidListObjectsCacheKey(req.GetStoreId())).(*storage.InvalidEntityCacheEntry); ok &&
+			!entry.LastModified.After(invalid.LastModified) {
+			return false
+		}
+
+		relations, err := typesys.GetTupleRelations(req.GetType(), req.GetRelation())
+		if err != nil {
+			return false
+		}
+		for _, relation := range relations {
+			objectType, relationName := tuple.SplitObjectRelation(relation)
+			key := storage.GetInvalidListObjectsByObjectTypeRelationCacheKey(req.GetStoreId(), objectType, relationName)
+			if invalid, ok := cache.Get(key).(*storage.InvalidEntityCacheEntry); ok &&
+				!entry.LastModified.After(invalid.LastModified) {
+				return false
+			}
+		}
+		return true
+	}()
+
+	q.logger.Debug("ListObjects found cache key",
+		zap.String("store_id", req.GetStoreId()),
+		zap.String("authorization_model_id", req.GetAuthorizationModelId()),
+		zap.Bool("isValid", isValid))
+
+	span.SetAttributes(attribute.Bool("cached", isValid))
+	if !isValid {
+		listObjectsCacheInvalidHit.Inc()
+		return nil, false
+	}
+
+	listObjectsCacheHitCounter.Inc()
+	return entry.Objects, true
+}
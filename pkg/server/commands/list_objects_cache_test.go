@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/sync/singleflight"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/internal/mocks"
+	"github.com/openfga/openfga/internal/shared"
+	serverconfig "github.com/openfga/openfga/pkg/server/config"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	storagetest "github.com/openfga/openfga/pkg/storage/test"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestListObjectsQueryCache(t *testing.T) {
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	model := `
+		model
+			schema 1.1
+		type user
+		type group
+			relations
+				define member: [user]
+		type folder
+			relations
+				define viewer: [user]
+		type document
+			relations
+				define parent: [folder]
+				define viewer: [user] or viewer from parent
+	`
+	storeID, authModel := storagetest.BootstrapFGAStore(t, ds, model, []string{
+		"document:1#viewer@user:a",
+	})
+	typesys, err := typesystem.NewAndValidate(context.Background(), authModel)
+	require.NoError(t, err)
+	ctx := typesystem.ContextWithTypesystem(context.Background(), typesys)
+
+	checkResolver, checkResolverCloser, err := graph.NewOrderedCheckResolvers().Build()
+	require.NoError(t, err)
+	t.Cleanup(checkResolverCloser)
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	// the invalidations of the cache controller are simulated by the test
+	mockCacheController := mocks.NewMockCacheController(ctrl)
+	mockCacheController.EXPECT().InvalidateIfNeeded(gomock.Any(), storeID).AnyTimes()
+
+	cacheSettings := serverconfig.NewDefaultCacheSettings()
+	cacheSettings.ListObjectsQueryCacheEnabled = true
+	cacheSettings.ListObjectsQueryCacheTTL = time.Minute
+
+	sharedResources, err := shared.NewSharedDatastoreResources(ctx, &singleflight.Group{}, ds, cacheSettings,
+		shared.WithCacheController(mockCacheController))
+	require.NoError(t, err)
+	t.Cleanup(sharedResources.Close)
+
+	q, err := NewListObjectsQuery(ds, checkResolver, storeID, WithListObjectsCache(sharedResources, cacheSettings))
+	require.NoError(t, err)
+
+	req := &openfgav1.ListObjectsRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: authModel.GetId(),
+		Type:                 "document",
+		Relation:             "viewer",
+		User:                 "user:a",
+	}
+
+	listObjects := func(t *testing.T, req *openfgav1.ListObjectsRequest) []string {
+		res, err := q.Execute(ctx, req)
+		require.NoError(t, err)
+		return res.Objects
+	}
+
+	invalidate := func(objectType, relation string) {
+		sharedResources.CheckCache.Set(storage.GetInvalidListObjectsByObjectTypeRelationCacheKey(storeID, objectType, relation),
+			&storage.InvalidEntityCacheEntry{LastModified: time.Now()}, time.Minute)
+	}
+
+	write := func(t *testing.T, tk *openfgav1.TupleKey) {
+		require.NoError(t, ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{tk}))
+	}
+
+	require.Equal(t, []string{"document:1"}, listObjects(t, req))
+
+	write(t, tuple.NewTupleKey("document:2", "viewer", "user:a"))
+	require.Equal(t, []string{"document:1"}, listObjects(t, req))
+
+	t.Run("not_invalidated_by_unrelated_relations", func(t *testing.T) {
+		invalidate("group", "member")
+		invalidate("document", "owner")
+		require.Equal(t, []string{"document:1"}, listObjects(t, req))
+	})
+
+	t.Run("invalidated_by_the_relation", func(t *testing.T) {
+		invalidate("document", "viewer")
+		require.ElementsMatch(t, []string{"document:1", "document:2"}, listObjects(t, req))
+	})
+
+	t.Run("invalidated_by_the_relations_it_depends_on", func(t *testing.T) {
+		write(t, tuple.NewTupleKey("document:3", "parent", "folder:x"))
+		invalidate("document", "parent")
+		require.ElementsMatch(t, []string{"document:1", "document:2"}, listObjects(t, req))
+
+		write(t, tuple.NewTupleKey("folder:x", "viewer", "user:a"))
+		require.ElementsMatch(t, []string{"document:1", "document:2"}, listObjects(t, req))
+
+		invalidate("folder", "viewer")
+		require.ElementsMatch(t, []string{"document:1", "document:2", "document:3"}, listObjects(t, req))
+	})
+
+	t.Run("invalidated_by_the_store", func(t *testing.T) {
+		write(t, tuple.NewTupleKey("document:4", "viewer", "user:a"))
+		sharedResources.CheckCache.Set(storage.GetInvalidListObjectsCacheKey(storeID),
+			&storage.InvalidEntityCacheEntry{LastModified: time.Now()}, time.Minute)
+		require.ElementsMatch(t, []string{"document:1", "document:2", "document:3", "document:4"}, listObjects(t, req))
+	})
+
+	t.Run("not_cached_with_higher_consistency", func(t *testing.T) {
+		write(t, tuple.NewTupleKey("document:5", "viewer", "user:a"))
+		higherConsistencyReq := &openfgav1.ListObjectsRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: authModel.GetId(),
+			Type:                 "document",
+			Relation:             "viewer",
+			User:                 "user:a",
+			Consistency:          openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY,
+		}
+		require.ElementsMatch(t, []string{"document:1", "document:2", "document:3", "document:4", "document:5"}, listObjects(t, higherConsistencyReq))
+		require.ElementsMatch(t, []string{"document:1", "document:2", "document:3", "document:4"}, listObjects(t, req))
+	})
+}
@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/containers"
+	serverconfig "github.com/openfga/openfga/pkg/server/config"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+)
+
+// ListObjectsCountQuery counts the distinct objects of a ListObjects request as the traversal finds them, without
+// collecting them. Its exact count keeps a bounded number of objects in memory and spills the others to disk,
+// while its approximate count estimates the count with a HyperLogLog in constant memory, which counts more objects
+// within the deadline.
+type ListObjectsCountQuery struct {
+	newResolver        ListObjectsResolverFactory
+	deadline           time.Duration
+	maxObjectsInMemory int
+}
+
+type ListObjectsCountQueryOption func(*ListObjectsCountQuery)
+
+// WithListObjectsCountDeadline sets the time after which the count stops, and returns the objects counted so far.
+// 0 counts every object.
+func WithListObjectsCountDeadline(deadline time.Duration) ListObjectsCountQueryOption {
+	return func(q *ListObjectsCountQuery) {
+		q.deadline = deadline
+	}
+}
+
+// WithListObjectsCountMaxObjectsInMemory bounds the number of objects the exact count keeps in memory to count
+// each object once. The objects beyond it are spilled to temporary files. 0 keeps every object in memory.
+func WithListObjectsCountMaxObjectsInMemory(objects int) ListObjectsCountQueryOption {
+	return func(q *ListObjectsCountQuery) {
+		q.maxObjectsInMemory = objects
+	}
+}
+
+func NewListObjectsCountQuery(newResolver ListObjectsResolverFactory, opts ...ListObjectsCountQueryOption) *ListObjectsCountQuery {
+	q := &ListObjectsCountQuery{
+		newResolver: newResolver,
+		deadline:    serverconfig.DefaultListObjectsDeadline,
+	}
+
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+type ListObjectsCountResponse struct {
+	Count uint64
+
+	// Approximate is true when Count is an estimate.
+	Approximate bool
+
+	// Complete is false when the deadline stopped the traversal before it found every object, in which case Count
+	// only counts the objects found until then.
+	Complete bool
+
+	ResolutionMetadata *ListObjectsResolutionMetadata
+}
+
+// Execute counts the objects of the request, estimating the count if approximate.
+func (q *ListObjectsCountQuery) Execute(ctx context.Context, req *openfgav1.ListObjectsRequest, approximate bool) (*ListObjectsCountResponse, error) {
+	resolver, err := q.newResolver(
+		// the deadline of the count tells whether the count is complete
+		WithListObjectsDeadline(0),
+	)
+	if err != nil {
+		return nil, serverErrors.NewInternalError("", err)
+	}
+
+	deadlineCtx := ctx
+	if q.deadline != 0 {
+		var cancel context.CancelFunc
+		deadlineCtx, cancel = context.WithTimeout(ctx, q.deadline)
+		defer cancel()
+	}
+
+	counter := containers.NewDistinctCounter(approximate, q.maxObjectsInMemory)
+	defer counter.Close()
+
+	resolutionMetadata, err := resolver.ExecuteStreamed(deadlineCtx, &openfgav1.StreamedListObjectsRequest{
+		StoreId:              req.GetStoreId(),
+		AuthorizationModelId: req.GetAuthorizationModelId(),
+		Type:                 req.GetType(),
+		Relation:             req.GetRelation(),
+		User:                 req.GetUser(),
+		ContextualTuples:     req.GetContextualTuples(),
+		Context:              req.GetContext(),
+		Consistency:          req.GetConsistency(),
+	}, &listObjectsCountCollector{ctx: deadlineCtx, counter: counter})
+
+	if ctx.Err() != nil {
+		return nil, serverErrors.HandleError("", ctx.Err())
+	}
+
+	// the traversal stops silently, or with an error, at the deadline
+	complete := deadlineCtx.Err() == nil
+	if err != nil && complete {
+		return nil, err
+	}
+
+	if resolutionMetadata == nil {
+		resolutionMetadata = &ListObjectsResolutionMetadata{}
+	}
+
+	return &ListObjectsCountResponse{
+		Count:              counter.Count(),
+		Approximate:        counter.Approximate(),
+		Complete:           complete,
+		ResolutionMetadata: resolutionMetadata,
+	}, nil
+}
+
+// listObjectsCountCollector receives the objects streamed by a resolver and counts them.
+type listObjectsCountCollector struct {
+	grpc.ServerStream
+
+	ctx     context.Context
+	counter containers.DistinctCounter
+}
+
+var _ openfgav1.OpenFGAService_StreamedListObjectsServer = (*listObjectsCountCollector)(nil)
+
+func (c *listObjectsCountCollector) Context() context.Context {
+	return c.ctx
+}
+
+func (c *listObjectsCountCollector) Send(res *openfgav1.StreamedListObjectsResponse) error {
+	return c.counter.Add(res.GetObject())
+}
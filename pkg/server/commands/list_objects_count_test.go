@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/graph"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	storagetest "github.com/openfga/openfga/pkg/storage/test"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestListObjectsCountQuery(t *testing.T) {
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	model := `
+		model
+			schema 1.1
+		type user
+		type folder
+			relations
+				define viewer: [user]
+		type document
+			relations
+				define parent: [folder]
+				define viewer: [user] or viewer from parent
+	`
+	var tuples []string
+	for i := 0; i < 40; i++ {
+		tuples = append(tuples, fmt.Sprintf("document:%d#parent@folder:x", i))
+	}
+	// document:0 is found through both branches of the union
+	tuples = append(tuples, "document:0#viewer@user:a", "document:40#viewer@user:a", "folder:x#viewer@user:a", "document:41#viewer@user:b")
+
+	storeID, authModel := storagetest.BootstrapFGAStore(t, ds, model, tuples)
+	typesys, err := typesystem.NewAndValidate(context.Background(), authModel)
+	require.NoError(t, err)
+	ctx := typesystem.ContextWithTypesystem(context.Background(), typesys)
+
+	checkResolver, checkResolverCloser, err := graph.NewOrderedCheckResolvers().Build()
+	require.NoError(t, err)
+	t.Cleanup(checkResolverCloser)
+
+	req := &openfgav1.ListObjectsRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: authModel.GetId(),
+		Type:                 "document",
+		Relation:             "viewer",
+		User:                 "user:a",
+	}
+
+	for _, pipelineEnabled := range []bool{false, true} {
+		for _, approximate := range []bool{false, true} {
+			t.Run(fmt.Sprintf("pipeline_%t_approximate_%t", pipelineEnabled, approximate), func(t *testing.T) {
+				q := NewListObjectsCountQuery(func(opts ...ListObjectsQueryOption) (ListObjectsResolver, error) {
+					return NewListObjectsQuery(ds, checkResolver, storeID, append([]ListObjectsQueryOption{
+						WithListObjectsPipelineEnabled(pipelineEnabled),
+						// the count is not bounded by the max results of ListObjects
+						WithListObjectsMaxResults(5),
+					}, opts...)...)
+				}, WithListObjectsCountMaxObjectsInMemory(4), WithListObjectsCountDeadline(10*time.Second))
+
+				res, err := q.Execute(ctx, req, approximate)
+				require.NoError(t, err)
+				require.Equal(t, uint64(41), res.Count)
+				require.Equal(t, approximate, res.Approximate)
+				require.True(t, res.Complete)
+				require.NotNil(t, res.ResolutionMetadata)
+			})
+		}
+	}
+
+	t.Run("canceled_request", func(t *testing.T) {
+		q := NewListObjectsCountQuery(func(opts ...ListObjectsQueryOption) (ListObjectsResolver, error) {
+			return NewListObjectsQuery(ds, checkResolver, storeID, opts...)
+		})
+
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := q.Execute(ctx, req, false)
+		require.ErrorIs(t, err, serverErrors.ErrRequestCancelled)
+	})
+}
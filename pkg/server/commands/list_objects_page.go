@@ -0,0 +1,208 @@
+package commands
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"google.golang.org/grpc"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/containers"
+	"github.com/openfga/openfga/pkg/encoder"
+	serverconfig "github.com/openfga/openfga/pkg/server/config"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+)
+
+// ListObjectsResolverFactory builds the resolver of a ListObjects request, with the provided options applied
+// after the ones of the caller.
+type ListObjectsResolverFactory func(opts ...ListObjectsQueryOption) (ListObjectsResolver, error)
+
+// ListObjectsPageQuery returns the objects of a ListObjects request page by page, in ascending order. Its
+// continuation tokens encode the last object of the previous page, so every page resumes the traversal after it
+// and the pages neither overlap nor miss objects, whatever the order in which the traversal finds them.
+//
+// Every page traverses the graph of the request keeping in memory only the objects of the page, so it is not bounded
+// by the ListObjects deadline but by the deadline of the request. When the objects of the type are never the users
+// of a tuple, the tuples of the objects of the previous pages are skipped when read, so a page reads only the
+// objects that follow the token. Otherwise every page traverses the whole graph, since the objects after the token
+// may be reached through the ones before it.
+type ListObjectsPageQuery struct {
+	newResolver     ListObjectsResolverFactory
+	maxPageSize     uint32
+	encoder         encoder.Encoder
+	tokenSerializer encoder.ContinuationTokenSerializer
+}
+
+type ListObjectsPageQueryOption func(*ListObjectsPageQuery)
+
+// WithListObjectsMaxPageSize sets the maximum number of objects of a page, which is also the size of the pages
+// of the requests that don't set one.
+func WithListObjectsMaxPageSize(size uint32) ListObjectsPageQueryOption {
+	return func(q *ListObjectsPageQuery) {
+		q.maxPageSize = size
+	}
+}
+
+func WithListObjectsPageQueryEncoder(e encoder.Encoder) ListObjectsPageQueryOption {
+	return func(q *ListObjectsPageQuery) {
+		q.encoder = e
+	}
+}
+
+func WithListObjectsPageQueryTokenSerializer(serializer encoder.ContinuationTokenSerializer) ListObjectsPageQueryOption {
+	return func(q *ListObjectsPageQuery) {
+		q.tokenSerializer = serializer
+	}
+}
+
+func NewListObjectsPageQuery(newResolver ListObjectsResolverFactory, opts ...ListObjectsPageQueryOption) *ListObjectsPageQuery {
+	q := &ListObjectsPageQuery{
+		newResolver:     newResolver,
+		maxPageSize:     serverconfig.DefaultListObjectsMaxResults,
+		encoder:         encoder.NewBase64Encoder(),
+		tokenSerializer: encoder.NewStringContinuationTokenSerializer(),
+	}
+
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+type ListObjectsPageResponse struct {
+	Objects []string
+
+	// ContinuationToken is empty on the last page.
+	ContinuationToken  string
+	ResolutionMetadata *ListObjectsResolutionMetadata
+}
+
+// Execute returns the page of at most pageSize objects that follows the continuation token, or the first page if
+// the token is empty. A pageSize of 0 returns pages of the maximum page size.
+func (q *ListObjectsPageQuery) Execute(ctx context.Context, req *openfgav1.ListObjectsRequest, pageSize uint32, continuationToken string) (*ListObjectsPageResponse, error) {
+	if pageSize > q.maxPageSize {
+		return nil, serverErrors.ValidationError(fmt.Errorf("page size must be at most %d", q.maxPageSize))
+	}
+	if pageSize == 0 {
+		pageSize = q.maxPageSize
+	}
+
+	after, err := q.decodeContinuationToken(req, continuationToken)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver, err := q.newResolver(
+		WithListObjectsResumeAfter(after),
+		// the pages must traverse the whole graph to return the smallest objects
+		WithListObjectsDeadline(0),
+	)
+	if err != nil {
+		return nil, serverErrors.NewInternalError("", err)
+	}
+
+	// one more object than the page tells whether there is a next page
+	collector := newListObjectsPageCollector(ctx, int(pageSize)+1)
+	resolutionMetadata, err := resolver.ExecuteStreamed(ctx, &openfgav1.StreamedListObjectsRequest{
+		StoreId:              req.GetStoreId(),
+		AuthorizationModelId: req.GetAuthorizationModelId(),
+		Type:                 req.GetType(),
+		Relation:             req.GetRelation(),
+		User:                 req.GetUser(),
+		ContextualTuples:     req.GetContextualTuples(),
+		Context:              req.GetContext(),
+		Consistency:          req.GetConsistency(),
+	}, collector)
+	if err != nil {
+		return nil, err
+	}
+
+	// the traversal stops silently when the request is done, which would return a page missing some objects
+	if ctx.Err() != nil {
+		return nil, serverErrors.HandleError("", ctx.Err())
+	}
+
+	if resolutionMetadata == nil {
+		resolutionMetadata = &ListObjectsResolutionMetadata{}
+	}
+
+	res := &ListObjectsPageResponse{
+		Objects:            collector.objects.Sorted(),
+		ResolutionMetadata: resolutionMetadata,
+	}
+
+	if len(res.Objects) > int(pageSize) {
+		res.Objects = res.Objects[:pageSize]
+		res.ContinuationToken, err = q.encodeContinuationToken(req, res.Objects[pageSize-1])
+		if err != nil {
+			return nil, serverErrors.HandleError("", err)
+		}
+	}
+
+	return res, nil
+}
+
+// decodeContinuationToken returns the last object of the previous page. The object is base64 encoded in the
+// token, since the object IDs may contain the separators of the token serializer.
+func (q *ListObjectsPageQuery) decodeContinuationToken(req *openfgav1.ListObjectsRequest, continuationToken string) (string, error) {
+	decodedContToken, err := q.encoder.Decode(continuationToken)
+	if err != nil {
+		return "", serverErrors.ErrInvalidContinuationToken
+	}
+
+	if len(decodedContToken) == 0 {
+		return "", nil
+	}
+
+	from, objType, err := q.tokenSerializer.Deserialize(string(decodedContToken))
+	if err != nil || objType != listObjectsPageTokenType(req) {
+		return "", serverErrors.ErrInvalidContinuationToken
+	}
+
+	after, err := base64.RawURLEncoding.DecodeString(from)
+	if err != nil || len(after) == 0 {
+		return "", serverErrors.ErrInvalidContinuationToken
+	}
+	return string(after), nil
+}
+
+func (q *ListObjectsPageQuery) encodeContinuationToken(req *openfgav1.ListObjectsRequest, last string) (string, error) {
+	contToken, err := q.tokenSerializer.Serialize(base64.RawURLEncoding.EncodeToString([]byte(last)), listObjectsPageTokenType(req))
+	if err != nil {
+		return "", err
+	}
+	return q.encoder.Encode(contToken)
+}
+
+// listObjectsPageTokenType ties the continuation tokens to the type and relation of their request.
+func listObjectsPageTokenType(req *openfgav1.ListObjectsRequest) string {
+	return req.GetType() + "#" + req.GetRelation()
+}
+
+// listObjectsPageCollector receives the objects streamed by a resolver and keeps the smallest ones.
+type listObjectsPageCollector struct {
+	grpc.ServerStream
+
+	ctx     context.Context
+	objects *containers.Smallest
+}
+
+var _ openfgav1.OpenFGAService_StreamedListObjectsServer = (*listObjectsPageCollector)(nil)
+
+func newListObjectsPageCollector(ctx context.Context, size int) *listObjectsPageCollector {
+	return &listObjectsPageCollector{
+		ctx:     ctx,
+		objects: containers.NewSmallest(size),
+	}
+}
+
+func (c *listObjectsPageCollector) Context() context.Context {
+	return c.ctx
+}
+
+func (c *listObjectsPageCollector) Send(res *openfgav1.StreamedListObjectsResponse) error {
+	c.objects.Add(res.GetObject())
+	return nil
+}
@@ -0,0 +1,180 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/graph"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	storagetest "github.com/openfga/openfga/pkg/storage/test"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestListObjectsPageQuery(t *testing.T) {
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	model := `
+		model
+			schema 1.1
+		type user
+		type folder
+			relations
+				define viewer: [user]
+		type document
+			relations
+				define parent: [folder]
+				define viewer: [user] or viewer from parent
+	`
+	var tuples []string
+	var expected []string
+	for i := 0; i < 7; i++ {
+		tuples = append(tuples, fmt.Sprintf("document:%d#parent@folder:x", i))
+		expected = append(expected, fmt.Sprintf("document:%d", i))
+	}
+	tuples = append(tuples, "document:7#viewer@user:a", "folder:x#viewer@user:a", "document:8#viewer@user:b")
+	expected = append(expected, "document:7")
+
+	storeID, authModel := storagetest.BootstrapFGAStore(t, ds, model, tuples)
+	typesys, err := typesystem.NewAndValidate(context.Background(), authModel)
+	require.NoError(t, err)
+	ctx := typesystem.ContextWithTypesystem(context.Background(), typesys)
+
+	checkResolver, checkResolverCloser, err := graph.NewOrderedCheckResolvers().Build()
+	require.NoError(t, err)
+	t.Cleanup(checkResolverCloser)
+
+	req := &openfgav1.ListObjectsRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: authModel.GetId(),
+		Type:                 "document",
+		Relation:             "viewer",
+		User:                 "user:a",
+	}
+
+	for _, pipelineEnabled := range []bool{false, true} {
+		t.Run(fmt.Sprintf("pipeline_%t", pipelineEnabled), func(t *testing.T) {
+			q := NewListObjectsPageQuery(func(opts ...ListObjectsQueryOption) (ListObjectsResolver, error) {
+				return NewListObjectsQuery(ds, checkResolver, storeID, append([]ListObjectsQueryOption{
+					WithListObjectsPipelineEnabled(pipelineEnabled),
+				}, opts...)...)
+			}, WithListObjectsMaxPageSize(5))
+
+			var objects []string
+			var pages int
+			token := ""
+			for {
+				res, err := q.Execute(ctx, req, 3, token)
+				require.NoError(t, err)
+				require.LessOrEqual(t, len(res.Objects), 3)
+				objects = append(objects, res.Objects...)
+				pages++
+
+				token = res.ContinuationToken
+				if token == "" {
+					break
+				}
+			}
+			require.Equal(t, expected, objects)
+			require.Equal(t, 3, pages)
+
+			t.Run("default_page_size", func(t *testing.T) {
+				res, err := q.Execute(ctx, req, 0, "")
+				require.NoError(t, err)
+				require.Equal(t, expected[:5], res.Objects)
+				require.NotEmpty(t, res.ContinuationToken)
+			})
+
+			t.Run("reads_only_the_objects_after_the_token", func(t *testing.T) {
+				res, err := q.Execute(ctx, req, 3, "")
+				require.NoError(t, err)
+
+				recorder := &readStartingWithUserRecorder{OpenFGADatastore: ds}
+				q := NewListObjectsPageQuery(func(opts ...ListObjectsQueryOption) (ListObjectsResolver, error) {
+					return NewListObjectsQuery(recorder, checkResolver, storeID, append([]ListObjectsQueryOption{
+						WithListObjectsPipelineEnabled(pipelineEnabled),
+					}, opts...)...)
+				}, WithListObjectsMaxPageSize(5))
+
+				res, err = q.Execute(ctx, req, 3, res.ContinuationToken)
+				require.NoError(t, err)
+				require.Equal(t, expected[3:6], res.Objects)
+
+				var documentReads int
+				for _, filter := range recorder.filters() {
+					if filter.ObjectType == "document" {
+						documentReads++
+						require.Equal(t, "2", filter.ObjectIDAfter)
+					}
+				}
+				require.Positive(t, documentReads)
+			})
+		})
+	}
+
+	q := NewListObjectsPageQuery(func(opts ...ListObjectsQueryOption) (ListObjectsResolver, error) {
+		return NewListObjectsQuery(ds, checkResolver, storeID, opts...)
+	}, WithListObjectsMaxPageSize(5))
+
+	t.Run("page_size_above_the_maximum", func(t *testing.T) {
+		_, err := q.Execute(ctx, req, 6, "")
+		require.ErrorContains(t, err, "page size must be at most 5")
+	})
+
+	t.Run("invalid_continuation_token", func(t *testing.T) {
+		_, err := q.Execute(ctx, req, 3, "not a token")
+		require.ErrorIs(t, err, serverErrors.ErrInvalidContinuationToken)
+	})
+
+	t.Run("continuation_token_of_another_relation", func(t *testing.T) {
+		res, err := q.Execute(ctx, req, 3, "")
+		require.NoError(t, err)
+
+		_, err = q.Execute(ctx, &openfgav1.ListObjectsRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: authModel.GetId(),
+			Type:                 "document",
+			Relation:             "parent",
+			User:                 "folder:x",
+		}, 3, res.ContinuationToken)
+		require.ErrorIs(t, err, serverErrors.ErrInvalidContinuationToken)
+	})
+
+	t.Run("canceled_request", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := q.Execute(ctx, req, 3, "")
+		require.ErrorIs(t, err, serverErrors.ErrRequestCancelled)
+	})
+}
+
+// readStartingWithUserRecorder records the filters of the ReadStartingWithUser calls.
+type readStartingWithUserRecorder struct {
+	storage.OpenFGADatastore
+
+	mu   sync.Mutex
+	seen []storage.ReadStartingWithUserFilter
+}
+
+func (r *readStartingWithUserRecorder) ReadStartingWithUser(ctx context.Context, store string, filter storage.ReadStartingWithUserFilter, options storage.ReadStartingWithUserOptions) (storage.TupleIterator, error) {
+	r.mu.Lock()
+	r.seen = append(r.seen, filter)
+	r.mu.Unlock()
+	return r.OpenFGADatastore.ReadStartingWithUser(ctx, store, filter, options)
+}
+
+func (r *readStartingWithUserRecorder) filters() []storage.ReadStartingWithUserFilter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.seen)
+}
@@ -0,0 +1,428 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/internal/planner"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+const ListObjectsPlannedExecute = "PlannedListObjectsQuery.Execute"
+
+// The strategies that the planner chooses from to resolve a ListObjects request.
+const (
+	// ListObjectsStrategyClassic resolves the request with reverse expansion.
+	ListObjectsStrategyClassic = "classic"
+	// ListObjectsStrategyWeighted resolves the request with reverse expansion optimized by the weighted graph.
+	ListObjectsStrategyWeighted = "weighted"
+	// ListObjectsStrategyPipeline resolves the request with the pipeline built from the weighted graph.
+	ListObjectsStrategyPipeline = "pipeline"
+)
+
+var listObjectsStrategyPlans = map[string]*planner.PlanConfig{
+	ListObjectsStrategyClassic: {
+		Name:         ListObjectsStrategyClassic,
+		InitialGuess: 100 * time.Millisecond,
+		// Low confidence in the initial guesses of every strategy, so that all of them are explored.
+		Lambda: 1,
+		Alpha:  0.5,
+		Beta:   0.5,
+	},
+	ListObjectsStrategyWeighted: {
+		Name:         ListObjectsStrategyWeighted,
+		InitialGuess: 80 * time.Millisecond,
+		Lambda:       1,
+		Alpha:        0.5,
+		Beta:         0.5,
+	},
+	ListObjectsStrategyPipeline: {
+		Name:         ListObjectsStrategyPipeline,
+		InitialGuess: 50 * time.Millisecond,
+		Lambda:       1,
+		Alpha:        0.5,
+		Beta:         0.5,
+	},
+}
+
+var listObjectsPlannerValidationCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: build.ProjectName,
+	Name:      "list_objects_planner_validation_count",
+	Help:      "The total number of shadow validations of ListObjects strategies chosen by the planner, labeled by strategy and result (match, mismatch, error or incomplete).",
+}, []string{"strategy", "result"})
+
+type plannedListObjectsQuery struct {
+	// baseline is configured by the static settings and feature flags, its strategy is always trusted.
+	baseline   *ListObjectsQuery
+	strategies map[string]ListObjectsResolver
+
+	planner       planner.Manager
+	trust         *planner.Trust
+	shadowTimeout time.Duration
+	maxDeltaItems int
+	logger        logger.Logger
+
+	// validating holds the key and strategy of the validations in flight, so that a strategy is validated
+	// by at most one request at a time.
+	validating sync.Map
+	// only used for testing signals
+	wg *sync.WaitGroup
+}
+
+var _ ListObjectsResolver = (*plannedListObjectsQuery)(nil)
+
+type ListObjectsPlannerOption func(c *ListObjectsPlannerConfig)
+
+// WithListObjectsPlannerShadowTimeout sets the maximum amount of time to wait for the validation of a strategy.
+func WithListObjectsPlannerShadowTimeout(timeout time.Duration) ListObjectsPlannerOption {
+	return func(c *ListObjectsPlannerConfig) {
+		c.shadowTimeout = timeout
+	}
+}
+
+func WithListObjectsPlannerLogger(logger logger.Logger) ListObjectsPlannerOption {
+	return func(c *ListObjectsPlannerConfig) {
+		c.logger = logger
+	}
+}
+
+func WithListObjectsPlannerMaxDeltaItems(maxDeltaItems int) ListObjectsPlannerOption {
+	return func(c *ListObjectsPlannerConfig) {
+		c.maxDeltaItems = maxDeltaItems
+	}
+}
+
+// ListObjectsPlannerConfig configures the selection of the ListObjects strategy by a planner.
+type ListObjectsPlannerConfig struct {
+	planner       planner.Manager
+	trust         *planner.Trust
+	shadowTimeout time.Duration // The maximum amount of time to wait for the validation of a strategy. Validations that time out are ignored.
+	maxDeltaItems int           // The maximum number of items to log in the delta between the baseline and the validated strategy.
+	logger        logger.Logger
+}
+
+// NewListObjectsPlannerConfig returns the configuration of a planner that selects the ListObjects strategy
+// with p. The strategies other than the baseline are used only once trust trusts them.
+func NewListObjectsPlannerConfig(p planner.Manager, trust *planner.Trust, opts ...ListObjectsPlannerOption) *ListObjectsPlannerConfig {
+	result := &ListObjectsPlannerConfig{
+		planner:       p,
+		trust:         trust,
+		shadowTimeout: 1 * time.Second,
+		maxDeltaItems: 100,
+		logger:        logger.NewNoopLogger(),
+	}
+	for _, opt := range opts {
+		opt(result)
+	}
+	return result
+}
+
+// NewPlannedListObjectsQuery creates a new ListObjectsResolver that lets the planner of plannerConfig select the
+// strategy that resolves each request. The strategy selected by the static settings and feature flags (the baseline)
+// is always trusted. Another strategy is first validated in shadow: the baseline answers the request and the
+// results of the selected strategy are compared to its results, until the strategy is trusted or rejected.
+func NewPlannedListObjectsQuery(
+	ds storage.RelationshipTupleReader,
+	checkResolver graph.CheckResolver,
+	plannerConfig *ListObjectsPlannerConfig,
+	storeID string,
+	opts ...ListObjectsQueryOption,
+) (ListObjectsResolver, error) {
+	if plannerConfig == nil || plannerConfig.planner == nil || plannerConfig.trust == nil {
+		return nil, errors.New("plannerConfig must be set with a planner and a trust")
+	}
+
+	baseline, err := NewListObjectsQuery(ds, checkResolver, storeID, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	strategies := make(map[string]ListObjectsResolver, len(listObjectsStrategyPlans))
+	for name := range listObjectsStrategyPlans {
+		strategy, err := NewListObjectsQuery(ds, checkResolver, storeID,
+			// the strategy is decided here and not by the feature flags
+			slices.Concat(opts, []ListObjectsQueryOption{
+				WithFeatureFlagClient(nil),
+				WithListObjectsOptimizationsEnabled(name == ListObjectsStrategyWeighted),
+				WithListObjectsPipelineEnabled(name == ListObjectsStrategyPipeline),
+			})...,
+		)
+		if err != nil {
+			return nil, err
+		}
+		strategies[name] = strategy
+	}
+
+	return &plannedListObjectsQuery{
+		baseline:      baseline,
+		strategies:    strategies,
+		planner:       plannerConfig.planner,
+		trust:         plannerConfig.trust,
+		shadowTimeout: plannerConfig.shadowTimeout,
+		maxDeltaItems: plannerConfig.maxDeltaItems,
+		logger:        plannerConfig.logger,
+		wg:            &sync.WaitGroup{}, // only used for testing signals
+	}, nil
+}
+
+func (q *plannedListObjectsQuery) Execute(
+	ctx context.Context,
+	req *openfgav1.ListObjectsRequest,
+) (*ListObjectsResponse, error) {
+	typesys, ok := typesystem.TypesystemFromContext(ctx)
+	if !ok {
+		return q.baseline.Execute(ctx, req)
+	}
+
+	key := listObjectsPlanKey(req.GetAuthorizationModelId(), req.GetType(), req.GetRelation(), req.GetUser())
+	baseline, candidates := q.candidates(key, typesys)
+	if len(candidates) == 1 {
+		return q.strategies[baseline].Execute(ctx, req)
+	}
+
+	selector := q.planner.GetPlanSelector(key)
+	plan := selector.Select(candidates)
+
+	if plan.Name == baseline || q.trust.Trusted(key, plan.Name) {
+		start := time.Now()
+		res, err := q.strategies[plan.Name].Execute(ctx, req)
+		if err == nil {
+			selector.UpdateStats(plan, time.Since(start))
+		}
+		return res, err
+	}
+
+	// the selected strategy is not trusted yet: answer with the baseline, and validate the strategy in shadow
+	start := time.Now()
+	res, err := q.strategies[baseline].Execute(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	latency := time.Since(start)
+	selector.UpdateStats(candidates[baseline], latency)
+
+	validationKey := key + "|" + plan.Name
+	if _, inFlight := q.validating.LoadOrStore(validationKey, struct{}{}); inFlight {
+		return res, nil
+	}
+
+	cloneCtx := context.WithoutCancel(ctx) // needs typesystem and datastore etc
+	q.wg.Add(1)                            // only used for testing signals
+	go func() {
+		defer q.wg.Done() // only used for testing signals
+		defer q.validating.Delete(validationKey)
+		defer func() {
+			if r := recover(); r != nil {
+				q.logger.ErrorWithContext(cloneCtx, "panic recovered",
+					loPlannedLogFields(req, plan.Name, zap.Any("error", r))...,
+				)
+			}
+		}()
+
+		q.validate(cloneCtx, req, key, selector, plan, res, latency)
+	}()
+
+	return res, nil
+}
+
+// ExecuteStreamed resolves the request with a trusted strategy. Streamed requests don't validate strategies,
+// nor teach the planner, since their latency depends on the client consuming the stream.
+func (q *plannedListObjectsQuery) ExecuteStreamed(ctx context.Context, req *openfgav1.StreamedListObjectsRequest, srv openfgav1.OpenFGAService_StreamedListObjectsServer) (*ListObjectsResolutionMetadata, error) {
+	typesys, ok := typesystem.TypesystemFromContext(ctx)
+	if !ok {
+		return q.baseline.ExecuteStreamed(ctx, req, srv)
+	}
+
+	key := listObjectsPlanKey(req.GetAuthorizationModelId(), req.GetType(), req.GetRelation(), req.GetUser())
+	baseline, candidates := q.candidates(key, typesys)
+	maps.DeleteFunc(candidates, func(name string, _ *planner.PlanConfig) bool {
+		return name != baseline && !q.trust.Trusted(key, name)
+	})
+	if len(candidates) == 1 {
+		return q.strategies[baseline].ExecuteStreamed(ctx, req, srv)
+	}
+
+	plan := q.planner.GetPlanSelector(key).Select(candidates)
+	return q.strategies[plan.Name].ExecuteStreamed(ctx, req, srv)
+}
+
+// candidates returns the strategy of the baseline and the strategies that can resolve the requests of key,
+// which excludes the strategies that need a weighted graph when the model has none, and the rejected strategies.
+func (q *plannedListObjectsQuery) candidates(key string, typesys *typesystem.TypeSystem) (string, map[string]*planner.PlanConfig) {
+	hasWeightedGraph := typesys.GetWeightedGraph() != nil
+
+	baseline := ListObjectsStrategyClassic
+	switch {
+	case q.baseline.pipelineEnabled && hasWeightedGraph:
+		baseline = ListObjectsStrategyPipeline
+	case q.baseline.optimizationsEnabled:
+		baseline = ListObjectsStrategyWeighted
+	}
+
+	candidates := map[string]*planner.PlanConfig{
+		baseline: listObjectsStrategyPlans[baseline],
+	}
+	if !hasWeightedGraph {
+		// the optimizations of reverse expansion fall back to the classic algorithm without a weighted graph
+		return baseline, candidates
+	}
+
+	for name, plan := range listObjectsStrategyPlans {
+		if name != baseline && !q.trust.Rejected(key, name) {
+			candidates[name] = plan
+		}
+	}
+	return baseline, candidates
+}
+
+// validate resolves req with the strategy of plan, and compares its results to the ones of the baseline. The
+// strategy is rejected if they differ, and gets closer to being trusted otherwise. Validations that can't compare
+// complete results, because either strategy reached the maximum number of results or timed out, are ignored.
+func (q *plannedListObjectsQuery) validate(
+	ctx context.Context,
+	req *openfgav1.ListObjectsRequest,
+	key string,
+	selector planner.Selector,
+	plan *planner.PlanConfig,
+	baselineRes *ListObjectsResponse,
+	baselineLatency time.Duration,
+) {
+	ctx, span := tracer.Start(ctx, "validatePlannedStrategy", trace.WithAttributes(
+		attribute.String("strategy", plan.Name),
+	))
+	defer span.End()
+
+	deadline := q.baseline.listObjectsDeadline
+	maxResults := int(q.baseline.listObjectsMaxResults)
+
+	shadowCtx, shadowCancel := context.WithTimeout(ctx, q.shadowTimeout)
+	defer shadowCancel()
+
+	start := time.Now()
+	res, err := q.strategies[plan.Name].Execute(shadowCtx, req)
+	latency := time.Since(start)
+
+	fields := []zap.Field{
+		zap.Duration("baseline_latency", baselineLatency),
+		zap.Duration("strategy_latency", latency),
+		zap.Int("baseline_result_count", len(baselineRes.Objects)),
+	}
+
+	timedOut := shadowCtx.Err() != nil || errors.Is(err, context.DeadlineExceeded) ||
+		(deadline != 0 && latency >= deadline)
+	if timedOut {
+		// the strategy is at least this slow, which the planner must learn
+		selector.UpdateStats(plan, latency)
+		listObjectsPlannerValidationCounter.WithLabelValues(plan.Name, "incomplete").Inc()
+		q.logger.InfoWithContext(ctx, "planned list objects strategy validation timed out",
+			loPlannedLogFields(req, plan.Name, fields...)...,
+		)
+		return
+	}
+
+	if err != nil {
+		// the strategy fails where the baseline succeeds
+		q.trust.Record(key, plan.Name, false)
+		listObjectsPlannerValidationCounter.WithLabelValues(plan.Name, "error").Inc()
+		q.logger.WarnWithContext(ctx, "planned list objects strategy rejected after an error",
+			loPlannedLogFields(req, plan.Name, append(fields, zap.Error(err))...)...,
+		)
+		return
+	}
+
+	selector.UpdateStats(plan, latency)
+
+	baselineComplete := (maxResults == 0 || len(baselineRes.Objects) < maxResults) &&
+		(deadline == 0 || baselineLatency < deadline)
+	if !baselineComplete || (maxResults != 0 && len(res.Objects) >= maxResults) {
+		listObjectsPlannerValidationCounter.WithLabelValues(plan.Name, "incomplete").Inc()
+		return
+	}
+
+	baselineObjects := keyMapFromSlice(baselineRes.Objects)
+	objects := keyMapFromSlice(res.Objects)
+	fields = append(fields, zap.Int("strategy_result_count", len(res.Objects)))
+
+	if maps.Equal(baselineObjects, objects) {
+		span.SetAttributes(attribute.Bool("matches", true))
+		q.trust.Record(key, plan.Name, true)
+		listObjectsPlannerValidationCounter.WithLabelValues(plan.Name, "match").Inc()
+		q.logger.DebugWithContext(ctx, "planned list objects strategy matches the baseline",
+			loPlannedLogFields(req, plan.Name, fields...)...,
+		)
+		return
+	}
+
+	span.SetAttributes(attribute.Bool("matches", false))
+	q.trust.Record(key, plan.Name, false)
+	listObjectsPlannerValidationCounter.WithLabelValues(plan.Name, "mismatch").Inc()
+
+	delta := calculateDelta(baselineObjects, objects)
+	totalDelta := len(delta)
+	if totalDelta > q.maxDeltaItems {
+		delta = delta[:q.maxDeltaItems]
+	}
+	q.logger.WarnWithContext(ctx, "planned list objects strategy rejected after a result difference",
+		loPlannedLogFields(req, plan.Name, append(fields,
+			zap.Int("total_delta", totalDelta),
+			zap.Any("delta", delta),
+		)...)...,
+	)
+}
+
+// listObjectsPlanKey returns the key of the planner for the requests of the objects of objectType that user
+// has relation with in the model.
+func listObjectsPlanKey(modelID, objectType, relation, user string) string {
+	var b strings.Builder
+	b.WriteString("listobjects|")
+	b.WriteString(modelID)
+	b.WriteString("|")
+	b.WriteString(objectType)
+	b.WriteString("|")
+	b.WriteString(relation)
+	b.WriteString("|")
+	b.WriteString(planUserType(user))
+	return b.String()
+}
+
+// planUserType returns the type of user, followed by its relation if it is a userset, or by ":*" if it is a
+// typed wildcard.
+func planUserType(user string) string {
+	userObj, userRel := tuple.SplitObjectRelation(user)
+	userType := tuple.GetType(userObj)
+	if tuple.IsTypedWildcard(userObj) {
+		return tuple.TypedPublicWildcard(userType)
+	}
+	if userRel != "" {
+		return tuple.ToObjectRelationString(userType, userRel)
+	}
+	return userType
+}
+
+func loPlannedLogFields(req *openfgav1.ListObjectsRequest, strategy string, fields ...zap.Field) []zap.Field {
+	return append([]zap.Field{
+		zap.String("func", ListObjectsPlannedExecute),
+		zap.Any("request", req),
+		zap.String("store_id", req.GetStoreId()),
+		zap.String("model_id", req.GetAuthorizationModelId()),
+		zap.String("strategy", strategy),
+	}, fields...)
+}
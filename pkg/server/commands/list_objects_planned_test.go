@@ -0,0 +1,204 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	parser "github.com/openfga/language/pkg/go/transformer"
+
+	"github.com/openfga/openfga/internal/planner"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// fixedPlanner always selects the plan named name when it is a candidate, and records the plans it learns about.
+type fixedPlanner struct {
+	name string
+
+	mu      sync.Mutex
+	updated []string
+}
+
+var _ planner.Manager = (*fixedPlanner)(nil)
+
+func (p *fixedPlanner) GetPlanSelector(string) planner.Selector { return p }
+
+func (p *fixedPlanner) Stop() {}
+
+func (p *fixedPlanner) Select(candidates map[string]*planner.PlanConfig) *planner.PlanConfig {
+	if plan, ok := candidates[p.name]; ok {
+		return plan
+	}
+	return candidates[slices.Sorted(maps.Keys(candidates))[0]]
+}
+
+func (p *fixedPlanner) UpdateStats(plan *planner.PlanConfig, _ time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.updated = append(p.updated, plan.Name)
+}
+
+func TestPlannedListObjectsQuery_Execute(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	model := &openfgav1.AuthorizationModel{
+		Id:            ulid.Make().String(),
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: parser.MustTransformDSLToProto(`
+			model
+			schema 1.1
+			type user
+			type document
+				relations
+					define viewer: [user]
+		`).GetTypeDefinitions(),
+	}
+	ts, err := typesystem.New(model)
+	require.NoError(t, err)
+	require.NotNil(t, ts.GetWeightedGraph())
+
+	req := &openfgav1.ListObjectsRequest{
+		AuthorizationModelId: model.GetId(),
+		Type:                 "document",
+		Relation:             "viewer",
+		User:                 "user:anne",
+	}
+	key := listObjectsPlanKey(model.GetId(), "document", "viewer", "user:anne")
+
+	newQuery := func(t *testing.T, p planner.Manager, trust *planner.Trust, pipelineObjects []string, pipelineErr error) *plannedListObjectsQuery {
+		baseline, err := NewListObjectsQuery(&mockTupleReader{}, &mockCheckResolver{}, fakeStoreID, WithListObjectsMaxResults(10))
+		require.NoError(t, err)
+
+		resolver := func(objects []string, err error) ListObjectsResolver {
+			return &mockListObjectsQuery{
+				executeFunc: func(ctx context.Context, req *openfgav1.ListObjectsRequest) (*ListObjectsResponse, error) {
+					if err != nil {
+						return nil, err
+					}
+					return &ListObjectsResponse{Objects: objects}, nil
+				},
+			}
+		}
+
+		return &plannedListObjectsQuery{
+			baseline: baseline,
+			strategies: map[string]ListObjectsResolver{
+				ListObjectsStrategyClassic:  resolver([]string{"document:1", "document:2"}, nil),
+				ListObjectsStrategyWeighted: resolver([]string{"document:2", "document:1"}, nil),
+				ListObjectsStrategyPipeline: resolver(pipelineObjects, pipelineErr),
+			},
+			planner:       p,
+			trust:         trust,
+			shadowTimeout: 1 * time.Second,
+			maxDeltaItems: 100,
+			logger:        logger.NewNoopLogger(),
+			wg:            &sync.WaitGroup{},
+		}
+	}
+
+	ctx := typesystem.ContextWithTypesystem(context.Background(), ts)
+
+	t.Run("trusted_after_required_validations", func(t *testing.T) {
+		p := &fixedPlanner{name: ListObjectsStrategyWeighted}
+		trust := planner.NewTrust(2)
+		// the weighted strategy returns the objects in another order, which tells which strategy answered
+		q := newQuery(t, p, trust, nil, nil)
+
+		for range 2 {
+			res, err := q.Execute(ctx, req)
+			require.NoError(t, err)
+			require.Equal(t, []string{"document:1", "document:2"}, res.Objects) // answered by the baseline
+			q.wg.Wait()
+		}
+		require.True(t, trust.Trusted(key, ListObjectsStrategyWeighted))
+
+		res, err := q.Execute(ctx, req)
+		require.NoError(t, err)
+		require.Equal(t, []string{"document:2", "document:1"}, res.Objects) // answered by the trusted strategy
+		q.wg.Wait()
+
+		require.Equal(t, []string{
+			ListObjectsStrategyClassic, ListObjectsStrategyWeighted,
+			ListObjectsStrategyClassic, ListObjectsStrategyWeighted,
+			ListObjectsStrategyWeighted,
+		}, p.updated)
+	})
+
+	t.Run("rejected_after_mismatch", func(t *testing.T) {
+		p := &fixedPlanner{name: ListObjectsStrategyPipeline}
+		trust := planner.NewTrust(2)
+		q := newQuery(t, p, trust, []string{"document:1"}, nil)
+
+		res, err := q.Execute(ctx, req)
+		require.NoError(t, err)
+		require.Equal(t, []string{"document:1", "document:2"}, res.Objects)
+		q.wg.Wait()
+		require.True(t, trust.Rejected(key, ListObjectsStrategyPipeline))
+
+		_, candidates := q.candidates(key, ts)
+		require.NotContains(t, candidates, ListObjectsStrategyPipeline)
+	})
+
+	t.Run("rejected_after_error", func(t *testing.T) {
+		p := &fixedPlanner{name: ListObjectsStrategyPipeline}
+		trust := planner.NewTrust(2)
+		q := newQuery(t, p, trust, nil, errors.New("boom"))
+
+		_, err := q.Execute(ctx, req)
+		require.NoError(t, err)
+		q.wg.Wait()
+		require.True(t, trust.Rejected(key, ListObjectsStrategyPipeline))
+	})
+
+	t.Run("incomplete_results_are_not_compared", func(t *testing.T) {
+		p := &fixedPlanner{name: ListObjectsStrategyPipeline}
+		trust := planner.NewTrust(1)
+		q := newQuery(t, p, trust, []string{"document:1"}, nil)
+		q.baseline.listObjectsMaxResults = 2
+
+		_, err := q.Execute(ctx, req)
+		require.NoError(t, err)
+		q.wg.Wait()
+		require.False(t, trust.Rejected(key, ListObjectsStrategyPipeline))
+		require.False(t, trust.Trusted(key, ListObjectsStrategyPipeline))
+	})
+
+	t.Run("no_alternative_without_weighted_graph", func(t *testing.T) {
+		p := &fixedPlanner{name: ListObjectsStrategyPipeline}
+		q := newQuery(t, p, planner.NewTrust(1), nil, nil)
+
+		tsWithoutGraph, err := typesystem.New(&openfgav1.AuthorizationModel{
+			Id:            ulid.Make().String(),
+			SchemaVersion: typesystem.SchemaVersion1_1,
+			TypeDefinitions: parser.MustTransformDSLToProto(`
+				model
+				schema 1.1
+				type user
+				type employee
+				type state
+					relations
+						define can_view: [user] or member or owner
+						define member: [user]
+						define owner: [employee] and approved_member
+						define approved_member: [user]
+			`).GetTypeDefinitions(),
+		})
+		require.NoError(t, err)
+
+		baseline, candidates := q.candidates(key, tsWithoutGraph)
+		require.Equal(t, ListObjectsStrategyClassic, baseline)
+		require.Len(t, candidates, 1)
+	})
+}
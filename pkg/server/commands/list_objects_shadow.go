@@ -10,6 +10,7 @@ import (
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 
@@ -21,12 +22,19 @@ import (
 
 const ListObjectsShadowExecute = "ShadowedListObjectsQuery.Execute"
 
+// ShadowListObjectsResultHandler is called with the results of the main and the shadow queries once the shadow
+// query of a request completes. shadowErr is set when the shadow query errored.
+type ShadowListObjectsResultHandler func(ctx context.Context, req *openfgav1.ListObjectsRequest, main, shadow *ListObjectsResponse, shadowErr error)
+
 type shadowedListObjectsQuery struct {
 	main          ListObjectsResolver
 	shadow        ListObjectsResolver
 	shadowTimeout time.Duration // A time.Duration specifying the maximum amount of time to wait for the shadow list_objects query to complete. If the shadow query exceeds this shadowTimeout, it will be cancelled, and its result will be ignored, but the shadowTimeout event will be logged.
 	maxDeltaItems int           // The maximum number of items to log in the delta between the main and shadow results. This prevents excessive logging in case of large differences.
 	logger        logger.Logger
+	resultHandler ShadowListObjectsResultHandler
+	// requireWeightedGraph skips the shadow query of the models without a weighted graph, which the pipeline needs
+	requireWeightedGraph bool
 	// only used for testing signals
 	wg *sync.WaitGroup
 }
@@ -59,11 +67,20 @@ func WithShadowListObjectsQueryMaxDeltaItems(maxDeltaItems int) ShadowListObject
 	}
 }
 
+// WithShadowListObjectsQueryResultHandler sets a handler that records the results of the main and the shadow
+// queries, in addition to the logs.
+func WithShadowListObjectsQueryResultHandler(handler ShadowListObjectsResultHandler) ShadowListObjectsQueryOption {
+	return func(c *ShadowListObjectsQueryConfig) {
+		c.resultHandler = handler
+	}
+}
+
 type ShadowListObjectsQueryConfig struct {
 	shadowEnabled bool          // A boolean flag to globally enable or disable the shadow mode for list_objects queries. When false, the shadow query will not be executed.
 	shadowTimeout time.Duration // A time.Duration specifying the maximum amount of time to wait for the shadow list_objects query to complete. If the shadow query exceeds this shadowTimeout, it will be cancelled, and its result will be ignored, but the shadowTimeout event will be logged.
 	maxDeltaItems int           // The maximum number of items to log in the delta between the main and shadow results. This prevents excessive logging in case of large differences.
 	logger        logger.Logger
+	resultHandler ShadowListObjectsResultHandler
 }
 
 func NewShadowListObjectsQueryConfig(opts ...ShadowListObjectsQueryOption) *ShadowListObjectsQueryConfig {
@@ -121,15 +138,60 @@ func newShadowedListObjectsQuery(
 	}
 
 	result := &shadowedListObjectsQuery{
-		main:          standard,
-		shadow:        optimized,
+		main:                 standard,
+		shadow:               optimized,
+		shadowTimeout:        shadowConfig.shadowTimeout,
+		logger:               shadowConfig.logger,
+		maxDeltaItems:        shadowConfig.maxDeltaItems,
+		resultHandler:        shadowConfig.resultHandler,
+		requireWeightedGraph: true,
+		wg:                   &sync.WaitGroup{}, // only used for testing signals
+	}
+
+	return result, nil
+}
+
+// NewCandidateModelListObjectsQuery creates a new ListObjectsResolver that returns the results of the main query,
+// and runs the same query against the candidate model in the background to compare their results.
+func NewCandidateModelListObjectsQuery(
+	main ListObjectsResolver,
+	candidate *typesystem.TypeSystem,
+	shadowConfig *ShadowListObjectsQueryConfig,
+) (ListObjectsResolver, error) {
+	if shadowConfig == nil {
+		return nil, errors.New("shadowConfig must be set")
+	}
+
+	return &shadowedListObjectsQuery{
+		main:          main,
+		shadow:        &candidateModelListObjectsQuery{delegate: main, candidate: candidate},
 		shadowTimeout: shadowConfig.shadowTimeout,
 		logger:        shadowConfig.logger,
 		maxDeltaItems: shadowConfig.maxDeltaItems,
+		resultHandler: shadowConfig.resultHandler,
 		wg:            &sync.WaitGroup{}, // only used for testing signals
-	}
+	}, nil
+}
 
-	return result, nil
+// candidateModelListObjectsQuery runs the queries of its delegate against the candidate model instead of the model
+// of the request.
+type candidateModelListObjectsQuery struct {
+	delegate  ListObjectsResolver
+	candidate *typesystem.TypeSystem
+}
+
+func (q *candidateModelListObjectsQuery) Execute(ctx context.Context, req *openfgav1.ListObjectsRequest) (*ListObjectsResponse, error) {
+	candidateReq := proto.Clone(req).(*openfgav1.ListObjectsRequest)
+	candidateReq.AuthorizationModelId = q.candidate.GetAuthorizationModelID()
+
+	return q.delegate.Execute(typesystem.ContextWithTypesystem(ctx, q.candidate), candidateReq)
+}
+
+func (q *candidateModelListObjectsQuery) ExecuteStreamed(ctx context.Context, req *openfgav1.StreamedListObjectsRequest, srv openfgav1.OpenFGAService_StreamedListObjectsServer) (*ListObjectsResolutionMetadata, error) {
+	candidateReq := proto.Clone(req).(*openfgav1.StreamedListObjectsRequest)
+	candidateReq.AuthorizationModelId = q.candidate.GetAuthorizationModelID()
+
+	return q.delegate.ExecuteStreamed(typesystem.ContextWithTypesystem(ctx, q.candidate), candidateReq, srv)
 }
 
 func (q *shadowedListObjectsQuery) Execute(
@@ -189,6 +251,10 @@ func (q *shadowedListObjectsQuery) executeShadowModeAndCompareResults(ctx contex
 	shadowRes, errShadow := q.shadow.Execute(shadowCtx, req)
 	shadowLatency := time.Since(startTime)
 
+	if q.resultHandler != nil {
+		q.resultHandler(ctx, req, mainResult, shadowRes, errShadow)
+	}
+
 	var mainQueryCount uint32
 	var mainItemCount uint64
 	var mainResultObjects []string
@@ -268,14 +334,14 @@ func (q *shadowedListObjectsQuery) executeShadowModeAndCompareResults(ctx contex
 }
 
 // checkShadowModePreconditions checks if the shadow mode preconditions are met:
-//   - If the weighted graph does not exist, skip the shadow query.
+//   - If the weighted graph is required and does not exist, skip the shadow query.
 func (q *shadowedListObjectsQuery) checkShadowModePreconditions(ctx context.Context, req *openfgav1.ListObjectsRequest) bool {
 	typesys, ok := typesystem.TypesystemFromContext(ctx)
 	if !ok {
 		return false
 	}
 
-	if typesys.GetWeightedGraph() == nil {
+	if q.requireWeightedGraph && typesys.GetWeightedGraph() == nil {
 		q.logger.InfoWithContext(ctx, "shadowed list objects query skipped due to missing weighted graph",
 			loShadowLogFields(req)...,
 		)
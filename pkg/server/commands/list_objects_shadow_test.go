@@ -772,8 +772,9 @@ func TestShadowedListObjectsQuery_checkShadowModePreconditions(t *testing.T) {
 				listObjectsDeadline:   tt.args.deadline,
 			}
 			q := &shadowedListObjectsQuery{
-				main:   mainQuery,
-				logger: mockLogger,
+				main:                 mainQuery,
+				logger:               mockLogger,
+				requireWeightedGraph: true,
 			}
 
 			ret := q.checkShadowModePreconditions(ctx, &openfgav1.ListObjectsRequest{})
@@ -3,6 +3,7 @@ package commands
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sort"
 	"strconv"
 	"testing"
@@ -1127,3 +1128,165 @@ func createRecursiveRelations(b *testing.B, ctx context.Context, datastore stora
 		require.NoError(b, err)
 	}
 }
+
+func TestListObjectsObjectIDFilter(t *testing.T) {
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	model := `
+		model
+			schema 1.1
+		type user
+		type folder
+			relations
+				define parent: [folder]
+				define viewer: [user] or viewer from parent
+		type document
+			relations
+				define parent: [folder]
+				define blocked: [user]
+				define viewer: ([user] or viewer from parent) but not blocked
+	`
+	var tuples []string
+	for i := 0; i < 40; i++ {
+		tuples = append(tuples, "document:doc"+strconv.Itoa(i)+"#parent@folder:x")
+	}
+	tuples = append(tuples,
+		"folder:x#viewer@user:a",
+		"document:other#viewer@user:a",
+		"document:doc2#blocked@user:a",
+		// the candidate folder:c is only reached through the other folders
+		"folder:b#parent@folder:x",
+		"folder:c#parent@folder:b",
+	)
+
+	storeID, authModel := storagetest.BootstrapFGAStore(t, ds, model, tuples)
+	typesys, err := typesystem.NewAndValidate(context.Background(), authModel)
+	require.NoError(t, err)
+	ctx := typesystem.ContextWithTypesystem(context.Background(), typesys)
+
+	checkResolver, checkResolverCloser, err := graph.NewOrderedCheckResolvers().Build()
+	require.NoError(t, err)
+	t.Cleanup(checkResolverCloser)
+
+	tests := []struct {
+		name         string
+		objectType   string
+		objectIDs    []string
+		prefix       string
+		expected     []string
+		contextTuple *openfgav1.TupleKey
+	}{
+		{
+			name:       "no_filter",
+			objectType: "folder",
+			expected:   []string{"folder:b", "folder:c", "folder:x"},
+		},
+		{
+			name:       "candidates",
+			objectType: "document",
+			objectIDs:  []string{"doc1", "doc2", "doc3", "other", "unknown"},
+			expected:   []string{"document:doc1", "document:doc3", "document:other"},
+		},
+		{
+			name:       "prefix",
+			objectType: "document",
+			prefix:     "doc3",
+			expected:   []string{"document:doc3", "document:doc30", "document:doc31", "document:doc32", "document:doc33", "document:doc34", "document:doc35", "document:doc36", "document:doc37", "document:doc38", "document:doc39"},
+		},
+		{
+			name:       "candidates_and_prefix",
+			objectType: "document",
+			objectIDs:  []string{"doc1", "doc12", "doc3", "other"},
+			prefix:     "doc1",
+			expected:   []string{"document:doc1", "document:doc12"},
+		},
+		{
+			name:       "empty_candidates",
+			objectType: "document",
+			objectIDs:  []string{},
+			expected:   []string{},
+		},
+		{
+			name:       "candidates_reached_through_other_objects",
+			objectType: "folder",
+			objectIDs:  []string{"c"},
+			expected:   []string{"folder:c"},
+		},
+		{
+			name:         "candidates_with_contextual_tuples",
+			objectType:   "document",
+			objectIDs:    []string{"contextual", "other"},
+			contextTuple: tuple.NewTupleKey("document:contextual", "viewer", "user:a"),
+			expected:     []string{"document:contextual", "document:other"},
+		},
+	}
+
+	for _, pipelineEnabled := range []bool{false, true} {
+		for _, optimizationsEnabled := range []bool{false, true} {
+			for _, test := range tests {
+				t.Run(fmt.Sprintf("pipeline_%t_optimizations_%t_%s", pipelineEnabled, optimizationsEnabled, test.name), func(t *testing.T) {
+					q, err := NewListObjectsQuery(ds, checkResolver, storeID,
+						WithListObjectsPipelineEnabled(pipelineEnabled),
+						WithListObjectsOptimizationsEnabled(optimizationsEnabled),
+						WithListObjectsCandidateObjectIDs(test.objectIDs),
+						WithListObjectsObjectIDPrefix(test.prefix),
+					)
+					require.NoError(t, err)
+
+					req := &openfgav1.ListObjectsRequest{
+						StoreId:              storeID,
+						AuthorizationModelId: authModel.GetId(),
+						Type:                 test.objectType,
+						Relation:             "viewer",
+						User:                 "user:a",
+					}
+					if test.contextTuple != nil {
+						req.ContextualTuples = &openfgav1.ContextualTupleKeys{TupleKeys: []*openfgav1.TupleKey{test.contextTuple}}
+					}
+
+					res, err := q.Execute(ctx, req)
+					require.NoError(t, err)
+					require.ElementsMatch(t, test.expected, res.Objects)
+
+					collector := newListObjectsPageCollector(ctx, 100)
+					_, err = q.ExecuteStreamed(ctx, &openfgav1.StreamedListObjectsRequest{
+						StoreId:              req.GetStoreId(),
+						AuthorizationModelId: req.GetAuthorizationModelId(),
+						Type:                 req.GetType(),
+						Relation:             req.GetRelation(),
+						User:                 req.GetUser(),
+						ContextualTuples:     req.GetContextualTuples(),
+					}, collector)
+					require.NoError(t, err)
+					require.ElementsMatch(t, test.expected, collector.objects.Sorted())
+				})
+			}
+
+			t.Run(fmt.Sprintf("pipeline_%t_optimizations_%t_pushed_down", pipelineEnabled, optimizationsEnabled), func(t *testing.T) {
+				req := &openfgav1.ListObjectsRequest{
+					StoreId:              storeID,
+					AuthorizationModelId: authModel.GetId(),
+					Type:                 "document",
+					Relation:             "viewer",
+					User:                 "user:a",
+				}
+
+				itemCount := func(opts ...ListObjectsQueryOption) uint64 {
+					q, err := NewListObjectsQuery(ds, checkResolver, storeID, append([]ListObjectsQueryOption{
+						WithListObjectsPipelineEnabled(pipelineEnabled),
+						WithListObjectsOptimizationsEnabled(optimizationsEnabled),
+					}, opts...)...)
+					require.NoError(t, err)
+
+					res, err := q.Execute(ctx, req)
+					require.NoError(t, err)
+					return res.ResolutionMetadata.DatastoreItemCount.Load()
+				}
+
+				// the documents of folder:x are not read when they are not candidates
+				require.Less(t, itemCount(WithListObjectsCandidateObjectIDs([]string{"doc1"})), itemCount()-30)
+			})
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package commands
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/protobuf/types/known/structpb"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/cachecontroller"
+	"github.com/openfga/openfga/internal/concurrency"
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/internal/shared"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/server/config"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// ListRelationsQuery evaluates several relations of an object for a single user. The relations are
+// resolved with a shared graph.CheckMemo, so that the sub-problems they have in common (e.g. "editor"
+// implying "viewer") are resolved only once.
+type ListRelationsQuery struct {
+	logger                     logger.Logger
+	checkResolver              graph.CheckResolver
+	typesys                    *typesystem.TypeSystem
+	datastore                  storage.RelationshipTupleReader
+	sharedCheckResources       *shared.SharedDatastoreResources
+	cacheSettings              config.CacheSettings
+	maxRelations               uint32
+	maxConcurrentChecks        uint32
+	datastoreThrottlingEnabled bool
+	datastoreThrottleThreshold int
+	datastoreThrottleDuration  time.Duration
+}
+
+type ListRelationsCommandParams struct {
+	StoreID string
+	Object  string
+	User    string
+	// Relations to evaluate. If empty, every relation of the object type is evaluated.
+	Relations        []string
+	ContextualTuples *openfgav1.ContextualTupleKeys
+	Context          *structpb.Struct
+	Consistency      openfgav1.ConsistencyPreference
+}
+
+type ListRelationsMetadata struct {
+	DispatchCount       uint32
+	DatastoreQueryCount uint32
+	DatastoreItemCount  uint64
+	DispatchThrottled   bool
+	DatastoreThrottled  bool
+	// MemoHits is the number of sub-problems whose resolution was shared between relations.
+	MemoHits uint64
+	// DispatchesSaved is the number of dispatches that sharing sub-problems between relations saved.
+	DispatchesSaved uint64
+}
+
+type ListRelationsValidationError struct {
+	Message string
+}
+
+func (e ListRelationsValidationError) Error() string {
+	return e.Message
+}
+
+type ListRelationsQueryOption func(*ListRelationsQuery)
+
+func WithListRelationsCacheOptions(sharedCheckResources *shared.SharedDatastoreResources, cacheSettings config.CacheSettings) ListRelationsQueryOption {
+	return func(q *ListRelationsQuery) {
+		q.sharedCheckResources = sharedCheckResources
+		q.cacheSettings = cacheSettings
+	}
+}
+
+func WithListRelationsCommandLogger(l logger.Logger) ListRelationsQueryOption {
+	return func(q *ListRelationsQuery) {
+		q.logger = l
+	}
+}
+
+// WithListRelationsMaxRelations sets the maximum number of relations a single request may evaluate.
+func WithListRelationsMaxRelations(maxRelations uint32) ListRelationsQueryOption {
+	return func(q *ListRelationsQuery) {
+		q.maxRelations = maxRelations
+	}
+}
+
+// WithListRelationsMaxConcurrentChecks sets how many relations are resolved concurrently.
+func WithListRelationsMaxConcurrentChecks(maxConcurrentChecks uint32) ListRelationsQueryOption {
+	return func(q *ListRelationsQuery) {
+		q.maxConcurrentChecks = maxConcurrentChecks
+	}
+}
+
+func WithListRelationsDatastoreThrottler(enabled bool, threshold int, duration time.Duration) ListRelationsQueryOption {
+	return func(q *ListRelationsQuery) {
+		q.datastoreThrottlingEnabled = enabled
+		q.datastoreThrottleThreshold = threshold
+		q.datastoreThrottleDuration = duration
+	}
+}
+
+func NewListRelationsCommand(datastore storage.RelationshipTupleReader, checkResolver graph.CheckResolver, typesys *typesystem.TypeSystem, opts ...ListRelationsQueryOption) *ListRelationsQuery {
+	cmd := &ListRelationsQuery{
+		logger:              logger.NewNoopLogger(),
+		datastore:           datastore,
+		checkResolver:       checkResolver,
+		typesys:             typesys,
+		maxRelations:        config.DefaultMaxChecksPerBatchCheck,
+		maxConcurrentChecks: config.DefaultMaxConcurrentChecksPerBatchCheck,
+		cacheSettings:       config.NewDefaultCacheSettings(),
+		sharedCheckResources: &shared.SharedDatastoreResources{
+			CacheController: cachecontroller.NewNoopCacheController(),
+		},
+	}
+
+	for _, opt := range opts {
+		opt(cmd)
+	}
+	return cmd
+}
+
+// Execute returns, for every evaluated relation, whether the user has it on the object. It fails if
+// any of the relations cannot be resolved.
+func (q *ListRelationsQuery) Execute(ctx context.Context, params *ListRelationsCommandParams) (map[string]bool, *ListRelationsMetadata, error) {
+	relations, err := q.relations(params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// validate every relation before resolving any of them
+	for _, relation := range relations {
+		if err := validateCheckRequest(q.typesys, tuple.NewCheckRequestTupleKey(params.Object, relation, params.User), params.ContextualTuples); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	memo := graph.NewCheckMemo()
+	ctx = graph.ContextWithCheckMemo(ctx, memo)
+
+	results := make([]bool, len(relations))
+	var totalDispatchCount atomic.Uint32
+	var totalQueryCount atomic.Uint32
+	var totalItemCount atomic.Uint64
+	var dispatchThrottled atomic.Bool
+	var datastoreThrottled atomic.Bool
+
+	pool := concurrency.NewPool(ctx, int(q.maxConcurrentChecks))
+	for i, relation := range relations {
+		pool.Go(func(ctx context.Context) error {
+			checkQuery := NewCheckCommand(
+				q.datastore,
+				q.checkResolver,
+				q.typesys,
+				WithCheckCommandLogger(q.logger),
+				WithCheckCommandCache(q.sharedCheckResources, q.cacheSettings),
+				WithCheckDatastoreThrottler(
+					q.datastoreThrottlingEnabled,
+					q.datastoreThrottleThreshold,
+					q.datastoreThrottleDuration,
+				),
+			)
+
+			resp, reqMetadata, err := checkQuery.Execute(ctx, &CheckCommandParams{
+				StoreID:          params.StoreID,
+				TupleKey:         tuple.NewCheckRequestTupleKey(params.Object, relation, params.User),
+				ContextualTuples: params.ContextualTuples,
+				Context:          params.Context,
+				Consistency:      params.Consistency,
+			})
+
+			if reqMetadata != nil {
+				totalDispatchCount.Add(reqMetadata.DispatchCounter.Load())
+				if reqMetadata.DispatchThrottled.Load() {
+					dispatchThrottled.Store(true)
+				}
+				if reqMetadata.DatastoreThrottled.Load() {
+					datastoreThrottled.Store(true)
+				}
+			}
+			totalQueryCount.Add(resp.GetResolutionMetadata().DatastoreQueryCount)
+			totalItemCount.Add(resp.GetResolutionMetadata().DatastoreItemCount)
+
+			if err != nil {
+				return err
+			}
+			results[i] = resp.GetAllowed()
+			return nil
+		})
+	}
+	err = pool.Wait()
+
+	metadata := &ListRelationsMetadata{
+		DispatchCount:       totalDispatchCount.Load(),
+		DatastoreQueryCount: totalQueryCount.Load(),
+		DatastoreItemCount:  totalItemCount.Load(),
+		DispatchThrottled:   dispatchThrottled.Load(),
+		DatastoreThrottled:  datastoreThrottled.Load(),
+		MemoHits:            memo.Hits(),
+		DispatchesSaved:     memo.DispatchesSaved(),
+	}
+
+	if err != nil {
+		return nil, metadata, err
+	}
+
+	allowed := make(map[string]bool, len(relations))
+	for i, relation := range relations {
+		allowed[relation] = results[i]
+	}
+	return allowed, metadata, nil
+}
+
+// relations returns the deduplicated relations to evaluate, in a deterministic order.
+func (q *ListRelationsQuery) relations(params *ListRelationsCommandParams) ([]string, error) {
+	relations := slices.Clone(params.Relations)
+	if len(relations) == 0 {
+		objectType, _ := tuple.SplitObject(params.Object)
+		typeRelations, err := q.typesys.GetRelations(objectType)
+		if err != nil {
+			return nil, &InvalidRelationError{Cause: err}
+		}
+		for relation := range typeRelations {
+			relations = append(relations, relation)
+		}
+	}
+
+	slices.Sort(relations)
+	relations = slices.Compact(relations)
+
+	if len(relations) > int(q.maxRelations) {
+		return nil, &ListRelationsValidationError{
+			Message: "listRelations received " + strconv.Itoa(len(relations)) + " relations, the maximum allowed is " + strconv.Itoa(int(q.maxRelations)),
+		}
+	}
+	return relations, nil
+}
@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestListRelationsCommand(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type group
+			relations
+				define member: [user, group#member]
+		type doc
+			relations
+				define owner: [group#member]
+				define editor: [user] or owner
+				define viewer: [user] or editor
+				define deleter: owner`)
+	ts, err := typesystem.NewAndValidate(context.Background(), model)
+	require.NoError(t, err)
+
+	err = ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("group:eng", "member", "group:backend#member"),
+		tuple.NewTupleKey("group:backend", "member", "user:anne"),
+		tuple.NewTupleKey("doc:1", "owner", "group:eng#member"),
+		tuple.NewTupleKey("doc:1", "viewer", "user:bob"),
+	})
+	require.NoError(t, err)
+
+	checkResolver, checkResolverCloser, err := graph.NewOrderedCheckResolvers().Build()
+	require.NoError(t, err)
+	t.Cleanup(checkResolverCloser)
+
+	t.Run("every_relation", func(t *testing.T) {
+		cmd := NewListRelationsCommand(ds, checkResolver, ts)
+
+		relations, metadata, err := cmd.Execute(context.Background(), &ListRelationsCommandParams{
+			StoreID: storeID,
+			Object:  "doc:1",
+			User:    "user:anne",
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[string]bool{"owner": true, "editor": true, "viewer": true, "deleter": true}, relations)
+		// every relation implies owner, which is resolved once
+		require.GreaterOrEqual(t, metadata.MemoHits, uint64(3))
+
+		relations, _, err = cmd.Execute(context.Background(), &ListRelationsCommandParams{
+			StoreID: storeID,
+			Object:  "doc:1",
+			User:    "user:bob",
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[string]bool{"owner": false, "editor": false, "viewer": true, "deleter": false}, relations)
+	})
+
+	t.Run("given_relations", func(t *testing.T) {
+		cmd := NewListRelationsCommand(ds, checkResolver, ts)
+
+		relations, _, err := cmd.Execute(context.Background(), &ListRelationsCommandParams{
+			StoreID:   storeID,
+			Object:    "doc:1",
+			User:      "user:bob",
+			Relations: []string{"viewer", "editor", "viewer"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[string]bool{"editor": false, "viewer": true}, relations)
+	})
+
+	t.Run("contextual_tuples", func(t *testing.T) {
+		cmd := NewListRelationsCommand(ds, checkResolver, ts)
+
+		relations, _, err := cmd.Execute(context.Background(), &ListRelationsCommandParams{
+			StoreID:   storeID,
+			Object:    "doc:1",
+			User:      "user:carl",
+			Relations: []string{"deleter", "viewer"},
+			ContextualTuples: &openfgav1.ContextualTupleKeys{TupleKeys: []*openfgav1.TupleKey{
+				tuple.NewTupleKey("group:eng", "member", "user:carl"),
+			}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[string]bool{"deleter": true, "viewer": true}, relations)
+	})
+
+	t.Run("undefined_relation", func(t *testing.T) {
+		cmd := NewListRelationsCommand(ds, checkResolver, ts)
+
+		_, _, err := cmd.Execute(context.Background(), &ListRelationsCommandParams{
+			StoreID:   storeID,
+			Object:    "doc:1",
+			User:      "user:anne",
+			Relations: []string{"viewer", "undefined"},
+		})
+		var invalidRelationError *InvalidRelationError
+		require.ErrorAs(t, err, &invalidRelationError)
+	})
+
+	t.Run("undefined_type", func(t *testing.T) {
+		cmd := NewListRelationsCommand(ds, checkResolver, ts)
+
+		_, _, err := cmd.Execute(context.Background(), &ListRelationsCommandParams{
+			StoreID: storeID,
+			Object:  "folder:1",
+			User:    "user:anne",
+		})
+		var invalidRelationError *InvalidRelationError
+		require.ErrorAs(t, err, &invalidRelationError)
+	})
+
+	t.Run("too_many_relations", func(t *testing.T) {
+		cmd := NewListRelationsCommand(ds, checkResolver, ts, WithListRelationsMaxRelations(2))
+
+		_, _, err := cmd.Execute(context.Background(), &ListRelationsCommandParams{
+			StoreID: storeID,
+			Object:  "doc:1",
+			User:    "user:anne",
+		})
+		var validationError *ListRelationsValidationError
+		require.ErrorAs(t, err, &validationError)
+	})
+}
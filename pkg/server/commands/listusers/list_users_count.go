@@ -0,0 +1,75 @@
+package listusers
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/containers"
+	"github.com/openfga/openfga/pkg/telemetry"
+)
+
+type listUsersCountResponse struct {
+	Count uint64
+
+	// Approximate is true when Count is an estimate.
+	Approximate bool
+
+	// Complete is false when the ListUsers deadline stopped the expansion before it found every user, in which case
+	// Count only counts the users found until then.
+	Complete bool
+
+	Metadata listUsersResponseMetadata
+}
+
+// CountListUsers counts the distinct users of the request as the expansion finds them, without collecting them and
+// without the max results of ListUsers. The exact count keeps up to the max users in memory and spills the others
+// to disk, while the approximate count estimates the count with a HyperLogLog in constant memory, which counts more
+// users within the deadline. It assumes that the typesystem is in the context and that the request is valid.
+func (l *listUsersQuery) CountListUsers(
+	ctx context.Context,
+	req *openfgav1.ListUsersRequest,
+	approximate bool,
+) (*listUsersCountResponse, error) {
+	ctx, span := tracer.Start(ctx, "CountListUsers", trace.WithAttributes(
+		attribute.String("store_id", req.GetStoreId()),
+		attribute.Bool("approximate", approximate),
+	))
+	defer span.End()
+
+	deadlineCtx := ctx
+	if l.deadline != 0 {
+		var cancel context.CancelFunc
+		deadlineCtx, cancel = context.WithTimeout(ctx, l.deadline)
+		defer cancel()
+	}
+
+	counter := containers.NewDistinctCounter(approximate, l.maxUsersInMemory)
+	defer counter.Close()
+
+	// the deadline of the count tells whether the count is complete
+	metadata, err := l.expandUsers(deadlineCtx, req, 0, counter.Add)
+
+	if ctx.Err() != nil {
+		telemetry.TraceError(span, ctx.Err())
+		return nil, ctx.Err()
+	}
+
+	// the expansion stops silently, or with an error, at the deadline
+	complete := deadlineCtx.Err() == nil
+	if err != nil && complete {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int64("result_count", int64(counter.Count())))
+	return &listUsersCountResponse{
+		Count:       counter.Count(),
+		Approximate: counter.Approximate(),
+		Complete:    complete,
+		Metadata:    metadata,
+	}, nil
+}
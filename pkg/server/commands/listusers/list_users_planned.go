@@ -0,0 +1,305 @@
+package listusers
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/internal/planner"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+const listUsersPlannedFunc = "PlannedListUsersQuery.ListUsers"
+
+// The strategies that the planner chooses from to resolve a ListUsers request.
+const (
+	// ListUsersStrategyClassic resolves the request reading the datastore for every expansion.
+	ListUsersStrategyClassic = "classic"
+	// ListUsersStrategySharedReads resolves the request sharing the identical reads of its expansions.
+	ListUsersStrategySharedReads = "shared_reads"
+)
+
+var listUsersStrategyPlans = map[string]*planner.PlanConfig{
+	ListUsersStrategyClassic: {
+		Name:         ListUsersStrategyClassic,
+		InitialGuess: 100 * time.Millisecond,
+		// Low confidence in the initial guesses of every strategy, so that all of them are explored.
+		Lambda: 1,
+		Alpha:  0.5,
+		Beta:   0.5,
+	},
+	ListUsersStrategySharedReads: {
+		Name:         ListUsersStrategySharedReads,
+		InitialGuess: 80 * time.Millisecond,
+		Lambda:       1,
+		Alpha:        0.5,
+		Beta:         0.5,
+	},
+}
+
+var listUsersPlannerValidationCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: build.ProjectName,
+	Name:      "list_users_planner_validation_count",
+	Help:      "The total number of shadow validations of ListUsers strategies chosen by the planner, labeled by strategy and result (match, mismatch, error or incomplete).",
+}, []string{"strategy", "result"})
+
+type listUsersPlanner struct {
+	planner       planner.Manager
+	trust         *planner.Trust
+	shadowTimeout time.Duration
+
+	// validating holds the key and strategy of the validations in flight, so that a strategy is validated
+	// by at most one request at a time.
+	validating *sync.Map
+	// only used for testing signals
+	wg *sync.WaitGroup
+}
+
+// WithListUsersPlanner lets p select the strategy that resolves each request. The strategy configured by the
+// other options (the baseline) is always trusted. Another strategy is first validated in shadow, for at most
+// shadowTimeout: the baseline answers the request and the results of the selected strategy are compared to its
+// results, until trust trusts or rejects the strategy.
+func WithListUsersPlanner(p planner.Manager, trust *planner.Trust, shadowTimeout time.Duration) ListUsersQueryOption {
+	return func(d *listUsersQuery) {
+		if p == nil || trust == nil {
+			d.planner = nil
+			return
+		}
+		d.planner = &listUsersPlanner{
+			planner:       p,
+			trust:         trust,
+			shadowTimeout: shadowTimeout,
+			validating:    &sync.Map{},
+			wg:            &sync.WaitGroup{}, // only used for testing signals
+		}
+	}
+}
+
+// withStrategy returns a copy of l, without planner, that resolves requests with strategy.
+func (l *listUsersQuery) withStrategy(strategy string) *listUsersQuery {
+	clone := *l
+	clone.planner = nil
+	clone.sharedReads = strategy == ListUsersStrategySharedReads
+	clone.wasDispatchThrottled = new(atomic.Bool)
+	clone.wasDatastoreThrottled = new(atomic.Bool)
+	clone.datastore = clone.newDatastore()
+	return &clone
+}
+
+func (l *listUsersQuery) plannedListUsers(
+	ctx context.Context,
+	req *openfgav1.ListUsersRequest,
+) (*listUsersResponse, error) {
+	p := l.planner
+
+	baseline := ListUsersStrategyClassic
+	if l.sharedReads {
+		baseline = ListUsersStrategySharedReads
+	}
+
+	key := listUsersPlanKey(req)
+	candidates := map[string]*planner.PlanConfig{}
+	for name, plan := range listUsersStrategyPlans {
+		if name == baseline || !p.trust.Rejected(key, name) {
+			candidates[name] = plan
+		}
+	}
+	if len(candidates) == 1 {
+		return l.withStrategy(baseline).listUsers(ctx, req)
+	}
+
+	selector := p.planner.GetPlanSelector(key)
+	plan := selector.Select(candidates)
+
+	if plan.Name == baseline || p.trust.Trusted(key, plan.Name) {
+		start := time.Now()
+		res, err := l.withStrategy(plan.Name).listUsers(ctx, req)
+		if err == nil {
+			selector.UpdateStats(plan, time.Since(start))
+		}
+		return res, err
+	}
+
+	// the selected strategy is not trusted yet: answer with the baseline, and validate the strategy in shadow
+	start := time.Now()
+	res, err := l.withStrategy(baseline).listUsers(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	latency := time.Since(start)
+	selector.UpdateStats(candidates[baseline], latency)
+
+	validationKey := key + "|" + plan.Name
+	if _, inFlight := p.validating.LoadOrStore(validationKey, struct{}{}); inFlight {
+		return res, nil
+	}
+
+	cloneCtx := context.WithoutCancel(ctx) // needs typesystem
+	p.wg.Add(1)                            // only used for testing signals
+	go func() {
+		defer p.wg.Done() // only used for testing signals
+		defer p.validating.Delete(validationKey)
+		defer func() {
+			if r := recover(); r != nil {
+				l.logger.ErrorWithContext(cloneCtx, "panic recovered",
+					luPlannedLogFields(req, plan.Name, zap.Any("error", r))...,
+				)
+			}
+		}()
+
+		l.validate(cloneCtx, req, key, selector, plan, res, latency)
+	}()
+
+	return res, nil
+}
+
+// validate resolves req with the strategy of plan, and compares its results to the ones of the baseline. The
+// strategy is rejected if they differ, and gets closer to being trusted otherwise. Validations that can't compare
+// complete results, because either strategy reached the maximum number of results or timed out, are ignored.
+func (l *listUsersQuery) validate(
+	ctx context.Context,
+	req *openfgav1.ListUsersRequest,
+	key string,
+	selector planner.Selector,
+	plan *planner.PlanConfig,
+	baselineRes *listUsersResponse,
+	baselineLatency time.Duration,
+) {
+	ctx, span := tracer.Start(ctx, "validatePlannedStrategy", trace.WithAttributes(
+		attribute.String("strategy", plan.Name),
+	))
+	defer span.End()
+
+	shadowCtx, shadowCancel := context.WithTimeout(ctx, l.planner.shadowTimeout)
+	defer shadowCancel()
+
+	start := time.Now()
+	res, err := l.withStrategy(plan.Name).listUsers(shadowCtx, req)
+	latency := time.Since(start)
+
+	fields := []zap.Field{
+		zap.Duration("baseline_latency", baselineLatency),
+		zap.Duration("strategy_latency", latency),
+		zap.Int("baseline_result_count", len(baselineRes.GetUsers())),
+	}
+
+	// listUsers returns partial results when it times out
+	if shadowCtx.Err() != nil || (l.deadline != 0 && latency >= l.deadline) {
+		// the strategy is at least this slow, which the planner must learn
+		selector.UpdateStats(plan, latency)
+		listUsersPlannerValidationCounter.WithLabelValues(plan.Name, "incomplete").Inc()
+		l.logger.InfoWithContext(ctx, "planned list users strategy validation timed out",
+			luPlannedLogFields(req, plan.Name, fields...)...,
+		)
+		return
+	}
+
+	if err != nil {
+		// the strategy fails where the baseline succeeds
+		l.planner.trust.Record(key, plan.Name, false)
+		listUsersPlannerValidationCounter.WithLabelValues(plan.Name, "error").Inc()
+		l.logger.WarnWithContext(ctx, "planned list users strategy rejected after an error",
+			luPlannedLogFields(req, plan.Name, append(fields, zap.Error(err))...)...,
+		)
+		return
+	}
+
+	selector.UpdateStats(plan, latency)
+
+	maxResults := int(l.maxResults)
+	baselineComplete := (maxResults == 0 || len(baselineRes.GetUsers()) < maxResults) &&
+		(l.deadline == 0 || baselineLatency < l.deadline)
+	if !baselineComplete || (maxResults != 0 && len(res.GetUsers()) >= maxResults) {
+		listUsersPlannerValidationCounter.WithLabelValues(plan.Name, "incomplete").Inc()
+		return
+	}
+
+	baselineUsers := userKeys(baselineRes.GetUsers())
+	users := userKeys(res.GetUsers())
+	fields = append(fields, zap.Int("strategy_result_count", len(res.GetUsers())))
+
+	if maps.Equal(baselineUsers, users) {
+		span.SetAttributes(attribute.Bool("matches", true))
+		l.planner.trust.Record(key, plan.Name, true)
+		listUsersPlannerValidationCounter.WithLabelValues(plan.Name, "match").Inc()
+		l.logger.DebugWithContext(ctx, "planned list users strategy matches the baseline",
+			luPlannedLogFields(req, plan.Name, fields...)...,
+		)
+		return
+	}
+
+	span.SetAttributes(attribute.Bool("matches", false))
+	l.planner.trust.Record(key, plan.Name, false)
+	listUsersPlannerValidationCounter.WithLabelValues(plan.Name, "mismatch").Inc()
+	l.logger.WarnWithContext(ctx, "planned list users strategy rejected after a result difference",
+		luPlannedLogFields(req, plan.Name, append(fields,
+			zap.Strings("missing", slices.Sorted(keysNotIn(baselineUsers, users))),
+			zap.Strings("extra", slices.Sorted(keysNotIn(users, baselineUsers))),
+		)...)...,
+	)
+}
+
+// listUsersPlanKey returns the key of the planner for the requests of the users of the user filter that have
+// the relation of the request with an object of its type in the model.
+func listUsersPlanKey(req *openfgav1.ListUsersRequest) string {
+	var b strings.Builder
+	b.WriteString("listusers|")
+	b.WriteString(req.GetAuthorizationModelId())
+	b.WriteString("|")
+	b.WriteString(req.GetObject().GetType())
+	b.WriteString("|")
+	b.WriteString(req.GetRelation())
+	b.WriteString("|")
+	userFilter := req.GetUserFilters()[0]
+	if userFilter.GetRelation() != "" {
+		b.WriteString(tuple.ToObjectRelationString(userFilter.GetType(), userFilter.GetRelation()))
+	} else {
+		b.WriteString(userFilter.GetType())
+	}
+	return b.String()
+}
+
+func userKeys(users []*openfgav1.User) map[string]struct{} {
+	result := make(map[string]struct{}, len(users))
+	for _, user := range users {
+		result[tuple.UserProtoToString(user)] = struct{}{}
+	}
+	return result
+}
+
+// keysNotIn returns the keys of a that are not in b.
+func keysNotIn(a, b map[string]struct{}) func(yield func(string) bool) {
+	return func(yield func(string) bool) {
+		for key := range a {
+			if _, ok := b[key]; ok {
+				continue
+			}
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
+
+func luPlannedLogFields(req *openfgav1.ListUsersRequest, strategy string, fields ...zap.Field) []zap.Field {
+	return append([]zap.Field{
+		zap.String("func", listUsersPlannedFunc),
+		zap.Any("request", req),
+		zap.String("store_id", req.GetStoreId()),
+		zap.String("model_id", req.GetAuthorizationModelId()),
+		zap.String("strategy", strategy),
+	}, fields...)
+}
@@ -0,0 +1,122 @@
+package listusers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/planner"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// fixedPlanner always selects the plan named name when it is a candidate.
+type fixedPlanner struct {
+	name string
+}
+
+func (p *fixedPlanner) GetPlanSelector(string) planner.Selector { return p }
+
+func (p *fixedPlanner) Stop() {}
+
+func (p *fixedPlanner) Select(candidates map[string]*planner.PlanConfig) *planner.PlanConfig {
+	if plan, ok := candidates[p.name]; ok {
+		return plan
+	}
+	return candidates[ListUsersStrategyClassic]
+}
+
+func (p *fixedPlanner) UpdateStats(*planner.PlanConfig, time.Duration) {}
+
+func TestListUsersPlanner(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	ctx := context.Background()
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type group
+			relations
+				define member: [user, group#member]
+		type repo
+			relations
+				define admin: [user, group#member]`)
+	storeID := ulid.Make().String()
+	require.NoError(t, ds.WriteAuthorizationModel(ctx, storeID, model))
+	require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("repo:target", "admin", "user:1"),
+		tuple.NewTupleKey("repo:target", "admin", "group:eng#member"),
+		tuple.NewTupleKey("group:eng", "member", "user:2"),
+		tuple.NewTupleKey("group:eng", "member", "group:all#member"),
+		tuple.NewTupleKey("group:all", "member", "user:3"),
+	}))
+
+	typesys, err := typesystem.NewAndValidate(ctx, model)
+	require.NoError(t, err)
+	ctx = typesystem.ContextWithTypesystem(ctx, typesys)
+
+	req := &openfgav1.ListUsersRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: model.GetId(),
+		Object:               &openfgav1.Object{Type: "repo", Id: "target"},
+		Relation:             "admin",
+		UserFilters:          []*openfgav1.UserTypeFilter{{Type: "user"}},
+	}
+	key := listUsersPlanKey(req)
+	expected := []*openfgav1.User{
+		{User: &openfgav1.User_Object{Object: &openfgav1.Object{Type: "user", Id: "1"}}},
+		{User: &openfgav1.User_Object{Object: &openfgav1.Object{Type: "user", Id: "2"}}},
+		{User: &openfgav1.User_Object{Object: &openfgav1.Object{Type: "user", Id: "3"}}},
+	}
+
+	trust := planner.NewTrust(2)
+	l := NewListUsersQuery(ds, nil,
+		WithListUsersDeadline(10*time.Second),
+		WithListUsersPlanner(&fixedPlanner{name: ListUsersStrategySharedReads}, trust, 10*time.Second),
+	)
+
+	for range 3 {
+		res, err := l.ListUsers(ctx, req)
+		require.NoError(t, err)
+		require.ElementsMatch(t, expected, res.GetUsers())
+		l.planner.wg.Wait()
+	}
+
+	require.True(t, trust.Trusted(key, ListUsersStrategySharedReads))
+	require.False(t, trust.Rejected(key, ListUsersStrategySharedReads))
+
+	t.Run("mismatch_rejects_the_strategy", func(t *testing.T) {
+		trust := planner.NewTrust(2)
+		l := NewListUsersQuery(ds, nil,
+			WithListUsersDeadline(10*time.Second),
+			WithListUsersPlanner(&fixedPlanner{name: ListUsersStrategySharedReads}, trust, 10*time.Second),
+		)
+
+		baselineRes, err := l.withStrategy(ListUsersStrategyClassic).listUsers(ctx, req)
+		require.NoError(t, err)
+		baselineRes.Users = baselineRes.Users[1:]
+
+		selector := l.planner.planner.GetPlanSelector(key)
+		l.validate(ctx, req, key, selector, listUsersStrategyPlans[ListUsersStrategySharedReads], baselineRes, time.Millisecond)
+		require.True(t, trust.Rejected(key, ListUsersStrategySharedReads))
+
+		res, err := l.ListUsers(ctx, req)
+		require.NoError(t, err)
+		require.ElementsMatch(t, expected, res.GetUsers())
+		l.planner.wg.Wait()
+	})
+}
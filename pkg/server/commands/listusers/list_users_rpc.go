@@ -23,10 +23,12 @@ import (
 	"github.com/openfga/openfga/internal/throttler/threshold"
 	"github.com/openfga/openfga/internal/utils/apimethod"
 	"github.com/openfga/openfga/internal/validation"
+	"github.com/openfga/openfga/pkg/encoder"
 	"github.com/openfga/openfga/pkg/logger"
 	serverconfig "github.com/openfga/openfga/pkg/server/config"
 	"github.com/openfga/openfga/pkg/storage"
 	"github.com/openfga/openfga/pkg/storage/storagewrappers"
+	"github.com/openfga/openfga/pkg/storage/storagewrappers/sharediterator"
 	"github.com/openfga/openfga/pkg/telemetry"
 	"github.com/openfga/openfga/pkg/tuple"
 	"github.com/openfga/openfga/pkg/typesystem"
@@ -44,6 +46,7 @@ type listUsersQuery struct {
 	resolveNodeLimit           uint32
 	maxResults                 uint32
 	maxConcurrentReads         uint32
+	concurrencyLimiter         *storagewrappers.AdaptiveConcurrencyLimiter
 	deadline                   time.Duration
 	dispatchThrottlerConfig    threshold.Config
 	wasDispatchThrottled       *atomic.Bool
@@ -51,6 +54,17 @@ type listUsersQuery struct {
 	expandDirectDispatch       expandDirectDispatchHandler
 	datastoreThrottleThreshold int
 	datastoreThrottleDuration  time.Duration
+
+	// the datastore and contextual tuples that datastore wraps, kept to build the strategies of the planner
+	baseDatastore    storage.RelationshipTupleReader
+	contextualTuples []*openfgav1.TupleKey
+	sharedReads      bool
+	planner          *listUsersPlanner
+
+	// the deduplication of the streamed users and the continuation tokens of the pages
+	maxUsersInMemory int
+	encoder          encoder.Encoder
+	tokenSerializer  encoder.ContinuationTokenSerializer
 }
 
 type expandResponse struct {
@@ -130,6 +144,14 @@ func WithListUsersMaxConcurrentReads(limit uint32) ListUsersQueryOption {
 	}
 }
 
+// WithListUsersConcurrencyLimiter bounds the concurrent datastore reads of the request to the limit of an adaptive
+// limiter instead of WithListUsersMaxConcurrentReads. See storagewrappers.AdaptiveConcurrencyLimiter.
+func WithListUsersConcurrencyLimiter(l *storagewrappers.AdaptiveConcurrencyLimiter) ListUsersQueryOption {
+	return func(d *listUsersQuery) {
+		d.concurrencyLimiter = l
+	}
+}
+
 func WithListUsersDatastoreThrottler(threshold int, duration time.Duration) ListUsersQueryOption {
 	return func(d *listUsersQuery) {
 		d.datastoreThrottleThreshold = threshold
@@ -137,6 +159,13 @@ func WithListUsersDatastoreThrottler(threshold int, duration time.Duration) List
 	}
 }
 
+// WithListUsersSharedReads makes the datastore reads of the request that are identical share one iterator.
+func WithListUsersSharedReads(enabled bool) ListUsersQueryOption {
+	return func(d *listUsersQuery) {
+		d.sharedReads = enabled
+	}
+}
+
 func (l *listUsersQuery) throttle(ctx context.Context, currentNumDispatch uint32) {
 	span := trace.SpanFromContext(ctx)
 
@@ -175,24 +204,55 @@ func NewListUsersQuery(ds storage.RelationshipTupleReader, contextualTuples []*o
 		wasDispatchThrottled:    new(atomic.Bool),
 		wasDatastoreThrottled:   new(atomic.Bool),
 		expandDirectDispatch:    expandDirectDispatch,
+		maxUsersInMemory:        serverconfig.DefaultListUsersMaxUsersInMemory,
+		encoder:                 encoder.NewBase64Encoder(),
+		tokenSerializer:         encoder.NewStringContinuationTokenSerializer(),
 	}
 
 	for _, opt := range opts {
 		opt(l)
 	}
 
-	l.datastore = storagewrappers.NewRequestStorageWrapper(ds, contextualTuples, &storagewrappers.Operation{
+	l.baseDatastore = ds
+	l.contextualTuples = contextualTuples
+	l.datastore = l.newDatastore()
+
+	return l
+}
+
+func (l *listUsersQuery) newDatastore() *storagewrappers.RequestStorageWrapper {
+	ds := l.baseDatastore
+	if l.sharedReads {
+		ds = sharediterator.NewSharedIteratorDatastore(
+			ds,
+			sharediterator.NewSharedIteratorDatastoreStorage(),
+			sharediterator.WithSharedIteratorDatastoreLogger(l.logger),
+			sharediterator.WithMethod(string(apimethod.ListUsers)),
+		)
+	}
+
+	return storagewrappers.NewRequestStorageWrapper(ds, l.contextualTuples, &storagewrappers.Operation{
 		Method:      apimethod.ListUsers,
 		Concurrency: l.maxConcurrentReads,
+		Limiter:     l.concurrencyLimiter,
 	})
-
-	return l
 }
 
 // ListUsers assumes that the typesystem is in the context and that the request is valid.
 func (l *listUsersQuery) ListUsers(
 	ctx context.Context,
 	req *openfgav1.ListUsersRequest,
+) (*listUsersResponse, error) {
+	if l.planner != nil {
+		return l.plannedListUsers(ctx, req)
+	}
+
+	return l.listUsers(ctx, req)
+}
+
+func (l *listUsersQuery) listUsers(
+	ctx context.Context,
+	req *openfgav1.ListUsersRequest,
 ) (*listUsersResponse, error) {
 	ctx, span := tracer.Start(ctx, "ListUsers", trace.WithAttributes(
 		attribute.String("store_id", req.GetStoreId()),
@@ -0,0 +1,307 @@
+package listusers
+
+import (
+	"context"
+	"maps"
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+const ListUsersShadowExecute = "ShadowedListUsersQuery.ListUsers"
+
+var listUsersShadowComparisonCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: build.ProjectName,
+	Name:      "list_users_shadow_comparison_count",
+	Help:      "The total number of ListUsers requests sampled for the shadow query, labeled by the result of the comparison (match, mismatch, error or incomplete).",
+}, []string{"result"})
+
+var listUsersShadowDeltaItemsHistogram = promauto.NewHistogram(prometheus.HistogramOpts{
+	Namespace:                       build.ProjectName,
+	Name:                            "list_users_shadow_delta_items",
+	Help:                            "The number of users in the difference between the results of the ListUsers queries and their shadow queries that do not match.",
+	Buckets:                         []float64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000},
+	NativeHistogramBucketFactor:     1.1,
+	NativeHistogramMaxBucketNumber:  100,
+	NativeHistogramMinResetDuration: time.Hour,
+})
+
+// ListUsersResolver resolves ListUsers requests.
+type ListUsersResolver interface {
+	ListUsers(ctx context.Context, req *openfgav1.ListUsersRequest) (*listUsersResponse, error)
+}
+
+var _ ListUsersResolver = (*listUsersQuery)(nil)
+
+type shadowedListUsersQuery struct {
+	main             ListUsersResolver
+	shadow           ListUsersResolver
+	shadowTimeout    time.Duration // The maximum amount of time to wait for the shadow query to complete. A shadow query that exceeds it is cancelled, and its result is ignored.
+	maxDeltaItems    int           // The maximum number of users to log in the delta between the main and shadow results. This prevents excessive logging in case of large differences.
+	samplePercentage int           // The percentage of the requests whose results are compared with the ones of the shadow query.
+	maxResults       uint32        // The ListUsers max results, which make a result incomplete.
+	deadline         time.Duration // The ListUsers deadline, which makes a result incomplete.
+	logger           logger.Logger
+	// only used for testing signals
+	wg *sync.WaitGroup
+}
+
+type ShadowListUsersQueryOption func(c *ShadowListUsersQueryConfig)
+
+// WithShadowListUsersQueryEnabled sets whether the ListUsers requests are compared with the shadow query.
+func WithShadowListUsersQueryEnabled(enabled bool) ShadowListUsersQueryOption {
+	return func(c *ShadowListUsersQueryConfig) {
+		c.shadowEnabled = enabled
+	}
+}
+
+// WithShadowListUsersQueryTimeout sets the timeout of the shadow query.
+func WithShadowListUsersQueryTimeout(timeout time.Duration) ShadowListUsersQueryOption {
+	return func(c *ShadowListUsersQueryConfig) {
+		c.shadowTimeout = timeout
+	}
+}
+
+func WithShadowListUsersQueryLogger(logger logger.Logger) ShadowListUsersQueryOption {
+	return func(c *ShadowListUsersQueryConfig) {
+		c.logger = logger
+	}
+}
+
+func WithShadowListUsersQueryMaxDeltaItems(maxDeltaItems int) ShadowListUsersQueryOption {
+	return func(c *ShadowListUsersQueryConfig) {
+		c.maxDeltaItems = maxDeltaItems
+	}
+}
+
+// WithShadowListUsersQuerySamplePercentage sets the percentage, between 0 and 100, of the requests that also run
+// the shadow query.
+func WithShadowListUsersQuerySamplePercentage(percentage int) ShadowListUsersQueryOption {
+	return func(c *ShadowListUsersQueryConfig) {
+		c.samplePercentage = percentage
+	}
+}
+
+// WithShadowListUsersQueryOptions sets the options of the shadow query, applied after the ones of the main query.
+// They select the implementation of ListUsers that is compared with the main one.
+func WithShadowListUsersQueryOptions(opts ...ListUsersQueryOption) ShadowListUsersQueryOption {
+	return func(c *ShadowListUsersQueryConfig) {
+		c.shadowOpts = opts
+	}
+}
+
+type ShadowListUsersQueryConfig struct {
+	shadowEnabled    bool          // A boolean flag to enable or disable the shadow mode for ListUsers queries. When false, the shadow query is not executed.
+	shadowTimeout    time.Duration // The maximum amount of time to wait for the shadow query to complete.
+	maxDeltaItems    int           // The maximum number of users to log in the delta between the main and shadow results.
+	samplePercentage int           // The percentage of the requests that also run the shadow query.
+	shadowOpts       []ListUsersQueryOption
+	logger           logger.Logger
+}
+
+func NewShadowListUsersQueryConfig(opts ...ShadowListUsersQueryOption) *ShadowListUsersQueryConfig {
+	result := &ShadowListUsersQueryConfig{
+		shadowEnabled:    false,                  // Disabled by default
+		shadowTimeout:    1 * time.Second,        // Default shadowTimeout for shadow queries
+		logger:           logger.NewNoopLogger(), // Default to a noop logger
+		maxDeltaItems:    100,                    // Default max delta items to log
+		samplePercentage: 100,                    // Default to every request
+	}
+	for _, opt := range opts {
+		opt(result)
+	}
+	return result
+}
+
+// NewListUsersQueryWithShadowConfig creates a new ListUsersResolver that runs the shadow query of shadowConfig in
+// the background, if enabled, and compares its results with the ones it returns.
+func NewListUsersQueryWithShadowConfig(
+	ds storage.RelationshipTupleReader,
+	contextualTuples []*openfgav1.TupleKey,
+	shadowConfig *ShadowListUsersQueryConfig,
+	opts ...ListUsersQueryOption,
+) ListUsersResolver {
+	main := NewListUsersQuery(ds, contextualTuples, opts...)
+	if shadowConfig == nil || !shadowConfig.shadowEnabled {
+		return main
+	}
+
+	// the shadow query resolves the requests with its implementation only, without the planner
+	shadow := NewListUsersQuery(ds, contextualTuples, slices.Concat(
+		opts,
+		[]ListUsersQueryOption{WithListUsersPlanner(nil, nil, 0)},
+		shadowConfig.shadowOpts,
+	)...)
+
+	return &shadowedListUsersQuery{
+		main:             main,
+		shadow:           shadow,
+		shadowTimeout:    shadowConfig.shadowTimeout,
+		maxDeltaItems:    shadowConfig.maxDeltaItems,
+		samplePercentage: shadowConfig.samplePercentage,
+		maxResults:       main.maxResults,
+		deadline:         main.deadline,
+		logger:           shadowConfig.logger,
+		wg:               &sync.WaitGroup{}, // only used for testing signals
+	}
+}
+
+func (q *shadowedListUsersQuery) ListUsers(
+	ctx context.Context,
+	req *openfgav1.ListUsersRequest,
+) (*listUsersResponse, error) {
+	cloneCtx := context.WithoutCancel(ctx) // needs typesystem
+
+	startTime := time.Now()
+	res, err := q.main.ListUsers(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	latency := time.Since(startTime)
+
+	if q.samplePercentage <= 0 || rand.Intn(100) >= q.samplePercentage {
+		return res, nil
+	}
+
+	q.wg.Add(1) // only used for testing signals
+	go func() {
+		defer q.wg.Done() // only used for testing signals
+		defer func() {
+			if r := recover(); r != nil {
+				q.logger.ErrorWithContext(cloneCtx, "panic recovered",
+					luShadowLogFields(req,
+						zap.Duration("main_latency", latency),
+						zap.Int("main_result_count", len(res.GetUsers())),
+						zap.Any("error", r),
+					)...,
+				)
+			}
+		}()
+
+		q.executeShadowModeAndCompareResults(cloneCtx, req, res, latency)
+	}()
+
+	return res, nil
+}
+
+// executeShadowModeAndCompareResults runs the shadow query, compares its results with the ones of the main query,
+// and logs the differences. The results that are not complete, because either query reached the max results or
+// the deadline, are not compared. It is designed to run in a separate goroutine.
+func (q *shadowedListUsersQuery) executeShadowModeAndCompareResults(
+	ctx context.Context,
+	req *openfgav1.ListUsersRequest,
+	mainRes *listUsersResponse,
+	latency time.Duration,
+) {
+	ctx, span := tracer.Start(ctx, "shadow")
+	defer span.End()
+
+	shadowCtx, shadowCancel := context.WithTimeout(ctx, q.shadowTimeout)
+	defer shadowCancel()
+
+	startTime := time.Now()
+	shadowRes, errShadow := q.shadow.ListUsers(shadowCtx, req)
+	shadowLatency := time.Since(startTime)
+
+	fields := []zap.Field{
+		zap.Duration("main_latency", latency),
+		zap.Duration("shadow_latency", shadowLatency),
+		zap.Int("main_result_count", len(mainRes.GetUsers())),
+	}
+
+	if errShadow != nil {
+		listUsersShadowComparisonCounter.WithLabelValues("error").Inc()
+		q.logger.WarnWithContext(ctx, "shadowed list users error",
+			luShadowLogFields(req, append(fields, zap.Any("error", errShadow))...)...,
+		)
+		return
+	}
+
+	fields = append(fields,
+		zap.Int("shadow_result_count", len(shadowRes.GetUsers())),
+		zap.Uint32("main_datastore_query_count", mainRes.GetMetadata().DatastoreQueryCount),
+		zap.Uint32("shadow_datastore_query_count", shadowRes.GetMetadata().DatastoreQueryCount),
+		zap.Uint64("main_datastore_item_count", mainRes.GetMetadata().DatastoreItemCount),
+		zap.Uint64("shadow_datastore_item_count", shadowRes.GetMetadata().DatastoreItemCount),
+	)
+
+	// ListUsers returns partial results when it reaches the max results or a deadline
+	if shadowCtx.Err() != nil || !q.isComplete(mainRes, latency) || !q.isComplete(shadowRes, shadowLatency) {
+		listUsersShadowComparisonCounter.WithLabelValues("incomplete").Inc()
+		q.logger.InfoWithContext(ctx, "shadowed list users results incomplete",
+			luShadowLogFields(req, fields...)...,
+		)
+		return
+	}
+
+	mainUsers := userKeys(mainRes.GetUsers())
+	shadowUsers := userKeys(shadowRes.GetUsers())
+
+	if maps.Equal(mainUsers, shadowUsers) {
+		span.SetAttributes(attribute.Bool("matches", true))
+		listUsersShadowComparisonCounter.WithLabelValues("match").Inc()
+		q.logger.InfoWithContext(ctx, "shadowed list users result matches",
+			luShadowLogFields(req, append(fields, zap.Bool("is_match", true))...)...,
+		)
+		return
+	}
+
+	span.SetAttributes(attribute.Bool("matches", false))
+	delta := calculateDelta(mainUsers, shadowUsers)
+	totalDelta := len(delta)
+	// Limit the delta to maxDeltaItems
+	if totalDelta > q.maxDeltaItems {
+		delta = delta[:q.maxDeltaItems]
+	}
+
+	listUsersShadowComparisonCounter.WithLabelValues("mismatch").Inc()
+	listUsersShadowDeltaItemsHistogram.Observe(float64(totalDelta))
+	q.logger.WarnWithContext(ctx, "shadowed list users result difference",
+		luShadowLogFields(req, append(fields,
+			zap.Bool("is_match", false),
+			zap.Int("total_delta", totalDelta),
+			zap.Strings("delta", delta),
+		)...)...,
+	)
+}
+
+// isComplete returns whether res holds every user of its request.
+func (q *shadowedListUsersQuery) isComplete(res *listUsersResponse, latency time.Duration) bool {
+	return (q.maxResults == 0 || len(res.GetUsers()) < int(q.maxResults)) &&
+		(q.deadline == 0 || latency < q.deadline)
+}
+
+func luShadowLogFields(req *openfgav1.ListUsersRequest, fields ...zap.Field) []zap.Field {
+	return append([]zap.Field{
+		zap.String("func", ListUsersShadowExecute),
+		zap.Any("request", req),
+		zap.String("store_id", req.GetStoreId()),
+		zap.String("model_id", req.GetAuthorizationModelId()),
+	}, fields...)
+}
+
+// calculateDelta returns the sorted users of main that are not in shadow, prefixed with "-", and the ones of
+// shadow that are not in main, prefixed with "+".
+func calculateDelta(main, shadow map[string]struct{}) []string {
+	delta := make([]string, 0, len(main)+len(shadow))
+	for key := range keysNotIn(main, shadow) {
+		delta = append(delta, "-"+key)
+	}
+	for key := range keysNotIn(shadow, main) {
+		delta = append(delta, "+"+key)
+	}
+	slices.Sort(delta)
+	return delta
+}
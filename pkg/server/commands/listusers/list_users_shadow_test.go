@@ -0,0 +1,175 @@
+package listusers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/mocks"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestShadowedListUsersQuery(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	ctx := context.Background()
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type group
+			relations
+				define member: [user, group#member]
+		type repo
+			relations
+				define admin: [user, group#member]`)
+	storeID := ulid.Make().String()
+	require.NoError(t, ds.WriteAuthorizationModel(ctx, storeID, model))
+	require.NoError(t, ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("repo:target", "admin", "user:1"),
+		tuple.NewTupleKey("repo:target", "admin", "group:eng#member"),
+		tuple.NewTupleKey("group:eng", "member", "user:2"),
+	}))
+
+	typesys, err := typesystem.NewAndValidate(ctx, model)
+	require.NoError(t, err)
+	ctx = typesystem.ContextWithTypesystem(ctx, typesys)
+
+	req := &openfgav1.ListUsersRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: model.GetId(),
+		Object:               &openfgav1.Object{Type: "repo", Id: "target"},
+		Relation:             "admin",
+		UserFilters:          []*openfgav1.UserTypeFilter{{Type: "user"}},
+	}
+	expected := []*openfgav1.User{
+		{User: &openfgav1.User_Object{Object: &openfgav1.Object{Type: "user", Id: "1"}}},
+		{User: &openfgav1.User_Object{Object: &openfgav1.Object{Type: "user", Id: "2"}}},
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		l := NewListUsersQueryWithShadowConfig(ds, nil, NewShadowListUsersQueryConfig())
+		require.IsType(t, &listUsersQuery{}, l)
+	})
+
+	t.Run("match", func(t *testing.T) {
+		matches := testutil.ToFloat64(listUsersShadowComparisonCounter.WithLabelValues("match"))
+
+		l := NewListUsersQueryWithShadowConfig(ds, nil, NewShadowListUsersQueryConfig(
+			WithShadowListUsersQueryEnabled(true),
+			WithShadowListUsersQueryTimeout(10*time.Second),
+			WithShadowListUsersQueryOptions(WithListUsersSharedReads(true)),
+		), WithListUsersDeadline(10*time.Second))
+		shadowed, ok := l.(*shadowedListUsersQuery)
+		require.True(t, ok)
+		require.False(t, shadowed.main.(*listUsersQuery).sharedReads)
+		require.True(t, shadowed.shadow.(*listUsersQuery).sharedReads)
+
+		res, err := l.ListUsers(ctx, req)
+		require.NoError(t, err)
+		require.ElementsMatch(t, expected, res.GetUsers())
+		shadowed.wg.Wait()
+
+		require.InDelta(t, matches+1, testutil.ToFloat64(listUsersShadowComparisonCounter.WithLabelValues("match")), 0)
+	})
+
+	t.Run("mismatch_logs_the_capped_delta", func(t *testing.T) {
+		mismatches := testutil.ToFloat64(listUsersShadowComparisonCounter.WithLabelValues("mismatch"))
+
+		ctrl := gomock.NewController(t)
+		mockLogger := mocks.NewMockLogger(ctrl)
+		mockLogger.EXPECT().WarnWithContext(gomock.Any(), "shadowed list users result difference", gomock.Any()).
+			Do(func(_ context.Context, _ string, fields ...zap.Field) {
+				for _, field := range fields {
+					switch field.Key {
+					case "total_delta":
+						require.Equal(t, int64(3), field.Integer)
+					case "delta":
+						require.Equal(t, zap.Strings("delta", []string{"+user:3", "+user:4"}), field)
+					}
+				}
+			})
+
+		// the shadow query finds more users than the main one
+		var contextualTuples []*openfgav1.TupleKey
+		for i := 3; i <= 5; i++ {
+			contextualTuples = append(contextualTuples, tuple.NewTupleKey("group:eng", "member", fmt.Sprintf("user:%d", i)))
+		}
+		q := &shadowedListUsersQuery{
+			main:             NewListUsersQuery(ds, nil),
+			shadow:           NewListUsersQuery(ds, contextualTuples),
+			shadowTimeout:    10 * time.Second,
+			maxDeltaItems:    2,
+			samplePercentage: 100,
+			logger:           mockLogger,
+			wg:               &sync.WaitGroup{},
+		}
+
+		res, err := q.ListUsers(ctx, req)
+		require.NoError(t, err)
+		require.ElementsMatch(t, expected, res.GetUsers())
+		q.wg.Wait()
+
+		require.InDelta(t, mismatches+1, testutil.ToFloat64(listUsersShadowComparisonCounter.WithLabelValues("mismatch")), 0)
+	})
+
+	t.Run("incomplete_results_are_not_compared", func(t *testing.T) {
+		incomplete := testutil.ToFloat64(listUsersShadowComparisonCounter.WithLabelValues("incomplete"))
+
+		l := NewListUsersQueryWithShadowConfig(ds, nil, NewShadowListUsersQueryConfig(
+			WithShadowListUsersQueryEnabled(true),
+			WithShadowListUsersQueryTimeout(10*time.Second),
+		), WithListUsersMaxResults(1))
+
+		res, err := l.ListUsers(ctx, req)
+		require.NoError(t, err)
+		require.Len(t, res.GetUsers(), 1)
+		l.(*shadowedListUsersQuery).wg.Wait()
+
+		require.InDelta(t, incomplete+1, testutil.ToFloat64(listUsersShadowComparisonCounter.WithLabelValues("incomplete")), 0)
+	})
+
+	t.Run("unsampled_requests_are_not_compared", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		// any log fails the test
+		mockLogger := mocks.NewMockLogger(ctrl)
+
+		l := NewListUsersQueryWithShadowConfig(ds, nil, NewShadowListUsersQueryConfig(
+			WithShadowListUsersQueryEnabled(true),
+			WithShadowListUsersQuerySamplePercentage(0),
+			WithShadowListUsersQueryLogger(mockLogger),
+		))
+
+		res, err := l.ListUsers(ctx, req)
+		require.NoError(t, err)
+		require.ElementsMatch(t, expected, res.GetUsers())
+		l.(*shadowedListUsersQuery).wg.Wait()
+	})
+}
+
+func TestCalculateDelta(t *testing.T) {
+	delta := calculateDelta(
+		map[string]struct{}{"user:1": {}, "user:2": {}},
+		map[string]struct{}{"user:2": {}, "user:3": {}},
+	)
+	require.Equal(t, []string{"+user:3", "-user:1"}, delta)
+}
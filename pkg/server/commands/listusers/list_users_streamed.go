@@ -0,0 +1,285 @@
+package listusers
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/containers"
+	openfgaErrors "github.com/openfga/openfga/internal/errors"
+	"github.com/openfga/openfga/pkg/encoder"
+	serverconfig "github.com/openfga/openfga/pkg/server/config"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/telemetry"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// WithListUsersMaxUsersInMemory bounds the number of users StreamListUsers keeps in memory to deduplicate the
+// users it sends. The users beyond it are spilled to temporary files. 0 keeps every user in memory.
+func WithListUsersMaxUsersInMemory(users int) ListUsersQueryOption {
+	return func(d *listUsersQuery) {
+		d.maxUsersInMemory = users
+	}
+}
+
+// WithListUsersEncoder sets the encoder of the continuation tokens of PaginatedListUsers.
+func WithListUsersEncoder(e encoder.Encoder) ListUsersQueryOption {
+	return func(d *listUsersQuery) {
+		d.encoder = e
+	}
+}
+
+// WithListUsersTokenSerializer sets the serializer of the continuation tokens of PaginatedListUsers.
+func WithListUsersTokenSerializer(serializer encoder.ContinuationTokenSerializer) ListUsersQueryOption {
+	return func(d *listUsersQuery) {
+		d.tokenSerializer = serializer
+	}
+}
+
+// StreamListUsers sends the users of the request as the expansion finds them, each one once and without the max
+// results of ListUsers. It stops at the ListUsers deadline, like ListUsers returns partial results. It assumes that
+// the typesystem is in the context and that the request is valid.
+func (l *listUsersQuery) StreamListUsers(
+	ctx context.Context,
+	req *openfgav1.ListUsersRequest,
+	send func(user *openfgav1.User) error,
+) (listUsersResponseMetadata, error) {
+	ctx, span := tracer.Start(ctx, "StreamListUsers", trace.WithAttributes(
+		attribute.String("store_id", req.GetStoreId()),
+	))
+	defer span.End()
+
+	sent := containers.NewSpillSet(l.maxUsersInMemory, "")
+	defer sent.Close()
+
+	metadata, err := l.expandUsers(ctx, req, l.deadline, func(user string) error {
+		added, err := sent.Add(user)
+		if err != nil || !added {
+			return err
+		}
+		return send(tuple.StringToUserProto(user))
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return metadata, err
+	}
+
+	span.SetAttributes(attribute.Int("result_count", sent.Len()))
+	return metadata, nil
+}
+
+type listUsersPageResponse struct {
+	Users []*openfgav1.User
+
+	// ContinuationToken is empty on the last page.
+	ContinuationToken string
+	Metadata          listUsersResponseMetadata
+}
+
+// PaginatedListUsers returns the page of at most pageSize users of the request that follows the continuation
+// token, or the first page if the token is empty. The users are ordered by their string representation, and the
+// token encodes the last user of the previous page, so the pages neither overlap nor miss users whatever the order
+// in which the expansion finds them. A pageSize of 0 returns pages of the ListUsers max results.
+//
+// Every page expands the whole request, keeping in memory only the users of the page, so it is not bounded by
+// the ListUsers deadline but by the deadline of the request. It assumes that the typesystem is in the context and
+// that the request is valid.
+func (l *listUsersQuery) PaginatedListUsers(
+	ctx context.Context,
+	req *openfgav1.ListUsersRequest,
+	pageSize uint32,
+	continuationToken string,
+) (*listUsersPageResponse, error) {
+	ctx, span := tracer.Start(ctx, "PaginatedListUsers", trace.WithAttributes(
+		attribute.String("store_id", req.GetStoreId()),
+		attribute.Int("page_size", int(pageSize)),
+	))
+	defer span.End()
+
+	maxPageSize := l.maxResults
+	if maxPageSize == 0 {
+		maxPageSize = serverconfig.DefaultListUsersMaxResults
+	}
+	if pageSize > maxPageSize {
+		return nil, serverErrors.ValidationError(fmt.Errorf("page size must be at most %d", maxPageSize))
+	}
+	if pageSize == 0 {
+		pageSize = maxPageSize
+	}
+
+	after, err := l.decodeContinuationToken(req, continuationToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// one more user than the page tells whether there is a next page
+	page := containers.NewSmallest(int(pageSize) + 1)
+
+	// the pages must expand the whole request to return the smallest users
+	metadata, err := l.expandUsers(ctx, req, 0, func(user string) error {
+		if user > after {
+			page.Add(user)
+		}
+		return nil
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+
+	// the expansion stops silently when the request is done, which would return a page missing some users
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	keys := page.Sorted()
+	res := &listUsersPageResponse{
+		Metadata: metadata,
+	}
+	if len(keys) > int(pageSize) {
+		keys = keys[:pageSize]
+		res.ContinuationToken, err = l.encodeContinuationToken(req, keys[pageSize-1])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res.Users = make([]*openfgav1.User, 0, len(keys))
+	for _, key := range keys {
+		res.Users = append(res.Users, tuple.StringToUserProto(key))
+	}
+
+	span.SetAttributes(attribute.Int("result_count", len(res.Users)))
+	return res, nil
+}
+
+// decodeContinuationToken returns the last user of the previous page. The user is base64 encoded in the token,
+// since the user IDs may contain the separators of the token serializer.
+func (l *listUsersQuery) decodeContinuationToken(req *openfgav1.ListUsersRequest, continuationToken string) (string, error) {
+	decodedContToken, err := l.encoder.Decode(continuationToken)
+	if err != nil {
+		return "", serverErrors.ErrInvalidContinuationToken
+	}
+
+	if len(decodedContToken) == 0 {
+		return "", nil
+	}
+
+	from, objType, err := l.tokenSerializer.Deserialize(string(decodedContToken))
+	if err != nil || objType != listUsersPageTokenType(req) {
+		return "", serverErrors.ErrInvalidContinuationToken
+	}
+
+	after, err := base64.RawURLEncoding.DecodeString(from)
+	if err != nil || len(after) == 0 {
+		return "", serverErrors.ErrInvalidContinuationToken
+	}
+	return string(after), nil
+}
+
+func (l *listUsersQuery) encodeContinuationToken(req *openfgav1.ListUsersRequest, last string) (string, error) {
+	contToken, err := l.tokenSerializer.Serialize(base64.RawURLEncoding.EncodeToString([]byte(last)), listUsersPageTokenType(req))
+	if err != nil {
+		return "", err
+	}
+	return l.encoder.Encode(contToken)
+}
+
+// listUsersPageTokenType ties the continuation tokens to the object type and relation of their request.
+func listUsersPageTokenType(req *openfgav1.ListUsersRequest) string {
+	return req.GetObject().GetType() + "#" + req.GetRelation()
+}
+
+// expandUsers expands the request like listUsers, but hands the users to handle as they are found instead of
+// collecting them. The users without a relationship are skipped. The expansion stops at the deadline, if not 0,
+// without an error, and stops with the error of handle if it fails.
+func (l *listUsersQuery) expandUsers(
+	ctx context.Context,
+	req *openfgav1.ListUsersRequest,
+	deadline time.Duration,
+	handle func(user string) error,
+) (listUsersResponseMetadata, error) {
+	metadata := listUsersResponseMetadata{
+		DispatchCounter:       new(atomic.Uint32),
+		WasDispatchThrottled:  l.wasDispatchThrottled,
+		WasDatastoreThrottled: l.wasDatastoreThrottled,
+	}
+
+	cancellableCtx, cancelCtx := context.WithCancel(ctx)
+	if deadline != 0 {
+		cancellableCtx, cancelCtx = context.WithTimeout(cancellableCtx, deadline)
+	}
+	defer cancelCtx()
+
+	typesys, ok := typesystem.TypesystemFromContext(cancellableCtx)
+	if !ok {
+		return metadata, fmt.Errorf("%w: typesystem missing in context", openfgaErrors.ErrUnknown)
+	}
+
+	userFilter := req.GetUserFilters()[0]
+	userset := tuple.ToObjectRelationString(tuple.ObjectKey(req.GetObject()), req.GetRelation())
+
+	if !tuple.UsersetMatchTypeAndRelation(userset, userFilter.GetRelation(), userFilter.GetType()) {
+		hasPossibleEdges, err := doesHavePossibleEdges(typesys, req)
+		if err != nil {
+			return metadata, err
+		}
+		if !hasPossibleEdges {
+			return metadata, nil
+		}
+	}
+
+	foundUsersCh := l.buildResultsChannel()
+	expandErrCh := make(chan error, 1)
+
+	go func() {
+		internalRequest := fromListUsersRequest(req, metadata.DispatchCounter)
+		resp := l.expand(cancellableCtx, internalRequest, foundUsersCh)
+		if resp.err != nil {
+			expandErrCh <- resp.err
+		}
+		close(foundUsersCh)
+	}()
+
+	var handleErr error
+	for foundUser := range foundUsersCh {
+		if handleErr != nil || foundUser.relationshipStatus == NoRelationship {
+			// keep draining the channel until the expansion stops
+			continue
+		}
+
+		if handleErr = handle(tuple.UserProtoToString(foundUser.user)); handleErr != nil {
+			cancelCtx()
+		}
+	}
+
+	dsMeta := l.datastore.GetMetadata()
+	l.wasDatastoreThrottled.Store(dsMeta.WasThrottled)
+	metadata.DatastoreQueryCount = dsMeta.DatastoreQueryCount
+	metadata.DatastoreItemCount = dsMeta.DatastoreItemCount
+
+	if handleErr != nil {
+		return metadata, handleErr
+	}
+
+	select {
+	case err := <-expandErrCh:
+		if cancellableCtx.Err() != nil && ctx.Err() == nil || errors.Is(err, context.DeadlineExceeded) {
+			// like ListUsers, the deadline ends the expansion with the users found so far
+			break
+		}
+		return metadata, err
+	default:
+	}
+
+	return metadata, nil
+}
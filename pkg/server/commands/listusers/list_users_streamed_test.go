@@ -0,0 +1,173 @@
+package listusers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestStreamAndPaginateListUsers(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	ctx := context.Background()
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type group
+			relations
+				define member: [user, group#member]
+		type folder
+			relations
+				define blocked: [user]
+				define viewer: [user, group#member] but not blocked`)
+	storeID := ulid.Make().String()
+	require.NoError(t, ds.WriteAuthorizationModel(ctx, storeID, model))
+
+	// the users are reachable directly and through the groups, and a blocked user is excluded
+	var tuples []*openfgav1.TupleKey
+	var expected []string
+	for i := range 12 {
+		user := fmt.Sprintf("user:%02d", i)
+		tuples = append(tuples,
+			tuple.NewTupleKey("folder:audit", "viewer", user),
+			tuple.NewTupleKey(fmt.Sprintf("group:%d", i%3), "member", user),
+		)
+		expected = append(expected, user)
+	}
+	for i := range 3 {
+		tuples = append(tuples, tuple.NewTupleKey("folder:audit", "viewer", fmt.Sprintf("group:%d#member", i)))
+	}
+	tuples = append(tuples, tuple.NewTupleKey("folder:audit", "blocked", "user:05"))
+	expected = append(expected[:5], expected[6:]...)
+	require.NoError(t, ds.Write(ctx, storeID, nil, tuples))
+
+	typesys, err := typesystem.NewAndValidate(ctx, model)
+	require.NoError(t, err)
+	ctx = typesystem.ContextWithTypesystem(ctx, typesys)
+
+	req := &openfgav1.ListUsersRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: model.GetId(),
+		Object:               &openfgav1.Object{Type: "folder", Id: "audit"},
+		Relation:             "viewer",
+		UserFilters:          []*openfgav1.UserTypeFilter{{Type: "user"}},
+	}
+
+	t.Run("stream_sends_every_user_once", func(t *testing.T) {
+		// the max results and the users in memory are below the number of users
+		l := NewListUsersQuery(ds, nil, WithListUsersMaxResults(2), WithListUsersMaxUsersInMemory(3))
+
+		var users []string
+		metadata, err := l.StreamListUsers(ctx, req, func(user *openfgav1.User) error {
+			users = append(users, tuple.UserProtoToString(user))
+			return nil
+		})
+		require.NoError(t, err)
+		require.ElementsMatch(t, expected, users)
+		require.Positive(t, metadata.DatastoreQueryCount)
+	})
+
+	t.Run("stream_stops_when_send_fails", func(t *testing.T) {
+		l := NewListUsersQuery(ds, nil)
+
+		sendErr := errors.New("send")
+		sent := 0
+		_, err := l.StreamListUsers(ctx, req, func(*openfgav1.User) error {
+			sent++
+			return sendErr
+		})
+		require.ErrorIs(t, err, sendErr)
+		require.Equal(t, 1, sent)
+	})
+
+	t.Run("pages", func(t *testing.T) {
+		l := NewListUsersQuery(ds, nil, WithListUsersMaxResults(5))
+
+		var users []string
+		var pages int
+		token := ""
+		for {
+			res, err := l.PaginatedListUsers(ctx, req, 4, token)
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(res.Users), 4)
+			for _, user := range res.Users {
+				users = append(users, tuple.UserProtoToString(user))
+			}
+			pages++
+
+			token = res.ContinuationToken
+			if token == "" {
+				break
+			}
+		}
+		require.Equal(t, expected, users)
+		require.Equal(t, 3, pages)
+
+		_, err := l.PaginatedListUsers(ctx, req, 6, "")
+		require.ErrorContains(t, err, "page size must be at most 5")
+
+		_, err = l.PaginatedListUsers(ctx, req, 0, "invalid")
+		require.ErrorIs(t, err, serverErrors.ErrInvalidContinuationToken)
+
+		res, err := l.PaginatedListUsers(ctx, req, 0, "")
+		require.NoError(t, err)
+		require.Len(t, res.Users, 5)
+
+		_, err = l.PaginatedListUsers(ctx, &openfgav1.ListUsersRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: model.GetId(),
+			Object:               &openfgav1.Object{Type: "folder", Id: "audit"},
+			Relation:             "blocked",
+			UserFilters:          []*openfgav1.UserTypeFilter{{Type: "user"}},
+		}, 0, res.ContinuationToken)
+		require.ErrorIs(t, err, serverErrors.ErrInvalidContinuationToken)
+	})
+
+	for _, approximate := range []bool{false, true} {
+		t.Run(fmt.Sprintf("count_approximate_%t", approximate), func(t *testing.T) {
+			// the count is not bounded by the max results
+			l := NewListUsersQuery(ds, nil, WithListUsersMaxResults(2), WithListUsersMaxUsersInMemory(3))
+
+			res, err := l.CountListUsers(ctx, req, approximate)
+			require.NoError(t, err)
+			require.Equal(t, uint64(len(expected)), res.Count)
+			require.Equal(t, approximate, res.Approximate)
+			require.True(t, res.Complete)
+		})
+	}
+
+	t.Run("canceled_count", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := NewListUsersQuery(ds, nil).CountListUsers(ctx, req, false)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("canceled_page", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := NewListUsersQuery(ds, nil).PaginatedListUsers(ctx, req, 0, "")
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,263 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/validation"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/telemetry"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+const (
+	defaultOrphanedTuplesPageSize      = 100
+	defaultOrphanedTuplesMaxSampleSize = 100
+	// defaultOrphanedTuplesTimeout leaves the rest of the default request timeout to report the partial result.
+	defaultOrphanedTuplesTimeout = 2 * time.Second
+)
+
+// OrphanedTuplesBackend reads the tuples and the active authorization model of a store.
+type OrphanedTuplesBackend interface {
+	storage.RelationshipTupleReader
+	storage.AuthorizationModelReadBackend
+}
+
+// OrphanedTuplesQuery finds the tuples of a store that a new authorization model would invalidate, i.e. the tuples
+// that are valid in the active model of the store but not in the new model, e.g. because the new model removes
+// their relation or narrows its type restrictions. Invalid tuples are ignored at query time, so they would silently
+// stop granting access.
+//
+// Only the tuples that the breaking changes from the active model to the new one may invalidate are read, see
+// typesystem.Diff.
+type OrphanedTuplesQuery struct {
+	backend       OrphanedTuplesBackend
+	pageSize      int
+	maxSampleSize int
+	limit         uint64
+	timeout       time.Duration
+}
+
+type OrphanedTuplesQueryOption func(*OrphanedTuplesQuery)
+
+// WithOrphanedTuplesPageSize sets the number of tuples read from the datastore in each page.
+func WithOrphanedTuplesPageSize(pageSize int) OrphanedTuplesQueryOption {
+	return func(q *OrphanedTuplesQuery) {
+		q.pageSize = pageSize
+	}
+}
+
+// WithOrphanedTuplesMaxSampleSize sets the maximum number of orphaned tuples returned, the others are only counted.
+func WithOrphanedTuplesMaxSampleSize(size int) OrphanedTuplesQueryOption {
+	return func(q *OrphanedTuplesQuery) {
+		q.maxSampleSize = size
+	}
+}
+
+// WithOrphanedTuplesLimit stops the scan once limit orphaned tuples are found. 0 means no limit.
+func WithOrphanedTuplesLimit(limit uint64) OrphanedTuplesQueryOption {
+	return func(q *OrphanedTuplesQuery) {
+		q.limit = limit
+	}
+}
+
+// WithOrphanedTuplesTimeout stops the scan after timeout, with the result of the tuples scanned until then.
+// 0 means no timeout other than the one of the context.
+func WithOrphanedTuplesTimeout(timeout time.Duration) OrphanedTuplesQueryOption {
+	return func(q *OrphanedTuplesQuery) {
+		q.timeout = timeout
+	}
+}
+
+func NewOrphanedTuplesQuery(backend OrphanedTuplesBackend, opts ...OrphanedTuplesQueryOption) *OrphanedTuplesQuery {
+	q := &OrphanedTuplesQuery{
+		backend:       backend,
+		pageSize:      defaultOrphanedTuplesPageSize,
+		maxSampleSize: defaultOrphanedTuplesMaxSampleSize,
+		timeout:       defaultOrphanedTuplesTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+// OrphanedTuple is a tuple that a new authorization model would invalidate, and the reason why.
+type OrphanedTuple struct {
+	TupleKey *openfgav1.TupleKey
+	Reason   string
+}
+
+type OrphanedTuplesResult struct {
+	// ScannedTupleCount is the number of tuples of the store that were validated against the new model, i.e. the
+	// tuples of the types, relations and conditions that the new model breaks.
+	ScannedTupleCount uint64
+
+	// OrphanedTupleCount is the number of tuples that the new model would invalidate.
+	OrphanedTupleCount uint64
+
+	// OrphanedTuples holds up to the max sample size of the orphaned tuples.
+	OrphanedTuples []OrphanedTuple
+
+	// Complete is false when the scan stopped at the limit or at the timeout, in which case the counts only cover
+	// the tuples scanned until then.
+	Complete bool
+}
+
+// Execute reads the tuples of the store that the breaking changes of the new model may invalidate, streaming them
+// page by page, and validates them against the new model. When the store has no active model, no tuple is valid in
+// it, so none is orphaned by the new model.
+func (q *OrphanedTuplesQuery) Execute(ctx context.Context, storeID string, model *typesystem.TypeSystem) (*OrphanedTuplesResult, error) {
+	ctx, span := tracer.Start(ctx, "OrphanedTuplesQuery.Execute", trace.WithAttributes(
+		attribute.String("store_id", storeID),
+	))
+	defer span.End()
+
+	res := &OrphanedTuplesResult{OrphanedTuples: []OrphanedTuple{}}
+
+	activeModel, err := storage.FindActiveAuthorizationModel(ctx, q.backend, storeID)
+	if errors.Is(err, storage.ErrNotFound) {
+		res.Complete = true
+		return res, nil
+	}
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, serverErrors.HandleError("", err)
+	}
+	active, err := typesystem.New(activeModel)
+	if err != nil {
+		return nil, serverErrors.HandleError("", fmt.Errorf("invalid active authorization model: %w", err))
+	}
+
+	if q.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, q.timeout)
+		defer cancel()
+	}
+
+	for _, filter := range orphanedTuplesFilters(active, model) {
+		continuationToken := ""
+		for {
+			tuples, token, err := q.backend.ReadPage(ctx, storeID, filter, storage.ReadPageOptions{
+				Pagination: storage.NewPaginationOptions(int32(q.pageSize), continuationToken),
+			})
+			if err != nil {
+				if errors.Is(err, context.DeadlineExceeded) && ctx.Err() != nil {
+					span.SetAttributes(attribute.Int64("orphaned_tuple_count", int64(res.OrphanedTupleCount)))
+					return res, nil
+				}
+				telemetry.TraceError(span, err)
+				return nil, serverErrors.HandleError("", err)
+			}
+
+			for _, t := range tuples {
+				res.ScannedTupleCount++
+
+				tk := t.GetKey()
+				// the tuples that are already invalid in the active model are not orphaned by the new one
+				err := validation.ValidateTupleForWrite(model, tk)
+				if err == nil || validation.ValidateTupleForWrite(active, tk) != nil {
+					continue
+				}
+
+				res.OrphanedTupleCount++
+				if len(res.OrphanedTuples) < q.maxSampleSize {
+					res.OrphanedTuples = append(res.OrphanedTuples, OrphanedTuple{TupleKey: tk, Reason: orphanedTupleReason(err)})
+				}
+
+				if q.limit > 0 && res.OrphanedTupleCount >= q.limit {
+					span.SetAttributes(attribute.Int64("orphaned_tuple_count", int64(res.OrphanedTupleCount)))
+					return res, nil
+				}
+			}
+
+			continuationToken = token
+			if continuationToken == "" {
+				break
+			}
+		}
+	}
+
+	res.Complete = true
+	span.SetAttributes(attribute.Int64("orphaned_tuple_count", int64(res.OrphanedTupleCount)))
+	return res, nil
+}
+
+// orphanedTuplesFilters returns the filters of the tuples that the breaking changes from the active model to the
+// new one may invalidate: the tuples of the removed types, of the relations that were removed or lost a type
+// restriction, and of the conditions that were removed or whose parameters changed.
+func orphanedTuplesFilters(active, model *typesystem.TypeSystem) []storage.ReadFilter {
+	types := make(map[string]struct{})
+	relations := make(map[string]struct{})
+	conditions := make(map[string][]string)
+	for _, change := range typesystem.Diff(active, model).Changes {
+		switch change.Kind {
+		case typesystem.TypeRemoved:
+			types[change.ObjectType] = struct{}{}
+		case typesystem.RelationRemoved, typesystem.TypeRestrictionRemoved:
+			relations[tuple.ToObjectRelationString(change.ObjectType, change.Relation)] = struct{}{}
+		case typesystem.ConditionRemoved, typesystem.ConditionSignatureChanged:
+			// only the relations with a type restriction of the condition hold valid tuples with it
+			for objectType, typeRelations := range active.GetAllRelations() {
+				for relation, rel := range typeRelations {
+					for _, ref := range rel.GetTypeInfo().GetDirectlyRelatedUserTypes() {
+						if ref.GetCondition() == change.Condition {
+							key := tuple.ToObjectRelationString(objectType, relation)
+							conditions[key] = append(conditions[key], change.Condition)
+							break
+						}
+					}
+				}
+			}
+		default:
+			// the other changes don't invalidate tuples
+		}
+	}
+
+	var filters []storage.ReadFilter
+	for objectType := range types {
+		filters = append(filters, storage.ReadFilter{Object: tuple.BuildObject(objectType, "")})
+	}
+	for key := range relations {
+		objectType, relation := tuple.SplitObjectRelation(key)
+		if _, ok := types[objectType]; ok {
+			continue
+		}
+		filters = append(filters, storage.ReadFilter{Object: tuple.BuildObject(objectType, ""), Relation: relation})
+	}
+	for key, names := range conditions {
+		objectType, relation := tuple.SplitObjectRelation(key)
+		_, typeRead := types[objectType]
+		_, relationRead := relations[key]
+		if typeRead || relationRead {
+			continue
+		}
+		filters = append(filters, storage.ReadFilter{Object: tuple.BuildObject(objectType, ""), Relation: relation, Conditions: names})
+	}
+
+	slices.SortFunc(filters, func(a, b storage.ReadFilter) int {
+		return strings.Compare(a.Object+"#"+a.Relation, b.Object+"#"+b.Relation)
+	})
+	return filters
+}
+
+// orphanedTupleReason returns the cause of the invalid tuple error without the tuple, which is reported apart.
+func orphanedTupleReason(err error) string {
+	var invalidTupleError *tuple.InvalidTupleError
+	if errors.As(err, &invalidTupleError) && invalidTupleError.Cause != nil {
+		return invalidTupleError.Cause.Error()
+	}
+	return err.Error()
+}
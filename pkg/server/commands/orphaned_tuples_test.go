@@ -0,0 +1,196 @@
+package commands
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/mocks"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	storagetest "github.com/openfga/openfga/pkg/storage/test"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestOrphanedTuplesQuery(t *testing.T) {
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID, _ := storagetest.BootstrapFGAStore(t, ds, `
+		model
+			schema 1.1
+		type user
+		type group
+			relations
+				define member: [user]
+		type document
+			relations
+				define owner: [user]
+				define viewer: [user, group#member]`, []string{
+		"group:1#member@user:a",
+		"document:1#owner@user:a",
+		"document:2#owner@user:b",
+		"document:1#viewer@user:a",
+		"document:1#viewer@group:1#member",
+		"document:2#viewer@group:1#member",
+	})
+
+	// the tuples that are already invalid are not orphaned by the new model
+	require.NoError(t, ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:3", "editor", "user:a"),
+	}))
+
+	newModel := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type group
+			relations
+				define member: [user]
+		type document
+			relations
+				define viewer: [user]`)
+	typesys, err := typesystem.NewAndValidate(context.Background(), newModel)
+	require.NoError(t, err)
+
+	t.Run("reports_the_orphaned_tuples", func(t *testing.T) {
+		q := NewOrphanedTuplesQuery(ds, WithOrphanedTuplesPageSize(2), WithOrphanedTuplesMaxSampleSize(3))
+
+		res, err := q.Execute(context.Background(), storeID, typesys)
+		require.NoError(t, err)
+		require.True(t, res.Complete)
+		// only the tuples of document#owner, which is removed, and of document#viewer, which loses the
+		// group#member type restriction, are read
+		require.Equal(t, uint64(5), res.ScannedTupleCount)
+		require.Equal(t, uint64(4), res.OrphanedTupleCount)
+		require.Len(t, res.OrphanedTuples, 3)
+
+		for _, orphan := range res.OrphanedTuples {
+			require.NotEmpty(t, orphan.Reason)
+			require.NotContains(t, orphan.Reason, "Invalid tuple")
+			require.NotEqual(t, "document:3", orphan.TupleKey.GetObject())
+		}
+	})
+
+	t.Run("stops_at_the_limit", func(t *testing.T) {
+		q := NewOrphanedTuplesQuery(ds, WithOrphanedTuplesPageSize(2), WithOrphanedTuplesLimit(2))
+
+		res, err := q.Execute(context.Background(), storeID, typesys)
+		require.NoError(t, err)
+		require.False(t, res.Complete)
+		require.Equal(t, uint64(2), res.OrphanedTupleCount)
+	})
+
+	t.Run("stops_at_the_timeout", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		backend := mocks.NewMockOpenFGADatastore(ctrl)
+		backend.EXPECT().ReadActiveAuthorizationModelID(gomock.Any(), storeID).
+			DoAndReturn(ds.ReadActiveAuthorizationModelID).AnyTimes()
+		backend.EXPECT().FindLatestAuthorizationModel(gomock.Any(), storeID).
+			DoAndReturn(ds.FindLatestAuthorizationModel).AnyTimes()
+		backend.EXPECT().ReadPage(gomock.Any(), storeID, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, _ string, _ storage.ReadFilter, _ storage.ReadPageOptions) ([]*openfgav1.Tuple, string, error) {
+				<-ctx.Done()
+				return nil, "", ctx.Err()
+			}).AnyTimes()
+
+		q := NewOrphanedTuplesQuery(backend, WithOrphanedTuplesTimeout(10*time.Millisecond))
+		res, err := q.Execute(context.Background(), storeID, typesys)
+		require.NoError(t, err)
+		require.False(t, res.Complete)
+		require.Zero(t, res.ScannedTupleCount)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err = NewWriteAuthorizationModelCommand(backend, WithWriteAuthModelOrphanedTuplesThreshold(backend, 3)).
+			checkOrphanedTuples(ctx, storeID, typesys)
+		require.Equal(t, serverErrors.ErrRequestDeadlineExceeded, err)
+	})
+
+	writeRequest := &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   newModel.GetSchemaVersion(),
+		TypeDefinitions: newModel.GetTypeDefinitions(),
+	}
+
+	t.Run("dry_run", func(t *testing.T) {
+		res, err := NewWriteAuthorizationModelCommand(ds).DryRun(context.Background(), writeRequest, NewOrphanedTuplesQuery(ds))
+		require.NoError(t, err)
+		require.Equal(t, uint64(4), res.OrphanedTupleCount)
+
+		_, err = NewWriteAuthorizationModelCommand(ds).DryRun(context.Background(), &openfgav1.WriteAuthorizationModelRequest{
+			StoreId:         storeID,
+			SchemaVersion:   typesystem.SchemaVersion1_1,
+			TypeDefinitions: []*openfgav1.TypeDefinition{{Type: "document"}, {Type: "document"}},
+		}, NewOrphanedTuplesQuery(ds))
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_invalid_authorization_model), status.Code(err))
+	})
+
+	t.Run("write_rejected_above_the_threshold", func(t *testing.T) {
+		_, err := NewWriteAuthorizationModelCommand(ds, WithWriteAuthModelOrphanedTuplesThreshold(ds, 3)).Execute(context.Background(), writeRequest)
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_invalid_authorization_model), status.Code(err))
+		require.ErrorContains(t, err, "the model would invalidate more than 3 existing tuples")
+
+		resp, err := NewWriteAuthorizationModelCommand(ds, WithWriteAuthModelOrphanedTuplesThreshold(ds, 4)).Execute(context.Background(), writeRequest)
+		require.NoError(t, err)
+		require.NotEmpty(t, resp.GetAuthorizationModelId())
+	})
+}
+
+func TestOrphanedTuplesFilters(t *testing.T) {
+	active := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type team
+			relations
+				define member: [user]
+		type document
+			relations
+				define owner: [user]
+				define editor: [user, user with in_region]
+				define viewer: [user, team#member]
+				define parent: [document]
+
+		condition in_region(region: string) {
+			region == "eu"
+		}`)
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define editor: [user, user with in_region]
+				define viewer: [user]
+				define parent: [document]
+				define reader: viewer or editor
+
+		condition in_region(region: int) {
+			region == 1
+		}`)
+
+	activeTypesys, err := typesystem.NewAndValidate(context.Background(), active)
+	require.NoError(t, err)
+	typesys, err := typesystem.NewAndValidate(context.Background(), model)
+	require.NoError(t, err)
+
+	require.Equal(t, []storage.ReadFilter{
+		{Object: "document:", Relation: "editor", Conditions: []string{"in_region"}},
+		{Object: "document:", Relation: "owner"},
+		{Object: "document:", Relation: "viewer"},
+		{Object: "team:"},
+	}, orphanedTuplesFilters(activeTypesys, typesys))
+
+	require.Empty(t, orphanedTuplesFilters(activeTypesys, activeTypesys))
+}
@@ -70,6 +70,25 @@ func New(backend *Backend, options ...Option) *Pipeline {
 		option(p)
 	}
 
+	// the objects of a type that is never a user are never traversed
+	// further, so their tuples can be pruned when they are read.
+	b := *backend
+	pruned := false
+	if p.objectIDFilterType != "" && !backend.TypeSystem.IsDirectlyRelatedUserType(p.objectIDFilterType) {
+		b.objectIDFilterType = p.objectIDFilterType
+		b.objectIDs = p.objectIDs
+		b.objectIDPrefix = p.objectIDPrefix
+		pruned = true
+	}
+	if objectType, objectID, _ := strings.Cut(p.resumeAfter, ":"); p.resumeAfter != "" && !backend.TypeSystem.IsDirectlyRelatedUserType(objectType) {
+		b.resumeAfterType = objectType
+		b.resumeAfterObjectID = objectID
+		pruned = true
+	}
+	if pruned {
+		p.backend = &b
+	}
+
 	p.bufferPool = newBufferPool(p.chunkSize)
 	return p
 }
@@ -104,6 +123,31 @@ func WithNumProcs(num int) Option {
 	}
 }
 
+// WithResumeAfter resumes a traversal after the provided object: only the
+// objects ordered after it are yielded. Paginated callers use the last object
+// of a page to resume the traversal for the next page. When the objects of
+// its type are never the users of a tuple, the tuples of the objects ordered
+// before it are also skipped when read, so that a page doesn't read again the
+// objects of the previous pages.
+func WithResumeAfter(object string) Option {
+	return func(p *Pipeline) {
+		p.resumeAfter = object
+	}
+}
+
+// WithObjectIDFilter restricts the yielded objects of the objectType to the
+// provided object IDs, when not nil, and to the object IDs starting with the
+// provided prefix. When the objects of the objectType are never the users of
+// a tuple, the tuples of these objects are also restricted when read, so that
+// the traversal prunes the other objects early.
+func WithObjectIDFilter(objectType string, objectIDs storage.SortedSet, prefix string) Option {
+	return func(p *Pipeline) {
+		p.objectIDFilterType = objectType
+		p.objectIDs = objectIDs
+		p.objectIDPrefix = prefix
+	}
+}
+
 type bufferPool struct {
 	size int
 	pool sync.Pool
@@ -155,6 +199,17 @@ type Backend struct {
 	Context    *structpb.Struct
 	Graph      *Graph
 	Preference openfgav1.ConsistencyPreference
+
+	// objectIDFilterType, objectIDs and objectIDPrefix restrict the tuples
+	// read for the objects of objectIDFilterType, see WithObjectIDFilter.
+	objectIDFilterType string
+	objectIDs          storage.SortedSet
+	objectIDPrefix     string
+
+	// resumeAfterType and resumeAfterObjectID restrict the tuples read for
+	// the objects of resumeAfterType, see WithResumeAfter.
+	resumeAfterType     string
+	resumeAfterObjectID string
 }
 
 // handleDirectEdge is a function that interprets input on a direct edge and provides output from
@@ -266,15 +321,26 @@ func (b *Backend) handleTTUEdge(ctx context.Context, edge *Edge, items []string)
 func (b *Backend) query(ctx context.Context, input queryInput) iter.Seq[Item] {
 	ctx, cancel := context.WithCancel(ctx)
 
+	filter := storage.ReadStartingWithUserFilter{
+		ObjectType: input.objectType,
+		Relation:   input.objectRelation,
+		UserFilter: input.userFilter,
+		Conditions: input.conditions,
+	}
+
+	if b.objectIDFilterType != "" && input.objectType == b.objectIDFilterType {
+		filter.ObjectIDs = b.objectIDs
+		filter.ObjectIDPrefix = b.objectIDPrefix
+	}
+
+	if b.resumeAfterType != "" && input.objectType == b.resumeAfterType {
+		filter.ObjectIDAfter = b.resumeAfterObjectID
+	}
+
 	it, err := b.Datastore.ReadStartingWithUser(
 		ctx,
 		b.StoreID,
-		storage.ReadStartingWithUserFilter{
-			ObjectType: input.objectType,
-			Relation:   input.objectRelation,
-			UserFilter: input.userFilter,
-			Conditions: input.conditions,
-		},
+		filter,
 		storage.ReadStartingWithUserOptions{
 			Consistency: storage.ConsistencyOptions{
 				Preference: b.Preference,
@@ -1014,6 +1080,25 @@ type Pipeline struct {
 	// The default value is 3. This value can be changed by constructing
 	// a pipeline using NewPipeline and providing the option WithNumProcs.
 	numProcs int
+
+	// resumeAfter is an object that, when set, excludes from the results
+	// the objects ordered before or equal to it. The objects are still
+	// traversed, since the objects after it may be reached through them.
+	//
+	// The default value is empty, which yields every object. This value can
+	// be changed by constructing a pipeline using NewPipeline and providing
+	// the option WithResumeAfter.
+	resumeAfter string
+
+	// objectIDFilterType, objectIDs and objectIDPrefix restrict the yielded
+	// objects of objectIDFilterType.
+	//
+	// The default value is empty, which yields every object. This value can
+	// be changed by constructing a pipeline using NewPipeline and providing
+	// the option WithObjectIDFilter.
+	objectIDFilterType string
+	objectIDs          storage.SortedSet
+	objectIDPrefix     string
 }
 
 type pipelineWorker = Worker[*Edge, *Message, *Message]
@@ -1079,6 +1164,12 @@ func (pl *Pipeline) Build(ctx context.Context, source Source, target Target) ite
 		for msg := range results.Seq() {
 			if ctx.Err() == nil {
 				for _, item := range msg.Value {
+					if pl.resumeAfter != "" && item.Err == nil && item.Value <= pl.resumeAfter {
+						continue
+					}
+					if item.Err == nil && !pl.matchesObjectIDFilter(item.Value) {
+						continue
+					}
 					if !yield(item) {
 						cancel()
 						break
@@ -1090,6 +1181,26 @@ func (pl *Pipeline) Build(ctx context.Context, source Source, target Target) ite
 	}
 }
 
+// matchesObjectIDFilter returns true if the object passes the filter of
+// WithObjectIDFilter. The filter is applied to the results even when it was
+// pushed down, since the datastores may ignore it.
+func (pl *Pipeline) matchesObjectIDFilter(object string) bool {
+	if pl.objectIDFilterType == "" {
+		return true
+	}
+
+	objectType, objectID, _ := strings.Cut(object, ":")
+	if objectType != pl.objectIDFilterType {
+		return true
+	}
+
+	if pl.objectIDs != nil && !pl.objectIDs.Exists(objectID) {
+		return false
+	}
+
+	return strings.HasPrefix(objectID, pl.objectIDPrefix)
+}
+
 func (pl *Pipeline) Source(name, relation string) (Source, bool) {
 	sourceNode, ok := pl.backend.Graph.GetNodeByID(name + "#" + relation)
 	return (Source)(sourceNode), ok
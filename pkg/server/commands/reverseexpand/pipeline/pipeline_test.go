@@ -2292,6 +2292,44 @@ func TestPipeline(t *testing.T) {
 		}
 	})
 
+	t.Run("resume_after", func(t *testing.T) {
+		defer goleak.VerifyNone(t)
+
+		ds := memory.New()
+		t.Cleanup(ds.Close)
+
+		storeID, model := storagetest.BootstrapFGAStore(t, ds, cycleModel, cycleTuples)
+
+		typesys, err := typesystem.NewAndValidate(
+			context.Background(),
+			model,
+		)
+		require.NoError(t, err)
+
+		backend := &Backend{
+			Datastore:  ds,
+			StoreID:    storeID,
+			TypeSystem: typesys,
+			Context:    nil,
+			Graph:      typesys.GetWeightedGraph(),
+		}
+
+		pl := New(backend, WithResumeAfter("document:1"))
+
+		target, ok := pl.Target("user", "1")
+		require.True(t, ok)
+
+		source, ok := pl.Source("document", "viewer")
+		require.True(t, ok)
+
+		var results []string
+		for item := range pl.Build(context.Background(), source, target) {
+			require.NoError(t, item.Err)
+			results = append(results, item.Value)
+		}
+		require.Equal(t, []string{"document:2"}, results)
+	})
+
 	t.Run("iterator_cancelation", func(t *testing.T) {
 		defer goleak.VerifyNone(t)
 
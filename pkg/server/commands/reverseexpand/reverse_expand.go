@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"sync/atomic"
 
@@ -144,6 +145,20 @@ type ReverseExpandQuery struct {
 	// localCheckResolver allows reverse expand to call check locally
 	localCheckResolver   graph.CheckRewriteResolver
 	optimizationsEnabled bool
+
+	// objectIDFilterType, objectIDs and objectIDPrefix restrict the candidate objects, see WithObjectIDFilter.
+	objectIDFilterType string
+	objectIDs          storage.SortedSet
+	objectIDPrefix     string
+
+	// pushDownObjectIDFilter indicates that the reads of the objects of objectIDFilterType can be restricted too
+	pushDownObjectIDFilter bool
+
+	// resumeAfter excludes the candidate objects ordered before or equal to it, see WithResumeAfter
+	resumeAfter string
+
+	// pushDownResumeAfter indicates that the reads of the objects of the type of resumeAfter can be restricted too
+	pushDownResumeAfter bool
 }
 
 type ReverseExpandQueryOption func(d *ReverseExpandQuery)
@@ -181,6 +196,27 @@ func WithListObjectOptimizationsEnabled(enabled bool) ReverseExpandQueryOption {
 	}
 }
 
+// WithObjectIDFilter restricts the candidate objects of the objectType to the provided object IDs, when not nil,
+// and to the object IDs starting with the provided prefix. When the objects of the objectType are never the users
+// of a tuple, the tuples of these objects are also restricted when read, so that the expansion prunes the other
+// objects early.
+func WithObjectIDFilter(objectType string, objectIDs storage.SortedSet, prefix string) ReverseExpandQueryOption {
+	return func(d *ReverseExpandQuery) {
+		d.objectIDFilterType = objectType
+		d.objectIDs = objectIDs
+		d.objectIDPrefix = prefix
+	}
+}
+
+// WithResumeAfter excludes the candidate objects ordered before or equal to the provided object, so that a paginated
+// caller resumes the expansion after the last object of a page. When the objects of its type are never the users of
+// a tuple, the tuples of these objects are also skipped when read.
+func WithResumeAfter(object string) ReverseExpandQueryOption {
+	return func(d *ReverseExpandQuery) {
+		d.resumeAfter = object
+	}
+}
+
 // TODO accept ReverseExpandRequest so we can build the datastore object right away.
 func NewReverseExpandQuery(ds storage.RelationshipTupleReader, ts *typesystem.TypeSystem, opts ...ReverseExpandQueryOption) *ReverseExpandQuery {
 	query := &ReverseExpandQuery{
@@ -205,6 +241,16 @@ func NewReverseExpandQuery(ds storage.RelationshipTupleReader, ts *typesystem.Ty
 		opt(query)
 	}
 
+	if query.objectIDFilterType != "" {
+		// the objects of a type that is never a user are never expanded further, so their tuples can be pruned
+		query.pushDownObjectIDFilter = !ts.IsDirectlyRelatedUserType(query.objectIDFilterType)
+	}
+
+	if query.resumeAfter != "" {
+		resumeAfterType, _ := tuple.SplitObject(query.resumeAfter)
+		query.pushDownResumeAfter = !ts.IsDirectlyRelatedUserType(resumeAfterType)
+	}
+
 	return query
 }
 
@@ -609,11 +655,11 @@ func (c *ReverseExpandQuery) readTuplesAndExecute(
 	}
 
 	// find all tuples of the form req.edge.TargetReference.Type:...#relationFilter@userFilter
-	iter, err := c.datastore.ReadStartingWithUser(ctx, req.StoreID, storage.ReadStartingWithUserFilter{
-		ObjectType: req.edge.TargetReference.GetType(),
-		Relation:   relationFilter,
-		UserFilter: userFilter,
-	}, storage.ReadStartingWithUserOptions{
+	iter, err := c.datastore.ReadStartingWithUser(ctx, req.StoreID, c.readStartingWithUserFilter(
+		req.edge.TargetReference.GetType(),
+		relationFilter,
+		userFilter,
+	), storage.ReadStartingWithUserOptions{
 		Consistency: storage.ConsistencyOptions{
 			Preference: req.Consistency,
 		},
@@ -708,6 +754,14 @@ func (c *ReverseExpandQuery) trySendCandidate(
 	))
 	defer span.End()
 
+	if !c.matchesObjectIDFilter(candidateObject) {
+		return
+	}
+
+	if c.resumeAfter != "" && candidateObject <= c.resumeAfter {
+		return
+	}
+
 	if _, ok := c.candidateObjectsMap.LoadOrStore(candidateObject, struct{}{}); !ok {
 		resultStatus := NoFurtherEvalStatus
 		if intersectionOrExclusionInPreviousEdges {
@@ -723,6 +777,48 @@ func (c *ReverseExpandQuery) trySendCandidate(
 	}
 }
 
+// readStartingWithUserFilter returns the filter of the tuples of the objectType, restricted to the object IDs of
+// WithObjectIDFilter and WithResumeAfter when they can be pruned.
+func (c *ReverseExpandQuery) readStartingWithUserFilter(
+	objectType string,
+	relation string,
+	userFilter []*openfgav1.ObjectRelation,
+) storage.ReadStartingWithUserFilter {
+	filter := storage.ReadStartingWithUserFilter{
+		ObjectType: objectType,
+		Relation:   relation,
+		UserFilter: userFilter,
+	}
+
+	if c.pushDownObjectIDFilter && objectType == c.objectIDFilterType {
+		filter.ObjectIDs = c.objectIDs
+		filter.ObjectIDPrefix = c.objectIDPrefix
+	}
+
+	if c.pushDownResumeAfter {
+		if resumeAfterType, resumeAfterObjectID := tuple.SplitObject(c.resumeAfter); objectType == resumeAfterType {
+			filter.ObjectIDAfter = resumeAfterObjectID
+		}
+	}
+
+	return filter
+}
+
+// matchesObjectIDFilter returns true if the object passes the filter of WithObjectIDFilter. The filter is applied
+// to the candidates even when it was pushed down, since the datastores may ignore it.
+func (c *ReverseExpandQuery) matchesObjectIDFilter(object string) bool {
+	objectType, objectID := tuple.SplitObject(object)
+	if c.objectIDFilterType == "" || objectType != c.objectIDFilterType {
+		return true
+	}
+
+	if c.objectIDs != nil && !c.objectIDs.Exists(objectID) {
+		return false
+	}
+
+	return strings.HasPrefix(objectID, c.objectIDPrefix)
+}
+
 func (c *ReverseExpandQuery) throttle(ctx context.Context, currentNumDispatch uint32, metadata *ResolutionMetadata) {
 	span := trace.SpanFromContext(ctx)
 
@@ -512,11 +512,11 @@ func (c *ReverseExpandQuery) buildFilteredIterator(
 	relation string,
 	userFilter []*openfgav1.ObjectRelation,
 ) (storage.TupleKeyIterator, error) {
-	iter, err := c.datastore.ReadStartingWithUser(ctx, req.StoreID, storage.ReadStartingWithUserFilter{
-		ObjectType: objectType,
-		Relation:   relation,
-		UserFilter: userFilter,
-	}, storage.ReadStartingWithUserOptions{
+	iter, err := c.datastore.ReadStartingWithUser(ctx, req.StoreID, c.readStartingWithUserFilter(
+		objectType,
+		relation,
+		userFilter,
+	), storage.ReadStartingWithUserOptions{
 		Consistency: storage.ConsistencyOptions{
 			Preference: req.Consistency,
 		},
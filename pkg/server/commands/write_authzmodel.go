@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/oklog/ulid/v2"
+	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
@@ -15,6 +16,7 @@ import (
 	serverconfig "github.com/openfga/openfga/pkg/server/config"
 	serverErrors "github.com/openfga/openfga/pkg/server/errors"
 	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
 	"github.com/openfga/openfga/pkg/typesystem"
 )
 
@@ -23,6 +25,9 @@ type WriteAuthorizationModelCommand struct {
 	backend                          storage.TypeDefinitionWriteBackend
 	logger                           logger.Logger
 	maxAuthorizationModelSizeInBytes int
+
+	orphanedTuplesBackend   OrphanedTuplesBackend
+	orphanedTuplesThreshold uint64
 }
 
 type WriteAuthModelOption func(*WriteAuthorizationModelCommand)
@@ -39,6 +44,15 @@ func WithWriteAuthModelMaxSizeInBytes(size int) WriteAuthModelOption {
 	}
 }
 
+// WithWriteAuthModelOrphanedTuplesThreshold rejects the models that would invalidate more than threshold existing
+// tuples of the store, see OrphanedTuplesQuery.
+func WithWriteAuthModelOrphanedTuplesThreshold(backend OrphanedTuplesBackend, threshold uint64) WriteAuthModelOption {
+	return func(m *WriteAuthorizationModelCommand) {
+		m.orphanedTuplesBackend = backend
+		m.orphanedTuplesThreshold = threshold
+	}
+}
+
 func NewWriteAuthorizationModelCommand(backend storage.TypeDefinitionWriteBackend, opts ...WriteAuthModelOption) *WriteAuthorizationModelCommand {
 	model := &WriteAuthorizationModelCommand{
 		backend:                          backend,
@@ -54,9 +68,44 @@ func NewWriteAuthorizationModelCommand(backend storage.TypeDefinitionWriteBacken
 
 // Execute the command using the supplied request.
 func (w *WriteAuthorizationModelCommand) Execute(ctx context.Context, req *openfgav1.WriteAuthorizationModelRequest) (*openfgav1.WriteAuthorizationModelResponse, error) {
+	model, typesys, err := w.validateModel(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.orphanedTuplesBackend != nil {
+		if err := w.checkOrphanedTuples(ctx, req.GetStoreId(), typesys); err != nil {
+			return nil, err
+		}
+	}
+
+	err = w.backend.WriteAuthorizationModel(ctx, req.GetStoreId(), model)
+	if err != nil {
+		return nil, serverErrors.
+			HandleError("Error writing authorization model configuration", err)
+	}
+
+	return &openfgav1.WriteAuthorizationModelResponse{
+		AuthorizationModelId: model.GetId(),
+	}, nil
+}
+
+// DryRun validates the model of the request without writing it, and returns the existing tuples of the store that
+// it would invalidate.
+func (w *WriteAuthorizationModelCommand) DryRun(ctx context.Context, req *openfgav1.WriteAuthorizationModelRequest, q *OrphanedTuplesQuery) (*OrphanedTuplesResult, error) {
+	_, typesys, err := w.validateModel(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.Execute(ctx, req.GetStoreId(), typesys)
+}
+
+// validateModel returns the model of the request and its typesystem, or an error if it is not valid.
+func (w *WriteAuthorizationModelCommand) validateModel(ctx context.Context, req *openfgav1.WriteAuthorizationModelRequest) (*openfgav1.AuthorizationModel, *typesystem.TypeSystem, error) {
 	// Until this is solved: https://github.com/envoyproxy/protoc-gen-validate/issues/74
 	if len(req.GetTypeDefinitions()) > w.backend.MaxTypesPerAuthorizationModel() {
-		return nil, serverErrors.ExceededEntityLimit("type definitions in an authorization model", w.backend.MaxTypesPerAuthorizationModel())
+		return nil, nil, serverErrors.ExceededEntityLimit("type definitions in an authorization model", w.backend.MaxTypesPerAuthorizationModel())
 	}
 
 	// Fill in the schema version for old requests, which don't contain it, while we migrate to the new schema version.
@@ -75,24 +124,47 @@ func (w *WriteAuthorizationModelCommand) Execute(ctx context.Context, req *openf
 	modelSize := proto.Size(model)
 	if modelSize > w.maxAuthorizationModelSizeInBytes {
 		// Consider using serverErrors.ExceededEntityLimit.
-		return nil, status.Error(
+		return nil, nil, status.Error(
 			codes.Code(openfgav1.ErrorCode_exceeded_entity_limit),
 			fmt.Sprintf("model exceeds size limit: %d bytes vs %d bytes", modelSize, w.maxAuthorizationModelSizeInBytes),
 		)
 	}
 
-	_, err := typesystem.NewAndValidate(ctx, model)
+	typesys, err := typesystem.NewAndValidate(ctx, model)
 	if err != nil {
-		return nil, serverErrors.InvalidAuthorizationModelInput(err)
+		return nil, nil, serverErrors.InvalidAuthorizationModelInput(err)
 	}
 
-	err = w.backend.WriteAuthorizationModel(ctx, req.GetStoreId(), model)
+	return model, typesys, nil
+}
+
+// checkOrphanedTuples returns an error if the model would invalidate more than the threshold of existing tuples.
+func (w *WriteAuthorizationModelCommand) checkOrphanedTuples(ctx context.Context, storeID string, typesys *typesystem.TypeSystem) error {
+	q := NewOrphanedTuplesQuery(w.orphanedTuplesBackend,
+		WithOrphanedTuplesMaxSampleSize(1),
+		WithOrphanedTuplesLimit(w.orphanedTuplesThreshold+1),
+	)
+
+	res, err := q.Execute(ctx, storeID, typesys)
 	if err != nil {
-		return nil, serverErrors.
-			HandleError("Error writing authorization model configuration", err)
+		return err
 	}
 
-	return &openfgav1.WriteAuthorizationModelResponse{
-		AuthorizationModelId: model.GetId(),
-	}, nil
+	if res.OrphanedTupleCount <= w.orphanedTuplesThreshold {
+		if !res.Complete {
+			// the scan timed out, the model may still invalidate more tuples than the threshold
+			w.logger.WarnWithContext(ctx, "timed out counting the tuples orphaned by the authorization model",
+				zap.String("store_id", storeID),
+				zap.Uint64("scanned_tuple_count", res.ScannedTupleCount),
+			)
+			return serverErrors.ErrRequestDeadlineExceeded
+		}
+		return nil
+	}
+
+	orphan := res.OrphanedTuples[0]
+	return serverErrors.InvalidAuthorizationModelInput(fmt.Errorf(
+		"the model would invalidate more than %d existing tuples, e.g. '%s': %s",
+		w.orphanedTuplesThreshold, tuple.TupleKeyToString(orphan.TupleKey), orphan.Reason,
+	))
 }
@@ -16,9 +16,18 @@ type CacheSettings struct {
 	ListObjectsIteratorCacheEnabled    bool
 	ListObjectsIteratorCacheMaxResults uint32
 	ListObjectsIteratorCacheTTL        time.Duration
+	ListObjectsQueryCacheEnabled       bool
+	ListObjectsQueryCacheTTL           time.Duration
 	SharedIteratorEnabled              bool
 	SharedIteratorLimit                uint32
 	SharedIteratorTTL                  time.Duration
+
+	// CheckCacheValkeyEnabled stores the check cache in Valkey instead of in memory. See CheckCacheValkeyConfig.
+	CheckCacheValkeyEnabled          bool
+	CheckCacheValkeyURI              string
+	CheckCacheValkeyKeyPrefix        string
+	CheckCacheValkeyLocalTierEnabled bool
+	CheckCacheValkeyLocalTierTTL     time.Duration
 }
 
 func NewDefaultCacheSettings() CacheSettings {
@@ -34,14 +43,21 @@ func NewDefaultCacheSettings() CacheSettings {
 		ListObjectsIteratorCacheEnabled:    DefaultListObjectsIteratorCacheEnabled,
 		ListObjectsIteratorCacheMaxResults: DefaultListObjectsIteratorCacheMaxResults,
 		ListObjectsIteratorCacheTTL:        DefaultListObjectsIteratorCacheTTL,
+		ListObjectsQueryCacheEnabled:       DefaultListObjectsQueryCacheEnabled,
+		ListObjectsQueryCacheTTL:           DefaultListObjectsQueryCacheTTL,
 		SharedIteratorEnabled:              DefaultSharedIteratorEnabled,
 		SharedIteratorLimit:                DefaultSharedIteratorLimit,
 		SharedIteratorTTL:                  DefaultSharedIteratorTTL,
+		CheckCacheValkeyEnabled:            DefaultCheckCacheValkeyEnabled,
+		CheckCacheValkeyKeyPrefix:          DefaultCheckCacheValkeyKeyPrefix,
+		CheckCacheValkeyLocalTierEnabled:   DefaultCheckCacheValkeyLocalTierEnabled,
+		CheckCacheValkeyLocalTierTTL:       DefaultCheckCacheValkeyLocalTierTTL,
 	}
 }
 
 func (c CacheSettings) ShouldCreateNewCache() bool {
-	return c.ShouldCacheCheckQueries() || c.ShouldCacheCheckIterators() || c.ShouldCacheListObjectsIterators()
+	return c.ShouldCacheCheckQueries() || c.ShouldCacheCheckIterators() || c.ShouldCacheListObjectsIterators() ||
+		c.ShouldCacheListObjectsQueries()
 }
 
 func (c CacheSettings) ShouldCreateCacheController() bool {
@@ -60,6 +76,12 @@ func (c CacheSettings) ShouldCacheListObjectsIterators() bool {
 	return c.ListObjectsIteratorCacheEnabled && c.ListObjectsIteratorCacheMaxResults > 0
 }
 
+// ShouldCacheListObjectsQueries returns true if the ListObjects results should be cached. They are stored in the
+// cache of Check, and are only invalidated when the cache controller is enabled.
+func (c CacheSettings) ShouldCacheListObjectsQueries() bool {
+	return c.CheckCacheLimit > 0 && c.ListObjectsQueryCacheEnabled
+}
+
 func (c CacheSettings) ShouldCreateShadowNewCache() bool {
 	return c.ShouldCreateNewCache()
 }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -17,22 +18,33 @@ const (
 	DefaultMaxTuplesPerWrite                = 100
 	DefaultMaxTypesPerAuthorizationModel    = 100
 	DefaultMaxAuthorizationModelSizeInBytes = 256 * 1_024
-	DefaultMaxAuthorizationModelCacheSize   = 100000
-	DefaultChangelogHorizonOffset           = 0
-	DefaultResolveNodeLimit                 = 25
-	DefaultResolveNodeBreadthLimit          = 10
-	DefaultListObjectsDeadline              = 3 * time.Second
-	DefaultListObjectsMaxResults            = 1000
-	DefaultMaxConcurrentReadsForCheck       = math.MaxUint32
-	DefaultMaxConcurrentReadsForListObjects = math.MaxUint32
-	DefaultListUsersDeadline                = 3 * time.Second
-	DefaultListUsersMaxResults              = 1000
-	DefaultMaxConcurrentReadsForListUsers   = math.MaxUint32
+
+	DefaultAuthorizationModelOrphanedTuplesCheckEnabled   = false
+	DefaultAuthorizationModelOrphanedTuplesCheckThreshold = 0
+	DefaultMaxAuthorizationModelCacheSize                 = 100000
+	DefaultChangelogHorizonOffset                         = 0
+	DefaultResolveNodeLimit                               = 25
+	DefaultResolveNodeBreadthLimit                        = 10
+	DefaultListObjectsDeadline                            = 3 * time.Second
+	DefaultListObjectsMaxResults                          = 1000
+	DefaultListObjectsMaxObjectsInMemory                  = 100000
+	DefaultListObjectsMaxCandidateObjectIDs               = 5000
+	DefaultMaxConcurrentReadsForCheck                     = math.MaxUint32
+	DefaultMaxConcurrentReadsForListObjects               = math.MaxUint32
+	DefaultListUsersDeadline                              = 3 * time.Second
+	DefaultListUsersMaxResults                            = 1000
+	DefaultListUsersMaxUsersInMemory                      = 100000
+	DefaultMaxConcurrentReadsForListUsers                 = math.MaxUint32
 
 	DefaultWriteContextByteLimit = 32 * 1_024 // 32KB
 
 	DefaultCheckCacheLimit = 10000
 
+	DefaultCheckCacheValkeyEnabled          = false
+	DefaultCheckCacheValkeyKeyPrefix        = "openfga:cache:"
+	DefaultCheckCacheValkeyLocalTierEnabled = false
+	DefaultCheckCacheValkeyLocalTierTTL     = 1 * time.Second
+
 	DefaultCacheControllerEnabled = false
 	DefaultCacheControllerTTL     = 10 * time.Second
 
@@ -47,6 +59,9 @@ const (
 	DefaultListObjectsIteratorCacheMaxResults = 10000
 	DefaultListObjectsIteratorCacheTTL        = 10 * time.Second
 
+	DefaultListObjectsQueryCacheEnabled = false
+	DefaultListObjectsQueryCacheTTL     = 10 * time.Second
+
 	DefaultListObjectsOptimizationsEnabled = false
 
 	DefaultCacheControllerConfigEnabled = false
@@ -57,6 +72,13 @@ const (
 	DefaultShadowListObjectsQueryTimeout       = 1 * time.Second
 	DefaultShadowListObjectsQueryMaxDeltaItems = 100
 
+	DefaultShadowListUsersQueryTimeout          = 1 * time.Second
+	DefaultShadowListUsersQueryMaxDeltaItems    = 100
+	DefaultShadowListUsersQuerySamplePercentage = 10
+
+	DefaultCandidateModelMaxDivergences   = 100
+	DefaultCandidateModelSamplePercentage = 10
+
 	// Care should be taken here - decreasing can cause API compatibility problems with Conditions.
 	DefaultMaxConditionEvaluationCost = 100
 	DefaultInterruptCheckFrequency    = 100
@@ -92,6 +114,59 @@ const (
 	DefaultPlannerEvictionThreshold = 0
 	DefaultPlannerCleanupInterval   = 0
 
+	DefaultPlannerSnapshotBackend       = PlannerSnapshotBackendNone
+	DefaultPlannerSnapshotInterval      = 1 * time.Minute
+	DefaultPlannerSnapshotKeyPrefix     = "openfga:planner:"
+	DefaultPlannerSnapshotMergeReplicas = false
+	DefaultPlannerSnapshotMaxAge        = 24 * time.Hour
+
+	DefaultPlannerListStrategiesEnabled             = false
+	DefaultPlannerListStrategiesRequiredValidations = 10
+	DefaultPlannerListStrategiesShadowTimeout       = 1 * time.Second
+
+	DefaultPeerDispatchEnabled            = false
+	DefaultPeerDispatchAddr               = "0.0.0.0:8082"
+	DefaultPeerDispatchDNSRefreshInterval = 10 * time.Second
+	DefaultPeerDispatchTimeout            = 1 * time.Second
+	DefaultPeerDispatchVirtualNodes       = 100
+
+	DefaultCheckPermissionIndexEnabled         = false
+	DefaultCheckPermissionIndexRefreshInterval = 5 * time.Second
+	DefaultCheckPermissionIndexMaxStaleness    = 10 * time.Second
+	DefaultCheckPermissionIndexValkeyKeyPrefix = "openfga:permissionindex:"
+
+	DefaultCheckSingleflightEnabled = false
+
+	DefaultEdgeSyncEnabled      = false
+	DefaultEdgeSyncPollInterval = 1 * time.Second
+	DefaultEdgeSyncCursorTTL    = 10 * time.Minute
+
+	DefaultDatastoreAdaptiveConcurrencyEnabled          = false
+	DefaultDatastoreAdaptiveConcurrencyInitialLimit     = 10
+	DefaultDatastoreAdaptiveConcurrencyMinLimit         = 1
+	DefaultDatastoreAdaptiveConcurrencyLatencyThreshold = 50 * time.Millisecond
+	DefaultDatastoreAdaptiveConcurrencyBackoffRatio     = 0.9
+
+	DefaultCheckCostAdmissionEnabled                = false
+	DefaultCheckCostAdmissionAction                 = CheckCostAdmissionActionDeprioritize
+	DefaultCheckCostAdmissionMaxReads               = 1000
+	DefaultCheckCostAdmissionMaxDispatches          = 500
+	DefaultCheckCostAdmissionDeprioritizedFrequency = 10 * time.Millisecond
+	DefaultCheckCostAdmissionStatisticsTTL          = 10 * time.Minute
+
+	DefaultFairDispatchThrottlingEnabled   = false
+	DefaultFairDispatchThrottlingPerClient = false
+	// DefaultFairDispatchThrottlingCheckFrequency is a tenth of DefaultCheckDispatchThrottlingFrequency, because the
+	// fair throttler of Check is shared by all the requests instead of one per request.
+	DefaultFairDispatchThrottlingCheckFrequency = time.Microsecond
+
+	DefaultLoadSheddingEnabled                = false
+	DefaultLoadSheddingMaxInflight            = 1000
+	DefaultLoadSheddingLatencyTarget          = 0 * time.Millisecond
+	DefaultLoadSheddingMaxThrottledDispatches = 0
+	DefaultLoadSheddingLowPriorityThreshold   = 0.8
+	DefaultLoadSheddingRetryAfter             = 1 * time.Second
+
 	ExperimentalCheckOptimizations       = "enable-check-optimizations"
 	ExperimentalListObjectsOptimizations = "enable-list-objects-optimizations"
 	ExperimentalAccessControlParams      = "enable-access-control"
@@ -101,6 +176,7 @@ const (
 	// 2. Flag names should have only numbers, letters and underscores.
 	ExperimentalShadowCheck         = "shadow_check"
 	ExperimentalShadowListObjects   = "shadow_list_objects"
+	ExperimentalShadowListUsers     = "shadow_list_users"
 	ExperimentalDatastoreThrottling = "datastore_throttling"
 	ExperimentalPipelineListObjects = "pipeline_list_objects"
 )
@@ -255,9 +331,42 @@ type CheckQueryCache struct {
 	TTL     time.Duration
 }
 
+// AuthorizationModelOrphanedTuplesCheckConfig defines the configuration of the check that rejects the models that
+// would invalidate more than Threshold existing tuples of the store.
+type AuthorizationModelOrphanedTuplesCheckConfig struct {
+	Enabled   bool
+	Threshold uint32
+}
+
+// ListObjectsQueryCacheConfig defines configuration for caching ListObjects results.
+type ListObjectsQueryCacheConfig struct {
+	Enabled bool
+	TTL     time.Duration
+}
+
 // CheckCacheConfig defines configuration for a cache that is shared across Check requests.
 type CheckCacheConfig struct {
 	Limit uint32
+
+	// Valkey configures the cache to be stored in Valkey, so that it is shared across replicas.
+	Valkey CheckCacheValkeyConfig
+}
+
+// CheckCacheValkeyConfig defines configuration for storing the Check cache in Valkey (or Redis).
+type CheckCacheValkeyConfig struct {
+	Enabled bool
+
+	// URI is the connection URI of the Valkey instance, e.g. redis://localhost:6379/0.
+	URI string
+
+	// KeyPrefix is the prefix of every key stored in Valkey.
+	KeyPrefix string
+
+	// LocalTierEnabled keeps a local LRU cache (of CheckCacheConfig.Limit items) in front of Valkey.
+	LocalTierEnabled bool
+
+	// LocalTierTTL is the maximum time a value is kept in the local tier.
+	LocalTierTTL time.Duration
 }
 
 // IteratorCacheConfig defines configuration to cache storage iterator results.
@@ -305,6 +414,289 @@ type AccessControlConfig struct {
 type PlannerConfig struct {
 	EvictionThreshold time.Duration
 	CleanupInterval   time.Duration
+	Snapshot          PlannerSnapshotConfig
+	ListStrategies    PlannerListStrategiesConfig
+}
+
+// PlannerListStrategiesConfig defines configuration for letting the planner select the strategy that resolves
+// ListObjects and ListUsers requests.
+type PlannerListStrategiesConfig struct {
+	// Enabled lets the planner select the strategy. The strategy selected by the other settings and the
+	// feature flags is the baseline, which is always trusted.
+	Enabled bool
+	// RequiredValidations is the number of times the results of another strategy must match the ones of the
+	// baseline, in shadow, before the strategy is trusted. A single mismatch rejects it.
+	RequiredValidations uint32
+	// ShadowTimeout is the maximum amount of time to wait for the validation of a strategy.
+	ShadowTimeout time.Duration
+}
+
+const (
+	PlannerSnapshotBackendNone      = "none"
+	PlannerSnapshotBackendFile      = "file"
+	PlannerSnapshotBackendValkey    = "valkey"
+	PlannerSnapshotBackendDatastore = "datastore"
+)
+
+// PlannerSnapshotConfig defines configuration for persisting what the planner learns, so that it survives
+// restarts and, optionally, is shared across replicas.
+type PlannerSnapshotConfig struct {
+	// Backend is where snapshots are stored: "none" (disabled), "file", "valkey" or "datastore", the datastore
+	// of the server.
+	Backend string
+	// Path is the directory snapshots are stored in when Backend is "file". Replicas share their snapshots
+	// by sharing the directory.
+	Path string
+	// URI is the connection URI of the Valkey instance when Backend is "valkey", e.g. redis://localhost:6379/0.
+	URI string
+	// KeyPrefix is the prefix of the keys of the snapshots stored in Valkey.
+	KeyPrefix string
+	// Interval is how often the snapshot of this replica is saved, and the ones of other replicas merged.
+	Interval time.Duration
+	// ReplicaID identifies the snapshot of this replica. It must be stable across restarts and unique
+	// across replicas. Defaults to the hostname.
+	ReplicaID string
+	// MergeReplicas merges what the other replicas learned into the statistics of this replica.
+	MergeReplicas bool
+	// MaxAge is how old a snapshot may be to be restored or merged.
+	MaxAge time.Duration
+}
+
+// PeerDispatchConfig defines configuration for sharding Check sub-problems across a cluster of OpenFGA nodes.
+// Peers are either listed statically (Peers) or discovered by resolving a DNS name (DNSName).
+type PeerDispatchConfig struct {
+	Enabled bool
+
+	// Addr is the address (host:port) of the dedicated gRPC listener serving the sub-problems dispatched by peers.
+	// It is kept apart from the public gRPC listener so that it can be firewalled to the cluster.
+	Addr string
+
+	// PresharedKeys are the keys that authenticate the peers on the peer dispatch listener. The first one is sent
+	// by this node, the others are also accepted to allow rotating the key.
+	PresharedKeys []string
+
+	// AdvertiseAddr is the address (host:port) of the peer dispatch listener under which this node is known to its peers.
+	AdvertiseAddr string
+
+	// Peers is the static list of gRPC addresses (host:port) of the nodes of the cluster.
+	Peers []string
+
+	// DNSName is a host:port whose host resolves to the addresses of all the nodes of the cluster,
+	// e.g. a Kubernetes headless service.
+	DNSName            string
+	DNSRefreshInterval time.Duration
+
+	// Timeout is the maximum amount of time to wait for a peer before resolving a sub-problem locally.
+	Timeout time.Duration
+
+	// VirtualNodes is the number of times each node is placed on the consistent hash ring.
+	VirtualNodes int
+}
+
+// CheckPermissionIndexConfig defines configuration for the precomputed index of nested userset membership
+// that Check consults for eligible recursive relations (e.g. `define member: [user, group#member]`).
+type CheckPermissionIndexConfig struct {
+	Enabled bool
+
+	// RefreshInterval is how often the index is brought up to date with the changelog of each store.
+	RefreshInterval time.Duration
+
+	// MaxStaleness is how old the index of a store may be for Check to use it. Staler stores are
+	// resolved through the graph.
+	MaxStaleness time.Duration
+
+	// ValkeyURI is the connection URI of the Valkey instance the index is stored in, e.g. redis://localhost:6379/0.
+	ValkeyURI string
+
+	// ValkeyKeyPrefix is the prefix of the keys of the index stored in Valkey.
+	ValkeyKeyPrefix string
+}
+
+// CheckSingleflightConfig defines configuration for coalescing identical concurrent Check sub-problems
+// into a single resolution.
+type CheckSingleflightConfig struct {
+	Enabled bool
+}
+
+// EdgeSyncConfig defines configuration for the edge sync service, through which edge sidecars subscribe
+// to materialized Check results.
+type EdgeSyncConfig struct {
+	Enabled bool
+
+	// PollInterval is how often the changelog of each subscribed store is read to stream deltas.
+	PollInterval time.Duration
+
+	// CursorTTL is how long a subscriber can resume from a cursor with deltas only.
+	CursorTTL time.Duration
+}
+
+// DatastoreAdaptiveConcurrencyConfig defines configuration for adapting the number of concurrent datastore reads of
+// each Check, ListObjects and ListUsers request to the latency and errors of the datastore. The MaxConcurrentReadsFor*
+// settings are the maximum limits of each method.
+type DatastoreAdaptiveConcurrencyConfig struct {
+	Enabled bool
+
+	// InitialLimit is the number of concurrent reads per request that the limits start at.
+	InitialLimit uint32
+
+	// MinLimit is the lowest number of concurrent reads per request.
+	MinLimit uint32
+
+	// LatencyThreshold is the latency of the datastore reads above which the limits are lowered.
+	LatencyThreshold time.Duration
+
+	// BackoffRatio is the ratio, between 0 and 1, by which the limits are lowered.
+	BackoffRatio float64
+}
+
+const (
+	CheckCostAdmissionActionReject       = "reject"
+	CheckCostAdmissionActionDeprioritize = "deprioritize"
+)
+
+// CheckCostAdmissionConfig defines configuration for admitting Check requests by their cost, estimated from
+// the weighted graph of the model and the sampled cardinality of the relations it traverses.
+type CheckCostAdmissionConfig struct {
+	Enabled bool
+
+	// Action is what happens to the checks estimated above their budget: 'reject' or 'deprioritize'.
+	Action string
+
+	// MaxReads is the maximum estimated number of datastore reads of a check. Zero is unlimited.
+	MaxReads uint32
+
+	// MaxDispatches is the maximum estimated number of dispatches of a check. Zero is unlimited.
+	MaxDispatches uint32
+
+	// StoreBudgets overrides the budget of specific stores, each as '<store_id>:<max_reads>:<max_dispatches>'.
+	StoreBudgets []string
+
+	// DeprioritizedFrequency is how often a deprioritized check is let through.
+	DeprioritizedFrequency time.Duration
+
+	// StatisticsTTL is how long the sampled cardinality of a relation is used before it is sampled again.
+	StatisticsTTL time.Duration
+}
+
+// CheckCostBudget is the budget of the checks of a store.
+type CheckCostBudget struct {
+	MaxReads      uint32
+	MaxDispatches uint32
+}
+
+// ParseCheckCostStoreBudgets parses budgets of the form '<store_id>:<max_reads>:<max_dispatches>' by store ID.
+func ParseCheckCostStoreBudgets(budgets []string) (map[string]CheckCostBudget, error) {
+	parsed := make(map[string]CheckCostBudget, len(budgets))
+	for _, budget := range budgets {
+		parts := strings.Split(budget, ":")
+		if len(parts) != 3 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid check cost store budget '%s', it must be '<store_id>:<max_reads>:<max_dispatches>'", budget)
+		}
+		maxReads, err := strconv.ParseUint(parts[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max reads in check cost store budget '%s': %w", budget, err)
+		}
+		maxDispatches, err := strconv.ParseUint(parts[2], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max dispatches in check cost store budget '%s': %w", budget, err)
+		}
+		parsed[parts[0]] = CheckCostBudget{MaxReads: uint32(maxReads), MaxDispatches: uint32(maxDispatches)}
+	}
+	return parsed, nil
+}
+
+// FairDispatchThrottlingConfig defines configuration for scheduling the throttled dispatches of Check, ListObjects
+// and ListUsers fairly across stores, with weighted fair queuing, instead of in the order they are throttled. It
+// applies to the dispatch throttling of the methods that have it enabled.
+type FairDispatchThrottlingConfig struct {
+	Enabled bool
+
+	// PerClient schedules the throttled dispatches fairly across the clients of each store too, by client ID.
+	PerClient bool
+
+	// StoreWeights overrides the weight of specific stores, each as '<store_id>:<weight>'. The other stores weigh 1.
+	StoreWeights []string
+
+	// CheckFrequency is the frequency at which the fair throttler of Check releases a throttled dispatch. Unlike the
+	// dispatch throttling of Check without fair scheduling, which has a throttler per request, it is shared by all
+	// the Check requests of the server, so it is usually much smaller than CheckDispatchThrottling.Frequency.
+	CheckFrequency time.Duration
+}
+
+// ParseFairDispatchThrottlingStoreWeights parses weights of the form '<store_id>:<weight>' by store ID.
+func ParseFairDispatchThrottlingStoreWeights(weights []string) (map[string]float64, error) {
+	parsed := make(map[string]float64, len(weights))
+	for _, weight := range weights {
+		storeID, value, ok := strings.Cut(weight, ":")
+		if !ok || storeID == "" {
+			return nil, fmt.Errorf("invalid fair dispatch throttling store weight '%s', it must be '<store_id>:<weight>'", weight)
+		}
+		w, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight in fair dispatch throttling store weight '%s': %w", weight, err)
+		}
+		if w <= 0 {
+			return nil, fmt.Errorf("invalid weight in fair dispatch throttling store weight '%s': it must be positive", weight)
+		}
+		parsed[storeID] = w
+	}
+	return parsed, nil
+}
+
+const (
+	LoadSheddingPriorityLow    = "low"
+	LoadSheddingPriorityNormal = "normal"
+	LoadSheddingPriorityHigh   = "high"
+)
+
+// LoadSheddingConfig defines configuration for rejecting requests when the server is under pressure, by priority
+// lane, so that the latency critical requests aren't affected by the bulk ones. The pressure is the highest ratio of
+// the requests in flight, the p99 latency of the high priority requests and the throttled dispatches to their
+// maximum. Zero maximums are ignored.
+type LoadSheddingConfig struct {
+	Enabled bool
+
+	// MaxInflight is the number of requests in flight at which the server is at capacity.
+	MaxInflight int
+
+	// LatencyTarget is the p99 latency of the high priority requests at which the server is at capacity.
+	LatencyTarget time.Duration
+
+	// MaxThrottledDispatches is the number of dispatches waiting in the dispatch throttlers at which the server is
+	// at capacity.
+	MaxThrottledDispatches int
+
+	// LowPriorityThreshold is the pressure, between 0 and 1, above which the low priority requests are shed. The
+	// normal priority requests are shed at capacity, and the high priority requests are never shed.
+	LowPriorityThreshold float64
+
+	// RetryAfter is the delay after which the shed requests are told to retry.
+	RetryAfter time.Duration
+
+	// MethodPriorities overrides the priority of specific methods, each as '<method>:<low|normal|high>'.
+	MethodPriorities []string
+
+	// ClientPriorities sets the priority of the requests of specific clients, each as '<client_id>:<low|normal|high>'.
+	ClientPriorities []string
+}
+
+// ParseLoadSheddingPriorities parses priorities of the form '<name>:<low|normal|high>' by name.
+func ParseLoadSheddingPriorities(priorities []string) (map[string]string, error) {
+	parsed := make(map[string]string, len(priorities))
+	for _, priority := range priorities {
+		name, value, ok := strings.Cut(priority, ":")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid load shedding priority '%s', it must be '<name>:<priority>'", priority)
+		}
+		switch value {
+		case LoadSheddingPriorityLow, LoadSheddingPriorityNormal, LoadSheddingPriorityHigh:
+		default:
+			return nil, fmt.Errorf("invalid load shedding priority '%s', the priority must be one of '%s', '%s' or '%s'",
+				priority, LoadSheddingPriorityLow, LoadSheddingPriorityNormal, LoadSheddingPriorityHigh)
+		}
+		parsed[name] = value
+	}
+	return parsed, nil
 }
 
 type Config struct {
@@ -321,6 +713,15 @@ type Config struct {
 	// This is to protect the server from misuse of the ListObjects endpoints.
 	ListObjectsMaxResults uint32
 
+	// ListObjectsMaxObjectsInMemory defines the maximum number of objects the exact ListObjects count
+	// keeps in memory to count each object once. The objects beyond it are spilled to temporary files.
+	// If 0, every object is kept in memory.
+	ListObjectsMaxObjectsInMemory uint32
+
+	// ListObjectsMaxCandidateObjectIDs defines the maximum number of candidate object IDs a paginated
+	// ListObjects request can restrict its results to. If 0, there is no maximum.
+	ListObjectsMaxCandidateObjectIDs uint32
+
 	// ListUsersDeadline defines the maximum amount of time to accumulate ListUsers results
 	// before the server will respond. This is to protect the server from misuse of the
 	// ListUsers endpoints. It cannot be larger than the configured server's request timeout (RequestTimeout or HTTPConfig.UpstreamTimeout).
@@ -331,6 +732,11 @@ type Config struct {
 	// This is to protect the server from misuse of the ListUsers endpoints.
 	ListUsersMaxResults uint32
 
+	// ListUsersMaxUsersInMemory defines the maximum number of users the streaming ListUsers API keeps
+	// in memory to deduplicate the users it sends, and the exact ListUsers count to count each user once.
+	// The users beyond it are spilled to temporary files. If 0, every user is kept in memory.
+	ListUsersMaxUsersInMemory uint32
+
 	// MaxTuplesPerWrite defines the maximum number of tuples per Write endpoint.
 	MaxTuplesPerWrite int
 
@@ -350,6 +756,10 @@ type Config struct {
 	// persisting an Authorization Model.
 	MaxAuthorizationModelSizeInBytes int
 
+	// AuthorizationModelOrphanedTuplesCheck defines whether the WriteAuthorizationModel endpoint rejects the models
+	// that would invalidate existing tuples.
+	AuthorizationModelOrphanedTuplesCheck AuthorizationModelOrphanedTuplesCheckConfig
+
 	// MaxConcurrentReadsForListObjects defines the maximum number of concurrent database reads
 	// allowed in ListObjects queries
 	MaxConcurrentReadsForListObjects uint32
@@ -411,8 +821,17 @@ type Config struct {
 	ListObjectsDatastoreThrottle  DatastoreThrottleConfig
 	ListUsersDatastoreThrottle    DatastoreThrottleConfig
 	ListObjectsIteratorCache      IteratorCacheConfig
+	ListObjectsQueryCache         ListObjectsQueryCacheConfig
 	SharedIterator                SharedIteratorConfig
 	Planner                       PlannerConfig
+	PeerDispatch                  PeerDispatchConfig
+	CheckPermissionIndex          CheckPermissionIndexConfig
+	CheckSingleflight             CheckSingleflightConfig
+	EdgeSync                      EdgeSyncConfig
+	CheckCostAdmission            CheckCostAdmissionConfig
+	DatastoreAdaptiveConcurrency  DatastoreAdaptiveConcurrencyConfig
+	FairDispatchThrottling        FairDispatchThrottlingConfig
+	LoadShedding                  LoadSheddingConfig
 
 	RequestDurationDatastoreQueryCountBuckets []string
 	RequestDurationDispatchCountBuckets       []string
@@ -464,6 +883,66 @@ func (cfg *Config) VerifyServerSettings() error {
 		return err
 	}
 
+	err = cfg.VerifyPeerDispatchConfig()
+	if err != nil {
+		return err
+	}
+
+	err = cfg.VerifyPlannerSnapshotConfig()
+	if err != nil {
+		return err
+	}
+
+	err = cfg.VerifyPlannerListStrategiesConfig()
+	if err != nil {
+		return err
+	}
+
+	err = cfg.VerifyCheckCostAdmissionConfig()
+	if err != nil {
+		return err
+	}
+
+	err = cfg.VerifyDatastoreAdaptiveConcurrencyConfig()
+	if err != nil {
+		return err
+	}
+
+	err = cfg.VerifyFairDispatchThrottlingConfig()
+	if err != nil {
+		return err
+	}
+
+	if cfg.CheckPermissionIndex.Enabled {
+		if cfg.CheckPermissionIndex.RefreshInterval <= 0 {
+			return errors.New("'checkPermissionIndex.refreshInterval' must be a positive time duration")
+		}
+		if cfg.CheckPermissionIndex.MaxStaleness <= 0 {
+			return errors.New("'checkPermissionIndex.maxStaleness' must be a positive time duration")
+		}
+		if cfg.CheckPermissionIndex.ValkeyURI == "" {
+			return errors.New("'checkPermissionIndex.valkeyURI' must be set when the permission index is enabled")
+		}
+	}
+
+	if cfg.CheckCache.Valkey.Enabled {
+		if cfg.CheckCache.Valkey.URI == "" {
+			return errors.New("'checkCache.valkey.uri' must be set when the Valkey check cache is enabled")
+		}
+		if cfg.CheckCache.Valkey.LocalTierEnabled && cfg.CheckCache.Valkey.LocalTierTTL <= 0 {
+			return errors.New("'checkCache.valkey.localTierTTL' must be a positive time duration")
+		}
+	}
+
+	if cfg.EdgeSync.Enabled {
+		if cfg.EdgeSync.PollInterval <= 0 {
+			return errors.New("'edgeSync.pollInterval' must be a positive time duration")
+		}
+		if cfg.EdgeSync.CursorTTL <= 0 {
+			return errors.New("'edgeSync.cursorTTL' must be a positive time duration")
+		}
+	}
+
 	if cfg.ListObjectsDeadline < 0 {
 		return errors.New("listObjectsDeadline must be non-negative time duration")
 	}
@@ -542,6 +1021,10 @@ func (cfg *Config) VerifyBinarySettings() error {
 		return errors.New("http.upstreamTimeout must be a non-negative time duration")
 	}
 
+	if err := cfg.VerifyLoadSheddingConfig(); err != nil {
+		return err
+	}
+
 	if viper.IsSet("cache.limit") && !viper.IsSet("checkCache.limit") {
 		fmt.Println("WARNING: flag `check-query-cache-limit` is deprecated. Please set --check-cache-limit instead.")
 	}
@@ -563,6 +1046,205 @@ func DefaultContextTimeout(config *Config) time.Duration {
 	return 0
 }
 
+// VerifyPeerDispatchConfig ensures PeerDispatchConfig is valid.
+func (cfg *Config) VerifyPeerDispatchConfig() error {
+	if !cfg.PeerDispatch.Enabled {
+		return nil
+	}
+
+	if cfg.PeerDispatch.Addr == "" {
+		return errors.New("'peerDispatch.addr' must be set when peer dispatch is enabled")
+	}
+
+	if cfg.PeerDispatch.Addr == cfg.GRPC.Addr {
+		return errors.New("'peerDispatch.addr' must be different from 'grpc.addr'")
+	}
+
+	if len(cfg.PeerDispatch.PresharedKeys) == 0 {
+		return errors.New("'peerDispatch.presharedKeys' must be set when peer dispatch is enabled")
+	}
+
+	if cfg.PeerDispatch.AdvertiseAddr == "" {
+		return errors.New("'peerDispatch.advertiseAddr' must be set when peer dispatch is enabled")
+	}
+
+	if (len(cfg.PeerDispatch.Peers) == 0) == (cfg.PeerDispatch.DNSName == "") {
+		return errors.New("exactly one of 'peerDispatch.peers' or 'peerDispatch.dnsName' must be set when peer dispatch is enabled")
+	}
+
+	if cfg.PeerDispatch.DNSName != "" && cfg.PeerDispatch.DNSRefreshInterval <= 0 {
+		return errors.New("'peerDispatch.dnsRefreshInterval' must be a positive time duration")
+	}
+
+	if cfg.PeerDispatch.Timeout <= 0 {
+		return errors.New("'peerDispatch.timeout' must be a positive time duration")
+	}
+
+	if cfg.PeerDispatch.VirtualNodes <= 0 {
+		return errors.New("'peerDispatch.virtualNodes' must be a positive integer")
+	}
+
+	return nil
+}
+
+// VerifyPlannerSnapshotConfig ensures PlannerSnapshotConfig is valid.
+func (cfg *Config) VerifyPlannerSnapshotConfig() error {
+	switch cfg.Planner.Snapshot.Backend {
+	case PlannerSnapshotBackendNone:
+		return nil
+	case PlannerSnapshotBackendFile:
+		if cfg.Planner.Snapshot.Path == "" {
+			return errors.New("'planner.snapshot.path' must be set when the planner snapshot backend is 'file'")
+		}
+	case PlannerSnapshotBackendValkey:
+		if cfg.Planner.Snapshot.URI == "" {
+			return errors.New("'planner.snapshot.uri' must be set when the planner snapshot backend is 'valkey'")
+		}
+	case PlannerSnapshotBackendDatastore:
+	default:
+		return fmt.Errorf("'planner.snapshot.backend' must be one of '%s', '%s', '%s' or '%s'",
+			PlannerSnapshotBackendNone, PlannerSnapshotBackendFile, PlannerSnapshotBackendValkey, PlannerSnapshotBackendDatastore)
+	}
+
+	if cfg.Planner.Snapshot.Interval <= 0 {
+		return errors.New("'planner.snapshot.interval' must be a positive time duration")
+	}
+
+	if cfg.Planner.Snapshot.MaxAge < 0 {
+		return errors.New("'planner.snapshot.maxAge' must be a non-negative time duration")
+	}
+
+	return nil
+}
+
+// VerifyPlannerListStrategiesConfig ensures PlannerListStrategiesConfig is valid.
+func (cfg *Config) VerifyPlannerListStrategiesConfig() error {
+	if !cfg.Planner.ListStrategies.Enabled {
+		return nil
+	}
+
+	if cfg.Planner.ListStrategies.RequiredValidations == 0 {
+		return errors.New("'planner.listStrategies.requiredValidations' must be a positive integer")
+	}
+
+	if cfg.Planner.ListStrategies.ShadowTimeout <= 0 {
+		return errors.New("'planner.listStrategies.shadowTimeout' must be a positive time duration")
+	}
+
+	return nil
+}
+
+// VerifyCheckCostAdmissionConfig ensures CheckCostAdmissionConfig is valid.
+func (cfg *Config) VerifyCheckCostAdmissionConfig() error {
+	if !cfg.CheckCostAdmission.Enabled {
+		return nil
+	}
+
+	switch cfg.CheckCostAdmission.Action {
+	case CheckCostAdmissionActionReject:
+	case CheckCostAdmissionActionDeprioritize:
+		if cfg.CheckCostAdmission.DeprioritizedFrequency <= 0 {
+			return errors.New("'checkCostAdmission.deprioritizedFrequency' must be a positive time duration")
+		}
+	default:
+		return fmt.Errorf("'checkCostAdmission.action' must be one of '%s' or '%s'",
+			CheckCostAdmissionActionReject, CheckCostAdmissionActionDeprioritize)
+	}
+
+	if cfg.CheckCostAdmission.StatisticsTTL <= 0 {
+		return errors.New("'checkCostAdmission.statisticsTTL' must be a positive time duration")
+	}
+
+	if _, err := ParseCheckCostStoreBudgets(cfg.CheckCostAdmission.StoreBudgets); err != nil {
+		return fmt.Errorf("'checkCostAdmission.storeBudgets': %w", err)
+	}
+
+	return nil
+}
+
+// VerifyDatastoreAdaptiveConcurrencyConfig ensures DatastoreAdaptiveConcurrencyConfig is valid.
+func (cfg *Config) VerifyDatastoreAdaptiveConcurrencyConfig() error {
+	if !cfg.DatastoreAdaptiveConcurrency.Enabled {
+		return nil
+	}
+
+	if cfg.DatastoreAdaptiveConcurrency.MinLimit == 0 {
+		return errors.New("'datastoreAdaptiveConcurrency.minLimit' must be a positive integer")
+	}
+
+	if cfg.DatastoreAdaptiveConcurrency.InitialLimit < cfg.DatastoreAdaptiveConcurrency.MinLimit {
+		return errors.New("'datastoreAdaptiveConcurrency.initialLimit' must not be less than 'datastoreAdaptiveConcurrency.minLimit'")
+	}
+
+	if cfg.DatastoreAdaptiveConcurrency.LatencyThreshold <= 0 {
+		return errors.New("'datastoreAdaptiveConcurrency.latencyThreshold' must be a positive time duration")
+	}
+
+	if cfg.DatastoreAdaptiveConcurrency.BackoffRatio <= 0 || cfg.DatastoreAdaptiveConcurrency.BackoffRatio >= 1 {
+		return errors.New("'datastoreAdaptiveConcurrency.backoffRatio' must be between 0 and 1, exclusive")
+	}
+
+	return nil
+}
+
+// VerifyFairDispatchThrottlingConfig ensures FairDispatchThrottlingConfig is valid.
+func (cfg *Config) VerifyFairDispatchThrottlingConfig() error {
+	if !cfg.FairDispatchThrottling.Enabled {
+		return nil
+	}
+
+	if _, err := ParseFairDispatchThrottlingStoreWeights(cfg.FairDispatchThrottling.StoreWeights); err != nil {
+		return fmt.Errorf("'fairDispatchThrottling.storeWeights': %w", err)
+	}
+
+	if cfg.FairDispatchThrottling.CheckFrequency <= 0 {
+		return errors.New("'fairDispatchThrottling.checkFrequency' must be greater than zero")
+	}
+
+	return nil
+}
+
+// VerifyLoadSheddingConfig ensures LoadSheddingConfig is valid.
+func (cfg *Config) VerifyLoadSheddingConfig() error {
+	if !cfg.LoadShedding.Enabled {
+		return nil
+	}
+
+	if cfg.LoadShedding.MaxInflight < 0 {
+		return errors.New("'loadShedding.maxInflight' must be a non-negative integer")
+	}
+
+	if cfg.LoadShedding.LatencyTarget < 0 {
+		return errors.New("'loadShedding.latencyTarget' must be a non-negative time duration")
+	}
+
+	if cfg.LoadShedding.MaxThrottledDispatches < 0 {
+		return errors.New("'loadShedding.maxThrottledDispatches' must be a non-negative integer")
+	}
+
+	if cfg.LoadShedding.MaxInflight == 0 && cfg.LoadShedding.LatencyTarget == 0 && cfg.LoadShedding.MaxThrottledDispatches == 0 {
+		return errors.New("one of 'loadShedding.maxInflight', 'loadShedding.latencyTarget' or 'loadShedding.maxThrottledDispatches' must be set")
+	}
+
+	if cfg.LoadShedding.LowPriorityThreshold <= 0 || cfg.LoadShedding.LowPriorityThreshold > 1 {
+		return errors.New("'loadShedding.lowPriorityThreshold' must be greater than 0 and at most 1")
+	}
+
+	if cfg.LoadShedding.RetryAfter <= 0 {
+		return errors.New("'loadShedding.retryAfter' must be a positive time duration")
+	}
+
+	if _, err := ParseLoadSheddingPriorities(cfg.LoadShedding.MethodPriorities); err != nil {
+		return fmt.Errorf("'loadShedding.methodPriorities': %w", err)
+	}
+
+	if _, err := ParseLoadSheddingPriorities(cfg.LoadShedding.ClientPriorities); err != nil {
+		return fmt.Errorf("'loadShedding.clientPriorities': %w", err)
+	}
+
+	return nil
+}
+
 // VerifyDispatchThrottlingConfig ensures DispatchThrottlingConfigs are valid.
 func (cfg *Config) VerifyDispatchThrottlingConfig() error {
 	if cfg.CheckDispatchThrottling.Enabled {
@@ -672,6 +1354,9 @@ func (cfg *Config) verifyCacheConfig() error {
 			return errors.New("'listObjectsIteratorCache.maxResults' must be greater than zero")
 		}
 	}
+	if cfg.ListObjectsQueryCache.Enabled && cfg.ListObjectsQueryCache.TTL <= 0 {
+		return errors.New("'listObjectsQueryCache.ttl' must be greater than zero")
+	}
 	if cfg.CacheController.Enabled && cfg.CacheController.TTL <= 0 {
 		return errors.New("'cacheController.ttl' must be greater than zero")
 	}
@@ -686,9 +1371,13 @@ func MaxConditionEvaluationCost() uint64 {
 // DefaultConfig is the OpenFGA server default configurations.
 func DefaultConfig() *Config {
 	return &Config{
-		MaxTuplesPerWrite:                         DefaultMaxTuplesPerWrite,
-		MaxTypesPerAuthorizationModel:             DefaultMaxTypesPerAuthorizationModel,
-		MaxAuthorizationModelSizeInBytes:          DefaultMaxAuthorizationModelSizeInBytes,
+		MaxTuplesPerWrite:                DefaultMaxTuplesPerWrite,
+		MaxTypesPerAuthorizationModel:    DefaultMaxTypesPerAuthorizationModel,
+		MaxAuthorizationModelSizeInBytes: DefaultMaxAuthorizationModelSizeInBytes,
+		AuthorizationModelOrphanedTuplesCheck: AuthorizationModelOrphanedTuplesCheckConfig{
+			Enabled:   DefaultAuthorizationModelOrphanedTuplesCheckEnabled,
+			Threshold: DefaultAuthorizationModelOrphanedTuplesCheckThreshold,
+		},
 		MaxChecksPerBatchCheck:                    DefaultMaxChecksPerBatchCheck,
 		MaxConcurrentChecksPerBatchCheck:          DefaultMaxConcurrentChecksPerBatchCheck,
 		MaxConcurrentReadsForCheck:                DefaultMaxConcurrentReadsForCheck,
@@ -702,7 +1391,10 @@ func DefaultConfig() *Config {
 		AccessControl:                             AccessControlConfig{Enabled: false, StoreID: "", ModelID: ""},
 		ListObjectsDeadline:                       DefaultListObjectsDeadline,
 		ListObjectsMaxResults:                     DefaultListObjectsMaxResults,
+		ListObjectsMaxObjectsInMemory:             DefaultListObjectsMaxObjectsInMemory,
+		ListObjectsMaxCandidateObjectIDs:          DefaultListObjectsMaxCandidateObjectIDs,
 		ListUsersMaxResults:                       DefaultListUsersMaxResults,
+		ListUsersMaxUsersInMemory:                 DefaultListUsersMaxUsersInMemory,
 		ListUsersDeadline:                         DefaultListUsersDeadline,
 		RequestDurationDatastoreQueryCountBuckets: []string{"50", "200"},
 		RequestDurationDispatchCountBuckets:       []string{"50", "200"},
@@ -771,6 +1463,12 @@ func DefaultConfig() *Config {
 		},
 		CheckCache: CheckCacheConfig{
 			Limit: DefaultCheckCacheLimit,
+			Valkey: CheckCacheValkeyConfig{
+				Enabled:          DefaultCheckCacheValkeyEnabled,
+				KeyPrefix:        DefaultCheckCacheValkeyKeyPrefix,
+				LocalTierEnabled: DefaultCheckCacheValkeyLocalTierEnabled,
+				LocalTierTTL:     DefaultCheckCacheValkeyLocalTierTTL,
+			},
 		},
 		SharedIterator: SharedIteratorConfig{
 			Enabled: DefaultSharedIteratorEnabled,
@@ -803,6 +1501,10 @@ func DefaultConfig() *Config {
 			MaxResults: DefaultListObjectsIteratorCacheMaxResults,
 			TTL:        DefaultListObjectsIteratorCacheTTL,
 		},
+		ListObjectsQueryCache: ListObjectsQueryCacheConfig{
+			Enabled: DefaultListObjectsQueryCacheEnabled,
+			TTL:     DefaultListObjectsQueryCacheTTL,
+		},
 		CheckDatastoreThrottle: DatastoreThrottleConfig{
 			Threshold: 0,
 			Duration:  0,
@@ -820,6 +1522,73 @@ func DefaultConfig() *Config {
 		Planner: PlannerConfig{
 			EvictionThreshold: DefaultPlannerEvictionThreshold,
 			CleanupInterval:   DefaultPlannerCleanupInterval,
+			Snapshot: PlannerSnapshotConfig{
+				Backend:       DefaultPlannerSnapshotBackend,
+				KeyPrefix:     DefaultPlannerSnapshotKeyPrefix,
+				Interval:      DefaultPlannerSnapshotInterval,
+				MergeReplicas: DefaultPlannerSnapshotMergeReplicas,
+				MaxAge:        DefaultPlannerSnapshotMaxAge,
+			},
+			ListStrategies: PlannerListStrategiesConfig{
+				Enabled:             DefaultPlannerListStrategiesEnabled,
+				RequiredValidations: DefaultPlannerListStrategiesRequiredValidations,
+				ShadowTimeout:       DefaultPlannerListStrategiesShadowTimeout,
+			},
+		},
+		PeerDispatch: PeerDispatchConfig{
+			Enabled:            DefaultPeerDispatchEnabled,
+			Addr:               DefaultPeerDispatchAddr,
+			PresharedKeys:      []string{},
+			Peers:              []string{},
+			DNSRefreshInterval: DefaultPeerDispatchDNSRefreshInterval,
+			Timeout:            DefaultPeerDispatchTimeout,
+			VirtualNodes:       DefaultPeerDispatchVirtualNodes,
+		},
+		CheckPermissionIndex: CheckPermissionIndexConfig{
+			Enabled:         DefaultCheckPermissionIndexEnabled,
+			RefreshInterval: DefaultCheckPermissionIndexRefreshInterval,
+			MaxStaleness:    DefaultCheckPermissionIndexMaxStaleness,
+			ValkeyKeyPrefix: DefaultCheckPermissionIndexValkeyKeyPrefix,
+		},
+		CheckSingleflight: CheckSingleflightConfig{
+			Enabled: DefaultCheckSingleflightEnabled,
+		},
+		EdgeSync: EdgeSyncConfig{
+			Enabled:      DefaultEdgeSyncEnabled,
+			PollInterval: DefaultEdgeSyncPollInterval,
+			CursorTTL:    DefaultEdgeSyncCursorTTL,
+		},
+		CheckCostAdmission: CheckCostAdmissionConfig{
+			Enabled:                DefaultCheckCostAdmissionEnabled,
+			Action:                 DefaultCheckCostAdmissionAction,
+			MaxReads:               DefaultCheckCostAdmissionMaxReads,
+			MaxDispatches:          DefaultCheckCostAdmissionMaxDispatches,
+			StoreBudgets:           []string{},
+			DeprioritizedFrequency: DefaultCheckCostAdmissionDeprioritizedFrequency,
+			StatisticsTTL:          DefaultCheckCostAdmissionStatisticsTTL,
+		},
+		DatastoreAdaptiveConcurrency: DatastoreAdaptiveConcurrencyConfig{
+			Enabled:          DefaultDatastoreAdaptiveConcurrencyEnabled,
+			InitialLimit:     DefaultDatastoreAdaptiveConcurrencyInitialLimit,
+			MinLimit:         DefaultDatastoreAdaptiveConcurrencyMinLimit,
+			LatencyThreshold: DefaultDatastoreAdaptiveConcurrencyLatencyThreshold,
+			BackoffRatio:     DefaultDatastoreAdaptiveConcurrencyBackoffRatio,
+		},
+		FairDispatchThrottling: FairDispatchThrottlingConfig{
+			Enabled:        DefaultFairDispatchThrottlingEnabled,
+			PerClient:      DefaultFairDispatchThrottlingPerClient,
+			StoreWeights:   []string{},
+			CheckFrequency: DefaultFairDispatchThrottlingCheckFrequency,
+		},
+		LoadShedding: LoadSheddingConfig{
+			Enabled:                DefaultLoadSheddingEnabled,
+			MaxInflight:            DefaultLoadSheddingMaxInflight,
+			LatencyTarget:          DefaultLoadSheddingLatencyTarget,
+			MaxThrottledDispatches: DefaultLoadSheddingMaxThrottledDispatches,
+			LowPriorityThreshold:   DefaultLoadSheddingLowPriorityThreshold,
+			RetryAfter:             DefaultLoadSheddingRetryAfter,
+			MethodPriorities:       []string{},
+			ClientPriorities:       []string{},
 		},
 	}
 }
@@ -569,6 +569,205 @@ func TestVerifyConfig(t *testing.T) {
 		require.Contains(t, buf.String(), "WARNING: Logging is not enabled. It is highly recommended to enable logging in production environments to avoid masking attacker operations.")
 	})
 
+	t.Run("planner_snapshot", func(t *testing.T) {
+		t.Run("unknown_backend", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Planner.Snapshot.Backend = "s3"
+
+			require.Error(t, cfg.VerifyServerSettings())
+		})
+
+		t.Run("file_backend_without_path", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Planner.Snapshot.Backend = PlannerSnapshotBackendFile
+
+			require.Error(t, cfg.VerifyServerSettings())
+		})
+
+		t.Run("valkey_backend_without_uri", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Planner.Snapshot.Backend = PlannerSnapshotBackendValkey
+
+			require.Error(t, cfg.VerifyServerSettings())
+		})
+
+		t.Run("non_positive_interval", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Planner.Snapshot.Backend = PlannerSnapshotBackendFile
+			cfg.Planner.Snapshot.Path = t.TempDir()
+			cfg.Planner.Snapshot.Interval = 0
+
+			require.Error(t, cfg.VerifyServerSettings())
+		})
+
+		t.Run("valid_datastore_backend", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Planner.Snapshot.Backend = PlannerSnapshotBackendDatastore
+
+			require.NoError(t, cfg.VerifyServerSettings())
+		})
+
+		t.Run("valid_file_backend", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Planner.Snapshot.Backend = PlannerSnapshotBackendFile
+			cfg.Planner.Snapshot.Path = t.TempDir()
+
+			require.NoError(t, cfg.VerifyServerSettings())
+		})
+	})
+
+	t.Run("planner_list_strategies", func(t *testing.T) {
+		t.Run("no_required_validations", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Planner.ListStrategies.Enabled = true
+			cfg.Planner.ListStrategies.RequiredValidations = 0
+
+			require.Error(t, cfg.VerifyServerSettings())
+		})
+
+		t.Run("non_positive_shadow_timeout", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Planner.ListStrategies.Enabled = true
+			cfg.Planner.ListStrategies.ShadowTimeout = 0
+
+			require.Error(t, cfg.VerifyServerSettings())
+		})
+
+		t.Run("valid", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Planner.ListStrategies.Enabled = true
+
+			require.NoError(t, cfg.VerifyServerSettings())
+		})
+	})
+
+	t.Run("check_cost_admission", func(t *testing.T) {
+		t.Run("unknown_action", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.CheckCostAdmission.Enabled = true
+			cfg.CheckCostAdmission.Action = "drop"
+
+			require.Error(t, cfg.VerifyServerSettings())
+		})
+
+		t.Run("non_positive_deprioritized_frequency", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.CheckCostAdmission.Enabled = true
+			cfg.CheckCostAdmission.DeprioritizedFrequency = 0
+
+			require.Error(t, cfg.VerifyServerSettings())
+		})
+
+		t.Run("non_positive_statistics_ttl", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.CheckCostAdmission.Enabled = true
+			cfg.CheckCostAdmission.StatisticsTTL = 0
+
+			require.Error(t, cfg.VerifyServerSettings())
+		})
+
+		t.Run("invalid_store_budget", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.CheckCostAdmission.Enabled = true
+			cfg.CheckCostAdmission.StoreBudgets = []string{"01JSTORE:100"}
+
+			require.Error(t, cfg.VerifyServerSettings())
+		})
+
+		t.Run("valid", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.CheckCostAdmission.Enabled = true
+			cfg.CheckCostAdmission.Action = CheckCostAdmissionActionReject
+			cfg.CheckCostAdmission.StoreBudgets = []string{"01JSTORE:100:0"}
+
+			require.NoError(t, cfg.VerifyServerSettings())
+
+			budgets, err := ParseCheckCostStoreBudgets(cfg.CheckCostAdmission.StoreBudgets)
+			require.NoError(t, err)
+			require.Equal(t, map[string]CheckCostBudget{"01JSTORE": {MaxReads: 100}}, budgets)
+		})
+	})
+
+	t.Run("datastore_adaptive_concurrency", func(t *testing.T) {
+		t.Run("zero_min_limit", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.DatastoreAdaptiveConcurrency.Enabled = true
+			cfg.DatastoreAdaptiveConcurrency.MinLimit = 0
+
+			require.Error(t, cfg.VerifyServerSettings())
+		})
+
+		t.Run("initial_limit_below_min_limit", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.DatastoreAdaptiveConcurrency.Enabled = true
+			cfg.DatastoreAdaptiveConcurrency.MinLimit = 5
+			cfg.DatastoreAdaptiveConcurrency.InitialLimit = 4
+
+			require.Error(t, cfg.VerifyServerSettings())
+		})
+
+		t.Run("non_positive_latency_threshold", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.DatastoreAdaptiveConcurrency.Enabled = true
+			cfg.DatastoreAdaptiveConcurrency.LatencyThreshold = 0
+
+			require.Error(t, cfg.VerifyServerSettings())
+		})
+
+		t.Run("backoff_ratio_out_of_range", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.DatastoreAdaptiveConcurrency.Enabled = true
+			cfg.DatastoreAdaptiveConcurrency.BackoffRatio = 1
+
+			require.Error(t, cfg.VerifyServerSettings())
+		})
+
+		t.Run("valid", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.DatastoreAdaptiveConcurrency.Enabled = true
+
+			require.NoError(t, cfg.VerifyServerSettings())
+		})
+	})
+
+	t.Run("fair_dispatch_throttling", func(t *testing.T) {
+		t.Run("invalid_store_weight", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.FairDispatchThrottling.Enabled = true
+			cfg.FairDispatchThrottling.StoreWeights = []string{"store"}
+
+			require.Error(t, cfg.VerifyServerSettings())
+		})
+
+		t.Run("non_positive_store_weight", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.FairDispatchThrottling.Enabled = true
+			cfg.FairDispatchThrottling.StoreWeights = []string{"store:0"}
+
+			require.Error(t, cfg.VerifyServerSettings())
+		})
+
+		t.Run("non_positive_check_frequency", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.FairDispatchThrottling.Enabled = true
+			cfg.FairDispatchThrottling.CheckFrequency = 0
+
+			require.Error(t, cfg.VerifyServerSettings())
+		})
+
+		t.Run("valid", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.FairDispatchThrottling.Enabled = true
+			cfg.FairDispatchThrottling.StoreWeights = []string{"store:2", "other:0.5"}
+
+			require.NoError(t, cfg.VerifyServerSettings())
+
+			weights, err := ParseFairDispatchThrottlingStoreWeights(cfg.FairDispatchThrottling.StoreWeights)
+			require.NoError(t, err)
+			require.Equal(t, map[string]float64{"store": 2, "other": 0.5}, weights)
+		})
+	})
+
 	t.Run("does_not_print_warning_when_log_level_is_not_none", func(t *testing.T) {
 		cfg := DefaultConfig()
 		cfg.Log.Level = "info"
@@ -1015,6 +1214,41 @@ func TestVerifyBinarySettings(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("load_shedding", func(t *testing.T) {
+		t.Run("no_capacity", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.LoadShedding.Enabled = true
+			cfg.LoadShedding.MaxInflight = 0
+
+			require.Error(t, cfg.VerifyBinarySettings())
+		})
+
+		t.Run("low_priority_threshold_out_of_range", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.LoadShedding.Enabled = true
+			cfg.LoadShedding.LowPriorityThreshold = 1.5
+
+			require.Error(t, cfg.VerifyBinarySettings())
+		})
+
+		t.Run("invalid_method_priority", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.LoadShedding.Enabled = true
+			cfg.LoadShedding.MethodPriorities = []string{"Read:urgent"}
+
+			require.Error(t, cfg.VerifyBinarySettings())
+		})
+
+		t.Run("valid", func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.LoadShedding.Enabled = true
+			cfg.LoadShedding.MethodPriorities = []string{"Read:normal"}
+			cfg.LoadShedding.ClientPriorities = []string{"batch-jobs:low"}
+
+			require.NoError(t, cfg.VerifyBinarySettings())
+		})
+	})
+
 	t.Run("prints_warning_when_log_level_is_none", func(t *testing.T) {
 		cfg := DefaultConfig()
 		cfg.Log.Level = "none"
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/internal/peer"
+	"github.com/openfga/openfga/internal/utils/apimethod"
+	"github.com/openfga/openfga/pkg/server/commands"
+	serverconfig "github.com/openfga/openfga/pkg/server/config"
+	"github.com/openfga/openfga/pkg/telemetry"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+var _ peer.DispatchServer = (*Server)(nil)
+
+// DispatchCheck resolves a Check sub-problem that a peer routed to this node because this node owns it.
+// The sub-problem is resolved with this node's check resolver chain, so it is served from (and saved to)
+// this node's check cache, and any nested sub-problem is routed to its own owner.
+// It is only served on the peer dispatch listener, whose callers are authenticated as peers. The peer
+// authorized the original request, so the sub-problem is not authorized again, but it is validated
+// against the model and its depth is bounded by this node's own resolution depth limit.
+func (s *Server) DispatchCheck(ctx context.Context, req *peer.CheckRequest) (*peer.CheckResponse, error) {
+	ctx, span := tracer.Start(ctx, "DispatchCheck", trace.WithAttributes(
+		attribute.String("store_id", req.StoreID),
+		attribute.String("tuple_key", tuple.TupleKeyWithConditionToString(req.TupleKey)),
+	))
+	defer span.End()
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  apimethod.Check.String(),
+	})
+
+	// a depth past the limit would never hit it, since the depth is only compared for equality
+	if req.Depth >= s.resolveNodeLimit {
+		return &peer.CheckResponse{ResolutionDepthExceeded: true}, nil
+	}
+
+	typesys, err := s.resolveTypesystem(ctx, req.StoreID, req.AuthorizationModelID)
+	if err != nil {
+		return nil, err
+	}
+
+	checkResolver, checkResolverCloser, err := s.getCheckResolverBuilder(req.StoreID).Build()
+	if err != nil {
+		return nil, err
+	}
+	defer checkResolverCloser()
+
+	// the sub-problem was routed here because this node owns it, so skip routing it again
+	if remote, ok := checkResolver.(*graph.RemoteCheckResolver); ok {
+		checkResolver = remote.GetDelegate()
+	}
+
+	resolveCheckRequest, err := graph.NewResolveCheckRequest(graph.ResolveCheckRequestParams{
+		StoreID:                   req.StoreID,
+		AuthorizationModelID:      typesys.GetAuthorizationModelID(),
+		TupleKey:                  req.TupleKey,
+		ContextualTuples:          req.ContextualTuples,
+		Context:                   req.Context,
+		Consistency:               req.Consistency,
+		LastCacheInvalidationTime: req.LastCacheInvalidationTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resolveCheckRequest.GetRequestMetadata().Depth = req.Depth
+	for _, path := range req.VisitedPaths {
+		resolveCheckRequest.VisitedPaths[path] = struct{}{}
+	}
+
+	checkQuery := commands.NewCheckCommand(
+		s.datastore,
+		checkResolver,
+		typesys,
+		commands.WithCheckCommandLogger(s.logger),
+		commands.WithCheckCommandMaxConcurrentReads(s.maxConcurrentReadsForCheck),
+		commands.WithCheckCommandConcurrencyLimiter(s.checkConcurrencyLimiter),
+		commands.WithCheckCommandCache(s.sharedDatastoreResources, s.cacheSettings),
+		commands.WithCheckDatastoreThrottler(
+			s.featureFlagClient.Boolean(serverconfig.ExperimentalDatastoreThrottling, req.StoreID),
+			s.checkDatastoreThrottleThreshold,
+			s.checkDatastoreThrottleDuration,
+		),
+	)
+
+	resp, checkRequestMetadata, err := checkQuery.ExecuteDispatched(ctx, resolveCheckRequest)
+	if err != nil {
+		if errors.Is(err, graph.ErrResolutionDepthExceeded) {
+			return &peer.CheckResponse{ResolutionDepthExceeded: true}, nil
+		}
+
+		telemetry.TraceError(span, err)
+		return nil, commands.CheckCommandErrorToServerError(err)
+	}
+
+	return &peer.CheckResponse{
+		Allowed:             resp.GetAllowed(),
+		CycleDetected:       resp.GetCycleDetected(),
+		DatastoreQueryCount: resp.GetResolutionMetadata().DatastoreQueryCount,
+		DatastoreItemCount:  resp.GetResolutionMetadata().DatastoreItemCount,
+		DispatchCount:       checkRequestMetadata.DispatchCounter.Load(),
+		DispatchThrottled:   checkRequestMetadata.DispatchThrottled.Load(),
+	}, nil
+}
@@ -0,0 +1,230 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/jsongrpc"
+	"github.com/openfga/openfga/internal/peer"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// countingDispatcher counts the sub-problems dispatched to other peers.
+type countingDispatcher struct {
+	*peer.Client
+	dispatched atomic.Uint32
+}
+
+func (c *countingDispatcher) DispatchCheck(ctx context.Context, addr string, req *peer.CheckRequest) (*peer.CheckResponse, error) {
+	c.dispatched.Add(1)
+	return c.Client.DispatchCheck(ctx, addr, req)
+}
+
+func TestDispatchCheckAcrossPeers(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	const numPeers = 3
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	addrs := make([]string, numPeers)
+	listeners := make(map[string]*bufconn.Listener, numPeers)
+	for i := range addrs {
+		addrs[i] = fmt.Sprintf("peer-%d:8081", i)
+		listeners[addrs[i]] = bufconn.Listen(1024 * 1024)
+	}
+
+	dialer := func(ctx context.Context, addr string) (net.Conn, error) {
+		return listeners[addr].DialContext(ctx)
+	}
+
+	servers := make([]*Server, numPeers)
+	dispatchers := make([]*countingDispatcher, numPeers)
+	for i, addr := range addrs {
+		dispatchers[i] = &countingDispatcher{
+			Client: peer.NewClient(addr, peer.NewStaticProvider(addrs...),
+				peer.WithDialOptions(
+					grpc.WithContextDialer(dialer),
+					grpc.WithTransportCredentials(insecure.NewCredentials()),
+				),
+			),
+		}
+
+		servers[i] = MustNewServerWithOpts(
+			WithDatastore(ds),
+			WithPeerDispatcher(dispatchers[i]),
+			WithCheckQueryCacheEnabled(true),
+		)
+
+		t.Cleanup(func() {
+			dispatchers[i].Close()
+			servers[i].Close()
+		})
+		// the peer is stopped before its server is closed
+		serveJSONGRPCTest(t, listeners[addr], servers[i], &peer.ServiceDesc)
+	}
+
+	s := servers[0]
+
+	createStoreResp, err := s.CreateStore(context.Background(), &openfgav1.CreateStoreRequest{
+		Name: "openfga-test",
+	})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type group
+			relations
+				define member: [user, group#member]
+
+		type folder
+			relations
+				define viewer: [user, group#member]
+
+		type document
+			relations
+				define parent: [folder]
+				define viewer: [user] or viewer from parent`)
+
+	writeAuthModelResp, err := s.WriteAuthorizationModel(context.Background(), &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+	modelID := writeAuthModelResp.GetAuthorizationModelId()
+
+	var tuples []*openfgav1.TupleKey
+	for i := 0; i < 10; i++ {
+		tuples = append(tuples,
+			tuple.NewTupleKey(fmt.Sprintf("document:%d", i), "parent", fmt.Sprintf("folder:%d", i)),
+			tuple.NewTupleKey(fmt.Sprintf("folder:%d", i), "viewer", fmt.Sprintf("group:%d#member", i)),
+			tuple.NewTupleKey(fmt.Sprintf("group:%d", i), "member", fmt.Sprintf("group:%d#member", i+1)),
+		)
+	}
+	tuples = append(tuples, tuple.NewTupleKey("group:10", "member", "user:anne"))
+
+	_, err = s.Write(context.Background(), &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes:  &openfgav1.WriteRequestWrites{TupleKeys: tuples},
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		for _, test := range []struct {
+			user    string
+			allowed bool
+		}{
+			{user: "user:anne", allowed: true},
+			{user: "user:bob", allowed: false},
+		} {
+			resp, err := servers[i%numPeers].Check(context.Background(), &openfgav1.CheckRequest{
+				StoreId:              storeID,
+				AuthorizationModelId: modelID,
+				TupleKey:             tuple.NewCheckRequestTupleKey(fmt.Sprintf("document:%d", i), "viewer", test.user),
+			})
+			require.NoError(t, err)
+			require.Equal(t, test.allowed, resp.GetAllowed(), "document:%d %s", i, test.user)
+		}
+	}
+
+	var dispatched uint32
+	for _, d := range dispatchers {
+		dispatched += d.dispatched.Load()
+	}
+	require.Positive(t, dispatched, "expected sub-problems to be dispatched to peers")
+}
+
+func TestDispatchCheckValidatesSubProblems(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(WithDatastore(ds))
+	t.Cleanup(s.Close)
+
+	ctx := context.Background()
+	createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "openfga-test"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define viewer: [user]`)
+	writeAuthModelResp, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+	modelID := writeAuthModelResp.GetAuthorizationModelId()
+
+	t.Run("valid_sub_problem", func(t *testing.T) {
+		resp, err := s.DispatchCheck(ctx, &peer.CheckRequest{
+			StoreRequest:         jsongrpc.StoreRequest{StoreID: storeID},
+			AuthorizationModelID: modelID,
+			TupleKey:             tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+			ContextualTuples:     []*openfgav1.TupleKey{tuple.NewTupleKey("document:1", "viewer", "user:anne")},
+		})
+		require.NoError(t, err)
+		require.True(t, resp.Allowed)
+	})
+
+	t.Run("invalid_relation", func(t *testing.T) {
+		_, err := s.DispatchCheck(ctx, &peer.CheckRequest{
+			StoreRequest:         jsongrpc.StoreRequest{StoreID: storeID},
+			AuthorizationModelID: modelID,
+			TupleKey:             tuple.NewTupleKey("document:1", "editor", "user:anne"),
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("invalid_contextual_tuple", func(t *testing.T) {
+		_, err := s.DispatchCheck(ctx, &peer.CheckRequest{
+			StoreRequest:         jsongrpc.StoreRequest{StoreID: storeID},
+			AuthorizationModelID: modelID,
+			TupleKey:             tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+			ContextualTuples:     []*openfgav1.TupleKey{tuple.NewTupleKey("document:1", "viewer", "document:2")},
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("depth_past_the_limit", func(t *testing.T) {
+		resp, err := s.DispatchCheck(ctx, &peer.CheckRequest{
+			StoreRequest:         jsongrpc.StoreRequest{StoreID: storeID},
+			AuthorizationModelID: modelID,
+			TupleKey:             tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+			Depth:                s.resolveNodeLimit + 10,
+		})
+		require.NoError(t, err)
+		require.True(t, resp.ResolutionDepthExceeded)
+	})
+}
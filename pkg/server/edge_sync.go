@@ -0,0 +1,510 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/internal/edgesync"
+	"github.com/openfga/openfga/internal/utils/apimethod"
+	"github.com/openfga/openfga/pkg/server/commands"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/telemetry"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+const (
+	// edgeSyncSnapshotChunkSize is the maximum number of results streamed in a single snapshot message.
+	edgeSyncSnapshotChunkSize = 1000
+
+	// edgeSyncMaxQueries is the maximum number of (object type, relation, user) combinations of a subscription.
+	edgeSyncMaxQueries = 1000
+
+	edgeSyncChangesPageSize = 100
+
+	// edgeSyncMaxCursors is the maximum number of cursors whose results are kept for subscribers to resume from.
+	edgeSyncMaxCursors = 1000
+)
+
+var (
+	edgeSyncSubscriptionsGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: build.ProjectName,
+		Name:      "edge_sync_subscriptions",
+		Help:      "The number of open edge sync subscriptions.",
+	})
+
+	edgeSyncMessageCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "edge_sync_message_count",
+		Help:      "The total number of edge sync messages streamed labeled by type (snapshot or delta).",
+	}, []string{"type"})
+
+	edgeSyncResumeCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "edge_sync_resume_count",
+		Help:      "The total number of edge sync subscriptions that provided a cursor, labeled by whether they resumed from it or were sent a new snapshot.",
+	}, []string{"outcome"})
+)
+
+var _ edgesync.SyncServer = (*Server)(nil)
+
+// edgeSyncResults is a materialized set of allowed results. It is never modified once built, so it
+// can be shared between the subscriptions that resume from the same cursor.
+type edgeSyncResults map[edgesync.CheckResult]struct{}
+
+func (r edgeSyncResults) CacheEntityType() string {
+	return "edge_sync_results"
+}
+
+// Subscribe streams the materialized Check results of a subscription: a snapshot first, unless the
+// subscription resumes from a cursor, and then a delta every time the store changelog moves.
+func (s *Server) Subscribe(req *edgesync.SubscribeRequest, stream edgesync.SubscribeServer) error {
+	if s.edgeSyncResults == nil {
+		return status.Error(codes.Unimplemented, "edge sync is not enabled")
+	}
+
+	ctx, span := tracer.Start(stream.Context(), "Subscribe", trace.WithAttributes(
+		attribute.String("store_id", req.StoreID),
+		attribute.StringSlice("object_types", req.ObjectTypes),
+		attribute.StringSlice("relations", req.Relations),
+	))
+	defer span.End()
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: edgesync.ServiceName,
+		Method:  "Subscribe",
+	})
+
+	if err := validateSubscribeRequest(req); err != nil {
+		return err
+	}
+
+	for _, method := range []apimethod.APIMethod{apimethod.ListObjects, apimethod.ReadChanges} {
+		if err := s.checkAuthz(ctx, req.StoreID, method); err != nil {
+			return err
+		}
+	}
+
+	edgeSyncSubscriptionsGauge.Inc()
+	defer edgeSyncSubscriptionsGauge.Dec()
+
+	sub := &edgeSubscription{server: s, req: req, stream: stream}
+	err := sub.run(ctx)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		telemetry.TraceError(span, err)
+		return err
+	}
+	return nil
+}
+
+func validateSubscribeRequest(req *edgesync.SubscribeRequest) error {
+	if req.StoreID == "" {
+		return status.Error(codes.InvalidArgument, "store_id is required")
+	}
+	if len(req.ObjectTypes) == 0 || len(req.Relations) == 0 || len(req.Users) == 0 {
+		return status.Error(codes.InvalidArgument, "object_types, relations and users are required")
+	}
+	if queries := len(req.ObjectTypes) * len(req.Relations) * len(req.Users); queries > edgeSyncMaxQueries {
+		return status.Errorf(codes.InvalidArgument,
+			"a subscription may hold at most %d object type, relation and user combinations, got %d", edgeSyncMaxQueries, queries)
+	}
+	return nil
+}
+
+// edgeSubscription is the state of a single subscription stream.
+type edgeSubscription struct {
+	server *Server
+	req    *edgesync.SubscribeRequest
+	stream edgesync.SubscribeServer
+
+	typesys     *typesystem.TypeSystem
+	fingerprint string
+	token       string // the changelog token results are up to date with
+	results     edgeSyncResults
+	// incomplete holds the queries, as "objectType#relation", whose results may be missing objects
+	incomplete map[string]struct{}
+}
+
+func (e *edgeSubscription) run(ctx context.Context) error {
+	typesys, err := e.server.resolveTypesystem(ctx, e.req.StoreID, e.req.AuthorizationModelID)
+	if err != nil {
+		return err
+	}
+	e.setModel(typesys)
+
+	resumed := false
+	if e.req.Cursor != "" {
+		resumed, err = e.resume(ctx)
+		if err != nil {
+			return err
+		}
+
+		outcome := "snapshot"
+		if resumed {
+			outcome = "resumed"
+		}
+		edgeSyncResumeCounter.WithLabelValues(outcome).Inc()
+	}
+
+	if !resumed {
+		if err := e.snapshot(ctx); err != nil {
+			return err
+		}
+	}
+
+	ticker := time.NewTicker(e.server.edgeSyncPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := e.poll(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (e *edgeSubscription) setModel(typesys *typesystem.TypeSystem) {
+	e.typesys = typesys
+
+	h := xxhash.New()
+	_, _ = h.WriteString(e.req.StoreID + "|" + typesys.GetAuthorizationModelID())
+	for _, values := range [][]string{e.req.ObjectTypes, e.req.Relations, e.req.Users} {
+		_, _ = h.WriteString("|" + strings.Join(values, ","))
+	}
+	e.fingerprint = fmt.Sprintf("%x", h.Sum64())
+}
+
+// cursor returns the cursor of the current results. A cursor identifies the subscription (store, model and
+// filters) and the changelog position the results are up to date with.
+func (e *edgeSubscription) cursor() string {
+	return e.fingerprint + "." + e.token
+}
+
+// resume restores the results of the subscriber from its cursor. It returns false if the subscriber
+// must be sent a new snapshot instead.
+func (e *edgeSubscription) resume(ctx context.Context) (bool, error) {
+	fingerprint, token, ok := strings.Cut(e.req.Cursor, ".")
+	if !ok || fingerprint != e.fingerprint {
+		return false, nil
+	}
+
+	if results := e.server.edgeSyncResults.Get(e.req.Cursor); results != nil {
+		e.token = token
+		e.results = results
+		return true, nil
+	}
+
+	// the results of the cursor are gone, but if nothing changed since then they are the current ones
+	_, changed, err := e.readChanges(ctx, token, 1)
+	if err != nil || len(changed) > 0 {
+		return false, err
+	}
+
+	results, incomplete, err := e.materialize(ctx, allEdgeSyncQueries)
+	if err != nil || len(incomplete) > 0 {
+		return false, err
+	}
+	e.token = token
+	e.results = results
+	e.incomplete = incomplete
+	e.keepResults()
+	return true, nil
+}
+
+// keepResults keeps the current results for the subscribers that resume from their cursor, unless some of them may
+// be incomplete, in which case the subscribers that resume from the cursor are sent a new snapshot.
+func (e *edgeSubscription) keepResults() {
+	if len(e.incomplete) > 0 {
+		return
+	}
+	e.server.edgeSyncResults.Set(e.cursor(), e.results, e.server.edgeSyncCursorTTL)
+}
+
+// incompleteQueries returns the queries whose results may be incomplete, sorted.
+func (e *edgeSubscription) incompleteQueries() []string {
+	queries := slices.Collect(maps.Keys(e.incomplete))
+	slices.Sort(queries)
+	return queries
+}
+
+// snapshot streams every allowed result, telling the subscriber to discard what it holds.
+func (e *edgeSubscription) snapshot(ctx context.Context) error {
+	// the head of the changelog is read before materializing, so that changes made while
+	// materializing are seen again (and are harmless) on the next poll rather than missed
+	token, err := e.headToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	results, incomplete, err := e.materialize(ctx, allEdgeSyncQueries)
+	if err != nil {
+		return err
+	}
+	e.token = token
+	e.results = results
+	e.incomplete = incomplete
+	e.keepResults()
+
+	updates := make([]edgesync.CheckResult, 0, len(results))
+	for result := range results {
+		updates = append(updates, result)
+	}
+
+	for i := 0; i == 0 || i < len(updates); i += edgeSyncSnapshotChunkSize {
+		msg := &edgesync.SyncMessage{
+			Type:    edgesync.MessageTypeSnapshot,
+			Reset:   i == 0,
+			Updates: updates[i:min(i+edgeSyncSnapshotChunkSize, len(updates))],
+			ModelID: e.typesys.GetAuthorizationModelID(),
+		}
+		if i+edgeSyncSnapshotChunkSize >= len(updates) {
+			msg.Cursor = e.cursor()
+			msg.Incomplete = e.incompleteQueries()
+		}
+		if err := e.send(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// poll streams a delta if the changelog moved since the current results in a way that changes them, or a new
+// snapshot if a newer model is now used. Only the queries of the subscription whose relations are evaluated with
+// the tuples that changed are computed again.
+func (e *edgeSubscription) poll(ctx context.Context) error {
+	if e.req.AuthorizationModelID == "" {
+		typesys, err := e.server.resolveTypesystem(ctx, e.req.StoreID, "")
+		if err != nil {
+			return err
+		}
+		if typesys.GetAuthorizationModelID() != e.typesys.GetAuthorizationModelID() {
+			e.setModel(typesys)
+			return e.snapshot(ctx)
+		}
+	}
+
+	token, changed, err := e.readChanges(ctx, e.token, edgeSyncChangesPageSize)
+	if err != nil || token == e.token {
+		return err
+	}
+
+	affected := map[string]bool{}
+	isAffected := func(objectType, relation string) bool {
+		query := tuple.ToObjectRelationString(objectType, relation)
+		if v, ok := affected[query]; ok {
+			return v
+		}
+
+		affected[query] = true
+		if tupleRelations, err := e.typesys.GetTupleRelations(objectType, relation); err == nil {
+			affected[query] = slices.ContainsFunc(tupleRelations, func(tupleRelation string) bool {
+				_, ok := changed[tupleRelation]
+				return ok
+			})
+		}
+		return affected[query]
+	}
+
+	recomputed, incomplete, err := e.materialize(ctx, isAffected)
+	if err != nil {
+		return err
+	}
+
+	results := make(edgeSyncResults, len(e.results))
+	for result := range e.results {
+		objectType := tuple.GetType(result.Object)
+		// the results that may be missing from an incomplete query are not reported as removed
+		_, keep := incomplete[tuple.ToObjectRelationString(objectType, result.Relation)]
+		if keep || !isAffected(objectType, result.Relation) {
+			results[result] = struct{}{}
+		}
+	}
+	// the queries computed again are only incomplete if they still are
+	for query := range e.incomplete {
+		objectType, relation, _ := strings.Cut(query, "#")
+		if !isAffected(objectType, relation) {
+			incomplete[query] = struct{}{}
+		}
+	}
+	for result := range recomputed {
+		results[result] = struct{}{}
+	}
+
+	var updates []edgesync.CheckResult
+	for result := range results {
+		if _, ok := e.results[result]; !ok {
+			updates = append(updates, result)
+		}
+	}
+	for result := range e.results {
+		if _, ok := results[result]; !ok {
+			result.Allowed = false
+			updates = append(updates, result)
+		}
+	}
+
+	incompleteChanged := !maps.Equal(incomplete, e.incomplete)
+
+	e.token = token
+	e.results = results
+	e.incomplete = incomplete
+	e.keepResults()
+
+	if len(updates) == 0 && !incompleteChanged {
+		return nil
+	}
+	return e.send(&edgesync.SyncMessage{
+		Type:       edgesync.MessageTypeDelta,
+		Updates:    updates,
+		Cursor:     e.cursor(),
+		ModelID:    e.typesys.GetAuthorizationModelID(),
+		Incomplete: e.incompleteQueries(),
+	})
+}
+
+func (e *edgeSubscription) send(msg *edgesync.SyncMessage) error {
+	edgeSyncMessageCounter.WithLabelValues(string(msg.Type)).Inc()
+	return e.stream.Send(msg)
+}
+
+// headToken returns the changelog token of the most recent change of the store, or an empty string if there is none.
+func (e *edgeSubscription) headToken(ctx context.Context) (string, error) {
+	_, token, err := e.server.datastore.ReadChanges(ctx, e.req.StoreID,
+		storage.ReadChangesFilter{HorizonOffset: e.server.edgeSyncHorizonOffset()},
+		storage.ReadChangesOptions{Pagination: storage.NewPaginationOptions(1, ""), SortDesc: true},
+	)
+	if errors.Is(err, storage.ErrNotFound) {
+		return "", nil
+	}
+	return token, err
+}
+
+// readChanges reads the changelog after from, up to its head unless pageSize is 1. It returns the token of
+// the last change read and the relations, as "objectType#relation", of the tuples that changed.
+func (e *edgeSubscription) readChanges(ctx context.Context, from string, pageSize int) (string, map[string]struct{}, error) {
+	token := from
+	changed := map[string]struct{}{}
+	for {
+		changes, next, err := e.server.datastore.ReadChanges(ctx, e.req.StoreID,
+			storage.ReadChangesFilter{HorizonOffset: e.server.edgeSyncHorizonOffset()},
+			storage.ReadChangesOptions{Pagination: storage.NewPaginationOptions(int32(pageSize), token)},
+		)
+		if errors.Is(err, storage.ErrNotFound) {
+			return token, changed, nil
+		}
+		if err != nil {
+			return "", nil, err
+		}
+
+		for _, change := range changes {
+			tk := change.GetTupleKey()
+			changed[tuple.ToObjectRelationString(tuple.GetType(tk.GetObject()), tk.GetRelation())] = struct{}{}
+		}
+		if next != "" {
+			token = next
+		}
+		if pageSize == 1 || len(changes) < pageSize {
+			return token, changed, nil
+		}
+	}
+}
+
+// allEdgeSyncQueries selects every query of a subscription.
+func allEdgeSyncQueries(string, string) bool {
+	return true
+}
+
+// materialize computes the allowed results of the queries (object type and relation) of the subscription that
+// include selects, with ListObjects and its deadline and maximum number of results. It also returns the queries,
+// as "objectType#relation", whose results may be incomplete because they reached either of them.
+func (e *edgeSubscription) materialize(ctx context.Context, include func(objectType, relation string) bool) (edgeSyncResults, map[string]struct{}, error) {
+	s := e.server
+	storeID := e.req.StoreID
+
+	checkResolver, checkResolverCloser, err := s.getListObjectsCheckResolverBuilder(storeID).Build()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer checkResolverCloser()
+
+	q, err := commands.NewListObjectsQuery(
+		s.datastore,
+		checkResolver,
+		storeID,
+		commands.WithLogger(s.logger),
+		commands.WithListObjectsDeadline(s.listObjectsDeadline),
+		commands.WithListObjectsMaxResults(s.listObjectsMaxResults),
+		commands.WithResolveNodeLimit(s.resolveNodeLimit),
+		commands.WithResolveNodeBreadthLimit(s.resolveNodeBreadthLimit),
+		commands.WithMaxConcurrentReads(s.maxConcurrentReadsForListObjects),
+		commands.WithListObjectsConcurrencyLimiter(s.listObjectsConcurrencyLimiter),
+		commands.WithFeatureFlagClient(s.featureFlagClient),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx = typesystem.ContextWithTypesystem(ctx, e.typesys)
+	results := make(edgeSyncResults)
+	incomplete := map[string]struct{}{}
+	for _, objectType := range e.req.ObjectTypes {
+		for _, relation := range e.req.Relations {
+			if _, err := e.typesys.GetRelation(objectType, relation); err != nil {
+				// not every relation is defined on every object type of the subscription
+				continue
+			}
+			if !include(objectType, relation) {
+				continue
+			}
+
+			for _, user := range e.req.Users {
+				start := time.Now()
+				resp, err := q.Execute(ctx, &openfgav1.ListObjectsRequest{
+					StoreId:              storeID,
+					AuthorizationModelId: e.typesys.GetAuthorizationModelID(),
+					Type:                 objectType,
+					Relation:             relation,
+					User:                 user,
+					Consistency:          openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY,
+				})
+				if err != nil {
+					return nil, nil, err
+				}
+
+				if (s.listObjectsMaxResults > 0 && len(resp.Objects) >= int(s.listObjectsMaxResults)) ||
+					(s.listObjectsDeadline > 0 && time.Since(start) >= s.listObjectsDeadline) {
+					incomplete[tuple.ToObjectRelationString(objectType, relation)] = struct{}{}
+				}
+				for _, object := range resp.Objects {
+					results[edgesync.CheckResult{Object: object, Relation: relation, User: user, Allowed: true}] = struct{}{}
+				}
+			}
+		}
+	}
+
+	return results, incomplete, nil
+}
+
+func (s *Server) edgeSyncHorizonOffset() time.Duration {
+	return time.Duration(s.changelogHorizonOffset) * time.Minute
+}
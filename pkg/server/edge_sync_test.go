@@ -0,0 +1,339 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/edgesync"
+	"github.com/openfga/openfga/internal/jsongrpc"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestEdgeSync(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		WithEdgeSyncEnabled(true),
+		WithEdgeSyncPollInterval(10*time.Millisecond),
+	)
+	t.Cleanup(s.Close)
+
+	conn := newJSONGRPCTestConn(t, s, &edgesync.ServiceDesc)
+
+	createStoreResp, err := s.CreateStore(context.Background(), &openfgav1.CreateStoreRequest{
+		Name: "openfga-test",
+	})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type folder
+			relations
+				define viewer: [user]
+
+		type document
+			relations
+				define parent: [folder]
+				define viewer: [user] or viewer from parent`)
+
+	writeAuthModelResp, err := s.WriteAuthorizationModel(context.Background(), &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+	modelID := writeAuthModelResp.GetAuthorizationModelId()
+
+	_, err = s.Write(context.Background(), &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "parent", "folder:1"),
+			tuple.NewTupleKey("folder:1", "viewer", "user:anne"),
+			tuple.NewTupleKey("document:2", "viewer", "user:bob"),
+		}},
+	})
+	require.NoError(t, err)
+
+	req := edgesync.SubscribeRequest{
+		StoreRequest: jsongrpc.StoreRequest{StoreID: storeID},
+		ObjectTypes:  []string{"document", "folder"},
+		Relations:    []string{"viewer", "parent"},
+		Users:        []string{"user:anne", "user:bob"},
+	}
+
+	writeTuple := func(t *testing.T, tk *openfgav1.TupleKey) {
+		_, err := s.Write(context.Background(), &openfgav1.WriteRequest{
+			StoreId: storeID,
+			Writes:  &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{tk}},
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("snapshot_then_deltas", func(t *testing.T) {
+		subscriber := edgesync.NewSubscriber(conn, req)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			done <- subscriber.Run(ctx)
+		}()
+		t.Cleanup(func() {
+			cancel()
+			<-done
+		})
+
+		requireEventually := func(object, relation, user string, expected bool) {
+			require.Eventually(t, func() bool {
+				allowed, ok := subscriber.Check(object, relation, user)
+				return ok && allowed == expected
+			}, 5*time.Second, 10*time.Millisecond, "%s#%s@%s", object, relation, user)
+		}
+
+		requireEventually("document:1", "viewer", "user:anne", true)
+		requireEventually("document:2", "viewer", "user:bob", true)
+		requireEventually("document:2", "viewer", "user:anne", false)
+		requireEventually("folder:1", "viewer", "user:anne", true)
+		require.Equal(t, modelID, subscriber.ModelID())
+		require.NotEmpty(t, subscriber.Cursor())
+
+		writeTuple(t, tuple.NewTupleKey("folder:1", "viewer", "user:bob"))
+		requireEventually("document:1", "viewer", "user:bob", true)
+
+		_, err := s.Write(context.Background(), &openfgav1.WriteRequest{
+			StoreId: storeID,
+			Deletes: &openfgav1.WriteRequestDeletes{TupleKeys: []*openfgav1.TupleKeyWithoutCondition{
+				tuple.TupleKeyToTupleKeyWithoutCondition(tuple.NewTupleKey("folder:1", "viewer", "user:anne")),
+			}},
+		})
+		require.NoError(t, err)
+		requireEventually("document:1", "viewer", "user:anne", false)
+		requireEventually("folder:1", "viewer", "user:anne", false)
+	})
+
+	t.Run("resume_from_cursor", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		stream, err := edgesync.Subscribe(ctx, conn, &req)
+		require.NoError(t, err)
+
+		var cursor string
+		for cursor == "" {
+			msg, err := stream.Recv()
+			require.NoError(t, err)
+			require.Equal(t, edgesync.MessageTypeSnapshot, msg.Type)
+			cursor = msg.Cursor
+		}
+		cancel()
+
+		writeTuple(t, tuple.NewTupleKey("document:3", "viewer", "user:anne"))
+
+		resumeCtx, resumeCancel := context.WithCancel(context.Background())
+		t.Cleanup(resumeCancel)
+
+		resumeReq := req
+		resumeReq.Cursor = cursor
+		stream, err = edgesync.Subscribe(resumeCtx, conn, &resumeReq)
+		require.NoError(t, err)
+
+		msg, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, edgesync.MessageTypeDelta, msg.Type)
+		require.False(t, msg.Reset)
+		require.Equal(t, []edgesync.CheckResult{
+			{Object: "document:3", Relation: "viewer", User: "user:anne", Allowed: true},
+		}, msg.Updates)
+		require.NotEqual(t, cursor, msg.Cursor)
+	})
+
+	t.Run("unrelated_changes_send_no_delta", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		folderReq := req
+		folderReq.ObjectTypes = []string{"folder"}
+		folderReq.Relations = []string{"viewer"}
+		stream, err := edgesync.Subscribe(ctx, conn, &folderReq)
+		require.NoError(t, err)
+
+		for {
+			msg, err := stream.Recv()
+			require.NoError(t, err)
+			require.Equal(t, edgesync.MessageTypeSnapshot, msg.Type)
+			if msg.Cursor != "" {
+				break
+			}
+		}
+
+		// folder#viewer is not evaluated with the tuples of document#viewer
+		writeTuple(t, tuple.NewTupleKey("document:4", "viewer", "user:anne"))
+		writeTuple(t, tuple.NewTupleKey("folder:2", "viewer", "user:anne"))
+
+		msg, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, edgesync.MessageTypeDelta, msg.Type)
+		require.Equal(t, []edgesync.CheckResult{
+			{Object: "folder:2", Relation: "viewer", User: "user:anne", Allowed: true},
+		}, msg.Updates)
+	})
+
+	t.Run("unknown_cursor_is_sent_a_snapshot", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		resumeReq := req
+		resumeReq.Cursor = "unknown.cursor"
+		stream, err := edgesync.Subscribe(ctx, conn, &resumeReq)
+		require.NoError(t, err)
+
+		msg, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, edgesync.MessageTypeSnapshot, msg.Type)
+		require.True(t, msg.Reset)
+	})
+
+	t.Run("invalid_request", func(t *testing.T) {
+		stream, err := edgesync.Subscribe(context.Background(), conn, &edgesync.SubscribeRequest{StoreRequest: jsongrpc.StoreRequest{StoreID: storeID}})
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestEdgeSyncIncompleteResults(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		WithEdgeSyncEnabled(true),
+		WithEdgeSyncPollInterval(10*time.Millisecond),
+		WithListObjectsMaxResults(2),
+	)
+	t.Cleanup(s.Close)
+
+	conn := newJSONGRPCTestConn(t, s, &edgesync.ServiceDesc)
+
+	ctx := context.Background()
+	createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "openfga-test"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type folder
+			relations
+				define viewer: [user]
+
+		type document
+			relations
+				define viewer: [user]`)
+
+	_, err = s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+			tuple.NewTupleKey("document:2", "viewer", "user:anne"),
+			tuple.NewTupleKey("document:3", "viewer", "user:anne"),
+			tuple.NewTupleKey("folder:1", "viewer", "user:anne"),
+		}},
+	})
+	require.NoError(t, err)
+
+	req := edgesync.SubscribeRequest{
+		StoreRequest: jsongrpc.StoreRequest{StoreID: storeID},
+		ObjectTypes:  []string{"document", "folder"},
+		Relations:    []string{"viewer"},
+		Users:        []string{"user:anne"},
+	}
+
+	subscribe := func(t *testing.T, req edgesync.SubscribeRequest) *edgesync.SyncMessage {
+		ctx, cancel := context.WithCancel(ctx)
+		t.Cleanup(cancel)
+
+		stream, err := edgesync.Subscribe(ctx, conn, &req)
+		require.NoError(t, err)
+		for {
+			msg, err := stream.Recv()
+			require.NoError(t, err)
+			require.Equal(t, edgesync.MessageTypeSnapshot, msg.Type)
+			if msg.Cursor != "" {
+				return msg
+			}
+		}
+	}
+
+	// document#viewer reaches the maximum number of results
+	msg := subscribe(t, req)
+	require.True(t, msg.Reset)
+	require.Len(t, msg.Updates, 3)
+	require.Equal(t, []string{"document#viewer"}, msg.Incomplete)
+
+	// the incomplete results are not kept for the subscribers that resume from the cursor
+	resumeReq := req
+	resumeReq.Cursor = msg.Cursor
+	msg = subscribe(t, resumeReq)
+	require.True(t, msg.Reset)
+	require.Equal(t, []string{"document#viewer"}, msg.Incomplete)
+}
+
+func TestEdgeSyncDisabled(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(WithDatastore(ds))
+	t.Cleanup(s.Close)
+
+	stream, err := edgesync.Subscribe(context.Background(), newJSONGRPCTestConn(t, s, &edgesync.ServiceDesc), &edgesync.SubscribeRequest{
+		StoreRequest: jsongrpc.StoreRequest{StoreID: ulid.Make().String()},
+		ObjectTypes:  []string{"document"},
+		Relations:    []string{"viewer"},
+		Users:        []string{"user:anne"},
+	})
+	require.NoError(t, err)
+
+	_, err = stream.Recv()
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+}
@@ -33,6 +33,9 @@ var (
 
 	// ErrTransactionThrottled can apply when a limit is hit at the database level.
 	ErrTransactionThrottled = status.Error(codes.ResourceExhausted, "transaction was throttled by the datastore")
+
+	// ErrCheckCostBudgetExceeded applies when the estimated cost of a check exceeds the budget of its store.
+	ErrCheckCostBudgetExceeded = status.Error(codes.ResourceExhausted, "the estimated cost of the check exceeds the budget of the store")
 )
 
 type InternalError struct {
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// serveJSONGRPCTest serves the provided services of s, which are not generated from the OpenFGA protobuf
+// definitions, on lis until the end of the test.
+func serveJSONGRPCTest(t *testing.T, lis net.Listener, s *Server, services ...*grpc.ServiceDesc) {
+	grpcServer := grpc.NewServer()
+	for _, service := range services {
+		grpcServer.RegisterService(service, s)
+	}
+	go func() {
+		_ = grpcServer.Serve(lis)
+	}()
+	t.Cleanup(grpcServer.Stop)
+}
+
+// newJSONGRPCTestConn serves the provided services of s on an in-memory listener, and returns a connection to
+// them that is closed at the end of the test.
+func newJSONGRPCTestConn(t *testing.T, s *Server, services ...*grpc.ServiceDesc) *grpc.ClientConn {
+	lis := bufconn.Listen(1024 * 1024)
+	serveJSONGRPCTest(t, lis, s, services...)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/internal/condition"
+	"github.com/openfga/openfga/internal/listcount"
+	"github.com/openfga/openfga/internal/utils/apimethod"
+	"github.com/openfga/openfga/pkg/server/commands"
+	"github.com/openfga/openfga/pkg/server/commands/listusers"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/telemetry"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+var _ listcount.ListCountServer = (*Server)(nil)
+
+// CountObjects counts the objects of a ListObjects request without returning them, and without the ListObjects
+// max results. It stops at the ListObjects deadline. It is authorized like a ListObjects.
+func (s *Server) CountObjects(ctx context.Context, req *listcount.CountObjectsRequest) (*listcount.CountResponse, error) {
+	start := time.Now()
+
+	const methodName = "countobjects"
+
+	listObjectsRequest := req.Request
+	storeID := listObjectsRequest.GetStoreId()
+
+	ctx, span := tracer.Start(ctx, "CountObjects", trace.WithAttributes(
+		attribute.String("store_id", storeID),
+		attribute.String("object_type", listObjectsRequest.GetType()),
+		attribute.String("relation", listObjectsRequest.GetRelation()),
+		attribute.String("user", listObjectsRequest.GetUser()),
+		attribute.String("consistency", listObjectsRequest.GetConsistency().String()),
+		attribute.Bool("approximate", req.Approximate),
+	))
+	defer span.End()
+
+	if listObjectsRequest == nil {
+		return nil, status.Error(codes.InvalidArgument, "request is required")
+	}
+	if err := listObjectsRequest.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: listcount.ServiceName,
+		Method:  "CountObjects",
+	})
+
+	if err := s.checkAuthz(ctx, storeID, apimethod.ListObjects); err != nil {
+		return nil, err
+	}
+
+	typesys, err := s.resolveTypesystem(ctx, storeID, listObjectsRequest.GetAuthorizationModelId())
+	if err != nil {
+		return nil, err
+	}
+
+	checkResolver, checkResolverCloser, err := s.getListObjectsCheckResolverBuilder(storeID).Build()
+	if err != nil {
+		return nil, err
+	}
+	defer checkResolverCloser()
+
+	queryOpts := s.listObjectsResolverOptions(storeID)
+
+	q := commands.NewListObjectsCountQuery(
+		func(opts ...commands.ListObjectsQueryOption) (commands.ListObjectsResolver, error) {
+			return s.newListObjectsQuery(storeID, checkResolver, slices.Concat(queryOpts, opts)...)
+		},
+		commands.WithListObjectsCountDeadline(s.listObjectsDeadline),
+		commands.WithListObjectsCountMaxObjectsInMemory(int(s.listObjectsMaxObjectsInMemory)),
+	)
+
+	listObjectsRequest.AuthorizationModelId = typesys.GetAuthorizationModelID() // the resolved model id
+	result, err := q.Execute(typesystem.ContextWithTypesystem(ctx, typesys), listObjectsRequest, req.Approximate)
+	if err != nil {
+		telemetry.TraceError(span, err)
+		if errors.Is(err, condition.ErrEvaluationFailed) {
+			return nil, serverErrors.ValidationError(err)
+		}
+
+		return nil, err
+	}
+
+	s.observeListObjectsMetrics(ctx, span, methodName, start, listObjectsRequest.GetConsistency(), result.ResolutionMetadata)
+
+	return &listcount.CountResponse{
+		Count:       result.Count,
+		Approximate: result.Approximate,
+		Complete:    result.Complete,
+	}, nil
+}
+
+// CountUsers counts the users of a ListUsers request without returning them, and without the ListUsers max
+// results. It stops at the ListUsers deadline. It is authorized like a ListUsers.
+func (s *Server) CountUsers(ctx context.Context, req *listcount.CountUsersRequest) (*listcount.CountResponse, error) {
+	start := time.Now()
+
+	const methodName = "countusers"
+
+	listUsersRequest := req.Request
+	if listUsersRequest == nil {
+		return nil, status.Error(codes.InvalidArgument, "request is required")
+	}
+
+	ctx, span := tracer.Start(ctx, "CountUsers", listUsersSpanAttributes(listUsersRequest),
+		trace.WithAttributes(attribute.Bool("approximate", req.Approximate)))
+	defer span.End()
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: listcount.ServiceName,
+		Method:  "CountUsers",
+	})
+
+	ctx, err := s.resolveListUsersRequest(ctx, listUsersRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	listUsersQuery := listusers.NewListUsersQuery(s.datastore, listUsersRequest.GetContextualTuples(), append(s.listUsersQueryOptions(),
+		listusers.WithListUsersMaxUsersInMemory(int(s.listUsersMaxUsersInMemory)),
+	)...)
+
+	resp, err := listUsersQuery.CountListUsers(ctx, listUsersRequest, req.Approximate)
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, listUsersErrorToServerError(err)
+	}
+
+	s.observeListUsersMetrics(ctx, span, methodName, start, listUsersRequest.GetConsistency(),
+		resp.Metadata.DatastoreQueryCount, resp.Metadata.DatastoreItemCount, resp.Metadata.DispatchCounter.Load(),
+		resp.Metadata.WasDispatchThrottled.Load(), resp.Metadata.WasDatastoreThrottled.Load())
+
+	return &listcount.CountResponse{
+		Count:       resp.Count,
+		Approximate: resp.Approximate,
+		Complete:    resp.Complete,
+	}, nil
+}
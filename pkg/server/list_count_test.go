@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/listcount"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestListCount(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	// the counts are not bounded by the max results
+	s := MustNewServerWithOpts(WithDatastore(ds),
+		WithListObjectsMaxResults(2), WithListObjectsMaxObjectsInMemory(3),
+		WithListUsersMaxResults(2), WithListUsersMaxUsersInMemory(3),
+	)
+	t.Cleanup(s.Close)
+
+	conn := newJSONGRPCTestConn(t, s, &listcount.ServiceDesc)
+
+	ctx := context.Background()
+	createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "openfga-test"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type project
+			relations
+				define viewer: [user]`)
+	_, err = s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	var writes []*openfgav1.TupleKey
+	for i := 0; i < 8; i++ {
+		writes = append(writes,
+			tuple.NewTupleKey(fmt.Sprintf("project:%d", i), "viewer", "user:anne"),
+			tuple.NewTupleKey("project:0", "viewer", fmt.Sprintf("user:%d", i)),
+		)
+	}
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes:  &openfgav1.WriteRequestWrites{TupleKeys: writes},
+	})
+	require.NoError(t, err)
+
+	for _, approximate := range []bool{false, true} {
+		t.Run(fmt.Sprintf("approximate_%t", approximate), func(t *testing.T) {
+			resp, err := listcount.CountObjects(ctx, conn, &listcount.CountObjectsRequest{
+				Request: &openfgav1.ListObjectsRequest{
+					StoreId:  storeID,
+					Type:     "project",
+					Relation: "viewer",
+					User:     "user:anne",
+				},
+				Approximate: approximate,
+			})
+			require.NoError(t, err)
+			require.Equal(t, &listcount.CountResponse{Count: 8, Approximate: approximate, Complete: true}, resp)
+
+			resp, err = listcount.CountUsers(ctx, conn, &listcount.CountUsersRequest{
+				Request: &openfgav1.ListUsersRequest{
+					StoreId:     storeID,
+					Object:      &openfgav1.Object{Type: "project", Id: "0"},
+					Relation:    "viewer",
+					UserFilters: []*openfgav1.UserTypeFilter{{Type: "user"}},
+				},
+				Approximate: approximate,
+			})
+			require.NoError(t, err)
+			require.Equal(t, &listcount.CountResponse{Count: 9, Approximate: approximate, Complete: true}, resp)
+		})
+	}
+
+	t.Run("invalid_requests", func(t *testing.T) {
+		_, err := listcount.CountObjects(ctx, conn, &listcount.CountObjectsRequest{})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = listcount.CountUsers(ctx, conn, &listcount.CountUsersRequest{})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = listcount.CountObjects(ctx, conn, &listcount.CountObjectsRequest{
+			Request: &openfgav1.ListObjectsRequest{
+				StoreId:  storeID,
+				Type:     "project",
+				Relation: "undefined",
+				User:     "user:anne",
+			},
+		})
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_relation_not_found), status.Code(err))
+	})
+}
@@ -73,16 +73,9 @@ func (s *Server) ListObjects(ctx context.Context, req *openfgav1.ListObjectsRequ
 	}
 	defer checkResolverCloser()
 
-	q, err := commands.NewListObjectsQueryWithShadowConfig(
-		s.datastore,
-		checkResolver,
-		commands.NewShadowListObjectsQueryConfig(
-			commands.WithShadowListObjectsQueryEnabled(s.featureFlagClient.Boolean(serverconfig.ExperimentalShadowListObjects, req.GetStoreId())),
-			commands.WithShadowListObjectsQueryTimeout(s.shadowListObjectsQueryTimeout),
-			commands.WithShadowListObjectsQueryMaxDeltaItems(s.shadowListObjectsQueryMaxDeltaItems),
-			commands.WithShadowListObjectsQueryLogger(s.logger),
-		),
+	q, err := s.newListObjectsQuery(
 		storeID,
+		checkResolver,
 		commands.WithLogger(s.logger),
 		commands.WithListObjectsDeadline(s.listObjectsDeadline),
 		commands.WithListObjectsMaxResults(s.listObjectsMaxResults),
@@ -95,6 +88,7 @@ func (s *Server) ListObjects(ctx context.Context, req *openfgav1.ListObjectsRequ
 		commands.WithResolveNodeLimit(s.resolveNodeLimit),
 		commands.WithResolveNodeBreadthLimit(s.resolveNodeBreadthLimit),
 		commands.WithMaxConcurrentReads(s.maxConcurrentReadsForListObjects),
+		commands.WithListObjectsConcurrencyLimiter(s.listObjectsConcurrencyLimiter),
 		commands.WithListObjectsCache(s.sharedDatastoreResources, s.cacheSettings),
 		commands.WithListObjectsDatastoreThrottler(
 			s.featureFlagClient.Boolean(serverconfig.ExperimentalDatastoreThrottling, storeID),
@@ -108,6 +102,11 @@ func (s *Server) ListObjects(ctx context.Context, req *openfgav1.ListObjectsRequ
 		return nil, serverErrors.NewInternalError("", err)
 	}
 
+	q, err = s.withCandidateModelListObjectsQuery(ctx, storeID, typesys, q)
+	if err != nil {
+		return nil, serverErrors.NewInternalError("", err)
+	}
+
 	result, err := q.Execute(
 		typesystem.ContextWithTypesystem(ctx, typesys),
 		&openfgav1.ListObjectsRequest{
@@ -238,16 +237,9 @@ func (s *Server) StreamedListObjects(req *openfgav1.StreamedListObjectsRequest,
 	}
 	defer checkResolverCloser()
 
-	q, err := commands.NewListObjectsQueryWithShadowConfig(
-		s.datastore,
-		checkResolver,
-		commands.NewShadowListObjectsQueryConfig(
-			commands.WithShadowListObjectsQueryEnabled(s.featureFlagClient.Boolean(serverconfig.ExperimentalShadowListObjects, storeID)),
-			commands.WithShadowListObjectsQueryTimeout(s.shadowListObjectsQueryTimeout),
-			commands.WithShadowListObjectsQueryMaxDeltaItems(s.shadowListObjectsQueryMaxDeltaItems),
-			commands.WithShadowListObjectsQueryLogger(s.logger),
-		),
+	q, err := s.newListObjectsQuery(
 		storeID,
+		checkResolver,
 		commands.WithLogger(s.logger),
 		commands.WithListObjectsDeadline(s.listObjectsDeadline),
 		commands.WithDispatchThrottlerConfig(threshold.Config{
@@ -260,6 +252,7 @@ func (s *Server) StreamedListObjects(req *openfgav1.StreamedListObjectsRequest,
 		commands.WithResolveNodeLimit(s.resolveNodeLimit),
 		commands.WithResolveNodeBreadthLimit(s.resolveNodeBreadthLimit),
 		commands.WithMaxConcurrentReads(s.maxConcurrentReadsForListObjects),
+		commands.WithListObjectsConcurrencyLimiter(s.listObjectsConcurrencyLimiter),
 		commands.WithListObjectsPipelineEnabled(s.featureFlagClient.Boolean(serverconfig.ExperimentalPipelineListObjects, storeID)),
 		commands.WithFeatureFlagClient(s.featureFlagClient),
 	)
@@ -337,6 +330,42 @@ func (s *Server) getListObjectsCheckResolverBuilder(storeID string) *graph.Check
 			graph.WithMaxResolutionDepth(s.resolveNodeLimit),
 		}...),
 		graph.WithCachedCheckResolverOpts(s.cacheSettings.ShouldCacheCheckQueries(), checkCacheOptions...),
+		graph.WithSingleflightCheckResolverOpts(s.inflightChecks != nil, graph.WithInflightChecks(s.inflightChecks)),
 		graph.WithDispatchThrottlingCheckResolverOpts(s.checkDispatchThrottlingEnabled, checkDispatchThrottlingOptions...),
 	}...)
 }
+
+// newListObjectsQuery returns the resolver of the ListObjects requests of storeID. Its strategy is selected by the
+// planner if WithListStrategiesPlanner enabled it, otherwise by the options and the feature flags.
+func (s *Server) newListObjectsQuery(
+	storeID string,
+	checkResolver graph.CheckResolver,
+	opts ...commands.ListObjectsQueryOption,
+) (commands.ListObjectsResolver, error) {
+	if s.listStrategiesTrust != nil {
+		return commands.NewPlannedListObjectsQuery(
+			s.datastore,
+			checkResolver,
+			commands.NewListObjectsPlannerConfig(s.planner, s.listStrategiesTrust,
+				commands.WithListObjectsPlannerShadowTimeout(s.listStrategiesShadowTimeout),
+				commands.WithListObjectsPlannerMaxDeltaItems(s.shadowListObjectsQueryMaxDeltaItems),
+				commands.WithListObjectsPlannerLogger(s.logger),
+			),
+			storeID,
+			opts...,
+		)
+	}
+
+	return commands.NewListObjectsQueryWithShadowConfig(
+		s.datastore,
+		checkResolver,
+		commands.NewShadowListObjectsQueryConfig(
+			commands.WithShadowListObjectsQueryEnabled(s.featureFlagClient.Boolean(serverconfig.ExperimentalShadowListObjects, storeID)),
+			commands.WithShadowListObjectsQueryTimeout(s.shadowListObjectsQueryTimeout),
+			commands.WithShadowListObjectsQueryMaxDeltaItems(s.shadowListObjectsQueryMaxDeltaItems),
+			commands.WithShadowListObjectsQueryLogger(s.logger),
+		),
+		storeID,
+		opts...,
+	)
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/listrelations"
+	"github.com/openfga/openfga/internal/utils/apimethod"
+	"github.com/openfga/openfga/pkg/server/commands"
+	serverconfig "github.com/openfga/openfga/pkg/server/config"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/telemetry"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+var _ listrelations.RelationsServer = (*Server)(nil)
+
+// ListRelations returns, for every requested relation (or every relation of the object type if none is
+// requested), whether the user has it on the object. It is authorized like a Check.
+func (s *Server) ListRelations(ctx context.Context, req *listrelations.ListRelationsRequest) (*listrelations.ListRelationsResponse, error) {
+	const methodName = "listrelations"
+
+	ctx, span := tracer.Start(ctx, "ListRelations", trace.WithAttributes(
+		attribute.String("store_id", req.StoreID),
+		attribute.String("object", req.Object),
+		attribute.String("user", req.User),
+		attribute.StringSlice("relations", req.Relations),
+		attribute.String("consistency", req.Consistency.String()),
+	))
+	defer span.End()
+
+	if err := validateListRelationsRequest(req); err != nil {
+		return nil, err
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: listrelations.ServiceName,
+		Method:  "ListRelations",
+	})
+
+	storeID := req.StoreID
+	if err := s.checkAuthz(ctx, storeID, apimethod.Check); err != nil {
+		return nil, err
+	}
+
+	typesys, err := s.resolveTypesystem(ctx, storeID, req.AuthorizationModelID)
+	if err != nil {
+		return nil, err
+	}
+
+	checkResolver, checkResolverCloser, err := s.getCheckResolverBuilder(storeID).Build()
+	if err != nil {
+		return nil, err
+	}
+	defer checkResolverCloser()
+
+	cmd := commands.NewListRelationsCommand(
+		s.datastore,
+		checkResolver,
+		typesys,
+		commands.WithListRelationsCacheOptions(s.sharedDatastoreResources, s.cacheSettings),
+		commands.WithListRelationsCommandLogger(s.logger),
+		commands.WithListRelationsMaxRelations(s.maxChecksPerBatchCheck),
+		commands.WithListRelationsMaxConcurrentChecks(s.maxConcurrentChecksPerBatch),
+		commands.WithListRelationsDatastoreThrottler(
+			s.featureFlagClient.Boolean(serverconfig.ExperimentalDatastoreThrottling, storeID),
+			s.checkDatastoreThrottleThreshold,
+			s.checkDatastoreThrottleDuration,
+		),
+	)
+
+	relations, metadata, err := cmd.Execute(ctx, &commands.ListRelationsCommandParams{
+		StoreID:          storeID,
+		Object:           req.Object,
+		User:             req.User,
+		Relations:        req.Relations,
+		ContextualTuples: &openfgav1.ContextualTupleKeys{TupleKeys: req.ContextualTuples},
+		Context:          req.Context,
+		Consistency:      req.Consistency,
+	})
+
+	if metadata != nil {
+		dispatchCount := float64(metadata.DispatchCount)
+		grpc_ctxtags.Extract(ctx).Set(dispatchCountHistogramName, dispatchCount)
+		span.SetAttributes(attribute.Float64(dispatchCountHistogramName, dispatchCount))
+		dispatchCountHistogram.WithLabelValues(s.serviceName, methodName).Observe(dispatchCount)
+
+		queryCount := float64(metadata.DatastoreQueryCount)
+		grpc_ctxtags.Extract(ctx).Set(datastoreQueryCountHistogramName, queryCount)
+		span.SetAttributes(attribute.Float64(datastoreQueryCountHistogramName, queryCount))
+		datastoreQueryCountHistogram.WithLabelValues(s.serviceName, methodName).Observe(queryCount)
+
+		datastoreItemCount := float64(metadata.DatastoreItemCount)
+		grpc_ctxtags.Extract(ctx).Set(datastoreItemCountHistogramName, datastoreItemCount)
+		span.SetAttributes(attribute.Float64(datastoreItemCountHistogramName, datastoreItemCount))
+		datastoreItemCountHistogram.WithLabelValues(s.serviceName, methodName).Observe(datastoreItemCount)
+
+		if metadata.DispatchThrottled {
+			throttledRequestCounter.WithLabelValues(s.serviceName, methodName, throttleTypeDispatch).Inc()
+		}
+		grpc_ctxtags.Extract(ctx).Set("request.dispatch_throttled", metadata.DispatchThrottled)
+
+		if metadata.DatastoreThrottled {
+			throttledRequestCounter.WithLabelValues(s.serviceName, methodName, throttleTypeDatastore).Inc()
+		}
+		grpc_ctxtags.Extract(ctx).Set("request.datastore_throttled", metadata.DatastoreThrottled)
+
+		span.SetAttributes(attribute.Int64("memo_hits", int64(metadata.MemoHits)))
+		dispatchesSavedCounter.WithLabelValues(s.serviceName, methodName).Add(float64(metadata.DispatchesSaved))
+	}
+
+	if err != nil {
+		telemetry.TraceError(span, err)
+		var validationError *commands.ListRelationsValidationError
+		if errors.As(err, &validationError) {
+			return nil, serverErrors.ValidationError(err)
+		}
+		return nil, commands.CheckCommandErrorToServerError(err)
+	}
+
+	return &listrelations.ListRelationsResponse{Relations: relations}, nil
+}
+
+func validateListRelationsRequest(req *listrelations.ListRelationsRequest) error {
+	if req.StoreID == "" {
+		return status.Error(codes.InvalidArgument, "store_id is required")
+	}
+	if !tuple.IsValidObject(req.Object) {
+		return status.Errorf(codes.InvalidArgument, "invalid object '%s'", req.Object)
+	}
+	if !tuple.IsValidUser(req.User) {
+		return status.Errorf(codes.InvalidArgument, "invalid user '%s'", req.User)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/jsongrpc"
+	"github.com/openfga/openfga/internal/listrelations"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestListRelations(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(WithDatastore(ds))
+	t.Cleanup(s.Close)
+
+	conn := newJSONGRPCTestConn(t, s, &listrelations.ServiceDesc)
+
+	ctx := context.Background()
+	createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "openfga-test"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type doc
+			relations
+				define owner: [user]
+				define editor: [user] or owner
+				define viewer: [user, user with x_less_than] or editor
+
+		condition x_less_than(x: int) {
+			x < 100
+		}`)
+	_, err = s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+		Conditions:      model.GetConditions(),
+	})
+	require.NoError(t, err)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKey("doc:1", "editor", "user:anne"),
+			tuple.NewTupleKeyWithCondition("doc:1", "viewer", "user:bob", "x_less_than", nil),
+		}},
+	})
+	require.NoError(t, err)
+
+	t.Run("every_relation", func(t *testing.T) {
+		resp, err := listrelations.ListRelations(ctx, conn, &listrelations.ListRelationsRequest{
+			StoreRequest: jsongrpc.StoreRequest{StoreID: storeID},
+			Object:       "doc:1",
+			User:         "user:anne",
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[string]bool{"owner": false, "editor": true, "viewer": true}, resp.Relations)
+	})
+
+	t.Run("with_context_and_contextual_tuples", func(t *testing.T) {
+		reqContext, err := structpb.NewStruct(map[string]any{"x": 10})
+		require.NoError(t, err)
+
+		resp, err := listrelations.ListRelations(ctx, conn, &listrelations.ListRelationsRequest{
+			StoreRequest: jsongrpc.StoreRequest{StoreID: storeID},
+			Object:       "doc:1",
+			User:         "user:bob",
+			Relations:    []string{"owner", "viewer"},
+			ContextualTuples: []*openfgav1.TupleKey{
+				tuple.NewTupleKey("doc:1", "owner", "user:bob"),
+			},
+			Context: reqContext,
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[string]bool{"owner": true, "viewer": true}, resp.Relations)
+	})
+
+	t.Run("invalid_requests", func(t *testing.T) {
+		for _, req := range []*listrelations.ListRelationsRequest{
+			{Object: "doc:1", User: "user:anne"},
+			{StoreRequest: jsongrpc.StoreRequest{StoreID: storeID}, Object: "doc", User: "user:anne"},
+		} {
+			_, err := listrelations.ListRelations(ctx, conn, req)
+			require.Equal(t, codes.InvalidArgument, status.Code(err))
+		}
+
+		// like Check
+		_, err := listrelations.ListRelations(ctx, conn, &listrelations.ListRelationsRequest{
+			StoreRequest: jsongrpc.StoreRequest{StoreID: storeID},
+			Object:       "doc:1",
+			User:         "user:anne",
+			Relations:    []string{"undefined"},
+		})
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_validation_error), status.Code(err))
+	})
+}
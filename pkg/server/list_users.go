@@ -21,6 +21,7 @@ import (
 	"github.com/openfga/openfga/internal/utils/apimethod"
 	"github.com/openfga/openfga/pkg/middleware/validator"
 	"github.com/openfga/openfga/pkg/server/commands/listusers"
+	serverconfig "github.com/openfga/openfga/pkg/server/config"
 	serverErrors "github.com/openfga/openfga/pkg/server/errors"
 	"github.com/openfga/openfga/pkg/telemetry"
 	"github.com/openfga/openfga/pkg/tuple"
@@ -75,35 +76,25 @@ func (s *Server) ListUsers(
 
 	ctx = typesystem.ContextWithTypesystem(ctx, typesys)
 
-	listUsersQuery := listusers.NewListUsersQuery(s.datastore,
+	listUsersQuery := listusers.NewListUsersQueryWithShadowConfig(
+		s.datastore,
 		req.GetContextualTuples(),
-		listusers.WithResolveNodeLimit(s.resolveNodeLimit),
-		listusers.WithResolveNodeBreadthLimit(s.resolveNodeBreadthLimit),
-		listusers.WithListUsersQueryLogger(s.logger),
-		listusers.WithListUsersMaxResults(s.listUsersMaxResults),
-		listusers.WithListUsersDeadline(s.listUsersDeadline),
-		listusers.WithListUsersMaxConcurrentReads(s.maxConcurrentReadsForListUsers),
-		listusers.WithDispatchThrottlerConfig(threshold.Config{
-			Throttler:    s.listUsersDispatchThrottler,
-			Enabled:      s.listUsersDispatchThrottlingEnabled,
-			Threshold:    s.listUsersDispatchDefaultThreshold,
-			MaxThreshold: s.listUsersDispatchThrottlingMaxThreshold,
-		}),
-		listusers.WithListUsersDatastoreThrottler(s.listUsersDatastoreThrottleThreshold, s.listUsersDatastoreThrottleDuration),
+		listusers.NewShadowListUsersQueryConfig(
+			listusers.WithShadowListUsersQueryEnabled(s.featureFlagClient.Boolean(serverconfig.ExperimentalShadowListUsers, req.GetStoreId())),
+			listusers.WithShadowListUsersQueryTimeout(s.shadowListUsersQueryTimeout),
+			listusers.WithShadowListUsersQueryMaxDeltaItems(s.shadowListUsersQueryMaxDeltaItems),
+			listusers.WithShadowListUsersQuerySamplePercentage(s.shadowListUsersQuerySamplePercentage),
+			// the shadow evaluation shares the identical datastore reads of the expansions
+			listusers.WithShadowListUsersQueryOptions(listusers.WithListUsersSharedReads(true)),
+			listusers.WithShadowListUsersQueryLogger(s.logger),
+		),
+		s.listUsersQueryOptions()...,
 	)
 
 	resp, err := listUsersQuery.ListUsers(ctx, req)
 	if err != nil {
 		telemetry.TraceError(span, err)
-
-		switch {
-		case errors.Is(err, graph.ErrResolutionDepthExceeded):
-			return nil, serverErrors.ErrAuthorizationModelResolutionTooComplex
-		case errors.Is(err, condition.ErrEvaluationFailed):
-			return nil, serverErrors.ValidationError(err)
-		default:
-			return nil, serverErrors.HandleError("", err)
-		}
+		return nil, listUsersErrorToServerError(err)
 	}
 
 	datastoreQueryCount := float64(resp.Metadata.DatastoreQueryCount)
@@ -155,6 +146,38 @@ func (s *Server) ListUsers(
 	}, nil
 }
 
+// listUsersQueryOptions returns the options of the queries of the ListUsers requests.
+func (s *Server) listUsersQueryOptions() []listusers.ListUsersQueryOption {
+	return []listusers.ListUsersQueryOption{
+		listusers.WithResolveNodeLimit(s.resolveNodeLimit),
+		listusers.WithResolveNodeBreadthLimit(s.resolveNodeBreadthLimit),
+		listusers.WithListUsersQueryLogger(s.logger),
+		listusers.WithListUsersMaxResults(s.listUsersMaxResults),
+		listusers.WithListUsersDeadline(s.listUsersDeadline),
+		listusers.WithListUsersMaxConcurrentReads(s.maxConcurrentReadsForListUsers),
+		listusers.WithListUsersConcurrencyLimiter(s.listUsersConcurrencyLimiter),
+		listusers.WithDispatchThrottlerConfig(threshold.Config{
+			Throttler:    s.listUsersDispatchThrottler,
+			Enabled:      s.listUsersDispatchThrottlingEnabled,
+			Threshold:    s.listUsersDispatchDefaultThreshold,
+			MaxThreshold: s.listUsersDispatchThrottlingMaxThreshold,
+		}),
+		listusers.WithListUsersDatastoreThrottler(s.listUsersDatastoreThrottleThreshold, s.listUsersDatastoreThrottleDuration),
+		listusers.WithListUsersPlanner(s.planner, s.listStrategiesTrust, s.listStrategiesShadowTimeout),
+	}
+}
+
+func listUsersErrorToServerError(err error) error {
+	switch {
+	case errors.Is(err, graph.ErrResolutionDepthExceeded):
+		return serverErrors.ErrAuthorizationModelResolutionTooComplex
+	case errors.Is(err, condition.ErrEvaluationFailed):
+		return serverErrors.ValidationError(err)
+	default:
+		return serverErrors.HandleError("", err)
+	}
+}
+
 func userFiltersToString(filter []*openfgav1.UserTypeFilter) string {
 	var s strings.Builder
 	for _, f := range filter {
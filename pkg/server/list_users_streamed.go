@@ -0,0 +1,192 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/paginatedlist"
+	"github.com/openfga/openfga/internal/streamedlistusers"
+	"github.com/openfga/openfga/internal/utils"
+	"github.com/openfga/openfga/internal/utils/apimethod"
+	"github.com/openfga/openfga/pkg/server/commands/listusers"
+	"github.com/openfga/openfga/pkg/telemetry"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+var _ streamedlistusers.StreamedListUsersServer = (*Server)(nil)
+
+// StreamedListUsers streams the users matching the user filter that have the relation with the object, each one
+// as soon as it is found and without the ListUsers max results. It stops at the ListUsers deadline. It is
+// authorized like a ListUsers.
+func (s *Server) StreamedListUsers(req *openfgav1.ListUsersRequest, srv streamedlistusers.StreamServer) error {
+	start := time.Now()
+
+	const methodName = "streamedlistusers"
+
+	ctx, span := tracer.Start(srv.Context(), "StreamedListUsers", listUsersSpanAttributes(req))
+	defer span.End()
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: streamedlistusers.ServiceName,
+		Method:  "StreamedListUsers",
+	})
+
+	ctx, err := s.resolveListUsersRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	listUsersQuery := listusers.NewListUsersQuery(s.datastore, req.GetContextualTuples(), append(s.listUsersQueryOptions(),
+		listusers.WithListUsersMaxUsersInMemory(int(s.listUsersMaxUsersInMemory)),
+	)...)
+
+	metadata, err := listUsersQuery.StreamListUsers(ctx, req, srv.Send)
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return listUsersErrorToServerError(err)
+	}
+
+	s.observeListUsersMetrics(ctx, span, methodName, start, req.GetConsistency(),
+		metadata.DatastoreQueryCount, metadata.DatastoreItemCount, metadata.DispatchCounter.Load(),
+		metadata.WasDispatchThrottled.Load(), metadata.WasDatastoreThrottled.Load())
+
+	return nil
+}
+
+// PaginatedListUsers returns the users of a ListUsers request page by page, ordered by their string representation,
+// with continuation tokens that resume the listing after the last user of the previous page. The pages hold at most
+// the ListUsers max results, and are not cut by the ListUsers deadline. It is authorized like a ListUsers.
+func (s *Server) PaginatedListUsers(ctx context.Context, req *paginatedlist.PaginatedListUsersRequest) (*paginatedlist.PaginatedListUsersResponse, error) {
+	start := time.Now()
+
+	const methodName = "paginatedlistusers"
+
+	listUsersRequest := req.Request
+	if listUsersRequest == nil {
+		return nil, status.Error(codes.InvalidArgument, "request is required")
+	}
+
+	ctx, span := tracer.Start(ctx, "PaginatedListUsers", listUsersSpanAttributes(listUsersRequest),
+		trace.WithAttributes(attribute.Int("page_size", int(req.PageSize))))
+	defer span.End()
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: paginatedlist.ServiceName,
+		Method:  "PaginatedListUsers",
+	})
+
+	ctx, err := s.resolveListUsersRequest(ctx, listUsersRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	listUsersQuery := listusers.NewListUsersQuery(s.datastore, listUsersRequest.GetContextualTuples(), append(s.listUsersQueryOptions(),
+		listusers.WithListUsersEncoder(s.encoder),
+		listusers.WithListUsersTokenSerializer(s.tokenSerializer),
+	)...)
+
+	resp, err := listUsersQuery.PaginatedListUsers(ctx, listUsersRequest, req.PageSize, req.ContinuationToken)
+	if err != nil {
+		telemetry.TraceError(span, err)
+		if _, ok := status.FromError(err); ok {
+			// the invalid page sizes and continuation tokens
+			return nil, err
+		}
+		return nil, listUsersErrorToServerError(err)
+	}
+
+	s.observeListUsersMetrics(ctx, span, methodName, start, listUsersRequest.GetConsistency(),
+		resp.Metadata.DatastoreQueryCount, resp.Metadata.DatastoreItemCount, resp.Metadata.DispatchCounter.Load(),
+		resp.Metadata.WasDispatchThrottled.Load(), resp.Metadata.WasDatastoreThrottled.Load())
+
+	return &paginatedlist.PaginatedListUsersResponse{
+		Users:             resp.Users,
+		ContinuationToken: resp.ContinuationToken,
+	}, nil
+}
+
+func listUsersSpanAttributes(req *openfgav1.ListUsersRequest) trace.SpanStartOption {
+	return trace.WithAttributes(
+		attribute.String("store_id", req.GetStoreId()),
+		attribute.String("object", tuple.BuildObject(req.GetObject().GetType(), req.GetObject().GetId())),
+		attribute.String("relation", req.GetRelation()),
+		attribute.String("user_filters", userFiltersToString(req.GetUserFilters())),
+		attribute.String("consistency", req.GetConsistency().String()),
+	)
+}
+
+// resolveListUsersRequest validates and authorizes a ListUsers request of the services that are not generated from
+// the OpenFGA protobuf definitions, and returns the context holding its typesystem. The request is validated
+// explicitly, since it may not have gone through the validator interceptor.
+func (s *Server) resolveListUsersRequest(ctx context.Context, req *openfgav1.ListUsersRequest) (context.Context, error) {
+	if err := req.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.checkAuthz(ctx, req.GetStoreId(), apimethod.ListUsers); err != nil {
+		return nil, err
+	}
+
+	typesys, err := s.resolveTypesystem(ctx, req.GetStoreId(), req.GetAuthorizationModelId())
+	if err != nil {
+		return nil, err
+	}
+	req.AuthorizationModelId = typesys.GetAuthorizationModelID() // the resolved model id
+
+	if err := listusers.ValidateListUsersRequest(ctx, req, typesys); err != nil {
+		return nil, err
+	}
+
+	return typesystem.ContextWithTypesystem(ctx, typesys), nil
+}
+
+func (s *Server) observeListUsersMetrics(
+	ctx context.Context,
+	span trace.Span,
+	methodName string,
+	start time.Time,
+	consistency openfgav1.ConsistencyPreference,
+	queryCount uint32,
+	itemCount uint64,
+	dispatches uint32,
+	wasDispatchThrottled, wasDatastoreThrottled bool,
+) {
+	datastoreQueryCount := float64(queryCount)
+	grpc_ctxtags.Extract(ctx).Set(datastoreQueryCountHistogramName, datastoreQueryCount)
+	span.SetAttributes(attribute.Float64(datastoreQueryCountHistogramName, datastoreQueryCount))
+	datastoreQueryCountHistogram.WithLabelValues(s.serviceName, methodName).Observe(datastoreQueryCount)
+
+	datastoreItemCount := float64(itemCount)
+	grpc_ctxtags.Extract(ctx).Set(datastoreItemCountHistogramName, datastoreItemCount)
+	span.SetAttributes(attribute.Float64(datastoreItemCountHistogramName, datastoreItemCount))
+	datastoreItemCountHistogram.WithLabelValues(s.serviceName, methodName).Observe(datastoreItemCount)
+
+	dispatchCount := float64(dispatches)
+	grpc_ctxtags.Extract(ctx).Set(dispatchCountHistogramName, dispatchCount)
+	span.SetAttributes(attribute.Float64(dispatchCountHistogramName, dispatchCount))
+	dispatchCountHistogram.WithLabelValues(s.serviceName, methodName).Observe(dispatchCount)
+
+	requestDurationHistogram.WithLabelValues(
+		s.serviceName,
+		methodName,
+		utils.Bucketize(uint(datastoreQueryCount), s.requestDurationByQueryHistogramBuckets),
+		utils.Bucketize(uint(dispatchCount), s.requestDurationByDispatchCountHistogramBuckets),
+		consistency.String(),
+	).Observe(float64(time.Since(start).Milliseconds()))
+
+	if wasDispatchThrottled {
+		throttledRequestCounter.WithLabelValues(s.serviceName, methodName, throttleTypeDispatch).Inc()
+	}
+	if wasDatastoreThrottled {
+		throttledRequestCounter.WithLabelValues(s.serviceName, methodName, throttleTypeDatastore).Inc()
+	}
+}
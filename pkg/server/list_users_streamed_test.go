@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/paginatedlist"
+	"github.com/openfga/openfga/internal/streamedlistusers"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestStreamedAndPaginatedListUsers(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(WithDatastore(ds), WithListUsersMaxResults(3), WithListUsersMaxUsersInMemory(2))
+	t.Cleanup(s.Close)
+
+	conn := newJSONGRPCTestConn(t, s, &streamedlistusers.ServiceDesc, &paginatedlist.ServiceDesc)
+
+	ctx := context.Background()
+	createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "openfga-test"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type folder
+			relations
+				define viewer: [user, user:*]`)
+	_, err = s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	writes := []*openfgav1.TupleKey{tuple.NewTupleKey("folder:audit", "viewer", "user:*")}
+	expected := []string{"user:*"}
+	for i := 0; i < 7; i++ {
+		writes = append(writes, tuple.NewTupleKey("folder:audit", "viewer", fmt.Sprintf("user:%02d", i)))
+		expected = append(expected, fmt.Sprintf("user:%02d", i))
+	}
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes:  &openfgav1.WriteRequestWrites{TupleKeys: writes},
+	})
+	require.NoError(t, err)
+
+	listUsersRequest := &openfgav1.ListUsersRequest{
+		StoreId:     storeID,
+		Object:      &openfgav1.Object{Type: "folder", Id: "audit"},
+		Relation:    "viewer",
+		UserFilters: []*openfgav1.UserTypeFilter{{Type: "user"}},
+	}
+
+	t.Run("streamed", func(t *testing.T) {
+		stream, err := streamedlistusers.StreamedListUsers(ctx, conn, listUsersRequest)
+		require.NoError(t, err)
+
+		var users []string
+		for {
+			user, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			users = append(users, tuple.UserProtoToString(user))
+		}
+		require.ElementsMatch(t, expected, users)
+	})
+
+	t.Run("paginated", func(t *testing.T) {
+		var users []string
+		token := ""
+		for {
+			resp, err := paginatedlist.PaginatedListUsers(ctx, conn, &paginatedlist.PaginatedListUsersRequest{
+				Request:           listUsersRequest,
+				ContinuationToken: token,
+			})
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(resp.Users), 3)
+			for _, user := range resp.Users {
+				users = append(users, tuple.UserProtoToString(user))
+			}
+
+			token = resp.ContinuationToken
+			if token == "" {
+				break
+			}
+		}
+		require.Equal(t, expected, users)
+	})
+
+	t.Run("invalid_requests", func(t *testing.T) {
+		_, err := paginatedlist.PaginatedListUsers(ctx, conn, &paginatedlist.PaginatedListUsersRequest{})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = paginatedlist.PaginatedListUsers(ctx, conn, &paginatedlist.PaginatedListUsersRequest{
+			Request: &openfgav1.ListUsersRequest{StoreId: storeID, Relation: "viewer"},
+		})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = paginatedlist.PaginatedListUsers(ctx, conn, &paginatedlist.PaginatedListUsersRequest{
+			Request:  listUsersRequest,
+			PageSize: 4,
+		})
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_validation_error), status.Code(err))
+
+		_, err = paginatedlist.PaginatedListUsers(ctx, conn, &paginatedlist.PaginatedListUsersRequest{
+			Request:           listUsersRequest,
+			ContinuationToken: "not a token",
+		})
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_invalid_continuation_token), status.Code(err))
+
+		stream, err := streamedlistusers.StreamedListUsers(ctx, conn, &openfgav1.ListUsersRequest{
+			StoreId:     storeID,
+			Object:      &openfgav1.Object{Type: "folder", Id: "audit"},
+			Relation:    "undefined",
+			UserFilters: []*openfgav1.UserTypeFilter{{Type: "user"}},
+		})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_relation_not_found), status.Code(err))
+	})
+}
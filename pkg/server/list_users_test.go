@@ -197,6 +197,7 @@ func TestModelIdNotFound(t *testing.T) {
 	defer mockController.Finish()
 
 	mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+	mockDatastore.EXPECT().ReadActiveAuthorizationModelID(gomock.Any(), gomock.Any()).Return("", storage.ErrNotFound)
 	mockDatastore.EXPECT().FindLatestAuthorizationModel(gomock.Any(), gomock.Any()).Return(nil, storage.ErrNotFound)
 
 	server := MustNewServerWithOpts(
@@ -246,6 +247,7 @@ func TestExperimentalListUsers(t *testing.T) {
 	})
 
 	t.Run("list_users_returns_error_if_latest_model_not_found", func(t *testing.T) {
+		mockDatastore.EXPECT().ReadActiveAuthorizationModelID(gomock.Any(), gomock.Any()).Return("", storage.ErrNotFound)
 		mockDatastore.EXPECT().FindLatestAuthorizationModel(gomock.Any(), gomock.Any()).Return(nil, storage.ErrNotFound) // error demonstrates that main code path is reached
 
 		_, err := server.ListUsers(ctx, req)
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/internal/modeldiff"
+	"github.com/openfga/openfga/internal/utils/apimethod"
+	"github.com/openfga/openfga/pkg/server/commands"
+	"github.com/openfga/openfga/pkg/telemetry"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+const (
+	defaultDryRunMaxOrphanedTuples = 100
+	maxDryRunMaxOrphanedTuples     = 1000
+)
+
+var _ modeldiff.ModelDiffServer = (*Server)(nil)
+
+// DiffAuthorizationModels returns the changes between two authorization models of a store, and classifies each
+// of them as additive, behavior-changing or breaking. It is authorized like a ReadAuthorizationModel.
+func (s *Server) DiffAuthorizationModels(ctx context.Context, req *modeldiff.DiffAuthorizationModelsRequest) (*modeldiff.DiffAuthorizationModelsResponse, error) {
+	ctx, span := tracer.Start(ctx, "DiffAuthorizationModels", trace.WithAttributes(
+		attribute.String("store_id", req.StoreID),
+		attribute.String("from_authorization_model_id", req.FromAuthorizationModelID),
+		attribute.String("to_authorization_model_id", req.ToAuthorizationModelID),
+	))
+	defer span.End()
+
+	if req.StoreID == "" {
+		return nil, status.Error(codes.InvalidArgument, "store_id is required")
+	}
+	if req.FromAuthorizationModelID == "" {
+		return nil, status.Error(codes.InvalidArgument, "from_authorization_model_id is required")
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: modeldiff.ServiceName,
+		Method:  "DiffAuthorizationModels",
+	})
+
+	if err := s.checkAuthz(ctx, req.StoreID, apimethod.ReadAuthorizationModel); err != nil {
+		return nil, err
+	}
+
+	from, err := s.resolveTypesystem(ctx, req.StoreID, req.FromAuthorizationModelID)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := s.resolveTypesystem(ctx, req.StoreID, req.ToAuthorizationModelID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := typesystem.Diff(from, to)
+
+	changes := make([]modeldiff.ModelChange, 0, len(diff.Changes))
+	for _, change := range diff.Changes {
+		changes = append(changes, modeldiff.ModelChange{
+			Kind:           string(change.Kind),
+			Classification: change.Classification.String(),
+			ObjectType:     change.ObjectType,
+			Relation:       change.Relation,
+			Condition:      change.Condition,
+			Detail:         change.Detail,
+		})
+	}
+
+	span.SetAttributes(attribute.String("classification", diff.Classification().String()))
+	return &modeldiff.DiffAuthorizationModelsResponse{
+		FromAuthorizationModelID: from.GetAuthorizationModelID(),
+		ToAuthorizationModelID:   to.GetAuthorizationModelID(),
+		Classification:           diff.Classification().String(),
+		Changes:                  changes,
+	}, nil
+}
+
+// DryRunWriteAuthorizationModel validates the model of a WriteAuthorizationModel request without writing it, and
+// reads every tuple of the store to report the tuples that the model would invalidate. It is authorized like a
+// WriteAuthorizationModel.
+func (s *Server) DryRunWriteAuthorizationModel(ctx context.Context, req *modeldiff.DryRunWriteAuthorizationModelRequest) (*modeldiff.DryRunWriteAuthorizationModelResponse, error) {
+	writeRequest := req.Request
+	if writeRequest == nil {
+		return nil, status.Error(codes.InvalidArgument, "request is required")
+	}
+
+	ctx, span := tracer.Start(ctx, "DryRunWriteAuthorizationModel", trace.WithAttributes(
+		attribute.String("store_id", writeRequest.GetStoreId()),
+	))
+	defer span.End()
+
+	if err := writeRequest.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: modeldiff.ServiceName,
+		Method:  "DryRunWriteAuthorizationModel",
+	})
+
+	if err := s.checkAuthz(ctx, writeRequest.GetStoreId(), apimethod.WriteAuthorizationModel); err != nil {
+		return nil, err
+	}
+
+	maxOrphanedTuples := defaultDryRunMaxOrphanedTuples
+	if req.MaxOrphanedTuples > 0 {
+		maxOrphanedTuples = min(int(req.MaxOrphanedTuples), maxDryRunMaxOrphanedTuples)
+	}
+
+	c := commands.NewWriteAuthorizationModelCommand(s.datastore,
+		commands.WithWriteAuthModelLogger(s.logger),
+		commands.WithWriteAuthModelMaxSizeInBytes(s.maxAuthorizationModelSizeInBytes),
+	)
+	res, err := c.DryRun(ctx, writeRequest, commands.NewOrphanedTuplesQuery(s.datastore,
+		commands.WithOrphanedTuplesMaxSampleSize(maxOrphanedTuples),
+	))
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+
+	orphanedTuples := make([]modeldiff.OrphanedTuple, 0, len(res.OrphanedTuples))
+	for _, orphan := range res.OrphanedTuples {
+		orphanedTuples = append(orphanedTuples, modeldiff.OrphanedTuple{
+			Object:    orphan.TupleKey.GetObject(),
+			Relation:  orphan.TupleKey.GetRelation(),
+			User:      orphan.TupleKey.GetUser(),
+			Condition: orphan.TupleKey.GetCondition().GetName(),
+			Reason:    orphan.Reason,
+		})
+	}
+
+	span.SetAttributes(attribute.Int64("orphaned_tuple_count", int64(res.OrphanedTupleCount)))
+	return &modeldiff.DryRunWriteAuthorizationModelResponse{
+		ScannedTupleCount:  res.ScannedTupleCount,
+		OrphanedTupleCount: res.OrphanedTupleCount,
+		OrphanedTuples:     orphanedTuples,
+		Complete:           res.Complete,
+		Rejected: s.authorizationModelOrphanedTuplesCheckEnabled &&
+			res.OrphanedTupleCount > uint64(s.authorizationModelOrphanedTuplesCheckThreshold),
+	}, nil
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/jsongrpc"
+	"github.com/openfga/openfga/internal/modeldiff"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestDiffAuthorizationModels(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(WithDatastore(ds))
+	t.Cleanup(s.Close)
+
+	conn := newJSONGRPCTestConn(t, s, &modeldiff.ServiceDesc)
+
+	ctx := context.Background()
+	createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "openfga-test"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	writeModel := func(t *testing.T, dsl string) string {
+		model := testutils.MustTransformDSLToProtoWithID(dsl)
+		resp, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+			StoreId:         storeID,
+			SchemaVersion:   model.GetSchemaVersion(),
+			TypeDefinitions: model.GetTypeDefinitions(),
+		})
+		require.NoError(t, err)
+		return resp.GetAuthorizationModelId()
+	}
+
+	fromModelID := writeModel(t, `
+		model
+			schema 1.1
+		type user
+		type employee
+		type project
+			relations
+				define viewer: [user, employee]`)
+	toModelID := writeModel(t, `
+		model
+			schema 1.1
+		type user
+		type project
+			relations
+				define owner: [user]
+				define viewer: [user] or owner`)
+
+	t.Run("latest_model", func(t *testing.T) {
+		resp, err := modeldiff.DiffAuthorizationModels(ctx, conn, &modeldiff.DiffAuthorizationModelsRequest{
+			StoreRequest:             jsongrpc.StoreRequest{StoreID: storeID},
+			FromAuthorizationModelID: fromModelID,
+		})
+		require.NoError(t, err)
+		require.Equal(t, &modeldiff.DiffAuthorizationModelsResponse{
+			FromAuthorizationModelID: fromModelID,
+			ToAuthorizationModelID:   toModelID,
+			Classification:           "breaking",
+			Changes: []modeldiff.ModelChange{
+				{Kind: "type_removed", Classification: "breaking", ObjectType: "employee"},
+				{Kind: "relation_added", Classification: "additive", ObjectType: "project", Relation: "owner"},
+				{Kind: "relation_rewrite_changed", Classification: "behavior_changing", ObjectType: "project", Relation: "viewer"},
+				{Kind: "type_restriction_removed", Classification: "breaking", ObjectType: "project", Relation: "viewer", Detail: "employee"},
+			},
+		}, resp)
+	})
+
+	t.Run("same_model", func(t *testing.T) {
+		resp, err := modeldiff.DiffAuthorizationModels(ctx, conn, &modeldiff.DiffAuthorizationModelsRequest{
+			StoreRequest:             jsongrpc.StoreRequest{StoreID: storeID},
+			FromAuthorizationModelID: toModelID,
+			ToAuthorizationModelID:   toModelID,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "additive", resp.Classification)
+		require.Empty(t, resp.Changes)
+	})
+
+	t.Run("dry_run_write", func(t *testing.T) {
+		_, err := s.Write(ctx, &openfgav1.WriteRequest{
+			StoreId: storeID,
+			Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+				tuple.NewTupleKey("project:1", "owner", "user:anne"),
+				tuple.NewTupleKey("project:1", "viewer", "user:anne"),
+			}},
+		})
+		require.NoError(t, err)
+
+		// the first model has no owner relation
+		fromModel := testutils.MustTransformDSLToProtoWithID(`
+			model
+				schema 1.1
+			type user
+			type employee
+			type project
+				relations
+					define viewer: [user, employee]`)
+		resp, err := modeldiff.DryRunWriteAuthorizationModel(ctx, conn, &modeldiff.DryRunWriteAuthorizationModelRequest{
+			Request: &openfgav1.WriteAuthorizationModelRequest{
+				StoreId:         storeID,
+				SchemaVersion:   fromModel.GetSchemaVersion(),
+				TypeDefinitions: fromModel.GetTypeDefinitions(),
+			},
+		})
+		require.NoError(t, err)
+		// only the tuples of the removed project#owner relation are read
+		require.Equal(t, uint64(1), resp.ScannedTupleCount)
+		require.Equal(t, uint64(1), resp.OrphanedTupleCount)
+		require.Len(t, resp.OrphanedTuples, 1)
+		require.True(t, resp.Complete)
+		require.Equal(t, "project:1", resp.OrphanedTuples[0].Object)
+		require.Equal(t, "owner", resp.OrphanedTuples[0].Relation)
+		require.NotEmpty(t, resp.OrphanedTuples[0].Reason)
+		require.False(t, resp.Rejected)
+
+		_, err = modeldiff.DryRunWriteAuthorizationModel(ctx, conn, &modeldiff.DryRunWriteAuthorizationModelRequest{})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("invalid_requests", func(t *testing.T) {
+		_, err := modeldiff.DiffAuthorizationModels(ctx, conn, &modeldiff.DiffAuthorizationModelsRequest{StoreRequest: jsongrpc.StoreRequest{StoreID: storeID}})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = modeldiff.DiffAuthorizationModels(ctx, conn, &modeldiff.DiffAuthorizationModelsRequest{
+			StoreRequest:             jsongrpc.StoreRequest{StoreID: storeID},
+			FromAuthorizationModelID: "01JBVMPYB8Q4G2NCC8Z2RMMA5A",
+		})
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_authorization_model_not_found), status.Code(err))
+	})
+}
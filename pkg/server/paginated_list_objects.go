@@ -0,0 +1,213 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/condition"
+	"github.com/openfga/openfga/internal/paginatedlist"
+	"github.com/openfga/openfga/internal/throttler/threshold"
+	"github.com/openfga/openfga/internal/utils"
+	"github.com/openfga/openfga/internal/utils/apimethod"
+	"github.com/openfga/openfga/pkg/server/commands"
+	serverconfig "github.com/openfga/openfga/pkg/server/config"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/telemetry"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+var _ paginatedlist.PaginatedListServer = (*Server)(nil)
+
+// maxObjectLength is the maximum length of an object of the OpenFGA API, as in the validation of its tuple keys.
+const maxObjectLength = 256
+
+// PaginatedListObjects returns the objects of a ListObjects request page by page, in ascending order, with
+// continuation tokens that resume the listing after the last object of the previous page. The pages hold at most
+// the ListObjects max results, and are not cut by the ListObjects deadline. The objects can be restricted to a list
+// of candidate object IDs, or to an object ID prefix, which prunes the traversal. It is authorized like a
+// ListObjects.
+func (s *Server) PaginatedListObjects(ctx context.Context, req *paginatedlist.PaginatedListObjectsRequest) (*paginatedlist.PaginatedListObjectsResponse, error) {
+	start := time.Now()
+
+	const methodName = "paginatedlistobjects"
+
+	listObjectsRequest := req.Request
+	storeID := listObjectsRequest.GetStoreId()
+
+	ctx, span := tracer.Start(ctx, "PaginatedListObjects", trace.WithAttributes(
+		attribute.String("store_id", storeID),
+		attribute.String("object_type", listObjectsRequest.GetType()),
+		attribute.String("relation", listObjectsRequest.GetRelation()),
+		attribute.String("user", listObjectsRequest.GetUser()),
+		attribute.String("consistency", listObjectsRequest.GetConsistency().String()),
+		attribute.Int("page_size", int(req.PageSize)),
+		attribute.Int("object_ids", len(req.ObjectIDs)),
+		attribute.String("object_id_prefix", req.ObjectIDPrefix),
+	))
+	defer span.End()
+
+	if err := validatePaginatedListObjectsRequest(req, s.listObjectsMaxCandidateObjectIDs); err != nil {
+		return nil, err
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: paginatedlist.ServiceName,
+		Method:  "PaginatedListObjects",
+	})
+
+	if err := s.checkAuthz(ctx, storeID, apimethod.ListObjects); err != nil {
+		return nil, err
+	}
+
+	typesys, err := s.resolveTypesystem(ctx, storeID, listObjectsRequest.GetAuthorizationModelId())
+	if err != nil {
+		return nil, err
+	}
+
+	checkResolver, checkResolverCloser, err := s.getListObjectsCheckResolverBuilder(storeID).Build()
+	if err != nil {
+		return nil, err
+	}
+	defer checkResolverCloser()
+
+	queryOpts := append(s.listObjectsResolverOptions(storeID),
+		commands.WithListObjectsCandidateObjectIDs(req.ObjectIDs),
+		commands.WithListObjectsObjectIDPrefix(req.ObjectIDPrefix),
+	)
+
+	q := commands.NewListObjectsPageQuery(
+		func(opts ...commands.ListObjectsQueryOption) (commands.ListObjectsResolver, error) {
+			return s.newListObjectsQuery(storeID, checkResolver, slices.Concat(queryOpts, opts)...)
+		},
+		commands.WithListObjectsMaxPageSize(s.listObjectsMaxResults),
+		commands.WithListObjectsPageQueryEncoder(s.encoder),
+		commands.WithListObjectsPageQueryTokenSerializer(s.tokenSerializer),
+	)
+
+	listObjectsRequest.AuthorizationModelId = typesys.GetAuthorizationModelID() // the resolved model id
+	result, err := q.Execute(
+		typesystem.ContextWithTypesystem(ctx, typesys),
+		listObjectsRequest,
+		req.PageSize,
+		req.ContinuationToken,
+	)
+	if err != nil {
+		telemetry.TraceError(span, err)
+		if errors.Is(err, condition.ErrEvaluationFailed) {
+			return nil, serverErrors.ValidationError(err)
+		}
+
+		return nil, err
+	}
+
+	s.observeListObjectsMetrics(ctx, span, methodName, start, listObjectsRequest.GetConsistency(), result.ResolutionMetadata)
+
+	return &paginatedlist.PaginatedListObjectsResponse{
+		Objects:           result.Objects,
+		ContinuationToken: result.ContinuationToken,
+	}, nil
+}
+
+// validatePaginatedListObjectsRequest validates the ListObjects request explicitly, since the validator
+// interceptor only validates the generated protobuf messages. The candidate object IDs, which are at most
+// maxObjectIDs unless it is 0, are validated like the IDs of the objects of the request type.
+func validatePaginatedListObjectsRequest(req *paginatedlist.PaginatedListObjectsRequest, maxObjectIDs uint32) error {
+	if req.Request == nil {
+		return status.Error(codes.InvalidArgument, "request is required")
+	}
+	if err := req.Request.Validate(); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if maxObjectIDs > 0 && len(req.ObjectIDs) > int(maxObjectIDs) {
+		return status.Errorf(codes.InvalidArgument, "object_ids must have at most %d items", maxObjectIDs)
+	}
+	for _, objectID := range req.ObjectIDs {
+		object := tuple.BuildObject(req.Request.GetType(), objectID)
+		if len(object) > maxObjectLength || !tuple.IsValidObject(object) {
+			return status.Errorf(codes.InvalidArgument, "invalid object id '%s'", objectID)
+		}
+	}
+	return nil
+}
+
+// listObjectsResolverOptions returns the options of the resolvers of the ListObjects requests of storeID that are
+// served by the services that are not generated from the OpenFGA protobuf definitions.
+func (s *Server) listObjectsResolverOptions(storeID string) []commands.ListObjectsQueryOption {
+	return []commands.ListObjectsQueryOption{
+		commands.WithLogger(s.logger),
+		commands.WithDispatchThrottlerConfig(threshold.Config{
+			Throttler:    s.listObjectsDispatchThrottler,
+			Enabled:      s.listObjectsDispatchThrottlingEnabled,
+			Threshold:    s.listObjectsDispatchDefaultThreshold,
+			MaxThreshold: s.listObjectsDispatchThrottlingMaxThreshold,
+		}),
+		commands.WithResolveNodeLimit(s.resolveNodeLimit),
+		commands.WithResolveNodeBreadthLimit(s.resolveNodeBreadthLimit),
+		commands.WithMaxConcurrentReads(s.maxConcurrentReadsForListObjects),
+		commands.WithListObjectsConcurrencyLimiter(s.listObjectsConcurrencyLimiter),
+		commands.WithListObjectsCache(s.sharedDatastoreResources, s.cacheSettings),
+		commands.WithListObjectsDatastoreThrottler(
+			s.featureFlagClient.Boolean(serverconfig.ExperimentalDatastoreThrottling, storeID),
+			s.listObjectsDatastoreThrottleThreshold,
+			s.listObjectsDatastoreThrottleDuration,
+		),
+		commands.WithListObjectsPipelineEnabled(s.featureFlagClient.Boolean(serverconfig.ExperimentalPipelineListObjects, storeID)),
+		commands.WithFeatureFlagClient(s.featureFlagClient),
+	}
+}
+
+func (s *Server) observeListObjectsMetrics(
+	ctx context.Context,
+	span trace.Span,
+	methodName string,
+	start time.Time,
+	consistency openfgav1.ConsistencyPreference,
+	resolutionMetadata *commands.ListObjectsResolutionMetadata,
+) {
+	datastoreQueryCount := float64(resolutionMetadata.DatastoreQueryCount.Load())
+	grpc_ctxtags.Extract(ctx).Set(datastoreQueryCountHistogramName, datastoreQueryCount)
+	span.SetAttributes(attribute.Float64(datastoreQueryCountHistogramName, datastoreQueryCount))
+	datastoreQueryCountHistogram.WithLabelValues(s.serviceName, methodName).Observe(datastoreQueryCount)
+
+	datastoreItemCount := float64(resolutionMetadata.DatastoreItemCount.Load())
+	grpc_ctxtags.Extract(ctx).Set(datastoreItemCountHistogramName, datastoreItemCount)
+	span.SetAttributes(attribute.Float64(datastoreItemCountHistogramName, datastoreItemCount))
+	datastoreItemCountHistogram.WithLabelValues(s.serviceName, methodName).Observe(datastoreItemCount)
+
+	dispatchCount := float64(resolutionMetadata.DispatchCounter.Load())
+	grpc_ctxtags.Extract(ctx).Set(dispatchCountHistogramName, dispatchCount)
+	span.SetAttributes(attribute.Float64(dispatchCountHistogramName, dispatchCount))
+	dispatchCountHistogram.WithLabelValues(s.serviceName, methodName).Observe(dispatchCount)
+
+	requestDurationHistogram.WithLabelValues(
+		s.serviceName,
+		methodName,
+		utils.Bucketize(uint(datastoreQueryCount), s.requestDurationByQueryHistogramBuckets),
+		utils.Bucketize(uint(dispatchCount), s.requestDurationByDispatchCountHistogramBuckets),
+		consistency.String(),
+	).Observe(float64(time.Since(start).Milliseconds()))
+
+	wasDispatchThrottled := resolutionMetadata.DispatchThrottled.Load()
+	grpc_ctxtags.Extract(ctx).Set("request.dispatch_throttled", wasDispatchThrottled)
+	if wasDispatchThrottled {
+		throttledRequestCounter.WithLabelValues(s.serviceName, methodName, throttleTypeDispatch).Inc()
+	}
+
+	wasDatastoreThrottled := resolutionMetadata.DatastoreThrottled.Load()
+	grpc_ctxtags.Extract(ctx).Set("request.datastore_throttled", wasDatastoreThrottled)
+	if wasDatastoreThrottled {
+		throttledRequestCounter.WithLabelValues(s.serviceName, methodName, throttleTypeDatastore).Inc()
+	}
+}
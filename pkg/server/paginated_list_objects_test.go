@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/paginatedlist"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestPaginatedListObjects(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(WithDatastore(ds), WithListObjectsMaxResults(4), WithListObjectsMaxCandidateObjectIDs(3))
+	t.Cleanup(s.Close)
+
+	conn := newJSONGRPCTestConn(t, s, &paginatedlist.ServiceDesc)
+
+	ctx := context.Background()
+	createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "openfga-test"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type doc
+			relations
+				define viewer: [user]`)
+	_, err = s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	var writes []*openfgav1.TupleKey
+	var expected []string
+	for i := 0; i < 10; i++ {
+		writes = append(writes, tuple.NewTupleKey(fmt.Sprintf("doc:%02d", i), "viewer", "user:anne"))
+		expected = append(expected, fmt.Sprintf("doc:%02d", i))
+	}
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes:  &openfgav1.WriteRequestWrites{TupleKeys: writes},
+	})
+	require.NoError(t, err)
+
+	listObjectsRequest := &openfgav1.ListObjectsRequest{
+		StoreId:  storeID,
+		Type:     "doc",
+		Relation: "viewer",
+		User:     "user:anne",
+	}
+
+	t.Run("every_page", func(t *testing.T) {
+		var objects []string
+		token := ""
+		for {
+			resp, err := paginatedlist.PaginatedListObjects(ctx, conn, &paginatedlist.PaginatedListObjectsRequest{
+				Request:           listObjectsRequest,
+				PageSize:          3,
+				ContinuationToken: token,
+			})
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(resp.Objects), 3)
+			objects = append(objects, resp.Objects...)
+
+			token = resp.ContinuationToken
+			if token == "" {
+				break
+			}
+		}
+		require.Equal(t, expected, objects)
+	})
+
+	t.Run("default_page_size_is_the_max_results", func(t *testing.T) {
+		resp, err := paginatedlist.PaginatedListObjects(ctx, conn, &paginatedlist.PaginatedListObjectsRequest{
+			Request: listObjectsRequest,
+		})
+		require.NoError(t, err)
+		require.Equal(t, expected[:4], resp.Objects)
+		require.NotEmpty(t, resp.ContinuationToken)
+	})
+
+	t.Run("object_id_filters", func(t *testing.T) {
+		resp, err := paginatedlist.PaginatedListObjects(ctx, conn, &paginatedlist.PaginatedListObjectsRequest{
+			Request:   listObjectsRequest,
+			ObjectIDs: []string{"07", "03", "42"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"doc:03", "doc:07"}, resp.Objects)
+		require.Empty(t, resp.ContinuationToken)
+
+		resp, err = paginatedlist.PaginatedListObjects(ctx, conn, &paginatedlist.PaginatedListObjectsRequest{
+			Request:        listObjectsRequest,
+			ObjectIDPrefix: "0",
+			ObjectIDs:      []string{"01", "02", "10"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"doc:01", "doc:02"}, resp.Objects)
+
+		// an empty list of candidates lists no object
+		resp, err = paginatedlist.PaginatedListObjects(ctx, conn, &paginatedlist.PaginatedListObjectsRequest{
+			Request:   listObjectsRequest,
+			ObjectIDs: []string{},
+		})
+		require.NoError(t, err)
+		require.Empty(t, resp.Objects)
+	})
+
+	t.Run("invalid_requests", func(t *testing.T) {
+		for _, req := range []*paginatedlist.PaginatedListObjectsRequest{
+			{},
+			{Request: &openfgav1.ListObjectsRequest{StoreId: storeID, Type: "doc", Relation: "viewer"}},
+			{Request: listObjectsRequest, ObjectIDs: []string{"01", "02", "03", "04"}},
+			{Request: listObjectsRequest, ObjectIDs: []string{"01", "doc:02"}},
+			{Request: listObjectsRequest, ObjectIDs: []string{"0 1"}},
+			{Request: listObjectsRequest, ObjectIDs: []string{"01#viewer"}},
+			{Request: listObjectsRequest, ObjectIDs: []string{""}},
+			{Request: listObjectsRequest, ObjectIDs: []string{strings.Repeat("0", 256)}},
+		} {
+			_, err := paginatedlist.PaginatedListObjects(ctx, conn, req)
+			require.Equal(t, codes.InvalidArgument, status.Code(err))
+		}
+
+		_, err := paginatedlist.PaginatedListObjects(ctx, conn, &paginatedlist.PaginatedListObjectsRequest{
+			Request:           listObjectsRequest,
+			ContinuationToken: "invalid",
+		})
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_invalid_continuation_token), status.Code(err))
+
+		_, err = paginatedlist.PaginatedListObjects(ctx, conn, &paginatedlist.PaginatedListObjectsRequest{
+			Request:  listObjectsRequest,
+			PageSize: 5,
+		})
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_validation_error), status.Code(err))
+	})
+}
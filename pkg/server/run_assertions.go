@@ -0,0 +1,163 @@
+package server
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/assertionrun"
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/internal/utils/apimethod"
+	"github.com/openfga/openfga/pkg/server/commands"
+	serverconfig "github.com/openfga/openfga/pkg/server/config"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/telemetry"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+var _ assertionrun.AssertionRunServer = (*Server)(nil)
+
+// RunAssertions runs the assertions stored for a model of a store against the same or another model, and returns
+// the outcome of each of them. It is authorized like a ReadAssertions.
+func (s *Server) RunAssertions(ctx context.Context, req *assertionrun.RunAssertionsRequest) (*assertionrun.RunAssertionsResponse, error) {
+	ctx, span := tracer.Start(ctx, "RunAssertions", trace.WithAttributes(
+		attribute.String("store_id", req.StoreID),
+		attribute.String("authorization_model_id", req.AuthorizationModelID),
+		attribute.String("assertions_authorization_model_id", req.AssertionsAuthorizationModelID),
+	))
+	defer span.End()
+
+	if req.StoreID == "" {
+		return nil, status.Error(codes.InvalidArgument, "store_id is required")
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: assertionrun.ServiceName,
+		Method:  "RunAssertions",
+	})
+
+	if err := s.checkAuthz(ctx, req.StoreID, apimethod.ReadAssertions); err != nil {
+		return nil, err
+	}
+
+	return s.runAssertions(ctx, req.StoreID, req.AuthorizationModelID, req.AssertionsAuthorizationModelID)
+}
+
+// runAssertions checks the assertions stored for the assertionsModelID model, or for the model they are run against
+// if empty, against the modelID model, or the active model if empty.
+func (s *Server) runAssertions(ctx context.Context, storeID, modelID, assertionsModelID string) (*assertionrun.RunAssertionsResponse, error) {
+	typesys, err := s.resolveTypesystem(ctx, storeID, modelID)
+	if err != nil {
+		return nil, err
+	}
+	modelID = typesys.GetAuthorizationModelID()
+
+	if assertionsModelID == "" {
+		assertionsModelID = modelID
+	} else if assertionsModelID != modelID {
+		if _, err := s.resolveTypesystem(ctx, storeID, assertionsModelID); err != nil {
+			return nil, err
+		}
+	}
+
+	assertions, err := s.datastore.ReadAssertions(ctx, storeID, assertionsModelID)
+	if err != nil {
+		return nil, serverErrors.HandleError("", err)
+	}
+
+	checkResolver, checkResolverCloser, err := s.getCheckResolverBuilder(storeID).Build()
+	if err != nil {
+		return nil, err
+	}
+	defer checkResolverCloser()
+
+	res := &assertionrun.RunAssertionsResponse{
+		AuthorizationModelID:           modelID,
+		AssertionsAuthorizationModelID: assertionsModelID,
+		Results:                        make([]assertionrun.AssertionResult, 0, len(assertions)),
+	}
+	for _, assertion := range assertions {
+		result, err := s.runAssertion(ctx, storeID, checkResolver, typesys, assertion)
+		if err != nil {
+			return nil, err
+		}
+
+		if result.Passed {
+			res.Passed++
+		} else {
+			res.Failed++
+		}
+		res.Results = append(res.Results, *result)
+	}
+
+	return res, nil
+}
+
+// runAssertion checks the assertion, with its contextual tuples and context, against typesys. The check is resolved
+// like the ones of a BatchCheck: it is not authorized, admitted or reported on its own. The errors of the check fail
+// the assertion, unless the request itself is canceled.
+func (s *Server) runAssertion(ctx context.Context, storeID string, checkResolver graph.CheckResolver, typesys *typesystem.TypeSystem, assertion *openfgav1.Assertion) (*assertionrun.AssertionResult, error) {
+	encoded, err := protojson.Marshal(assertion)
+	if err != nil {
+		return nil, serverErrors.NewInternalError("", err)
+	}
+
+	result := &assertionrun.AssertionResult{
+		Assertion:   encoded,
+		Expectation: assertion.GetExpectation(),
+	}
+
+	tk := assertion.GetTupleKey()
+	checkReq := &openfgav1.CheckRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: typesys.GetAuthorizationModelID(),
+		TupleKey:             &openfgav1.CheckRequestTupleKey{User: tk.GetUser(), Relation: tk.GetRelation(), Object: tk.GetObject()},
+		ContextualTuples:     &openfgav1.ContextualTupleKeys{TupleKeys: assertion.GetContextualTuples()},
+		Context:              assertion.GetContext(),
+	}
+
+	// the RunAssertions request is validated by the interceptors, but the checks it makes are not
+	if err := checkReq.Validate(); err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	checkQuery := commands.NewCheckCommand(
+		s.datastore,
+		checkResolver,
+		typesys,
+		commands.WithCheckCommandLogger(s.logger),
+		commands.WithCheckCommandMaxConcurrentReads(s.maxConcurrentReadsForCheck),
+		commands.WithCheckCommandConcurrencyLimiter(s.checkConcurrencyLimiter),
+		commands.WithCheckCommandCache(s.sharedDatastoreResources, s.cacheSettings),
+		commands.WithCheckDatastoreThrottler(
+			s.featureFlagClient.Boolean(serverconfig.ExperimentalDatastoreThrottling, storeID),
+			s.checkDatastoreThrottleThreshold,
+			s.checkDatastoreThrottleDuration,
+		),
+	)
+
+	checkRes, _, err := checkQuery.Execute(ctx, &commands.CheckCommandParams{
+		StoreID:          storeID,
+		TupleKey:         checkReq.GetTupleKey(),
+		ContextualTuples: checkReq.GetContextualTuples(),
+		Context:          checkReq.GetContext(),
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		result.Error = status.Convert(commands.CheckCommandErrorToServerError(err)).Message()
+		return result, nil
+	}
+
+	result.Allowed = checkRes.GetAllowed()
+	result.Passed = result.Allowed == result.Expectation
+	return result, nil
+}
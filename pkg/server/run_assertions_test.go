@@ -0,0 +1,224 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/activemodel"
+	"github.com/openfga/openfga/internal/assertionrun"
+	"github.com/openfga/openfga/internal/jsongrpc"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestRunAssertions(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(WithDatastore(ds))
+	t.Cleanup(s.Close)
+
+	conn := newJSONGRPCTestConn(t, s, &assertionrun.ServiceDesc, &activemodel.ServiceDesc)
+
+	ctx := context.Background()
+	createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "openfga-test"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	writeModel := func(t *testing.T, dsl string) string {
+		model := testutils.MustTransformDSLToProtoWithID(dsl)
+		resp, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+			StoreId:         storeID,
+			SchemaVersion:   model.GetSchemaVersion(),
+			TypeDefinitions: model.GetTypeDefinitions(),
+			Conditions:      model.GetConditions(),
+		})
+		require.NoError(t, err)
+		return resp.GetAuthorizationModelId()
+	}
+
+	firstModelID := writeModel(t, `
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define viewer: [user, user with weekday]
+
+		condition weekday(day: string) {
+			day != "sunday"
+		}`)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		}},
+	})
+	require.NoError(t, err)
+
+	monday, err := structpb.NewStruct(map[string]any{"day": "monday"})
+	require.NoError(t, err)
+
+	_, err = s.WriteAssertions(ctx, &openfgav1.WriteAssertionsRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: firstModelID,
+		Assertions: []*openfgav1.Assertion{
+			{
+				TupleKey:    tuple.NewAssertionTupleKey("document:1", "viewer", "user:anne"),
+				Expectation: true,
+			},
+			{
+				TupleKey:    tuple.NewAssertionTupleKey("document:2", "viewer", "user:anne"),
+				Expectation: false,
+			},
+			{
+				TupleKey:    tuple.NewAssertionTupleKey("document:1", "viewer", "user:bob"),
+				Expectation: true,
+				ContextualTuples: []*openfgav1.TupleKey{
+					tuple.NewTupleKeyWithCondition("document:1", "viewer", "user:bob", "weekday", nil),
+				},
+				Context: monday,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	// the viewers are no longer directly assignable in the second model
+	secondModelID := writeModel(t, `
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define editor: [user]
+				define viewer: editor`)
+
+	t.Run("assertions_pass_against_their_model", func(t *testing.T) {
+		resp, err := assertionrun.RunAssertions(ctx, conn, &assertionrun.RunAssertionsRequest{
+			StoreRequest:         jsongrpc.StoreRequest{StoreID: storeID},
+			AuthorizationModelID: firstModelID,
+		})
+		require.NoError(t, err)
+		require.Equal(t, firstModelID, resp.AuthorizationModelID)
+		require.Equal(t, firstModelID, resp.AssertionsAuthorizationModelID)
+		require.Equal(t, 3, resp.Passed)
+		require.Zero(t, resp.Failed)
+		require.Len(t, resp.Results, 3)
+		for _, result := range resp.Results {
+			require.True(t, result.Passed)
+			require.Equal(t, result.Expectation, result.Allowed)
+			require.Empty(t, result.Error)
+		}
+	})
+
+	t.Run("assertions_fail_against_another_model", func(t *testing.T) {
+		// the active model is the latest one
+		resp, err := assertionrun.RunAssertions(ctx, conn, &assertionrun.RunAssertionsRequest{
+			StoreRequest:                   jsongrpc.StoreRequest{StoreID: storeID},
+			AssertionsAuthorizationModelID: firstModelID,
+		})
+		require.NoError(t, err)
+		require.Equal(t, secondModelID, resp.AuthorizationModelID)
+		require.Equal(t, firstModelID, resp.AssertionsAuthorizationModelID)
+		require.Equal(t, 1, resp.Passed)
+		require.Equal(t, 2, resp.Failed)
+		require.Len(t, resp.Results, 3)
+
+		require.False(t, resp.Results[0].Passed)
+		require.False(t, resp.Results[0].Allowed)
+		require.Empty(t, resp.Results[0].Error)
+
+		require.True(t, resp.Results[1].Passed)
+
+		// the contextual tuple is not valid for the second model
+		require.False(t, resp.Results[2].Passed)
+		require.NotEmpty(t, resp.Results[2].Error)
+		require.Contains(t, string(resp.Results[2].Assertion), "user:bob")
+	})
+
+	t.Run("model_without_assertions", func(t *testing.T) {
+		resp, err := assertionrun.RunAssertions(ctx, conn, &assertionrun.RunAssertionsRequest{
+			StoreRequest:         jsongrpc.StoreRequest{StoreID: storeID},
+			AuthorizationModelID: secondModelID,
+		})
+		require.NoError(t, err)
+		require.Zero(t, resp.Passed)
+		require.Zero(t, resp.Failed)
+		require.Empty(t, resp.Results)
+	})
+
+	t.Run("promotion_requires_passing_assertions", func(t *testing.T) {
+		// a model without assertions is not promoted
+		_, err := activemodel.SetActiveAuthorizationModel(ctx, conn, &activemodel.SetActiveAuthorizationModelRequest{
+			StoreRequest:             jsongrpc.StoreRequest{StoreID: storeID},
+			AuthorizationModelID:     secondModelID,
+			RequirePassingAssertions: true,
+		})
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+		_, err = s.WriteAssertions(ctx, &openfgav1.WriteAssertionsRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: secondModelID,
+			Assertions: []*openfgav1.Assertion{
+				{
+					TupleKey:    tuple.NewAssertionTupleKey("document:1", "viewer", "user:anne"),
+					Expectation: true,
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		_, err = activemodel.SetActiveAuthorizationModel(ctx, conn, &activemodel.SetActiveAuthorizationModelRequest{
+			StoreRequest:             jsongrpc.StoreRequest{StoreID: storeID},
+			AuthorizationModelID:     secondModelID,
+			RequirePassingAssertions: true,
+		})
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+		active, err := activemodel.GetActiveAuthorizationModel(ctx, conn, &activemodel.GetActiveAuthorizationModelRequest{StoreRequest: jsongrpc.StoreRequest{StoreID: storeID}})
+		require.NoError(t, err)
+		require.False(t, active.Pinned)
+
+		_, err = activemodel.SetActiveAuthorizationModel(ctx, conn, &activemodel.SetActiveAuthorizationModelRequest{
+			StoreRequest:             jsongrpc.StoreRequest{StoreID: storeID},
+			AuthorizationModelID:     firstModelID,
+			RequirePassingAssertions: true,
+		})
+		require.NoError(t, err)
+
+		active, err = activemodel.GetActiveAuthorizationModel(ctx, conn, &activemodel.GetActiveAuthorizationModelRequest{StoreRequest: jsongrpc.StoreRequest{StoreID: storeID}})
+		require.NoError(t, err)
+		require.Equal(t, &activemodel.GetActiveAuthorizationModelResponse{AuthorizationModelID: firstModelID, Pinned: true}, active)
+	})
+
+	t.Run("invalid_requests", func(t *testing.T) {
+		_, err := assertionrun.RunAssertions(ctx, conn, &assertionrun.RunAssertionsRequest{})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = assertionrun.RunAssertions(ctx, conn, &assertionrun.RunAssertionsRequest{
+			StoreRequest:         jsongrpc.StoreRequest{StoreID: storeID},
+			AuthorizationModelID: "01JBVMPYB8Q4G2NCC8Z2RMMA5A",
+		})
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_authorization_model_not_found), status.Code(err))
+
+		_, err = assertionrun.RunAssertions(ctx, conn, &assertionrun.RunAssertionsRequest{
+			StoreRequest:                   jsongrpc.StoreRequest{StoreID: storeID},
+			AssertionsAuthorizationModelID: "01JBVMPYB8Q4G2NCC8Z2RMMA5A",
+		})
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_authorization_model_not_found), status.Code(err))
+	})
+}
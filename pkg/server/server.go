@@ -22,7 +22,10 @@ import (
 
 	"github.com/openfga/openfga/internal/authz"
 	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/internal/candidatemodel"
+	"github.com/openfga/openfga/internal/checkcost"
 	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/internal/permissionindex"
 	"github.com/openfga/openfga/internal/planner"
 	"github.com/openfga/openfga/internal/shared"
 	"github.com/openfga/openfga/internal/throttler"
@@ -45,6 +48,11 @@ const (
 	AuthorizationModelIDHeader = "Openfga-Authorization-Model-Id"
 	authorizationModelIDKey    = "authorization_model_id"
 
+	// CheckEstimatedReadsHeader and CheckEstimatedDispatchesHeader are set on the Check responses with the estimated
+	// cost of the check, when checks are admitted by their cost (see WithCheckCostAdmissionEnabled).
+	CheckEstimatedReadsHeader      = "Openfga-Check-Estimated-Reads"
+	CheckEstimatedDispatchesHeader = "Openfga-Check-Estimated-Dispatches"
+
 	allowedLabel = "allowed"
 
 	throttleTypeDatastore = "datastore"
@@ -110,6 +118,12 @@ var (
 
 	listObjectsCheckCountName = "check_count"
 
+	dispatchesSavedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "dispatches_saved_count",
+		Help:      "The total number of dispatches saved by sharing the resolution of sub-problems between the checks of a request (e.g. BatchCheck).",
+	}, []string{"grpc_service", "grpc_method"})
+
 	throttledRequestCounter = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: build.ProjectName,
 		Name:      "throttled_requests_count",
@@ -173,8 +187,11 @@ type Server struct {
 	changelogHorizonOffset           int
 	listObjectsDeadline              time.Duration
 	listObjectsMaxResults            uint32
+	listObjectsMaxObjectsInMemory    uint32
+	listObjectsMaxCandidateObjectIDs uint32
 	listUsersDeadline                time.Duration
 	listUsersMaxResults              uint32
+	listUsersMaxUsersInMemory        uint32
 	maxChecksPerBatchCheck           uint32
 	maxConcurrentChecksPerBatch      uint32
 	maxConcurrentReadsForListObjects uint32
@@ -182,15 +199,18 @@ type Server struct {
 	maxConcurrentReadsForListUsers   uint32
 	maxAuthorizationModelCacheSize   int
 	maxAuthorizationModelSizeInBytes int
-	experimentals                    []string
-	AccessControl                    serverconfig.AccessControlConfig
-	AuthnMethod                      string
-	serviceName                      string
-	featureFlagClient                featureflags.Client
+
+	authorizationModelOrphanedTuplesCheckEnabled   bool
+	authorizationModelOrphanedTuplesCheckThreshold uint32
+	experimentals                                  []string
+	AccessControl                                  serverconfig.AccessControlConfig
+	AuthnMethod                                    string
+	serviceName                                    string
+	featureFlagClient                              featureflags.Client
 
 	// NOTE don't use this directly, use function resolveTypesystem. See https://github.com/openfga/openfga/issues/1527
-	typesystemResolver     typesystem.TypesystemResolverFunc
-	typesystemResolverStop func()
+	typesystemResolver typesystem.TypesystemResolverFunc
+	memoizedTypesystem *typesystem.MemoizedTypesystemResolver
 
 	// cacheSettings are given by the user
 	cacheSettings serverconfig.CacheSettings
@@ -202,6 +222,15 @@ type Server struct {
 	shadowListObjectsQueryTimeout       time.Duration
 	shadowListObjectsQueryMaxDeltaItems int
 
+	shadowListUsersQueryTimeout          time.Duration
+	shadowListUsersQueryMaxDeltaItems    int
+	shadowListUsersQuerySamplePercentage int
+
+	// candidateModels holds the candidate model of each store, see SetCandidateAuthorizationModel
+	candidateModels                *candidatemodel.Recorder
+	candidateModelMaxDivergences   int
+	candidateModelSamplePercentage int
+
 	requestDurationByQueryHistogramBuckets         []uint
 	requestDurationByDispatchCountHistogramBuckets []uint
 
@@ -223,6 +252,16 @@ type Server struct {
 	listObjectsDispatchThrottler throttler.Throttler
 	listUsersDispatchThrottler   throttler.Throttler
 
+	fairDispatchThrottlingEnabled      bool
+	fairDispatchThrottlingPerClient    bool
+	fairDispatchThrottlingStoreWeights map[string]float64
+	// fairCheckDispatchThrottlingFrequency is the frequency of checkDispatchThrottler, which is shared by all the
+	// Check requests, unlike checkDispatchThrottlingFrequency which applies to each request
+	fairCheckDispatchThrottlingFrequency time.Duration
+	// checkDispatchThrottler is the throttler shared by the Check requests when fair dispatch throttling is enabled,
+	// otherwise each request has its own
+	checkDispatchThrottler throttler.Throttler
+
 	checkDatastoreThrottleThreshold       int
 	checkDatastoreThrottleDuration        time.Duration
 	listObjectsDatastoreThrottleThreshold int
@@ -240,7 +279,54 @@ type Server struct {
 
 	planner *planner.Planner
 
+	// listStrategiesTrust is set when the planner selects the ListObjects and ListUsers strategies. It holds the
+	// strategies that were validated against the baseline strategy.
+	listStrategiesPlannerEnabled      bool
+	listStrategiesRequiredValidations uint32
+	listStrategiesShadowTimeout       time.Duration
+	listStrategiesTrust               *planner.Trust
+
 	requestTimeout time.Duration
+
+	// peerDispatcher is set when Check sub-problems are sharded across a cluster of peers.
+	peerDispatcher graph.PeerDispatcher
+
+	checkPermissionIndexEnabled         bool
+	checkPermissionIndexRefreshInterval time.Duration
+	checkPermissionIndexMaxStaleness    time.Duration
+	checkPermissionIndexValkeyURI       string
+	checkPermissionIndexValkeyKeyPrefix string
+	permissionIndexStore                permissionindex.Store
+	permissionIndex                     *permissionindex.Index
+
+	// inflightChecks holds the Check sub-problems being resolved, if they are coalesced (see WithCheckSingleflightEnabled).
+	inflightChecks *graph.InflightChecks
+
+	edgeSyncEnabled      bool
+	edgeSyncPollInterval time.Duration
+	edgeSyncCursorTTL    time.Duration
+	// edgeSyncResults holds the materialized results of recent edge sync cursors, so that subscribers can resume from them.
+	edgeSyncResults storage.InMemoryCache[edgeSyncResults]
+
+	checkCostAdmissionEnabled                bool
+	checkCostAdmissionAction                 string
+	checkCostBudget                          checkcost.Budget
+	checkCostStoreBudgets                    map[string]checkcost.Budget
+	checkCostAdmissionDeprioritizedFrequency time.Duration
+	checkCostStatisticsTTL                   time.Duration
+	// checkCostAdmission admits checks by their estimated cost, if it is enabled.
+	checkCostAdmission  *checkcost.Admission
+	checkCostStatistics *checkcost.SampledStatistics
+
+	adaptiveConcurrencyEnabled          bool
+	adaptiveConcurrencyInitialLimit     uint32
+	adaptiveConcurrencyMinLimit         uint32
+	adaptiveConcurrencyLatencyThreshold time.Duration
+	adaptiveConcurrencyBackoffRatio     float64
+	// the adaptive limiters of the concurrent datastore reads of each method, if they are enabled
+	checkConcurrencyLimiter       *storagewrappers.AdaptiveConcurrencyLimiter
+	listObjectsConcurrencyLimiter *storagewrappers.AdaptiveConcurrencyLimiter
+	listUsersConcurrencyLimiter   *storagewrappers.AdaptiveConcurrencyLimiter
 }
 
 type OpenFGAServiceV1Option func(s *Server)
@@ -339,6 +425,24 @@ func WithListObjectsMaxResults(limit uint32) OpenFGAServiceV1Option {
 	}
 }
 
+// WithListObjectsMaxObjectsInMemory affects the exact ListObjects count only.
+// It sets the maximum number of objects kept in memory to count each object once, the objects
+// beyond it being spilled to temporary files. If it's zero, every object is kept in memory.
+func WithListObjectsMaxObjectsInMemory(objects uint32) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.listObjectsMaxObjectsInMemory = objects
+	}
+}
+
+// WithListObjectsMaxCandidateObjectIDs affects the paginated ListObjects only.
+// It sets the maximum number of candidate object IDs a request can restrict its results to. If it's zero, there is
+// no maximum.
+func WithListObjectsMaxCandidateObjectIDs(objectIDs uint32) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.listObjectsMaxCandidateObjectIDs = objectIDs
+	}
+}
+
 // WithListUsersDeadline affect the ListUsers API only.
 // It sets the maximum amount of time that the server will spend gathering results.
 func WithListUsersDeadline(deadline time.Duration) OpenFGAServiceV1Option {
@@ -356,6 +460,15 @@ func WithListUsersMaxResults(limit uint32) OpenFGAServiceV1Option {
 	}
 }
 
+// WithListUsersMaxUsersInMemory affects the streaming ListUsers API and the exact ListUsers count only.
+// It sets the maximum number of users kept in memory to deduplicate the users sent or counted, the users
+// beyond it being spilled to temporary files. If it's zero, every user is kept in memory.
+func WithListUsersMaxUsersInMemory(users uint32) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.listUsersMaxUsersInMemory = users
+	}
+}
+
 // WithMaxConcurrentReadsForListObjects sets a limit on the number of datastore reads that can be in flight for a given ListObjects call.
 // This number should be set depending on the RPS expected for Check and ListObjects APIs, the number of OpenFGA replicas running,
 // and the number of connections the datastore allows.
@@ -440,6 +553,38 @@ func WithCheckCacheLimit(limit uint32) OpenFGAServiceV1Option {
 	}
 }
 
+// WithCheckCacheValkeyEnabled stores the check cache (see WithCheckCacheLimit) in Valkey, so that cached Check
+// sub-problems and iterators, as well as the invalidation timestamps of the cache controller, are shared by every
+// replica using the same Valkey instance. See also WithCheckCacheValkeyURI.
+func WithCheckCacheValkeyEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.cacheSettings.CheckCacheValkeyEnabled = enabled
+	}
+}
+
+// WithCheckCacheValkeyURI sets the connection URI of the Valkey instance of the check cache, e.g. redis://localhost:6379/0.
+func WithCheckCacheValkeyURI(uri string) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.cacheSettings.CheckCacheValkeyURI = uri
+	}
+}
+
+// WithCheckCacheValkeyKeyPrefix sets the prefix of every key of the check cache stored in Valkey.
+func WithCheckCacheValkeyKeyPrefix(prefix string) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.cacheSettings.CheckCacheValkeyKeyPrefix = prefix
+	}
+}
+
+// WithCheckCacheValkeyLocalTier keeps a local LRU cache (of the check cache limit) in front of Valkey, in which values
+// are kept for at most ttl. Cache invalidation timestamps are never kept in the local tier.
+func WithCheckCacheValkeyLocalTier(enabled bool, ttl time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.cacheSettings.CheckCacheValkeyLocalTierEnabled = enabled
+		s.cacheSettings.CheckCacheValkeyLocalTierTTL = ttl
+	}
+}
+
 // WithCacheControllerEnabled enables cache invalidation of different cache entities.
 func WithCacheControllerEnabled(enabled bool) OpenFGAServiceV1Option {
 	return func(s *Server) {
@@ -508,6 +653,23 @@ func WithListObjectsIteratorCacheTTL(ttl time.Duration) OpenFGAServiceV1Option {
 	}
 }
 
+// WithListObjectsQueryCacheEnabled enables caching of ListObjects results. The cached results are invalidated by
+// the cache controller when the tuples they depend on change, so it should be enabled too.
+// See also WithCheckCacheLimit, WithListObjectsQueryCacheTTL and WithCacheControllerEnabled.
+func WithListObjectsQueryCacheEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.cacheSettings.ListObjectsQueryCacheEnabled = enabled
+	}
+}
+
+// WithListObjectsQueryCacheTTL sets the TTL of cached ListObjects results.
+// Needs WithListObjectsQueryCacheEnabled set to true.
+func WithListObjectsQueryCacheTTL(ttl time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.cacheSettings.ListObjectsQueryCacheTTL = ttl
+	}
+}
+
 // WithRequestDurationByQueryHistogramBuckets sets the buckets used in labelling the requestDurationByQueryAndDispatchHistogram.
 func WithRequestDurationByQueryHistogramBuckets(buckets []uint) OpenFGAServiceV1Option {
 	return func(s *Server) {
@@ -530,6 +692,22 @@ func WithMaxAuthorizationModelSizeInBytes(size int) OpenFGAServiceV1Option {
 	}
 }
 
+// WithAuthorizationModelOrphanedTuplesCheckEnabled enables the rejection of the authorization models that would
+// invalidate more than the threshold of existing tuples. See WithAuthorizationModelOrphanedTuplesCheckThreshold.
+func WithAuthorizationModelOrphanedTuplesCheckEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.authorizationModelOrphanedTuplesCheckEnabled = enabled
+	}
+}
+
+// WithAuthorizationModelOrphanedTuplesCheckThreshold sets the maximum number of existing tuples that a new
+// authorization model may invalidate. Needs WithAuthorizationModelOrphanedTuplesCheckEnabled set to true.
+func WithAuthorizationModelOrphanedTuplesCheckThreshold(threshold uint32) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.authorizationModelOrphanedTuplesCheckThreshold = threshold
+	}
+}
+
 // WithDispatchThrottlingCheckResolverEnabled sets whether dispatch throttling is enabled for Check requests.
 // Enabling this feature will prioritize dispatched requests requiring less than the configured dispatch
 // threshold over requests whose dispatch count exceeds the configured threshold.
@@ -585,12 +763,198 @@ func WithPlanner(planner *planner.Planner) OpenFGAServiceV1Option {
 	}
 }
 
+// WithListStrategiesPlanner lets the planner select the strategy that resolves ListObjects and ListUsers requests.
+// The strategy selected by the other options and the feature flags is always trusted. Another strategy is trusted
+// once its results matched the ones of that strategy requiredValidations times, when run in shadow for at most
+// shadowTimeout, and rejected as soon as they don't.
+func WithListStrategiesPlanner(enabled bool, requiredValidations uint32, shadowTimeout time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.listStrategiesPlannerEnabled = enabled
+		s.listStrategiesRequiredValidations = requiredValidations
+		s.listStrategiesShadowTimeout = shadowTimeout
+	}
+}
+
 func WithRequestTimeout(timeout time.Duration) OpenFGAServiceV1Option {
 	return func(s *Server) {
 		s.requestTimeout = timeout
 	}
 }
 
+// WithPeerDispatcher shards Check sub-problems across the peers of a cluster using the provided dispatcher.
+// Each sub-problem is resolved (and cached) by the peer that owns it. Peers must also serve the
+// [peer.ServiceDesc] service, which is implemented by Server.DispatchCheck.
+// The dispatcher is not closed by the Server.
+func WithPeerDispatcher(dispatcher graph.PeerDispatcher) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.peerDispatcher = dispatcher
+	}
+}
+
+// WithCheckSingleflightEnabled enables coalescing of identical concurrent Check sub-problems, across every Check,
+// BatchCheck and ListObjects request, so that they share a single resolution. See [graph.SingleflightCheckResolver].
+func WithCheckSingleflightEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		if enabled {
+			s.inflightChecks = graph.NewInflightChecks()
+		} else {
+			s.inflightChecks = nil
+		}
+	}
+}
+
+// WithEdgeSyncEnabled enables the edge sync service (see [edgesync.ServiceDesc]), through which edge sidecars
+// subscribe to materialized Check results. The service must also be registered on the gRPC server.
+func WithEdgeSyncEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.edgeSyncEnabled = enabled
+	}
+}
+
+// WithEdgeSyncPollInterval sets how often the changelog of each subscribed store is read to stream deltas.
+func WithEdgeSyncPollInterval(interval time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.edgeSyncPollInterval = interval
+	}
+}
+
+// WithEdgeSyncCursorTTL sets how long a subscriber can resume from a cursor with deltas only. Subscribers
+// resuming from an older cursor are sent a new snapshot.
+func WithEdgeSyncCursorTTL(ttl time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.edgeSyncCursorTTL = ttl
+	}
+}
+
+// WithDatastoreAdaptiveConcurrency adapts the number of concurrent datastore reads of each Check, ListObjects and
+// ListUsers request to the latency and errors of the datastore (see [storagewrappers.AdaptiveConcurrencyLimiter]),
+// instead of bounding it with a static limit. The limits start at initialLimit, never go below minLimit nor above
+// the maximum concurrent reads of each method, and are lowered by backoffRatio when reads are slower than
+// latencyThreshold.
+func WithDatastoreAdaptiveConcurrency(enabled bool, initialLimit, minLimit uint32, latencyThreshold time.Duration, backoffRatio float64) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.adaptiveConcurrencyEnabled = enabled
+		s.adaptiveConcurrencyInitialLimit = initialLimit
+		s.adaptiveConcurrencyMinLimit = minLimit
+		s.adaptiveConcurrencyLatencyThreshold = latencyThreshold
+		s.adaptiveConcurrencyBackoffRatio = backoffRatio
+	}
+}
+
+// WithFairDispatchThrottling schedules the throttled dispatches of Check, ListObjects and ListUsers fairly across
+// stores, with weighted fair queuing (see [throttler.FairThrottler]), instead of in the order they are throttled,
+// so that the dispatches of a noisy store are throttled first. Each store gets a share of the throttled dispatches
+// proportional to its weight in storeWeights, or 1. If perClient is true, the dispatches are also scheduled fairly
+// across the clients of each store. It applies to the dispatch throttling of the methods that have it enabled.
+func WithFairDispatchThrottling(enabled, perClient bool, storeWeights map[string]float64) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.fairDispatchThrottlingEnabled = enabled
+		s.fairDispatchThrottlingPerClient = perClient
+		s.fairDispatchThrottlingStoreWeights = storeWeights
+	}
+}
+
+// WithFairCheckDispatchThrottlingFrequency sets the frequency at which the throttled dispatches of Check are
+// released when fair dispatch throttling is enabled. The fair throttler of Check is shared by all the Check requests
+// of the server, whereas without fair scheduling each request has its own throttler at the frequency of
+// WithDispatchThrottlingCheckResolverFrequency, so this frequency bounds the throttled dispatches of the whole server.
+func WithFairCheckDispatchThrottlingFrequency(frequency time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.fairCheckDispatchThrottlingFrequency = frequency
+	}
+}
+
+// WithCheckCostAdmissionEnabled enables the admission of Check requests by their cost, estimated from the weighted
+// graph of the model and the sampled cardinality of the relations it traverses. The checks estimated above the
+// budget of their store are rejected or deprioritized (see WithCheckCostAdmissionAction).
+func WithCheckCostAdmissionEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.checkCostAdmissionEnabled = enabled
+	}
+}
+
+// WithCheckCostAdmissionAction sets what happens to the checks estimated above their budget: they are either
+// rejected ('reject') or let through at a constant rate ('deprioritize').
+func WithCheckCostAdmissionAction(action string) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.checkCostAdmissionAction = action
+	}
+}
+
+// WithCheckCostBudget sets the maximum estimated reads and dispatches of the checks of the stores without a budget
+// of their own. Zero is unlimited.
+func WithCheckCostBudget(maxReads, maxDispatches uint32) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.checkCostBudget = checkcost.Budget{MaxReads: maxReads, MaxDispatches: maxDispatches}
+	}
+}
+
+// WithCheckCostStoreBudgets sets the maximum estimated reads and dispatches of the checks of specific stores, by
+// store ID. Zero is unlimited.
+func WithCheckCostStoreBudgets(budgets map[string]serverconfig.CheckCostBudget) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.checkCostStoreBudgets = make(map[string]checkcost.Budget, len(budgets))
+		for storeID, budget := range budgets {
+			s.checkCostStoreBudgets[storeID] = checkcost.Budget{MaxReads: budget.MaxReads, MaxDispatches: budget.MaxDispatches}
+		}
+	}
+}
+
+// WithCheckCostAdmissionDeprioritizedFrequency sets how often a deprioritized check is let through.
+func WithCheckCostAdmissionDeprioritizedFrequency(frequency time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.checkCostAdmissionDeprioritizedFrequency = frequency
+	}
+}
+
+// WithCheckCostStatisticsTTL sets how long the sampled cardinality of a relation is used before it is sampled again.
+func WithCheckCostStatisticsTTL(ttl time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.checkCostStatisticsTTL = ttl
+	}
+}
+
+// WithCheckPermissionIndexEnabled enables a precomputed index of nested userset membership that Check consults
+// for eligible recursive relations (e.g. `define member: [user, group#member]`) instead of traversing them.
+// The index is maintained in the background from the changelog of each store that is checked.
+func WithCheckPermissionIndexEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.checkPermissionIndexEnabled = enabled
+	}
+}
+
+// WithCheckPermissionIndexRefreshInterval sets how often the permission index is brought up to date
+// with the changelog of each store.
+func WithCheckPermissionIndexRefreshInterval(interval time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.checkPermissionIndexRefreshInterval = interval
+	}
+}
+
+// WithCheckPermissionIndexMaxStaleness sets how old the permission index of a store may be for Check to use it.
+// Checks on staler stores are resolved through the graph.
+func WithCheckPermissionIndexMaxStaleness(staleness time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.checkPermissionIndexMaxStaleness = staleness
+	}
+}
+
+// WithCheckPermissionIndexValkeyURI stores the permission index in the Valkey instance at uri, e.g.
+// redis://localhost:6379/0, so that it survives restarts and is shared by the replicas. When it is not set
+// the index is held in the memory of each replica.
+func WithCheckPermissionIndexValkeyURI(uri string) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.checkPermissionIndexValkeyURI = uri
+	}
+}
+
+// WithCheckPermissionIndexValkeyKeyPrefix sets the prefix of every key of the permission index stored in Valkey.
+func WithCheckPermissionIndexValkeyKeyPrefix(prefix string) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.checkPermissionIndexValkeyKeyPrefix = prefix
+	}
+}
+
 // MustNewServerWithOpts see NewServerWithOpts.
 func MustNewServerWithOpts(opts ...OpenFGAServiceV1Option) *Server {
 	s, err := NewServerWithOpts(opts...)
@@ -740,6 +1104,45 @@ func WithShadowListObjectsQueryMaxDeltaItems(maxDeltaItems int) OpenFGAServiceV1
 	}
 }
 
+// WithShadowListUsersQueryTimeout is the amount of time to wait for the shadow ListUsers evaluation response.
+func WithShadowListUsersQueryTimeout(threshold time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.shadowListUsersQueryTimeout = threshold
+	}
+}
+
+// WithShadowListUsersQueryMaxDeltaItems is the maximum number of users logged in the difference between the
+// results of a ListUsers request and the ones of its shadow evaluation.
+func WithShadowListUsersQueryMaxDeltaItems(maxDeltaItems int) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.shadowListUsersQueryMaxDeltaItems = maxDeltaItems
+	}
+}
+
+// WithShadowListUsersQuerySamplePercentage is the percentage, between 0 and 100, of the ListUsers requests of the
+// stores with the shadow_list_users experimental flag that are also evaluated in shadow.
+func WithShadowListUsersQuerySamplePercentage(percentage int) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.shadowListUsersQuerySamplePercentage = percentage
+	}
+}
+
+// WithCandidateModelMaxDivergences is the maximum number of divergences kept in the report of the candidate model
+// of each store. The most recent ones are kept.
+func WithCandidateModelMaxDivergences(maxDivergences int) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.candidateModelMaxDivergences = maxDivergences
+	}
+}
+
+// WithCandidateModelSamplePercentage is the percentage, between 0 and 100, of the Check and ListObjects requests of
+// a store that are also evaluated against its candidate model when SetCandidateAuthorizationModel does not set one.
+func WithCandidateModelSamplePercentage(percentage int) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.candidateModelSamplePercentage = percentage
+	}
+}
+
 // WithSharedIteratorEnabled enables iterator to be shared across different consumer.
 func WithSharedIteratorEnabled(enabled bool) OpenFGAServiceV1Option {
 	return func(s *Server) {
@@ -773,8 +1176,11 @@ func NewServerWithOpts(opts ...OpenFGAServiceV1Option) (*Server, error) {
 		resolveNodeBreadthLimit:          serverconfig.DefaultResolveNodeBreadthLimit,
 		listObjectsDeadline:              serverconfig.DefaultListObjectsDeadline,
 		listObjectsMaxResults:            serverconfig.DefaultListObjectsMaxResults,
+		listObjectsMaxObjectsInMemory:    serverconfig.DefaultListObjectsMaxObjectsInMemory,
+		listObjectsMaxCandidateObjectIDs: serverconfig.DefaultListObjectsMaxCandidateObjectIDs,
 		listUsersDeadline:                serverconfig.DefaultListUsersDeadline,
 		listUsersMaxResults:              serverconfig.DefaultListUsersMaxResults,
+		listUsersMaxUsersInMemory:        serverconfig.DefaultListUsersMaxUsersInMemory,
 		maxChecksPerBatchCheck:           serverconfig.DefaultMaxChecksPerBatchCheck,
 		maxConcurrentChecksPerBatch:      serverconfig.DefaultMaxConcurrentChecksPerBatchCheck,
 		maxConcurrentReadsForCheck:       serverconfig.DefaultMaxConcurrentReadsForCheck,
@@ -792,6 +1198,13 @@ func NewServerWithOpts(opts ...OpenFGAServiceV1Option) (*Server, error) {
 		shadowListObjectsQueryTimeout:       serverconfig.DefaultShadowListObjectsQueryTimeout,
 		shadowListObjectsQueryMaxDeltaItems: serverconfig.DefaultShadowListObjectsQueryMaxDeltaItems,
 
+		shadowListUsersQueryTimeout:          serverconfig.DefaultShadowListUsersQueryTimeout,
+		shadowListUsersQueryMaxDeltaItems:    serverconfig.DefaultShadowListUsersQueryMaxDeltaItems,
+		shadowListUsersQuerySamplePercentage: serverconfig.DefaultShadowListUsersQuerySamplePercentage,
+
+		candidateModelMaxDivergences:   serverconfig.DefaultCandidateModelMaxDivergences,
+		candidateModelSamplePercentage: serverconfig.DefaultCandidateModelSamplePercentage,
+
 		requestDurationByQueryHistogramBuckets:         []uint{50, 200},
 		requestDurationByDispatchCountHistogramBuckets: []uint{50, 200},
 		serviceName: openfgav1.OpenFGAService_ServiceDesc.ServiceName,
@@ -810,6 +1223,10 @@ func NewServerWithOpts(opts ...OpenFGAServiceV1Option) (*Server, error) {
 		listUsersDispatchDefaultThreshold:       serverconfig.DefaultListUsersDispatchThrottlingDefaultThreshold,
 		listUsersDispatchThrottlingMaxThreshold: serverconfig.DefaultListUsersDispatchThrottlingMaxThreshold,
 
+		fairDispatchThrottlingEnabled:        serverconfig.DefaultFairDispatchThrottlingEnabled,
+		fairDispatchThrottlingPerClient:      serverconfig.DefaultFairDispatchThrottlingPerClient,
+		fairCheckDispatchThrottlingFrequency: serverconfig.DefaultFairDispatchThrottlingCheckFrequency,
+
 		tokenSerializer:   encoder.NewStringContinuationTokenSerializer(),
 		singleflightGroup: &singleflight.Group{},
 		authorizer:        authz.NewAuthorizerNoop(),
@@ -818,6 +1235,34 @@ func NewServerWithOpts(opts ...OpenFGAServiceV1Option) (*Server, error) {
 			CleanupInterval:   serverconfig.DefaultPlannerCleanupInterval,
 		}),
 		requestTimeout: serverconfig.DefaultRequestTimeout,
+
+		listStrategiesPlannerEnabled:      serverconfig.DefaultPlannerListStrategiesEnabled,
+		listStrategiesRequiredValidations: serverconfig.DefaultPlannerListStrategiesRequiredValidations,
+		listStrategiesShadowTimeout:       serverconfig.DefaultPlannerListStrategiesShadowTimeout,
+
+		checkPermissionIndexEnabled:         serverconfig.DefaultCheckPermissionIndexEnabled,
+		checkPermissionIndexRefreshInterval: serverconfig.DefaultCheckPermissionIndexRefreshInterval,
+		checkPermissionIndexMaxStaleness:    serverconfig.DefaultCheckPermissionIndexMaxStaleness,
+		checkPermissionIndexValkeyKeyPrefix: serverconfig.DefaultCheckPermissionIndexValkeyKeyPrefix,
+
+		edgeSyncEnabled:      serverconfig.DefaultEdgeSyncEnabled,
+		edgeSyncPollInterval: serverconfig.DefaultEdgeSyncPollInterval,
+		edgeSyncCursorTTL:    serverconfig.DefaultEdgeSyncCursorTTL,
+
+		checkCostAdmissionEnabled: serverconfig.DefaultCheckCostAdmissionEnabled,
+		checkCostAdmissionAction:  serverconfig.DefaultCheckCostAdmissionAction,
+		checkCostBudget: checkcost.Budget{
+			MaxReads:      serverconfig.DefaultCheckCostAdmissionMaxReads,
+			MaxDispatches: serverconfig.DefaultCheckCostAdmissionMaxDispatches,
+		},
+		checkCostAdmissionDeprioritizedFrequency: serverconfig.DefaultCheckCostAdmissionDeprioritizedFrequency,
+		checkCostStatisticsTTL:                   serverconfig.DefaultCheckCostAdmissionStatisticsTTL,
+
+		adaptiveConcurrencyEnabled:          serverconfig.DefaultDatastoreAdaptiveConcurrencyEnabled,
+		adaptiveConcurrencyInitialLimit:     serverconfig.DefaultDatastoreAdaptiveConcurrencyInitialLimit,
+		adaptiveConcurrencyMinLimit:         serverconfig.DefaultDatastoreAdaptiveConcurrencyMinLimit,
+		adaptiveConcurrencyLatencyThreshold: serverconfig.DefaultDatastoreAdaptiveConcurrencyLatencyThreshold,
+		adaptiveConcurrencyBackoffRatio:     serverconfig.DefaultDatastoreAdaptiveConcurrencyBackoffRatio,
 	}
 
 	for _, opt := range opts {
@@ -852,10 +1297,34 @@ func NewServerWithOpts(opts ...OpenFGAServiceV1Option) (*Server, error) {
 		return nil, fmt.Errorf("ListUsers default dispatch throttling threshold must be equal or smaller than max dispatch threshold for ListUsers")
 	}
 
+	if s.listStrategiesPlannerEnabled && s.listStrategiesRequiredValidations == 0 {
+		return nil, fmt.Errorf("the number of validations required to trust a ListObjects or ListUsers strategy must be positive")
+	}
+
+	if s.checkCostAdmissionEnabled {
+		switch s.checkCostAdmissionAction {
+		case serverconfig.CheckCostAdmissionActionReject:
+		case serverconfig.CheckCostAdmissionActionDeprioritize:
+			if s.checkCostAdmissionDeprioritizedFrequency <= 0 {
+				return nil, fmt.Errorf("the frequency at which deprioritized checks are let through must be positive")
+			}
+		default:
+			return nil, fmt.Errorf("unknown check cost admission action '%s'", s.checkCostAdmissionAction)
+		}
+	}
+
+	if s.adaptiveConcurrencyEnabled && (s.adaptiveConcurrencyBackoffRatio <= 0 || s.adaptiveConcurrencyBackoffRatio >= 1) {
+		return nil, fmt.Errorf("the backoff ratio of the adaptive datastore concurrency must be between 0 and 1, exclusive")
+	}
+
 	if s.featureFlagClient == nil {
 		s.featureFlagClient = featureflags.NewDefaultClient(s.experimentals)
 	}
 
+	if s.listStrategiesPlannerEnabled {
+		s.listStrategiesTrust = planner.NewTrust(s.listStrategiesRequiredValidations)
+	}
+
 	err := s.validateAccessControlEnabled()
 	if err != nil {
 		return nil, err
@@ -880,17 +1349,90 @@ func NewServerWithOpts(opts ...OpenFGAServiceV1Option) (*Server, error) {
 	}
 
 	if s.listObjectsDispatchThrottlingEnabled {
-		s.listObjectsDispatchThrottler = throttler.NewConstantRateThrottler(s.listObjectsDispatchThrottlingFrequency, "list_objects_dispatch_throttle")
+		s.listObjectsDispatchThrottler = s.newDispatchThrottler(s.listObjectsDispatchThrottlingFrequency, "list_objects_dispatch_throttle")
 	}
 
 	if s.listUsersDispatchThrottlingEnabled {
-		s.listUsersDispatchThrottler = throttler.NewConstantRateThrottler(s.listUsersDispatchThrottlingFrequency, "list_users_dispatch_throttle")
+		s.listUsersDispatchThrottler = s.newDispatchThrottler(s.listUsersDispatchThrottlingFrequency, "list_users_dispatch_throttle")
 	}
 
-	s.typesystemResolver, s.typesystemResolverStop, err = typesystem.MemoizedTypesystemResolverFunc(s.datastore)
+	if s.checkDispatchThrottlingEnabled && s.fairDispatchThrottlingEnabled {
+		// the dispatches of all the checks are scheduled together to be fair across stores, so they are released at
+		// the frequency of the whole server rather than the one of each request
+		s.checkDispatchThrottler = s.newDispatchThrottler(s.fairCheckDispatchThrottlingFrequency, "check_dispatch_throttle")
+	}
+
+	s.memoizedTypesystem, err = typesystem.NewMemoizedTypesystemResolver(s.datastore)
 	if err != nil {
 		return nil, err
 	}
+	s.typesystemResolver = s.memoizedTypesystem.Resolve
+
+	s.candidateModels, err = candidatemodel.NewRecorder(s.datastore,
+		candidatemodel.WithMaxDivergences(s.candidateModelMaxDivergences),
+		candidatemodel.WithLogger(s.logger),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.edgeSyncEnabled {
+		s.edgeSyncResults, err = storage.NewInMemoryLRUCache([]storage.InMemoryLRUCacheOpt[edgeSyncResults]{
+			storage.WithMaxCacheSize[edgeSyncResults](edgeSyncMaxCursors),
+		}...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if s.adaptiveConcurrencyEnabled {
+		limiterOpts := []storagewrappers.AdaptiveConcurrencyLimiterOption{
+			storagewrappers.WithAdaptiveConcurrencyInitialLimit(s.adaptiveConcurrencyInitialLimit),
+			storagewrappers.WithAdaptiveConcurrencyMinLimit(s.adaptiveConcurrencyMinLimit),
+			storagewrappers.WithAdaptiveConcurrencyLatencyThreshold(s.adaptiveConcurrencyLatencyThreshold),
+			storagewrappers.WithAdaptiveConcurrencyBackoffRatio(s.adaptiveConcurrencyBackoffRatio),
+		}
+		s.checkConcurrencyLimiter = storagewrappers.NewAdaptiveConcurrencyLimiter(apimethod.Check.String(), s.maxConcurrentReadsForCheck, limiterOpts...)
+		s.listObjectsConcurrencyLimiter = storagewrappers.NewAdaptiveConcurrencyLimiter(apimethod.ListObjects.String(), s.maxConcurrentReadsForListObjects, limiterOpts...)
+		s.listUsersConcurrencyLimiter = storagewrappers.NewAdaptiveConcurrencyLimiter(apimethod.ListUsers.String(), s.maxConcurrentReadsForListUsers, limiterOpts...)
+	}
+
+	if s.checkCostAdmissionEnabled {
+		s.checkCostStatistics, err = checkcost.NewSampledStatistics(s.datastore,
+			checkcost.WithStatisticsTTL(s.checkCostStatisticsTTL),
+			checkcost.WithStatisticsLogger(s.logger),
+		)
+		if err != nil {
+			return nil, err
+		}
+		admissionOpts := []checkcost.AdmissionOption{
+			checkcost.WithAction(checkcost.Action(s.checkCostAdmissionAction)),
+			checkcost.WithDefaultBudget(s.checkCostBudget),
+			checkcost.WithStoreBudgets(s.checkCostStoreBudgets),
+		}
+		if s.checkCostAdmissionAction == serverconfig.CheckCostAdmissionActionDeprioritize {
+			admissionOpts = append(admissionOpts, checkcost.WithThrottler(
+				throttler.NewConstantRateThrottler(s.checkCostAdmissionDeprioritizedFrequency, "check_cost_deprioritized"),
+			))
+		}
+		s.checkCostAdmission = checkcost.NewAdmission(checkcost.NewEstimator(s.checkCostStatistics), admissionOpts...)
+	}
+
+	if s.checkPermissionIndexEnabled {
+		s.permissionIndexStore = permissionindex.NewMemoryStore()
+		if s.checkPermissionIndexValkeyURI != "" {
+			s.permissionIndexStore, err = permissionindex.NewValkeyStore(s.checkPermissionIndexValkeyURI, s.checkPermissionIndexValkeyKeyPrefix)
+			if err != nil {
+				return nil, err
+			}
+		}
+		s.permissionIndex = permissionindex.New(s.datastore, s.permissionIndexStore, s.typesystemResolver,
+			permissionindex.WithRefreshInterval(s.checkPermissionIndexRefreshInterval),
+			permissionindex.WithMaxStaleness(s.checkPermissionIndexMaxStaleness),
+			permissionindex.WithHorizonOffset(time.Duration(s.changelogHorizonOffset)*time.Minute),
+			permissionindex.WithLogger(s.logger),
+		)
+	}
 
 	if s.IsAccessControlEnabled() {
 		s.authorizer = authz.NewAuthorizer(&authz.Config{StoreID: s.AccessControl.StoreID, ModelID: s.AccessControl.ModelID}, s, s.logger)
@@ -904,7 +1446,17 @@ func (s *Server) Close() {
 	if s.planner != nil {
 		s.planner.Stop()
 	}
-	s.typesystemResolverStop()
+	s.memoizedTypesystem.Stop()
+	s.candidateModels.Close()
+
+	if s.permissionIndex != nil {
+		s.permissionIndex.Close()
+		s.permissionIndexStore.Close()
+	}
+
+	if s.edgeSyncResults != nil {
+		s.edgeSyncResults.Stop()
+	}
 
 	if s.listObjectsDispatchThrottler != nil {
 		s.listObjectsDispatchThrottler.Close()
@@ -912,6 +1464,14 @@ func (s *Server) Close() {
 	if s.listUsersDispatchThrottler != nil {
 		s.listUsersDispatchThrottler.Close()
 	}
+	if s.checkDispatchThrottler != nil {
+		s.checkDispatchThrottler.Close()
+	}
+
+	if s.checkCostAdmission != nil {
+		s.checkCostAdmission.Close()
+		s.checkCostStatistics.Close()
+	}
 
 	s.sharedDatastoreResources.Close()
 	s.datastore.Close()
@@ -1042,6 +1602,30 @@ func (s *Server) getAccessibleStores(ctx context.Context) ([]string, error) {
 	return stores, nil
 }
 
+// ThrottledDispatches returns the number of dispatches waiting in the dispatch throttlers shared by the requests:
+// the ones of ListObjects and ListUsers, and the one of Check if fair dispatch throttling is enabled.
+func (s *Server) ThrottledDispatches() int {
+	var waiting int
+	for _, t := range []throttler.Throttler{s.listObjectsDispatchThrottler, s.listUsersDispatchThrottler, s.checkDispatchThrottler} {
+		if t != nil {
+			waiting += throttler.Waiting(t)
+		}
+	}
+	return waiting
+}
+
+// newDispatchThrottler returns the throttler of the dispatches of a method, which is fair across stores if fair
+// dispatch throttling is enabled.
+func (s *Server) newDispatchThrottler(frequency time.Duration, metricLabel string) throttler.Throttler {
+	if !s.fairDispatchThrottlingEnabled {
+		return throttler.NewConstantRateThrottler(frequency, metricLabel)
+	}
+	return throttler.NewFairThrottler(frequency, metricLabel,
+		throttler.WithStoreWeights(s.fairDispatchThrottlingStoreWeights),
+		throttler.WithPerClientFairness(s.fairDispatchThrottlingPerClient),
+	)
+}
+
 func (s *Server) getCheckResolverOptions() ([]graph.CachedCheckResolverOpt, []graph.DispatchThrottlingCheckResolverOpt) {
 	var checkCacheOptions []graph.CachedCheckResolverOpt
 	if s.cacheSettings.ShouldCacheCheckQueries() {
@@ -1054,14 +1638,18 @@ func (s *Server) getCheckResolverOptions() ([]graph.CachedCheckResolverOpt, []gr
 
 	var checkDispatchThrottlingOptions []graph.DispatchThrottlingCheckResolverOpt
 	if s.checkDispatchThrottlingEnabled {
+		// only create the throttler if the feature is enabled, so that we can clean it afterward
+		throttlerOpt := graph.WithConstantRateThrottler(s.checkDispatchThrottlingFrequency,
+			"check_dispatch_throttle")
+		if s.checkDispatchThrottler != nil {
+			throttlerOpt = graph.WithSharedThrottler(s.checkDispatchThrottler)
+		}
 		checkDispatchThrottlingOptions = []graph.DispatchThrottlingCheckResolverOpt{
 			graph.WithDispatchThrottlingCheckResolverConfig(graph.DispatchThrottlingCheckResolverConfig{
 				DefaultThreshold: s.checkDispatchThrottlingDefaultThreshold,
 				MaxThreshold:     s.checkDispatchThrottlingMaxThreshold,
 			}),
-			// only create the throttler if the feature is enabled, so that we can clean it afterward
-			graph.WithConstantRateThrottler(s.checkDispatchThrottlingFrequency,
-				"check_dispatch_throttle"),
+			throttlerOpt,
 		}
 	}
 	return checkCacheOptions, checkDispatchThrottlingOptions
@@ -32,6 +32,7 @@ import (
 	"github.com/openfga/openfga/internal/cachecontroller"
 	"github.com/openfga/openfga/internal/graph"
 	mockstorage "github.com/openfga/openfga/internal/mocks"
+	"github.com/openfga/openfga/internal/throttler"
 	"github.com/openfga/openfga/pkg/featureflags"
 	serverconfig "github.com/openfga/openfga/pkg/server/config"
 	serverErrors "github.com/openfga/openfga/pkg/server/errors"
@@ -249,14 +250,17 @@ func TestServerPanicIfValidationsFail(t *testing.T) {
 }
 
 func TestServerNotReadyDueToDatastoreRevision(t *testing.T) {
-	// skipping sqlite here because the lowest supported schema revision is 4
-	engines := []string{"postgres", "mysql"}
+	minimumRevisions := map[string]int64{
+		"postgres": build.MinimumSupportedPostgresSchemaRevision,
+		"mysql":    build.MinimumSupportedMySQLSchemaRevision,
+		"sqlite":   build.MinimumSupportedSQLiteSchemaRevision,
+	}
 
-	for _, engine := range engines {
+	for engine, minimumRevision := range minimumRevisions {
 		t.Run(engine, func(t *testing.T) {
 			_, ds, uri := util.MustBootstrapDatastore(t, engine)
 
-			targetVersion := build.MinimumSupportedDatastoreSchemaRevision - 1
+			targetVersion := minimumRevision - 1
 
 			migrateCommand := migrate.NewMigrateCommand()
 
@@ -266,7 +270,7 @@ func TestServerNotReadyDueToDatastoreRevision(t *testing.T) {
 			require.NoError(t, err)
 
 			status, _ := ds.IsReady(context.Background())
-			require.Contains(t, status.Message, fmt.Sprintf("datastore requires migrations: at revision '%d', but requires '%d'.", targetVersion, build.MinimumSupportedDatastoreSchemaRevision))
+			require.Contains(t, status.Message, fmt.Sprintf("datastore requires migrations: at revision '%d', but requires '%d'.", targetVersion, minimumRevision))
 			require.False(t, status.IsReady)
 		})
 	}
@@ -619,6 +623,7 @@ func TestRequestContextPropagation(t *testing.T) {
 			t.Cleanup(cancelParentCtx)
 
 			mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+			mockDatastore.EXPECT().ReadCandidateAuthorizationModel(gomock.Any(), gomock.Any()).Return(nil, storage.ErrNotFound).AnyTimes()
 
 			model := testutils.MustTransformDSLToProtoWithID(`
 			model
@@ -838,6 +843,7 @@ func TestCheckDoesNotThrowBecauseDirectTupleWasFound(t *testing.T) {
 	defer mockController.Finish()
 
 	mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+	mockDatastore.EXPECT().ReadCandidateAuthorizationModel(gomock.Any(), gomock.Any()).Return(nil, storage.ErrNotFound).AnyTimes()
 
 	mockDatastore.EXPECT().
 		ReadAuthorizationModel(gomock.Any(), storeID, modelID).
@@ -1129,6 +1135,7 @@ func TestShortestPathToSolutionWins(t *testing.T) {
 	defer mockController.Finish()
 
 	mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+	mockDatastore.EXPECT().ReadCandidateAuthorizationModel(gomock.Any(), gomock.Any()).Return(nil, storage.ErrNotFound).AnyTimes()
 
 	mockDatastore.EXPECT().
 		ReadAuthorizationModel(gomock.Any(), storeID, modelID).
@@ -1211,6 +1218,7 @@ func TestCheckWithCachedResolution(t *testing.T) {
 	defer mockController.Finish()
 
 	mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+	mockDatastore.EXPECT().ReadCandidateAuthorizationModel(gomock.Any(), gomock.Any()).Return(nil, storage.ErrNotFound).AnyTimes()
 
 	mockDatastore.EXPECT().
 		ReadAuthorizationModel(gomock.Any(), storeID, modelID).
@@ -1271,6 +1279,7 @@ func TestResolveAuthorizationModel(t *testing.T) {
 		defer mockController.Finish()
 
 		mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+		mockDatastore.EXPECT().ReadActiveAuthorizationModelID(gomock.Any(), store).Return("", storage.ErrNotFound)
 		mockDatastore.EXPECT().FindLatestAuthorizationModel(gomock.Any(), store).Return(nil, storage.ErrNotFound)
 
 		s := MustNewServerWithOpts(
@@ -1295,6 +1304,7 @@ func TestResolveAuthorizationModel(t *testing.T) {
 		defer mockController.Finish()
 
 		mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+		mockDatastore.EXPECT().ReadActiveAuthorizationModelID(gomock.Any(), store).Return("", storage.ErrNotFound)
 		mockDatastore.EXPECT().FindLatestAuthorizationModel(gomock.Any(), store).Return(
 			&openfgav1.AuthorizationModel{
 				Id:            modelID,
@@ -1440,6 +1450,7 @@ func TestListObjects_ErrorCases(t *testing.T) {
 
 	t.Run("database_errors", func(t *testing.T) {
 		mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+		mockDatastore.EXPECT().ReadCandidateAuthorizationModel(gomock.Any(), gomock.Any()).Return(nil, storage.ErrNotFound).AnyTimes()
 
 		s := MustNewServerWithOpts(
 			WithDatastore(mockDatastore),
@@ -1761,6 +1772,31 @@ func TestDelegateCheckResolver(t *testing.T) {
 		require.True(t, ok)
 	})
 
+	t.Run("fair_dispatch_throttling_enabled", func(t *testing.T) {
+		ds := memory.New()
+		t.Cleanup(ds.Close)
+		s := MustNewServerWithOpts(
+			WithDatastore(ds),
+			WithDispatchThrottlingCheckResolverEnabled(true),
+			WithListObjectsDispatchThrottlingEnabled(true),
+			WithFairDispatchThrottling(true, false, map[string]float64{"store_id_123": 2}),
+		)
+		t.Cleanup(s.Close)
+
+		require.IsType(t, &throttler.FairThrottler{}, s.checkDispatchThrottler)
+		require.IsType(t, &throttler.FairThrottler{}, s.listObjectsDispatchThrottler)
+
+		// the check throttler is shared by the requests, and outlives their resolvers
+		checkResolver, closer, _ := s.getCheckResolverBuilder("store_id_123").Build()
+		require.NotNil(t, checkResolver)
+		closer()
+
+		checkResolver, closer, _ = s.getCheckResolverBuilder("store_id_123").Build()
+		defer closer()
+		_, ok := checkResolver.(*graph.DispatchThrottlingCheckResolver)
+		require.True(t, ok)
+	})
+
 	t.Run("dispatch_throttling_check_resolver_enabled_zero_max_threshold", func(t *testing.T) {
 		ds := memory.New()
 		t.Cleanup(ds.Close)
@@ -2239,6 +2275,7 @@ func TestCheckWithCachedIterator(t *testing.T) {
 	defer mockController.Finish()
 
 	mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+	mockDatastore.EXPECT().ReadCandidateAuthorizationModel(gomock.Any(), gomock.Any()).Return(nil, storage.ErrNotFound).AnyTimes()
 
 	mockDatastore.EXPECT().
 		ReadAuthorizationModel(gomock.Any(), storeID, modelID).
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/streamedbatchcheck"
+	"github.com/openfga/openfga/pkg/server/config"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestStreamedBatchCheck(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	s := MustNewServerWithOpts(WithDatastore(ds))
+	t.Cleanup(s.Close)
+
+	conn := newJSONGRPCTestConn(t, s, &streamedbatchcheck.ServiceDesc)
+
+	ctx := context.Background()
+	createStoreResp, err := s.CreateStore(ctx, &openfgav1.CreateStoreRequest{Name: "openfga-test"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type doc
+			relations
+				define viewer: [user]`)
+	_, err = s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKey("doc:0", "viewer", "user:anne"),
+		}},
+	})
+	require.NoError(t, err)
+
+	t.Run("more_checks_than_allowed_per_batch", func(t *testing.T) {
+		numChecks := config.DefaultMaxChecksPerBatchCheck + 1
+		checks := make([]*openfgav1.BatchCheckItem, numChecks)
+		for i := 0; i < numChecks; i++ {
+			checks[i] = &openfgav1.BatchCheckItem{
+				TupleKey:      tuple.NewCheckRequestTupleKey(fmt.Sprintf("doc:%d", i), "viewer", "user:anne"),
+				CorrelationId: fmt.Sprintf("id%d", i),
+			}
+		}
+
+		stream, err := streamedbatchcheck.StreamedBatchCheck(ctx, conn, &openfgav1.BatchCheckRequest{
+			StoreId: storeID,
+			Checks:  checks,
+		})
+		require.NoError(t, err)
+
+		results := map[string]*openfgav1.BatchCheckSingleResult{}
+		for {
+			resp, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			require.Len(t, resp.GetResult(), 1)
+			for id, result := range resp.GetResult() {
+				results[id] = result
+			}
+		}
+
+		require.Len(t, results, numChecks)
+		require.True(t, results["id0"].GetAllowed())
+		require.False(t, results["id1"].GetAllowed())
+	})
+
+	t.Run("invalid_relation", func(t *testing.T) {
+		stream, err := streamedbatchcheck.StreamedBatchCheck(ctx, conn, &openfgav1.BatchCheckRequest{
+			StoreId: storeID,
+			Checks: []*openfgav1.BatchCheckItem{{
+				TupleKey:      tuple.NewCheckRequestTupleKey("doc:0", "undefined", "user:anne"),
+				CorrelationId: "id0",
+			}},
+		})
+		require.NoError(t, err)
+
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+		require.NotNil(t, resp.GetResult()["id0"].GetError())
+
+		_, err = stream.Recv()
+		require.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("duplicate_correlation_ids", func(t *testing.T) {
+		check := &openfgav1.BatchCheckItem{
+			TupleKey:      tuple.NewCheckRequestTupleKey("doc:0", "viewer", "user:anne"),
+			CorrelationId: "id0",
+		}
+		stream, err := streamedbatchcheck.StreamedBatchCheck(ctx, conn, &openfgav1.BatchCheckRequest{
+			StoreId: storeID,
+			Checks:  []*openfgav1.BatchCheckItem{check, check},
+		})
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_validation_error), status.Code(err))
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -41,6 +42,8 @@ const (
 	iteratorCachePrefix        = "ic."
 	changelogCachePrefix       = "cc."
 	invalidIteratorCachePrefix = "iq."
+	listObjectsCachePrefix     = "lo."
+	invalidListObjectsPrefix   = "il."
 	defaultMaxCacheSize        = 10000
 	oneYear                    = time.Hour * 24 * 365
 
@@ -170,6 +173,7 @@ var (
 	_ CacheItem = (*ChangelogCacheEntry)(nil)
 	_ CacheItem = (*InvalidEntityCacheEntry)(nil)
 	_ CacheItem = (*TupleIteratorCacheEntry)(nil)
+	_ CacheItem = (*ListObjectsCacheEntry)(nil)
 )
 
 type ChangelogCacheEntry struct {
@@ -211,6 +215,26 @@ func GetInvalidIteratorByUserObjectTypeCacheKeys(storeID string, users []string,
 	return res
 }
 
+// GetInvalidListObjectsCacheKey returns the key of the InvalidEntityCacheEntry that invalidates every cached
+// ListObjects result of the store.
+func GetInvalidListObjectsCacheKey(storeID string) string {
+	return invalidListObjectsPrefix + storeID
+}
+
+// GetInvalidListObjectsByObjectTypeRelationCacheKey returns the key of the InvalidEntityCacheEntry that invalidates
+// the cached ListObjects results that depend on the tuples of the relation of the objectType.
+func GetInvalidListObjectsByObjectTypeRelationCacheKey(storeID, objectType, relation string) string {
+	return invalidListObjectsPrefix + storeID + "-tr/" + objectType + "#" + relation
+}
+
+// IsCacheInvalidationKey reports whether key holds a ChangelogCacheEntry or an InvalidEntityCacheEntry,
+// i.e. a timestamp that decides whether other cache entries are still valid.
+func IsCacheInvalidationKey(key string) bool {
+	return strings.HasPrefix(key, changelogCachePrefix) ||
+		strings.HasPrefix(key, invalidIteratorCachePrefix) ||
+		strings.HasPrefix(key, invalidListObjectsPrefix)
+}
+
 type TupleIteratorCacheEntry struct {
 	Tuples       []*TupleRecord
 	LastModified time.Time
@@ -220,6 +244,54 @@ func (t *TupleIteratorCacheEntry) CacheEntityType() string {
 	return "tuple_iterator"
 }
 
+// ListObjectsCacheEntry is a cached ListObjects result.
+type ListObjectsCacheEntry struct {
+	Objects      []string
+	LastModified time.Time
+}
+
+func (l *ListObjectsCacheEntry) CacheEntityType() string {
+	return "list_objects"
+}
+
+// ListObjectsCacheKeyParams is all the necessary pieces to create a unique-per-ListObjects cache key.
+type ListObjectsCacheKeyParams struct {
+	StoreID              string
+	AuthorizationModelID string
+	ObjectType           string
+	Relation             string
+	User                 string
+	Context              *structpb.Struct
+}
+
+// WriteListObjectsCacheKey converts the elements of a ListObjects request into a canonical cache key, and writes it
+// to the provided writer. The context parameter order is ignored, only the contents are compared.
+func WriteListObjectsCacheKey(w io.StringWriter, params *ListObjectsCacheKeyParams) error {
+	_, err := w.WriteString(
+		listObjectsCachePrefix +
+			params.StoreID +
+			"/" +
+			params.AuthorizationModelID +
+			"/" +
+			params.ObjectType +
+			"#" +
+			params.Relation +
+			"@" +
+			params.User,
+	)
+	if err != nil {
+		return err
+	}
+
+	if params.Context != nil {
+		if err = writeStruct(w, params.Context); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func GetReadUsersetTuplesCacheKeyPrefix(store, object, relation string) string {
 	return iteratorCachePrefix + "rut/" + store + "/" + object + "#" + relation
 }
@@ -0,0 +1,224 @@
+package storage
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ErrUncacheableValue is returned by a CacheCodec for values that cannot be encoded, e.g.
+// a CacheItem that was never registered with RegisterCacheItem.
+var ErrUncacheableValue = errors.New("value cannot be stored in an out of process cache")
+
+// CacheCodec encodes and decodes the values of an InMemoryCache implementation that stores
+// them outside the process, such as a cache shared between replicas.
+type CacheCodec[T any] interface {
+	Encode(value T) ([]byte, error)
+	Decode(data []byte) (T, error)
+}
+
+var (
+	cacheItemTypesMu sync.RWMutex
+	cacheItemTypes   = map[string]func() CacheItem{}
+)
+
+// RegisterCacheItem makes a CacheItem type encodable by the codec returned from NewCacheItemCodec.
+// newItem must return a new zero value of the type, which must implement encoding.BinaryMarshaler
+// and encoding.BinaryUnmarshaler. It is meant to be called from init functions.
+func RegisterCacheItem(newItem func() CacheItem) {
+	item := newItem()
+	if _, ok := item.(encoding.BinaryMarshaler); !ok {
+		panic(fmt.Sprintf("cache item %q does not implement encoding.BinaryMarshaler", item.CacheEntityType()))
+	}
+	if _, ok := item.(encoding.BinaryUnmarshaler); !ok {
+		panic(fmt.Sprintf("cache item %q does not implement encoding.BinaryUnmarshaler", item.CacheEntityType()))
+	}
+
+	cacheItemTypesMu.Lock()
+	defer cacheItemTypesMu.Unlock()
+	cacheItemTypes[item.CacheEntityType()] = newItem
+}
+
+func init() {
+	RegisterCacheItem(func() CacheItem { return &ChangelogCacheEntry{} })
+	RegisterCacheItem(func() CacheItem { return &InvalidEntityCacheEntry{} })
+	RegisterCacheItem(func() CacheItem { return &TupleIteratorCacheEntry{} })
+	RegisterCacheItem(func() CacheItem { return &ListObjectsCacheEntry{} })
+}
+
+// NewCacheItemCodec returns a CacheCodec for caches of CacheItem values, such as the cache shared
+// by Check sub-problems and iterators. Only the types registered with RegisterCacheItem are encoded.
+func NewCacheItemCodec() CacheCodec[any] {
+	return cacheItemCodec{}
+}
+
+type cacheItemCodec struct{}
+
+// cacheItemSeparator separates the entity type of an encoded CacheItem from its payload.
+const cacheItemSeparator = '\n'
+
+func (cacheItemCodec) Encode(value any) ([]byte, error) {
+	item, ok := value.(CacheItem)
+	if !ok {
+		return nil, ErrUncacheableValue
+	}
+
+	cacheItemTypesMu.RLock()
+	_, registered := cacheItemTypes[item.CacheEntityType()]
+	cacheItemTypesMu.RUnlock()
+	if !registered {
+		return nil, ErrUncacheableValue
+	}
+
+	payload, err := item.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 0, len(item.CacheEntityType())+1+len(payload))
+	data = append(data, item.CacheEntityType()...)
+	data = append(data, cacheItemSeparator)
+	return append(data, payload...), nil
+}
+
+func (cacheItemCodec) Decode(data []byte) (any, error) {
+	entityType, payload, ok := bytes.Cut(data, []byte{cacheItemSeparator})
+	if !ok {
+		return nil, errors.New("malformed cache item")
+	}
+
+	cacheItemTypesMu.RLock()
+	newItem, registered := cacheItemTypes[string(entityType)]
+	cacheItemTypesMu.RUnlock()
+	if !registered {
+		return nil, fmt.Errorf("unknown cache item type %q", entityType)
+	}
+
+	item := newItem()
+	if err := item.(encoding.BinaryUnmarshaler).UnmarshalBinary(payload); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (c *ChangelogCacheEntry) MarshalBinary() ([]byte, error) {
+	type entry ChangelogCacheEntry
+	return json.Marshal((*entry)(c))
+}
+
+func (c *ChangelogCacheEntry) UnmarshalBinary(data []byte) error {
+	type entry ChangelogCacheEntry
+	return json.Unmarshal(data, (*entry)(c))
+}
+
+func (i *InvalidEntityCacheEntry) MarshalBinary() ([]byte, error) {
+	type entry InvalidEntityCacheEntry
+	return json.Marshal((*entry)(i))
+}
+
+func (i *InvalidEntityCacheEntry) UnmarshalBinary(data []byte) error {
+	type entry InvalidEntityCacheEntry
+	return json.Unmarshal(data, (*entry)(i))
+}
+
+func (l *ListObjectsCacheEntry) MarshalBinary() ([]byte, error) {
+	type entry ListObjectsCacheEntry
+	return json.Marshal((*entry)(l))
+}
+
+func (l *ListObjectsCacheEntry) UnmarshalBinary(data []byte) error {
+	type entry ListObjectsCacheEntry
+	return json.Unmarshal(data, (*entry)(l))
+}
+
+// encodedTupleRecord is the encoding of a TupleRecord. The condition context is a protobuf
+// message, which is not supported by encoding/json.
+type encodedTupleRecord struct {
+	Store            string          `json:"store,omitempty"`
+	ObjectType       string          `json:"object_type"`
+	ObjectID         string          `json:"object_id"`
+	Relation         string          `json:"relation"`
+	User             string          `json:"user,omitempty"`
+	UserObjectType   string          `json:"user_object_type,omitempty"`
+	UserObjectID     string          `json:"user_object_id,omitempty"`
+	UserRelation     string          `json:"user_relation,omitempty"`
+	ConditionName    string          `json:"condition_name,omitempty"`
+	ConditionContext json.RawMessage `json:"condition_context,omitempty"`
+	Ulid             string          `json:"ulid,omitempty"`
+	InsertedAt       time.Time       `json:"inserted_at"`
+}
+
+type encodedTupleIteratorCacheEntry struct {
+	Tuples       []encodedTupleRecord `json:"tuples"`
+	LastModified time.Time            `json:"last_modified"`
+}
+
+func (t *TupleIteratorCacheEntry) MarshalBinary() ([]byte, error) {
+	entry := encodedTupleIteratorCacheEntry{
+		Tuples:       make([]encodedTupleRecord, len(t.Tuples)),
+		LastModified: t.LastModified,
+	}
+	for i, record := range t.Tuples {
+		entry.Tuples[i] = encodedTupleRecord{
+			Store:          record.Store,
+			ObjectType:     record.ObjectType,
+			ObjectID:       record.ObjectID,
+			Relation:       record.Relation,
+			User:           record.User,
+			UserObjectType: record.UserObjectType,
+			UserObjectID:   record.UserObjectID,
+			UserRelation:   record.UserRelation,
+			ConditionName:  record.ConditionName,
+			Ulid:           record.Ulid,
+			InsertedAt:     record.InsertedAt,
+		}
+		if record.ConditionContext != nil {
+			conditionContext, err := protojson.Marshal(record.ConditionContext)
+			if err != nil {
+				return nil, err
+			}
+			entry.Tuples[i].ConditionContext = conditionContext
+		}
+	}
+	return json.Marshal(entry)
+}
+
+func (t *TupleIteratorCacheEntry) UnmarshalBinary(data []byte) error {
+	var entry encodedTupleIteratorCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return err
+	}
+
+	t.LastModified = entry.LastModified
+	t.Tuples = make([]*TupleRecord, len(entry.Tuples))
+	for i, record := range entry.Tuples {
+		t.Tuples[i] = &TupleRecord{
+			Store:          record.Store,
+			ObjectType:     record.ObjectType,
+			ObjectID:       record.ObjectID,
+			Relation:       record.Relation,
+			User:           record.User,
+			UserObjectType: record.UserObjectType,
+			UserObjectID:   record.UserObjectID,
+			UserRelation:   record.UserRelation,
+			ConditionName:  record.ConditionName,
+			Ulid:           record.Ulid,
+			InsertedAt:     record.InsertedAt,
+		}
+		if len(record.ConditionContext) > 0 {
+			conditionContext := &structpb.Struct{}
+			if err := protojson.Unmarshal(record.ConditionContext, conditionContext); err != nil {
+				return err
+			}
+			t.Tuples[i].ConditionContext = conditionContext
+		}
+	}
+	return nil
+}
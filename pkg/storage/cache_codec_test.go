@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestCacheItemCodec(t *testing.T) {
+	codec := NewCacheItemCodec()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	conditionContext, err := structpb.NewStruct(map[string]any{"x": 10, "names": []any{"a", "b"}})
+	require.NoError(t, err)
+
+	items := []CacheItem{
+		&ChangelogCacheEntry{LastModified: now, LastChecked: now.Add(time.Second)},
+		&InvalidEntityCacheEntry{LastModified: now},
+		&TupleIteratorCacheEntry{LastModified: now, Tuples: []*TupleRecord{}},
+		&ListObjectsCacheEntry{LastModified: now, Objects: []string{"document:1", "document:2"}},
+		&TupleIteratorCacheEntry{
+			LastModified: now,
+			Tuples: []*TupleRecord{
+				{
+					Store:          "store",
+					ObjectType:     "document",
+					ObjectID:       "1",
+					Relation:       "viewer",
+					UserObjectType: "group",
+					UserObjectID:   "eng",
+					UserRelation:   "member",
+					Ulid:           "01JAMVSWHC3VH9ZR2S9XVVBGJD",
+					InsertedAt:     now,
+				},
+				{
+					ObjectType:       "document",
+					ObjectID:         "2",
+					Relation:         "viewer",
+					User:             "user:anne",
+					ConditionName:    "cond",
+					ConditionContext: conditionContext,
+					InsertedAt:       now,
+				},
+			},
+		},
+	}
+
+	for _, item := range items {
+		t.Run(item.CacheEntityType(), func(t *testing.T) {
+			data, err := codec.Encode(item)
+			require.NoError(t, err)
+
+			decoded, err := codec.Decode(data)
+			require.NoError(t, err)
+			if diff := cmp.Diff(item, decoded, protocmp.Transform()); diff != "" {
+				t.Fatalf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("uncacheable_values", func(t *testing.T) {
+		for _, value := range []any{"value", &unregisteredCacheItem{}} {
+			_, err := codec.Encode(value)
+			require.ErrorIs(t, err, ErrUncacheableValue)
+		}
+	})
+
+	t.Run("invalid_data", func(t *testing.T) {
+		for _, data := range []string{"", "unknown\n{}", "changelog\n{"} {
+			_, err := codec.Decode([]byte(data))
+			require.Error(t, err)
+		}
+	})
+}
+
+type unregisteredCacheItem struct{}
+
+func (*unregisteredCacheItem) CacheEntityType() string {
+	return "unregistered"
+}
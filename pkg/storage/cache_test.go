@@ -998,3 +998,43 @@ func BenchmarkGetInvalidIteratorByUserObjectTypeCacheKeys(b *testing.B) {
 		_ = GetInvalidIteratorByUserObjectTypeCacheKeys(storeID, users, objectType)
 	}
 }
+
+func TestListObjectsCacheKey(t *testing.T) {
+	mustGetKey := func(params *ListObjectsCacheKeyParams) string {
+		w := &strings.Builder{}
+		require.NoError(t, WriteListObjectsCacheKey(w, params))
+		return w.String()
+	}
+
+	params := ListObjectsCacheKeyParams{
+		StoreID:              ulid.Make().String(),
+		AuthorizationModelID: ulid.Make().String(),
+		ObjectType:           "document",
+		Relation:             "viewer",
+		User:                 "user:jon",
+	}
+	key := mustGetKey(&params)
+
+	for _, modify := range []func(p *ListObjectsCacheKeyParams){
+		func(p *ListObjectsCacheKeyParams) { p.AuthorizationModelID = ulid.Make().String() },
+		func(p *ListObjectsCacheKeyParams) { p.ObjectType = "folder" },
+		func(p *ListObjectsCacheKeyParams) { p.Relation = "editor" },
+		func(p *ListObjectsCacheKeyParams) { p.User = "user:anne" },
+		func(p *ListObjectsCacheKeyParams) {
+			p.Context, _ = structpb.NewStruct(map[string]interface{}{"x": 1})
+		},
+	} {
+		modified := params
+		modify(&modified)
+		require.NotEqual(t, key, mustGetKey(&modified))
+	}
+
+	withContext := func(context map[string]interface{}) string {
+		p := params
+		var err error
+		p.Context, err = structpb.NewStruct(context)
+		require.NoError(t, err)
+		return mustGetKey(&p)
+	}
+	require.Equal(t, withContext(map[string]interface{}{"x": 1, "y": "a"}), withContext(map[string]interface{}{"y": "a", "x": 1}))
+}
@@ -139,7 +139,9 @@ type MemoryBackend struct {
 	// AuthorizationModelBackend
 	// map: store = > map: type definition id => type definition
 	authorizationModels map[string]map[string]*AuthorizationModelEntry // GUARDED_BY(mutexModels).
-	mutexModels         sync.RWMutex
+	// map: store => active authz model id
+	activeAuthorizationModels map[string]string // GUARDED_BY(mutexModels).
+	mutexModels               sync.RWMutex
 
 	// map: store id => store data
 	stores      map[string]*openfgav1.Store // GUARDED_BY(mutexStores).
@@ -148,6 +150,19 @@ type MemoryBackend struct {
 	// map: store id | authz model id => assertions
 	assertions      map[string][]*openfgav1.Assertion // GUARDED_BY(mutexAssertions).
 	mutexAssertions sync.RWMutex
+
+	// map: replica id => planner snapshot
+	plannerSnapshots      map[string]plannerSnapshotEntry // GUARDED_BY(mutexPlannerSnapshots).
+	mutexPlannerSnapshots sync.RWMutex
+
+	// map: store id => candidate authorization model
+	candidateAuthorizationModels      map[string]storage.CandidateAuthorizationModel // GUARDED_BY(mutexCandidateAuthorizationModels).
+	mutexCandidateAuthorizationModels sync.RWMutex
+}
+
+type plannerSnapshotEntry struct {
+	snapshot  []byte
+	updatedAt time.Time
 }
 
 // Ensures that [MemoryBackend] implements the [storage.OpenFGADatastore] interface.
@@ -168,8 +183,11 @@ func New(opts ...StorageOption) storage.OpenFGADatastore {
 		tuples:                        make(map[string][]*storage.TupleRecord, 0),
 		changes:                       make(map[string][]*tupleChangeRec, 0),
 		authorizationModels:           make(map[string]map[string]*AuthorizationModelEntry),
+		activeAuthorizationModels:     make(map[string]string),
 		stores:                        make(map[string]*openfgav1.Store, 0),
 		assertions:                    make(map[string][]*openfgav1.Assertion, 0),
+		plannerSnapshots:              make(map[string]plannerSnapshotEntry),
+		candidateAuthorizationModels:  make(map[string]storage.CandidateAuthorizationModel),
 	}
 
 	for _, opt := range opts {
@@ -572,6 +590,14 @@ func (s *MemoryBackend) ReadStartingWithUser(
 			continue
 		}
 
+		if !strings.HasPrefix(t.ObjectID, filter.ObjectIDPrefix) {
+			continue
+		}
+
+		if filter.ObjectIDAfter != "" && t.ObjectID <= filter.ObjectIDAfter {
+			continue
+		}
+
 		if len(filter.Conditions) > 0 && !slices.Contains(filter.Conditions, t.ConditionName) {
 			continue
 		}
@@ -740,6 +766,37 @@ func (s *MemoryBackend) WriteAuthorizationModel(ctx context.Context, store strin
 	return nil
 }
 
+// ReadActiveAuthorizationModelID see [storage.AuthorizationModelReadBackend].ReadActiveAuthorizationModelID.
+func (s *MemoryBackend) ReadActiveAuthorizationModelID(ctx context.Context, store string) (string, error) {
+	_, span := tracer.Start(ctx, "memory.ReadActiveAuthorizationModelID")
+	defer span.End()
+
+	s.mutexModels.RLock()
+	defer s.mutexModels.RUnlock()
+
+	modelID, ok := s.activeAuthorizationModels[store]
+	if !ok {
+		return "", storage.ErrNotFound
+	}
+	return modelID, nil
+}
+
+// WriteActiveAuthorizationModelID see [storage.TypeDefinitionWriteBackend].WriteActiveAuthorizationModelID.
+func (s *MemoryBackend) WriteActiveAuthorizationModelID(ctx context.Context, store, modelID string) error {
+	_, span := tracer.Start(ctx, "memory.WriteActiveAuthorizationModelID")
+	defer span.End()
+
+	s.mutexModels.Lock()
+	defer s.mutexModels.Unlock()
+
+	if modelID == "" {
+		delete(s.activeAuthorizationModels, store)
+		return nil
+	}
+	s.activeAuthorizationModels[store] = modelID
+	return nil
+}
+
 // CreateStore adds a new store to the [MemoryBackend].
 func (s *MemoryBackend) CreateStore(ctx context.Context, newStore *openfgav1.Store) (*openfgav1.Store, error) {
 	_, span := tracer.Start(ctx, "memory.CreateStore")
@@ -805,6 +862,112 @@ func (s *MemoryBackend) ReadAssertions(ctx context.Context, store, modelID strin
 	return assertions, nil
 }
 
+// WritePlannerSnapshot see [storage.PlannerSnapshotBackend].WritePlannerSnapshot.
+func (s *MemoryBackend) WritePlannerSnapshot(ctx context.Context, replicaID string, snapshot []byte) error {
+	_, span := tracer.Start(ctx, "memory.WritePlannerSnapshot")
+	defer span.End()
+
+	s.mutexPlannerSnapshots.Lock()
+	defer s.mutexPlannerSnapshots.Unlock()
+
+	s.plannerSnapshots[replicaID] = plannerSnapshotEntry{snapshot: slices.Clone(snapshot), updatedAt: time.Now()}
+	return nil
+}
+
+// ReadPlannerSnapshots see [storage.PlannerSnapshotBackend].ReadPlannerSnapshots.
+func (s *MemoryBackend) ReadPlannerSnapshots(ctx context.Context) (map[string][]byte, error) {
+	_, span := tracer.Start(ctx, "memory.ReadPlannerSnapshots")
+	defer span.End()
+
+	s.mutexPlannerSnapshots.RLock()
+	defer s.mutexPlannerSnapshots.RUnlock()
+
+	snapshots := make(map[string][]byte, len(s.plannerSnapshots))
+	for replicaID, entry := range s.plannerSnapshots {
+		snapshots[replicaID] = slices.Clone(entry.snapshot)
+	}
+	return snapshots, nil
+}
+
+// DeletePlannerSnapshots see [storage.PlannerSnapshotBackend].DeletePlannerSnapshots.
+func (s *MemoryBackend) DeletePlannerSnapshots(ctx context.Context, olderThan time.Duration) error {
+	_, span := tracer.Start(ctx, "memory.DeletePlannerSnapshots")
+	defer span.End()
+
+	s.mutexPlannerSnapshots.Lock()
+	defer s.mutexPlannerSnapshots.Unlock()
+
+	for replicaID, entry := range s.plannerSnapshots {
+		if time.Since(entry.updatedAt) > olderThan {
+			delete(s.plannerSnapshots, replicaID)
+		}
+	}
+	return nil
+}
+
+// WriteCandidateAuthorizationModel see [storage.CandidateModelBackend].WriteCandidateAuthorizationModel.
+func (s *MemoryBackend) WriteCandidateAuthorizationModel(ctx context.Context, store, modelID string, samplePercentage int) error {
+	_, span := tracer.Start(ctx, "memory.WriteCandidateAuthorizationModel")
+	defer span.End()
+
+	s.mutexCandidateAuthorizationModels.Lock()
+	defer s.mutexCandidateAuthorizationModels.Unlock()
+
+	if modelID == "" {
+		delete(s.candidateAuthorizationModels, store)
+		return nil
+	}
+
+	s.candidateAuthorizationModels[store] = storage.CandidateAuthorizationModel{
+		ModelID:          modelID,
+		SamplePercentage: samplePercentage,
+		Since:            time.Now().UTC(),
+	}
+	return nil
+}
+
+// ReadCandidateAuthorizationModel see [storage.CandidateModelBackend].ReadCandidateAuthorizationModel.
+func (s *MemoryBackend) ReadCandidateAuthorizationModel(ctx context.Context, store string) (*storage.CandidateAuthorizationModel, error) {
+	_, span := tracer.Start(ctx, "memory.ReadCandidateAuthorizationModel")
+	defer span.End()
+
+	s.mutexCandidateAuthorizationModels.RLock()
+	defer s.mutexCandidateAuthorizationModels.RUnlock()
+
+	candidate, ok := s.candidateAuthorizationModels[store]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return &candidate, nil
+}
+
+// AddCandidateAuthorizationModelCounts see [storage.CandidateModelBackend].AddCandidateAuthorizationModelCounts.
+func (s *MemoryBackend) AddCandidateAuthorizationModelCounts(ctx context.Context, store, modelID string, check, listObjects storage.CandidateModelCounts) error {
+	_, span := tracer.Start(ctx, "memory.AddCandidateAuthorizationModelCounts")
+	defer span.End()
+
+	s.mutexCandidateAuthorizationModels.Lock()
+	defer s.mutexCandidateAuthorizationModels.Unlock()
+
+	candidate, ok := s.candidateAuthorizationModels[store]
+	if !ok || candidate.ModelID != modelID {
+		return nil
+	}
+
+	candidate.Check = addCandidateModelCounts(candidate.Check, check)
+	candidate.ListObjects = addCandidateModelCounts(candidate.ListObjects, listObjects)
+	s.candidateAuthorizationModels[store] = candidate
+	return nil
+}
+
+func addCandidateModelCounts(a, b storage.CandidateModelCounts) storage.CandidateModelCounts {
+	return storage.CandidateModelCounts{
+		Evaluated: a.Evaluated + b.Evaluated,
+		Diverged:  a.Diverged + b.Diverged,
+		Errored:   a.Errored + b.Errored,
+	}
+}
+
 // MaxTuplesPerWrite see [storage.RelationshipTupleWriter].MaxTuplesPerWrite.
 func (s *MemoryBackend) MaxTuplesPerWrite() int {
 	return s.maxTuplesPerWrite
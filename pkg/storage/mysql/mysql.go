@@ -22,6 +22,7 @@ import (
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 
+	"github.com/openfga/openfga/internal/build"
 	"github.com/openfga/openfga/pkg/logger"
 	"github.com/openfga/openfga/pkg/storage"
 	"github.com/openfga/openfga/pkg/storage/sqlcommon"
@@ -380,6 +381,14 @@ func (s *Datastore) ReadStartingWithUser(
 	if filter.ObjectIDs != nil && filter.ObjectIDs.Size() > 0 {
 		builder = builder.Where(sq.Eq{"object_id": filter.ObjectIDs.Values()})
 	}
+
+	if filter.ObjectIDPrefix != "" {
+		builder = builder.Where(sq.Expr("object_id LIKE ? ESCAPE '!'", sqlcommon.LikePrefixPattern(filter.ObjectIDPrefix)))
+	}
+
+	if filter.ObjectIDAfter != "" {
+		builder = builder.Where(sq.Gt{"object_id": filter.ObjectIDAfter})
+	}
 	if len(filter.Conditions) > 0 {
 		builder = builder.Where(sq.Eq{"COALESCE(condition_name, '')": filter.Conditions})
 	}
@@ -484,6 +493,51 @@ func (s *Datastore) WriteAuthorizationModel(ctx context.Context, store string, m
 	return sqlcommon.WriteAuthorizationModel(ctx, s.dbInfo, store, model)
 }
 
+// ReadActiveAuthorizationModelID see [storage.AuthorizationModelReadBackend].ReadActiveAuthorizationModelID.
+func (s *Datastore) ReadActiveAuthorizationModelID(ctx context.Context, store string) (string, error) {
+	ctx, span := startTrace(ctx, "ReadActiveAuthorizationModelID")
+	defer span.End()
+
+	var modelID string
+	err := s.stbl.
+		Select("authorization_model_id").
+		From("active_authorization_model").
+		Where(sq.Eq{"store": store}).
+		QueryRowContext(ctx).
+		Scan(&modelID)
+	if err != nil {
+		return "", HandleSQLError(err)
+	}
+
+	return modelID, nil
+}
+
+// WriteActiveAuthorizationModelID see [storage.TypeDefinitionWriteBackend].WriteActiveAuthorizationModelID.
+func (s *Datastore) WriteActiveAuthorizationModelID(ctx context.Context, store, modelID string) error {
+	ctx, span := startTrace(ctx, "WriteActiveAuthorizationModelID")
+	defer span.End()
+
+	var err error
+	if modelID == "" {
+		_, err = s.stbl.
+			Delete("active_authorization_model").
+			Where(sq.Eq{"store": store}).
+			ExecContext(ctx)
+	} else {
+		_, err = s.stbl.
+			Insert("active_authorization_model").
+			Columns("store", "authorization_model_id", "updated_at").
+			Values(store, modelID, sq.Expr("NOW()")).
+			Suffix("ON DUPLICATE KEY UPDATE authorization_model_id = ?, updated_at = NOW()", modelID).
+			ExecContext(ctx)
+	}
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	return nil
+}
+
 // CreateStore adds a new store to storage.
 func (s *Datastore) CreateStore(ctx context.Context, store *openfgav1.Store) (*openfgav1.Store, error) {
 	ctx, span := startTrace(ctx, "CreateStore")
@@ -703,6 +757,148 @@ func (s *Datastore) ReadAssertions(ctx context.Context, store, modelID string) (
 	return assertions.GetAssertions(), nil
 }
 
+// WritePlannerSnapshot see [storage.PlannerSnapshotBackend].WritePlannerSnapshot.
+func (s *Datastore) WritePlannerSnapshot(ctx context.Context, replicaID string, snapshot []byte) error {
+	ctx, span := startTrace(ctx, "WritePlannerSnapshot")
+	defer span.End()
+
+	_, err := s.stbl.
+		Insert("planner_snapshot").
+		Columns("replica_id", "snapshot", "updated_at").
+		Values(replicaID, snapshot, sq.Expr("NOW()")).
+		Suffix("ON DUPLICATE KEY UPDATE snapshot = ?, updated_at = NOW()", snapshot).
+		ExecContext(ctx)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	return nil
+}
+
+// ReadPlannerSnapshots see [storage.PlannerSnapshotBackend].ReadPlannerSnapshots.
+func (s *Datastore) ReadPlannerSnapshots(ctx context.Context) (map[string][]byte, error) {
+	ctx, span := startTrace(ctx, "ReadPlannerSnapshots")
+	defer span.End()
+
+	rows, err := s.stbl.
+		Select("replica_id", "snapshot").
+		From("planner_snapshot").
+		QueryContext(ctx)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+	defer rows.Close()
+
+	snapshots := map[string][]byte{}
+	for rows.Next() {
+		var replicaID string
+		var snapshot []byte
+		if err := rows.Scan(&replicaID, &snapshot); err != nil {
+			return nil, HandleSQLError(err)
+		}
+		snapshots[replicaID] = snapshot
+	}
+	if err := rows.Err(); err != nil {
+		return nil, HandleSQLError(err)
+	}
+
+	return snapshots, nil
+}
+
+// DeletePlannerSnapshots see [storage.PlannerSnapshotBackend].DeletePlannerSnapshots.
+func (s *Datastore) DeletePlannerSnapshots(ctx context.Context, olderThan time.Duration) error {
+	ctx, span := startTrace(ctx, "DeletePlannerSnapshots")
+	defer span.End()
+
+	_, err := s.stbl.
+		Delete("planner_snapshot").
+		Where(sq.Expr("updated_at < NOW() - INTERVAL ? MICROSECOND", olderThan.Microseconds())).
+		ExecContext(ctx)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	return nil
+}
+
+// WriteCandidateAuthorizationModel see [storage.CandidateModelBackend].WriteCandidateAuthorizationModel.
+func (s *Datastore) WriteCandidateAuthorizationModel(ctx context.Context, store, modelID string, samplePercentage int) error {
+	ctx, span := startTrace(ctx, "WriteCandidateAuthorizationModel")
+	defer span.End()
+
+	var err error
+	if modelID == "" {
+		_, err = s.stbl.
+			Delete("candidate_authorization_model").
+			Where(sq.Eq{"store": store}).
+			ExecContext(ctx)
+	} else {
+		_, err = s.stbl.
+			Insert("candidate_authorization_model").
+			Columns("store", "authorization_model_id", "sample_percentage", "created_at").
+			Values(store, modelID, samplePercentage, sq.Expr("NOW()")).
+			Suffix("ON DUPLICATE KEY UPDATE authorization_model_id = ?, sample_percentage = ?, created_at = NOW(), "+
+				"check_evaluated = 0, check_diverged = 0, check_errored = 0, "+
+				"list_objects_evaluated = 0, list_objects_diverged = 0, list_objects_errored = 0", modelID, samplePercentage).
+			ExecContext(ctx)
+	}
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	return nil
+}
+
+// ReadCandidateAuthorizationModel see [storage.CandidateModelBackend].ReadCandidateAuthorizationModel.
+func (s *Datastore) ReadCandidateAuthorizationModel(ctx context.Context, store string) (*storage.CandidateAuthorizationModel, error) {
+	ctx, span := startTrace(ctx, "ReadCandidateAuthorizationModel")
+	defer span.End()
+
+	var candidate storage.CandidateAuthorizationModel
+	err := s.stbl.
+		Select(
+			"authorization_model_id", "sample_percentage", "created_at",
+			"check_evaluated", "check_diverged", "check_errored",
+			"list_objects_evaluated", "list_objects_diverged", "list_objects_errored",
+		).
+		From("candidate_authorization_model").
+		Where(sq.Eq{"store": store}).
+		QueryRowContext(ctx).
+		Scan(
+			&candidate.ModelID, &candidate.SamplePercentage, &candidate.Since,
+			&candidate.Check.Evaluated, &candidate.Check.Diverged, &candidate.Check.Errored,
+			&candidate.ListObjects.Evaluated, &candidate.ListObjects.Diverged, &candidate.ListObjects.Errored,
+		)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+
+	candidate.Since = candidate.Since.UTC()
+	return &candidate, nil
+}
+
+// AddCandidateAuthorizationModelCounts see [storage.CandidateModelBackend].AddCandidateAuthorizationModelCounts.
+func (s *Datastore) AddCandidateAuthorizationModelCounts(ctx context.Context, store, modelID string, check, listObjects storage.CandidateModelCounts) error {
+	ctx, span := startTrace(ctx, "AddCandidateAuthorizationModelCounts")
+	defer span.End()
+
+	_, err := s.stbl.
+		Update("candidate_authorization_model").
+		Set("check_evaluated", sq.Expr("check_evaluated + ?", check.Evaluated)).
+		Set("check_diverged", sq.Expr("check_diverged + ?", check.Diverged)).
+		Set("check_errored", sq.Expr("check_errored + ?", check.Errored)).
+		Set("list_objects_evaluated", sq.Expr("list_objects_evaluated + ?", listObjects.Evaluated)).
+		Set("list_objects_diverged", sq.Expr("list_objects_diverged + ?", listObjects.Diverged)).
+		Set("list_objects_errored", sq.Expr("list_objects_errored + ?", listObjects.Errored)).
+		Where(sq.Eq{"store": store, "authorization_model_id": modelID}).
+		ExecContext(ctx)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	return nil
+}
+
 // ReadChanges see [storage.ChangelogBackend].ReadChanges.
 func (s *Datastore) ReadChanges(ctx context.Context, store string, filter storage.ReadChangesFilter, options storage.ReadChangesOptions) ([]*openfgav1.TupleChange, string, error) {
 	ctx, span := startTrace(ctx, "ReadChanges")
@@ -801,7 +997,7 @@ func (s *Datastore) ReadChanges(ctx context.Context, store string, filter storag
 
 // IsReady see [sqlcommon.IsReady].
 func (s *Datastore) IsReady(ctx context.Context) (storage.ReadinessStatus, error) {
-	versionReady, err := sqlcommon.IsReady(ctx, s.versionReady, s.db)
+	versionReady, err := sqlcommon.IsReady(ctx, s.versionReady, s.db, build.MinimumSupportedMySQLSchemaRevision)
 	if err != nil {
 		return versionReady, err
 	}
@@ -25,6 +25,7 @@ import (
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 
+	"github.com/openfga/openfga/internal/build"
 	"github.com/openfga/openfga/pkg/logger"
 	"github.com/openfga/openfga/pkg/storage"
 	"github.com/openfga/openfga/pkg/storage/sqlcommon"
@@ -704,6 +705,14 @@ func (s *Datastore) ReadStartingWithUser(
 	if filter.ObjectIDs != nil && filter.ObjectIDs.Size() > 0 {
 		builder = builder.Where(sq.Eq{"object_id": filter.ObjectIDs.Values()})
 	}
+
+	if filter.ObjectIDPrefix != "" {
+		builder = builder.Where(sq.Expr("object_id LIKE ? ESCAPE '!'", sqlcommon.LikePrefixPattern(filter.ObjectIDPrefix)))
+	}
+
+	if filter.ObjectIDAfter != "" {
+		builder = builder.Where(sq.Expr("object_id collate \"C\" > ?", filter.ObjectIDAfter))
+	}
 	if len(filter.Conditions) > 0 {
 		builder = builder.Where(sq.Eq{"COALESCE(condition_name, '')": filter.Conditions})
 	}
@@ -885,6 +894,68 @@ func (s *Datastore) WriteAuthorizationModel(ctx context.Context, store string, m
 	return nil
 }
 
+// ReadActiveAuthorizationModelID see [storage.AuthorizationModelReadBackend].ReadActiveAuthorizationModelID.
+func (s *Datastore) ReadActiveAuthorizationModelID(ctx context.Context, store string) (string, error) {
+	ctx, span := startTrace(ctx, "ReadActiveAuthorizationModelID")
+	defer span.End()
+
+	// the pinned model is read from the primary, so that it is not served from a lagging replica right after it
+	// is changed
+	db := s.getPgxPool(openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY)
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select("authorization_model_id").
+		From("active_authorization_model").
+		Where(sq.Eq{"store": store}).
+		ToSql()
+	if err != nil {
+		return "", HandleSQLError(err)
+	}
+
+	var modelID string
+	err = db.QueryRow(ctx, stmt, args...).Scan(&modelID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", storage.ErrNotFound
+		}
+		return "", HandleSQLError(err)
+	}
+
+	return modelID, nil
+}
+
+// WriteActiveAuthorizationModelID see [storage.TypeDefinitionWriteBackend].WriteActiveAuthorizationModelID.
+func (s *Datastore) WriteActiveAuthorizationModelID(ctx context.Context, store, modelID string) error {
+	ctx, span := startTrace(ctx, "WriteActiveAuthorizationModelID")
+	defer span.End()
+
+	var stmt string
+	var args []interface{}
+	var err error
+	if modelID == "" {
+		stmt, args, err = sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+			Delete("active_authorization_model").
+			Where(sq.Eq{"store": store}).
+			ToSql()
+	} else {
+		stmt, args, err = sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+			Insert("active_authorization_model").
+			Columns("store", "authorization_model_id", "updated_at").
+			Values(store, modelID, sq.Expr("NOW()")).
+			Suffix("ON CONFLICT (store) DO UPDATE SET authorization_model_id = ?, updated_at = NOW()", modelID).
+			ToSql()
+	}
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	_, err = s.primaryDB.Exec(ctx, stmt, args...)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	return nil
+}
+
 // CreateStore adds a new store to storage.
 func (s *Datastore) CreateStore(ctx context.Context, store *openfgav1.Store) (*openfgav1.Store, error) {
 	ctx, span := startTrace(ctx, "CreateStore")
@@ -1116,6 +1187,186 @@ func (s *Datastore) ReadAssertions(ctx context.Context, store, modelID string) (
 	return assertions.GetAssertions(), nil
 }
 
+// WriteCandidateAuthorizationModel see [storage.CandidateModelBackend].WriteCandidateAuthorizationModel.
+func (s *Datastore) WriteCandidateAuthorizationModel(ctx context.Context, store, modelID string, samplePercentage int) error {
+	ctx, span := startTrace(ctx, "WriteCandidateAuthorizationModel")
+	defer span.End()
+
+	var stmt string
+	var args []interface{}
+	var err error
+	if modelID == "" {
+		stmt, args, err = sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+			Delete("candidate_authorization_model").
+			Where(sq.Eq{"store": store}).
+			ToSql()
+	} else {
+		stmt, args, err = sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+			Insert("candidate_authorization_model").
+			Columns("store", "authorization_model_id", "sample_percentage", "created_at").
+			Values(store, modelID, samplePercentage, sq.Expr("NOW()")).
+			Suffix("ON CONFLICT (store) DO UPDATE SET authorization_model_id = ?, sample_percentage = ?, created_at = NOW(), "+
+				"check_evaluated = 0, check_diverged = 0, check_errored = 0, "+
+				"list_objects_evaluated = 0, list_objects_diverged = 0, list_objects_errored = 0", modelID, samplePercentage).
+			ToSql()
+	}
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	_, err = s.primaryDB.Exec(ctx, stmt, args...)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	return nil
+}
+
+// ReadCandidateAuthorizationModel see [storage.CandidateModelBackend].ReadCandidateAuthorizationModel.
+func (s *Datastore) ReadCandidateAuthorizationModel(ctx context.Context, store string) (*storage.CandidateAuthorizationModel, error) {
+	ctx, span := startTrace(ctx, "ReadCandidateAuthorizationModel")
+	defer span.End()
+
+	// the candidate model is read from the primary, so that it is not served from a lagging replica right after it
+	// is changed
+	db := s.getPgxPool(openfgav1.ConsistencyPreference_HIGHER_CONSISTENCY)
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select(
+			"authorization_model_id", "sample_percentage", "created_at",
+			"check_evaluated", "check_diverged", "check_errored",
+			"list_objects_evaluated", "list_objects_diverged", "list_objects_errored",
+		).
+		From("candidate_authorization_model").
+		Where(sq.Eq{"store": store}).
+		ToSql()
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+
+	var candidate storage.CandidateAuthorizationModel
+	err = db.QueryRow(ctx, stmt, args...).Scan(
+		&candidate.ModelID, &candidate.SamplePercentage, &candidate.Since,
+		&candidate.Check.Evaluated, &candidate.Check.Diverged, &candidate.Check.Errored,
+		&candidate.ListObjects.Evaluated, &candidate.ListObjects.Diverged, &candidate.ListObjects.Errored,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, storage.ErrNotFound
+		}
+		return nil, HandleSQLError(err)
+	}
+
+	candidate.Since = candidate.Since.UTC()
+	return &candidate, nil
+}
+
+// AddCandidateAuthorizationModelCounts see [storage.CandidateModelBackend].AddCandidateAuthorizationModelCounts.
+func (s *Datastore) AddCandidateAuthorizationModelCounts(ctx context.Context, store, modelID string, check, listObjects storage.CandidateModelCounts) error {
+	ctx, span := startTrace(ctx, "AddCandidateAuthorizationModelCounts")
+	defer span.End()
+
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Update("candidate_authorization_model").
+		Set("check_evaluated", sq.Expr("check_evaluated + ?", check.Evaluated)).
+		Set("check_diverged", sq.Expr("check_diverged + ?", check.Diverged)).
+		Set("check_errored", sq.Expr("check_errored + ?", check.Errored)).
+		Set("list_objects_evaluated", sq.Expr("list_objects_evaluated + ?", listObjects.Evaluated)).
+		Set("list_objects_diverged", sq.Expr("list_objects_diverged + ?", listObjects.Diverged)).
+		Set("list_objects_errored", sq.Expr("list_objects_errored + ?", listObjects.Errored)).
+		Where(sq.Eq{"store": store, "authorization_model_id": modelID}).
+		ToSql()
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	_, err = s.primaryDB.Exec(ctx, stmt, args...)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	return nil
+}
+
+// WritePlannerSnapshot see [storage.PlannerSnapshotBackend].WritePlannerSnapshot.
+func (s *Datastore) WritePlannerSnapshot(ctx context.Context, replicaID string, snapshot []byte) error {
+	ctx, span := startTrace(ctx, "WritePlannerSnapshot")
+	defer span.End()
+
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Insert("planner_snapshot").
+		Columns("replica_id", "snapshot", "updated_at").
+		Values(replicaID, snapshot, sq.Expr("NOW()")).
+		Suffix("ON CONFLICT (replica_id) DO UPDATE SET snapshot = ?, updated_at = NOW()", snapshot).
+		ToSql()
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	_, err = s.primaryDB.Exec(ctx, stmt, args...)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	return nil
+}
+
+// ReadPlannerSnapshots see [storage.PlannerSnapshotBackend].ReadPlannerSnapshots.
+func (s *Datastore) ReadPlannerSnapshots(ctx context.Context) (map[string][]byte, error) {
+	ctx, span := startTrace(ctx, "ReadPlannerSnapshots")
+	defer span.End()
+
+	db := s.getPgxPool(openfgav1.ConsistencyPreference_MINIMIZE_LATENCY)
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select("replica_id", "snapshot").
+		From("planner_snapshot").
+		ToSql()
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+
+	rows, err := db.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+	defer rows.Close()
+
+	snapshots := map[string][]byte{}
+	for rows.Next() {
+		var replicaID string
+		var snapshot []byte
+		if err := rows.Scan(&replicaID, &snapshot); err != nil {
+			return nil, HandleSQLError(err)
+		}
+		snapshots[replicaID] = snapshot
+	}
+	if err := rows.Err(); err != nil {
+		return nil, HandleSQLError(err)
+	}
+
+	return snapshots, nil
+}
+
+// DeletePlannerSnapshots see [storage.PlannerSnapshotBackend].DeletePlannerSnapshots.
+func (s *Datastore) DeletePlannerSnapshots(ctx context.Context, olderThan time.Duration) error {
+	ctx, span := startTrace(ctx, "DeletePlannerSnapshots")
+	defer span.End()
+
+	stmt, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Delete("planner_snapshot").
+		Where(sq.Expr("updated_at < NOW() - make_interval(secs => ?)", olderThan.Seconds())).
+		ToSql()
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	_, err = s.primaryDB.Exec(ctx, stmt, args...)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	return nil
+}
+
 // ReadChanges see [storage.ChangelogBackend].ReadChanges.
 func (s *Datastore) ReadChanges(ctx context.Context, store string, filter storage.ReadChangesFilter, options storage.ReadChangesOptions) ([]*openfgav1.TupleChange, string, error) {
 	ctx, span := startTrace(ctx, "ReadChanges")
@@ -1231,7 +1482,7 @@ func isDBReady(ctx context.Context, versionReady bool, db *pgxpool.Pool) (storag
 	defer func() {
 		_ = sqlDB.Close()
 	}()
-	return sqlcommon.IsVersionReady(ctx, versionReady, sqlDB)
+	return sqlcommon.IsVersionReady(ctx, versionReady, sqlDB, build.MinimumSupportedPostgresSchemaRevision)
 }
 
 // IsReady see [sqlcommon.IsReady].
@@ -21,7 +21,6 @@ import (
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 
-	"github.com/openfga/openfga/internal/build"
 	"github.com/openfga/openfga/pkg/encoder"
 	"github.com/openfga/openfga/pkg/logger"
 	"github.com/openfga/openfga/pkg/storage"
@@ -1112,9 +1111,9 @@ func ReadAuthorizationModel(
 	return ret, nil
 }
 
-// IsVersionReady checks if the database schema revision is at least the minimum supported revision.
+// IsVersionReady checks if the database schema revision is at least minimumRevision.
 // The passed in context should have a timeout.
-func IsVersionReady(ctx context.Context, skipVersionCheck bool, db *sql.DB) (storage.ReadinessStatus, error) {
+func IsVersionReady(ctx context.Context, skipVersionCheck bool, db *sql.DB, minimumRevision int64) (storage.ReadinessStatus, error) {
 	if skipVersionCheck {
 		return storage.ReadinessStatus{
 			IsReady: true,
@@ -1126,12 +1125,12 @@ func IsVersionReady(ctx context.Context, skipVersionCheck bool, db *sql.DB) (sto
 		return storage.ReadinessStatus{}, err
 	}
 
-	if revision < build.MinimumSupportedDatastoreSchemaRevision {
+	if revision < minimumRevision {
 		return storage.ReadinessStatus{
 			Message: "datastore requires migrations: at revision '" +
 				strconv.FormatInt(revision, 10) +
 				"', but requires '" +
-				strconv.FormatInt(build.MinimumSupportedDatastoreSchemaRevision, 10) +
+				strconv.FormatInt(minimumRevision, 10) +
 				"'. Run 'openfga migrate'.",
 			IsReady: false,
 		}, nil
@@ -1142,8 +1141,8 @@ func IsVersionReady(ctx context.Context, skipVersionCheck bool, db *sql.DB) (sto
 }
 
 // IsReady returns true if connection to datastore is successful AND
-// (the datastore has the latest migration applied OR skipVersionCheck).
-func IsReady(ctx context.Context, skipVersionCheck bool, db *sql.DB) (storage.ReadinessStatus, error) {
+// (the datastore has at least the minimumRevision migration applied OR skipVersionCheck).
+func IsReady(ctx context.Context, skipVersionCheck bool, db *sql.DB, minimumRevision int64) (storage.ReadinessStatus, error) {
 	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
 	defer cancel()
 
@@ -1152,7 +1151,7 @@ func IsReady(ctx context.Context, skipVersionCheck bool, db *sql.DB) (storage.Re
 	if pingErr := db.PingContext(ctx); pingErr != nil {
 		return storage.ReadinessStatus{}, pingErr
 	}
-	return IsVersionReady(ctx, skipVersionCheck, db)
+	return IsVersionReady(ctx, skipVersionCheck, db, minimumRevision)
 }
 
 func AddFromUlid(sb sq.SelectBuilder, fromUlid string, sortDescending bool) sq.SelectBuilder {
@@ -1161,3 +1160,11 @@ func AddFromUlid(sb sq.SelectBuilder, fromUlid string, sortDescending bool) sq.S
 	}
 	return sb.Where(sq.Gt{"ulid": fromUlid})
 }
+
+// likeEscaper escapes the wildcards of a LIKE pattern, and its escape character '!'.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// LikePrefixPattern returns the pattern of a LIKE ... ESCAPE '!' that matches the strings starting with prefix.
+func LikePrefixPattern(prefix string) string {
+	return likeEscaper.Replace(prefix) + "%"
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
@@ -298,6 +299,13 @@ type ReadStartingWithUserFilter struct {
 	// The datastore should return the intersection between this filter and what is in the database.
 	ObjectIDs SortedSet
 
+	// Optional. If not empty, only the tuples whose object ID starts with it are returned. It combines with ObjectIDs.
+	ObjectIDPrefix string
+
+	// Optional. If not empty, only the tuples whose object ID is ordered after it, byte-wise, are returned. It
+	// combines with ObjectIDs and ObjectIDPrefix.
+	ObjectIDAfter string
+
 	// Optional. It can be nil. If present, it will be used to filter the results. Conditions can hold the empty value
 	Conditions []string
 }
@@ -342,6 +350,25 @@ type AuthorizationModelReadBackend interface {
 	// FindLatestAuthorizationModel returns the last model for the store.
 	// If none were ever written, it must return ErrNotFound.
 	FindLatestAuthorizationModel(ctx context.Context, store string) (*openfgav1.AuthorizationModel, error)
+
+	// ReadActiveAuthorizationModelID returns the ID of the model pinned as the active model of the store, which
+	// is the model used by the requests that do not specify one.
+	// If no model is pinned, it must return ErrNotFound, and the latest model is the active one.
+	ReadActiveAuthorizationModelID(ctx context.Context, store string) (string, error)
+}
+
+// FindActiveAuthorizationModel returns the active model of the store, i.e. the model pinned with
+// [TypeDefinitionWriteBackend].WriteActiveAuthorizationModelID, or the latest model if none is pinned.
+// If the store has no model, it returns ErrNotFound.
+func FindActiveAuthorizationModel(ctx context.Context, backend AuthorizationModelReadBackend, store string) (*openfgav1.AuthorizationModel, error) {
+	modelID, err := backend.ReadActiveAuthorizationModelID(ctx, store)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return backend.FindLatestAuthorizationModel(ctx, store)
+	case err != nil:
+		return nil, err
+	}
+	return backend.ReadAuthorizationModel(ctx, store, modelID)
 }
 
 // TypeDefinitionWriteBackend provides a write interface for managing typed definition.
@@ -352,6 +379,11 @@ type TypeDefinitionWriteBackend interface {
 	// WriteAuthorizationModel writes an authorization model for the given store.
 	// If the model has zero types, the datastore may choose to do nothing and return no error.
 	WriteAuthorizationModel(ctx context.Context, store string, model *openfgav1.AuthorizationModel) error
+
+	// WriteActiveAuthorizationModelID pins the model as the active model of the store, overwriting the previous one.
+	// An empty model ID unpins the active model, so that the latest model is the active one again.
+	// The datastore does not check that the model exists.
+	WriteActiveAuthorizationModelID(ctx context.Context, store, modelID string) error
 }
 
 // AuthorizationModelBackend provides an read/write interface for managing models and their type definitions.
@@ -387,6 +419,56 @@ type AssertionsBackend interface {
 	ReadAssertions(ctx context.Context, store, modelID string) ([]*openfgav1.Assertion, error)
 }
 
+// PlannerSnapshotBackend persists what the planner of each replica of the server learned, so that it survives
+// restarts and is shared by the replicas. Snapshots are opaque to the datastore.
+type PlannerSnapshotBackend interface {
+	// WritePlannerSnapshot stores the snapshot as the latest snapshot of the replica, overwriting the previous one.
+	WritePlannerSnapshot(ctx context.Context, replicaID string, snapshot []byte) error
+
+	// ReadPlannerSnapshots returns the latest snapshot of every replica, keyed by replica ID.
+	// If no snapshots were ever written, it must return an empty map.
+	ReadPlannerSnapshots(ctx context.Context) (map[string][]byte, error)
+
+	// DeletePlannerSnapshots deletes the snapshots that were last written more than olderThan ago, as measured by
+	// the clock of the datastore.
+	DeletePlannerSnapshots(ctx context.Context, olderThan time.Duration) error
+}
+
+// CandidateModelCounts holds the number of requests evaluated against a candidate model, and how many of them
+// diverged from the active model or errored.
+type CandidateModelCounts struct {
+	Evaluated uint64
+	Diverged  uint64
+	Errored   uint64
+}
+
+// CandidateAuthorizationModel is the candidate model of a store, and the counts of the requests evaluated against it
+// since it was set.
+type CandidateAuthorizationModel struct {
+	ModelID          string
+	SamplePercentage int
+	// Since is when the candidate model was set, as measured by the clock of the datastore.
+	Since       time.Time
+	Check       CandidateModelCounts
+	ListObjects CandidateModelCounts
+}
+
+// CandidateModelBackend persists the candidate authorization model of each store and the counts of its report, so
+// that they are shared by the replicas of the server and survive restarts.
+type CandidateModelBackend interface {
+	// WriteCandidateAuthorizationModel sets the candidate model of the store, with zero counts, overwriting the
+	// previous one and its counts. An empty model ID removes the candidate model of the store.
+	WriteCandidateAuthorizationModel(ctx context.Context, store, modelID string, samplePercentage int) error
+
+	// ReadCandidateAuthorizationModel returns the candidate model of the store and its counts.
+	// If the store has no candidate model, it must return ErrNotFound.
+	ReadCandidateAuthorizationModel(ctx context.Context, store string) (*CandidateAuthorizationModel, error)
+
+	// AddCandidateAuthorizationModelCounts adds the counts to the ones of the candidate model of the store, if it is
+	// still modelID. Otherwise, it does nothing.
+	AddCandidateAuthorizationModelCounts(ctx context.Context, store, modelID string, check, listObjects CandidateModelCounts) error
+}
+
 type ReadChangesFilter struct {
 	ObjectType    string
 	HorizonOffset time.Duration
@@ -412,6 +494,8 @@ type OpenFGADatastore interface {
 	StoresBackend
 	AssertionsBackend
 	ChangelogBackend
+	PlannerSnapshotBackend
+	CandidateModelBackend
 
 	// IsReady reports whether the datastore is ready to accept traffic.
 	IsReady(ctx context.Context) (ReadinessStatus, error)
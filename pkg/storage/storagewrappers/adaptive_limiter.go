@@ -0,0 +1,189 @@
+package storagewrappers
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+const (
+	// DefaultAdaptiveConcurrencyInitialLimit is the limit an AdaptiveConcurrencyLimiter starts at.
+	DefaultAdaptiveConcurrencyInitialLimit = 10
+	// DefaultAdaptiveConcurrencyMinLimit is the lowest concurrency limit of an AdaptiveConcurrencyLimiter.
+	DefaultAdaptiveConcurrencyMinLimit = 1
+	// DefaultAdaptiveConcurrencyLatencyThreshold is the latency of the datastore reads above which an
+	// AdaptiveConcurrencyLimiter lowers its limit.
+	DefaultAdaptiveConcurrencyLatencyThreshold = 50 * time.Millisecond
+	// DefaultAdaptiveConcurrencyBackoffRatio is the ratio by which an AdaptiveConcurrencyLimiter lowers its limit.
+	DefaultAdaptiveConcurrencyBackoffRatio = 0.9
+)
+
+var adaptiveConcurrencyLimitGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: build.ProjectName,
+	Name:      "datastore_adaptive_concurrency_limit",
+	Help:      "The current limit of concurrent datastore reads per request, as adapted to the latency and errors of the datastore, labeled by method.",
+}, []string{"method"})
+
+// AdaptiveConcurrencyLimiter adapts the number of concurrent datastore reads of each request of a method to the
+// latency and errors of the datastore, with an additive-increase/multiplicative-decrease (AIMD) algorithm.
+//
+// It is shared by all the requests of a method: every read that is slower than the latency threshold, or fails
+// because the datastore is overloaded, lowers the limit by the backoff ratio, and every other read raises it so
+// that it grows by one once as many reads as the limit succeed. The limit never exceeds the static limit of the
+// method, and never goes below the minimum limit.
+type AdaptiveConcurrencyLimiter struct {
+	method           string
+	initialLimit     float64
+	minLimit         float64
+	maxLimit         float64
+	latencyThreshold time.Duration
+	backoffRatio     float64
+
+	mu    sync.Mutex
+	limit float64
+	// lastDecrease is when the limit was last lowered. The reads that were in flight at the time don't lower it
+	// again, so that a burst of slow reads lowers it only once.
+	lastDecrease time.Time
+}
+
+type AdaptiveConcurrencyLimiterOption func(*AdaptiveConcurrencyLimiter)
+
+// WithAdaptiveConcurrencyInitialLimit sets the limit of concurrent reads per request that the limiter starts at.
+func WithAdaptiveConcurrencyInitialLimit(limit uint32) AdaptiveConcurrencyLimiterOption {
+	return func(l *AdaptiveConcurrencyLimiter) {
+		l.initialLimit = float64(limit)
+	}
+}
+
+// WithAdaptiveConcurrencyMinLimit sets the lowest limit of concurrent reads per request.
+func WithAdaptiveConcurrencyMinLimit(limit uint32) AdaptiveConcurrencyLimiterOption {
+	return func(l *AdaptiveConcurrencyLimiter) {
+		l.minLimit = float64(limit)
+	}
+}
+
+// WithAdaptiveConcurrencyLatencyThreshold sets the latency of the datastore reads above which the limit is lowered.
+func WithAdaptiveConcurrencyLatencyThreshold(threshold time.Duration) AdaptiveConcurrencyLimiterOption {
+	return func(l *AdaptiveConcurrencyLimiter) {
+		l.latencyThreshold = threshold
+	}
+}
+
+// WithAdaptiveConcurrencyBackoffRatio sets the ratio, between 0 and 1, by which the limit is lowered.
+func WithAdaptiveConcurrencyBackoffRatio(ratio float64) AdaptiveConcurrencyLimiterOption {
+	return func(l *AdaptiveConcurrencyLimiter) {
+		l.backoffRatio = ratio
+	}
+}
+
+// NewAdaptiveConcurrencyLimiter returns a limiter of the concurrent reads of the requests of method, which never
+// exceeds maxLimit.
+func NewAdaptiveConcurrencyLimiter(method string, maxLimit uint32, opts ...AdaptiveConcurrencyLimiterOption) *AdaptiveConcurrencyLimiter {
+	l := &AdaptiveConcurrencyLimiter{
+		method:           method,
+		initialLimit:     DefaultAdaptiveConcurrencyInitialLimit,
+		minLimit:         DefaultAdaptiveConcurrencyMinLimit,
+		maxLimit:         float64(maxLimit),
+		latencyThreshold: DefaultAdaptiveConcurrencyLatencyThreshold,
+		backoffRatio:     DefaultAdaptiveConcurrencyBackoffRatio,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	l.minLimit = math.Max(1, math.Min(l.minLimit, l.maxLimit))
+	l.maxLimit = math.Max(l.minLimit, l.maxLimit)
+	l.limit = math.Max(l.minLimit, math.Min(l.initialLimit, l.maxLimit))
+	adaptiveConcurrencyLimitGauge.WithLabelValues(method).Set(l.limit)
+	return l
+}
+
+// Limit returns the current limit of concurrent reads per request.
+func (l *AdaptiveConcurrencyLimiter) Limit() uint32 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return uint32(l.limit)
+}
+
+// Observe adapts the limit to a read that started at start and returned err. Reads that are delayed by the
+// datastore throttling (see Operation.ThrottlingEnabled) don't raise the limit, since their request is already
+// slowed down on purpose.
+func (l *AdaptiveConcurrencyLimiter) Observe(start time.Time, err error, throttled bool) {
+	latency := time.Since(start)
+	if errors.Is(err, context.Canceled) {
+		// the request was canceled, the read tells nothing about the datastore
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	overloaded := errors.Is(err, context.DeadlineExceeded) || errors.Is(err, storage.ErrTransactionThrottled)
+	switch {
+	case overloaded || latency > l.latencyThreshold:
+		if start.Before(l.lastDecrease) {
+			return
+		}
+		l.limit = math.Max(l.minLimit, l.limit*l.backoffRatio)
+		l.lastDecrease = time.Now()
+	case err == nil && !throttled:
+		l.limit = math.Min(l.maxLimit, l.limit+1/l.limit)
+	default:
+		return
+	}
+	adaptiveConcurrencyLimitGauge.WithLabelValues(l.method).Set(math.Floor(l.limit))
+}
+
+// adaptiveSemaphore bounds the concurrent reads of a request to the current limit of an AdaptiveConcurrencyLimiter.
+type adaptiveSemaphore struct {
+	limiter *AdaptiveConcurrencyLimiter
+
+	mu       sync.Mutex
+	inFlight uint32
+	// released is closed, and replaced, every time a read of the request completes
+	released chan struct{}
+}
+
+func newAdaptiveSemaphore(limiter *AdaptiveConcurrencyLimiter) *adaptiveSemaphore {
+	return &adaptiveSemaphore{
+		limiter:  limiter,
+		released: make(chan struct{}),
+	}
+}
+
+func (s *adaptiveSemaphore) acquire(ctx context.Context) error {
+	for {
+		s.mu.Lock()
+		if s.inFlight < s.limiter.Limit() {
+			s.inFlight++
+			s.mu.Unlock()
+			return nil
+		}
+		released := s.released
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-released:
+		}
+	}
+}
+
+func (s *adaptiveSemaphore) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inFlight == 0 {
+		return
+	}
+	s.inFlight--
+	close(s.released)
+	s.released = make(chan struct{})
+}
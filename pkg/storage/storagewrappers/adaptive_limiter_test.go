@@ -0,0 +1,211 @@
+package storagewrappers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/sync/errgroup"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/internal/mocks"
+	"github.com/openfga/openfga/internal/utils/apimethod"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestAdaptiveConcurrencyLimiter(t *testing.T) {
+	newLimiter := func() *AdaptiveConcurrencyLimiter {
+		return NewAdaptiveConcurrencyLimiter("test", 20,
+			WithAdaptiveConcurrencyInitialLimit(10),
+			WithAdaptiveConcurrencyMinLimit(2),
+			WithAdaptiveConcurrencyLatencyThreshold(time.Hour),
+			WithAdaptiveConcurrencyBackoffRatio(0.5),
+		)
+	}
+	slow := time.Now().Add(-2 * time.Hour)
+
+	t.Run("starts_at_initial_limit_within_bounds", func(t *testing.T) {
+		require.Equal(t, uint32(10), newLimiter().Limit())
+		require.Equal(t, uint32(5), NewAdaptiveConcurrencyLimiter("test", 5, WithAdaptiveConcurrencyInitialLimit(10)).Limit())
+		require.Equal(t, uint32(1), NewAdaptiveConcurrencyLimiter("test", 5, WithAdaptiveConcurrencyInitialLimit(0)).Limit())
+	})
+
+	t.Run("fast_reads_raise_the_limit_up_to_the_max", func(t *testing.T) {
+		l := newLimiter()
+		// each read raises the limit by 1/limit, so that it grows by one after about as many reads as the limit
+		for range 11 {
+			l.Observe(time.Now(), nil, false)
+		}
+		require.Equal(t, uint32(11), l.Limit())
+
+		for range 1000 {
+			l.Observe(time.Now(), nil, false)
+		}
+		require.Equal(t, uint32(20), l.Limit())
+	})
+
+	t.Run("slow_reads_lower_the_limit_once_per_burst", func(t *testing.T) {
+		l := newLimiter()
+		// both reads were in flight when the limit was lowered
+		l.Observe(slow, nil, false)
+		l.Observe(slow, nil, false)
+		require.Equal(t, uint32(5), l.Limit())
+
+		l.Observe(time.Now().Add(-2*time.Hour+time.Second), nil, false)
+		require.Equal(t, uint32(5), l.Limit())
+
+		time.Sleep(time.Millisecond)
+		l.Observe(time.Now(), storage.ErrTransactionThrottled, false)
+		require.Equal(t, uint32(2), l.Limit())
+
+		time.Sleep(time.Millisecond)
+		l.Observe(time.Now(), context.DeadlineExceeded, false)
+		require.Equal(t, uint32(2), l.Limit())
+	})
+
+	t.Run("throttled_failed_and_canceled_reads_do_not_raise_the_limit", func(t *testing.T) {
+		l := newLimiter()
+		for range 100 {
+			l.Observe(time.Now(), nil, true)
+			l.Observe(time.Now(), errors.New("boom"), false)
+			l.Observe(slow, context.Canceled, false)
+		}
+		require.Equal(t, uint32(10), l.Limit())
+	})
+}
+
+func TestBoundedWrapperWithAdaptiveConcurrencyLimiter(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	store := ulid.Make().String()
+	slowBackend := mocks.NewMockSlowDataStorage(memory.New(), 100*time.Millisecond)
+	t.Cleanup(slowBackend.Close)
+	require.NoError(t, slowBackend.Write(context.Background(), store, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("obj:1", "viewer", "user:anne"),
+	}))
+
+	t.Run("bounded_by_the_current_limit", func(t *testing.T) {
+		limiter := NewAdaptiveConcurrencyLimiter(apimethod.Check.String(), 2,
+			WithAdaptiveConcurrencyInitialLimit(2),
+			WithAdaptiveConcurrencyLatencyThreshold(time.Hour),
+		)
+		reader := NewBoundedTupleReader(slowBackend, &Operation{Method: apimethod.Check, Limiter: limiter})
+
+		var wg errgroup.Group
+		start := time.Now()
+		for range 4 {
+			wg.Go(func() error {
+				_, err := reader.ReadUserTuple(context.Background(), store, storage.ReadUserTupleFilter{Object: "obj:1", Relation: "viewer", User: "user:anne"}, storage.ReadUserTupleOptions{})
+				return err
+			})
+		}
+		require.NoError(t, wg.Wait())
+		// 4 reads, 2 at a time
+		require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+		require.Equal(t, uint32(4), reader.GetMetadata().DatastoreQueryCount)
+	})
+
+	t.Run("slow_reads_lower_the_limit", func(t *testing.T) {
+		limiter := NewAdaptiveConcurrencyLimiter(apimethod.Check.String(), 10,
+			WithAdaptiveConcurrencyLatencyThreshold(time.Millisecond),
+			WithAdaptiveConcurrencyBackoffRatio(0.5),
+		)
+		reader := NewBoundedTupleReader(slowBackend, &Operation{Method: apimethod.Check, Limiter: limiter})
+
+		_, err := reader.ReadUserTuple(context.Background(), store, storage.ReadUserTupleFilter{Object: "obj:1", Relation: "viewer", User: "user:anne"}, storage.ReadUserTupleOptions{})
+		require.NoError(t, err)
+		require.Equal(t, uint32(5), limiter.Limit())
+	})
+
+	t.Run("lazy_iterators_are_observed_when_read", func(t *testing.T) {
+		mockController := gomock.NewController(t)
+		t.Cleanup(mockController.Finish)
+
+		// like the SQL datastores, the query only runs on the first read of the iterator
+		mockReader := mocks.NewMockRelationshipTupleReader(mockController)
+		mockReader.EXPECT().Read(gomock.Any(), store, gomock.Any(), gomock.Any()).
+			Return(&lazyTupleIterator{delay: 20 * time.Millisecond, errs: []error{nil, storage.ErrTransactionThrottled}}, nil)
+
+		limiter := NewAdaptiveConcurrencyLimiter(apimethod.Check.String(), 10,
+			WithAdaptiveConcurrencyLatencyThreshold(10*time.Millisecond),
+			WithAdaptiveConcurrencyBackoffRatio(0.5),
+		)
+		reader := NewBoundedTupleReader(mockReader, &Operation{Method: apimethod.Check, Limiter: limiter})
+
+		itr, err := reader.Read(context.Background(), store, storage.ReadFilter{Object: "obj:1"}, storage.ReadOptions{})
+		require.NoError(t, err)
+		t.Cleanup(itr.Stop)
+		require.Equal(t, uint32(10), limiter.Limit())
+
+		_, err = itr.Next(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint32(5), limiter.Limit())
+
+		// the datastore errors of the next reads are observed too
+		_, err = itr.Next(context.Background())
+		require.ErrorIs(t, err, storage.ErrTransactionThrottled)
+		require.Equal(t, uint32(2), limiter.Limit())
+
+		_, err = itr.Next(context.Background())
+		require.ErrorIs(t, err, storage.ErrIteratorDone)
+		require.Equal(t, uint32(2), limiter.Limit())
+	})
+
+	t.Run("waiting_reads_exit_on_context_error", func(t *testing.T) {
+		limiter := NewAdaptiveConcurrencyLimiter(apimethod.Check.String(), 1, WithAdaptiveConcurrencyLatencyThreshold(time.Hour))
+		reader := NewBoundedTupleReader(slowBackend, &Operation{Method: apimethod.Check, Limiter: limiter})
+
+		var wg errgroup.Group
+		wg.Go(func() error {
+			_, err := reader.ReadUserTuple(context.Background(), store, storage.ReadUserTupleFilter{Object: "obj:1", Relation: "viewer", User: "user:anne"}, storage.ReadUserTupleOptions{})
+			return err
+		})
+		time.Sleep(10 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := reader.ReadUserTuple(ctx, store, storage.ReadUserTupleFilter{Object: "obj:1", Relation: "viewer", User: "user:anne"}, storage.ReadUserTupleOptions{})
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.NoError(t, wg.Wait())
+	})
+}
+
+// lazyTupleIterator returns a tuple, or the next error of errs, after delay on its first read, and ErrIteratorDone
+// once errs are exhausted.
+type lazyTupleIterator struct {
+	delay time.Duration
+	errs  []error
+	read  bool
+}
+
+func (itr *lazyTupleIterator) Next(ctx context.Context) (*openfgav1.Tuple, error) {
+	if !itr.read {
+		itr.read = true
+		time.Sleep(itr.delay)
+	}
+	if len(itr.errs) == 0 {
+		return nil, storage.ErrIteratorDone
+	}
+	err := itr.errs[0]
+	itr.errs = itr.errs[1:]
+	if err != nil {
+		return nil, err
+	}
+	return &openfgav1.Tuple{Key: tuple.NewTupleKey("obj:1", "viewer", "user:anne")}, nil
+}
+
+func (itr *lazyTupleIterator) Head(ctx context.Context) (*openfgav1.Tuple, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (itr *lazyTupleIterator) Stop() {}
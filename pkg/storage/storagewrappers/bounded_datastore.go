@@ -2,6 +2,7 @@ package storagewrappers
 
 import (
 	"context"
+	"errors"
 	"sync/atomic"
 	"time"
 
@@ -44,10 +45,48 @@ func (itr *countingTupleIterator) Next(ctx context.Context) (*openfgav1.Tuple, e
 	return i, nil
 }
 
+// observedTupleIterator reports the datastore query of a tuple iterator to the adaptive limiter when its first
+// result is read, since the SQL datastores only run the query then, and the datastore errors of the next reads.
+type observedTupleIterator struct {
+	storage.TupleIterator
+	reader *BoundedTupleReader
+	// built is the time it took to build the iterator, which is part of the query latency
+	built    time.Duration
+	observed atomic.Bool
+}
+
+func (itr *observedTupleIterator) Next(ctx context.Context) (*openfgav1.Tuple, error) {
+	start := time.Now()
+	t, err := itr.TupleIterator.Next(ctx)
+	itr.observe(start, err)
+	return t, err
+}
+
+func (itr *observedTupleIterator) Head(ctx context.Context) (*openfgav1.Tuple, error) {
+	start := time.Now()
+	t, err := itr.TupleIterator.Head(ctx)
+	itr.observe(start, err)
+	return t, err
+}
+
+func (itr *observedTupleIterator) observe(start time.Time, err error) {
+	if errors.Is(err, storage.ErrIteratorDone) {
+		err = nil
+	}
+	if itr.observed.CompareAndSwap(false, true) {
+		itr.reader.observe(start.Add(-itr.built), err)
+		return
+	}
+	if err != nil {
+		itr.reader.observe(start, err)
+	}
+}
+
 var (
 	_ storage.RelationshipTupleReader = (*BoundedTupleReader)(nil)
 	_ StorageInstrumentation          = (*BoundedTupleReader)(nil)
 	_ storage.TupleIterator           = (*countingTupleIterator)(nil)
+	_ storage.TupleIterator           = (*observedTupleIterator)(nil)
 
 	concurrentReadDelayMsHistogram = promauto.NewHistogramVec(prometheus.HistogramOpts{
 		Namespace:                       build.ProjectName,
@@ -72,7 +111,8 @@ var (
 
 type BoundedTupleReader struct {
 	storage.RelationshipTupleReader
-	limiter    chan struct{} // bound concurrency
+	limiter    chan struct{}      // bound concurrency
+	adaptive   *adaptiveSemaphore // bound concurrency to an adaptive limit, instead of limiter
 	countReads atomic.Uint32
 	countItems atomic.Uint64
 	method     string
@@ -86,10 +126,11 @@ type BoundedTupleReader struct {
 // NewBoundedTupleReader returns a wrapper over a datastore that makes sure that there are, at most,
 // "concurrency" concurrent calls to Read, ReadUserTuple and ReadUsersetTuples.
 // Consumers can then rest assured that one client will not hoard all the database connections available.
+// If the operation has a Limiter, the concurrency is bounded by its current limit instead, and every call is
+// reported to it.
 func NewBoundedTupleReader(wrapped storage.RelationshipTupleReader, op *Operation) *BoundedTupleReader {
-	return &BoundedTupleReader{
+	b := &BoundedTupleReader{
 		RelationshipTupleReader: wrapped,
-		limiter:                 make(chan struct{}, op.Concurrency),
 		countReads:              atomic.Uint32{},
 
 		method:            string(op.Method),
@@ -97,6 +138,12 @@ func NewBoundedTupleReader(wrapped storage.RelationshipTupleReader, op *Operatio
 		threshold:         op.ThrottleThreshold,
 		throttleTime:      op.ThrottleDuration,
 	}
+	if op.Limiter != nil {
+		b.adaptive = newAdaptiveSemaphore(op.Limiter)
+	} else {
+		b.limiter = make(chan struct{}, op.Concurrency)
+	}
+	return b
 }
 
 func (b *BoundedTupleReader) GetMetadata() Metadata {
@@ -120,7 +167,9 @@ func (b *BoundedTupleReader) ReadUserTuple(
 	}
 
 	defer b.done()
+	start := time.Now()
 	t, err := b.RelationshipTupleReader.ReadUserTuple(ctx, store, filter, options)
+	b.observe(start, err)
 	if t == nil || err != nil {
 		return t, err
 	}
@@ -136,11 +185,13 @@ func (b *BoundedTupleReader) Read(ctx context.Context, store string, filter stor
 	}
 
 	defer b.done()
+	start := time.Now()
 	itr, err := b.RelationshipTupleReader.Read(ctx, store, filter, options)
 	if itr == nil || err != nil {
+		b.observe(start, err)
 		return itr, err
 	}
-	return &countingTupleIterator{itr, &b.countItems}, nil
+	return b.wrapIterator(itr, start), nil
 }
 
 // ReadUsersetTuples returns all userset tuples for a specified object and relation.
@@ -156,11 +207,13 @@ func (b *BoundedTupleReader) ReadUsersetTuples(
 	}
 
 	defer b.done()
+	start := time.Now()
 	itr, err := b.RelationshipTupleReader.ReadUsersetTuples(ctx, store, filter, options)
 	if itr == nil || err != nil {
+		b.observe(start, err)
 		return itr, err
 	}
-	return &countingTupleIterator{itr, &b.countItems}, nil
+	return b.wrapIterator(itr, start), nil
 }
 
 // ReadStartingWithUser performs a reverse read of relationship tuples starting at one or
@@ -178,11 +231,13 @@ func (b *BoundedTupleReader) ReadStartingWithUser(
 
 	defer b.done()
 
+	start := time.Now()
 	itr, err := b.RelationshipTupleReader.ReadStartingWithUser(ctx, store, filter, options)
 	if itr == nil || err != nil {
+		b.observe(start, err)
 		return itr, err
 	}
-	return &countingTupleIterator{itr, &b.countItems}, nil
+	return b.wrapIterator(itr, start), nil
 }
 
 func (b *BoundedTupleReader) instrument(ctx context.Context, op string, d time.Duration, vec *prometheus.HistogramVec) {
@@ -221,6 +276,9 @@ func (b *BoundedTupleReader) bound(ctx context.Context, op string) error {
 
 // waitForLimiter respects context errors and returns an error only if it couldn't send an item to the channel.
 func (b *BoundedTupleReader) waitForLimiter(ctx context.Context) error {
+	if b.adaptive != nil {
+		return b.adaptive.acquire(ctx)
+	}
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
@@ -231,12 +289,32 @@ func (b *BoundedTupleReader) waitForLimiter(ctx context.Context) error {
 }
 
 func (b *BoundedTupleReader) done() {
+	if b.adaptive != nil {
+		b.adaptive.release()
+		return
+	}
 	select {
 	case <-b.limiter:
 	default:
 	}
 }
 
+// observe reports a call to the datastore that started at start to the adaptive limiter, if there is one.
+func (b *BoundedTupleReader) observe(start time.Time, err error) {
+	if b.adaptive != nil {
+		b.adaptive.limiter.Observe(start, err, b.throttled.Load())
+	}
+}
+
+// wrapIterator counts the tuples read from itr, which was built since start, and reports its datastore query to the
+// adaptive limiter, if there is one.
+func (b *BoundedTupleReader) wrapIterator(itr storage.TupleIterator, start time.Time) storage.TupleIterator {
+	if b.adaptive != nil {
+		itr = &observedTupleIterator{TupleIterator: itr, reader: b, built: time.Since(start)}
+	}
+	return &countingTupleIterator{itr, &b.countItems}
+}
+
 func (b *BoundedTupleReader) increaseReads() int {
 	return int(b.countReads.Add(1))
 }
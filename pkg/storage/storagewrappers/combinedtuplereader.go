@@ -173,7 +173,17 @@ func (c *CombinedTupleReader) ReadStartingWithUser(
 
 	filteredTuples := make([]*openfgav1.Tuple, 0, len(c.contextualTuplesOrderedByObjectID))
 	for _, t := range filterTuples(c.contextualTuplesOrderedByObjectID, "", filter.Relation, userFilters) {
-		if tuple.GetType(t.GetKey().GetObject()) != filter.ObjectType {
+		objectType, objectID := tuple.SplitObject(t.GetKey().GetObject())
+		if objectType != filter.ObjectType {
+			continue
+		}
+		if filter.ObjectIDs != nil && !filter.ObjectIDs.Exists(objectID) {
+			continue
+		}
+		if !strings.HasPrefix(objectID, filter.ObjectIDPrefix) {
+			continue
+		}
+		if filter.ObjectIDAfter != "" && objectID <= filter.ObjectIDAfter {
 			continue
 		}
 		filteredTuples = append(filteredTuples, t)
@@ -20,6 +20,8 @@ type Operation struct {
 	ThrottlingEnabled bool
 	ThrottleThreshold int
 	ThrottleDuration  time.Duration
+	// Limiter adapts the concurrency to the latency of the datastore, in place of Concurrency, if set.
+	Limiter *AdaptiveConcurrencyLimiter
 }
 
 // RequestStorageWrapper uses the decorator pattern to wrap a RelationshipTupleReader with various functionalities,
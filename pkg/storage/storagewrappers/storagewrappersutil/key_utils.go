@@ -48,6 +48,14 @@ func ReadStartingWithUserKey(
 
 		b.WriteString("/" + strconv.FormatUint(hasher.Sum64(), 10))
 	}
+
+	if filter.ObjectIDPrefix != "" {
+		b.WriteString("/prefix:" + filter.ObjectIDPrefix)
+	}
+
+	if filter.ObjectIDAfter != "" {
+		b.WriteString("/after:" + filter.ObjectIDAfter)
+	}
 	return b.String(), nil
 }
 
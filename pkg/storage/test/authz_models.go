@@ -287,3 +287,82 @@ func FindLatestAuthorizationModelTest(t *testing.T, datastore storage.OpenFGADat
 		}
 	})
 }
+
+func ActiveAuthorizationModelTest(t *testing.T, datastore storage.OpenFGADatastore) {
+	ctx := context.Background()
+
+	newModel := func() *openfgav1.AuthorizationModel {
+		return &openfgav1.AuthorizationModel{
+			Id:            ulid.Make().String(),
+			SchemaVersion: typesystem.SchemaVersion1_1,
+			TypeDefinitions: []*openfgav1.TypeDefinition{
+				{
+					Type: "user",
+				},
+			},
+		}
+	}
+
+	t.Run("read_active_authorization_model_id_should_return_not_found_when_none_pinned", func(t *testing.T) {
+		store := ulid.Make().String()
+		_, err := datastore.ReadActiveAuthorizationModelID(ctx, store)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		_, err = storage.FindActiveAuthorizationModel(ctx, datastore, store)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("write_pin_overwrite_and_unpin", func(t *testing.T) {
+		store := ulid.Make().String()
+
+		oldModel := newModel()
+		err := datastore.WriteAuthorizationModel(ctx, store, oldModel)
+		require.NoError(t, err)
+
+		latestModel := newModel()
+		err = datastore.WriteAuthorizationModel(ctx, store, latestModel)
+		require.NoError(t, err)
+
+		activeModel, err := storage.FindActiveAuthorizationModel(ctx, datastore, store)
+		require.NoError(t, err)
+		require.Equal(t, latestModel.GetId(), activeModel.GetId())
+
+		err = datastore.WriteActiveAuthorizationModelID(ctx, store, oldModel.GetId())
+		require.NoError(t, err)
+
+		modelID, err := datastore.ReadActiveAuthorizationModelID(ctx, store)
+		require.NoError(t, err)
+		require.Equal(t, oldModel.GetId(), modelID)
+
+		activeModel, err = storage.FindActiveAuthorizationModel(ctx, datastore, store)
+		require.NoError(t, err)
+		if diff := cmp.Diff(oldModel, activeModel, cmpOpts...); diff != "" {
+			t.Errorf("mismatch (-want +got):\n%s", diff)
+		}
+
+		// a pinned model is not superseded by a newer model
+		err = datastore.WriteAuthorizationModel(ctx, store, newModel())
+		require.NoError(t, err)
+
+		modelID, err = datastore.ReadActiveAuthorizationModelID(ctx, store)
+		require.NoError(t, err)
+		require.Equal(t, oldModel.GetId(), modelID)
+
+		err = datastore.WriteActiveAuthorizationModelID(ctx, store, latestModel.GetId())
+		require.NoError(t, err)
+
+		modelID, err = datastore.ReadActiveAuthorizationModelID(ctx, store)
+		require.NoError(t, err)
+		require.Equal(t, latestModel.GetId(), modelID)
+
+		err = datastore.WriteActiveAuthorizationModelID(ctx, store, "")
+		require.NoError(t, err)
+
+		_, err = datastore.ReadActiveAuthorizationModelID(ctx, store)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		// unpinning a store without an active model is a no-op
+		err = datastore.WriteActiveAuthorizationModelID(ctx, store, "")
+		require.NoError(t, err)
+	})
+}
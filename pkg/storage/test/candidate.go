@@ -0,0 +1,71 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+func CandidateAuthorizationModelTest(t *testing.T, datastore storage.OpenFGADatastore) {
+	ctx := context.Background()
+
+	t.Run("reading_a_missing_candidate_returns_not_found", func(t *testing.T) {
+		_, err := datastore.ReadCandidateAuthorizationModel(ctx, ulid.Make().String())
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("writing_and_reading_a_candidate_succeeds", func(t *testing.T) {
+		store, modelID := ulid.Make().String(), ulid.Make().String()
+
+		require.NoError(t, datastore.WriteCandidateAuthorizationModel(ctx, store, modelID, 25))
+
+		candidate, err := datastore.ReadCandidateAuthorizationModel(ctx, store)
+		require.NoError(t, err)
+		require.Equal(t, modelID, candidate.ModelID)
+		require.Equal(t, 25, candidate.SamplePercentage)
+		require.WithinDuration(t, time.Now(), candidate.Since, time.Minute)
+		require.Equal(t, storage.CandidateModelCounts{}, candidate.Check)
+		require.Equal(t, storage.CandidateModelCounts{}, candidate.ListObjects)
+	})
+
+	t.Run("counts_are_added_to_the_current_candidate_only", func(t *testing.T) {
+		store, modelID := ulid.Make().String(), ulid.Make().String()
+		require.NoError(t, datastore.WriteCandidateAuthorizationModel(ctx, store, modelID, 100))
+
+		check := storage.CandidateModelCounts{Evaluated: 3, Diverged: 1}
+		listObjects := storage.CandidateModelCounts{Evaluated: 2, Errored: 1}
+		require.NoError(t, datastore.AddCandidateAuthorizationModelCounts(ctx, store, modelID, check, listObjects))
+		require.NoError(t, datastore.AddCandidateAuthorizationModelCounts(ctx, store, modelID, check, storage.CandidateModelCounts{}))
+
+		// the counts of another candidate, or of a store without one, are ignored
+		require.NoError(t, datastore.AddCandidateAuthorizationModelCounts(ctx, store, ulid.Make().String(), check, listObjects))
+		require.NoError(t, datastore.AddCandidateAuthorizationModelCounts(ctx, ulid.Make().String(), modelID, check, listObjects))
+
+		candidate, err := datastore.ReadCandidateAuthorizationModel(ctx, store)
+		require.NoError(t, err)
+		require.Equal(t, storage.CandidateModelCounts{Evaluated: 6, Diverged: 2}, candidate.Check)
+		require.Equal(t, storage.CandidateModelCounts{Evaluated: 2, Errored: 1}, candidate.ListObjects)
+
+		// setting the candidate again resets its counts
+		require.NoError(t, datastore.WriteCandidateAuthorizationModel(ctx, store, modelID, 50))
+		candidate, err = datastore.ReadCandidateAuthorizationModel(ctx, store)
+		require.NoError(t, err)
+		require.Equal(t, 50, candidate.SamplePercentage)
+		require.Equal(t, storage.CandidateModelCounts{}, candidate.Check)
+		require.Equal(t, storage.CandidateModelCounts{}, candidate.ListObjects)
+	})
+
+	t.Run("an_empty_model_id_removes_the_candidate", func(t *testing.T) {
+		store := ulid.Make().String()
+		require.NoError(t, datastore.WriteCandidateAuthorizationModel(ctx, store, ulid.Make().String(), 100))
+
+		require.NoError(t, datastore.WriteCandidateAuthorizationModel(ctx, store, "", 0))
+		_, err := datastore.ReadCandidateAuthorizationModel(ctx, store)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	})
+}
@@ -0,0 +1,51 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+func PlannerSnapshotTest(t *testing.T, datastore storage.OpenFGADatastore) {
+	ctx := context.Background()
+
+	t.Run("writing_and_reading_snapshots_succeeds", func(t *testing.T) {
+		replicaA, replicaB := ulid.Make().String(), ulid.Make().String()
+
+		require.NoError(t, datastore.WritePlannerSnapshot(ctx, replicaA, []byte(`{"a":1}`)))
+		require.NoError(t, datastore.WritePlannerSnapshot(ctx, replicaB, []byte(`{"b":1}`)))
+
+		snapshots, err := datastore.ReadPlannerSnapshots(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []byte(`{"a":1}`), snapshots[replicaA])
+		require.Equal(t, []byte(`{"b":1}`), snapshots[replicaB])
+
+		// the latest snapshot of a replica overwrites the previous one
+		require.NoError(t, datastore.WritePlannerSnapshot(ctx, replicaA, []byte(`{"a":2}`)))
+
+		snapshots, err = datastore.ReadPlannerSnapshots(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []byte(`{"a":2}`), snapshots[replicaA])
+	})
+
+	t.Run("deleting_stale_snapshots_succeeds", func(t *testing.T) {
+		replica := ulid.Make().String()
+		require.NoError(t, datastore.WritePlannerSnapshot(ctx, replica, []byte(`{}`)))
+
+		require.NoError(t, datastore.DeletePlannerSnapshots(ctx, time.Hour))
+		snapshots, err := datastore.ReadPlannerSnapshots(ctx)
+		require.NoError(t, err)
+		require.Contains(t, snapshots, replica)
+
+		// a negative age deletes the snapshots written until an hour from now, i.e. all of them
+		require.NoError(t, datastore.DeletePlannerSnapshots(ctx, -time.Hour))
+		snapshots, err = datastore.ReadPlannerSnapshots(ctx)
+		require.NoError(t, err)
+		require.Empty(t, snapshots)
+	})
+}
@@ -43,12 +43,17 @@ func RunAllTests(t *testing.T, ds storage.OpenFGADatastore) {
 	t.Run("TestWriteAndReadAuthorizationModel", func(t *testing.T) { WriteAndReadAuthorizationModelTest(t, ds) })
 	t.Run("TestReadAuthorizationModels", func(t *testing.T) { ReadAuthorizationModelsTest(t, ds) })
 	t.Run("TestFindLatestAuthorizationModel", func(t *testing.T) { FindLatestAuthorizationModelTest(t, ds) })
+	t.Run("TestActiveAuthorizationModel", func(t *testing.T) { ActiveAuthorizationModelTest(t, ds) })
+	t.Run("TestCandidateAuthorizationModel", func(t *testing.T) { CandidateAuthorizationModelTest(t, ds) })
 
 	// Assertions.
 	t.Run("TestWriteAndReadAssertions", func(t *testing.T) { AssertionsTest(t, ds) })
 
 	// Stores.
 	t.Run("TestStore", func(t *testing.T) { StoreTest(t, ds) })
+
+	// Planner snapshots.
+	t.Run("TestPlannerSnapshot", func(t *testing.T) { PlannerSnapshotTest(t, ds) })
 }
 
 // BootstrapFGAStore is a utility to write an FGA model and relationship tuples to a datastore.
@@ -2005,6 +2005,112 @@ func ReadStartingWithUserTest(t *testing.T, datastore storage.OpenFGADatastore)
 		_, objectID := tuple.SplitObject(tuples[0].GetObject())
 		require.Equal(t, "doc1", objectID)
 	})
+	t.Run("returns_results_that_match_objectid_prefix_provided", func(t *testing.T) {
+		storeID := ulid.Make().String()
+
+		err := datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:doc1", "viewer", "user:jon"),
+			tuple.NewTupleKey("document:doc12", "viewer", "user:jon"),
+			tuple.NewTupleKey("document:doc2", "viewer", "user:jon"),
+			tuple.NewTupleKey("document:do%1", "viewer", "user:jon"),
+			tuple.NewTupleKey("document:Doc1", "viewer", "user:jon"),
+			tuple.NewTupleKey("document:d_c!*?[1", "viewer", "user:jon"),
+		})
+		require.NoError(t, err)
+
+		objectIDs := storage.NewSortedSet("doc1", "doc2", "Doc1")
+
+		for _, test := range []struct {
+			prefix    string
+			objectIDs storage.SortedSet
+			expected  []string
+		}{
+			{prefix: "doc1", expected: []string{"doc1", "doc12"}},
+			{prefix: "do%", expected: []string{"do%1"}},
+			{prefix: "d_c!*?[", expected: []string{"d_c!*?[1"}},
+			{prefix: "d_", expected: []string{"d_c!*?[1"}},
+			{prefix: "doc", expected: []string{"doc1", "doc12", "doc2"}},
+			{prefix: "doc1", objectIDs: objectIDs, expected: []string{"doc1"}},
+			{prefix: "missing", expected: nil},
+		} {
+			tupleIterator, err := datastore.ReadStartingWithUser(
+				ctx,
+				storeID,
+				storage.ReadStartingWithUserFilter{
+					ObjectType: "document",
+					Relation:   "viewer",
+					UserFilter: []*openfgav1.ObjectRelation{
+						{
+							Object: "user:jon",
+						},
+					},
+					ObjectIDs:      test.objectIDs,
+					ObjectIDPrefix: test.prefix,
+				},
+				storage.ReadStartingWithUserOptions{
+					WithResultsSortedAscending: true,
+				},
+			)
+			require.NoError(t, err)
+
+			var actualObjectIDs []string
+			for _, item := range iterateThroughAllTuples(t, tupleIterator) {
+				_, objectID := tuple.SplitObject(item.GetObject())
+				actualObjectIDs = append(actualObjectIDs, objectID)
+			}
+			require.Equal(t, test.expected, actualObjectIDs)
+		}
+	})
+	t.Run("returns_results_ordered_after_the_objectid_provided", func(t *testing.T) {
+		storeID := ulid.Make().String()
+
+		err := datastore.Write(ctx, storeID, nil, []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:Doc1", "viewer", "user:jon"),
+			tuple.NewTupleKey("document:doc1", "viewer", "user:jon"),
+			tuple.NewTupleKey("document:doc12", "viewer", "user:jon"),
+			tuple.NewTupleKey("document:doc2", "viewer", "user:jon"),
+			tuple.NewTupleKey("document:doc_3", "viewer", "user:jon"),
+		})
+		require.NoError(t, err)
+
+		for _, test := range []struct {
+			after    string
+			prefix   string
+			expected []string
+		}{
+			{after: "Doc1", expected: []string{"doc1", "doc12", "doc2", "doc_3"}},
+			{after: "doc1", expected: []string{"doc12", "doc2", "doc_3"}},
+			{after: "doc1", prefix: "doc1", expected: []string{"doc12"}},
+			{after: "doc_3", expected: nil},
+		} {
+			tupleIterator, err := datastore.ReadStartingWithUser(
+				ctx,
+				storeID,
+				storage.ReadStartingWithUserFilter{
+					ObjectType: "document",
+					Relation:   "viewer",
+					UserFilter: []*openfgav1.ObjectRelation{
+						{
+							Object: "user:jon",
+						},
+					},
+					ObjectIDPrefix: test.prefix,
+					ObjectIDAfter:  test.after,
+				},
+				storage.ReadStartingWithUserOptions{
+					WithResultsSortedAscending: true,
+				},
+			)
+			require.NoError(t, err)
+
+			var actualObjectIDs []string
+			for _, item := range iterateThroughAllTuples(t, tupleIterator) {
+				_, objectID := tuple.SplitObject(item.GetObject())
+				actualObjectIDs = append(actualObjectIDs, objectID)
+			}
+			require.Equal(t, test.expected, actualObjectIDs)
+		}
+	})
 	t.Run("assert_bytewise_ordering_of_tuples", func(t *testing.T) {
 		storeID := ulid.Make().String()
 
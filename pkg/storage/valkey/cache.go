@@ -0,0 +1,232 @@
+package valkey
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+const (
+	defaultCacheKeyPrefix        = "cache:"
+	defaultCacheOperationTimeout = 100 * time.Millisecond
+	defaultCacheLocalTierTTL     = time.Second
+
+	// maxCacheTTL mirrors the truncation of storage.InMemoryLRUCache.
+	maxCacheTTL = time.Hour * 24 * 365
+)
+
+var (
+	cacheRequestCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "valkey_cache_request_count",
+		Help:      "The total number of Get requests to the Valkey cache labeled by the tier that served them (local or remote) or miss.",
+	}, []string{"result"})
+
+	cacheErrorCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: build.ProjectName,
+		Name:      "valkey_cache_error_count",
+		Help:      "The total number of failed Valkey cache operations labeled by operation. Failed reads are treated as misses.",
+	}, []string{"operation"})
+)
+
+// CacheOpt defines an option that can be used to change the behavior of a Cache instance.
+type CacheOpt[T any] func(*Cache[T])
+
+// WithCacheKeyPrefix sets the prefix of every key the cache stores in Valkey, so that several caches
+// (or a cache and a datastore) can share a Valkey database.
+func WithCacheKeyPrefix[T any](prefix string) CacheOpt[T] {
+	return func(c *Cache[T]) {
+		c.keyPrefix = prefix
+	}
+}
+
+// WithCacheOperationTimeout sets how long a single Valkey operation may take. Reads that time out
+// are treated as misses.
+func WithCacheOperationTimeout[T any](timeout time.Duration) CacheOpt[T] {
+	return func(c *Cache[T]) {
+		c.operationTimeout = timeout
+	}
+}
+
+// WithCacheLocalTier keeps a process-local LRU of at most maxElements in front of Valkey. Values
+// are kept locally for at most ttl, which bounds how long a replica may serve a value that was
+// overwritten or deleted by another replica.
+func WithCacheLocalTier[T any](maxElements int64, ttl time.Duration) CacheOpt[T] {
+	return func(c *Cache[T]) {
+		c.localMaxElements = maxElements
+		c.localTTL = ttl
+	}
+}
+
+// WithCacheLocalTierBypass makes the keys for which bypass returns true always read from and
+// written to Valkey only. It is meant for keys that must be consistent across replicas, such as
+// the invalidation timestamps of the cache controller.
+func WithCacheLocalTierBypass[T any](bypass func(key string) bool) CacheOpt[T] {
+	return func(c *Cache[T]) {
+		c.localBypass = bypass
+	}
+}
+
+// Cache is a storage.InMemoryCache backed by Valkey (or Redis), shared by every replica that uses
+// the same Valkey database and key prefix. Values are encoded with the provided storage.CacheCodec;
+// values it cannot encode are only kept in the local tier, if any.
+//
+// Valkey failures never fail the caller: reads are reported as misses and writes are dropped.
+type Cache[T any] struct {
+	client           *redis.Client
+	codec            storage.CacheCodec[T]
+	keyPrefix        string
+	operationTimeout time.Duration
+
+	local            *storage.InMemoryLRUCache[T]
+	localMaxElements int64
+	localTTL         time.Duration
+	localBypass      func(key string) bool
+
+	stopOnce sync.Once
+}
+
+var _ storage.InMemoryCache[any] = (*Cache[any])(nil)
+
+// NewCache returns a Cache connected to the Valkey instance at uri, e.g. redis://localhost:6379/0.
+func NewCache[T any](uri string, codec storage.CacheCodec[T], opts ...CacheOpt[T]) (*Cache[T], error) {
+	redisOpts, err := redis.ParseURL(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cache[T]{
+		codec:            codec,
+		keyPrefix:        defaultCacheKeyPrefix,
+		operationTimeout: defaultCacheOperationTimeout,
+		localTTL:         defaultCacheLocalTierTTL,
+		localBypass:      func(string) bool { return false },
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.localMaxElements > 0 {
+		c.local, err = storage.NewInMemoryLRUCache([]storage.InMemoryLRUCacheOpt[T]{
+			storage.WithMaxCacheSize[T](c.localMaxElements),
+		}...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c.client = redis.NewClient(redisOpts)
+	return c, nil
+}
+
+func (c *Cache[T]) useLocal(key string) bool {
+	return c.local != nil && !c.localBypass(key)
+}
+
+// Get returns the value of key, looking it up in the local tier first. If the key doesn't
+// exist, or Valkey cannot be reached, it returns the zero value.
+func (c *Cache[T]) Get(key string) T {
+	var zero T
+
+	useLocal := c.useLocal(key)
+	if useLocal {
+		if value := c.local.Get(key); !isZero(value) {
+			cacheRequestCounter.WithLabelValues("local").Inc()
+			return value
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.operationTimeout)
+	defer cancel()
+
+	data, err := c.client.Get(ctx, c.keyPrefix+key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			cacheErrorCounter.WithLabelValues("get").Inc()
+		}
+		cacheRequestCounter.WithLabelValues("miss").Inc()
+		return zero
+	}
+
+	value, err := c.codec.Decode(data)
+	if err != nil {
+		cacheErrorCounter.WithLabelValues("decode").Inc()
+		cacheRequestCounter.WithLabelValues("miss").Inc()
+		return zero
+	}
+
+	cacheRequestCounter.WithLabelValues("remote").Inc()
+	if useLocal {
+		c.local.Set(key, value, c.localTTL)
+	}
+	return value
+}
+
+// Set stores value for the ttl in Valkey, and for at most the local tier TTL in the local tier.
+// Like storage.InMemoryLRUCache, ttl is truncated to one year and negative ttl are noop.
+func (c *Cache[T]) Set(key string, value T, ttl time.Duration) {
+	if ttl < 0 {
+		return
+	}
+	if ttl >= maxCacheTTL {
+		ttl = maxCacheTTL
+	}
+
+	if c.useLocal(key) {
+		c.local.Set(key, value, min(ttl, c.localTTL))
+	}
+
+	data, err := c.codec.Encode(value)
+	if err != nil {
+		if !errors.Is(err, storage.ErrUncacheableValue) {
+			cacheErrorCounter.WithLabelValues("encode").Inc()
+		}
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.operationTimeout)
+	defer cancel()
+
+	if err := c.client.Set(ctx, c.keyPrefix+key, data, ttl).Err(); err != nil {
+		cacheErrorCounter.WithLabelValues("set").Inc()
+	}
+}
+
+// Delete removes key from both tiers.
+func (c *Cache[T]) Delete(key string) {
+	if c.local != nil {
+		c.local.Delete(key)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.operationTimeout)
+	defer cancel()
+
+	if err := c.client.Del(ctx, c.keyPrefix+key).Err(); err != nil {
+		cacheErrorCounter.WithLabelValues("delete").Inc()
+	}
+}
+
+// Stop closes the connection to Valkey and the local tier.
+func (c *Cache[T]) Stop() {
+	c.stopOnce.Do(func() {
+		if c.local != nil {
+			c.local.Stop()
+		}
+		_ = c.client.Close()
+	})
+}
+
+// isZero reports whether value is the zero value of T, which InMemoryCache.Get returns on misses.
+func isZero[T any](value T) bool {
+	return reflect.ValueOf(&value).Elem().IsZero()
+}
@@ -0,0 +1,101 @@
+package valkey_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/valkey"
+)
+
+func TestValkeyCache(t *testing.T) {
+	uri := os.Getenv("OPENFGA_VALKEY_URI")
+	if uri == "" {
+		uri = "redis://localhost:6380"
+	}
+
+	opt, err := redis.ParseURL(uri)
+	require.NoError(t, err)
+	client := redis.NewClient(opt)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Valkey not ready at %s: %v", uri, err)
+	}
+
+	newCache := func(t *testing.T, prefix string, opts ...valkey.CacheOpt[any]) *valkey.Cache[any] {
+		opts = append([]valkey.CacheOpt[any]{valkey.WithCacheKeyPrefix[any](prefix)}, opts...)
+		cache, err := valkey.NewCache(uri, storage.NewCacheItemCodec(), opts...)
+		require.NoError(t, err)
+		t.Cleanup(cache.Stop)
+		return cache
+	}
+
+	entry := &storage.InvalidEntityCacheEntry{LastModified: time.Now().UTC().Truncate(time.Microsecond)}
+
+	t.Run("shared_between_replicas", func(t *testing.T) {
+		prefix := "test:" + ulid.Make().String() + ":"
+		replica1 := newCache(t, prefix)
+		replica2 := newCache(t, prefix)
+
+		require.Nil(t, replica2.Get("key"))
+
+		replica1.Set("key", entry, time.Minute)
+		require.Equal(t, entry, replica2.Get("key"))
+
+		replica2.Delete("key")
+		require.Nil(t, replica1.Get("key"))
+	})
+
+	t.Run("expires", func(t *testing.T) {
+		cache := newCache(t, "test:"+ulid.Make().String()+":")
+
+		cache.Set("key", entry, 50*time.Millisecond)
+		require.Eventually(t, func() bool {
+			return cache.Get("key") == nil
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("uncacheable_values_are_only_kept_locally", func(t *testing.T) {
+		prefix := "test:" + ulid.Make().String() + ":"
+		replica1 := newCache(t, prefix, valkey.WithCacheLocalTier[any](10, time.Minute))
+		replica2 := newCache(t, prefix)
+
+		replica1.Set("key", "value", time.Minute)
+		require.Equal(t, "value", replica1.Get("key"))
+		require.Nil(t, replica2.Get("key"))
+	})
+
+	t.Run("local_tier", func(t *testing.T) {
+		prefix := "test:" + ulid.Make().String() + ":"
+		replica1 := newCache(t, prefix,
+			valkey.WithCacheLocalTier[any](10, 200*time.Millisecond),
+			valkey.WithCacheLocalTierBypass[any](storage.IsCacheInvalidationKey),
+		)
+		replica2 := newCache(t, prefix)
+
+		invalidationKey := storage.GetInvalidIteratorCacheKey("store")
+		replica1.Set("key", entry, time.Minute)
+		replica1.Set(invalidationKey, entry, time.Minute)
+
+		replica2.Delete("key")
+		replica2.Delete(invalidationKey)
+
+		// the local tier keeps serving the deleted value until it expires locally
+		require.Equal(t, entry, replica1.Get("key"))
+		require.Eventually(t, func() bool {
+			return replica1.Get("key") == nil
+		}, 2*time.Second, 10*time.Millisecond)
+
+		// but invalidation keys are never kept locally
+		require.Nil(t, replica1.Get(invalidationKey))
+	})
+}
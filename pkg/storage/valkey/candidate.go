@@ -0,0 +1,111 @@
+package valkey
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/openfga/openfga/pkg/storage"
+)
+
+const (
+	candidateModelIDField          = "model_id"
+	candidateSamplePercentageField = "sample_percentage"
+	candidateSinceField            = "since"
+)
+
+var candidateCountFields = []string{
+	"check_evaluated", "check_diverged", "check_errored",
+	"list_objects_evaluated", "list_objects_diverged", "list_objects_errored",
+}
+
+// addCandidateCountsScript adds the counts to the ones of the candidate model, only if it is still the model of ARGV[1].
+var addCandidateCountsScript = redis.NewScript(`
+if redis.call("HGET", KEYS[1], "model_id") ~= ARGV[1] then
+	return 0
+end
+local fields = {"check_evaluated", "check_diverged", "check_errored", "list_objects_evaluated", "list_objects_diverged", "list_objects_errored"}
+for i, field in ipairs(fields) do
+	if ARGV[i + 1] ~= "0" then
+		redis.call("HINCRBY", KEYS[1], field, ARGV[i + 1])
+	end
+end
+return 1`)
+
+func (s *ValkeyBackend) WriteCandidateAuthorizationModel(ctx context.Context, store, modelID string, samplePercentage int) error {
+	ctx, span := tracer.Start(ctx, "valkey.WriteCandidateAuthorizationModel")
+	defer span.End()
+
+	key := candidateAuthorizationModelKey(store)
+	if modelID == "" {
+		return s.client.Del(ctx, key).Err()
+	}
+
+	now, err := s.client.Time(ctx).Result()
+	if err != nil {
+		return err
+	}
+
+	values := []any{
+		candidateModelIDField, modelID,
+		candidateSamplePercentageField, samplePercentage,
+		candidateSinceField, now.UnixMilli(),
+	}
+	for _, field := range candidateCountFields {
+		values = append(values, field, 0)
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.HSet(ctx, key, values...)
+		return nil
+	})
+	return err
+}
+
+func (s *ValkeyBackend) ReadCandidateAuthorizationModel(ctx context.Context, store string) (*storage.CandidateAuthorizationModel, error) {
+	ctx, span := tracer.Start(ctx, "valkey.ReadCandidateAuthorizationModel")
+	defer span.End()
+
+	fields, err := s.client.HGetAll(ctx, candidateAuthorizationModelKey(store)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if fields[candidateModelIDField] == "" {
+		return nil, storage.ErrNotFound
+	}
+
+	candidate := &storage.CandidateAuthorizationModel{ModelID: fields[candidateModelIDField]}
+	if candidate.SamplePercentage, err = strconv.Atoi(fields[candidateSamplePercentageField]); err != nil {
+		return nil, err
+	}
+	since, err := strconv.ParseInt(fields[candidateSinceField], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	candidate.Since = time.UnixMilli(since).UTC()
+
+	counts := []*uint64{
+		&candidate.Check.Evaluated, &candidate.Check.Diverged, &candidate.Check.Errored,
+		&candidate.ListObjects.Evaluated, &candidate.ListObjects.Diverged, &candidate.ListObjects.Errored,
+	}
+	for i, field := range candidateCountFields {
+		if *counts[i], err = strconv.ParseUint(fields[field], 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	return candidate, nil
+}
+
+func (s *ValkeyBackend) AddCandidateAuthorizationModelCounts(ctx context.Context, store, modelID string, check, listObjects storage.CandidateModelCounts) error {
+	ctx, span := tracer.Start(ctx, "valkey.AddCandidateAuthorizationModelCounts")
+	defer span.End()
+
+	return addCandidateCountsScript.Run(ctx, s.client, []string{candidateAuthorizationModelKey(store)},
+		modelID,
+		check.Evaluated, check.Diverged, check.Errored,
+		listObjects.Evaluated, listObjects.Diverged, listObjects.Errored,
+	).Err()
+}
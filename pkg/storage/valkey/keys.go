@@ -10,6 +10,11 @@ const (
 	assertionPrefix = "assertions"
 	tuplePrefix     = "tuples"
 	changelogPrefix = "changelog"
+
+	// plannerSnapshotsKey is the Hash of the planner snapshot of each replica, and plannerSnapshotsUpdatedKey the
+	// Sorted Set of the replicas by the time their snapshot was last written, in milliseconds.
+	plannerSnapshotsKey        = "planner:snapshots"
+	plannerSnapshotsUpdatedKey = "planner:snapshots:updated"
 )
 
 func storeKey(id string) string {
@@ -29,6 +34,15 @@ func latestAuthorizationModelKey(storeID string) string {
 	return fmt.Sprintf("%s:%s:latest", modelPrefix, storeID)
 }
 
+func activeAuthorizationModelKey(storeID string) string {
+	return fmt.Sprintf("%s:%s:active", modelPrefix, storeID)
+}
+
+// candidateAuthorizationModelKey returns the key for the Hash of the candidate model of a store and its counts.
+func candidateAuthorizationModelKey(storeID string) string {
+	return fmt.Sprintf("%s:%s:candidate", modelPrefix, storeID)
+}
+
 func assertionsKey(storeID, modelID string) string {
 	return fmt.Sprintf("%s:%s:%s", assertionPrefix, storeID, modelID)
 }
@@ -225,3 +225,29 @@ func (s *ValkeyBackend) WriteAuthorizationModel(ctx context.Context, store strin
 	_, err = pipeline.Exec(ctx)
 	return err
 }
+
+func (s *ValkeyBackend) ReadActiveAuthorizationModelID(ctx context.Context, store string) (string, error) {
+	ctx, span := tracer.Start(ctx, "valkey.ReadActiveAuthorizationModelID")
+	defer span.End()
+
+	id, err := s.client.Get(ctx, activeAuthorizationModelKey(store)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", storage.ErrNotFound
+		}
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (s *ValkeyBackend) WriteActiveAuthorizationModelID(ctx context.Context, store, modelID string) error {
+	ctx, span := tracer.Start(ctx, "valkey.WriteActiveAuthorizationModelID")
+	defer span.End()
+
+	if modelID == "" {
+		return s.client.Del(ctx, activeAuthorizationModelKey(store)).Err()
+	}
+
+	return s.client.Set(ctx, activeAuthorizationModelKey(store), modelID, 0).Err()
+}
@@ -0,0 +1,72 @@
+package valkey
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func (s *ValkeyBackend) WritePlannerSnapshot(ctx context.Context, replicaID string, snapshot []byte) error {
+	ctx, span := tracer.Start(ctx, "valkey.WritePlannerSnapshot")
+	defer span.End()
+
+	now, err := s.client.Time(ctx).Result()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, plannerSnapshotsKey, replicaID, snapshot)
+		pipe.ZAdd(ctx, plannerSnapshotsUpdatedKey, redis.Z{Score: float64(now.UnixMilli()), Member: replicaID})
+		return nil
+	})
+	return err
+}
+
+func (s *ValkeyBackend) ReadPlannerSnapshots(ctx context.Context) (map[string][]byte, error) {
+	ctx, span := tracer.Start(ctx, "valkey.ReadPlannerSnapshots")
+	defer span.End()
+
+	values, err := s.client.HGetAll(ctx, plannerSnapshotsKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make(map[string][]byte, len(values))
+	for replicaID, snapshot := range values {
+		snapshots[replicaID] = []byte(snapshot)
+	}
+	return snapshots, nil
+}
+
+func (s *ValkeyBackend) DeletePlannerSnapshots(ctx context.Context, olderThan time.Duration) error {
+	ctx, span := tracer.Start(ctx, "valkey.DeletePlannerSnapshots")
+	defer span.End()
+
+	now, err := s.client.Time(ctx).Result()
+	if err != nil {
+		return err
+	}
+
+	replicaIDs, err := s.client.ZRangeByScore(ctx, plannerSnapshotsUpdatedKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: "(" + strconv.FormatInt(now.Add(-olderThan).UnixMilli(), 10),
+	}).Result()
+	if err != nil || len(replicaIDs) == 0 {
+		return err
+	}
+
+	members := make([]any, 0, len(replicaIDs))
+	for _, replicaID := range replicaIDs {
+		members = append(members, replicaID)
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HDel(ctx, plannerSnapshotsKey, replicaIDs...)
+		pipe.ZRem(ctx, plannerSnapshotsUpdatedKey, members...)
+		return nil
+	})
+	return err
+}
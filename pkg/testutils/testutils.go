@@ -29,6 +29,7 @@ import (
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 	parser "github.com/openfga/language/pkg/go/transformer"
 
+	"github.com/openfga/openfga/pkg/middleware/storeid"
 	serverconfig "github.com/openfga/openfga/pkg/server/config"
 	"github.com/openfga/openfga/pkg/tuple"
 )
@@ -160,6 +161,19 @@ func MakeStringWithRuneset(n uint64, runeSet []rune) string {
 // attempts to parse it using the official OpenFGA language parser. The model returned
 // includes an auto-generated model id which assists with producing models for testing
 // purposes.
+// ContextWithStoreID returns a context that holds the store ID, as set by the store ID interceptor of a request of
+// the store.
+func ContextWithStoreID(t testing.TB, storeID string) context.Context {
+	var storeCtx context.Context
+	_, err := storeid.NewUnaryInterceptor()(context.Background(), &openfgav1.CheckRequest{StoreId: storeID}, &grpc.UnaryServerInfo{},
+		func(ctx context.Context, _ any) (any, error) {
+			storeCtx = ctx
+			return nil, nil
+		})
+	require.NoError(t, err)
+	return storeCtx
+}
+
 func MustTransformDSLToProtoWithID(s string) *openfgav1.AuthorizationModel {
 	model := parser.MustTransformDSLToProto(s)
 	model.Id = ulid.Make().String()
@@ -0,0 +1,242 @@
+package typesystem
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+)
+
+// ModelChangeClassification tells how a change of an authorization model affects the existing tuples and the
+// results of the queries. The classifications are ordered from the least to the most severe.
+type ModelChangeClassification int
+
+const (
+	// ChangeAdditive changes allow new tuples or new queries without changing the existing results.
+	ChangeAdditive ModelChangeClassification = iota
+	// ChangeBehaviorChanging changes may change the results of the queries on the existing tuples.
+	ChangeBehaviorChanging
+	// ChangeBreaking changes invalidate existing tuples or queries, e.g. tuples of a removed relation.
+	ChangeBreaking
+)
+
+var modelChangeClassificationNames = []string{"additive", "behavior_changing", "breaking"}
+
+func (c ModelChangeClassification) String() string {
+	if c < 0 || int(c) >= len(modelChangeClassificationNames) {
+		return fmt.Sprintf("ModelChangeClassification(%d)", int(c))
+	}
+	return modelChangeClassificationNames[c]
+}
+
+// ParseModelChangeClassification returns the classification of its String representation.
+func ParseModelChangeClassification(s string) (ModelChangeClassification, error) {
+	idx := slices.Index(modelChangeClassificationNames, s)
+	if idx < 0 {
+		return 0, fmt.Errorf("unknown model change classification '%s'", s)
+	}
+	return ModelChangeClassification(idx), nil
+}
+
+// ModelChangeKind is the kind of change of an authorization model.
+type ModelChangeKind string
+
+const (
+	SchemaVersionChanged       ModelChangeKind = "schema_version_changed"
+	TypeAdded                  ModelChangeKind = "type_added"
+	TypeRemoved                ModelChangeKind = "type_removed"
+	RelationAdded              ModelChangeKind = "relation_added"
+	RelationRemoved            ModelChangeKind = "relation_removed"
+	RelationRewriteChanged     ModelChangeKind = "relation_rewrite_changed"
+	TypeRestrictionAdded       ModelChangeKind = "type_restriction_added"
+	TypeRestrictionRemoved     ModelChangeKind = "type_restriction_removed"
+	ConditionAdded             ModelChangeKind = "condition_added"
+	ConditionRemoved           ModelChangeKind = "condition_removed"
+	ConditionSignatureChanged  ModelChangeKind = "condition_signature_changed"
+	ConditionExpressionChanged ModelChangeKind = "condition_expression_changed"
+)
+
+// ModelChange is a semantic change between two authorization models. ObjectType and Relation, or Condition, locate
+// the change, and Detail describes it, e.g. with the added or removed type restriction.
+type ModelChange struct {
+	Kind           ModelChangeKind
+	Classification ModelChangeClassification
+	ObjectType     string
+	Relation       string
+	Condition      string
+	Detail         string
+}
+
+func (c ModelChange) String() string {
+	var sb strings.Builder
+	sb.WriteString(c.Classification.String() + ": " + string(c.Kind))
+
+	switch {
+	case c.Condition != "":
+		sb.WriteString(" condition " + c.Condition)
+	case c.Relation != "":
+		sb.WriteString(" " + c.ObjectType + "#" + c.Relation)
+	case c.ObjectType != "":
+		sb.WriteString(" " + c.ObjectType)
+	}
+
+	if c.Detail != "" {
+		sb.WriteString(" (" + c.Detail + ")")
+	}
+
+	return sb.String()
+}
+
+// ModelDiff holds the changes between two authorization models, ordered by type, relation and condition.
+type ModelDiff struct {
+	Changes []ModelChange
+}
+
+// Classification returns the most severe classification of the changes, or ChangeAdditive if there is none.
+func (d *ModelDiff) Classification() ModelChangeClassification {
+	classification := ChangeAdditive
+	for _, change := range d.Changes {
+		classification = max(classification, change.Classification)
+	}
+	return classification
+}
+
+// Diff returns the semantic changes that turn the from model into the to model:
+//   - added types, relations, type restrictions and conditions are additive,
+//   - changed rewrites, condition expressions and schema versions change the behavior of the queries, and
+//   - removed types, relations, type restrictions and conditions, and changed condition parameters, are breaking,
+//     since the existing tuples that use them become invalid.
+func Diff(from, to *TypeSystem) *ModelDiff {
+	d := &ModelDiff{}
+
+	if from.GetSchemaVersion() != to.GetSchemaVersion() {
+		d.Changes = append(d.Changes, ModelChange{
+			Kind:           SchemaVersionChanged,
+			Classification: ChangeBehaviorChanging,
+			Detail:         from.GetSchemaVersion() + " -> " + to.GetSchemaVersion(),
+		})
+	}
+
+	for _, objectType := range sortedKeys(from.typeDefinitions, to.typeDefinitions) {
+		_, inFrom := from.typeDefinitions[objectType]
+		_, inTo := to.typeDefinitions[objectType]
+		switch {
+		case !inFrom:
+			d.Changes = append(d.Changes, ModelChange{Kind: TypeAdded, Classification: ChangeAdditive, ObjectType: objectType})
+		case !inTo:
+			d.Changes = append(d.Changes, ModelChange{Kind: TypeRemoved, Classification: ChangeBreaking, ObjectType: objectType})
+		default:
+			d.diffRelations(objectType, from.relations[objectType], to.relations[objectType])
+		}
+	}
+
+	for _, name := range sortedKeys(from.conditions, to.conditions) {
+		fromCondition, inFrom := from.conditions[name]
+		toCondition, inTo := to.conditions[name]
+		switch {
+		case !inFrom:
+			d.Changes = append(d.Changes, ModelChange{Kind: ConditionAdded, Classification: ChangeAdditive, Condition: name})
+		case !inTo:
+			d.Changes = append(d.Changes, ModelChange{Kind: ConditionRemoved, Classification: ChangeBreaking, Condition: name})
+		default:
+			d.diffCondition(name, fromCondition.Condition, toCondition.Condition)
+		}
+	}
+
+	return d
+}
+
+func (d *ModelDiff) diffRelations(objectType string, from, to map[string]*openfgav1.Relation) {
+	for _, relation := range sortedKeys(from, to) {
+		fromRelation, inFrom := from[relation]
+		toRelation, inTo := to[relation]
+		switch {
+		case !inFrom:
+			d.Changes = append(d.Changes, ModelChange{Kind: RelationAdded, Classification: ChangeAdditive, ObjectType: objectType, Relation: relation})
+			continue
+		case !inTo:
+			d.Changes = append(d.Changes, ModelChange{Kind: RelationRemoved, Classification: ChangeBreaking, ObjectType: objectType, Relation: relation})
+			continue
+		}
+
+		if !proto.Equal(fromRelation.GetRewrite(), toRelation.GetRewrite()) {
+			d.Changes = append(d.Changes, ModelChange{Kind: RelationRewriteChanged, Classification: ChangeBehaviorChanging, ObjectType: objectType, Relation: relation})
+		}
+
+		fromRestrictions := typeRestrictions(fromRelation)
+		toRestrictions := typeRestrictions(toRelation)
+		for _, restriction := range sortedKeys(fromRestrictions, toRestrictions) {
+			_, inFrom := fromRestrictions[restriction]
+			_, inTo := toRestrictions[restriction]
+			switch {
+			case !inFrom:
+				d.Changes = append(d.Changes, ModelChange{Kind: TypeRestrictionAdded, Classification: ChangeAdditive, ObjectType: objectType, Relation: relation, Detail: restriction})
+			case !inTo:
+				// the tuples written with the type restriction can no longer be written or read
+				d.Changes = append(d.Changes, ModelChange{Kind: TypeRestrictionRemoved, Classification: ChangeBreaking, ObjectType: objectType, Relation: relation, Detail: restriction})
+			}
+		}
+	}
+}
+
+func (d *ModelDiff) diffCondition(name string, from, to *openfgav1.Condition) {
+	var changedParameters []string
+	for _, parameter := range sortedKeys(from.GetParameters(), to.GetParameters()) {
+		if !proto.Equal(from.GetParameters()[parameter], to.GetParameters()[parameter]) {
+			changedParameters = append(changedParameters, parameter)
+		}
+	}
+
+	if len(changedParameters) > 0 {
+		// the contexts stored with the existing tuples may no longer match the parameters
+		d.Changes = append(d.Changes, ModelChange{
+			Kind:           ConditionSignatureChanged,
+			Classification: ChangeBreaking,
+			Condition:      name,
+			Detail:         "parameters " + strings.Join(changedParameters, ", "),
+		})
+	}
+
+	// the expressions are compared regardless of their formatting
+	if strings.Join(strings.Fields(from.GetExpression()), " ") != strings.Join(strings.Fields(to.GetExpression()), " ") {
+		d.Changes = append(d.Changes, ModelChange{Kind: ConditionExpressionChanged, Classification: ChangeBehaviorChanging, Condition: name})
+	}
+}
+
+// typeRestrictions returns the type restrictions of the relation, e.g. "user", "user:*", "group#member" or
+// "user with condition".
+func typeRestrictions(relation *openfgav1.Relation) map[string]struct{} {
+	restrictions := make(map[string]struct{})
+	for _, ref := range relation.GetTypeInfo().GetDirectlyRelatedUserTypes() {
+		restriction := ref.GetType()
+		switch {
+		case ref.GetWildcard() != nil:
+			restriction += ":*"
+		case ref.GetRelation() != "":
+			restriction += "#" + ref.GetRelation()
+		}
+		if ref.GetCondition() != "" {
+			restriction += " with " + ref.GetCondition()
+		}
+		restrictions[restriction] = struct{}{}
+	}
+	return restrictions
+}
+
+// sortedKeys returns the sorted union of the keys of the maps.
+func sortedKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
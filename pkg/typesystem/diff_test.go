@@ -0,0 +1,166 @@
+package typesystem
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/testutils"
+)
+
+func TestDiff(t *testing.T) {
+	from := `
+		model
+			schema 1.1
+		type user
+		type employee
+		type group
+			relations
+				define member: [user, employee]
+		type document
+			relations
+				define owner: [user]
+				define editor: [user, group#member with in_office] or owner
+				define viewer: [user:*] or editor
+		condition in_office(ip: ipaddress) {
+			ip.in_cidr("192.168.0.0/24")
+		}
+		condition in_region(region: string) {
+			region == "eu"
+		}`
+
+	tests := []struct {
+		name                   string
+		to                     string
+		expectedChanges        []ModelChange
+		expectedClassification ModelChangeClassification
+	}{
+		{
+			name:                   "same_model",
+			to:                     from,
+			expectedClassification: ChangeAdditive,
+		},
+		{
+			name: "additive",
+			to: `
+				model
+					schema 1.1
+				type user
+				type employee
+				type folder
+				type group
+					relations
+						define member: [user, employee]
+						define admin: [user]
+				type document
+					relations
+						define owner: [user, employee]
+						define editor: [user, group#member with in_office] or owner
+						define viewer: [user:*] or editor
+				condition in_office(ip: ipaddress) {
+					ip.in_cidr("192.168.0.0/24")
+				}
+				condition in_region(region: string) {
+					region == "eu"
+				}
+				condition in_time(time: timestamp) {
+					time > timestamp("2024-01-01T00:00:00Z")
+				}`,
+			expectedChanges: []ModelChange{
+				{Kind: TypeRestrictionAdded, Classification: ChangeAdditive, ObjectType: "document", Relation: "owner", Detail: "employee"},
+				{Kind: TypeAdded, Classification: ChangeAdditive, ObjectType: "folder"},
+				{Kind: RelationAdded, Classification: ChangeAdditive, ObjectType: "group", Relation: "admin"},
+				{Kind: ConditionAdded, Classification: ChangeAdditive, Condition: "in_time"},
+			},
+			expectedClassification: ChangeAdditive,
+		},
+		{
+			name: "behavior_changing",
+			to: `
+				model
+					schema 1.1
+				type user
+				type employee
+				type group
+					relations
+						define member: [user, employee]
+				type document
+					relations
+						define owner: [user]
+						define editor: [user, group#member with in_office] but not owner
+						define viewer: [user:*] or editor
+				condition in_office(ip: ipaddress) {
+					ip.in_cidr("10.0.0.0/8")
+				}
+				condition in_region(region: string) {
+					region == "eu"
+				}`,
+			expectedChanges: []ModelChange{
+				{Kind: RelationRewriteChanged, Classification: ChangeBehaviorChanging, ObjectType: "document", Relation: "editor"},
+				{Kind: ConditionExpressionChanged, Classification: ChangeBehaviorChanging, Condition: "in_office"},
+			},
+			expectedClassification: ChangeBehaviorChanging,
+		},
+		{
+			name: "breaking",
+			to: `
+				model
+					schema 1.1
+				type user
+				type group
+					relations
+						define member: [user]
+				type document
+					relations
+						define editor: [user, group#member]
+						define viewer: [user] or editor
+				condition in_office(ip: ipaddress) {
+					ip.in_cidr("192.168.0.0/24")
+				}
+				condition in_region(region: string, country: string) {
+					region == "eu"
+				}`,
+			expectedChanges: []ModelChange{
+				{Kind: RelationRewriteChanged, Classification: ChangeBehaviorChanging, ObjectType: "document", Relation: "editor"},
+				{Kind: TypeRestrictionAdded, Classification: ChangeAdditive, ObjectType: "document", Relation: "editor", Detail: "group#member"},
+				{Kind: TypeRestrictionRemoved, Classification: ChangeBreaking, ObjectType: "document", Relation: "editor", Detail: "group#member with in_office"},
+				{Kind: RelationRemoved, Classification: ChangeBreaking, ObjectType: "document", Relation: "owner"},
+				{Kind: TypeRestrictionAdded, Classification: ChangeAdditive, ObjectType: "document", Relation: "viewer", Detail: "user"},
+				{Kind: TypeRestrictionRemoved, Classification: ChangeBreaking, ObjectType: "document", Relation: "viewer", Detail: "user:*"},
+				{Kind: TypeRemoved, Classification: ChangeBreaking, ObjectType: "employee"},
+				{Kind: TypeRestrictionRemoved, Classification: ChangeBreaking, ObjectType: "group", Relation: "member", Detail: "employee"},
+				{Kind: ConditionSignatureChanged, Classification: ChangeBreaking, Condition: "in_region", Detail: "parameters country"},
+			},
+			expectedClassification: ChangeBreaking,
+		},
+	}
+
+	fromTypesys, err := New(testutils.MustTransformDSLToProtoWithID(from))
+	require.NoError(t, err)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			toTypesys, err := New(testutils.MustTransformDSLToProtoWithID(test.to))
+			require.NoError(t, err)
+
+			diff := Diff(fromTypesys, toTypesys)
+			require.Equal(t, test.expectedChanges, diff.Changes)
+			require.Equal(t, test.expectedClassification, diff.Classification())
+		})
+	}
+
+	t.Run("classification_names", func(t *testing.T) {
+		for _, classification := range []ModelChangeClassification{ChangeAdditive, ChangeBehaviorChanging, ChangeBreaking} {
+			parsed, err := ParseModelChangeClassification(classification.String())
+			require.NoError(t, err)
+			require.Equal(t, classification, parsed)
+		}
+
+		_, err := ParseModelChangeClassification("unknown")
+		require.Error(t, err)
+
+		require.Equal(t, "breaking: type_restriction_removed document#viewer (user:*)", ModelChange{
+			Kind: TypeRestrictionRemoved, Classification: ChangeBreaking, ObjectType: "document", Relation: "viewer", Detail: "user:*",
+		}.String())
+	})
+}
@@ -20,89 +20,176 @@ import (
 
 const (
 	typesystemCacheTTL = 168 * time.Hour // 7 days.
+
+	// activeModelIDCacheTTL bounds how long the active model of a store that was pinned, or unpinned, through
+	// another server is still served from the cache.
+	activeModelIDCacheTTL = 10 * time.Second
 )
 
 type TypesystemResolverFunc func(ctx context.Context, storeID, modelID string) (*TypeSystem, error)
 
-// MemoizedTypesystemResolverFunc does several things.
-//
-// If given a model ID: validates the model ID, and tries to fetch it from the cache.
-// If not found in the cache, fetches from the datastore, validates it, stores in cache, and returns it.
-//
-// If not given a model ID: fetches the latest model ID from the datastore, then sees if the model ID is in the cache.
-// If it is, returns it. Else, validates it and returns it.
-func MemoizedTypesystemResolverFunc(datastore storage.AuthorizationModelReadBackend) (TypesystemResolverFunc, func(), error) {
-	lookupGroup := singleflight.Group{}
+// activeModelID is the ID of the model pinned as the active model of a store, or empty if none is pinned.
+type activeModelID struct {
+	modelID string
+}
+
+var _ storage.CacheItem = (*activeModelID)(nil)
+
+func (*activeModelID) CacheEntityType() string {
+	return "active_authorization_model_id"
+}
+
+// MemoizedTypesystemResolver resolves the typesystems of the models of the stores, see Resolve.
+type MemoizedTypesystemResolver struct {
+	datastore   storage.AuthorizationModelReadBackend
+	lookupGroup singleflight.Group
 
 	// cache holds models that have already been validated.
+	cache *storage.InMemoryLRUCache[*TypeSystem]
+	// activeModelIDs holds the pinned model ID of the stores, including the stores without one.
+	activeModelIDs *storage.InMemoryLRUCache[*activeModelID]
+}
+
+func NewMemoizedTypesystemResolver(datastore storage.AuthorizationModelReadBackend) (*MemoizedTypesystemResolver, error) {
 	cache, err := storage.NewInMemoryLRUCache[*TypeSystem]()
+	if err != nil {
+		return nil, err
+	}
+
+	activeModelIDs, err := storage.NewInMemoryLRUCache[*activeModelID]()
+	if err != nil {
+		cache.Stop()
+		return nil, err
+	}
+
+	return &MemoizedTypesystemResolver{
+		datastore:      datastore,
+		cache:          cache,
+		activeModelIDs: activeModelIDs,
+	}, nil
+}
+
+// MemoizedTypesystemResolverFunc returns the Resolve function of a new MemoizedTypesystemResolver, and the function
+// that stops it.
+func MemoizedTypesystemResolverFunc(datastore storage.AuthorizationModelReadBackend) (TypesystemResolverFunc, func(), error) {
+	r, err := NewMemoizedTypesystemResolver(datastore)
 	if err != nil {
 		return nil, nil, err
 	}
+	return r.Resolve, r.Stop, nil
+}
 
-	return func(ctx context.Context, storeID, modelID string) (*TypeSystem, error) {
-		ctx, span := tracer.Start(ctx, "resolveTypesystem", trace.WithAttributes(
-			attribute.String("store_id", storeID),
-		))
-		defer func() {
-			span.SetAttributes(attribute.String("authorization_model_id", modelID))
-			span.End()
-		}()
+// Resolve does several things.
+//
+// If given a model ID: validates the model ID, and tries to fetch it from the cache.
+// If not found in the cache, fetches from the datastore, validates it, stores in cache, and returns it.
+//
+// If not given a model ID: uses the active model ID of the store if one is pinned, as if it was given. Otherwise,
+// fetches the latest model from the datastore, then sees if the model ID is in the cache.
+// If it is, returns it. Else, validates it and returns it. The pinned model ID, or its absence, is cached for a
+// short TTL, see InvalidateActiveModelID.
+func (r *MemoizedTypesystemResolver) Resolve(ctx context.Context, storeID, modelID string) (*TypeSystem, error) {
+	ctx, span := tracer.Start(ctx, "resolveTypesystem", trace.WithAttributes(
+		attribute.String("store_id", storeID),
+	))
+	defer func() {
+		span.SetAttributes(attribute.String("authorization_model_id", modelID))
+		span.End()
+	}()
+
+	var err error
+
+	if modelID != "" {
+		if _, err := ulid.Parse(modelID); err != nil {
+			return nil, ErrModelNotFound
+		}
+	}
 
-		var err error
+	if modelID == "" {
+		modelID, err = r.readActiveModelID(ctx, storeID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to ReadActiveAuthorizationModelID: %w", err)
+		}
+	}
 
-		if modelID != "" {
-			if _, err := ulid.Parse(modelID); err != nil {
+	var model *openfgav1.AuthorizationModel
+	var key string
+	if modelID == "" {
+		v, err, _ := r.lookupGroup.Do("FindLatestAuthorizationModel:"+storeID, func() (interface{}, error) {
+			return r.datastore.FindLatestAuthorizationModel(ctx, storeID)
+		})
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
 				return nil, ErrModelNotFound
 			}
+
+			return nil, fmt.Errorf("failed to FindLatestAuthorizationModel: %w", err)
 		}
 
-		var model *openfgav1.AuthorizationModel
-		var key string
-		if modelID == "" {
-			v, err, _ := lookupGroup.Do("FindLatestAuthorizationModel:"+storeID, func() (interface{}, error) {
-				return datastore.FindLatestAuthorizationModel(ctx, storeID)
-			})
-			if err != nil {
-				if errors.Is(err, storage.ErrNotFound) {
-					return nil, ErrModelNotFound
-				}
-
-				return nil, fmt.Errorf("failed to FindLatestAuthorizationModel: %w", err)
+		model = v.(*openfgav1.AuthorizationModel)
+		modelID = model.GetId()
+	}
+
+	key = fmt.Sprintf("%s/%s", storeID, modelID)
+	item := r.cache.Get(key)
+	if item != nil {
+		return item, nil
+	}
+
+	if model == nil {
+		v, err, _ := r.lookupGroup.Do(fmt.Sprintf("ReadAuthorizationModel:%s/%s", storeID, modelID), func() (interface{}, error) {
+			return r.datastore.ReadAuthorizationModel(ctx, storeID, modelID)
+		})
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return nil, ErrModelNotFound
 			}
 
-			model = v.(*openfgav1.AuthorizationModel)
-			modelID = model.GetId()
+			return nil, fmt.Errorf("failed to ReadAuthorizationModel: %w", err)
 		}
 
-		key = fmt.Sprintf("%s/%s", storeID, modelID)
-		item := cache.Get(key)
-		if item != nil {
-			return item, nil
-		}
+		model = v.(*openfgav1.AuthorizationModel)
+	}
 
-		if model == nil {
-			v, err, _ := lookupGroup.Do(fmt.Sprintf("ReadAuthorizationModel:%s/%s", storeID, modelID), func() (interface{}, error) {
-				return datastore.ReadAuthorizationModel(ctx, storeID, modelID)
-			})
-			if err != nil {
-				if errors.Is(err, storage.ErrNotFound) {
-					return nil, ErrModelNotFound
-				}
+	typesys, err := NewAndValidate(ctx, model)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidModel, err)
+	}
 
-				return nil, fmt.Errorf("failed to ReadAuthorizationModel: %w", err)
-			}
+	r.cache.Set(key, typesys, typesystemCacheTTL)
 
-			model = v.(*openfgav1.AuthorizationModel)
-		}
+	return typesys, nil
+}
 
-		typesys, err := NewAndValidate(ctx, model)
-		if err != nil {
-			return nil, fmt.Errorf("%w: %v", ErrInvalidModel, err)
-		}
+// readActiveModelID returns the model ID pinned as the active model of the store, or an empty ID if none is pinned.
+func (r *MemoizedTypesystemResolver) readActiveModelID(ctx context.Context, storeID string) (string, error) {
+	if item := r.activeModelIDs.Get(storeID); item != nil {
+		return item.modelID, nil
+	}
+
+	v, err, _ := r.lookupGroup.Do("ReadActiveAuthorizationModelID:"+storeID, func() (interface{}, error) {
+		return r.datastore.ReadActiveAuthorizationModelID(ctx, storeID)
+	})
+	modelID := ""
+	switch {
+	case err == nil:
+		modelID = v.(string)
+	case !errors.Is(err, storage.ErrNotFound):
+		return "", err
+	}
 
-		cache.Set(key, typesys, typesystemCacheTTL)
+	r.activeModelIDs.Set(storeID, &activeModelID{modelID: modelID}, activeModelIDCacheTTL)
+	return modelID, nil
+}
+
+// InvalidateActiveModelID drops the cached active model ID of the store, e.g. after it was pinned or unpinned, so
+// that the next requests of the store that do not specify a model ID read it again.
+func (r *MemoizedTypesystemResolver) InvalidateActiveModelID(storeID string) {
+	r.activeModelIDs.Delete(storeID)
+}
 
-		return typesys, nil
-	}, cache.Stop, nil
+// Stop releases the caches.
+func (r *MemoizedTypesystemResolver) Stop() {
+	r.cache.Stop()
+	r.activeModelIDs.Stop()
 }
@@ -43,6 +43,9 @@ func TestMemoizedTypesystemResolverFunc(t *testing.T) {
 		defer mockController.Finish()
 
 		mockDatastore := mockstorage.NewMockAuthorizationModelReadBackend(mockController)
+		mockDatastore.EXPECT().ReadActiveAuthorizationModelID(gomock.Any(), store).
+			Return("", storage.ErrNotFound).
+			Times(1)
 		mockDatastore.EXPECT().FindLatestAuthorizationModel(gomock.Any(), store).
 			Return(nil, storage.ErrNotFound).
 			Times(1)
@@ -67,6 +70,9 @@ func TestMemoizedTypesystemResolverFunc(t *testing.T) {
 
 		mockDatastore := mockstorage.NewMockAuthorizationModelReadBackend(mockController)
 
+		mockDatastore.EXPECT().ReadActiveAuthorizationModelID(gomock.Any(), store).
+			Return("", storage.ErrNotFound).
+			Times(1)
 		mockDatastore.EXPECT().FindLatestAuthorizationModel(gomock.Any(), store).
 			Return(model, nil).
 			Times(1)
@@ -143,6 +149,9 @@ func TestMemoizedTypesystemResolverFunc(t *testing.T) {
 
 		mockDatastore := mockstorage.NewMockAuthorizationModelReadBackend(mockController)
 
+		mockDatastore.EXPECT().ReadActiveAuthorizationModelID(gomock.Any(), store).
+			Return("", storage.ErrNotFound).
+			Times(1)
 		mockDatastore.EXPECT().FindLatestAuthorizationModel(gomock.Any(), store).
 			Return(model, nil).
 			Times(1)
@@ -200,6 +209,10 @@ func TestMemoizedTypesystemResolverFunc(t *testing.T) {
 
 		mockDatastore := mockstorage.NewMockAuthorizationModelReadBackend(mockController)
 
+		// the absence of an active model is cached
+		mockDatastore.EXPECT().ReadActiveAuthorizationModelID(gomock.Any(), store).
+			Return("", storage.ErrNotFound).
+			Times(1)
 		mockDatastore.EXPECT().FindLatestAuthorizationModel(gomock.Any(), store).
 			Return(model, nil).
 			Times(2)
@@ -241,6 +254,9 @@ func TestMemoizedTypesystemResolverFunc(t *testing.T) {
 		defer resolverStop()
 
 		// first read returns modelOne
+		mockDatastore.EXPECT().ReadActiveAuthorizationModelID(gomock.Any(), store).
+			Return("", storage.ErrNotFound).
+			Times(1)
 		mockDatastore.EXPECT().FindLatestAuthorizationModel(gomock.Any(), store).
 			Return(modelOne, nil).
 			Times(1)
@@ -258,4 +274,93 @@ func TestMemoizedTypesystemResolverFunc(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, modelTwo.GetId(), typesys.GetAuthorizationModelID())
 	})
+
+	t.Run("empty_model_id_returns_active_model", func(t *testing.T) {
+		store := ulid.Make().String()
+		model := testutils.MustTransformDSLToProtoWithID(`
+			model
+				schema 1.1
+
+			type user`)
+		mockController := gomock.NewController(t)
+		defer mockController.Finish()
+
+		mockDatastore := mockstorage.NewMockAuthorizationModelReadBackend(mockController)
+		mockDatastore.EXPECT().ReadActiveAuthorizationModelID(gomock.Any(), store).
+			Return(model.GetId(), nil).
+			Times(1)
+		mockDatastore.EXPECT().ReadAuthorizationModel(gomock.Any(), store, model.GetId()).
+			Return(model, nil).
+			Times(1)
+
+		resolver, resolverStop, err := MemoizedTypesystemResolverFunc(mockDatastore)
+		require.NoError(t, err)
+		defer resolverStop()
+
+		typesys, err := resolver(context.Background(), store, "")
+		require.NoError(t, err)
+		require.Equal(t, model.GetId(), typesys.GetAuthorizationModelID())
+
+		// second call from cache, including the active model ID
+		typesys, err = resolver(context.Background(), store, "")
+		require.NoError(t, err)
+		require.Equal(t, model.GetId(), typesys.GetAuthorizationModelID())
+	})
+
+	t.Run("error_reading_active_model_id", func(t *testing.T) {
+		store := ulid.Make().String()
+		mockController := gomock.NewController(t)
+		defer mockController.Finish()
+
+		mockDatastore := mockstorage.NewMockAuthorizationModelReadBackend(mockController)
+		mockDatastore.EXPECT().ReadActiveAuthorizationModelID(gomock.Any(), store).
+			Return("", context.DeadlineExceeded).
+			Times(1)
+
+		resolver, resolverStop, err := MemoizedTypesystemResolverFunc(mockDatastore)
+		require.NoError(t, err)
+		defer resolverStop()
+
+		_, err = resolver(context.Background(), store, "")
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("active_model_id_is_read_again_once_invalidated", func(t *testing.T) {
+		store := ulid.Make().String()
+		model := testutils.MustTransformDSLToProtoWithID(`
+			model
+				schema 1.1
+
+			type user`)
+		mockController := gomock.NewController(t)
+		defer mockController.Finish()
+
+		mockDatastore := mockstorage.NewMockAuthorizationModelReadBackend(mockController)
+		gomock.InOrder(
+			mockDatastore.EXPECT().ReadActiveAuthorizationModelID(gomock.Any(), store).
+				Return("", storage.ErrNotFound),
+			mockDatastore.EXPECT().ReadActiveAuthorizationModelID(gomock.Any(), store).
+				Return(model.GetId(), nil),
+		)
+		mockDatastore.EXPECT().FindLatestAuthorizationModel(gomock.Any(), store).
+			Return(nil, storage.ErrNotFound).
+			Times(1)
+		mockDatastore.EXPECT().ReadAuthorizationModel(gomock.Any(), store, model.GetId()).
+			Return(model, nil).
+			Times(1)
+
+		resolver, err := NewMemoizedTypesystemResolver(mockDatastore)
+		require.NoError(t, err)
+		defer resolver.Stop()
+
+		_, err = resolver.Resolve(context.Background(), store, "")
+		require.ErrorIs(t, err, ErrModelNotFound)
+
+		// the model is pinned
+		resolver.InvalidateActiveModelID(store)
+
+		typesys, err := resolver.Resolve(context.Background(), store, "")
+		require.NoError(t, err)
+		require.Equal(t, model.GetId(), typesys.GetAuthorizationModelID())
+	})
 }
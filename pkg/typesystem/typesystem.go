@@ -177,6 +177,7 @@ type TypeSystem struct {
 	ttuRelations map[string]map[string][]*openfgav1.TupleToUserset
 
 	computedRelations sync.Map
+	tupleRelations    sync.Map
 
 	modelID                 string
 	schemaVersion           string
@@ -312,6 +313,94 @@ func (t *TypeSystem) ResolveComputedRelation(objectType, relation string) (strin
 	}
 }
 
+// GetTupleRelations returns the relations, as "objectType#relation" strings in ascending order, of the tuples that
+// the relation of the objectType is evaluated with: the tuples of the relation itself, and recursively those of the
+// relations it is rewritten to, of the usersets it is directly related to and of its tuple to userset rewrites. The
+// changes of the tuples of the other relations do not change which users have the relation with the objects of the
+// objectType. Subsequent calls to this method are resolved from a cache.
+func (t *TypeSystem) GetTupleRelations(objectType, relation string) ([]string, error) {
+	memoizeKey := tuple.ToObjectRelationString(objectType, relation)
+	if val, ok := t.tupleRelations.Load(memoizeKey); ok {
+		return val.([]string), nil
+	}
+
+	visited := map[string]struct{}{}
+	tupleRelations := map[string]struct{}{}
+	if err := t.collectTupleRelations(objectType, relation, visited, tupleRelations); err != nil {
+		return nil, err
+	}
+
+	result := slices.Sorted(maps.Keys(tupleRelations))
+	t.tupleRelations.Store(memoizeKey, result)
+	return result, nil
+}
+
+func (t *TypeSystem) collectTupleRelations(objectType, relation string, visited, tupleRelations map[string]struct{}) error {
+	key := tuple.ToObjectRelationString(objectType, relation)
+	if _, ok := visited[key]; ok {
+		return nil
+	}
+	visited[key] = struct{}{}
+
+	rel, err := t.GetRelation(objectType, relation)
+	if err != nil {
+		return err
+	}
+
+	rewrites := []*openfgav1.Userset{rel.GetRewrite()}
+	for len(rewrites) > 0 {
+		rewrite := rewrites[len(rewrites)-1]
+		rewrites = rewrites[:len(rewrites)-1]
+
+		switch rw := rewrite.GetUserset().(type) {
+		case *openfgav1.Userset_This:
+			tupleRelations[key] = struct{}{}
+
+			for _, userType := range rel.GetTypeInfo().GetDirectlyRelatedUserTypes() {
+				if userType.GetRelation() == "" {
+					continue
+				}
+
+				if err := t.collectTupleRelations(userType.GetType(), userType.GetRelation(), visited, tupleRelations); err != nil {
+					return err
+				}
+			}
+		case *openfgav1.Userset_ComputedUserset:
+			if err := t.collectTupleRelations(objectType, rw.ComputedUserset.GetRelation(), visited, tupleRelations); err != nil {
+				return err
+			}
+		case *openfgav1.Userset_TupleToUserset:
+			tupleset := rw.TupleToUserset.GetTupleset().GetRelation()
+			tupleRelations[tuple.ToObjectRelationString(objectType, tupleset)] = struct{}{}
+
+			tuplesetRel, err := t.GetRelation(objectType, tupleset)
+			if err != nil {
+				return err
+			}
+
+			computedRelation := rw.TupleToUserset.GetComputedUserset().GetRelation()
+			for _, userType := range tuplesetRel.GetTypeInfo().GetDirectlyRelatedUserTypes() {
+				if _, err := t.GetRelation(userType.GetType(), computedRelation); err != nil {
+					// the computed relation does not need to be defined on every type of the tupleset
+					continue
+				}
+
+				if err := t.collectTupleRelations(userType.GetType(), computedRelation, visited, tupleRelations); err != nil {
+					return err
+				}
+			}
+		case *openfgav1.Userset_Union:
+			rewrites = append(rewrites, rw.Union.GetChild()...)
+		case *openfgav1.Userset_Intersection:
+			rewrites = append(rewrites, rw.Intersection.GetChild()...)
+		case *openfgav1.Userset_Difference:
+			rewrites = append(rewrites, rw.Difference.GetBase(), rw.Difference.GetSubtract())
+		}
+	}
+
+	return nil
+}
+
 // GetRelations returns all relations in the TypeSystem for a given type.
 func (t *TypeSystem) GetRelations(objectType string) (map[string]*openfgav1.Relation, error) {
 	_, ok := t.GetTypeDefinition(objectType)
@@ -379,6 +468,23 @@ func (t *TypeSystem) GetDirectlyRelatedUserTypes(objectType, relation string) ([
 	return r.GetTypeInfo().GetDirectlyRelatedUserTypes(), nil
 }
 
+// IsDirectlyRelatedUserType returns true if the objectType is one of the directly related user types of any relation
+// of the model, as a type, a typed wildcard or a userset. The objects of the other types are never the users of a
+// tuple, so they are never traversed further than the relations of the object itself.
+func (t *TypeSystem) IsDirectlyRelatedUserType(objectType string) bool {
+	for _, relations := range t.relations {
+		for _, relation := range relations {
+			for _, userType := range relation.GetTypeInfo().GetDirectlyRelatedUserTypes() {
+				if userType.GetType() == objectType {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
 // DirectlyRelatedUsersets returns a list of the directly user related types that are usersets.
 func (t *TypeSystem) DirectlyRelatedUsersets(objectType, relation string) ([]*openfgav1.RelationReference, error) {
 	refs, err := t.GetDirectlyRelatedUserTypes(objectType, relation)
@@ -3464,6 +3464,77 @@ func TestDirectlyRelatedUsersets(t *testing.T) {
 	}
 }
 
+func TestIsDirectlyRelatedUserType(t *testing.T) {
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type employee
+		type group
+			relations
+				define member: [user, employee:*]
+		type folder
+			relations
+				define viewer: [group#member]
+		type document
+			relations
+				define parent: [folder]
+				define viewer: viewer from parent`)
+
+	typesys, err := New(model)
+	require.NoError(t, err)
+
+	require.True(t, typesys.IsDirectlyRelatedUserType("user"))
+	require.True(t, typesys.IsDirectlyRelatedUserType("employee"))
+	require.True(t, typesys.IsDirectlyRelatedUserType("group"))
+	require.True(t, typesys.IsDirectlyRelatedUserType("folder"))
+	require.False(t, typesys.IsDirectlyRelatedUserType("document"))
+	require.False(t, typesys.IsDirectlyRelatedUserType("undefined"))
+}
+
+func TestGetTupleRelations(t *testing.T) {
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type group
+			relations
+				define member: [user, group#member]
+				define admin: [user]
+		type folder
+			relations
+				define owner: [user]
+				define viewer: [group#member] or owner
+		type document
+			relations
+				define parent: [folder, group]
+				define blocked: [user]
+				define editor: [user]
+				define viewer: (editor or viewer from parent) but not blocked
+				define unrelated: [user]`)
+
+	typesys, err := New(model)
+	require.NoError(t, err)
+
+	tupleRelations, err := typesys.GetTupleRelations("document", "viewer")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"document#blocked",
+		"document#editor",
+		"document#parent",
+		"folder#owner",
+		"folder#viewer",
+		"group#member",
+	}, tupleRelations)
+
+	tupleRelations, err = typesys.GetTupleRelations("group", "member")
+	require.NoError(t, err)
+	require.Equal(t, []string{"group#member"}, tupleRelations)
+
+	_, err = typesys.GetTupleRelations("document", "undefined")
+	require.ErrorIs(t, err, ErrRelationUndefined)
+}
+
 func TestUsersetUseWeight2Resolver(t *testing.T) {
 	tests := []struct {
 		name       string